@@ -21,6 +21,7 @@ require (
 	github.com/mattn/go-sqlite3 v1.14.33
 	github.com/rivo/uniseg v0.4.7
 	github.com/yuin/goldmark v1.7.8
+	go.starlark.net v0.0.0-20231121155337-90ade8b19d09
 	golang.org/x/term v0.39.0
 	gopkg.in/yaml.v3 v3.0.1
 	modernc.org/sqlite v1.41.0
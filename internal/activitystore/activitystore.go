@@ -5,6 +5,11 @@
 // all read "just now" and a turn that finished while Sidecar was closed can
 // never surface as done. Persisting the idle transition keeps both facts true
 // across restarts.
+//
+// The store is also the cheapest answer to "what are my agents doing" for a
+// process that is not the board: `sidecar status` reads it every second from
+// a status line, so live states are written too, and readers decide how long
+// to believe them.
 package activitystore
 
 import (
@@ -23,6 +28,11 @@ const FileName = "agent-activity.json"
 // go; without pruning the file would accumulate every shell ever observed.
 const RetainFor = 7 * 24 * time.Hour
 
+// LiveFor bounds how long a persisted working or blocked reading is believed.
+// The board rewrites the store after every poll, at most half a minute apart,
+// so an older live reading means nobody is watching the pane any more.
+const LiveFor = 2 * time.Minute
+
 type entry struct {
 	agentactivity.Snapshot
 	UpdatedAt time.Time `json:"updatedAt"`
@@ -61,12 +71,56 @@ func Load(path string, now time.Time) map[string]agentactivity.Tracker {
 	return trackers
 }
 
+// Entry is one persisted reading, for readers that only report it.
+type Entry struct {
+	State     agentactivity.State
+	ChangedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Current is the state a reader should report at now. A working or blocked
+// reading older than LiveFor is unknown: the session may have finished, or
+// died, since anyone last looked. Idle stays idle, as Load treats it.
+func (e Entry) Current(now time.Time) agentactivity.State {
+	switch e.State {
+	case agentactivity.StateIdle:
+		return e.State
+	case agentactivity.StateWorking, agentactivity.StateBlocked:
+		if now.Sub(e.UpdatedAt) <= LiveFor {
+			return e.State
+		}
+	}
+	return agentactivity.StateUnknown
+}
+
+// Read returns every unexpired entry without restoring trackers. Like Load,
+// a missing or unreadable store reads as empty.
+func Read(path string, now time.Time) map[string]Entry {
+	entries := make(map[string]Entry)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return entries
+	}
+	var stored file
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return entries
+	}
+	for key, e := range stored.Entries {
+		if !e.UpdatedAt.IsZero() && now.Sub(e.UpdatedAt) > RetainFor {
+			continue
+		}
+		entries[key] = Entry{State: agentactivity.State(e.State), ChangedAt: e.ChangedAt, UpdatedAt: e.UpdatedAt}
+	}
+	return entries
+}
+
 // Save writes the given trackers, replacing the file atomically so a crash
-// mid-write cannot leave a half-parsed store behind.
+// mid-write cannot leave a half-parsed store behind. Every known state is
+// written; Load decides what is safe to restore.
 func Save(path string, trackers map[string]agentactivity.Tracker, now time.Time) error {
 	out := file{Version: 1, Entries: make(map[string]entry, len(trackers))}
 	for key, tracker := range trackers {
-		if tracker.State != agentactivity.StateIdle {
+		if tracker.State == agentactivity.StateUnknown || tracker.State == "" {
 			continue
 		}
 		out.Entries[key] = entry{Snapshot: tracker.Snapshot(), UpdatedAt: now}
@@ -64,3 +64,38 @@ func TestLoadMissingFileIsEmpty(t *testing.T) {
 		t.Fatalf("expected empty map, got %v", got)
 	}
 }
+
+func TestReadReportsLiveStatesWhileFresh(t *testing.T) {
+	now := time.Now()
+	path := filepath.Join(t.TempDir(), FileName)
+	in := map[string]agentactivity.Tracker{
+		"working": {State: agentactivity.StateWorking, ChangedAt: now},
+		"blocked": {State: agentactivity.StateBlocked, ChangedAt: now},
+		"idle":    {State: agentactivity.StateIdle, ChangedAt: now},
+		"unknown": {State: agentactivity.StateUnknown, ChangedAt: now},
+	}
+	if err := Save(path, in, now); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	entries := Read(path, now)
+	if _, ok := entries["unknown"]; ok {
+		t.Fatalf("unknown state should not be written")
+	}
+	for key, want := range map[string]agentactivity.State{"working": agentactivity.StateWorking, "blocked": agentactivity.StateBlocked, "idle": agentactivity.StateIdle} {
+		if got := entries[key].Current(now); got != want {
+			t.Fatalf("%s: current = %q, want %q", key, got, want)
+		}
+	}
+	// Nobody has rewritten the store since: a live reading is no longer
+	// believable, an idle one still is.
+	later := now.Add(LiveFor + time.Second)
+	if got := entries["working"].Current(later); got != agentactivity.StateUnknown {
+		t.Fatalf("stale working = %q, want unknown", got)
+	}
+	if got := entries["idle"].Current(later); got != agentactivity.StateIdle {
+		t.Fatalf("stale idle = %q, want idle", got)
+	}
+	if aged := Read(path, now.Add(RetainFor+time.Hour)); len(aged) != 0 {
+		t.Fatalf("expired entries survived: %v", aged)
+	}
+}
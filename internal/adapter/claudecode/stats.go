@@ -143,6 +143,29 @@ func (s *StatsCache) TotalCost() float64 {
 	return total
 }
 
+// DayCost estimates one day's cost from its per-model token totals. The cache
+// keeps only one total per model per day, so each model's tokens are priced at
+// its lifetime average cost per input and output token. ok is false when the
+// cache has no entry for the day, which is ordinary: Claude Code recomputes
+// the cache lazily, so today is often missing until later.
+func (s *StatsCache) DayCost(date string) (cost float64, ok bool) {
+	for _, day := range s.DailyModelTokens {
+		if day.Date != date {
+			continue
+		}
+		for model, tokens := range day.TokensByModel {
+			usage, known := s.ModelUsage[model]
+			billed := int64(usage.InputTokens) + int64(usage.OutputTokens)
+			if !known || billed == 0 {
+				continue
+			}
+			cost += CalculateModelCost(model, usage) / float64(billed) * float64(tokens)
+		}
+		return cost, true
+	}
+	return 0, false
+}
+
 // CalculateModelCost calculates cost for a specific model's usage.
 func CalculateModelCost(model string, usage ModelUsage) float64 {
 	return pricing.ModelCost(model, pricing.Usage{
@@ -0,0 +1,30 @@
+package claudecode
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDayCostPricesTokensAtTheModelsAverage(t *testing.T) {
+	usage := ModelUsage{InputTokens: 800_000, OutputTokens: 200_000, CacheReadInputTokens: 4_000_000}
+	stats := &StatsCache{
+		DailyModelTokens: []DailyModelTokens{
+			{Date: "2026-10-17", TokensByModel: map[string]int{"claude-sonnet-4-5": 900_000}},
+			{Date: "2026-10-18", TokensByModel: map[string]int{"claude-sonnet-4-5": 250_000, "unpriced": 10}},
+		},
+		ModelUsage: map[string]ModelUsage{"claude-sonnet-4-5": usage},
+	}
+
+	cost, ok := stats.DayCost("2026-10-18")
+	if !ok {
+		t.Fatal("day with tokens reported missing")
+	}
+	want := CalculateModelCost("claude-sonnet-4-5", usage) / 4
+	if math.Abs(cost-want) > 1e-9 {
+		t.Fatalf("cost = %v, want %v", cost, want)
+	}
+
+	if _, ok := stats.DayCost("2026-10-19"); ok {
+		t.Fatal("a day the cache has not computed should report missing")
+	}
+}
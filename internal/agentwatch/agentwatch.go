@@ -0,0 +1,215 @@
+// Package agentwatch is the headless half of agent monitoring: it polls every
+// registered project's shells and worktrees, classifies what each agent is
+// doing, keeps the activity store current, and turns settled lane changes into
+// notifications — the work the Agents board and the workspace plugin do while
+// a TUI is open, without one.
+//
+// It owns no delivery. A Round says what to post and what to withdraw; the
+// caller decides where that goes, which is how `sidecar watch` can stay quiet
+// about a project a running instance is already announcing.
+package agentwatch
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/marcus/sidecar/internal/activitystore"
+	"github.com/marcus/sidecar/internal/agentactivity"
+	"github.com/marcus/sidecar/internal/notify"
+	"github.com/marcus/sidecar/internal/workspaceinventory"
+)
+
+// DefaultInventoryEvery is how often the Git and manifest inventory is re-read.
+// Between inventories a round only lists tmux panes and captures the agents,
+// as the board's live poll does.
+const DefaultInventoryEvery = time.Minute
+
+// maxCaptures bounds concurrent pane captures within one round.
+const maxCaptures = 4
+
+// Project is one configured project to watch.
+type Project struct {
+	Name, Path string
+}
+
+// Post is a notification and the project it is about.
+type Post struct {
+	Notification notify.Notification
+	ProjectRoot  string
+}
+
+// Round is what one poll found.
+type Round struct {
+	// Agents is every agent workspace observed, across projects.
+	Agents []workspaceinventory.Workspace
+	// Post and Dismiss are the lane tracker's answer for this round.
+	Post    []Post
+	Dismiss []string
+	// Errors are per-project inventory failures. A failing project is still
+	// watched for the shells its manifest records.
+	Errors map[string]error
+}
+
+// Watcher polls agents across projects. The zero value is not usable; build
+// one with New.
+type Watcher struct {
+	collector workspaceinventory.Collector
+	// activityPath is the activity store written after every round. Empty
+	// disables persistence.
+	activityPath   string
+	inventoryEvery time.Duration
+	lanes          notify.LaneTracker
+
+	inventory     map[string]workspaceinventory.ProjectResult
+	inventoried   []string
+	lastInventory time.Time
+}
+
+// New returns a watcher seeded from the activity store at activityPath, so an
+// agent that finished while nothing was watching still reads as done.
+func New(collector workspaceinventory.Collector, activityPath string, inventoryEvery time.Duration) *Watcher {
+	collector = collector.WithDefaults()
+	if activityPath != "" {
+		collector = collector.SeedTrackers(activitystore.Load(activityPath, time.Now()))
+	}
+	if inventoryEvery <= 0 {
+		inventoryEvery = DefaultInventoryEvery
+	}
+	return &Watcher{collector: collector, activityPath: activityPath, inventoryEvery: inventoryEvery}
+}
+
+// Round polls once at now. The first round only establishes each lane: a lane
+// tracker never announces a state it did not see begin.
+func (w *Watcher) Round(ctx context.Context, projects []Project, now time.Time) (Round, error) {
+	projects = dedupe(projects)
+	roots := make([]string, 0, len(projects))
+	for _, p := range projects {
+		roots = append(roots, p.Path)
+	}
+	if w.inventory == nil || !slices.Equal(roots, w.inventoried) || now.Sub(w.lastInventory) >= w.inventoryEvery {
+		w.inventory = make(map[string]workspaceinventory.ProjectResult, len(projects))
+		for _, p := range projects {
+			w.inventory[p.Path] = w.collector.CollectProjectInventory(ctx, p.Name, p.Path)
+		}
+		w.inventoried, w.lastInventory = roots, now
+	}
+
+	panes, err := w.collector.ListPanes(ctx)
+	if err != nil {
+		return Round{}, err
+	}
+	results := make([]workspaceinventory.ProjectResult, 0, len(projects))
+	for _, p := range projects {
+		results = append(results, w.inventory[p.Path])
+	}
+	refresh := w.collector.ForRefresh(maxCaptures, workspaceinventory.BuildShellClaims(results))
+
+	var round Round
+	var observations []notify.LaneObservation
+	rootOf := make(map[string]string)
+	for _, inventory := range results {
+		if inventory.Err != nil {
+			if round.Errors == nil {
+				round.Errors = make(map[string]error)
+			}
+			round.Errors[inventory.ProjectRoot] = inventory.Err
+		}
+		status := refresh.RefreshProjectStatus(ctx, inventory, roots, panes)
+		for _, workspace := range workspaceinventory.AgentWorkspaces(status.Workspaces) {
+			round.Agents = append(round.Agents, workspace)
+			if o, ok := observation(workspace); ok {
+				observations = append(observations, o)
+				rootOf[o.Origin.WorkDir] = workspace.ProjectRoot
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		// A canceled round is never committed, exactly as a stale board
+		// refresh is not: half the captures would read as vanished agents.
+		return Round{}, err
+	}
+	refresh.CommitTrackers()
+	if w.activityPath != "" {
+		_ = activitystore.Save(w.activityPath, w.collector.TrackerSnapshot(), now)
+	}
+
+	events := w.lanes.Observe(observations, now)
+	for _, n := range events.Post {
+		round.Post = append(round.Post, Post{Notification: n, ProjectRoot: rootOf[n.Origin.WorkDir]})
+	}
+	round.Dismiss = events.Dismiss
+	return round, nil
+}
+
+// Run polls every interval until ctx ends, handing each round to handle.
+// A round that fails is reported to handle as an error and the loop goes on:
+// a tmux server that is briefly unreachable is not a reason to stop watching.
+func (w *Watcher) Run(ctx context.Context, interval time.Duration, projects func() []Project, handle func(Round, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		round, err := w.Round(ctx, projects(), time.Now())
+		if ctx.Err() != nil {
+			return
+		}
+		handle(round, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// observation is the lane tracker's view of one agent workspace, in the shape
+// the workspace plugin builds, so a notification from either reads the same.
+func observation(workspace workspaceinventory.Workspace) (notify.LaneObservation, bool) {
+	// As in the plugin: only an agent whose activity can actually be read has
+	// lanes worth announcing.
+	if !agentactivity.Supports(workspace.Provider) {
+		return notify.LaneObservation{}, false
+	}
+	project := workspace.ProjectName
+	if project == "" {
+		project = filepath.Base(workspace.ProjectRoot)
+	}
+	where := project
+	if branch := strings.TrimSpace(workspace.Branch); branch != "" && branch != project {
+		where = project + "/" + branch
+	}
+	return notify.LaneObservation{
+		Key:          workspace.ID,
+		Label:        workspace.Name,
+		Context:      where,
+		Provider:     workspace.Provider,
+		Presentation: workspace.Presentation,
+		Origin: notify.Origin{
+			TmuxSession: workspace.TmuxName,
+			ProjectKey:  filepath.Base(workspace.ProjectRoot),
+			WorkDir:     workspace.Path,
+		},
+	}, true
+}
+
+// dedupe resolves each project to its canonical main worktree and drops
+// repeats, as the board does: two configured paths into one repository are one
+// project, and watching it twice would announce every transition twice.
+func dedupe(projects []Project) []Project {
+	seen := make(map[string]bool, len(projects))
+	out := make([]Project, 0, len(projects))
+	for _, p := range projects {
+		if strings.TrimSpace(p.Path) == "" {
+			continue
+		}
+		root := workspaceinventory.CanonicalProjectPath(p.Path)
+		if seen[root] {
+			continue
+		}
+		seen[root] = true
+		out = append(out, Project{Name: p.Name, Path: root})
+	}
+	return out
+}
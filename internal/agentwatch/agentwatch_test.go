@@ -0,0 +1,114 @@
+package agentwatch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/marcus/sidecar/internal/activitystore"
+	"github.com/marcus/sidecar/internal/agentactivity"
+	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/notify"
+	"github.com/marcus/sidecar/internal/projectdir"
+	"github.com/marcus/sidecar/internal/tmuxenv"
+	"github.com/marcus/sidecar/internal/tty"
+	"github.com/marcus/sidecar/internal/workspaceinventory"
+)
+
+type fakeRunner struct {
+	git  map[string]string
+	tmux string
+}
+
+func (r *fakeRunner) Output(_ context.Context, name string, args ...string) ([]byte, error) {
+	if name == "tmux" {
+		return []byte(r.tmux), nil
+	}
+	for i, arg := range args {
+		if arg == "-C" && i+1 < len(args) {
+			return []byte(r.git[args[i+1]]), nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected command %s %v", name, args)
+}
+
+func TestRoundAnnouncesAWaitingAgentAndWithdrawsIt(t *testing.T) {
+	stateBase := t.TempDir()
+	config.SetTestStateDir(stateBase)
+	t.Cleanup(config.ResetTestStateDir)
+	root := workspaceinventory.CanonicalPath(t.TempDir())
+	projectState, err := projectdir.ResolveWithBase(stateBase, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := `{"version":1,"shells":[{"tmuxName":"sidecar-sh-app-1","displayName":"Shell 1","namespace":"` + tmuxenv.Namespace() + `","agentType":"copilot"}]}`
+	if err := os.WriteFile(filepath.Join(projectState, "shells.json"), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	screen := "esc again to cancel"
+	runner := &fakeRunner{
+		git:  map[string]string{root: "worktree " + root + "\nHEAD abc\nbranch refs/heads/main\n"},
+		tmux: "%1\tsidecar-sh-app-1\t" + root + "\tcopilot\tcopilot\t0",
+	}
+	collector := workspaceinventory.Collector{
+		Runner:  runner,
+		Capture: func(string, int) (string, tty.PaneState, error) { return screen, tty.PaneState{}, nil },
+		Now:     func() time.Time { return now },
+	}
+	activityPath := filepath.Join(stateBase, activitystore.FileName)
+	w := New(collector, activityPath, time.Hour)
+	projects := []Project{{Name: "app", Path: root}}
+
+	round := func(at time.Time) Round {
+		t.Helper()
+		now = at
+		r, err := w.Round(context.Background(), projects, at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	if r := round(now); len(r.Agents) != 1 || len(r.Post) != 0 {
+		t.Fatalf("first round = %+v, want one agent and no posts", r)
+	}
+	entries := activitystore.Read(activityPath, now)
+	if got := entries[root+":shell:sidecar-sh-app-1"].State; got != agentactivity.StateWorking {
+		t.Fatalf("activity store state = %q, want working", got)
+	}
+
+	screen = "esc to cancel\nenter to confirm"
+	blockedAt := now.Add(5 * time.Second)
+	if r := round(blockedAt); len(r.Post) != 0 {
+		t.Fatalf("an unsettled lane posted: %+v", r.Post)
+	}
+	r := round(blockedAt.Add(notify.DefaultLaneDebounce + time.Second))
+	if len(r.Post) != 1 {
+		t.Fatalf("posts = %+v, want the waiting notification", r.Post)
+	}
+	posted := r.Post[0]
+	if posted.Notification.Source != notify.SourceWaiting || posted.ProjectRoot != root || posted.Notification.Origin.TmuxSession != "sidecar-sh-app-1" {
+		t.Fatalf("post = %+v", posted)
+	}
+
+	screen = "esc again to cancel"
+	resumedAt := blockedAt.Add(20 * time.Second)
+	round(resumedAt)
+	r = round(resumedAt.Add(notify.DefaultLaneDebounce + time.Second))
+	if len(r.Dismiss) != 1 || r.Dismiss[0] != posted.Notification.ID {
+		t.Fatalf("dismiss = %v, want %s", r.Dismiss, posted.Notification.ID)
+	}
+}
+
+func TestDedupeWatchesARepositoryOnce(t *testing.T) {
+	root := t.TempDir()
+	got := dedupe([]Project{{Name: "a", Path: root}, {Name: "b", Path: root + "/."}, {Name: "blank"}})
+	if len(got) != 1 || got[0].Name != "a" {
+		t.Fatalf("dedupe = %+v", got)
+	}
+}
@@ -0,0 +1,338 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/modal"
+	"github.com/marcus/sidecar/internal/mouse"
+	"github.com/marcus/sidecar/internal/state"
+	"github.com/marcus/sidecar/internal/styles"
+	"github.com/marcus/sidecar/internal/ui"
+	"github.com/marcus/sidecar/internal/uirequest"
+)
+
+const (
+	bookmarksItemPrefix = "bookmarks-item-"
+	bookmarksMaxVisible = 10
+)
+
+// bookmarksItemID returns the focusable ID for a bookmark row.
+func bookmarksItemID(idx int) string {
+	return fmt.Sprintf("%s%d", bookmarksItemPrefix, idx)
+}
+
+// bookmarkProject is the key the current project's bookmarks are filed under:
+// the main worktree, so every worktree of the project shares one list, the
+// same key `sidecar bookmark` computes from a working directory.
+func (m *Model) bookmarkProject() string {
+	if m.ui.ProjectRoot != "" {
+		return m.ui.ProjectRoot
+	}
+	return m.ui.WorkDir
+}
+
+// initBookmarks loads the current project's bookmarks. The file is read on
+// every open rather than cached: agents add to it from the CLI while the TUI
+// runs, and the picker is where the user expects to find what they left.
+func (m *Model) initBookmarks() {
+	m.clearBookmarksModal()
+	m.bookmarksErr = ""
+	items, err := state.ProjectBookmarks(config.StateDir(), m.bookmarkProject())
+	if err != nil {
+		m.bookmarksErr = err.Error()
+	}
+	m.bookmarksItems = items
+	// Newest first: the bookmark just left is the one most likely wanted.
+	for i, j := 0, len(m.bookmarksItems)-1; i < j; i, j = i+1, j-1 {
+		m.bookmarksItems[i], m.bookmarksItems[j] = m.bookmarksItems[j], m.bookmarksItems[i]
+	}
+	m.bookmarksCursor = 0
+	m.bookmarksScroll = 0
+}
+
+// resetBookmarks closes the picker and clears its state.
+func (m *Model) resetBookmarks() {
+	m.showBookmarks = false
+	m.bookmarksItems = nil
+	m.bookmarksCursor = 0
+	m.bookmarksScroll = 0
+	m.bookmarksErr = ""
+	m.clearBookmarksModal()
+}
+
+// clearBookmarksModal clears the modal/width/mouseHandler cache.
+func (m *Model) clearBookmarksModal() {
+	m.bookmarksModal = nil
+	m.bookmarksModalWidth = 0
+	m.bookmarksMouseHandler = nil
+}
+
+// ensureBookmarksModal builds/rebuilds the bookmarks modal if needed.
+func (m *Model) ensureBookmarksModal() {
+	modalW := 72
+	if modalW > m.width-4 {
+		modalW = m.width - 4
+	}
+	if modalW < 30 {
+		modalW = 30
+	}
+	if m.bookmarksModal != nil && m.bookmarksModalWidth == modalW {
+		return
+	}
+	m.bookmarksModalWidth = modalW
+
+	m.bookmarksModal = modal.New("Bookmarks",
+		modal.WithWidth(modalW),
+		modal.WithHints(false),
+	).
+		AddSection(m.bookmarksListSection()).
+		AddSection(m.bookmarksHintsSection())
+}
+
+// bookmarkKindLabel is the short tag shown before a bookmark's title.
+func bookmarkKindLabel(kind uirequest.TargetKind) string {
+	switch kind {
+	case uirequest.TargetKindSession, uirequest.TargetKindShell:
+		return "shell"
+	case uirequest.TargetKindResource:
+		return "link"
+	}
+	return string(kind)
+}
+
+// bookmarksListSection renders the bookmark list with cursor and scroll.
+func (m *Model) bookmarksListSection() modal.Section {
+	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
+		items := m.bookmarksItems
+		if m.bookmarksErr != "" {
+			return modal.RenderedSection{Content: lipgloss.NewStyle().Foreground(styles.Error).Render("Could not read bookmarks: " + m.bookmarksErr)}
+		}
+		if len(items) == 0 {
+			return modal.RenderedSection{Content: styles.Muted.Render("No bookmarks yet. Press b in Files, or run\nsidecar bookmark add <path[:line]>.")}
+		}
+
+		cursorStyle := lipgloss.NewStyle().Foreground(styles.Primary)
+		nameNormalStyle := lipgloss.NewStyle().Foreground(styles.Secondary)
+		nameSelectedStyle := lipgloss.NewStyle().Foreground(styles.Primary).Bold(true)
+
+		visibleCount := min(len(items), bookmarksMaxVisible)
+		scrollOffset := m.bookmarksScroll
+
+		var sb strings.Builder
+		focusables := make([]modal.FocusableInfo, 0, visibleCount)
+		lineOffset := 0
+
+		if scrollOffset > 0 {
+			sb.WriteString(styles.Muted.Render(fmt.Sprintf("  ↑ %d more above", scrollOffset)))
+			sb.WriteString("\n")
+			lineOffset++
+		}
+
+		for i := scrollOffset; i < scrollOffset+visibleCount && i < len(items); i++ {
+			b := items[i]
+			isCursor := i == m.bookmarksCursor
+			itemID := bookmarksItemID(i)
+
+			if isCursor {
+				sb.WriteString(cursorStyle.Render("> "))
+			} else {
+				sb.WriteString("  ")
+			}
+			nameStyle := nameNormalStyle
+			if isCursor || itemID == hoverID {
+				nameStyle = nameSelectedStyle
+			}
+
+			tag := fmt.Sprintf("%-6s ", bookmarkKindLabel(b.Target.Kind))
+			var detail string
+			if b.Label != "" {
+				detail = " " + b.Location()
+			}
+			if b.CreatedBy != "" && b.CreatedBy != "user" {
+				detail += " · " + b.CreatedBy
+			}
+			if b.Note != "" {
+				detail += " — " + strings.ReplaceAll(b.Note, "\n", " ")
+			}
+			avail := contentWidth - 2 - lipgloss.Width(tag)
+			title := ansi.Truncate(b.Title(), max(avail, 1), "…")
+			detail = ansi.Truncate(detail, max(avail-lipgloss.Width(title), 0), "…")
+			sb.WriteString(styles.Muted.Render(tag))
+			sb.WriteString(nameStyle.Render(title))
+			sb.WriteString(styles.Muted.Render(detail))
+
+			if i < scrollOffset+visibleCount-1 && i < len(items)-1 {
+				sb.WriteString("\n")
+			}
+			focusables = append(focusables, modal.FocusableInfo{
+				ID:      itemID,
+				OffsetX: 0,
+				OffsetY: lineOffset + (i - scrollOffset),
+				Width:   contentWidth,
+				Height:  1,
+			})
+		}
+
+		if remaining := len(items) - (scrollOffset + visibleCount); remaining > 0 {
+			sb.WriteString("\n")
+			sb.WriteString(styles.Muted.Render(fmt.Sprintf("  ↓ %d more below", remaining)))
+		}
+
+		return modal.RenderedSection{Content: sb.String(), Focusables: focusables}
+	}, m.bookmarksListUpdate)
+}
+
+// bookmarksListUpdate handles key events for the bookmark list.
+func (m *Model) bookmarksListUpdate(msg tea.Msg, focusID string) (string, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyPressMsg)
+	if !ok || len(m.bookmarksItems) == 0 {
+		return "", nil
+	}
+	switch keyMsg.String() {
+	case "up", "k", "ctrl+p":
+		if m.bookmarksCursor > 0 {
+			m.bookmarksCursor--
+			m.bookmarksScroll = openInEnsureCursorVisible(m.bookmarksCursor, m.bookmarksScroll, bookmarksMaxVisible)
+			m.bookmarksModalWidth = 0
+		}
+	case "down", "j", "ctrl+n":
+		if m.bookmarksCursor < len(m.bookmarksItems)-1 {
+			m.bookmarksCursor++
+			m.bookmarksScroll = openInEnsureCursorVisible(m.bookmarksCursor, m.bookmarksScroll, bookmarksMaxVisible)
+			m.bookmarksModalWidth = 0
+		}
+	case "enter":
+		return "select", nil
+	case "d", "delete":
+		return "delete", nil
+	}
+	return "", nil
+}
+
+// bookmarksHintsSection renders the help text for the bookmarks modal.
+func (m *Model) bookmarksHintsSection() modal.Section {
+	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
+		var sb strings.Builder
+		sb.WriteString("\n")
+		sb.WriteString(styles.KeyHint.Render("enter"))
+		sb.WriteString(styles.Muted.Render(" open  "))
+		sb.WriteString(styles.KeyHint.Render("d"))
+		sb.WriteString(styles.Muted.Render(" delete  "))
+		sb.WriteString(styles.KeyHint.Render("↑/↓"))
+		sb.WriteString(styles.Muted.Render(" navigate  "))
+		sb.WriteString(styles.KeyHint.Render("esc"))
+		sb.WriteString(styles.Muted.Render(" close"))
+		return modal.RenderedSection{Content: sb.String()}
+	}, nil)
+}
+
+// renderBookmarksModal renders the bookmarks modal over content.
+func (m *Model) renderBookmarksModal(content string) string {
+	m.ensureBookmarksModal()
+	if m.bookmarksModal == nil {
+		return content
+	}
+	if m.bookmarksMouseHandler == nil {
+		m.bookmarksMouseHandler = mouse.NewHandler()
+	}
+	modalContent := m.bookmarksModal.Render(m.width, m.height, m.bookmarksMouseHandler)
+	return ui.OverlayModal(content, modalContent, m.width, m.height)
+}
+
+// handleBookmarksAction applies a modal action from either keyboard or mouse.
+func (m *Model) handleBookmarksAction(action string) (tea.Model, tea.Cmd) {
+	if strings.HasPrefix(action, bookmarksItemPrefix) {
+		var idx int
+		if _, err := fmt.Sscanf(action, bookmarksItemPrefix+"%d", &idx); err != nil || idx < 0 || idx >= len(m.bookmarksItems) {
+			return m, nil
+		}
+		m.bookmarksCursor = idx
+		action = "select"
+	}
+	switch action {
+	case "cancel":
+		m.resetBookmarks()
+		m.updateContext()
+	case "select":
+		cmd := m.openSelectedBookmark()
+		m.resetBookmarks()
+		m.updateContext()
+		return m, cmd
+	case "delete":
+		return m, m.deleteSelectedBookmark()
+	}
+	return m, nil
+}
+
+// handleBookmarksMouse handles mouse events for the bookmarks modal.
+func (m *Model) handleBookmarksMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	m.ensureBookmarksModal()
+	if m.bookmarksModal == nil {
+		return m, nil
+	}
+	if m.bookmarksMouseHandler == nil {
+		m.bookmarksMouseHandler = mouse.NewHandler()
+	}
+	return m.handleBookmarksAction(m.bookmarksModal.HandleMouse(msg, m.bookmarksMouseHandler))
+}
+
+// openSelectedBookmark activates the bookmark under the cursor through the
+// same route a terminal link or `sidecar open` takes. A file bookmark carries
+// the checkout it was taken in, so one left in another worktree lands there
+// rather than on this checkout's copy of the file.
+func (m *Model) openSelectedBookmark() tea.Cmd {
+	if m.bookmarksCursor < 0 || m.bookmarksCursor >= len(m.bookmarksItems) {
+		return nil
+	}
+	b := m.bookmarksItems[m.bookmarksCursor]
+	project := ""
+	switch b.Target.Kind {
+	case uirequest.TargetKindFile, uirequest.TargetKindDiff:
+		project = b.WorkDir
+	}
+	return ActivateTargetIn(b.Target, project)
+}
+
+// deleteSelectedBookmark removes the bookmark under the cursor and keeps the
+// picker open on its neighbour.
+func (m *Model) deleteSelectedBookmark() tea.Cmd {
+	if m.bookmarksCursor < 0 || m.bookmarksCursor >= len(m.bookmarksItems) {
+		return nil
+	}
+	b := m.bookmarksItems[m.bookmarksCursor]
+	if _, err := state.RemoveBookmark(config.StateDir(), b.ID); err != nil {
+		return func() tea.Msg {
+			return ToastMsg{Message: "Bookmark not removed: " + err.Error(), Duration: 3 * time.Second, IsError: true}
+		}
+	}
+	m.bookmarksItems = append(m.bookmarksItems[:m.bookmarksCursor:m.bookmarksCursor], m.bookmarksItems[m.bookmarksCursor+1:]...)
+	if m.bookmarksCursor >= len(m.bookmarksItems) {
+		m.bookmarksCursor = max(len(m.bookmarksItems)-1, 0)
+	}
+	m.bookmarksScroll = openInEnsureCursorVisible(m.bookmarksCursor, min(m.bookmarksScroll, m.bookmarksCursor), bookmarksMaxVisible)
+	m.bookmarksModalWidth = 0
+	return ShowFlash("Removed bookmark " + b.Title())
+}
+
+// addBookmark files a target from the TUI under the current project.
+func (m *Model) addBookmark(req AddBookmarkMsg) tea.Cmd {
+	b, err := state.AddBookmark(config.StateDir(), state.Bookmark{
+		Project:   m.bookmarkProject(),
+		WorkDir:   m.ui.WorkDir,
+		Target:    req.Target,
+		Label:     strings.TrimSpace(req.Label),
+		CreatedBy: "user",
+	})
+	if err != nil {
+		return func() tea.Msg {
+			return ToastMsg{Message: "Bookmark not saved: " + err.Error(), Duration: 3 * time.Second, IsError: true}
+		}
+	}
+	return ShowFlash("Bookmarked " + b.Location())
+}
@@ -0,0 +1,85 @@
+package app
+
+import (
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/keymap"
+	"github.com/marcus/sidecar/internal/state"
+	"github.com/marcus/sidecar/internal/uirequest"
+)
+
+func bookmarksTestModel(t *testing.T) Model {
+	t.Helper()
+	config.SetTestStateDir(t.TempDir())
+	t.Cleanup(config.ResetTestStateDir)
+	p := newRouterPlugin()
+	p.context = "file-browser-tree"
+	m := routerTestModel(t, p)
+	m.ui.WorkDir = "/tmp/alpha"
+	m.ui.ProjectRoot = "/tmp/alpha"
+	keymap.RegisterDefaults(m.keymap)
+	m.updateContext()
+	return m
+}
+
+func TestAddBookmarkMsgFilesUnderProjectRoot(t *testing.T) {
+	m := bookmarksTestModel(t)
+	m.ui.WorkDir = "/tmp/alpha-wt"
+
+	m.Update(AddBookmarkMsg{Target: uirequest.Target{Kind: uirequest.TargetKindFile, Value: "main.go", Line: 7}})
+
+	list, err := state.ProjectBookmarks(config.StateDir(), "/tmp/alpha")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 {
+		t.Fatalf("project has %d bookmarks, want 1", len(list))
+	}
+	if list[0].WorkDir != "/tmp/alpha-wt" || list[0].CreatedBy != "user" {
+		t.Fatalf("bookmark = %+v", list[0])
+	}
+}
+
+func TestBookmarksPickerOpensNewestAndDeletes(t *testing.T) {
+	m := bookmarksTestModel(t)
+	dir := config.StateDir()
+	for _, v := range []string{"old.go", "new.go"} {
+		if _, err := state.AddBookmark(dir, state.Bookmark{Project: "/tmp/alpha", WorkDir: "/tmp/alpha", Target: uirequest.Target{Kind: uirequest.TargetKindFile, Value: v}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m.handleKeyMsg(tea.KeyPressMsg{Code: '\'', Text: "'"})
+	if m.activeModal() != ModalBookmarks {
+		t.Fatalf("' opened %v, want the bookmarks picker", m.activeModal())
+	}
+	if got := m.bookmarksItems[0].Target.Value; got != "new.go" {
+		t.Fatalf("first row = %q, want the newest bookmark", got)
+	}
+	// Keys reach the list through the focus its first render registers.
+	m.renderBookmarksModal("")
+
+	m.handleKeyMsg(tea.KeyPressMsg{Code: 'd', Text: "d"})
+	if len(m.bookmarksItems) != 1 || m.bookmarksItems[0].Target.Value != "old.go" {
+		t.Fatalf("after delete rows = %+v", m.bookmarksItems)
+	}
+	list, _ := state.ProjectBookmarks(dir, "/tmp/alpha")
+	if len(list) != 1 {
+		t.Fatalf("delete left %d bookmarks on disk, want 1", len(list))
+	}
+	m.renderBookmarksModal("")
+
+	_, cmd := m.handleKeyMsg(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if m.showBookmarks {
+		t.Fatal("enter should close the picker")
+	}
+	if cmd == nil {
+		t.Fatal("enter produced no activation")
+	}
+	act, ok := cmd().(ActivateTargetMsg)
+	if !ok || act.Target.Value != "old.go" || act.Project != "/tmp/alpha" {
+		t.Fatalf("activation = %#v", act)
+	}
+}
@@ -133,6 +133,19 @@ type ActivateTargetMsg struct {
 	Project string
 }
 
+// AddBookmarkMsg asks the app to bookmark a target in the current project.
+// Plugins send this rather than writing the bookmark file themselves, so a
+// bookmark is always filed under the project the user is looking at.
+type AddBookmarkMsg struct {
+	Target uirequest.Target
+	Label  string
+}
+
+// AddBookmark returns a command that bookmarks a target in the current project.
+func AddBookmark(target uirequest.Target, label string) tea.Cmd {
+	return func() tea.Msg { return AddBookmarkMsg{Target: target, Label: label} }
+}
+
 // OpenIssuePaneMsg, OpenDiffPaneMsg, OpenResourcePaneMsg and AttachSessionMsg
 // are the public entries for what used to be reachable only through
 // workspace-plugin-private methods. They are the surface-parity seam: any
@@ -365,6 +365,7 @@ func (m *Model) applyConfigSaved(msg configui.ConfigSavedMsg) tea.Cmd {
 		// Saving the config screen is the moment an edited expiry takes
 		// effect; notifications posted afterwards use the new value.
 		notify.ApplyConfig(cfg.Notifications)
+		m.notificationSinks = newNotificationSinks(cfg.Notifications)
 		m.showClock = cfg.UI.ShowClock
 		m.titleTemplate = cfg.UI.TerminalTitle
 		// Nerd Font glyphs are read from one package-level flag at startup;
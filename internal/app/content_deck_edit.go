@@ -7,7 +7,6 @@ import (
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/marcus/sidecar/internal/docview"
-	"github.com/marcus/sidecar/internal/features"
 	"github.com/marcus/sidecar/internal/inlineedit"
 	"github.com/marcus/sidecar/internal/mouse"
 	appmsg "github.com/marcus/sidecar/internal/msg"
@@ -130,8 +129,8 @@ func (m *Model) enterAppContentDocumentEdit() tea.Cmd {
 	if h == nil || h.appContentDocumentEditing() {
 		return nil
 	}
-	if !features.IsEnabled(features.TmuxInlineEdit.Name) {
-		return appmsg.ShowToast("Inline edit is disabled (features.tmux-inline-edit)", 3*time.Second)
+	if !inlineedit.Enabled() {
+		return appmsg.ShowToast(inlineedit.DisabledMessage, 3*time.Second)
 	}
 	leafID, view := h.activeDocumentForEdit()
 	if leafID == 0 || view == nil || view.Title() == "" {
@@ -155,7 +154,7 @@ func (m *Model) enterAppContentDocumentEdit() tea.Cmd {
 	if width <= 0 || height <= 0 {
 		return nil
 	}
-	return inlineedit.Start(inlineedit.StartOptions{
+	return inlineedit.StartPreferred(inlineedit.StartOptions{
 		Surface: h.appContentEditSurface(), LeafID: leafID,
 		AbsPath: abs, Path: rel, Line: view.TopSourceLine(),
 		Width: width, Height: height,
@@ -189,7 +188,7 @@ func (m *Model) applyAppContentEditStarted(msg inlineedit.StartedMsg) (tea.Cmd,
 		}
 		h.deck.FocusLeaf(msg.LeafID)
 		h.syncInnerFocus()
-		return session.Begin(msg.SessionName, msg.Editor, msg.Path), true
+		return session.BeginStarted(msg), true
 	}
 	return (tty.EditorSession{Name: msg.SessionName, Editor: msg.Editor}).KillCmd(), true
 }
@@ -387,8 +386,8 @@ func (h *appContentDeck) renderAppContentDocumentEdit(leafID int, size paneframe
 	body := ""
 	if session.ShowExitConfirm {
 		body = session.RenderExitConfirm()
-	} else if session.Model != nil {
-		body = session.Model.View()
+	} else {
+		body = session.View()
 	}
 	return header + "\n" + ui.FitBlock(body, size.Width, bodyH), true
 }
@@ -1,6 +1,8 @@
 package app
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -155,3 +157,51 @@ func TestAppContentEditConfirmationChangesRealSessionState(t *testing.T) {
 		t.Fatalf("cancel handled=%v still-open=%v", handled, session.ShowExitConfirm)
 	}
 }
+
+func TestAppContentEditNativeEditorSavesThroughDeck(t *testing.T) {
+	m, h, leafID := appDeckEditFixture(t)
+	cfg := config.Default()
+	cfg.Features.Flags = map[string]bool{features.PluginContentPanes.Name: true, features.NativeInlineEdit.Name: true}
+	features.Init(cfg)
+	t.Cleanup(func() { features.Init(config.Default()) })
+
+	_, view := h.activeDocumentForEdit()
+	root := view.Root()
+	if root == "" {
+		root = h.workdir
+	}
+	path := filepath.Join(root, "README.md")
+	if err := os.WriteFile(path, []byte("hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	start := m.enterAppContentDocumentEdit()
+	if start == nil {
+		t.Fatal("native edit returned no start command")
+	}
+	started, ok := start().(inlineedit.StartedMsg)
+	if !ok || started.Native == nil || started.SessionName != "" {
+		t.Fatalf("start result = %#v", started)
+	}
+	if _, handled := m.applyAppContentEditStarted(started); !handled {
+		t.Fatal("native start was not handled")
+	}
+	session := h.appContentDocumentEdit(false).editor()
+	if !session.Active || session.Native == nil || !h.appContentDocumentEditing() {
+		t.Fatalf("native session active=%v native=%v", session.Active, session.Native != nil)
+	}
+	if frame, ok := h.renderAppContentDocumentEdit(leafID, paneframe.Size{Width: 50, Height: 10}); !ok || !strings.Contains(frame, "ello") {
+		t.Fatalf("native render = %q ok=%v", frame, ok)
+	}
+
+	m.handleAppContentEditKey(tea.KeyPressMsg{Code: '!', Text: "!"})
+	m.handleAppContentEditKey(tea.KeyPressMsg{Code: 's', Mod: tea.ModCtrl})
+	data, _ := os.ReadFile(path)
+	if string(data) != "!hello\n" {
+		t.Fatalf("saved %q", data)
+	}
+	m.handleAppContentEditKey(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if session.Active || h.appContentDocumentEditing() {
+		t.Fatal("esc on a saved buffer did not leave the editor")
+	}
+}
@@ -0,0 +1,646 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/controlapi"
+	"github.com/marcus/sidecar/internal/features"
+	"github.com/marcus/sidecar/internal/gitstate"
+	"github.com/marcus/sidecar/internal/notify"
+	"github.com/marcus/sidecar/internal/projectdir"
+	"github.com/marcus/sidecar/internal/shellstate"
+	"github.com/marcus/sidecar/internal/uirequest"
+	"github.com/marcus/sidecar/internal/workspaceops"
+)
+
+// The control API's actions are the request bus's actions: a call builds the
+// same uirequest.Request `sidecar open`, `sidecar notify` or `sidecar create`
+// would write, and injects it into this instance's watcher instead of writing
+// a file. The request then takes exactly the path a CLI request takes — the
+// app, the overview and the plugins answer it and ack it — so the API cannot
+// drift from the CLI, and nothing here touches the Model. Handlers run on the
+// server's goroutines; what they need of the app is the project it is
+// showing, which is copied in here whenever the instance announces itself.
+
+const (
+	// controlAPIWait is how long an action waits for a surface to answer
+	// when the caller does not say.
+	controlAPIWait = 1200 * time.Millisecond
+	// controlAPISettle is how long an action keeps listening after a surface
+	// has queued or declined it, for another surface that may yet open it.
+	controlAPISettle = 150 * time.Millisecond
+)
+
+// controlAPI is the running server and what its handlers know of the app.
+type controlAPI struct {
+	srv       *controlapi.Server
+	watcher   *uirequest.Watcher
+	startOnce sync.Once
+
+	mu         sync.Mutex
+	root       string
+	projectKey string
+	// pluginPanes is the workspace plugin's share of the panes topic; the
+	// app's own decks are added to it on every publish.
+	pluginPanes []controlapi.PaneSurface
+}
+
+// newControlAPI opens the socket when the control_api feature is on. It needs
+// the request watcher to deliver anything, so without one there is no API.
+func newControlAPI(watcher *uirequest.Watcher) *controlAPI {
+	if watcher == nil || !features.IsEnabled(features.ControlAPI.Name) {
+		return nil
+	}
+	api, err := listenControlAPI(controlapi.SocketPath(config.StateDir(), os.Getpid()), watcher)
+	if err != nil {
+		slog.Warn("control api: listen failed", "err", err)
+		return nil
+	}
+	return api
+}
+
+func listenControlAPI(path string, watcher *uirequest.Watcher) (*controlAPI, error) {
+	srv, err := controlapi.Listen(path)
+	if err != nil {
+		return nil, err
+	}
+	api := &controlAPI{srv: srv, watcher: watcher}
+	srv.Handle("open", api.handleOpen)
+	srv.Handle("notify.post", api.handleNotifyPost)
+	srv.Handle("notify.dismiss", api.handleNotifyDismiss)
+	srv.Handle("create", api.handleCreate)
+	return api, nil
+}
+
+func (a *controlAPI) start() {
+	if a == nil {
+		return
+	}
+	a.startOnce.Do(func() {
+		go func() {
+			if err := a.srv.Serve(); !errors.Is(err, net.ErrClosed) {
+				slog.Warn("control api: serve stopped", "err", err)
+			}
+		}()
+	})
+}
+
+func (a *controlAPI) close() {
+	if a == nil {
+		return
+	}
+	_ = a.srv.Close()
+}
+
+// socket is the path announced in the instance file.
+func (a *controlAPI) socket() string {
+	if a == nil {
+		return ""
+	}
+	return a.srv.Path()
+}
+
+func (a *controlAPI) setProject(root, projectKey string) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	a.root, a.projectKey = root, projectKey
+	a.mu.Unlock()
+}
+
+// project is the project the instance is showing, or Unavailable when it is
+// showing none: every action is addressed to a project.
+func (a *controlAPI) project() (root, projectKey string, err error) {
+	a.mu.Lock()
+	root, projectKey = a.root, a.projectKey
+	a.mu.Unlock()
+	if root == "" {
+		return "", "", controlapi.Unavailable("this instance has no project open")
+	}
+	return root, projectKey, nil
+}
+
+// publishPanes publishes the app's decks together with the plugin's.
+func (a *controlAPI) publishPanes(decks map[string]*appContentDeck) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	surfaces := append([]controlapi.PaneSurface(nil), a.pluginPanes...)
+	a.mu.Unlock()
+	for _, h := range decks {
+		if h == nil || h.deck == nil {
+			continue
+		}
+		if surface, open := controlapi.NewPaneSurface("plugin:"+h.pluginID, h.workdir, h.deck.Encode()); open {
+			surfaces = append(surfaces, surface)
+		}
+	}
+	sort.Slice(surfaces, func(i, j int) bool {
+		if surfaces[i].Surface != surfaces[j].Surface {
+			return surfaces[i].Surface < surfaces[j].Surface
+		}
+		return surfaces[i].WorkDir < surfaces[j].WorkDir
+	})
+	if surfaces == nil {
+		surfaces = []controlapi.PaneSurface{}
+	}
+	a.srv.Publish(controlapi.TopicPanes, controlapi.PanesState{Surfaces: surfaces})
+}
+
+// applyPublish handles a plugin's PublishMsg.
+func (a *controlAPI) applyPublish(msg controlapi.PublishMsg, decks map[string]*appContentDeck) {
+	if a == nil {
+		return
+	}
+	if msg.Topic == controlapi.TopicPanes {
+		surfaces, _ := msg.Data.([]controlapi.PaneSurface)
+		a.mu.Lock()
+		a.pluginPanes = surfaces
+		a.mu.Unlock()
+		a.publishPanes(decks)
+		return
+	}
+	a.srv.Publish(msg.Topic, msg.Data)
+}
+
+// publishNotifications publishes the centre: everything not dismissed.
+func (a *controlAPI) publishNotifications(all []notify.Notification) {
+	if a == nil {
+		return
+	}
+	items := notify.Active(all)
+	if items == nil {
+		items = []notify.Notification{}
+	}
+	a.srv.Publish(controlapi.TopicNotifications, controlapi.NotificationsState{
+		Unread: notify.UnreadCount(all),
+		Items:  items,
+	})
+}
+
+// publishGit publishes a git status snapshot. Snapshots of other checkouts —
+// a worktree the workspace plugin is previewing — are not this project's
+// status and are skipped.
+func (a *controlAPI) publishGit(s *gitstate.Snapshot, workDir string) {
+	if a == nil || s == nil || !s.Covers(workDir) {
+		return
+	}
+	state := controlapi.GitState{Root: s.Root, Files: []controlapi.GitFile{}}
+	for _, path := range s.Paths() {
+		rel, err := filepath.Rel(s.Root, path)
+		if err != nil {
+			continue
+		}
+		flags := s.File(path)
+		state.Files = append(state.Files, controlapi.GitFile{Path: filepath.ToSlash(rel), States: flags.Names()})
+		if flags&gitstate.Modified != 0 {
+			state.Counts.Modified++
+		}
+		if flags&gitstate.Staged != 0 {
+			state.Counts.Staged++
+		}
+		if flags&gitstate.Untracked != 0 {
+			state.Counts.Untracked++
+		}
+		if flags&gitstate.Conflicted != 0 {
+			state.Counts.Conflicted++
+		}
+	}
+	a.srv.Publish(controlapi.TopicGit, state)
+}
+
+// apiOrigin is who an action is on behalf of. By default that is the project
+// itself; a client running inside a Sidecar shell names its session so an
+// open lands beside it and a notification is attributed to it, exactly as
+// the CLI run from that shell would be.
+type apiOrigin struct {
+	Session string `json:"session,omitempty"`
+	WorkDir string `json:"workDir,omitempty"`
+}
+
+func (a *controlAPI) requestOrigin(o *apiOrigin) (uirequest.Origin, error) {
+	root, key, err := a.project()
+	if err != nil {
+		return uirequest.Origin{}, err
+	}
+	origin := uirequest.Origin{ProjectKey: key, WorkDir: root, PID: os.Getpid()}
+	if o != nil {
+		origin.TmuxSession = strings.TrimSpace(o.Session)
+		if wd := strings.TrimSpace(o.WorkDir); wd != "" {
+			origin.WorkDir = wd
+		}
+	}
+	return origin, nil
+}
+
+// actionResult is what every action returns. A surface that declines is an
+// answer, not a failure, so it is reported here rather than as an error.
+type actionResult struct {
+	ID string `json:"id"`
+	// Status is the best answer any surface gave — opened, retargeted,
+	// queued, declined or error — or "unanswered" when none did in time.
+	Status  uirequest.Status `json:"status"`
+	Reason  string           `json:"reason,omitempty"`
+	Surface string           `json:"surface,omitempty"`
+	Acks    []uirequest.Ack  `json:"acks"`
+}
+
+const statusUnanswered uirequest.Status = "unanswered"
+
+// statusRank orders answers so the most useful one is reported.
+func statusRank(s uirequest.Status) int {
+	switch s {
+	case uirequest.StatusOpened:
+		return 5
+	case uirequest.StatusRetargeted:
+		return 4
+	case uirequest.StatusQueued:
+		return 3
+	case uirequest.StatusError:
+		return 2
+	case uirequest.StatusDeclined:
+		return 1
+	}
+	return 0
+}
+
+// deliver injects req and collects its acks. It returns as soon as a surface
+// opens it; otherwise it listens a moment past the first answer, or until
+// wait runs out.
+func (a *controlAPI) deliver(ctx context.Context, req uirequest.Request, wait time.Duration) (actionResult, error) {
+	if wait <= 0 {
+		wait = controlAPIWait
+	}
+	req.Version = 1
+	req.ID = uirequest.NewRequestID()
+	req.CreatedAt = time.Now().UTC()
+	req.TTLMs = int(uirequest.DefaultTTL / time.Millisecond)
+
+	acks, release := uirequest.AwaitAcks(req.ID)
+	defer release()
+	if !a.watcher.Inject(req) {
+		return actionResult{}, controlapi.Unavailable("the instance is not accepting requests")
+	}
+
+	result := actionResult{ID: req.ID, Status: statusUnanswered, Acks: []uirequest.Ack{}}
+	deadline := time.NewTimer(wait)
+	defer deadline.Stop()
+	var settle <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return actionResult{}, ctx.Err()
+		case <-deadline.C:
+			return result, nil
+		case <-settle:
+			return result, nil
+		case ack := <-acks:
+			result.Acks = append(result.Acks, ack)
+			if statusRank(ack.Status) > statusRank(result.Status) {
+				result.Status, result.Reason, result.Surface = ack.Status, ack.Reason, ack.Surface
+			}
+			if ack.Status == uirequest.StatusOpened || ack.Status == uirequest.StatusRetargeted {
+				return result, nil
+			}
+			if settle == nil {
+				settle = time.After(controlAPISettle)
+			}
+		}
+	}
+}
+
+type openParams struct {
+	Target   string     `json:"target"`
+	Line     int        `json:"line,omitempty"`
+	Diff     bool       `json:"diff,omitempty"`
+	Provider string     `json:"provider,omitempty"`
+	Split    string     `json:"split,omitempty"`
+	Wait     int        `json:"wait,omitempty"`
+	Origin   *apiOrigin `json:"origin,omitempty"`
+}
+
+func (a *controlAPI) handleOpen(ctx context.Context, raw json.RawMessage) (any, error) {
+	var params openParams
+	if err := controlapi.DecodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+	switch params.Split {
+	case "", "auto", "right", "below":
+	default:
+		return nil, controlapi.InvalidParams("split must be auto, right or below")
+	}
+	origin, err := a.requestOrigin(params.Origin)
+	if err != nil {
+		return nil, err
+	}
+	// Relative paths resolve against the origin's directory, which is the
+	// project root unless the caller named its own.
+	target, err := uirequest.ResolveTarget(origin.WorkDir, params.Target, params.Line, uirequest.ResolveOptions{
+		Diff:     params.Diff,
+		Provider: params.Provider,
+	})
+	if err != nil {
+		return nil, controlapi.InvalidParams("%v", err)
+	}
+	return a.deliver(ctx, uirequest.Request{
+		Origin:  origin,
+		Action:  uirequest.ActionOpen,
+		Target:  target,
+		Options: uirequest.Options{Split: params.Split},
+	}, time.Duration(params.Wait)*time.Millisecond)
+}
+
+type notifyPostParams struct {
+	Title   string     `json:"title"`
+	Body    string     `json:"body,omitempty"`
+	Source  string     `json:"source,omitempty"`
+	Targets []string   `json:"targets,omitempty"`
+	Actions []string   `json:"actions,omitempty"`
+	Expiry  string     `json:"expiry,omitempty"`
+	Origin  *apiOrigin `json:"origin,omitempty"`
+}
+
+// notifyPostResult is an action result plus the notification as filed.
+type notifyPostResult struct {
+	actionResult
+	Notification notify.Notification `json:"notification"`
+}
+
+func (a *controlAPI) handleNotifyPost(ctx context.Context, raw json.RawMessage) (any, error) {
+	var params notifyPostParams
+	if err := controlapi.DecodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+	title := strings.TrimSpace(params.Title)
+	if title == "" {
+		return nil, controlapi.InvalidParams("title is required")
+	}
+	source := params.Source
+	if source == "" {
+		source = string(notify.SourceAgent)
+	}
+	if !notify.ValidSource(notify.SourceID(source)) {
+		// Registered with `sidecar notify source add` since this instance
+		// read its config, perhaps.
+		if cfg, err := config.Load(); err == nil {
+			notify.ApplyConfig(cfg.Notifications)
+		}
+		if !notify.ValidSource(notify.SourceID(source)) {
+			return nil, controlapi.InvalidParams("unknown source %q (one of: %s)", source, strings.Join(notify.SourceIDs(), ", "))
+		}
+	}
+	targets, err := notify.ParseTargetSpecs(params.Targets)
+	if err != nil {
+		return nil, controlapi.InvalidParams("%v", err)
+	}
+	var actions []notify.Action
+	for _, name := range params.Actions {
+		kind, err := notify.ParseActionKind(name)
+		if err != nil {
+			return nil, controlapi.InvalidParams("%v", err)
+		}
+		actions = append(actions, notify.Action{Kind: kind})
+	}
+	origin, err := a.requestOrigin(params.Origin)
+	if err != nil {
+		return nil, err
+	}
+
+	n := notify.Notification{
+		ID:      notify.NewID(),
+		Source:  notify.SourceID(source),
+		Title:   title,
+		Body:    params.Body,
+		Origin:  notifyOriginFrom(origin),
+		Targets: targets,
+		Actions: actions,
+	}
+	switch strings.TrimSpace(strings.ToLower(params.Expiry)) {
+	case "":
+	case "0", "never", "sticky":
+		n.Sticky = true
+	default:
+		d, err := time.ParseDuration(params.Expiry)
+		if err != nil || d < 0 {
+			return nil, controlapi.InvalidParams("invalid expiry %q (a duration such as 10s, or \"never\")", params.Expiry)
+		}
+		exp := time.Now().UTC().Add(d)
+		n.ExpiresAt = &exp
+	}
+	n = notify.Normalize(n, time.Now())
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return nil, err
+	}
+	result, err := a.deliver(ctx, uirequest.Request{
+		Origin:  origin,
+		Action:  uirequest.ActionNotify,
+		Target:  uirequest.Target{Kind: uirequest.TargetKindNotification},
+		Payload: payload,
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	return notifyPostResult{actionResult: result, Notification: n}, nil
+}
+
+type notifyDismissParams struct {
+	ID     string     `json:"id"`
+	Origin *apiOrigin `json:"origin,omitempty"`
+}
+
+// handleNotifyDismiss leaves the origin check to the app, which applies it to
+// every dismissal whoever asks: a caller may only dismiss what it posted.
+func (a *controlAPI) handleNotifyDismiss(ctx context.Context, raw json.RawMessage) (any, error) {
+	var params notifyDismissParams
+	if err := controlapi.DecodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(params.ID) == "" {
+		return nil, controlapi.InvalidParams("id is required")
+	}
+	origin, err := a.requestOrigin(params.Origin)
+	if err != nil {
+		return nil, err
+	}
+	return a.deliver(ctx, uirequest.Request{
+		Origin: origin,
+		Action: uirequest.ActionNotify,
+		Target: uirequest.Target{Kind: uirequest.TargetKindNotification, Value: strings.TrimSpace(params.ID)},
+	}, 0)
+}
+
+type createParams struct {
+	Kind            string     `json:"kind"`
+	Name            string     `json:"name,omitempty"`
+	Run             string     `json:"run,omitempty"`
+	Type            string     `json:"type,omitempty"`
+	Base            string     `json:"base,omitempty"`
+	Agent           string     `json:"agent,omitempty"`
+	SkipPermissions bool       `json:"skipPermissions,omitempty"`
+	NoLaunch        bool       `json:"noLaunch,omitempty"`
+	Wait            int        `json:"wait,omitempty"`
+	Origin          *apiOrigin `json:"origin,omitempty"`
+}
+
+// createResult is an action result plus what was made. Setup that failed is
+// listed rather than failing the call: the worktree exists either way, as
+// with `sidecar create worktree`.
+type createResult struct {
+	actionResult
+	Workspace controlapi.Workspace `json:"workspace"`
+	Setup     []string             `json:"setupFailures,omitempty"`
+	Warning   string               `json:"warning,omitempty"`
+}
+
+func (a *controlAPI) handleCreate(ctx context.Context, raw json.RawMessage) (any, error) {
+	var params createParams
+	if err := controlapi.DecodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+	if params.Run != "" && params.Type != "" {
+		return nil, controlapi.InvalidParams("run and type are mutually exclusive")
+	}
+	root, _, err := a.project()
+	if err != nil {
+		return nil, err
+	}
+	origin, err := a.requestOrigin(params.Origin)
+	if err != nil {
+		return nil, err
+	}
+	wait := time.Duration(params.Wait) * time.Millisecond
+	focus := true
+
+	switch params.Kind {
+	case uirequest.CreateKindShell:
+		if params.Base != "" || params.Agent != "" || params.NoLaunch || params.SkipPermissions {
+			return nil, controlapi.InvalidParams("base, agent, skipPermissions and noLaunch apply to worktrees")
+		}
+		name := ""
+		if custom := strings.TrimSpace(params.Name); custom != "" {
+			if name, err = shellstate.NormalizeName(custom); err != nil {
+				return nil, controlapi.InvalidParams("%v", err)
+			}
+		}
+		var existing []shellstate.Definition
+		if dir, ok := projectdir.Lookup(root); ok {
+			existing, _ = shellstate.ListAtPath(filepath.Join(dir, "shells.json"))
+		}
+		created, err := workspaceops.CreateShellWorkspace(ctx, workspaceops.ShellWorkspaceSpec{
+			ProjectRoot: root,
+			Existing:    existing,
+			Name:        name,
+			Run:         params.Run,
+			Type:        params.Type,
+		})
+		if err != nil {
+			return nil, err
+		}
+		payload, _ := json.Marshal(uirequest.CreatePayload{
+			Kind:        uirequest.CreateKindShell,
+			Session:     created.Session,
+			DisplayName: created.DisplayName,
+			Focus:       &focus,
+		})
+		result, err := a.deliver(ctx, uirequest.Request{
+			Origin:  origin,
+			Action:  uirequest.ActionCreate,
+			Target:  uirequest.Target{Kind: uirequest.TargetKindShell, Value: created.Session},
+			Payload: payload,
+		}, wait)
+		if err != nil {
+			return nil, err
+		}
+		out := createResult{
+			actionResult: result,
+			Workspace:    controlapi.Workspace{Kind: "shell", Name: created.DisplayName, Path: root, Session: created.Session},
+		}
+		if created.SeedErr != nil {
+			out.Warning = created.SeedErr.Error()
+		}
+		return out, nil
+
+	case uirequest.CreateKindWorktree:
+		if strings.TrimSpace(params.Name) == "" {
+			return nil, controlapi.InvalidParams("a worktree needs a name")
+		}
+		cfg, err := config.Load()
+		if err != nil || cfg == nil {
+			cfg = config.Default()
+		}
+		created, err := workspaceops.CreateWorktreeWorkspace(ctx, workspaceops.WorktreeWorkspaceSpec{
+			WorkDir:     origin.WorkDir,
+			ProjectRoot: root,
+			Name:        params.Name,
+			Base:        params.Base,
+			DirPrefix:   cfg.Plugins.Workspace.DirPrefix,
+			Setup:       cfg.WorktreeSetupForProject(root),
+			AgentStart:  cfg.Plugins.Workspace.AgentStart,
+			Agent:       params.Agent,
+			SkipPerms:   params.SkipPermissions,
+			Run:         params.Run,
+			NoLaunch:    params.NoLaunch,
+			OperationID: "api-" + uirequest.NewRequestID(),
+		})
+		if err != nil {
+			var planErr *workspaceops.PlanError
+			if errors.As(err, &planErr) {
+				return nil, controlapi.InvalidParams("%v", planErr.Err)
+			}
+			return nil, err
+		}
+		record := created.Record
+		payload, _ := json.Marshal(uirequest.CreatePayload{
+			Kind:        uirequest.CreateKindWorktree,
+			Session:     created.Session,
+			DisplayName: record.Name,
+			Focus:       &focus,
+			Path:        record.Path,
+			Branch:      record.Branch,
+		})
+		result, err := a.deliver(ctx, uirequest.Request{
+			Origin:  origin,
+			Action:  uirequest.ActionCreate,
+			Target:  uirequest.Target{Kind: uirequest.TargetKindWorktree, Value: record.Path},
+			Payload: payload,
+		}, wait)
+		if err != nil {
+			return nil, err
+		}
+		out := createResult{
+			actionResult: result,
+			Workspace: controlapi.Workspace{
+				Kind:    "worktree",
+				Name:    record.Name,
+				Path:    record.Path,
+				Branch:  record.Branch,
+				Session: created.Session,
+				Agent:   params.Agent,
+			},
+		}
+		for _, failed := range workspaceops.FailedSetup(created.Outcomes, false) {
+			out.Setup = append(out.Setup, failed.Action+": "+failed.Err.Error())
+		}
+		if created.LaunchErr != nil {
+			out.Warning = created.LaunchErr.Error()
+		}
+		return out, nil
+
+	default:
+		return nil, controlapi.InvalidParams("kind must be %q or %q", uirequest.CreateKindShell, uirequest.CreateKindWorktree)
+	}
+}
@@ -0,0 +1,148 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/marcus/sidecar/internal/controlapi"
+	"github.com/marcus/sidecar/internal/uirequest"
+)
+
+// controlAPIModel is a notification-centre model whose API requests are
+// answered by a goroutine standing in for the update loop. Only that goroutine
+// touches the model once the test starts calling.
+func controlAPIModel(t *testing.T) (*Model, *controlapi.Client) {
+	t.Helper()
+	t.Setenv("SIDECAR_ISOLATED_STATE", "1")
+	root := t.TempDir()
+	watcher, err := uirequest.NewWatcher(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	watcher.Start()
+	t.Cleanup(watcher.Stop)
+
+	sockDir, err := os.MkdirTemp("", "scapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(sockDir) })
+	api, err := listenControlAPI(controlapi.SocketPath(sockDir, 1), watcher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api.start()
+	t.Cleanup(api.close)
+	api.setProject(root, "p1")
+
+	m := notifyModel()
+	m.ui.WorkDir, m.ui.ProjectRoot = root, root
+	m.controlAPI = api
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-watcher.Messages():
+				if req, ok := msg.(uirequest.RequestMsg); ok {
+					m.handleNotifyRequest(req.Request)
+				}
+			}
+		}
+	}()
+
+	dialCtx, dialCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer dialCancel()
+	client, err := controlapi.Dial(dialCtx, api.socket())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return m, client
+}
+
+func TestControlAPIPostsAndDismissesThroughTheRequestPath(t *testing.T) {
+	_, client := controlAPIModel(t)
+	ctx := context.Background()
+
+	if _, err := client.Subscribe(ctx, controlapi.TopicNotifications); err != nil {
+		t.Fatal(err)
+	}
+
+	var posted notifyPostResult
+	if err := client.Call(ctx, "notify.post", map[string]any{"title": "build finished", "expiry": "never"}, &posted); err != nil {
+		t.Fatal(err)
+	}
+	if posted.Status != uirequest.StatusOpened || posted.Notification.ID == "" {
+		t.Fatalf("post = %+v", posted)
+	}
+
+	deadline := time.After(3 * time.Second)
+	for found := false; !found; {
+		select {
+		case ev := <-client.Events():
+			var state controlapi.NotificationsState
+			if err := json.Unmarshal(ev.Data, &state); err != nil {
+				t.Fatal(err)
+			}
+			for _, n := range state.Items {
+				found = found || n.ID == posted.Notification.ID
+			}
+		case <-deadline:
+			t.Fatal("the posted notification was never published")
+		}
+	}
+
+	// Another caller may not dismiss it; the poster may.
+	var result actionResult
+	stranger := map[string]any{"id": posted.Notification.ID, "origin": map[string]string{"session": "sidecar-sh-other"}}
+	if err := client.Call(ctx, "notify.dismiss", stranger, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != uirequest.StatusDeclined {
+		t.Fatalf("stranger's dismiss = %+v, want declined", result)
+	}
+	if err := client.Call(ctx, "notify.dismiss", map[string]any{"id": posted.Notification.ID}, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != uirequest.StatusOpened {
+		t.Fatalf("poster's dismiss = %+v, want opened", result)
+	}
+}
+
+func TestControlAPIRejectsWhatTheCLIWould(t *testing.T) {
+	m, client := controlAPIModel(t)
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		method string
+		params map[string]any
+	}{
+		{"notify.post", map[string]any{"title": " "}},
+		{"notify.post", map[string]any{"title": "x", "source": "nope"}},
+		{"notify.post", map[string]any{"title": "x", "actions": []string{"launch"}}},
+		{"open", map[string]any{"target": "main.go", "split": "sideways"}},
+		{"create", map[string]any{"kind": "tab"}},
+		{"create", map[string]any{"kind": "shell", "run": "a", "type": "b"}},
+	} {
+		err := client.Call(ctx, tc.method, tc.params, nil)
+		var rpcErr *controlapi.Error
+		if !errors.As(err, &rpcErr) || rpcErr.Code != controlapi.CodeInvalidParams {
+			t.Errorf("%s %v = %v, want invalid params", tc.method, tc.params, err)
+		}
+	}
+
+	m.controlAPI.setProject("", "")
+	err := client.Call(ctx, "notify.post", map[string]any{"title": "x"}, nil)
+	var rpcErr *controlapi.Error
+	if !errors.As(err, &rpcErr) || rpcErr.Code != controlapi.CodeUnavailable {
+		t.Fatalf("post with no project = %v, want unavailable", err)
+	}
+}
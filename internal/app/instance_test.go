@@ -19,7 +19,7 @@ func TestAnnounceInstanceCmdWritesPresence(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	cmd := announceInstanceCmd(workDir, workDir)
+	cmd := announceInstanceCmd(workDir, workDir, nil)
 	if cmd == nil {
 		t.Fatal("expected announce cmd")
 	}
@@ -56,7 +56,7 @@ func TestAnnounceInstanceCmdOverwritesOnProjectSwitch(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if msg := announceInstanceCmd(projectA, projectA)(); msg != nil {
+	if msg := announceInstanceCmd(projectA, projectA, nil)(); msg != nil {
 		t.Fatalf("announce A returned %v", msg)
 	}
 	live, err := uirequest.ListInstances(config.StateDir())
@@ -71,7 +71,7 @@ func TestAnnounceInstanceCmdOverwritesOnProjectSwitch(t *testing.T) {
 		t.Fatal("expected projectKey for A")
 	}
 
-	if msg := announceInstanceCmd(projectB, projectB)(); msg != nil {
+	if msg := announceInstanceCmd(projectB, projectB, nil)(); msg != nil {
 		t.Fatalf("announce B returned %v", msg)
 	}
 	live, err = uirequest.ListInstances(config.StateDir())
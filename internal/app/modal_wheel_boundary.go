@@ -67,11 +67,20 @@ func (m *Model) activeModalWheelAtBoundary(msg tea.MouseWheelMsg) bool {
 	case ModalOpenIn:
 		return modalWheelAtBoundary(m.openInModal, m.openInMouseHandler, msg)
 
+	case ModalBookmarks:
+		return modalWheelAtBoundary(m.bookmarksModal, m.bookmarksMouseHandler, msg)
+
 	case ModalIssueInput:
 		return modalWheelAtBoundary(m.issueInputModal, m.issueInputMouseHandler, msg)
 
 	case ModalIssuePreview:
 		return m.issuePreviewWheelAtBoundary(msg)
+
+	case ModalAgentMessage:
+		return modalWheelAtBoundary(m.agentMessage.modal, m.agentMessage.mouseHandler, msg)
+
+	case ModalScriptPrompt:
+		return modalWheelAtBoundary(m.scriptPrompt.modal, m.scriptPrompt.mouseHandler, msg)
 	}
 	return false
 }
@@ -327,6 +327,16 @@ func TestActiveModalWheelAtBoundaryLedger(t *testing.T) {
 			},
 			want: want{up: true, down: true},
 		},
+		{
+			name: "bookmarks picker",
+			setup: func(t *testing.T, m *Model) (int, int) {
+				m.showBookmarks = true
+				md, h := renderedModal(m.width, m.height, "main.go:12\ntd-4c1f9a")
+				m.bookmarksModal, m.bookmarksMouseHandler = md, h
+				return modalBodyPoint(t, h)
+			},
+			want: want{up: true, down: true},
+		},
 		{
 			name: "issue lookup with results that overflow",
 			setup: func(t *testing.T, m *Model) (int, int) {
@@ -409,6 +419,24 @@ func TestActiveModalWheelAtBoundaryLedger(t *testing.T) {
 			},
 			want: want{up: true, down: true},
 		},
+		{
+			name: "agent message is bounded in both directions",
+			setup: func(t *testing.T, m *Model) (int, int) {
+				md, h := renderedModal(m.width, m.height, "Message sidecar-ws-auth")
+				m.agentMessage = &agentMessageState{modal: md, mouseHandler: h}
+				return modalBodyPoint(t, h)
+			},
+			want: want{up: true, down: true},
+		},
+		{
+			name: "script prompt is bounded in both directions",
+			setup: func(t *testing.T, m *Model) (int, int) {
+				md, h := renderedModal(m.width, m.height, "Commit all worktrees")
+				m.scriptPrompt = &scriptPromptState{modal: md, mouseHandler: h}
+				return modalBodyPoint(t, h)
+			},
+			want: want{up: true, down: true},
+		},
 	}
 
 	for _, tt := range tests {
@@ -466,10 +494,13 @@ func TestEveryModalKindHasALedgerRow(t *testing.T) {
 		ModalWorktreeSwitcher: "worktree switcher body at top",
 		ModalThemeSwitcher:    "theme switcher body at top",
 		ModalOpenIn:           "open in picker",
+		ModalBookmarks:        "bookmarks picker",
 		ModalIssueInput:       "issue lookup with results that overflow",
 		ModalIssuePreview:     "issue preview long card at top",
+		ModalAgentMessage:     "agent message is bounded in both directions",
+		ModalScriptPrompt:     "script prompt is bounded in both directions",
 	}
-	for kind := ModalPalette; kind <= ModalIssuePreview; kind++ {
+	for kind := ModalPalette; kind <= ModalScriptPrompt; kind++ {
 		if _, ok := covered[kind]; !ok {
 			t.Errorf("ModalKind %d has no boundary ledger row", kind)
 		}
@@ -22,10 +22,12 @@ import (
 	"github.com/marcus/sidecar/internal/mouse"
 	"github.com/marcus/sidecar/internal/msg"
 	"github.com/marcus/sidecar/internal/notify"
+	"github.com/marcus/sidecar/internal/notifysink"
 	"github.com/marcus/sidecar/internal/overview"
 	"github.com/marcus/sidecar/internal/palette"
 	"github.com/marcus/sidecar/internal/plugin"
 	"github.com/marcus/sidecar/internal/projectdir"
+	"github.com/marcus/sidecar/internal/scripting"
 	"github.com/marcus/sidecar/internal/state"
 	"github.com/marcus/sidecar/internal/styles"
 	"github.com/marcus/sidecar/internal/theme"
@@ -50,8 +52,11 @@ const (
 	ModalWorktreeSwitcher                  // Worktree switcher
 	ModalThemeSwitcher                     // Theme switcher
 	ModalOpenIn                            // Open In IDE picker
+	ModalBookmarks                         // Bookmarks picker
 	ModalIssueInput                        // Issue ID text input
-	ModalIssuePreview                      // Issue preview display (lowest priority)
+	ModalIssuePreview                      // Issue preview display
+	ModalAgentMessage                      // Message to a waiting agent
+	ModalScriptPrompt                      // Text for a script command (lowest priority)
 )
 
 // activeModal returns the highest-priority open modal.
@@ -76,10 +81,16 @@ func (m *Model) activeModal() ModalKind {
 		return ModalThemeSwitcher
 	case m.showOpenIn:
 		return ModalOpenIn
+	case m.showBookmarks:
+		return ModalBookmarks
 	case m.showIssueInput:
 		return ModalIssueInput
 	case m.showIssuePreview:
 		return ModalIssuePreview
+	case m.agentMessage != nil:
+		return ModalAgentMessage
+	case m.scriptPrompt != nil:
+		return ModalScriptPrompt
 	default:
 		return ModalNone
 	}
@@ -113,10 +124,16 @@ func modalFocusContext(kind ModalKind) (string, bool) {
 		return "theme-switcher", true
 	case ModalOpenIn:
 		return "open-in", true
+	case ModalBookmarks:
+		return "bookmarks", true
 	case ModalIssueInput:
 		return "issue-input", true
 	case ModalIssuePreview:
 		return "issue-preview", true
+	case ModalAgentMessage:
+		return "agent-message", true
+	case ModalScriptPrompt:
+		return "script-prompt", true
 	}
 	return "", false
 }
@@ -241,6 +258,16 @@ type Model struct {
 	openInModalWidth   int
 	openInMouseHandler *mouse.Handler
 
+	// Bookmarks picker
+	showBookmarks         bool
+	bookmarksItems        []state.Bookmark
+	bookmarksCursor       int
+	bookmarksScroll       int
+	bookmarksErr          string
+	bookmarksModal        *modal.Modal
+	bookmarksModalWidth   int
+	bookmarksMouseHandler *mouse.Handler
+
 	// Theme switcher modal
 	showThemeSwitcher         bool
 	themeSwitcherModal        *modal.Modal
@@ -380,12 +407,28 @@ type Model struct {
 	// value, so anything it assigns is discarded, and a cached-and-nil channel
 	// silently stops the listener re-arming after the first request.
 	uiRequestWatcher *uirequest.Watcher
+	// controlAPI is the local JSON-RPC API (control_api feature), nil when
+	// off. It delivers through uiRequestWatcher and publishes what the app
+	// already tracks; see control_api.go.
+	controlAPI *controlAPI
 
 	// Notification store and its render-side snapshot. The store is app-shell
 	// state, like the header: it outlives every plugin, survives project and
 	// worktree switches, and is the single writer for this process.
 	notifications     notify.Store
 	notificationCache []notify.Notification
+	// notificationSinks deliver what this instance files beyond its own
+	// terminal. Rebuilt whenever the config is saved.
+	notificationSinks *notifysink.Dispatcher
+	// agentMessage is the open "send message…" prompt a waiting notification's
+	// quick action raises, or nil.
+	agentMessage *agentMessageState
+	// scripts runs the user's Starlark scripts (scripting feature), nil when
+	// off; scriptLoadErrors are the scripts that failed, reported after the
+	// first frame. scriptPrompt is a script command's open text prompt.
+	scripts          *scripting.Engine
+	scriptLoadErrors []error
+	scriptPrompt     *scriptPromptState
 	// notificationCTAs memoizes each notification's reconciled target list by
 	// id, so the file-existence check behind a verified underline runs once per
 	// record rather than once per frame. See notification_targets.go.
@@ -511,12 +554,15 @@ func New(reg *plugin.Registry, km *keymap.Registry, cfg *config.Config, currentV
 	if watcher, err := uirequest.NewWatcher(config.StateDir()); err == nil {
 		m.uiRequestWatcher = watcher
 	}
+	m.controlAPI = newControlAPI(m.uiRequestWatcher)
 	// Bind the `notifications` config section before the store opens: the
 	// store completes every record it is handed, and completion is where a
 	// per-source expiry is applied.
 	notify.ApplyConfig(cfg.Notifications)
+	m.notificationSinks = newNotificationSinks(cfg.Notifications)
 	m.notifications = openNotificationStore()
 	m.refreshNotifications()
+	m.scripts, m.scriptLoadErrors = loadScripts(km)
 	m.notificationCentreMouse = mouse.NewHandler()
 	m.notificationCentreWheel = &tty.WheelBurst{}
 	m.toastMouse = mouse.NewHandler()
@@ -553,13 +599,14 @@ func New(reg *plugin.Registry, km *keymap.Registry, cfg *config.Config, currentV
 	return m
 }
 
-func announceInstanceCmd(workDir, projectRoot string) tea.Cmd {
+func announceInstanceCmd(workDir, projectRoot string, api *controlAPI) tea.Cmd {
 	return func() tea.Msg {
 		inst := uirequest.Instance{
 			PID:       os.Getpid(),
 			Host:      uirequest.HostName(),
 			WorkDir:   workDir,
 			StartedAt: time.Now().UTC(),
+			Socket:    api.socket(),
 		}
 		if projectRoot != "" {
 			inst.Project = filepath.Base(projectRoot)
@@ -572,6 +619,10 @@ func announceInstanceCmd(workDir, projectRoot string) tea.Cmd {
 		} else if workDir != "" {
 			inst.Project = filepath.Base(workDir)
 		}
+		// The API addresses its requests to the project being announced, so
+		// it learns of a project switch at the same moment `sidecar open`
+		// does.
+		api.setProject(projectRoot, inst.ProjectKey)
 		_ = uirequest.Announce(config.StateDir(), inst)
 		return nil
 	}
@@ -595,12 +646,15 @@ func (m Model) Init() tea.Cmd {
 	cmds := []tea.Cmd{
 		tickCmd(),
 		IntroTick(),
-		announceInstanceCmd(m.ui.WorkDir, m.ui.ProjectRoot),
+		announceInstanceCmd(m.ui.WorkDir, m.ui.ProjectRoot, m.controlAPI),
 	}
 	cmds = append(cmds, m.productCheckCmds(false)...)
 	if cmd := defaultThemeNoticeCmd(m.cfg); cmd != nil {
 		cmds = append(cmds, cmd)
 	}
+	if cmd := scriptLoadErrorsCmd(m.scriptLoadErrors); cmd != nil {
+		cmds = append(cmds, cmd)
+	}
 
 	// Mark the startup plugin focused. SetActivePlugin only runs when the user
 	// switches tabs, so without this the initial tab reports itself unfocused —
@@ -630,6 +684,7 @@ func (m Model) Init() tea.Cmd {
 		m.uiRequestWatcher.Start()
 		cmds = append(cmds, listenForUIRequests(m.uiRequestWatcher.Messages()))
 	}
+	m.controlAPI.start()
 
 	// Terminal resource providers describe themselves asynchronously. The
 	// command waits on the first-ready-frame latch before it touches a
@@ -661,7 +716,15 @@ func (m *Model) initQuitModal() {
 		modal.WithVariant(modal.VariantDefault),
 		modal.WithPrimaryAction("quit"),
 	).
-		AddSection(modal.Text("Are you sure you want to quit?")).
+		AddSection(modal.Text("Are you sure you want to quit?"))
+	if unsaved := m.unsavedEdits(); len(unsaved) > 0 {
+		warning := fmt.Sprintf("Unsaved edits to %d files will be lost.", len(unsaved))
+		if len(unsaved) == 1 {
+			warning = "Unsaved edits to " + unsaved[0] + " will be lost."
+		}
+		m.quitModal.AddSection(modal.Text(styles.StatusDeleted.Render(warning)))
+	}
+	m.quitModal.
 		AddSection(modal.Spacer()).
 		AddSection(modal.Buttons(
 			modal.Btn(" Quit ", "quit"),
@@ -670,6 +733,20 @@ func (m *Model) initQuitModal() {
 	m.quitMouseHandler = mouse.NewHandler()
 }
 
+// unsavedEdits collects the files plugins hold unsaved edits for.
+func (m *Model) unsavedEdits() []string {
+	if m.registry == nil {
+		return nil
+	}
+	var paths []string
+	for _, p := range m.registry.Plugins() {
+		if r, ok := p.(plugin.UnsavedEditsReporter); ok {
+			paths = append(paths, r.UnsavedEdits()...)
+		}
+	}
+	return paths
+}
+
 // ActivePlugin returns the currently active plugin.
 func (m Model) ActivePlugin() plugin.Plugin {
 	if m.registry == nil {
@@ -1186,7 +1263,7 @@ func (m *Model) switchProjectWithSelection(projectPath string, inventory []Workt
 		m.refreshConfigContext(),
 		titleCmd,
 		inventoryRefresh,
-		announceInstanceCmd(m.ui.WorkDir, m.ui.ProjectRoot),
+		announceInstanceCmd(m.ui.WorkDir, m.ui.ProjectRoot, m.controlAPI),
 		// Routine confirmation of a switch the user just made and can see:
 		// a flash, not a stored notification (audit row 18).
 		ShowFlash(fmt.Sprintf("Switched to %s", GetRepoName(targetPath))),
@@ -1598,6 +1675,11 @@ func (m *Model) runHostCommand(id string) (tea.Cmd, bool) {
 		m.initOpenIn()
 		m.activeContext = "open-in"
 		return nil, true
+	case "bookmarks":
+		m.showBookmarks = true
+		m.initBookmarks()
+		m.activeContext = "bookmarks"
+		return nil, true
 	case "toggle-overview":
 		return m.toggleOverview(), true
 	case "toggle-notifications":
@@ -1607,6 +1689,12 @@ func (m *Model) runHostCommand(id string) (tea.Cmd, bool) {
 			return nil, true
 		}
 		return m.toggleNotificationCentre(), true
+	case "toggle-dnd":
+		return m.toggleDoNotDisturb(), true
+	case "toast-approve", "toast-deny", "toast-message", "toast-open-diff":
+		kind, _ := notify.ParseActionKind(strings.TrimPrefix(id, "toast-"))
+		cmd, _ := m.actOnVisibleToast(kind)
+		return cmd, true
 	case "expand-toast":
 		if m.toggleToastExpand() {
 			return m.syncToastReveal(time.Now()), true
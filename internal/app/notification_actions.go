@@ -0,0 +1,319 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/modal"
+	"github.com/marcus/sidecar/internal/mouse"
+	"github.com/marcus/sidecar/internal/notify"
+	"github.com/marcus/sidecar/internal/reveal"
+	"github.com/marcus/sidecar/internal/styles"
+	"github.com/marcus/sidecar/internal/ui"
+	"github.com/marcus/sidecar/internal/uirequest"
+	"github.com/marcus/sidecar/internal/workspacediff"
+	"github.com/marcus/sidecar/internal/workspaceops"
+)
+
+// Quick actions answer a notification where it is shown: approve, deny, a
+// typed message, or a look at the diff that decides between them (see
+// notify/action.go for what each one types). The centre answers the selected
+// entry on a bare key; a toast takes no focus, so it answers the same letter
+// behind alt, the family alt+e and alt+n already live in.
+
+// notificationActionTimeout bounds one answer. send-keys returns at once, so
+// anything slower is a tmux server that is not going to answer.
+const notificationActionTimeout = 5 * time.Second
+
+// answerAgent is the send path, replaced in tests.
+var answerAgent = workspaceops.AnswerAgent
+
+// notificationActionKey is the centre's key for kind. The toast's is the same
+// letter behind alt (toastActionKey).
+func notificationActionKey(kind notify.ActionKind) string {
+	switch kind {
+	case notify.ActionApprove:
+		return "y"
+	case notify.ActionDeny:
+		return "x"
+	case notify.ActionMessage:
+		return "m"
+	case notify.ActionOpenDiff:
+		return "o"
+	}
+	return ""
+}
+
+func toastActionKey(kind notify.ActionKind) string {
+	return "alt+" + notificationActionKey(kind)
+}
+
+// notificationActionForKey reads a centre key, or with toast set a toast key,
+// as the action it answers.
+func notificationActionForKey(key string, toast bool) (notify.ActionKind, bool) {
+	for _, kind := range notify.ActionKinds() {
+		want := notificationActionKey(kind)
+		if toast {
+			want = toastActionKey(kind)
+		}
+		if key == want {
+			return kind, true
+		}
+	}
+	return "", false
+}
+
+// notificationActionDoneMsg reports an answer typed into an agent's session.
+type notificationActionDoneMsg struct {
+	Kind  notify.ActionKind
+	Title string
+	Err   error
+}
+
+// runNotificationAction performs kind on n. Answering a notification is the
+// user seeing it, so it is marked read, which also retires its toast; the lane
+// tracker withdraws it outright once the agent stops waiting.
+func (m *Model) runNotificationAction(n notify.Notification, kind notify.ActionKind) tea.Cmd {
+	if !notify.HasAction(n, kind) {
+		return nil
+	}
+	m.readNotification(n.ID)
+	switch kind {
+	case notify.ActionOpenDiff:
+		target := uirequest.Target{Kind: uirequest.TargetKindDiff, Value: workspacediff.IdentityWorkingTree}
+		return ActivateTargetIn(target, n.Origin.WorkDir)
+	case notify.ActionMessage:
+		m.openAgentMessage(n)
+		return nil
+	}
+	strokes, err := notify.Keystrokes(kind, "")
+	if err != nil {
+		return nil
+	}
+	return answerAgentCmd(n, kind, strokes)
+}
+
+func answerAgentCmd(n notify.Notification, kind notify.ActionKind, strokes []notify.Keystroke) tea.Cmd {
+	session, title := n.Origin.TmuxSession, notify.CTATitle(n)
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), notificationActionTimeout)
+		defer cancel()
+		return notificationActionDoneMsg{Kind: kind, Title: title, Err: answerAgent(ctx, session, strokes)}
+	}
+}
+
+// notificationActionFlash confirms an answer, or says why it did not land.
+func notificationActionFlash(done notificationActionDoneMsg) tea.Cmd {
+	if done.Err != nil {
+		return func() tea.Msg {
+			return FlashMsg{Text: "Could not answer: " + done.Err.Error(), Source: string(notify.SourceWaiting), IsError: true}
+		}
+	}
+	verb := "Sent"
+	switch done.Kind {
+	case notify.ActionApprove:
+		verb = "Approved"
+	case notify.ActionDeny:
+		verb = "Denied"
+	}
+	return ShowFlashFrom(string(notify.SourceWaiting), verb+" · "+done.Title)
+}
+
+// actOnVisibleToast answers a toast key: the top block whose notification
+// offers kind. It reports false when no block does, so the key falls through
+// to whatever else it means.
+func (m *Model) actOnVisibleToast(kind notify.ActionKind) (tea.Cmd, bool) {
+	if m.overlaysSuppressed() {
+		return nil, false
+	}
+	for _, r := range m.toastColumnBlocks() {
+		if r.state.Phase() == reveal.Leaving {
+			continue
+		}
+		lead := r.stack.Lead()
+		if !notify.HasAction(lead, kind) {
+			continue
+		}
+		cmd := m.runNotificationAction(lead, kind)
+		return tea.Batch(cmd, m.syncToastReveal(time.Now())), true
+	}
+	return nil, false
+}
+
+// notificationActionHints is the key row for n's actions: "y approve · x deny
+// · m message… · o diff", each key given by keyOf.
+func notificationActionHints(n notify.Notification, keyOf func(notify.ActionKind) string) []string {
+	// Styled as the targets row styles its digits, so the centre's two key
+	// rows read as one grammar.
+	keyStyle := lipgloss.NewStyle().Foreground(styles.Accent).Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(styles.TextSubtle)
+	actions := notify.ActionsOf(n)
+	out := make([]string, 0, len(actions))
+	for _, a := range actions {
+		out = append(out, keyStyle.Render(keyOf(a.Kind))+" "+labelStyle.Render(a.Kind.Label()))
+	}
+	return out
+}
+
+// notificationActionLines is the selected centre entry's action rows, drawn
+// under its targets for the same reason the targets are: it is the only entry
+// the keys act on.
+func (m Model) notificationActionLines(n notify.Notification, inner, index int) []string {
+	if !m.notificationCentreOwnsKeys() || index != m.notificationCentreCursor {
+		return nil
+	}
+	const indent = "  "
+	rows := packActionHints(notificationActionHints(n, notificationActionKey), max(1, inner-len(indent)))
+	for i, row := range rows {
+		rows[i] = m.styleCentreRow(indent+row, inner, index)
+	}
+	return rows
+}
+
+// toastActionRows is a toast's action rows. A waiting toast is sticky and
+// exists to be answered, so its keys are worth the rows the other toasts gave
+// up.
+func toastActionRows(n notify.Notification, inner int) []string {
+	return packActionHints(notificationActionHints(n, toastActionKey), inner)
+}
+
+// packActionHints fills rows of width with hints, starting a new row rather
+// than cutting one in half, so every action stays readable at any width.
+func packActionHints(hints []string, width int) []string {
+	var rows []string
+	row := ""
+	for _, h := range hints {
+		switch {
+		case row == "":
+			row = h
+		case lipgloss.Width(row)+2+lipgloss.Width(h) <= width:
+			row += "  " + h
+		default:
+			rows = append(rows, row)
+			row = h
+		}
+	}
+	if row != "" {
+		rows = append(rows, row)
+	}
+	for i := range rows {
+		rows[i] = ansi.Truncate(rows[i], width, "…")
+	}
+	return rows
+}
+
+// Send message…
+
+const (
+	agentMessageInputID = "agent-message-input"
+	agentMessageSend    = "send"
+)
+
+// agentMessageState is the open "send message…" prompt. It is held by pointer
+// so the modal's input survives the Model being copied through Update.
+type agentMessageState struct {
+	target       notify.Notification
+	input        textinput.Model
+	modal        *modal.Modal
+	modalWidth   int
+	mouseHandler *mouse.Handler
+}
+
+func (m *Model) openAgentMessage(n notify.Notification) {
+	ti := textinput.New()
+	ti.Placeholder = "Reply to the agent"
+	ti.CharLimit = 2000
+	ti.Focus()
+	m.agentMessage = &agentMessageState{target: n, input: ti, mouseHandler: mouse.NewHandler()}
+	m.activeContext = "agent-message"
+}
+
+func (m *Model) closeAgentMessage() {
+	m.agentMessage = nil
+	m.updateContext()
+}
+
+func (m *Model) ensureAgentMessageModal() {
+	s := m.agentMessage
+	if s == nil {
+		return
+	}
+	modalW := min(72, max(30, m.width-4))
+	if s.modal != nil && s.modalWidth == modalW {
+		return
+	}
+	s.modalWidth = modalW
+	s.input.SetWidth(modalW - 8)
+	s.modal = modal.New("Message "+agentMessageName(s.target),
+		modal.WithWidth(modalW),
+		modal.WithPrimaryAction(agentMessageSend),
+	).
+		AddSection(modal.Text(notify.CTATitle(s.target))).
+		AddSection(modal.Spacer()).
+		AddSection(modal.Input(agentMessageInputID, &s.input, modal.WithSubmitAction(agentMessageSend))).
+		AddSection(modal.Spacer()).
+		AddSection(modal.Buttons(
+			modal.Btn(" Send ", agentMessageSend),
+			modal.Btn(" Cancel ", "cancel"),
+		))
+}
+
+// agentMessageName is who the prompt is addressed to: the tmux session, which
+// is where the text is about to go.
+func agentMessageName(n notify.Notification) string {
+	if s := strings.TrimSpace(n.Origin.TmuxSession); s != "" {
+		return s
+	}
+	return "agent"
+}
+
+func (m *Model) renderAgentMessageModal(content string) string {
+	m.ensureAgentMessageModal()
+	if m.agentMessage == nil || m.agentMessage.modal == nil {
+		return content
+	}
+	rendered := m.agentMessage.modal.Render(m.width, m.height, m.agentMessage.mouseHandler)
+	return ui.OverlayModal(content, rendered, m.width, m.height)
+}
+
+func (m *Model) handleAgentMessageKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	m.ensureAgentMessageModal()
+	if m.agentMessage == nil || m.agentMessage.modal == nil {
+		return m, nil
+	}
+	action, cmd := m.agentMessage.modal.HandleKey(msg)
+	if action != "" {
+		return m, m.handleAgentMessageAction(action)
+	}
+	return m, cmd
+}
+
+func (m *Model) handleAgentMessageMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	m.ensureAgentMessageModal()
+	if m.agentMessage == nil || m.agentMessage.modal == nil {
+		return m, nil
+	}
+	return m, m.handleAgentMessageAction(m.agentMessage.modal.HandleMouse(msg, m.agentMessage.mouseHandler))
+}
+
+func (m *Model) handleAgentMessageAction(action string) tea.Cmd {
+	switch action {
+	case "cancel":
+		m.closeAgentMessage()
+	case agentMessageSend:
+		s := m.agentMessage
+		strokes, err := notify.Keystrokes(notify.ActionMessage, s.input.Value())
+		if err != nil {
+			// Nothing typed: keep the prompt open rather than send an Enter.
+			return nil
+		}
+		m.closeAgentMessage()
+		return answerAgentCmd(s.target, notify.ActionMessage, strokes)
+	}
+	return nil
+}
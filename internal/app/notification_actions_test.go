@@ -0,0 +1,154 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/notify"
+	"github.com/marcus/sidecar/internal/reveal"
+)
+
+type answeredAgent struct {
+	session string
+	strokes []notify.Keystroke
+}
+
+// stubAnswerAgent records what the actions would have typed.
+func stubAnswerAgent(t *testing.T) *[]answeredAgent {
+	t.Helper()
+	var got []answeredAgent
+	prev := answerAgent
+	answerAgent = func(_ context.Context, session string, strokes []notify.Keystroke) error {
+		got = append(got, answeredAgent{session, strokes})
+		return nil
+	}
+	t.Cleanup(func() { answerAgent = prev })
+	return &got
+}
+
+func postWaiting(t *testing.T, m *Model, title string) notify.Notification {
+	t.Helper()
+	stored, err := m.notifications.Post(notify.Notification{
+		Source:  notify.SourceWaiting,
+		Title:   title,
+		Origin:  notify.Origin{TmuxSession: "sidecar-ws-agent", WorkDir: "/tmp/agent"},
+		Actions: notify.WaitingActions(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.refreshNotifications()
+	return stored
+}
+
+// findActionDone runs cmd and whatever it batches, looking for the answer.
+func findActionDone(cmd tea.Cmd) (notificationActionDoneMsg, bool) {
+	if cmd == nil {
+		return notificationActionDoneMsg{}, false
+	}
+	switch msg := cmd().(type) {
+	case notificationActionDoneMsg:
+		return msg, true
+	case tea.BatchMsg:
+		for _, c := range msg {
+			if done, ok := findActionDone(c); ok {
+				return done, true
+			}
+		}
+	}
+	return notificationActionDoneMsg{}, false
+}
+
+func TestCentreActionKeysAnswerTheSelectedAgent(t *testing.T) {
+	sent := stubAnswerAgent(t)
+	m := centreTestModel(t, &sizingPlugin{id: "files"})
+	waiting := postWaiting(t, &m, "Allow edit?")
+	m.toggleNotificationCentre()
+
+	// The panel is narrow, so the actions wrap rather than lose one to an
+	// ellipsis.
+	var entry []string
+	for _, row := range m.notificationCentreBody(notificationCentreDefaultWidth-4, time.Now()) {
+		if row.item == 0 {
+			entry = append(entry, strings.Join(strings.Fields(ansi.Strip(row.text)), " "))
+		}
+	}
+	text := strings.Join(entry, " ")
+	for _, want := range []string{"y approve", "x deny", "m message…", "o diff"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("the selection does not offer %q: %q", want, entry)
+		}
+	}
+
+	_, cmd := m.handleKeyMsg(tea.KeyPressMsg{Code: 'y', Text: "y"})
+	done, ok := findActionDone(cmd)
+	if !ok || done.Err != nil || done.Kind != notify.ActionApprove {
+		t.Fatalf("y did not approve: %+v", done)
+	}
+	if len(*sent) != 1 || (*sent)[0].session != "sidecar-ws-agent" || (*sent)[0].strokes[0].Keys != "y" {
+		t.Fatalf("y typed %+v, want y into the origin session", *sent)
+	}
+	all, _ := m.notifications.List()
+	for _, n := range all {
+		if n.ID == waiting.ID && !n.Read() {
+			t.Fatal("answering a notification must mark it read")
+		}
+	}
+
+	// m asks for the text first, and sends it on enter.
+	m.handleKeyMsg(tea.KeyPressMsg{Code: 'm', Text: "m"})
+	if m.activeModal() != ModalAgentMessage {
+		t.Fatalf("m opened %v, want the message prompt", m.activeModal())
+	}
+	m.renderAgentMessageModal(blankScreen(m.width, m.height)) // the first frame focuses the input
+	m.agentMessage.input.SetValue("use the v2 client")
+	_, cmd = m.handleKeyMsg(tea.KeyPressMsg{Code: tea.KeyEnter})
+	done, ok = findActionDone(cmd)
+	if !ok || done.Kind != notify.ActionMessage {
+		t.Fatalf("enter did not send the message: %+v", done)
+	}
+	if m.agentMessage != nil {
+		t.Fatal("the prompt stayed open after sending")
+	}
+	if got := (*sent)[len(*sent)-1].strokes[0]; got.Keys != "use the v2 client" || !got.Literal {
+		t.Fatalf("message typed %+v", got)
+	}
+}
+
+func TestToastActionKeysAnswerTheVisibleAgent(t *testing.T) {
+	restore := reveal.SetAnimatedForTests(false)
+	defer restore()
+	sent := stubAnswerAgent(t)
+	m := notifyModel()
+	m.width, m.height, m.ready = 100, 30, true
+	postWaiting(t, m, "Allow edit?")
+	syncToasts(t, m)
+
+	if screen := paint(m); !strings.Contains(strings.Join(strings.Fields(screen), " "), "alt+y approve") {
+		t.Fatalf("the toast shows no action keys:\n%s", screen)
+	}
+	cmd, ok := m.actOnVisibleToast(notify.ActionDeny)
+	if !ok {
+		t.Fatal("alt+x found no toast to answer")
+	}
+	if done, ok := findActionDone(cmd); !ok || done.Kind != notify.ActionDeny {
+		t.Fatalf("alt+x did not deny: %+v", done)
+	}
+	if len(*sent) != 1 || (*sent)[0].strokes[0].Keys != "n" {
+		t.Fatalf("alt+x typed %+v, want n", *sent)
+	}
+
+	// A plain toast offers nothing, so the key is left for whatever else it
+	// means.
+	other := notifyModel()
+	other.width, other.height, other.ready = 100, 30, true
+	other.postNotification(notify.Notification{Source: notify.SourceAgent, Title: "done"})
+	syncToasts(t, other)
+	if _, ok := other.actOnVisibleToast(notify.ActionApprove); ok {
+		t.Fatal("a toast without actions claimed alt+y")
+	}
+}
@@ -230,6 +230,7 @@ func (m Model) notificationCentreItemLines(n notify.Notification, inner, index i
 	if row, ok := m.notificationTargetsLine(ctas, inner, index); ok {
 		lines = append(lines, row)
 	}
+	lines = append(lines, m.notificationActionLines(n, inner, index)...)
 	return lines
 }
 
@@ -636,6 +637,11 @@ func (m Model) notificationCentreCommands() []plugin.Command {
 		{ID: "dismiss-group", Name: "Group", Context: notificationCentreContext, Priority: 6},
 		{ID: "close-notification-centre", Name: "Close", Context: notificationCentreContext, Priority: 7},
 		{ID: "focus-content", Name: "Content", Description: "Move focus on to the content", Context: notificationCentreContext, Priority: 8},
+		{ID: "toggle-dnd", Name: "Quiet", Description: "Toggle do not disturb", Context: notificationCentreContext, Priority: 9},
+		{ID: "approve", Name: "Approve", Description: "Answer a waiting agent with yes", Context: notificationCentreContext, Priority: 10},
+		{ID: "deny", Name: "Deny", Description: "Answer a waiting agent with no", Context: notificationCentreContext, Priority: 11},
+		{ID: "send-message", Name: "Message", Description: "Type a reply to a waiting agent", Context: notificationCentreContext, Priority: 12},
+		{ID: "open-diff", Name: "Diff", Description: "Open the waiting agent's working-tree diff", Context: notificationCentreContext, Priority: 13},
 	}
 }
 
@@ -663,6 +669,13 @@ func (m *Model) notificationCentreKey(msg tea.KeyPressMsg) (bool, tea.Cmd) {
 			return true, m.activateNotificationTarget(number)
 		}
 	}
+	// A quick action of the selected entry. A key the entry does not offer
+	// falls through, so `o` on a notification with no diff stays nothing.
+	if kind, ok := notificationActionForKey(key, false); ok {
+		if selected, ok := m.selectedNotification(items); ok && notify.HasAction(selected, kind) {
+			return true, m.runNotificationAction(selected, kind)
+		}
+	}
 	if notificationCentreReleasesFocus(key) {
 		// A navigation key means the user is going somewhere else. Hand the
 		// keyboard back to the content and let the key run its ordinary course —
@@ -706,6 +719,8 @@ func (m *Model) notificationCentreKey(msg tea.KeyPressMsg) (bool, tea.Cmd) {
 		return true, m.activateSelectedNotification()
 	case "v":
 		return true, m.showNotificationDetails()
+	case "z":
+		return true, m.toggleDoNotDisturb()
 	}
 	return false, nil
 }
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"encoding/json"
 	"log/slog"
 	"os"
@@ -10,7 +11,9 @@ import (
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/configui"
 	"github.com/marcus/sidecar/internal/notify"
+	"github.com/marcus/sidecar/internal/notifysink"
 	"github.com/marcus/sidecar/internal/reveal"
 	"github.com/marcus/sidecar/internal/state"
 	"github.com/marcus/sidecar/internal/uirequest"
@@ -207,6 +210,7 @@ func (m *Model) refreshNotifications() {
 	}
 	m.notificationCache = all
 	m.pruneNotificationCTAs()
+	m.controlAPI.publishNotifications(all)
 }
 
 // postNotification stores a notification and returns the broadcast announcing
@@ -221,7 +225,65 @@ func (m *Model) postNotification(n notify.Notification) tea.Cmd {
 		return nil
 	}
 	m.refreshNotifications()
-	return func() tea.Msg { return notify.PostedMsg{Notification: stored} }
+	cmds := []tea.Cmd{func() tea.Msg { return notify.PostedMsg{Notification: stored} }}
+	// The bell and the sinks fire once, on arrival, and only in the instance
+	// that filed the notification: a record swept in from the log later is old
+	// news.
+	now := time.Now()
+	if notify.RingsBell(stored, now) {
+		cmds = append(cmds, tea.Raw("\a"))
+	}
+	if seq := m.notificationSinks.Terminal(stored, now); seq != "" {
+		cmds = append(cmds, tea.Raw(seq))
+	}
+	cmds = append(cmds, deliverNotification(m.notificationSinks, stored, now))
+	cmds = append(cmds, m.scripts.ObserveNotification(stored))
+	return tea.Batch(cmds...)
+}
+
+// notificationDeliveryTimeout bounds one notification's trip through every
+// sink, retries included.
+const notificationDeliveryTimeout = 30 * time.Second
+
+// newNotificationSinks builds the configured delivery sinks, logging any it
+// had to leave out.
+func newNotificationSinks(cfg config.NotificationsConfig) *notifysink.Dispatcher {
+	sinks, errs := notifysink.New(cfg)
+	for _, err := range errs {
+		slog.Warn("notifications: ignoring sink", "error", err)
+	}
+	return sinks
+}
+
+// deliverNotification hands a filed notification to the sinks off the update
+// loop. It answers nothing: a sink that fails is logged, never surfaced as a
+// toast about a toast.
+func deliverNotification(sinks *notifysink.Dispatcher, n notify.Notification, now time.Time) tea.Cmd {
+	if sinks.Empty() {
+		return nil
+	}
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), notificationDeliveryTimeout)
+		defer cancel()
+		for _, err := range sinks.Deliver(ctx, n, now) {
+			slog.Debug("notify: delivery failed", "id", n.ID, "err", err)
+		}
+		return nil
+	}
+}
+
+// toggleDoNotDisturb flips do-not-disturb. It is a config setting rather than
+// session state, so a CLI post files under the same answer the header shows;
+// the running state is re-read from the saved file like any other setting.
+func (m *Model) toggleDoNotDisturb() tea.Cmd {
+	on := !notify.DoNotDisturb()
+	notice := "Do not disturb off"
+	if on {
+		notice = "Do not disturb on"
+	}
+	return configui.SaveCmd(notice, func() error {
+		return config.SaveNotifications(func(n *config.NotificationsConfig) { n.DoNotDisturb = on })
+	})
 }
 
 // dismissNotification dismisses by id, ignoring an id the store never saw.
@@ -397,6 +459,20 @@ func (m *Model) handleNotifyRequest(req uirequest.Request) tea.Cmd {
 	if n.Origin.Zero() {
 		n.Origin = notifyOriginFrom(req.Origin)
 	}
+	// Quick actions type into the origin's session, so a poster may only
+	// offer them for its own: a notification naming someone else's shell must
+	// not put an approve key in front of the user for it.
+	if len(n.Actions) > 0 && !n.Origin.Matches(notifyOriginFrom(req.Origin)) {
+		n.Actions = nil
+	}
+	if !notify.ValidSource(n.Source) {
+		// A source registered with `sidecar notify source add` since this
+		// instance read its config: read it again so the notification lands
+		// in its own section rather than under system.
+		if cfg, err := config.Load(); err == nil {
+			notify.ApplyConfig(cfg.Notifications)
+		}
+	}
 	cmd := m.postNotification(n)
 	m.ackNotify(req, uirequest.StatusOpened, "")
 	return cmd
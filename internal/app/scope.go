@@ -678,4 +678,5 @@ func (m *Model) shutdown() {
 	if m.overview != nil {
 		m.overview.Stop()
 	}
+	m.controlAPI.close()
 }
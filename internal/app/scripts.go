@@ -0,0 +1,222 @@
+package app
+
+import (
+	"time"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"github.com/marcus/sidecar/internal/features"
+	"github.com/marcus/sidecar/internal/keymap"
+	"github.com/marcus/sidecar/internal/modal"
+	"github.com/marcus/sidecar/internal/mouse"
+	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/scripting"
+	"github.com/marcus/sidecar/internal/ui"
+)
+
+// User scripts (the scripting feature). The engine in internal/scripting
+// knows nothing of the app: this file gives its commands a keymap id and a
+// palette row, and turns what a script asked for into notifications. The
+// events it hooks are fed from Update where the app already sees them — the
+// workspaces topic, git snapshots, lane transitions, stored notifications.
+
+// scriptCommandPrefix namespaces script commands in the keymap, so a script
+// cannot shadow a built-in command and a keymap override can still name one:
+// "ctrl+g": "script:commit-all".
+const scriptCommandPrefix = "script:"
+
+// scriptToastDuration is how long a script's flash message floats.
+const scriptToastDuration = 4 * time.Second
+
+// openScriptPromptMsg asks for the prompt of a command that takes text.
+type openScriptPromptMsg struct {
+	command scripting.Command
+}
+
+// loadScripts loads the scripts directory and registers each command with
+// the keymap. It returns a nil engine while the feature is off.
+func loadScripts(km *keymap.Registry) (*scripting.Engine, []error) {
+	if !features.IsEnabled(features.Scripting.Name) {
+		return nil, nil
+	}
+	engine, errs := scripting.Load(scripting.Dir())
+	registerScriptCommands(km, engine)
+	return engine, errs
+}
+
+func registerScriptCommands(km *keymap.Registry, engine *scripting.Engine) {
+	for _, c := range engine.Commands() {
+		km.RegisterCommand(keymap.Command{
+			ID:      scriptCommandPrefix + c.ID,
+			Name:    c.Name,
+			Context: "global",
+			Handler: scriptCommandHandler(engine, c),
+		})
+	}
+}
+
+// scriptCommandHandler runs a command, or raises its prompt first. It closes
+// over the engine, never the Model: the keymap outlives every copy of it.
+func scriptCommandHandler(engine *scripting.Engine, c scripting.Command) func() tea.Cmd {
+	return func() tea.Cmd {
+		if c.Prompt != "" {
+			return func() tea.Msg { return openScriptPromptMsg{command: c} }
+		}
+		return engine.Run(c.ID, "")
+	}
+}
+
+// scriptPaletteCommands are the palette rows for script commands. The keymap
+// already runs them; the rows are what make them visible without a binding.
+func (m *Model) scriptPaletteCommands() []plugin.Command {
+	commands := m.scripts.Commands()
+	if len(commands) == 0 {
+		return nil
+	}
+	out := make([]plugin.Command, 0, len(commands))
+	for _, c := range commands {
+		description := c.Description
+		if description == "" {
+			description = c.Name
+		}
+		out = append(out, plugin.Command{
+			ID:          scriptCommandPrefix + c.ID,
+			Name:        c.Name,
+			Description: description,
+			Category:    plugin.CategoryActions,
+			Context:     "global",
+			Handler:     scriptCommandHandler(m.scripts, c),
+		})
+	}
+	return out
+}
+
+// scriptLoadErrorsCmd reports the scripts that failed to load, once, after
+// the first frame.
+func scriptLoadErrorsCmd(errs []error) tea.Cmd {
+	cmds := make([]tea.Cmd, 0, len(errs))
+	for _, err := range errs {
+		msg := scripting.ResultMsg{Err: err}
+		cmds = append(cmds, func() tea.Msg { return msg })
+	}
+	return tea.Batch(cmds...)
+}
+
+// applyScriptResult files what a finished command or hook asked for. Its
+// notifications take the same road as any other post.
+func (m *Model) applyScriptResult(msg scripting.ResultMsg) tea.Cmd {
+	cmds := make([]tea.Cmd, 0, len(msg.Notifications)+1)
+	for _, n := range msg.Notifications {
+		cmds = append(cmds, m.postNotification(n))
+	}
+	if msg.Err != nil {
+		text := "Script error: " + msg.Err.Error()
+		if msg.Label != "" {
+			text = "Script error in " + msg.Label + ": " + msg.Err.Error()
+		}
+		m.showToastWithSeverity(text, 2*scriptToastDuration, true)
+	}
+	if msg.Flash != "" {
+		m.ShowToast(msg.Flash, scriptToastDuration)
+	}
+	cmds = append(cmds, m.syncToastReveal(time.Now()))
+	return tea.Batch(cmds...)
+}
+
+// Script prompt
+
+const (
+	scriptPromptInputID = "script-prompt-input"
+	scriptPromptRun     = "run"
+)
+
+// scriptPromptState is the open prompt of a script command. It is held by
+// pointer so the modal's input survives the Model being copied through
+// Update.
+type scriptPromptState struct {
+	command      scripting.Command
+	input        textinput.Model
+	modal        *modal.Modal
+	modalWidth   int
+	mouseHandler *mouse.Handler
+}
+
+func (m *Model) openScriptPrompt(c scripting.Command) {
+	ti := textinput.New()
+	ti.Placeholder = c.Prompt
+	ti.CharLimit = 2000
+	ti.Focus()
+	m.scriptPrompt = &scriptPromptState{command: c, input: ti, mouseHandler: mouse.NewHandler()}
+	m.activeContext = "script-prompt"
+}
+
+func (m *Model) closeScriptPrompt() {
+	m.scriptPrompt = nil
+	m.updateContext()
+}
+
+func (m *Model) ensureScriptPromptModal() {
+	s := m.scriptPrompt
+	if s == nil {
+		return
+	}
+	modalW := min(72, max(30, m.width-4))
+	if s.modal != nil && s.modalWidth == modalW {
+		return
+	}
+	s.modalWidth = modalW
+	s.input.SetWidth(modalW - 8)
+	s.modal = modal.New(s.command.Name,
+		modal.WithWidth(modalW),
+		modal.WithPrimaryAction(scriptPromptRun),
+	).
+		AddSection(modal.Text(s.command.Prompt)).
+		AddSection(modal.Spacer()).
+		AddSection(modal.Input(scriptPromptInputID, &s.input, modal.WithSubmitAction(scriptPromptRun))).
+		AddSection(modal.Spacer()).
+		AddSection(modal.Buttons(
+			modal.Btn(" Run ", scriptPromptRun),
+			modal.Btn(" Cancel ", "cancel"),
+		))
+}
+
+func (m *Model) renderScriptPromptModal(content string) string {
+	m.ensureScriptPromptModal()
+	if m.scriptPrompt == nil || m.scriptPrompt.modal == nil {
+		return content
+	}
+	rendered := m.scriptPrompt.modal.Render(m.width, m.height, m.scriptPrompt.mouseHandler)
+	return ui.OverlayModal(content, rendered, m.width, m.height)
+}
+
+func (m *Model) handleScriptPromptKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	m.ensureScriptPromptModal()
+	if m.scriptPrompt == nil || m.scriptPrompt.modal == nil {
+		return m, nil
+	}
+	action, cmd := m.scriptPrompt.modal.HandleKey(msg)
+	if action != "" {
+		return m, m.handleScriptPromptAction(action)
+	}
+	return m, cmd
+}
+
+func (m *Model) handleScriptPromptMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	m.ensureScriptPromptModal()
+	if m.scriptPrompt == nil || m.scriptPrompt.modal == nil {
+		return m, nil
+	}
+	return m, m.handleScriptPromptAction(m.scriptPrompt.modal.HandleMouse(msg, m.scriptPrompt.mouseHandler))
+}
+
+func (m *Model) handleScriptPromptAction(action string) tea.Cmd {
+	switch action {
+	case "cancel":
+		m.closeScriptPrompt()
+	case scriptPromptRun:
+		s := m.scriptPrompt
+		m.closeScriptPrompt()
+		return m.scripts.Run(s.command.ID, s.input.Value())
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/marcus/sidecar/internal/palette"
+	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/scripting"
+)
+
+// runScriptCmd runs cmd and whatever it batches, returning the script's
+// result or the prompt it asked for.
+func runScriptCmd(cmd tea.Cmd) (tea.Msg, bool) {
+	if cmd == nil {
+		return nil, false
+	}
+	switch msg := cmd().(type) {
+	case scripting.ResultMsg, openScriptPromptMsg:
+		return msg, true
+	case tea.BatchMsg:
+		for _, c := range msg {
+			if found, ok := runScriptCmd(c); ok {
+				return found, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func TestScriptCommandsRunFromThePaletteThroughTheirPrompt(t *testing.T) {
+	m := centreTestModel(t)
+	engine := scripting.New()
+	if err := engine.LoadSource("greet.star", []byte(`
+sidecar.command("greet", lambda name: sidecar.flash("hello " + name), name = "Greet", prompt = "Who?")
+`)); err != nil {
+		t.Fatal(err)
+	}
+	m.scripts = engine
+	registerScriptCommands(m.keymap, engine)
+
+	found := false
+	for _, e := range palette.BuildEntries(m.keymap, []plugin.Plugin{appContentCommandPlugin{commands: m.scriptPaletteCommands()}}, "global", "global") {
+		if e.CommandID == "script:greet" && e.Name == "Greet" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("the palette does not list the script command")
+	}
+
+	_, cmd := m.Update(palette.CommandSelectedMsg{CommandID: "script:greet"})
+	prompt, ok := runScriptCmd(cmd)
+	if !ok {
+		t.Fatal("selecting a prompting command asked for nothing")
+	}
+	next, _ := m.Update(prompt)
+	m = next.(Model)
+	if m.activeModal() != ModalScriptPrompt {
+		t.Fatalf("modal = %v, want the script prompt", m.activeModal())
+	}
+	m.renderScriptPromptModal(blankScreen(m.width, m.height)) // the first frame focuses the input
+	m.scriptPrompt.input.SetValue("world")
+	_, cmd = m.handleKeyMsg(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if m.scriptPrompt != nil {
+		t.Fatal("the prompt stayed open after running")
+	}
+	result, ok := runScriptCmd(cmd)
+	if !ok {
+		t.Fatal("enter did not run the command")
+	}
+	next, _ = m.Update(result)
+	m = next.(Model)
+	all, _ := m.notifications.List()
+	if len(all) != 1 || all[0].Title != "hello world" {
+		t.Fatalf("notifications = %+v, want the script's flash", all)
+	}
+}
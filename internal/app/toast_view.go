@@ -301,6 +301,13 @@ func renderToastBlock(s notify.Stack, outerWidth int, now time.Time, expanded bo
 		}
 	}
 
+	if rows := toastActionRows(n, inner); len(rows) > 0 {
+		if body == "" && s.Hidden() == 0 {
+			lines = append(lines, lipgloss.NewStyle().Foreground(hue).Render(strings.Repeat("─", inner)))
+		}
+		lines = append(lines, rows...)
+	}
+
 	if hidden := s.Hidden(); hidden > 0 {
 		if expanded {
 			for i, member := range s.Members[1:] {
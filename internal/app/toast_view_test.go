@@ -8,6 +8,7 @@ import (
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/config"
 	"github.com/marcus/sidecar/internal/notify"
 )
 
@@ -213,6 +214,76 @@ func TestHeaderIndicator(t *testing.T) {
 	}
 }
 
+// Do-not-disturb holds toasts back and says so in the corner, and a bell
+// route rings only when nothing is holding it back.
+func TestDoNotDisturbMutesTheIndicatorAndTheBell(t *testing.T) {
+	t.Cleanup(func() { notify.ApplyConfig(config.NotificationsConfig{}) })
+	notify.ApplyConfig(config.NotificationsConfig{Sources: map[string]config.NotificationSourceConfig{"ci": {Bell: true}}})
+	m := notifyModel()
+	if cmd := m.postNotification(notify.Notification{Source: notify.SourceCI, Title: "build failed"}); !batchRings(cmd) {
+		t.Fatal("a bell source posted without ringing")
+	}
+
+	notify.ApplyConfig(config.NotificationsConfig{
+		Sources:      map[string]config.NotificationSourceConfig{"ci": {Bell: true}},
+		DoNotDisturb: true,
+	})
+	if cmd := m.postNotification(notify.Notification{Source: notify.SourceCI, Title: "again"}); batchRings(cmd) {
+		t.Fatal("the bell rang under do-not-disturb")
+	}
+	if got := ansi.Strip(m.renderHeaderIndicator()); got != "◌2" {
+		t.Fatalf("indicator = %q, want ◌2", got)
+	}
+	if got := m.ToastableNotifications(time.Now()); len(got) != 0 {
+		t.Fatalf("%d toasts on screen under do-not-disturb", len(got))
+	}
+}
+
+// batchRings reports whether a post's command includes the terminal bell.
+func batchRings(cmd tea.Cmd) bool {
+	for _, raw := range batchRaw(cmd) {
+		if raw == "\a" {
+			return true
+		}
+	}
+	return false
+}
+
+// batchRaw is everything a post's command writes straight to the terminal.
+func batchRaw(cmd tea.Cmd) []string {
+	if cmd == nil {
+		return nil
+	}
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok {
+		return nil
+	}
+	var out []string
+	for _, c := range batch {
+		if c == nil {
+			continue
+		}
+		if raw, ok := c().(tea.RawMsg); ok {
+			if s, ok := raw.Msg.(string); ok {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+// An OSC sink announces a notification through the terminal emulator, once,
+// from the instance that filed it.
+func TestOSCSinkWritesThroughTheTerminal(t *testing.T) {
+	t.Setenv("TMUX", "")
+	m := notifyModel()
+	m.notificationSinks = newNotificationSinks(config.NotificationsConfig{Sinks: []config.NotificationSinkConfig{{Kind: "osc"}}})
+	raw := batchRaw(m.postNotification(notify.Notification{Source: notify.SourceAgent, Title: "Agent finished"}))
+	if len(raw) != 1 || raw[0] != "\x1b]9;Agent finished\a" {
+		t.Fatalf("terminal writes = %q", raw)
+	}
+}
+
 // The indicator is the centre's only route in, so it registers a hit region and
 // the click toggles the panel.
 func TestIndicatorHitRegionTogglesTheCentre(t *testing.T) {
@@ -10,8 +10,10 @@ import (
 	"github.com/marcus/sidecar/internal/config"
 	"github.com/marcus/sidecar/internal/configui"
 	"github.com/marcus/sidecar/internal/contentpanes"
+	"github.com/marcus/sidecar/internal/controlapi"
 	"github.com/marcus/sidecar/internal/docview"
 	"github.com/marcus/sidecar/internal/gitinit"
+	"github.com/marcus/sidecar/internal/gitstate"
 	"github.com/marcus/sidecar/internal/inlineedit"
 	"github.com/marcus/sidecar/internal/issueview"
 	"github.com/marcus/sidecar/internal/keymap"
@@ -24,6 +26,7 @@ import (
 	"github.com/marcus/sidecar/internal/panelayout"
 	"github.com/marcus/sidecar/internal/plugin"
 	"github.com/marcus/sidecar/internal/resourceview"
+	"github.com/marcus/sidecar/internal/scripting"
 	"github.com/marcus/sidecar/internal/styles"
 	"github.com/marcus/sidecar/internal/theme"
 	"github.com/marcus/sidecar/internal/tty"
@@ -119,6 +122,16 @@ func (m *Model) handlePaste(msg tea.PasteMsg) (tea.Model, tea.Cmd) {
 			m.issueSearchCursor = -1
 		}
 		return m, cmd
+
+	case ModalAgentMessage:
+		var cmd tea.Cmd
+		m.agentMessage.input, cmd = m.agentMessage.input.Update(msg)
+		return m, cmd
+
+	case ModalScriptPrompt:
+		var cmd tea.Cmd
+		m.scriptPrompt.input, cmd = m.scriptPrompt.input.Update(msg)
+		return m, cmd
 	}
 
 	if m.globalWorkspacesVisible() && m.overview.CreateOpen() && m.overview.CreatePaste(msg.Content) {
@@ -322,10 +335,16 @@ func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.handleThemeSwitcherMouse(msg)
 		case ModalOpenIn:
 			return m.handleOpenInMouse(msg)
+		case ModalBookmarks:
+			return m.handleBookmarksMouse(msg)
 		case ModalIssueInput:
 			return m.handleIssueInputMouse(msg)
 		case ModalIssuePreview:
 			return m.handleIssuePreviewMouse(msg)
+		case ModalAgentMessage:
+			return m.handleAgentMessageMouse(msg)
+		case ModalScriptPrompt:
+			return m.handleScriptPromptMouse(msg)
 		}
 
 		// Only row 0 is painted header chrome. Left-clicks on that row stay
@@ -487,6 +506,10 @@ func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.titleResyncCounter = 0
 		}
 		titleCmd := (&m).syncTerminalTitle(forceTitle)
+		// Pane layouts change from too many places to publish at each; the
+		// heartbeat publishes them, and the server drops a tick that changed
+		// nothing.
+		m.controlAPI.publishPanes(m.contentDecks)
 		// Periodically check if current worktree still exists (every 10 seconds)
 		m.worktreeCheckCounter++
 		if m.worktreeCheckCounter >= 10 {
@@ -531,6 +554,16 @@ func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmd := (&m).postNotification(msg.Notification)
 		return m, tea.Batch(cmd, (&m).syncToastReveal(time.Now()))
 
+	case notify.LaneTransitionMsg:
+		return m, m.scripts.ObserveAgent(msg.Transition)
+
+	case scripting.ResultMsg:
+		return m, (&m).applyScriptResult(msg)
+
+	case openScriptPromptMsg:
+		(&m).openScriptPrompt(msg.command)
+		return m, nil
+
 	case notify.DismissMsg:
 		(&m).dismissNotification(msg.ID)
 		return m, (&m).syncToastReveal(time.Now())
@@ -588,6 +621,12 @@ func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case ActivateTargetMsg:
 		return m, m.activateTarget(msg)
 
+	case notificationActionDoneMsg:
+		return m, notificationActionFlash(msg)
+
+	case AddBookmarkMsg:
+		return m, m.addBookmark(msg)
+
 	case FocusPluginByIDMsg:
 		// Switch to requested plugin
 		m.leaveOverview(false)
@@ -821,6 +860,21 @@ func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+	case controlapi.PublishMsg:
+		m.controlAPI.applyPublish(msg, m.contentDecks)
+		// Scripts see the same workspaces the API publishes.
+		if state, ok := msg.Data.(controlapi.WorkspacesState); ok && msg.Topic == controlapi.TopicWorkspaces {
+			return m, m.scripts.ObserveWorkspaces(state)
+		}
+		return m, nil
+
+	case gitstate.SnapshotMsg:
+		// Published and then passed on: the plugins adopt the same snapshot.
+		m.controlAPI.publishGit(msg.Snapshot, m.ui.WorkDir)
+		if cmd := m.scripts.ObserveGit(msg.Snapshot); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
 	case uirequest.RequestMsg:
 		if msg.Request.Action == uirequest.ActionNotify {
 			if cmd := (&m).handleNotifyRequest(msg.Request); cmd != nil {
@@ -982,6 +1036,12 @@ func (m *Model) handleKeyMsg(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 			m.resetIssueInput()
 			m.updateContext()
 			return m, nil
+		case ModalAgentMessage:
+			m.closeAgentMessage()
+			return m, nil
+		case ModalScriptPrompt:
+			m.closeScriptPrompt()
+			return m, nil
 		case ModalIssuePreview:
 			if m.issuePreviewView != nil && m.issuePreviewView.Active() {
 				m.issuePreviewView.SetActive(false)
@@ -1587,6 +1647,30 @@ func (m *Model) handleKeyMsg(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	// The message prompt a waiting notification raised. Lowest priority, so
+	// anything above it has already answered.
+	if m.activeModal() == ModalAgentMessage {
+		return m.handleAgentMessageKey(msg)
+	}
+	if m.activeModal() == ModalScriptPrompt {
+		return m.handleScriptPromptKey(msg)
+	}
+
+	// Handle bookmarks picker keys
+	if m.showBookmarks {
+		m.ensureBookmarksModal()
+		if m.bookmarksModal != nil {
+			action, cmd := m.bookmarksModal.HandleKey(msg)
+			if action != "" {
+				return m.handleBookmarksAction(action)
+			}
+			if cmd != nil {
+				return m, cmd
+			}
+		}
+		return m, nil
+	}
+
 	// Handle issue input modal keys
 	if m.showIssueInput {
 		// ctrl+x toggles closed issue visibility (before type switch)
@@ -1852,9 +1936,10 @@ func (m *Model) handleKeyMsg(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 			m.palette.SetSize(m.width, m.height)
 			// surfacePlugins includes the global Tasks host. A passive app-owned
 			// leaf contributes its own command projection without changing the
-			// underlying plugin's mandatory interface.
+			// underlying plugin's mandatory interface, and user scripts ride
+			// along with it.
 			surfaces := m.surfacePlugins()
-			if commands := m.appContentCommands(); len(commands) > 0 {
+			if commands := append(m.appContentCommands(), m.scriptPaletteCommands()...); len(commands) > 0 {
 				surfaces = append(surfaces, appContentCommandPlugin{Plugin: m.focusedSurface(), commands: commands})
 			}
 			m.palette.Open(m.keymap, surfaces, m.activeContext, pluginCtx)
@@ -1923,6 +2008,18 @@ func (m *Model) handleKeyMsg(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		if m.dismissVisibleToast() {
 			return m, m.syncToastReveal(time.Now())
 		}
+	case "alt+y", "alt+x", "alt+m", "alt+o":
+		// A toast's quick actions (notification_actions.go): the centre's
+		// letter behind alt, global for the reason alt+e is. Each falls
+		// through when no toast on screen offers that action.
+		if m.hasModal() || m.consumesTextInput() || m.contextRebindsKey(msg.String()) {
+			break
+		}
+		if kind, ok := notificationActionForKey(msg.String(), true); ok {
+			if cmd, acted := m.actOnVisibleToast(kind); acted {
+				return m, cmd
+			}
+		}
 	case toastExpandKey:
 		// The expand affordance on a collapsed stack (design 1b). `tab` is the
 		// design's key and Phase 2 spent it on the focus cycle, so this is
@@ -1995,6 +2092,15 @@ func (m *Model) handleKeyMsg(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 			m.initOpenIn()
 		}
 		return m, nil
+	case "'":
+		// The bookmarks picker. The apostrophe is vim's jump-to-mark key and
+		// otherwise unbound in every context.
+		if !m.hasModal() && !m.consumesTextInput() {
+			m.showBookmarks = true
+			m.activeContext = "bookmarks"
+			m.initBookmarks()
+		}
+		return m, nil
 	case "i":
 		// A context that binds "i" for itself answers before the issue modal,
 		// or the binding help advertises could never fire. Workspaces no longer
@@ -2222,7 +2328,7 @@ func isRootContext(ctx string) bool {
 		return true
 	case "git-status", "git-status-commits", "git-status-diff", "git-commit-preview", "git-no-repo":
 		return true
-	case "file-browser-tree", "file-browser-preview":
+	case "file-browser-tree", "file-browser-preview", "file-browser-structured":
 		return true
 	case "workspace-list", "workspace-preview":
 		return true
@@ -2245,7 +2351,9 @@ func isTextInputContext(ctx string) bool {
 		"global-workspaces-filter",
 		"global-workspaces-rename",
 		"global-workspaces-create",
-		"issue-input":
+		"issue-input",
+		"agent-message",
+		"script-prompt":
 		return true
 	default:
 		return false
@@ -2287,7 +2395,7 @@ func isGlobalRefreshContext(ctx string) bool {
 		return true
 
 	// File browser preview - 'r' refreshes (no text input)
-	case "file-browser-preview":
+	case "file-browser-preview", "file-browser-structured":
 		return true
 
 	// Contexts where 'r' should be forwarded to plugin:
@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
@@ -282,10 +283,16 @@ func (m Model) viewContent() string {
 		return m.renderThemeSwitcherModal(bg)
 	case ModalOpenIn:
 		return m.renderOpenInModal(bg)
+	case ModalBookmarks:
+		return m.renderBookmarksModal(bg)
 	case ModalIssueInput:
 		return m.renderIssueInputOverlay(bg)
 	case ModalIssuePreview:
 		return m.renderIssuePreviewOverlay(bg)
+	case ModalAgentMessage:
+		return m.renderAgentMessageModal(bg)
+	case ModalScriptPrompt:
+		return m.renderScriptPromptModal(bg)
 	}
 
 	return bg
@@ -762,18 +769,25 @@ const headerIndicatorMaxWidth = 5
 // renderHeaderIndicator paints the unread indicator that sits next to the
 // gear (design 1d). Its colour carries the loudest unread source, and it is
 // inverted while the notification centre is open, so the control that opened
-// the panel also shows that the panel is what is open.
+// the panel also shows that the panel is what is open. While do-not-disturb or
+// quiet hours hold toasts back it reads `◌`, muted, so a quiet screen is never
+// mistaken for a calm one.
 func (m Model) renderHeaderIndicator() string {
 	unread := m.UnreadNotifications()
 	hue, any := notify.LoudestHue(m.notificationCache)
 	text := "·"
+	count := ""
 	if any && unread > 0 {
-		count := "99+"
+		count = "99+"
 		if unread <= 99 {
 			count = strconv.Itoa(unread)
 		}
 		text = "●" + count
 	}
+	if notify.Quiet(time.Now()) {
+		text = "◌" + count
+		hue = notify.HueMuted
+	}
 	style := lipgloss.NewStyle().Foreground(notify.ResolveHue(hue))
 	if m.notificationCentreOpen {
 		style = lipgloss.NewStyle().Foreground(styles.BgPrimary).Background(notify.ResolveHue(hue))
@@ -0,0 +1,511 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marcus/sidecar/internal/state"
+	"github.com/marcus/sidecar/internal/uirequest"
+	"github.com/marcus/sidecar/internal/workspaceops"
+)
+
+func runBookmarkRoot(env Env, args []string) int {
+	cmd := RootCommand().FindSubcommand("bookmark")
+	if len(args) == 0 || isHelp(args[0]) {
+		_, _ = fmt.Fprint(env.Stdout, RenderHelp(cmd))
+		return 0
+	}
+	sub := cmd.FindSubcommand(args[0])
+	if sub != nil && sub.Run != nil {
+		return sub.Run(env, args[1:])
+	}
+	cliErrf(env.Stderr, "unknown bookmark command %q\n\n%s", args[0], RenderHelp(cmd))
+	return 2
+}
+
+// bookmarkScope is the project a bookmark command works in and the checkout
+// relative targets resolve against. --project names a registered project;
+// otherwise it is the checkout containing the working directory, filed under
+// that repository's main worktree so every worktree shares one list.
+type bookmarkScope struct {
+	Project string
+	WorkDir string
+}
+
+func resolveBookmarkScope(env Env, projectFlag string) (bookmarkScope, error) {
+	ctx := env.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if projectFlag != "" {
+		projects, err := loadRegisteredProjects(env.StateDir)
+		if err != nil {
+			return bookmarkScope{}, err
+		}
+		proj, err := matchProject(projects, projectFlag)
+		if err != nil {
+			return bookmarkScope{}, err
+		}
+		if proj.Path == "" {
+			return bookmarkScope{}, &destError{code: 1, msg: fmt.Sprintf("project %q has no recorded path", projectFlag)}
+		}
+		return bookmarkScope{Project: proj.Path, WorkDir: proj.Path}, nil
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return bookmarkScope{}, &destError{code: 1, msg: "resolve current directory: " + err.Error()}
+	}
+	scope := bookmarkScope{Project: wd, WorkDir: wd}
+	if root := workspaceops.WorktreeRoot(ctx, wd); root != "" {
+		scope.WorkDir = root
+		scope.Project = root
+	}
+	if main := workspaceops.MainWorktreePath(ctx, scope.WorkDir); main != "" {
+		scope.Project = main
+	}
+	return scope, nil
+}
+
+// bookmarkAuthor names whoever is adding from the command line: the Sidecar
+// shell's display name when there is one, so the user can tell which agent
+// left a bookmark, and "cli" otherwise.
+func bookmarkAuthor(env Env) string {
+	ctx := env.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if identity, err := currentShellIdentity(ctx); err == nil {
+		if res, err := lookupCurrentShellName(ctx, env.StateDir, identity); err == nil && res.Name != "" {
+			return res.Name
+		}
+	}
+	return "cli"
+}
+
+func runBookmarkAdd(env Env, args []string) int {
+	help := RenderHelp(RootCommand().FindSubcommand("bookmark").FindSubcommand("add"))
+
+	jsonOutput := false
+	wantDiff := false
+	label := ""
+	note := ""
+	lineNo := 0
+	projectFlag := ""
+	providerFlag := ""
+	shellFlag := ""
+	var positional []string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		value := func(flag string) (string, bool) {
+			if strings.HasPrefix(arg, flag+"=") {
+				v := strings.TrimPrefix(arg, flag+"=")
+				return v, v != ""
+			}
+			if arg == flag {
+				if i+1 >= len(args) {
+					return "", false
+				}
+				i++
+				return args[i], args[i] != ""
+			}
+			return "", false
+		}
+		switch {
+		case isHelp(arg):
+			_, _ = fmt.Fprint(env.Stdout, help)
+			return 0
+		case arg == "--json":
+			jsonOutput = true
+		case arg == "--diff":
+			wantDiff = true
+		case arg == "--label" || strings.HasPrefix(arg, "--label="):
+			v, ok := value("--label")
+			if !ok {
+				cliErrf(env.Stderr, "--label requires text\n\n%s", help)
+				return 2
+			}
+			label = strings.TrimSpace(v)
+		case arg == "--note" || strings.HasPrefix(arg, "--note="):
+			v, ok := value("--note")
+			if !ok {
+				cliErrf(env.Stderr, "--note requires text\n\n%s", help)
+				return 2
+			}
+			note = v
+		case arg == "--line" || strings.HasPrefix(arg, "--line="):
+			v, ok := value("--line")
+			n, err := strconv.Atoi(v)
+			if !ok || err != nil || n <= 0 {
+				cliErrf(env.Stderr, "invalid line number %q\n\n%s", v, help)
+				return 2
+			}
+			lineNo = n
+		case arg == "--project" || strings.HasPrefix(arg, "--project="):
+			v, ok := value("--project")
+			if !ok {
+				cliErrf(env.Stderr, "--project requires a project name\n\n%s", help)
+				return 2
+			}
+			projectFlag = v
+		case arg == "--provider" || strings.HasPrefix(arg, "--provider="):
+			v, ok := value("--provider")
+			if !ok {
+				cliErrf(env.Stderr, "--provider requires a provider instance id\n\n%s", help)
+				return 2
+			}
+			providerFlag = v
+		case arg == "--shell" || strings.HasPrefix(arg, "--shell="):
+			v, ok := value("--shell")
+			if !ok {
+				cliErrf(env.Stderr, "--shell requires a shell name\n\n%s", help)
+				return 2
+			}
+			shellFlag = v
+		default:
+			if strings.HasPrefix(arg, "-") {
+				cliErrf(env.Stderr, "unknown option %q\n\n%s", arg, help)
+				return 2
+			}
+			positional = append(positional, arg)
+		}
+	}
+
+	if providerFlag != "" && wantDiff {
+		cliErrf(env.Stderr, "--provider and --diff name different kinds of target\n\n%s", help)
+		return 2
+	}
+	if shellFlag != "" && (len(positional) > 0 || wantDiff || providerFlag != "" || lineNo > 0) {
+		cliErrf(env.Stderr, "--shell bookmarks a shell and takes no other target\n\n%s", help)
+		return 2
+	}
+	if shellFlag == "" && len(positional) != 1 && !(wantDiff && len(positional) == 0) {
+		cliErrf(env.Stderr, "bookmark add requires exactly one target\n\n%s", help)
+		return 2
+	}
+
+	scope, err := resolveBookmarkScope(env, projectFlag)
+	if err != nil {
+		cliErrln(env.Stderr, err)
+		return destExitCode(err)
+	}
+	var target uirequest.Target
+	if shellFlag != "" {
+		// A shell is pinned by its tmux session, which survives renames; the
+		// display name becomes the label so the picker still reads naturally.
+		projects, err := loadRegisteredProjects(env.StateDir)
+		if err != nil {
+			cliErrln(env.Stderr, err)
+			return destExitCode(err)
+		}
+		_, shell, err := matchShell(projects, shellFlag, false)
+		if err != nil {
+			cliErrln(env.Stderr, err)
+			return destExitCode(err)
+		}
+		target = uirequest.Target{Kind: uirequest.TargetKindSession, Value: shell.TmuxName}
+		if label == "" {
+			label = shell.DisplayName
+		}
+	} else {
+		raw := ""
+		if len(positional) == 1 {
+			raw = positional[0]
+		}
+		target, err = uirequest.ResolveTarget(scope.WorkDir, raw, lineNo, uirequest.ResolveOptions{Diff: wantDiff, Provider: providerFlag})
+		if err != nil {
+			cliErrf(env.Stderr, "validation error: %v\n\n%s", err, help)
+			return 2
+		}
+	}
+
+	b, err := state.AddBookmark(env.StateDir, state.Bookmark{
+		Project:   scope.Project,
+		WorkDir:   scope.WorkDir,
+		Target:    target,
+		Label:     label,
+		Note:      note,
+		CreatedBy: bookmarkAuthor(env),
+	})
+	if err != nil {
+		cliErrln(env.Stderr, err)
+		return 1
+	}
+
+	if jsonOutput {
+		return writeBookmarkJSON(env, b)
+	}
+	_, _ = fmt.Fprintf(env.Stdout, "Bookmarked %s as %s.\n", b.Location(), b.ID)
+	return 0
+}
+
+func runBookmarkList(env Env, args []string) int {
+	help := RenderHelp(RootCommand().FindSubcommand("bookmark").FindSubcommand("list"))
+
+	jsonOutput := false
+	all := false
+	projectFlag := ""
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case isHelp(arg):
+			_, _ = fmt.Fprint(env.Stdout, help)
+			return 0
+		case arg == "--json":
+			jsonOutput = true
+		case arg == "--all":
+			all = true
+		case arg == "--project":
+			if i+1 >= len(args) || args[i+1] == "" {
+				cliErrf(env.Stderr, "--project requires a project name\n\n%s", help)
+				return 2
+			}
+			i++
+			projectFlag = args[i]
+		case strings.HasPrefix(arg, "--project="):
+			projectFlag = strings.TrimPrefix(arg, "--project=")
+		default:
+			cliErrf(env.Stderr, "unknown option %q\n\n%s", arg, help)
+			return 2
+		}
+	}
+
+	var (
+		items []state.Bookmark
+		err   error
+		scope bookmarkScope
+	)
+	if all {
+		items, err = state.AllBookmarks(env.StateDir)
+	} else {
+		scope, err = resolveBookmarkScope(env, projectFlag)
+		if err != nil {
+			cliErrln(env.Stderr, err)
+			return destExitCode(err)
+		}
+		items, err = state.ProjectBookmarks(env.StateDir, scope.Project)
+	}
+	if err != nil {
+		cliErrln(env.Stderr, err)
+		return 1
+	}
+
+	if jsonOutput {
+		out := struct {
+			Project string           `json:"project,omitempty"`
+			Items   []state.Bookmark `json:"items"`
+		}{Project: scope.Project, Items: items}
+		if out.Items == nil {
+			out.Items = []state.Bookmark{}
+		}
+		if err := json.NewEncoder(env.Stdout).Encode(out); err != nil {
+			cliErrln(env.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+
+	if len(items) == 0 {
+		_, _ = fmt.Fprintln(env.Stdout, "No bookmarks.")
+		return 0
+	}
+	for i, b := range items {
+		line := fmt.Sprintf("%2d  %s  %s", i+1, b.ID, b.Title())
+		if b.Label != "" {
+			line += "  (" + b.Location() + ")"
+		}
+		if all {
+			line += "  [" + b.Project + "]"
+		}
+		if b.Note != "" {
+			line += "  — " + strings.ReplaceAll(b.Note, "\n", " ")
+		}
+		_, _ = fmt.Fprintln(env.Stdout, line)
+	}
+	return 0
+}
+
+// parseBookmarkRef reads the flags open and remove share and returns the one
+// bookmark reference. code is non-zero when the caller should return it.
+func parseBookmarkRef(env Env, help, verb string, args []string, extra func(arg string, args []string, i int) (next int, handled bool, code int)) (ref, projectFlag string, jsonOutput bool, code int, done bool) {
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case isHelp(arg):
+			_, _ = fmt.Fprint(env.Stdout, help)
+			return "", "", false, 0, true
+		case arg == "--json":
+			jsonOutput = true
+		case arg == "--project":
+			if i+1 >= len(args) || args[i+1] == "" {
+				cliErrf(env.Stderr, "--project requires a project name\n\n%s", help)
+				return "", "", false, 2, true
+			}
+			i++
+			projectFlag = args[i]
+		case strings.HasPrefix(arg, "--project="):
+			projectFlag = strings.TrimPrefix(arg, "--project=")
+		default:
+			if extra != nil {
+				next, handled, c := extra(arg, args, i)
+				if c != 0 {
+					return "", "", false, c, true
+				}
+				if handled {
+					i = next
+					continue
+				}
+			}
+			if strings.HasPrefix(arg, "-") {
+				cliErrf(env.Stderr, "unknown option %q\n\n%s", arg, help)
+				return "", "", false, 2, true
+			}
+			positional = append(positional, arg)
+		}
+	}
+	if len(positional) != 1 {
+		cliErrf(env.Stderr, "bookmark %s requires exactly one bookmark id or number\n\n%s", verb, help)
+		return "", "", false, 2, true
+	}
+	return positional[0], projectFlag, jsonOutput, 0, false
+}
+
+// findScopedBookmark resolves ref within the scope's project. A full id also
+// finds a bookmark filed under another project, so an id copied from
+// `list --all` works from anywhere.
+func findScopedBookmark(env Env, projectFlag, ref string) (state.Bookmark, int) {
+	scope, err := resolveBookmarkScope(env, projectFlag)
+	if err != nil {
+		cliErrln(env.Stderr, err)
+		return state.Bookmark{}, destExitCode(err)
+	}
+	list, err := state.ProjectBookmarks(env.StateDir, scope.Project)
+	if err != nil {
+		cliErrln(env.Stderr, err)
+		return state.Bookmark{}, 1
+	}
+	b, err := state.FindBookmark(list, ref)
+	if errors.Is(err, state.ErrBookmarkNotFound) {
+		everything, readErr := state.AllBookmarks(env.StateDir)
+		if readErr == nil {
+			for _, other := range everything {
+				if other.ID == ref {
+					return other, 0
+				}
+			}
+		}
+		cliErrf(env.Stderr, "no bookmark %q in %s\n", ref, scope.Project)
+		return state.Bookmark{}, 3
+	}
+	if err != nil {
+		cliErrln(env.Stderr, err)
+		return state.Bookmark{}, 2
+	}
+	return b, 0
+}
+
+func runBookmarkOpen(env Env, args []string) int {
+	help := RenderHelp(RootCommand().FindSubcommand("bookmark").FindSubcommand("open"))
+
+	splitMode := "auto"
+	waitDuration := 1200 * time.Millisecond
+	shellFlag := ""
+	extra := func(arg string, args []string, i int) (int, bool, int) {
+		switch {
+		case arg == "--split" || strings.HasPrefix(arg, "--split="):
+			v, next, ok := takeFlagArg(arg, args, i, "--split")
+			v = strings.ToLower(v)
+			if !ok || (v != "auto" && v != "right" && v != "below") {
+				cliErrf(env.Stderr, "invalid split option %q (must be auto, right, or below)\n\n%s", v, help)
+				return i, false, 2
+			}
+			splitMode = v
+			return next, true, 0
+		case arg == "--wait" || strings.HasPrefix(arg, "--wait="):
+			v, next, ok := takeFlagArg(arg, args, i, "--wait")
+			d, err := parseWaitDuration(v)
+			if !ok || err != nil {
+				cliErrf(env.Stderr, "invalid wait duration %q\n\n%s", v, help)
+				return i, false, 2
+			}
+			waitDuration = d
+			return next, true, 0
+		case arg == "--shell" || strings.HasPrefix(arg, "--shell="):
+			v, next, ok := takeFlagArg(arg, args, i, "--shell")
+			if !ok || v == "" {
+				cliErrf(env.Stderr, "--shell requires a shell name\n\n%s", help)
+				return i, false, 2
+			}
+			shellFlag = v
+			return next, true, 0
+		}
+		return i, false, 0
+	}
+	ref, projectFlag, jsonOutput, code, done := parseBookmarkRef(env, help, "open", args, extra)
+	if done {
+		return code
+	}
+
+	b, code := findScopedBookmark(env, projectFlag, ref)
+	if code != 0 {
+		return code
+	}
+
+	ctx := env.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	dest, err := resolveOpenDestination(ctx, env.StateDir, shellFlag, projectFlag)
+	if err != nil {
+		cliErrln(env.Stderr, err)
+		return destExitCode(err)
+	}
+	// The target was resolved against the checkout it was taken in; the
+	// instance must resolve it there too, not in whichever worktree the
+	// destination shell happens to be showing.
+	if b.WorkDir != "" {
+		dest.Origin.WorkDir = b.WorkDir
+	}
+	return deliverOpen(env, dest, b.Target, splitMode, waitDuration, jsonOutput)
+}
+
+func runBookmarkRemove(env Env, args []string) int {
+	help := RenderHelp(RootCommand().FindSubcommand("bookmark").FindSubcommand("remove"))
+
+	ref, projectFlag, jsonOutput, code, done := parseBookmarkRef(env, help, "remove", args, nil)
+	if done {
+		return code
+	}
+	b, code := findScopedBookmark(env, projectFlag, ref)
+	if code != 0 {
+		return code
+	}
+	if _, err := state.RemoveBookmark(env.StateDir, b.ID); err != nil {
+		if errors.Is(err, state.ErrBookmarkNotFound) {
+			cliErrf(env.Stderr, "no bookmark %q\n", b.ID)
+			return 3
+		}
+		cliErrln(env.Stderr, err)
+		return 1
+	}
+	if jsonOutput {
+		return writeBookmarkJSON(env, b)
+	}
+	_, _ = fmt.Fprintf(env.Stdout, "Removed %s (%s).\n", b.ID, b.Title())
+	return 0
+}
+
+func writeBookmarkJSON(env Env, b state.Bookmark) int {
+	if err := json.NewEncoder(env.Stdout).Encode(b); err != nil {
+		cliErrln(env.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/marcus/sidecar/internal/state"
+)
+
+// bookmarkProject makes a plain directory (no git) the working directory, so
+// the bookmark scope is the directory itself.
+func bookmarkProject(t *testing.T) string {
+	t.Helper()
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+	return dir
+}
+
+func TestBookmarkAddListRemove(t *testing.T) {
+	env, out, errOut := notifyEnv(t)
+	project := bookmarkProject(t)
+
+	if code := runBookmarkAdd(env, []string{"--label", "entry", "--note", "look here", "--json", "main.go:1"}); code != 0 {
+		t.Fatalf("add = %d, stderr %q", code, errOut.String())
+	}
+	var added state.Bookmark
+	if err := json.Unmarshal(out.Bytes(), &added); err != nil {
+		t.Fatalf("add --json: %v in %q", err, out.String())
+	}
+	if added.Project != project || added.Target.Value != "main.go" || added.Target.Line != 1 {
+		t.Fatalf("added = %+v", added)
+	}
+	if added.CreatedBy != "cli" {
+		t.Fatalf("createdBy = %q, want cli outside a Sidecar shell", added.CreatedBy)
+	}
+
+	out.Reset()
+	if code := runBookmarkList(env, []string{"--json"}); code != 0 {
+		t.Fatalf("list = %d, stderr %q", code, errOut.String())
+	}
+	var listed struct {
+		Project string           `json:"project"`
+		Items   []state.Bookmark `json:"items"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &listed); err != nil {
+		t.Fatal(err)
+	}
+	if listed.Project != project || len(listed.Items) != 1 || listed.Items[0].ID != added.ID {
+		t.Fatalf("list = %+v", listed)
+	}
+
+	out.Reset()
+	if code := runBookmarkRemove(env, []string{"1"}); code != 0 {
+		t.Fatalf("remove = %d, stderr %q", code, errOut.String())
+	}
+	if !strings.Contains(out.String(), added.ID) {
+		t.Fatalf("remove output %q does not name %s", out.String(), added.ID)
+	}
+	if code := runBookmarkRemove(env, []string{added.ID}); code != 3 {
+		t.Fatalf("removing twice = %d, want 3", code)
+	}
+}
+
+func TestBookmarkAddRejectsMissingFile(t *testing.T) {
+	env, _, errOut := notifyEnv(t)
+	bookmarkProject(t)
+	if code := runBookmarkAdd(env, []string{"nope.go"}); code != 2 {
+		t.Fatalf("add of a missing file = %d, want 2", code)
+	}
+	if !strings.Contains(errOut.String(), "does not exist") {
+		t.Fatalf("stderr %q", errOut.String())
+	}
+}
+
+func TestBookmarkOpenWithoutInstance(t *testing.T) {
+	env, _, errOut := notifyEnv(t)
+	bookmarkProject(t)
+	if code := runBookmarkAdd(env, []string{"main.go"}); code != 0 {
+		t.Fatalf("add = %d, stderr %q", code, errOut.String())
+	}
+	if code := runBookmarkOpen(env, []string{"1"}); code != 3 {
+		t.Fatalf("open with no instance = %d, want 3; stderr %q", code, errOut.String())
+	}
+}
@@ -140,35 +140,28 @@ func runCreateShellWorkspace(env Env, dest openDestination, flags createCommonFl
 		return 2
 	}
 
-	display, session := workspaceops.ShellNames(proj.Path, existingShellDefinitions(proj))
+	name := ""
 	if custom := strings.TrimSpace(nameFlag); custom != "" {
 		var err error
-		display, err = shellstate.NormalizeName(custom)
+		name, err = shellstate.NormalizeName(custom)
 		if err != nil {
 			cliErrln(env.Stderr, err)
 			return 2
 		}
 	}
 
-	spec := workspaceops.ManagedShellSpec{
-		ShellSpec: workspaceops.ShellSpec{
-			WorkDir:     proj.Path,
-			SessionName: session,
-			DisplayName: display,
-		},
+	created, err := workspaceops.CreateShellWorkspace(ctx, workspaceops.ShellWorkspaceSpec{
 		ProjectRoot: proj.Path,
-	}
-	if _, err := workspaceops.CreateManagedShell(spec); err != nil {
+		Existing:    existingShellDefinitions(proj),
+		Name:        name,
+		Run:         runCmd,
+		Type:        typeCmd,
+	})
+	if err != nil {
 		cliErrln(env.Stderr, err)
 		return 1
 	}
-
-	var seedErr error
-	if runCmd != "" {
-		seedErr = workspaceops.StartAgentInShell(ctx, session, runCmd)
-	} else if typeCmd != "" {
-		seedErr = workspaceops.TypeInShell(ctx, session, typeCmd)
-	}
+	display, session, seedErr := created.DisplayName, created.Session, created.SeedErr
 
 	focus := true
 	payload := uirequest.CreatePayload{
@@ -3,6 +3,7 @@ package cli
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -116,69 +117,31 @@ func runCreateWorktree(env Env, args []string) int {
 		workDir = dest.Origin.WorkDir
 	}
 
-	plan, err := workspaceops.ResolveWorktreePlan(ctx, workDir, proj.Path, positional[0], base, dirPrefix, setup)
+	created, err := workspaceops.CreateWorktreeWorkspace(ctx, workspaceops.WorktreeWorkspaceSpec{
+		WorkDir:     workDir,
+		ProjectRoot: proj.Path,
+		Name:        positional[0],
+		Base:        base,
+		DirPrefix:   dirPrefix,
+		Setup:       setup,
+		AgentStart:  cfg.Plugins.Workspace.AgentStart,
+		Agent:       agent,
+		SkipPerms:   skipPerms,
+		Run:         runCmd,
+		NoLaunch:    noLaunch,
+		OperationID: fmt.Sprintf("cli-%d", time.Now().UnixNano()),
+	})
 	if err != nil {
 		cliErrln(env.Stderr, err)
-		return 2
-	}
-	if repoKey, keyErr := workspaceops.RepoKeyForPath(ctx, proj.Path); keyErr == nil {
-		plan.RepoKey = repoKey
-	} else {
-		plan.RepoKey = workspaceops.StablePathKey(proj.Path)
-	}
-	plan.OperationID = fmt.Sprintf("cli-%d", time.Now().UnixNano())
-	plan.AgentType = agent
-	plan.SkipPerms = skipPerms
-
-	record, err := workspaceops.ExecuteWorktree(ctx, plan.RepoKey, plan)
-	if record == nil {
-		cliErrln(env.Stderr, err)
-		return 1
-	}
-	outcomes := make([]workspaceops.SetupOutcome, 0)
-	if journalErr := workspaceops.PersistPendingCreation(ctx, plan, record); journalErr != nil {
-		outcomes = append(outcomes, workspaceops.SetupOutcome{Kind: "journal", Action: "persist recovery", Required: true, Err: journalErr})
-	}
-	if err != nil {
-		cliErrln(env.Stderr, err)
-		return 1
-	}
-	outcomes = append(outcomes, workspaceops.PersistWorktreeIdentity(ctx, plan)...)
-	outcomes = append(outcomes, workspaceops.RunConfiguredSetup(ctx, plan)...)
-
-	requiredFailed := setupOutcomesFailed(outcomes, true)
-	if len(requiredFailed) == 0 {
-		if journalErr := workspaceops.RemovePendingCreation(plan); journalErr != nil {
-			outcomes = append(outcomes, workspaceops.SetupOutcome{Kind: "journal", Action: "finalize pending creation", Required: true, Err: journalErr})
-			requiredFailed = setupOutcomesFailed(outcomes, true)
-		}
-	}
-
-	session := workspaceops.WorktreeSessionName(record.Path, record.Name)
-	var launchErr error
-	if !noLaunch && len(requiredFailed) == 0 {
-		configured := map[string]string(nil)
-		if cfg != nil {
-			configured = cfg.Plugins.Workspace.AgentStart
-		}
-		startAgent := agent != "" || runCmd != ""
-		command := ""
-		if agent != "" {
-			command = workspaceops.ResolveAgentCommand(record.Path, agent, configured, skipPerms)
-		} else if runCmd != "" {
-			command = runCmd
-		}
-		_, launchErr = workspaceops.LaunchWorktreeSession(ctx, workspaceops.AgentLaunchSpec{
-			SessionName:  session,
-			WorkDir:      record.Path,
-			AgentCommand: command,
-			Env:          workspaceops.BuildEnvOverrides(plan.MainWorktree),
-			StartAgent:   startAgent,
-		})
-		if launchErr == nil && agent != "" && runCmd != "" {
-			launchErr = workspaceops.StartAgentInShell(ctx, session, runCmd)
+		var planErr *workspaceops.PlanError
+		if errors.As(err, &planErr) {
+			return 2
 		}
+		return 1
 	}
+	record, session, outcomes := created.Record, created.Session, created.Outcomes
+	requiredFailed := created.RequiredFailed()
+	launchErr := created.LaunchErr
 
 	focus := true
 	payload := uirequest.CreatePayload{
@@ -227,7 +190,7 @@ func runCreateWorktree(env Env, args []string) int {
 		_, _ = fmt.Fprintf(env.Stdout, "Created worktree %q (%s) on %s.\n", record.Name, record.Path, record.Branch)
 	}
 	if len(failed) > 0 {
-		cliErrln(env.Stderr, summarizeSetupOutcomes(failed))
+		cliErrln(env.Stderr, workspaceops.SummarizeSetup(failed))
 		return 1
 	}
 	if launchErr != nil {
@@ -273,23 +236,3 @@ func encodeSetupOutcomes(outcomes []workspaceops.SetupOutcome) []createSetupOutc
 	}
 	return out
 }
-
-func setupOutcomesFailed(outcomes []workspaceops.SetupOutcome, requiredOnly bool) []workspaceops.SetupOutcome {
-	var failed []workspaceops.SetupOutcome
-	for _, outcome := range outcomes {
-		if outcome.Err != nil && (!requiredOnly || outcome.Required) {
-			failed = append(failed, outcome)
-		}
-	}
-	return failed
-}
-
-func summarizeSetupOutcomes(outcomes []workspaceops.SetupOutcome) string {
-	parts := make([]string, 0, len(outcomes))
-	for _, outcome := range outcomes {
-		if outcome.Err != nil {
-			parts = append(parts, outcome.Action+": "+outcome.Err.Error())
-		}
-	}
-	return strings.Join(parts, "; ")
-}
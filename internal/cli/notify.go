@@ -10,7 +10,10 @@ import (
 
 	"github.com/marcus/sidecar/internal/config"
 	"github.com/marcus/sidecar/internal/notify"
+	"github.com/marcus/sidecar/internal/notifysink"
 	"github.com/marcus/sidecar/internal/uirequest"
+	"github.com/marcus/sidecar/internal/workspacediff"
+	"github.com/marcus/sidecar/internal/workspaceops"
 )
 
 // notifyWait is how long a post or dismiss waits for a running instance to
@@ -41,6 +44,7 @@ func runNotifyPost(env Env, args []string) int {
 	source := string(notify.SourceAgent)
 	expiry := ""
 	var targetSpecs []string
+	var actionNames []string
 	var positional []string
 
 	for i := 0; i < len(args); i++ {
@@ -92,6 +96,13 @@ func runNotifyPost(env Env, args []string) int {
 				return 2
 			}
 			targetSpecs = append(targetSpecs, v)
+		case arg == "--action" || strings.HasPrefix(arg, "--action="):
+			v, ok := value("--action")
+			if !ok {
+				cliErrf(env.Stderr, "--action requires %s\n\n%s", strings.Join(notify.ActionKindNames(), ", "), help)
+				return 2
+			}
+			actionNames = append(actionNames, v)
 		default:
 			if strings.HasPrefix(arg, "-") {
 				cliErrf(env.Stderr, "unknown option %q\n\n%s", arg, help)
@@ -105,6 +116,14 @@ func runNotifyPost(env Env, args []string) int {
 		cliErrf(env.Stderr, "notify post requires exactly one title\n\n%s", help)
 		return 2
 	}
+	// The user's sources and per-source expiries live in config, and this
+	// process is the one validating and completing the record — without this a
+	// `sidecar notify post` would refuse a registered source and carry the
+	// built-in expiry while the TUI used the configured one.
+	cfg, err := config.Load()
+	if err == nil {
+		notify.ApplyConfig(cfg.Notifications)
+	}
 	if !notify.ValidSource(notify.SourceID(source)) {
 		cliErrf(env.Stderr, "unknown source %q (one of: %s)\n\n%s", source, strings.Join(notify.SourceIDs(), ", "), help)
 		return 2
@@ -118,6 +137,15 @@ func runNotifyPost(env Env, args []string) int {
 		cliErrf(env.Stderr, "%s\n\n%s", err, help)
 		return 2
 	}
+	var actions []notify.Action
+	for _, name := range actionNames {
+		kind, err := notify.ParseActionKind(name)
+		if err != nil {
+			cliErrf(env.Stderr, "%s\n\n%s", err, help)
+			return 2
+		}
+		actions = append(actions, notify.Action{Kind: kind})
+	}
 
 	n := notify.Notification{
 		ID:      notify.NewID(),
@@ -127,6 +155,7 @@ func runNotifyPost(env Env, args []string) int {
 		Origin:  notifyOrigin(env),
 		Sticky:  false,
 		Targets: targets,
+		Actions: actions,
 	}
 	switch strings.TrimSpace(strings.ToLower(expiry)) {
 	case "":
@@ -142,12 +171,6 @@ func runNotifyPost(env Env, args []string) int {
 		exp := time.Now().UTC().Add(d)
 		n.ExpiresAt = &exp
 	}
-	// The user's per-source expiries live in config, and this process is the
-	// one completing the record — without this a `sidecar notify post` would
-	// carry the built-in default while the TUI used the configured one.
-	if cfg, err := config.Load(); err == nil {
-		notify.ApplyConfig(cfg.Notifications)
-	}
 	n = notify.Normalize(n, time.Now())
 
 	payload, err := json.Marshal(n)
@@ -172,10 +195,17 @@ func runNotifyPost(env Env, args []string) int {
 			return 1
 		}
 		defer func() { _ = store.Close() }()
-		if _, err := store.Post(n); err != nil {
+		stored, err := store.Post(n)
+		if err != nil {
 			cliErrln(env.Stderr, err)
 			return 1
 		}
+		// With no instance to deliver it, this process does: the desktop and
+		// webhook sinks are how a notification reaches a user who has no
+		// Sidecar open. OSC sinks are not, since this terminal is the agent's.
+		if cfg != nil {
+			deliverFallback(env, cfg.Notifications, stored)
+		}
 	}
 
 	if jsonOutput {
@@ -189,6 +219,20 @@ func runNotifyPost(env Env, args []string) int {
 	return 0
 }
 
+// deliverFallback runs the configured sinks for a notification no instance
+// took, bounded so a dead webhook cannot hold an agent's shell hostage.
+func deliverFallback(env Env, cfg config.NotificationsConfig, n notify.Notification) {
+	sinks, _ := notifysink.New(cfg)
+	if sinks.Empty() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	for _, err := range sinks.Deliver(ctx, n, time.Now()) {
+		cliErrf(env.Stderr, "warning: %s\n", err)
+	}
+}
+
 func runNotifyDismiss(env Env, args []string) int {
 	help := RenderHelp(RootCommand().FindSubcommand("notify").FindSubcommand("dismiss"))
 
@@ -260,6 +304,140 @@ func runNotifyDismiss(env Env, args []string) int {
 	return 0
 }
 
+// notifyActWait bounds the keystrokes `notify act` types, the same budget the
+// app gives its own answers.
+const notifyActWait = 5 * time.Second
+
+// answerAgent is the send path, replaced in tests.
+var answerAgent = workspaceops.AnswerAgent
+
+func runNotifyAct(env Env, args []string) int {
+	help := RenderHelp(RootCommand().FindSubcommand("notify").FindSubcommand("act"))
+
+	jsonOutput := false
+	message := ""
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case isHelp(arg):
+			_, _ = fmt.Fprint(env.Stdout, help)
+			return 0
+		case arg == "--json":
+			jsonOutput = true
+		case arg == "--message" || strings.HasPrefix(arg, "--message="):
+			if v, ok := strings.CutPrefix(arg, "--message="); ok {
+				message = v
+				continue
+			}
+			if i+1 >= len(args) {
+				cliErrf(env.Stderr, "--message requires text\n\n%s", help)
+				return 2
+			}
+			i++
+			message = args[i]
+		default:
+			if strings.HasPrefix(arg, "-") {
+				cliErrf(env.Stderr, "unknown option %q\n\n%s", arg, help)
+				return 2
+			}
+			positional = append(positional, arg)
+		}
+	}
+	if len(positional) != 2 {
+		cliErrf(env.Stderr, "notify act requires a notification id and an action\n\n%s", help)
+		return 2
+	}
+	id := positional[0]
+	kind, err := notify.ParseActionKind(positional[1])
+	if err != nil {
+		cliErrf(env.Stderr, "%s\n\n%s", err, help)
+		return 2
+	}
+	var strokes []notify.Keystroke
+	if kind.Types() {
+		// Checked before the log is read: an empty message is the caller's
+		// mistake whatever the notification says.
+		if strokes, err = notify.Keystrokes(kind, message); err != nil {
+			cliErrf(env.Stderr, "%s (--message)\n\n%s", err, help)
+			return 2
+		}
+	}
+
+	all, err := notify.ReadAll(notify.Path(env.StateDir))
+	if err != nil {
+		cliErrln(env.Stderr, err)
+		return 1
+	}
+	var target notify.Notification
+	found := false
+	for _, n := range all {
+		if n.ID == id {
+			target, found = n, true
+			break
+		}
+	}
+	if !found {
+		cliErrf(env.Stderr, "no notification with id %q\n", id)
+		return 3
+	}
+	if !notify.HasAction(target, kind) {
+		if target.Dismissed() {
+			cliErrf(env.Stderr, "notification %s was dismissed; it no longer offers %s\n", id, kind)
+		} else {
+			cliErrf(env.Stderr, "notification %s does not offer %s\n", id, kind)
+		}
+		return 4
+	}
+	// An agent answering its own prompt is the thing the prompt exists to
+	// prevent: approval is the user's, so the caller must be somewhere else.
+	if kind.Types() && target.Origin.Matches(notifyOrigin(env)) {
+		cliErrf(env.Stderr, "notification %s was raised from this session; it cannot be answered from it\n", id)
+		return 4
+	}
+
+	delivered := false
+	if kind == notify.ActionOpenDiff {
+		var outcome deliveryOutcome
+		delivered, outcome = notifyDeliver(env, uirequest.Request{
+			Origin:  uirequest.Origin{ProjectKey: target.Origin.ProjectKey, WorkDir: target.Origin.WorkDir, PID: os.Getpid()},
+			Action:  uirequest.ActionOpen,
+			Target:  uirequest.Target{Kind: uirequest.TargetKindDiff, Value: workspacediff.IdentityWorkingTree},
+			Options: uirequest.Options{Split: "auto"},
+		})
+		if !delivered {
+			cliErrf(env.Stderr, "could not open the diff: %s\n", outcome.explain())
+			return 1
+		}
+	} else {
+		ctx := env.Ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		ctx, cancel := context.WithTimeout(ctx, notifyActWait)
+		defer cancel()
+		if err := answerAgent(ctx, target.Origin.TmuxSession, strokes); err != nil {
+			cliErrf(env.Stderr, "could not answer %s: %v\n", id, err)
+			return 1
+		}
+	}
+
+	if jsonOutput {
+		return writeNotifyJSON(env, notifyResult{Action: "act", ID: id, Kind: kind, Delivered: delivered})
+	}
+	verb := "Sent a message to"
+	switch kind {
+	case notify.ActionApprove:
+		verb = "Approved"
+	case notify.ActionDeny:
+		verb = "Denied"
+	case notify.ActionOpenDiff:
+		verb = "Opened the diff for"
+	}
+	_, _ = fmt.Fprintf(env.Stdout, "%s %s (%s).\n", verb, id, notify.CTATitle(target))
+	return 0
+}
+
 func runNotifyList(env Env, args []string) int {
 	help := RenderHelp(RootCommand().FindSubcommand("notify").FindSubcommand("list"))
 
@@ -348,10 +526,12 @@ func notifyDismissRequest(caller notify.Origin, id string) uirequest.Request {
 	}
 }
 
-// notifyResult is the --json shape for post and dismiss.
+// notifyResult is the --json shape for post, act and dismiss.
 type notifyResult struct {
 	Action string `json:"action"`
 	ID     string `json:"id"`
+	// Kind is the quick action act ran.
+	Kind notify.ActionKind `json:"kind,omitempty"`
 	// Delivered reports whether a running instance took it. False means the
 	// log was written directly and it appears at the next start.
 	Delivered    bool                 `json:"delivered"`
@@ -0,0 +1,287 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/notify"
+)
+
+func runNotifySourceRoot(env Env, args []string) int {
+	cmd := RootCommand().FindSubcommand("notify").FindSubcommand("source")
+	if len(args) == 0 || isHelp(args[0]) {
+		_, _ = fmt.Fprint(env.Stdout, RenderHelp(cmd))
+		return 0
+	}
+	sub := cmd.FindSubcommand(args[0])
+	if sub != nil && sub.Run != nil {
+		return sub.Run(env, args[1:])
+	}
+	cliErrf(env.Stderr, "unknown notify source command %q\n\n%s", args[0], RenderHelp(cmd))
+	return 2
+}
+
+// notifySourceJSON is the --json shape of one source.
+type notifySourceJSON struct {
+	ID       string `json:"id"`
+	Label    string `json:"label"`
+	Glyph    string `json:"glyph"`
+	Hue      string `json:"hue"`
+	Priority int    `json:"priority"`
+	// Expiry is the toast lifetime in effect, "sticky" for none.
+	Expiry  string `json:"expiry"`
+	Toast   bool   `json:"toast"`
+	BuiltIn bool   `json:"builtIn"`
+}
+
+func sourceJSON(s notify.Source) notifySourceJSON {
+	expiry := "sticky"
+	if d := notify.ExpiryFor(s.ID); d > 0 {
+		expiry = d.String()
+	}
+	return notifySourceJSON{
+		ID: string(s.ID), Label: s.Label, Glyph: s.Glyph, Hue: string(s.Hue), Priority: s.Priority,
+		Expiry: expiry, Toast: !s.Silent, BuiltIn: notify.Builtin(s.ID),
+	}
+}
+
+func runNotifySourceAdd(env Env, args []string) int {
+	help := RenderHelp(RootCommand().FindSubcommand("notify").FindSubcommand("source").FindSubcommand("add"))
+
+	jsonOutput := false
+	entry := config.NotificationSourceConfig{}
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		value := func(flag string) (string, bool) {
+			if strings.HasPrefix(arg, flag+"=") {
+				return strings.TrimPrefix(arg, flag+"="), true
+			}
+			if arg == flag {
+				if i+1 >= len(args) {
+					return "", false
+				}
+				i++
+				return args[i], true
+			}
+			return "", false
+		}
+		flagValue := func(flag, what string) (string, bool) {
+			v, ok := value(flag)
+			if !ok {
+				cliErrf(env.Stderr, "%s requires %s\n\n%s", flag, what, help)
+			}
+			return v, ok
+		}
+		var ok bool
+		switch {
+		case isHelp(arg):
+			_, _ = fmt.Fprint(env.Stdout, help)
+			return 0
+		case arg == "--json":
+			jsonOutput = true
+		case arg == "--no-toast":
+			off := false
+			entry.Toast = &off
+		case arg == "--label" || strings.HasPrefix(arg, "--label="):
+			if entry.Label, ok = flagValue("--label", "text"); !ok {
+				return 2
+			}
+		case arg == "--glyph" || strings.HasPrefix(arg, "--glyph="):
+			if entry.Glyph, ok = flagValue("--glyph", "a character"); !ok {
+				return 2
+			}
+		case arg == "--hue" || strings.HasPrefix(arg, "--hue="):
+			if entry.Hue, ok = flagValue("--hue", "a palette name"); !ok {
+				return 2
+			}
+		case arg == "--expiry" || strings.HasPrefix(arg, "--expiry="):
+			if entry.Expiry, ok = flagValue("--expiry", "a duration"); !ok {
+				return 2
+			}
+		case arg == "--priority" || strings.HasPrefix(arg, "--priority="):
+			v, ok := flagValue("--priority", "a number")
+			if !ok {
+				return 2
+			}
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > 100 {
+				cliErrf(env.Stderr, "invalid priority %q (1-100)\n\n%s", v, help)
+				return 2
+			}
+			entry.Priority = n
+		default:
+			if strings.HasPrefix(arg, "-") {
+				cliErrf(env.Stderr, "unknown option %q\n\n%s", arg, help)
+				return 2
+			}
+			positional = append(positional, arg)
+		}
+	}
+	if len(positional) != 1 {
+		cliErrf(env.Stderr, "notify source add requires exactly one source id\n\n%s", help)
+		return 2
+	}
+	id := positional[0]
+	if notify.Builtin(notify.SourceID(id)) {
+		cliErrf(env.Stderr, "%q is a built-in source; set its expiry or toast in the notifications section of config.json\n", id)
+		return 2
+	}
+	if entry.Label == "" {
+		entry.Label = strings.ToUpper(id)
+	}
+	if entry.Expiry != "" {
+		switch strings.ToLower(entry.Expiry) {
+		case "0", "never", "sticky":
+			entry.Expiry = "sticky"
+		default:
+			if d, err := time.ParseDuration(entry.Expiry); err != nil || d < 0 {
+				cliErrf(env.Stderr, "invalid expiry %q (a duration such as 10s, or \"never\")\n\n%s", entry.Expiry, help)
+				return 2
+			}
+		}
+	}
+	// Checked before anything is written: a source the registry would refuse
+	// must not sit in config.json looking registered.
+	src, err := notify.CheckCustom(notify.Source{
+		ID: notify.SourceID(id), Label: entry.Label, Glyph: entry.Glyph, Hue: notify.Hue(entry.Hue), Priority: entry.Priority,
+	})
+	if err != nil {
+		cliErrf(env.Stderr, "%s\n\n%s", err, help)
+		return 2
+	}
+	entry.Label, entry.Glyph, entry.Hue, entry.Priority = src.Label, src.Glyph, string(src.Hue), src.Priority
+
+	if err := config.SaveNotifications(func(n *config.NotificationsConfig) {
+		if n.Sources == nil {
+			n.Sources = map[string]config.NotificationSourceConfig{}
+		}
+		n.Sources[id] = entry
+	}); err != nil {
+		cliErrln(env.Stderr, err)
+		return 1
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		cliErrln(env.Stderr, err)
+		return 1
+	}
+	notify.ApplyConfig(cfg.Notifications)
+	registered := notify.SourceOf(notify.SourceID(id))
+	if jsonOutput {
+		return writeNotifySourcesJSON(env, []notify.Source{registered})
+	}
+	_, _ = fmt.Fprintf(env.Stdout, "Registered %s %s (%s).\n", registered.Glyph, registered.Label, id)
+	return 0
+}
+
+func runNotifySourceRemove(env Env, args []string) int {
+	help := RenderHelp(RootCommand().FindSubcommand("notify").FindSubcommand("source").FindSubcommand("remove"))
+
+	jsonOutput := false
+	var positional []string
+	for _, arg := range args {
+		switch {
+		case isHelp(arg):
+			_, _ = fmt.Fprint(env.Stdout, help)
+			return 0
+		case arg == "--json":
+			jsonOutput = true
+		default:
+			if strings.HasPrefix(arg, "-") {
+				cliErrf(env.Stderr, "unknown option %q\n\n%s", arg, help)
+				return 2
+			}
+			positional = append(positional, arg)
+		}
+	}
+	if len(positional) != 1 {
+		cliErrf(env.Stderr, "notify source remove requires exactly one source id\n\n%s", help)
+		return 2
+	}
+	id := positional[0]
+	if notify.Builtin(notify.SourceID(id)) {
+		cliErrf(env.Stderr, "%q is a built-in source and cannot be removed\n", id)
+		return 2
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		cliErrln(env.Stderr, err)
+		return 1
+	}
+	if entry, ok := cfg.Notifications.Sources[id]; !ok || entry.Label == "" {
+		cliErrf(env.Stderr, "no registered source %q\n", id)
+		return 3
+	}
+	if err := config.SaveNotifications(func(n *config.NotificationsConfig) {
+		delete(n.Sources, id)
+	}); err != nil {
+		cliErrln(env.Stderr, err)
+		return 1
+	}
+
+	if jsonOutput {
+		return writeNotifyJSON(env, notifyResult{Action: "remove-source", ID: id})
+	}
+	_, _ = fmt.Fprintf(env.Stdout, "Removed %s; its notifications now file under system.\n", id)
+	return 0
+}
+
+func runNotifySourceList(env Env, args []string) int {
+	help := RenderHelp(RootCommand().FindSubcommand("notify").FindSubcommand("source").FindSubcommand("list"))
+
+	jsonOutput := false
+	for _, arg := range args {
+		switch {
+		case isHelp(arg):
+			_, _ = fmt.Fprint(env.Stdout, help)
+			return 0
+		case arg == "--json":
+			jsonOutput = true
+		default:
+			cliErrf(env.Stderr, "unknown option %q\n\n%s", arg, help)
+			return 2
+		}
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		cliErrln(env.Stderr, err)
+		return 1
+	}
+	notify.ApplyConfig(cfg.Notifications)
+	all := notify.Sources()
+	if jsonOutput {
+		return writeNotifySourcesJSON(env, all)
+	}
+	for _, s := range all {
+		j := sourceJSON(s)
+		line := fmt.Sprintf("%s %-10s %-10s %-9s %3d  %s", s.Glyph, j.ID, j.Label, j.Hue, j.Priority, j.Expiry)
+		if !j.Toast {
+			line += "  no toast"
+		}
+		if !j.BuiltIn {
+			line += "  (custom)"
+		}
+		_, _ = fmt.Fprintln(env.Stdout, line)
+	}
+	return 0
+}
+
+func writeNotifySourcesJSON(env Env, list []notify.Source) int {
+	out := struct {
+		Sources []notifySourceJSON `json:"sources"`
+	}{Sources: make([]notifySourceJSON, 0, len(list))}
+	for _, s := range list {
+		out.Sources = append(out.Sources, sourceJSON(s))
+	}
+	if err := json.NewEncoder(env.Stdout).Encode(out); err != nil {
+		cliErrln(env.Stderr, err)
+		return 1
+	}
+	return 0
+}
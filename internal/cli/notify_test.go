@@ -2,11 +2,17 @@ package cli
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/marcus/sidecar/internal/config"
 	"github.com/marcus/sidecar/internal/notify"
 	"github.com/marcus/sidecar/internal/terminallink"
 	"github.com/marcus/sidecar/internal/uirequest"
@@ -294,3 +300,179 @@ func TestNotifyPostRefusesAMalformedTarget(t *testing.T) {
 		t.Fatalf("a refused post must store nothing, got %d records", len(all))
 	}
 }
+
+func TestNotifySourceAddPostListRemove(t *testing.T) {
+	env, out, errOut := notifyEnv(t)
+	config.SetTestConfigPath(filepath.Join(t.TempDir(), "config.json"))
+	t.Cleanup(func() {
+		config.ResetTestConfigPath()
+		notify.ApplyConfig(config.NotificationsConfig{})
+	})
+
+	if code := runNotifySourceAdd(env, []string{"agent"}); code != 2 {
+		t.Fatalf("adding a built-in = %d", code)
+	}
+	if code := runNotifySourceAdd(env, []string{"deploy", "--hue", "plaid"}); code != 2 {
+		t.Fatalf("adding a bad hue = %d", code)
+	}
+	if code := runNotifySourceAdd(env, []string{"deploy", "--label", "Deploys", "--glyph", "▲", "--hue", "warning", "--no-toast"}); code != 0 {
+		t.Fatalf("add = %d, stderr %q", code, errOut.String())
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry := cfg.Notifications.Sources["deploy"]; entry.Label != "DEPLOYS" || entry.Hue != "warning" || entry.Toast == nil || *entry.Toast {
+		t.Fatalf("stored entry = %+v", entry)
+	}
+
+	// A fresh process knows the source only from config.
+	notify.ApplyConfig(config.NotificationsConfig{})
+	if code := runNotifyPost(env, []string{"--source", "deploy", "Shipped"}); code != 0 {
+		t.Fatalf("post = %d, stderr %q", code, errOut.String())
+	}
+	all, _ := notify.ReadAll(notify.Path(env.StateDir))
+	if len(all) != 1 || all[0].Source != "deploy" || notify.MayToast(all[0], time.Now()) {
+		t.Fatalf("posted = %+v", all)
+	}
+
+	out.Reset()
+	if code := runNotifySourceList(env, nil); code != 0 || !strings.Contains(out.String(), "▲ deploy     DEPLOYS") || !strings.Contains(out.String(), "no toast  (custom)") {
+		t.Fatalf("list = %d, %q", code, out.String())
+	}
+
+	if code := runNotifySourceRemove(env, []string{"deploy"}); code != 0 {
+		t.Fatalf("remove = %d, stderr %q", code, errOut.String())
+	}
+	if code := runNotifySourceRemove(env, []string{"deploy"}); code != 3 {
+		t.Fatalf("second remove = %d", code)
+	}
+	if cfg, _ := config.Load(); len(cfg.Notifications.Sources) != 0 {
+		t.Fatalf("sources after remove = %+v", cfg.Notifications.Sources)
+	}
+}
+
+// With no instance to take a post, the CLI delivers it to the configured sinks
+// itself, so a user with Sidecar closed still hears about it.
+func TestNotifyPostFallbackDeliversToSinks(t *testing.T) {
+	env, _, errOut := notifyEnv(t)
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("Title"))
+	}))
+	defer srv.Close()
+	config.SetTestConfigPath(filepath.Join(t.TempDir(), "config.json"))
+	t.Cleanup(func() {
+		config.ResetTestConfigPath()
+		notify.ApplyConfig(config.NotificationsConfig{})
+	})
+	if err := config.SaveNotifications(func(n *config.NotificationsConfig) {
+		n.Sinks = []config.NotificationSinkConfig{{Kind: "webhook", URL: srv.URL, Format: "ntfy"}}
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := runNotifyPost(env, []string{"--source", "ci", "Deploy failed"}); code != 0 {
+		t.Fatalf("post = %d, stderr %q", code, errOut.String())
+	}
+	if len(got) != 1 || got[0] != "Deploy failed" {
+		t.Fatalf("webhook saw %q", got)
+	}
+}
+
+func TestNotifyActTypesIntoTheOriginSession(t *testing.T) {
+	env, out, errOut := notifyEnv(t)
+
+	type sent struct {
+		session string
+		strokes []notify.Keystroke
+	}
+	var calls []sent
+	prev := answerAgent
+	answerAgent = func(_ context.Context, session string, strokes []notify.Keystroke) error {
+		calls = append(calls, sent{session, strokes})
+		return nil
+	}
+	t.Cleanup(func() { answerAgent = prev })
+
+	store, err := notify.Open(env.StateDir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	waiting, err := store.Post(notify.Notification{
+		ID:      "ntf-waiting",
+		Source:  notify.SourceWaiting,
+		Title:   "Allow edit?",
+		Origin:  notify.Origin{TmuxSession: "sidecar-ws-agent", WorkDir: "/tmp/agent"},
+		Actions: notify.WaitingActions(),
+	})
+	if err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	plain, err := store.Post(notify.Notification{ID: "ntf-plain", Source: notify.SourceAgent, Title: "done"})
+	if err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	_ = store.Close()
+
+	if code := runNotifyAct(env, []string{waiting.ID, "approve"}); code != 0 {
+		t.Fatalf("approve = %d, stderr %q", code, errOut.String())
+	}
+	if len(calls) != 1 || calls[0].session != "sidecar-ws-agent" || calls[0].strokes[0].Keys != "y" {
+		t.Fatalf("approve sent %+v, want y into sidecar-ws-agent", calls)
+	}
+	if !strings.Contains(out.String(), "Approved ntf-waiting (Allow edit?)") {
+		t.Fatalf("unexpected confirmation %q", out.String())
+	}
+
+	if code := runNotifyAct(env, []string{waiting.ID, "message", "--message", "use  the\nv2 client"}); code != 0 {
+		t.Fatalf("message = %d, stderr %q", code, errOut.String())
+	}
+	if got := calls[len(calls)-1].strokes[0]; got.Keys != "use the v2 client" || !got.Literal {
+		t.Fatalf("message typed %+v", got)
+	}
+
+	n := len(calls)
+	if code := runNotifyAct(env, []string{waiting.ID, "message"}); code != 2 {
+		t.Fatalf("message without text = %d, want 2", code)
+	}
+	if code := runNotifyAct(env, []string{waiting.ID, "shrug"}); code != 2 {
+		t.Fatalf("unknown action = %d, want 2", code)
+	}
+	if code := runNotifyAct(env, []string{"ntf-missing", "approve"}); code != 3 {
+		t.Fatalf("unknown id = %d, want 3", code)
+	}
+	if code := runNotifyAct(env, []string{plain.ID, "approve"}); code != 4 {
+		t.Fatalf("a notification without actions = %d, want 4", code)
+	}
+
+	store, err = notify.Open(env.StateDir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := store.Dismiss(waiting.ID); err != nil {
+		t.Fatalf("Dismiss: %v", err)
+	}
+	_ = store.Close()
+	errOut.Reset()
+	if code := runNotifyAct(env, []string{waiting.ID, "deny"}); code != 4 || !strings.Contains(errOut.String(), "dismissed") {
+		t.Fatalf("a dismissed notification = %d %q, want 4 saying why", code, errOut.String())
+	}
+	if len(calls) != n {
+		t.Fatalf("refused answers must type nothing, got %+v", calls[n:])
+	}
+}
+
+func TestNotifyPostStoresActions(t *testing.T) {
+	env, _, errOut := notifyEnv(t)
+	if code := runNotifyPost(env, []string{"Apply?", "--action", "approve", "--action=Deny"}); code != 0 {
+		t.Fatalf("post = %d, stderr %q", code, errOut.String())
+	}
+	all, _ := notify.ReadAll(notify.Path(env.StateDir))
+	if len(all) != 1 || len(all[0].Actions) != 2 || all[0].Actions[1].Kind != notify.ActionDeny {
+		t.Fatalf("actions not stored: %+v", all)
+	}
+	if code := runNotifyPost(env, []string{"Apply?", "--action", "rm -rf"}); code != 2 {
+		t.Fatalf("an unknown action = %d, want 2", code)
+	}
+}
@@ -196,7 +196,13 @@ func runOpen(env Env, args []string) int {
 		cliErrf(env.Stderr, "validation error: %v\n\n%s", err, openHelp)
 		return 2
 	}
+	return deliverOpen(env, dest, target, splitMode, waitDuration, jsonOutput)
+}
 
+// deliverOpen sends one open request to dest and reports the instance's answer
+// with open's exit codes. `sidecar bookmark open` shares it, so a bookmark
+// lands exactly as the same target passed to `sidecar open` would.
+func deliverOpen(env Env, dest openDestination, target uirequest.Target, splitMode string, waitDuration time.Duration, jsonOutput bool) int {
 	req := uirequest.Request{
 		Version:   1,
 		ID:        uirequest.NewRequestID(),
@@ -210,8 +216,7 @@ func runOpen(env Env, args []string) int {
 		},
 	}
 
-	_, err = uirequest.WriteRequest(env.StateDir, req)
-	if err != nil {
+	if _, err := uirequest.WriteRequest(env.StateDir, req); err != nil {
 		cliErrln(env.Stderr, err)
 		return 1
 	}
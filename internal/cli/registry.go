@@ -308,10 +308,227 @@ func RootCommand() *Command {
 		Launch: runSetupLaunch,
 	}
 
-	root.Sub = []*Command{agentsCmd, createCmd, helpCmd, notifyCommand(), openCmd, setupCmd, shellCmd, terminalLinksCommand()}
+	statusCmd := &Command{
+		Name:    "status",
+		Summary: "Summarize agents, notifications, dirty worktrees and today's spend",
+		Usage:   "sidecar status [--json | --format TEMPLATE]",
+		Long: "Print a one-look summary across every registered project: agents working,\n" +
+			"blocked and idle; unread notifications by severity; worktrees with uncommitted\n" +
+			"changes; and today's estimated Claude Code spend.\n\n" +
+			"It reads only what Sidecar and Claude Code have already written — it never runs\n" +
+			"git or asks tmux — so a tmux status line, starship or polybar can call it every\n" +
+			"second. Agent states come from the Agents board's last poll; a working or\n" +
+			"blocked reading more than two minutes old is reported as unknown. Dirty\n" +
+			"worktrees are as of the last workspace refresh in a running Sidecar. Spend\n" +
+			"prices today's tokens at each model's average rate and is \"-\" until Claude\n" +
+			"Code has computed the day.\n\n" +
+			"--format prints TEMPLATE with placeholders replaced: {working}, {blocked},\n" +
+			"{idle}, {unknown}, {agents} (all four), {unread}, {error}, {warning}, {info},\n" +
+			"{dirty} and {spend}. An unknown placeholder is a usage error.",
+		Flags: []Flag{
+			{Name: "--format", Arg: "TEMPLATE", Summary: "Print TEMPLATE with {placeholders} filled in"},
+			{Name: "--json", Summary: "Write one structured result object to stdout", Bool: true},
+			{Name: "--help", Short: "-h", Summary: "Show this help", Bool: true},
+		},
+		Args: ArgSpec{Min: 0, Max: 0},
+		ExitCodes: []ExitCode{
+			{Code: 0, Summary: "success"},
+			{Code: 1, Summary: "state failure"},
+			{Code: 2, Summary: "usage error"},
+		},
+		Examples: []Example{
+			{Command: "sidecar status"},
+			{Command: "sidecar status --format '{working}⚙ {blocked}! {unread}✉ {spend}'", Description: "tmux status-right"},
+			{Command: "sidecar status --json"},
+		},
+		Run: runStatus,
+	}
+
+	watchCmd := &Command{
+		Name:    "watch",
+		Summary: "Watch agents and post waiting notifications with no TUI open",
+		Usage:   "sidecar watch [--interval DURATION] [--once]",
+		Long: "Run headless: poll every configured project's shells and worktrees, classify\n" +
+			"what each agent is doing, keep the activity store `sidecar status` reads\n" +
+			"current, and post a notification when an agent settles into waiting for\n" +
+			"input — withdrawn again when it resumes. Notifications are delivered as\n" +
+			"`sidecar notify post` delivers them, and with no Sidecar running the\n" +
+			"configured desktop, terminal and webhook sinks fire from here.\n\n" +
+			"A project open in a running Sidecar is left to that instance, which already\n" +
+			"announces its own agents, so watch can run all day beside the TUI without\n" +
+			"doubling anything. Only one watcher runs per state directory. The first round\n" +
+			"only establishes each agent's state: an agent already waiting when watch\n" +
+			"starts is not announced. It stops on Ctrl-C or SIGTERM.\n\n" +
+			"--once runs a single round and exits, which refreshes the activity store for\n" +
+			"a cron job or status bar without a daemon.",
+		Flags: []Flag{
+			{Name: "--interval", Arg: "DURATION", Summary: "Time between polls (default 5s, at least 1s)"},
+			{Name: "--once", Summary: "Poll once and exit", Bool: true},
+			{Name: "--help", Short: "-h", Summary: "Show this help", Bool: true},
+		},
+		Args: ArgSpec{Min: 0, Max: 0},
+		ExitCodes: []ExitCode{
+			{Code: 0, Summary: "stopped, or --once finished"},
+			{Code: 1, Summary: "state failure"},
+			{Code: 2, Summary: "usage error"},
+			{Code: 3, Summary: "another sidecar watch is already running"},
+		},
+		Examples: []Example{
+			{Command: "sidecar watch"},
+			{Command: "sidecar watch --interval 15s", Description: "poll less often on battery"},
+			{Command: "sidecar watch --once", Description: "refresh agent states once"},
+		},
+		Run: runWatch,
+	}
+
+	root.Sub = []*Command{agentsCmd, bookmarkCommand(), createCmd, helpCmd, notifyCommand(), openCmd, setupCmd, shellCmd, statusCmd, terminalLinksCommand(), watchCmd, workspaceCommand()}
 	return root
 }
 
+// bookmarkCommand saves locations worth coming back to. Bookmarks are filed
+// per project in Sidecar's state directory, so every command here works with
+// no Sidecar running except `open`, which asks an instance to show one.
+func bookmarkCommand() *Command {
+	addCmd := &Command{
+		Name:    "add",
+		Summary: "Bookmark a file, line, diff, issue, or resource",
+		Usage:   "sidecar bookmark add [options] <target>",
+		Long: "Bookmark a target for the project containing this directory. The target is\n" +
+			"anything `sidecar open` accepts: a path (with an optional :line), a td-xxxxxx\n" +
+			"issue, a git spec with --diff, or a provider resource with --provider. --shell\n" +
+			"pins a Sidecar shell or worktree agent by name instead.\n\n" +
+			"Bookmarks are shared by every worktree of a project and show in Sidecar's\n" +
+			"bookmark picker ('), labelled with who left them. Adding a target the project\n" +
+			"already has updates its label and note instead of adding a duplicate, so an\n" +
+			"agent can leave \"places for the user to review\" without tracking what it\n" +
+			"already left.",
+		Flags: []Flag{
+			{Name: "--label", Arg: "TEXT", Summary: "Short name shown instead of the target"},
+			{Name: "--note", Arg: "TEXT", Summary: "Why this place matters, shown beside it"},
+			{Name: "--line", Arg: "N", Summary: "Line number for a file target"},
+			{Name: "--diff", Summary: "Treat the target as a git spec", Bool: true},
+			{Name: "--provider", Arg: "ID", Summary: "Bookmark a terminal resource from this provider instance"},
+			{Name: "--shell", Arg: "NAME", Summary: "Bookmark a Sidecar shell instead of a target"},
+			{Name: "--project", Arg: "NAME", Summary: "File the bookmark under this registered project"},
+			{Name: "--json", Summary: "Write the stored bookmark as JSON to stdout", Bool: true},
+			{Name: "--help", Short: "-h", Summary: "Show this help", Bool: true},
+		},
+		Args: ArgSpec{Min: 0, Max: 1, Description: "The target, as for sidecar open"},
+		ExitCodes: []ExitCode{
+			{Code: 0, Summary: "bookmarked"},
+			{Code: 1, Summary: "state failure"},
+			{Code: 2, Summary: "usage or validation error"},
+			{Code: 3, Summary: "--project or --shell matched more than one, or no such shell"},
+		},
+		Examples: []Example{
+			{Command: "sidecar bookmark add internal/app/model.go:42"},
+			{Command: "sidecar bookmark add internal/cli/open.go --line 120 --label \"ack polling\" --note \"check the timeout\""},
+			{Command: "sidecar bookmark add --diff HEAD~1 --label \"last refactor\""},
+			{Command: "sidecar bookmark add td-4c1f9a --json"},
+			{Command: "sidecar bookmark add --shell \"API server\""},
+		},
+		Agent: AgentDoc{
+			Invocation: "sidecar bookmark add <path[:line]|td-id> [--label TEXT] [--note TEXT]",
+			Summary:    "Leave the user a place to review; it shows in Sidecar's bookmark picker",
+		},
+		Run: runBookmarkAdd,
+	}
+
+	listCmd := &Command{
+		Name:    "list",
+		Summary: "List bookmarks",
+		Usage:   "sidecar bookmark list [--all] [--project NAME] [--json]",
+		Long: "List this project's bookmarks, oldest first and numbered; the number or the id\n" +
+			"can be passed to open and remove. --all lists every project's.",
+		Flags: []Flag{
+			{Name: "--all", Summary: "List bookmarks from every project", Bool: true},
+			{Name: "--project", Arg: "NAME", Summary: "List this registered project's bookmarks"},
+			{Name: "--json", Summary: "Write one structured result object to stdout", Bool: true},
+			{Name: "--help", Short: "-h", Summary: "Show this help", Bool: true},
+		},
+		Args: ArgSpec{Min: 0, Max: 0},
+		ExitCodes: []ExitCode{
+			{Code: 0, Summary: "success"},
+			{Code: 1, Summary: "the bookmark file could not be read"},
+			{Code: 2, Summary: "usage error"},
+		},
+		Examples: []Example{
+			{Command: "sidecar bookmark list"},
+			{Command: "sidecar bookmark list --all --json"},
+		},
+		Agent: AgentDoc{
+			Invocation: "sidecar bookmark list --json",
+			Summary:    "See the places already bookmarked in this project",
+		},
+		Run: runBookmarkList,
+	}
+
+	openCmd := &Command{
+		Name:    "open",
+		Summary: "Open a bookmark in the running Sidecar",
+		Usage:   "sidecar bookmark open [options] <id|number>",
+		Long: "Open a bookmark exactly as `sidecar open` would open its target: in this shell's\n" +
+			"Sidecar instance, or the one named by --shell or --project.",
+		Flags: []Flag{
+			{Name: "--shell", Arg: "NAME", Summary: "Open in the instance showing this shell"},
+			{Name: "--project", Arg: "NAME", Summary: "Look the bookmark up in, and open it in, this project"},
+			{Name: "--split", Arg: "MODE", Summary: "Split placement: auto, right, or below (default auto)"},
+			{Name: "--wait", Arg: "DURATION", Summary: "How long to wait for an acknowledgement (default 1.2s; 0 to not wait)"},
+			{Name: "--json", Summary: "Write one structured result object to stdout", Bool: true},
+			{Name: "--help", Short: "-h", Summary: "Show this help", Bool: true},
+		},
+		Args: ArgSpec{Min: 1, Max: 1, Description: "Bookmark id, unique id prefix, or list number"},
+		ExitCodes: []ExitCode{
+			{Code: 0, Summary: "opened or queued"},
+			{Code: 1, Summary: "state failure"},
+			{Code: 2, Summary: "usage error"},
+			{Code: 3, Summary: "no such bookmark, or no running instance to show it"},
+			{Code: 4, Summary: "the instance declined the request"},
+		},
+		Examples: []Example{
+			{Command: "sidecar bookmark open 2"},
+			{Command: "sidecar bookmark open bm-1f2e3d4c --split right"},
+		},
+		Run: runBookmarkOpen,
+	}
+
+	removeCmd := &Command{
+		Name:    "remove",
+		Summary: "Remove a bookmark",
+		Usage:   "sidecar bookmark remove [--project NAME] [--json] <id|number>",
+		Flags: []Flag{
+			{Name: "--project", Arg: "NAME", Summary: "Look the bookmark up in this registered project"},
+			{Name: "--json", Summary: "Write the removed bookmark as JSON to stdout", Bool: true},
+			{Name: "--help", Short: "-h", Summary: "Show this help", Bool: true},
+		},
+		Args: ArgSpec{Min: 1, Max: 1, Description: "Bookmark id, unique id prefix, or list number"},
+		ExitCodes: []ExitCode{
+			{Code: 0, Summary: "removed"},
+			{Code: 1, Summary: "state failure"},
+			{Code: 2, Summary: "usage error"},
+			{Code: 3, Summary: "no such bookmark"},
+		},
+		Examples: []Example{
+			{Command: "sidecar bookmark remove 1"},
+		},
+		Agent: AgentDoc{
+			Invocation: "sidecar bookmark remove <id>",
+			Summary:    "Take back a bookmark once the place no longer needs review",
+		},
+		Run: runBookmarkRemove,
+	}
+
+	return &Command{
+		Name:    "bookmark",
+		Summary: "Add, list, open, and remove project bookmarks",
+		Usage:   "sidecar bookmark <command>",
+		Long: "Bookmarks are saved locations — files and lines, diffs, issues, resources —\n" +
+			"filed per project. The same list appears in Sidecar's bookmark picker.",
+		Sub: []*Command{addCmd, listCmd, openCmd, removeCmd},
+		Run: runBookmarkRoot,
+	}
+}
+
 // notifyCommand is the agent-facing side of the notification system: post an
 // alert the user sees as a toast and in the notification centre, dismiss one
 // you posted, and read the log back.
@@ -327,7 +544,8 @@ func notifyCommand() *Command {
 		Long: "Post a notification. It appears as a toast in the running Sidecar instance for\n" +
 			"this shell's project and stays in the notification centre until dismissed.\n\n" +
 			"With no instance running the notification is written to Sidecar's notification\n" +
-			"log and appears at the next start; nothing is lost.\n\n" +
+			"log and appears at the next start; nothing is lost. The desktop and webhook\n" +
+			"sinks configured under notifications.sinks still deliver it at once.\n\n" +
 			"--expiry sets how long the toast stays on screen — a duration such as 10s, or\n" +
 			"\"never\" for one that waits for the user. Expiry never removes the notification\n" +
 			"from the centre.\n\n" +
@@ -341,11 +559,16 @@ func notifyCommand() *Command {
 			"text does not spell out. A session target attaches a Sidecar-owned tmux\n" +
 			"session — the sidecar-sh-… and sidecar-ws-… names shells and worktree agents\n" +
 			"run under, which are also the only ones found by scanning. A task target opens\n" +
-			"the Tasks tab.",
+			"the Tasks tab.\n\n" +
+			"--action offers a quick action the user can answer with from the toast, the\n" +
+			"centre or `sidecar notify act`: approve, deny, message or open-diff. The first\n" +
+			"three type into this shell's tmux session, so they are offered only when posting\n" +
+			"from inside one.",
 		Flags: []Flag{
 			{Name: "--body", Arg: "TEXT", Summary: "Detail line shown under the title"},
 			{Name: "--target", Arg: "SPEC", Summary: "Call to action, kind:value[:line][@project]; repeatable"},
-			{Name: "--source", Arg: "ID", Summary: "Source: agent, waiting, session, tasks, td, system (default agent)"},
+			{Name: "--action", Arg: "KIND", Summary: "Quick action: approve, deny, message or open-diff; repeatable"},
+			{Name: "--source", Arg: "ID", Summary: "Source: agent, waiting, session, tasks, td, system, or a registered one (default agent)"},
 			{Name: "--expiry", Arg: "DURATION", Summary: "Toast lifetime (e.g. 10s), or \"never\" (default: the source's)"},
 			{Name: "--json", Summary: "Write one structured result object to stdout", Bool: true},
 			{Name: "--help", Short: "-h", Summary: "Show this help", Bool: true},
@@ -362,6 +585,7 @@ func notifyCommand() *Command {
 			{Command: "sidecar notify post \"Build failed\" --body \"go test ./internal/app\" --json"},
 			{Command: "sidecar notify post \"Review needed\" --target issue:td-4c1f9a --target file:internal/app/model.go:42"},
 			{Command: "sidecar notify post \"Fixed upstream\" --target issue:td-99aabb@braid"},
+			{Command: "sidecar notify post \"Apply the migration?\" --source waiting --action approve --action deny"},
 		},
 		Agent: AgentDoc{
 			Invocation: "sidecar notify post \"<short title>\" [--body TEXT] [--source ID] [--target kind:value[:line][@project]]",
@@ -370,6 +594,38 @@ func notifyCommand() *Command {
 		Run: runNotifyPost,
 	}
 
+	actCmd := &Command{
+		Name:    "act",
+		Summary: "Answer a notification with one of its quick actions",
+		Usage:   "sidecar notify act [--message TEXT] [--json] <id> <action>",
+		Long: "Run a notification's quick action, exactly as its toast or the notification\n" +
+			"centre would. A waiting agent's notification offers approve and deny, which type\n" +
+			"y or n and Enter into the agent's tmux session; message, which types --message\n" +
+			"and Enter; and open-diff, which opens the agent's working-tree diff in the\n" +
+			"running Sidecar instance.\n\n" +
+			"A dismissed notification offers nothing: its agent has stopped waiting, and a\n" +
+			"keystroke meant for the prompt would land in whatever it is doing now. A caller\n" +
+			"cannot answer a notification raised from its own session.",
+		Flags: []Flag{
+			{Name: "--message", Arg: "TEXT", Summary: "The text a message action types"},
+			{Name: "--json", Summary: "Write one structured result object to stdout", Bool: true},
+			{Name: "--help", Short: "-h", Summary: "Show this help", Bool: true},
+		},
+		Args: ArgSpec{Min: 2, Max: 2, Description: "The notification id, then approve, deny, message or open-diff"},
+		ExitCodes: []ExitCode{
+			{Code: 0, Summary: "answered"},
+			{Code: 1, Summary: "the keys or the diff could not be delivered"},
+			{Code: 2, Summary: "usage error"},
+			{Code: 3, Summary: "no notification with that id"},
+			{Code: 4, Summary: "that notification does not offer the action, or was raised by this caller"},
+		},
+		Examples: []Example{
+			{Command: "sidecar notify act ntf-06215f4b1a2c3-9f1e2d3c approve"},
+			{Command: "sidecar notify act ntf-06215f4b1a2c3-9f1e2d3c message --message \"use the v2 client\""},
+		},
+		Run: runNotifyAct,
+	}
+
 	dismissCmd := &Command{
 		Name:    "dismiss",
 		Summary: "Dismiss a notification you posted",
@@ -432,15 +688,118 @@ func notifyCommand() *Command {
 
 	return &Command{
 		Name:    "notify",
-		Summary: "Post, dismiss, and list Sidecar notifications",
+		Summary: "Post, answer, dismiss, and list Sidecar notifications",
 		Usage:   "sidecar notify <command>",
 		Long: "Sidecar's notification surface: a toast in the running instance, an entry in the\n" +
 			"notification centre, and a count in the header until the user reads it.",
-		Sub: []*Command{dismissCmd, listCmd, postCmd},
+		Sub: []*Command{actCmd, dismissCmd, listCmd, postCmd, notifySourceCommand()},
 		Run: runNotifyRoot,
 	}
 }
 
+// notifySourceCommand registers notification sources beyond the built-in ones,
+// so a CI script, a test watcher or a deploy hook posts under its own name and
+// gets its own section in the notification centre. Sources are entries in the
+// notifications section of config.json; these commands edit that section.
+func notifySourceCommand() *Command {
+	addCmd := &Command{
+		Name:    "add",
+		Summary: "Register a notification source",
+		Usage:   "sidecar notify source add [options] <id>",
+		Long: "Register a source that `sidecar notify post --source <id>` can post under. Its\n" +
+			"notifications get their own section in the notification centre, headed by the\n" +
+			"label and marked with the glyph in the hue.\n\n" +
+			"The id is lower-case letters, digits, - and _. Adding an id that is already\n" +
+			"registered replaces it. --priority breaks ties between equally severe\n" +
+			"notifications: the built-in sources run from 10 (system) to 60 (waiting), and a\n" +
+			"new source defaults to 15. --no-toast files the source's notifications into the\n" +
+			"centre without putting them on screen.\n\n" +
+			"The source is written to the notifications section of config.json; a running\n" +
+			"Sidecar picks it up with its first notification.",
+		Flags: []Flag{
+			{Name: "--label", Arg: "TEXT", Summary: "Section heading in the notification centre (default: the id)"},
+			{Name: "--glyph", Arg: "CHAR", Summary: "One-cell mark for its notifications (default ●)"},
+			{Name: "--hue", Arg: "NAME", Summary: "primary, secondary, accent, success, warning, error, info or muted (default muted)"},
+			{Name: "--priority", Arg: "N", Summary: "Loudness among equally severe notifications, 1-100 (default 15)"},
+			{Name: "--expiry", Arg: "DURATION", Summary: "Toast lifetime (e.g. 30s), or \"never\" (default 10s)"},
+			{Name: "--no-toast", Summary: "File into the centre without toasting", Bool: true},
+			{Name: "--json", Summary: "Write the registered source as JSON to stdout", Bool: true},
+			{Name: "--help", Short: "-h", Summary: "Show this help", Bool: true},
+		},
+		Args: ArgSpec{Min: 1, Max: 1, Description: "The source id to post under"},
+		ExitCodes: []ExitCode{
+			{Code: 0, Summary: "registered"},
+			{Code: 1, Summary: "config could not be read or written"},
+			{Code: 2, Summary: "usage or validation error"},
+		},
+		Examples: []Example{
+			{Command: "sidecar notify source add deploy --label Deploys --glyph ▲ --hue warning --priority 35"},
+			{Command: "sidecar notify source add tests --hue info --no-toast"},
+		},
+		Agent: AgentDoc{
+			Invocation: "sidecar notify source add <id> [--label TEXT] [--glyph CHAR] [--hue NAME]",
+			Summary:    "Give a script or watcher its own section in the notification centre",
+		},
+		Run: runNotifySourceAdd,
+	}
+
+	listCmd := &Command{
+		Name:    "list",
+		Summary: "List notification sources",
+		Usage:   "sidecar notify source list [--json]",
+		Long: "List the built-in and registered sources, loudest first, with the toast expiry\n" +
+			"and policy in effect for each.",
+		Flags: []Flag{
+			{Name: "--json", Summary: "Write one structured result object to stdout", Bool: true},
+			{Name: "--help", Short: "-h", Summary: "Show this help", Bool: true},
+		},
+		Args: ArgSpec{Min: 0, Max: 0},
+		ExitCodes: []ExitCode{
+			{Code: 0, Summary: "success"},
+			{Code: 1, Summary: "config could not be read"},
+			{Code: 2, Summary: "usage error"},
+		},
+		Examples: []Example{
+			{Command: "sidecar notify source list"},
+			{Command: "sidecar notify source list --json"},
+		},
+		Run: runNotifySourceList,
+	}
+
+	removeCmd := &Command{
+		Name:    "remove",
+		Summary: "Remove a registered notification source",
+		Usage:   "sidecar notify source remove [--json] <id>",
+		Long: "Remove a registered source. Notifications it already posted stay in the centre,\n" +
+			"filed under system. Built-in sources cannot be removed.",
+		Flags: []Flag{
+			{Name: "--json", Summary: "Write one structured result object to stdout", Bool: true},
+			{Name: "--help", Short: "-h", Summary: "Show this help", Bool: true},
+		},
+		Args: ArgSpec{Min: 1, Max: 1, Description: "The source id"},
+		ExitCodes: []ExitCode{
+			{Code: 0, Summary: "removed"},
+			{Code: 1, Summary: "config could not be read or written"},
+			{Code: 2, Summary: "usage error, or a built-in source"},
+			{Code: 3, Summary: "no registered source with that id"},
+		},
+		Examples: []Example{
+			{Command: "sidecar notify source remove deploy"},
+		},
+		Run: runNotifySourceRemove,
+	}
+
+	return &Command{
+		Name:    "source",
+		Summary: "Register, list, and remove notification sources",
+		Usage:   "sidecar notify source <command>",
+		Long: "Notification sources beyond the built-in ones, each with its own section in the\n" +
+			"notification centre and its own toast policy.",
+		Sub: []*Command{addCmd, listCmd, removeCmd},
+		Run: runNotifySourceRoot,
+	}
+}
+
 // terminalLinksCommand is the protocol/admin surface for terminal resource
 // providers. It deliberately does not describe or resolve anything the user did
 // not ask for: `list` reads configuration, `check` adds one describe, and
@@ -606,3 +965,212 @@ func defaultEnv(stdout, stderr io.Writer) Env {
 		Ctx:      context.Background(),
 	}
 }
+
+// workspaceCommand manages a project's worktrees and shells from a script or
+// another agent: the same inventory the Workspaces browser reads, and the same
+// workspaceops the workspace plugin's actions run, so a refusal here is the
+// refusal the TUI would give. None of it needs Sidecar running.
+func workspaceCommand() *Command {
+	projectFlag := Flag{Name: "--project", Arg: "NAME", Summary: "Work in this registered project instead of the current one"}
+	jsonFlag := Flag{Name: "--json", Summary: "Write one structured result object to stdout", Bool: true}
+	helpFlag := Flag{Name: "--help", Short: "-h", Summary: "Show this help", Bool: true}
+	refArg := ArgSpec{Min: 1, Max: 1, Description: "Workspace id, tmux session, name, branch, or worktree path"}
+
+	listCmd := &Command{
+		Name:    "list",
+		Summary: "List worktrees and shells",
+		Usage:   "sidecar workspace list [--all] [--project NAME] [--json]",
+		Long: "List every worktree and shell of the project containing this directory, with\n" +
+			"its agent's state where it has one: working, blocked, done, idle or paused.\n" +
+			"Plain worktrees and shells are listed too. --all lists every registered\n" +
+			"project's.",
+		Flags: []Flag{
+			{Name: "--all", Summary: "List workspaces from every registered project", Bool: true},
+			projectFlag, jsonFlag, helpFlag,
+		},
+		Args: ArgSpec{Min: 0, Max: 0},
+		ExitCodes: []ExitCode{
+			{Code: 0, Summary: "success"},
+			{Code: 1, Summary: "state failure"},
+			{Code: 2, Summary: "usage error, or no registered project here"},
+			{Code: 3, Summary: "--project matched more than one project"},
+		},
+		Examples: []Example{
+			{Command: "sidecar workspace list"},
+			{Command: "sidecar workspace list --all --json"},
+		},
+		Agent: AgentDoc{
+			Invocation: "sidecar workspace list --json",
+			Summary:    "See sibling worktrees and shells and what their agents are doing",
+		},
+		Run: runWorkspaceList,
+	}
+
+	showCmd := &Command{
+		Name:    "show",
+		Summary: "Show one workspace",
+		Usage:   "sidecar workspace show [--project NAME] [--json] <workspace>",
+		Long: "Show a workspace as list does, and for a worktree its HEAD, any Git operation\n" +
+			"in progress, and its changed files in the control API's git shape.",
+		Flags: []Flag{projectFlag, jsonFlag, helpFlag},
+		Args:  refArg,
+		ExitCodes: []ExitCode{
+			{Code: 0, Summary: "success"},
+			{Code: 1, Summary: "state failure"},
+			{Code: 2, Summary: "usage error"},
+			{Code: 3, Summary: "no such workspace, or more than one matched"},
+		},
+		Examples: []Example{
+			{Command: "sidecar workspace show feature-auth --json"},
+		},
+		Run: runWorkspaceShow,
+	}
+
+	sendKeysCmd := &Command{
+		Name:    "send-keys",
+		Summary: "Type text into a workspace's session",
+		Usage:   "sidecar workspace send-keys [--enter] [--project NAME] [--json] <workspace> [text...]",
+		Long: "Type text into the workspace's tmux session exactly as written, then Enter with\n" +
+			"--enter. The words after the workspace are joined with spaces; put -- before\n" +
+			"text that starts with a dash.",
+		Flags: []Flag{
+			{Name: "--enter", Summary: "Press Enter after the text", Bool: true},
+			projectFlag, jsonFlag, helpFlag,
+		},
+		Args: ArgSpec{Min: 1, Max: -1, Description: "The workspace, then the text to type"},
+		ExitCodes: []ExitCode{
+			{Code: 0, Summary: "sent"},
+			{Code: 1, Summary: "the keys could not be delivered"},
+			{Code: 2, Summary: "usage error"},
+			{Code: 3, Summary: "no such workspace, or more than one matched"},
+			{Code: 4, Summary: "the workspace has no running session"},
+		},
+		Examples: []Example{
+			{Command: "sidecar workspace send-keys feature-auth --enter \"run the tests again\""},
+			{Command: "sidecar workspace send-keys \"Shell 2\" --enter -- --help"},
+		},
+		Agent: AgentDoc{
+			Invocation: "sidecar workspace send-keys <workspace> --enter \"<text>\"",
+			Summary:    "Hand a sibling worktree's agent an instruction",
+		},
+		Run: runWorkspaceSendKeys,
+	}
+
+	captureCmd := &Command{
+		Name:    "capture",
+		Summary: "Print what a workspace's terminal shows",
+		Usage:   "sidecar workspace capture [--lines N] [--ansi] [--project NAME] [--json] <workspace>",
+		Long: "Print the visible screen of the workspace's tmux pane, with N more lines of\n" +
+			"scrollback when --lines is given. Colours are stripped unless --ansi.",
+		Flags: []Flag{
+			{Name: "--lines", Arg: "N", Summary: "Scrollback lines to include above the screen (default 0)"},
+			{Name: "--ansi", Summary: "Keep terminal colour escapes", Bool: true},
+			projectFlag, jsonFlag, helpFlag,
+		},
+		Args: refArg,
+		ExitCodes: []ExitCode{
+			{Code: 0, Summary: "success"},
+			{Code: 1, Summary: "the pane could not be captured"},
+			{Code: 2, Summary: "usage error"},
+			{Code: 3, Summary: "no such workspace, or more than one matched"},
+			{Code: 4, Summary: "the workspace has no running session"},
+		},
+		Examples: []Example{
+			{Command: "sidecar workspace capture feature-auth --lines 200"},
+		},
+		Agent: AgentDoc{
+			Invocation: "sidecar workspace capture <workspace> [--lines N]",
+			Summary:    "Read what a sibling worktree's agent is showing",
+		},
+		Run: runWorkspaceCapture,
+	}
+
+	stopCmd := &Command{
+		Name:    "stop",
+		Summary: "Stop a workspace's agent",
+		Usage:   "sidecar workspace stop [--project NAME] [--json] <workspace>",
+		Long: "Stop the agent in a workspace. A worktree's agent gets Ctrl-C and its session\n" +
+			"is closed two seconds later if it is still there; a shell only gets Ctrl-C,\n" +
+			"so the shell itself stays open.",
+		Flags: []Flag{projectFlag, jsonFlag, helpFlag},
+		Args:  refArg,
+		ExitCodes: []ExitCode{
+			{Code: 0, Summary: "stopped"},
+			{Code: 1, Summary: "the session could not be stopped"},
+			{Code: 2, Summary: "usage error"},
+			{Code: 3, Summary: "no such workspace, or more than one matched"},
+			{Code: 4, Summary: "the workspace has no running session"},
+		},
+		Examples: []Example{
+			{Command: "sidecar workspace stop feature-auth"},
+		},
+		Run: runWorkspaceStop,
+	}
+
+	mergeCmd := &Command{
+		Name:    "merge",
+		Summary: "Merge a worktree's branch and push it",
+		Usage:   "sidecar workspace merge [--into BRANCH] [--project NAME] [--json] <worktree>",
+		Long: "Merge the worktree's branch into --into (the default branch when omitted) in\n" +
+			"the worktree that has it checked out, then push it — the workspace plugin's\n" +
+			"direct merge. Both checkouts must be clean with no Git operation in progress.\n\n" +
+			"A conflict is aborted, leaving the target as it was: resolve it by merging\n" +
+			"from Sidecar. The worktree itself is kept; delete it separately.",
+		Flags: []Flag{
+			{Name: "--into", Arg: "BRANCH", Summary: "Branch to merge into (default: the default branch)"},
+			projectFlag, jsonFlag, helpFlag,
+		},
+		Args: refArg,
+		ExitCodes: []ExitCode{
+			{Code: 0, Summary: "merged and pushed"},
+			{Code: 1, Summary: "the merge or the push failed"},
+			{Code: 2, Summary: "usage error"},
+			{Code: 3, Summary: "no such workspace, or more than one matched"},
+			{Code: 4, Summary: "refused: not a mergeable worktree, a dirty checkout, or a conflict"},
+		},
+		Examples: []Example{
+			{Command: "sidecar workspace merge feature-auth"},
+			{Command: "sidecar workspace merge feature-auth --into release --json"},
+		},
+		Run: runWorkspaceMerge,
+	}
+
+	deleteCmd := &Command{
+		Name:    "delete",
+		Summary: "Delete a worktree or shell",
+		Usage:   "sidecar workspace delete [--force] [--delete-branch] [--project NAME] [--json] <workspace>",
+		Long: "Delete a worktree, closing its sessions and the shells rooted in it first, or\n" +
+			"delete a shell and close its session. A worktree with uncommitted or untracked\n" +
+			"changes is refused unless --force. --delete-branch also deletes the worktree's\n" +
+			"local branch. The main worktree is never deleted.",
+		Flags: []Flag{
+			{Name: "--force", Summary: "Delete a worktree with uncommitted changes, and an unmerged branch", Bool: true},
+			{Name: "--delete-branch", Summary: "Also delete the worktree's local branch", Bool: true},
+			projectFlag, jsonFlag, helpFlag,
+		},
+		Args: refArg,
+		ExitCodes: []ExitCode{
+			{Code: 0, Summary: "deleted"},
+			{Code: 1, Summary: "the deletion failed"},
+			{Code: 2, Summary: "usage error"},
+			{Code: 3, Summary: "no such workspace, or more than one matched"},
+			{Code: 4, Summary: "refused: the main worktree, or uncommitted changes without --force"},
+		},
+		Examples: []Example{
+			{Command: "sidecar workspace delete feature-auth --delete-branch"},
+			{Command: "sidecar workspace delete \"Shell 3\""},
+		},
+		Run: runWorkspaceDelete,
+	}
+
+	return &Command{
+		Name:    "workspace",
+		Summary: "List, drive, merge and delete worktrees and shells",
+		Usage:   "sidecar workspace <command>",
+		Long: "Manage a project's worktrees and shells without the TUI. A workspace is named\n" +
+			"by its id from list, its tmux session, its name, its branch or its path;\n" +
+			"commands work in the project containing this directory unless --project.",
+		Sub: []*Command{listCmd, showCmd, sendKeysCmd, captureCmd, stopCmd, mergeCmd, deleteCmd},
+		Run: runWorkspaceRoot,
+	}
+}
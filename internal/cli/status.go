@@ -0,0 +1,321 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marcus/sidecar/internal/activitystore"
+	"github.com/marcus/sidecar/internal/adapter/claudecode"
+	"github.com/marcus/sidecar/internal/agentactivity"
+	"github.com/marcus/sidecar/internal/notify"
+	"github.com/marcus/sidecar/internal/workspaceinventory"
+	"github.com/marcus/sidecar/internal/worktreestatus"
+)
+
+// loadStatsCache is overridable so tests never read the developer's real
+// Claude Code usage.
+var loadStatsCache = claudecode.LoadStatsCache
+
+// statusSummary is everything `sidecar status` says, in its --json shape.
+type statusSummary struct {
+	Agents        statusAgents        `json:"agents"`
+	Notifications statusNotifications `json:"notifications"`
+	Worktrees     statusWorktrees     `json:"worktrees"`
+	Spend         statusSpend         `json:"spend"`
+}
+
+type statusAgents struct {
+	Working int `json:"working"`
+	Blocked int `json:"blocked"`
+	Idle    int `json:"idle"`
+	// Unknown is an agent with no believable reading: the board has not
+	// looked at it recently enough to say.
+	Unknown int `json:"unknown"`
+}
+
+type statusNotifications struct {
+	Unread  int `json:"unread"`
+	Error   int `json:"error"`
+	Warning int `json:"warning"`
+	Info    int `json:"info"`
+}
+
+type statusWorktrees struct {
+	Dirty int                       `json:"dirty"`
+	Items []worktreestatus.Worktree `json:"items"`
+}
+
+type statusSpend struct {
+	Date string `json:"date"`
+	// USD is absent until Claude Code has computed the day's usage.
+	USD *float64 `json:"usd,omitempty"`
+}
+
+// statusFields are the --format placeholders, in the order help lists them.
+var statusFields = []string{"working", "blocked", "idle", "unknown", "agents", "unread", "error", "warning", "info", "dirty", "spend"}
+
+var statusPlaceholder = regexp.MustCompile(`\{([a-z]+)\}`)
+
+func runStatus(env Env, args []string) int {
+	help := RenderHelp(RootCommand().FindSubcommand("status"))
+
+	jsonOutput := false
+	format, hasFormat := "", false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case isHelp(arg):
+			_, _ = fmt.Fprint(env.Stdout, help)
+			return 0
+		case arg == "--json":
+			jsonOutput = true
+		case arg == "--format" || strings.HasPrefix(arg, "--format="):
+			value, next, ok := takeFlagArg(arg, args, i, "--format")
+			if !ok {
+				cliErrf(env.Stderr, "--format requires a template\n\n%s", help)
+				return 2
+			}
+			format, hasFormat, i = value, true, next
+		default:
+			cliErrf(env.Stderr, "unknown option %q\n\n%s", arg, help)
+			return 2
+		}
+	}
+	if jsonOutput && hasFormat {
+		cliErrf(env.Stderr, "--json and --format are mutually exclusive\n\n%s", help)
+		return 2
+	}
+	if hasFormat {
+		// Checked before any state is read so a typo fails the same way every
+		// second rather than rendering a literal brace into a status bar.
+		for _, match := range statusPlaceholder.FindAllStringSubmatch(format, -1) {
+			if !knownStatusField(match[1]) {
+				cliErrf(env.Stderr, "unknown placeholder {%s}; use one of {%s}\n", match[1], strings.Join(statusFields, "}, {"))
+				return 2
+			}
+		}
+	}
+
+	report, err := readStatus(env.StateDir, time.Now())
+	if err != nil {
+		cliErrln(env.Stderr, err)
+		return 1
+	}
+
+	switch {
+	case jsonOutput:
+		if err := json.NewEncoder(env.Stdout).Encode(report); err != nil {
+			cliErrln(env.Stderr, err)
+			return 1
+		}
+	case hasFormat:
+		_, _ = fmt.Fprintln(env.Stdout, report.format(format))
+	default:
+		_, _ = fmt.Fprint(env.Stdout, report.human())
+	}
+	return 0
+}
+
+func knownStatusField(name string) bool {
+	for _, field := range statusFields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// readStatus gathers the report from persisted state only: the registered
+// projects' manifests, the activity store the board writes, the notification
+// log, the worktree status the workspace plugin writes, and Claude Code's own
+// usage cache. Nothing here runs git or asks tmux, so a status line can call
+// it every second.
+func readStatus(stateDir string, now time.Time) (statusSummary, error) {
+	report := statusSummary{Worktrees: statusWorktrees{Items: []worktreestatus.Worktree{}}}
+
+	projects, err := loadRegisteredProjects(stateDir)
+	if err != nil {
+		return report, err
+	}
+	activity := activitystore.Read(filepath.Join(stateDir, activitystore.FileName), now)
+	for _, p := range projects {
+		if p.Path == "" {
+			continue
+		}
+		for _, key := range statusAgentKeys(p, activity) {
+			report.Agents.count(activity[key], now)
+		}
+		status, err := worktreestatus.Read(worktreestatus.Path(p.Dir))
+		if err != nil {
+			continue
+		}
+		for _, wt := range status.Worktrees {
+			if wt.Dirty() {
+				report.Worktrees.Items = append(report.Worktrees.Items, wt)
+			}
+		}
+	}
+	sort.Slice(report.Worktrees.Items, func(i, j int) bool {
+		return report.Worktrees.Items[i].Path < report.Worktrees.Items[j].Path
+	})
+	report.Worktrees.Dirty = len(report.Worktrees.Items)
+
+	all, err := notify.ReadAll(notify.Path(stateDir))
+	if err != nil {
+		return report, err
+	}
+	for _, n := range notify.Unread(all) {
+		report.Notifications.Unread++
+		switch n.Severity {
+		case notify.SeverityError:
+			report.Notifications.Error++
+		case notify.SeverityWarning:
+			report.Notifications.Warning++
+		default:
+			report.Notifications.Info++
+		}
+	}
+
+	report.Spend.Date = now.Format("2006-01-02")
+	if stats, err := loadStatsCache(); err == nil {
+		if usd, ok := stats.DayCost(report.Spend.Date); ok {
+			report.Spend.USD = &usd
+		}
+	}
+	return report, nil
+}
+
+// statusAgentKeys names a project's agents by the workspace ids the board
+// files activity under. A worktree is an agent when Sidecar recorded one for
+// it; a shell when it was started with one, or when the board has since seen
+// one running in it.
+func statusAgentKeys(p registeredProject, activity map[string]activitystore.Entry) []string {
+	root := workspaceinventory.CanonicalPath(p.Path)
+	var keys []string
+	for _, shell := range p.Shells {
+		key := root + ":shell:" + shell.TmuxName
+		if _, seen := activity[key]; shell.AgentType != "" || seen {
+			keys = append(keys, key)
+		}
+	}
+	for _, path := range registeredAgentWorktrees(p.Dir) {
+		keys = append(keys, root+":worktree:"+workspaceinventory.CanonicalPath(path))
+	}
+	return keys
+}
+
+// registeredAgentWorktrees is the worktrees Sidecar recorded an agent for.
+func registeredAgentWorktrees(projectDir string) []string {
+	entries, err := os.ReadDir(filepath.Join(projectDir, "worktrees"))
+	if err != nil {
+		return nil
+	}
+	var paths []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(projectDir, "worktrees", entry.Name())
+		agent, err := os.ReadFile(filepath.Join(dir, "agent"))
+		if err != nil || strings.TrimSpace(string(agent)) == "" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "meta.json"))
+		if err != nil {
+			continue
+		}
+		var meta struct {
+			Path string `json:"path"`
+		}
+		if err := json.Unmarshal(data, &meta); err == nil && meta.Path != "" {
+			paths = append(paths, meta.Path)
+		}
+	}
+	return paths
+}
+
+func (a *statusAgents) count(entry activitystore.Entry, now time.Time) {
+	switch entry.Current(now) {
+	case agentactivity.StateWorking:
+		a.Working++
+	case agentactivity.StateBlocked:
+		a.Blocked++
+	case agentactivity.StateIdle:
+		a.Idle++
+	default:
+		a.Unknown++
+	}
+}
+
+func (a statusAgents) total() int { return a.Working + a.Blocked + a.Idle + a.Unknown }
+
+func (s statusSpend) String() string {
+	if s.USD == nil {
+		return "-"
+	}
+	return fmt.Sprintf("$%.2f", *s.USD)
+}
+
+// format fills a --format template. Placeholders were validated before the
+// state was read.
+func (r statusSummary) format(template string) string {
+	values := map[string]string{
+		"working": strconv.Itoa(r.Agents.Working),
+		"blocked": strconv.Itoa(r.Agents.Blocked),
+		"idle":    strconv.Itoa(r.Agents.Idle),
+		"unknown": strconv.Itoa(r.Agents.Unknown),
+		"agents":  strconv.Itoa(r.Agents.total()),
+		"unread":  strconv.Itoa(r.Notifications.Unread),
+		"error":   strconv.Itoa(r.Notifications.Error),
+		"warning": strconv.Itoa(r.Notifications.Warning),
+		"info":    strconv.Itoa(r.Notifications.Info),
+		"dirty":   strconv.Itoa(r.Worktrees.Dirty),
+		"spend":   r.Spend.String(),
+	}
+	return statusPlaceholder.ReplaceAllStringFunc(template, func(match string) string {
+		return values[match[1:len(match)-1]]
+	})
+}
+
+func (r statusSummary) human() string {
+	var b strings.Builder
+	agents := fmt.Sprintf("%d working, %d blocked, %d idle", r.Agents.Working, r.Agents.Blocked, r.Agents.Idle)
+	if r.Agents.Unknown > 0 {
+		agents += fmt.Sprintf(", %d unknown", r.Agents.Unknown)
+	}
+	fmt.Fprintf(&b, "Agents         %s\n", agents)
+
+	notes := fmt.Sprintf("%d unread", r.Notifications.Unread)
+	var bySeverity []string
+	for _, part := range []struct {
+		n    int
+		name string
+	}{{r.Notifications.Error, "error"}, {r.Notifications.Warning, "warning"}, {r.Notifications.Info, "info"}} {
+		if part.n > 0 {
+			bySeverity = append(bySeverity, fmt.Sprintf("%d %s", part.n, part.name))
+		}
+	}
+	if len(bySeverity) > 0 {
+		notes += " (" + strings.Join(bySeverity, ", ") + ")"
+	}
+	fmt.Fprintf(&b, "Notifications  %s\n", notes)
+
+	fmt.Fprintf(&b, "Worktrees      %d dirty\n", r.Worktrees.Dirty)
+	for _, wt := range r.Worktrees.Items {
+		fmt.Fprintf(&b, "               %s\n", wt.Path)
+	}
+
+	spend := "not yet computed by Claude Code"
+	if r.Spend.USD != nil {
+		spend = "~" + r.Spend.String()
+	}
+	fmt.Fprintf(&b, "Spend today    %s\n", spend)
+	return b.String()
+}
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcus/sidecar/internal/activitystore"
+	"github.com/marcus/sidecar/internal/adapter/claudecode"
+	"github.com/marcus/sidecar/internal/agentactivity"
+	"github.com/marcus/sidecar/internal/notify"
+	"github.com/marcus/sidecar/internal/workspaceinventory"
+	"github.com/marcus/sidecar/internal/worktreestatus"
+)
+
+// statusFixture registers one project with two agent shells, a plain shell
+// and an agent worktree, and writes the state `sidecar status` reads.
+func statusFixture(t *testing.T) (Env, *bytes.Buffer) {
+	t.Helper()
+	env, out, _ := notifyEnv(t)
+	now := time.Now()
+	root := t.TempDir()
+	wtPath := t.TempDir()
+	projectDir := filepath.Join(env.StateDir, "projects", "app")
+	wtDir := filepath.Join(projectDir, "worktrees", "auth")
+	if err := os.MkdirAll(wtDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeJSON := func(path string, v any) {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeJSON(filepath.Join(projectDir, "meta.json"), map[string]string{"path": root})
+	writeJSON(filepath.Join(projectDir, "shells.json"), map[string]any{"version": 1, "shells": []map[string]string{
+		{"tmuxName": "sidecar-sh-app-1", "displayName": "Shell 1", "agentType": "claude"},
+		{"tmuxName": "sidecar-sh-app-2", "displayName": "Shell 2", "agentType": "codex"},
+		{"tmuxName": "sidecar-sh-app-3", "displayName": "Shell 3"},
+	}})
+	writeJSON(filepath.Join(wtDir, "meta.json"), map[string]string{"path": wtPath})
+	if err := os.WriteFile(filepath.Join(wtDir, "agent"), []byte("claude\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	key := workspaceinventory.CanonicalPath(root)
+	trackers := map[string]agentactivity.Tracker{
+		key + ":shell:sidecar-sh-app-1":                               {State: agentactivity.StateWorking, ChangedAt: now},
+		key + ":worktree:" + workspaceinventory.CanonicalPath(wtPath): {State: agentactivity.StateBlocked, ChangedAt: now},
+		// An entry for a shell that is no longer registered is history, not
+		// an agent.
+		key + ":shell:sidecar-sh-gone": {State: agentactivity.StateWorking, ChangedAt: now},
+	}
+	if err := activitystore.Save(filepath.Join(env.StateDir, activitystore.FileName), trackers, now); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := worktreestatus.Save(worktreestatus.Path(projectDir), []worktreestatus.Worktree{
+		{Path: root, Branch: "main"},
+		{Path: wtPath, Branch: "auth", Unstaged: 3},
+	}, now); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := notify.Open(env.StateDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []notify.Notification{
+		{Source: notify.SourceAgent, Title: "build failed", Severity: notify.SeverityError},
+		{Source: notify.SourceAgent, Title: "done"},
+		{Source: notify.SourceAgent, Title: "done too"},
+	} {
+		if _, err := store.Post(n); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	today := now.Format("2006-01-02")
+	loadStatsCache = func() (*claudecode.StatsCache, error) {
+		usage := claudecode.ModelUsage{InputTokens: 1_000_000}
+		return &claudecode.StatsCache{
+			DailyModelTokens: []claudecode.DailyModelTokens{{Date: today, TokensByModel: map[string]int{"claude-sonnet-4-5": 1_000_000}}},
+			ModelUsage:       map[string]claudecode.ModelUsage{"claude-sonnet-4-5": usage},
+		}, nil
+	}
+	t.Cleanup(func() { loadStatsCache = claudecode.LoadStatsCache })
+	return env, out
+}
+
+func TestStatusCountsPersistedState(t *testing.T) {
+	env, out := statusFixture(t)
+
+	if code := runStatus(env, []string{"--json"}); code != 0 {
+		t.Fatalf("status = %d", code)
+	}
+	var report statusSummary
+	if err := json.Unmarshal([]byte(out.String()), &report); err != nil {
+		t.Fatalf("decode %q: %v", out.String(), err)
+	}
+	// Shell 2 has an agent the board has never reported on; the plain shell
+	// is not an agent at all.
+	if want := (statusAgents{Working: 1, Blocked: 1, Unknown: 1}); report.Agents != want {
+		t.Fatalf("agents = %+v, want %+v", report.Agents, want)
+	}
+	if want := (statusNotifications{Unread: 3, Error: 1, Info: 2}); report.Notifications != want {
+		t.Fatalf("notifications = %+v, want %+v", report.Notifications, want)
+	}
+	if report.Worktrees.Dirty != 1 || report.Worktrees.Items[0].Branch != "auth" {
+		t.Fatalf("worktrees = %+v", report.Worktrees)
+	}
+	if report.Spend.USD == nil || *report.Spend.USD <= 0 {
+		t.Fatalf("spend = %+v", report.Spend)
+	}
+}
+
+func TestStatusFormatTemplate(t *testing.T) {
+	env, out := statusFixture(t)
+
+	if code := runStatus(env, []string{"--format", "{working}w {blocked}b {agents} {unread}/{error} {dirty}d {spend}"}); code != 0 {
+		t.Fatalf("status = %d", code)
+	}
+	want := "1w 1b 3 3/1 1d $3.00\n"
+	if out.String() != want {
+		t.Fatalf("format = %q, want %q", out.String(), want)
+	}
+}
+
+func TestStatusRejectsUnknownPlaceholders(t *testing.T) {
+	env, _, errOut := notifyEnv(t)
+	if code := runStatus(env, []string{"--format={workin}"}); code != 2 {
+		t.Fatalf("unknown placeholder = %d, want 2", code)
+	}
+	if !strings.Contains(errOut.String(), "{workin}") {
+		t.Fatalf("stderr should name the placeholder: %q", errOut.String())
+	}
+	if code := runStatus(env, []string{"--json", "--format", "{idle}"}); code != 2 {
+		t.Fatalf("--json with --format = %d, want 2", code)
+	}
+}
+
+func TestStatusWithNothingRegistered(t *testing.T) {
+	env, out, _ := notifyEnv(t)
+	loadStatsCache = func() (*claudecode.StatsCache, error) { return nil, errors.New("no cache") }
+	t.Cleanup(func() { loadStatsCache = claudecode.LoadStatsCache })
+
+	if code := runStatus(env, []string{"--format", "{agents} {spend}"}); code != 0 {
+		t.Fatalf("status = %d", code)
+	}
+	if out.String() != "0 -\n" {
+		t.Fatalf("format = %q", out.String())
+	}
+}
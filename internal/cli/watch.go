@@ -0,0 +1,253 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/marcus/sidecar/internal/activitystore"
+	"github.com/marcus/sidecar/internal/agentwatch"
+	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/notify"
+	"github.com/marcus/sidecar/internal/notifysink"
+	"github.com/marcus/sidecar/internal/uirequest"
+	"github.com/marcus/sidecar/internal/workspaceinventory"
+)
+
+// watchLockFile keeps a second `sidecar watch` from announcing every
+// transition again.
+const watchLockFile = "watch.lock"
+
+// defaultWatchInterval matches the Agents board's live poll.
+const defaultWatchInterval = 5 * time.Second
+
+// errWatchRunning is returned by lockWatch when another watcher holds the lock.
+var errWatchRunning = errors.New("another sidecar watch is already running")
+
+// newCollector builds the inventory collector watch and the workspace commands
+// read through. It is overridable so tests never list the developer's tmux
+// panes.
+var newCollector = func() workspaceinventory.Collector { return workspaceinventory.Collector{} }
+
+func runWatch(env Env, args []string) int {
+	help := RenderHelp(RootCommand().FindSubcommand("watch"))
+
+	interval := defaultWatchInterval
+	once := false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case isHelp(arg):
+			_, _ = fmt.Fprint(env.Stdout, help)
+			return 0
+		case arg == "--once":
+			once = true
+		case arg == "--interval" || strings.HasPrefix(arg, "--interval="):
+			value, next, ok := takeFlagArg(arg, args, i, "--interval")
+			if !ok {
+				cliErrf(env.Stderr, "--interval requires a duration\n\n%s", help)
+				return 2
+			}
+			d, err := time.ParseDuration(value)
+			if err != nil || d < time.Second {
+				cliErrf(env.Stderr, "invalid interval %q (a duration of at least 1s, such as 5s)\n\n%s", value, help)
+				return 2
+			}
+			interval, i = d, next
+		default:
+			cliErrf(env.Stderr, "unknown option %q\n\n%s", arg, help)
+			return 2
+		}
+	}
+
+	unlock, err := lockWatch(env.StateDir)
+	if errors.Is(err, errWatchRunning) {
+		cliErrln(env.Stderr, err)
+		return 3
+	}
+	if err != nil {
+		cliErrln(env.Stderr, err)
+		return 1
+	}
+	defer unlock()
+
+	cfg, err := config.Load()
+	if err != nil {
+		cliErrln(env.Stderr, err)
+		return 1
+	}
+	notify.ApplyConfig(cfg.Notifications)
+	store, err := notify.Open(env.StateDir)
+	if err != nil {
+		cliErrln(env.Stderr, err)
+		return 1
+	}
+	defer func() { _ = store.Close() }()
+	// One dispatcher for the daemon's lifetime, so a sink's rate limit holds
+	// across rounds rather than resetting with every post.
+	sinks, sinkErrs := notifysink.New(cfg.Notifications)
+	for _, err := range sinkErrs {
+		cliErrf(env.Stderr, "warning: %s\n", err)
+	}
+
+	ctx := env.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	w := &watchSession{env: env, store: store, sinks: sinks}
+	watcher := agentwatch.New(newCollector(), filepath.Join(env.StateDir, activitystore.FileName), 0)
+	if once {
+		round, err := watcher.Round(ctx, watchProjects(), time.Now())
+		if err != nil {
+			cliErrln(env.Stderr, err)
+			return 1
+		}
+		w.handle(round, nil)
+		_, _ = fmt.Fprintf(env.Stdout, "Observed %d agents.\n", len(round.Agents))
+		return 0
+	}
+
+	_, _ = fmt.Fprintf(env.Stdout, "Watching agents every %s. Press Ctrl-C to stop.\n", interval)
+	watcher.Run(ctx, interval, watchProjects, w.handle)
+	return 0
+}
+
+// watchProjects is the configured project list, re-read every round so a
+// project added in a running Sidecar is watched without a restart.
+func watchProjects() []agentwatch.Project {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil
+	}
+	projects := make([]agentwatch.Project, 0, len(cfg.Projects.List))
+	for _, p := range cfg.Projects.List {
+		projects = append(projects, agentwatch.Project{Name: p.Name, Path: p.Path})
+	}
+	return projects
+}
+
+// lockWatch takes the single-watcher lock in stateDir and returns its
+// release. The lock is never waited for: a second watcher is a mistake to
+// report, not a turn to queue for.
+func lockWatch(stateDir string) (func(), error) {
+	if err := os.MkdirAll(stateDir, 0o755); err != nil {
+		return nil, err
+	}
+	lock, err := os.OpenFile(filepath.Join(stateDir, watchLockFile), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		_ = lock.Close()
+		if err == syscall.EWOULDBLOCK || err == syscall.EAGAIN {
+			return nil, errWatchRunning
+		}
+		return nil, err
+	}
+	return func() {
+		_ = syscall.Flock(int(lock.Fd()), syscall.LOCK_UN)
+		_ = lock.Close()
+	}, nil
+}
+
+// watchSession delivers what each round found.
+type watchSession struct {
+	env   Env
+	store *notify.JSONLStore
+	sinks *notifysink.Dispatcher
+}
+
+func (w *watchSession) handle(round agentwatch.Round, err error) {
+	if err != nil {
+		cliErrf(w.env.Stderr, "warning: %s\n", err)
+		return
+	}
+	for root, err := range round.Errors {
+		cliErrf(w.env.Stderr, "warning: %s: %s\n", root, err)
+	}
+	if len(round.Post) == 0 && len(round.Dismiss) == 0 {
+		return
+	}
+
+	shown := w.shownProjects()
+	for _, post := range round.Post {
+		if shown[workspaceinventory.CanonicalPath(post.ProjectRoot)] {
+			// The instance showing this project runs the same lane tracker
+			// and has already announced it.
+			continue
+		}
+		w.post(post.Notification)
+	}
+	for _, id := range round.Dismiss {
+		// A withdrawal for a post that was left to a running instance finds
+		// nothing here, which is the point.
+		if err := w.store.Dismiss(id); err != nil && !errors.Is(err, notify.ErrNotFound) {
+			cliErrf(w.env.Stderr, "warning: %s\n", err)
+		}
+	}
+}
+
+// post hands n to a running instance when one takes it, exactly as
+// `sidecar notify post` does, and otherwise files it and runs the sinks here.
+func (w *watchSession) post(n notify.Notification) {
+	if payload, err := json.Marshal(n); err == nil {
+		delivered, _ := notifyDeliver(w.env, uirequest.Request{
+			Origin:  originForRequest(n.Origin),
+			Action:  uirequest.ActionNotify,
+			Target:  uirequest.Target{Kind: uirequest.TargetKindNotification},
+			Payload: payload,
+		})
+		if delivered {
+			_, _ = fmt.Fprintf(w.env.Stdout, "%s %s\n", time.Now().Format("15:04:05"), n.Title)
+			return
+		}
+	}
+	stored, err := w.store.Post(n)
+	if err != nil {
+		cliErrf(w.env.Stderr, "warning: %s\n", err)
+		return
+	}
+	_, _ = fmt.Fprintf(w.env.Stdout, "%s %s\n", time.Now().Format("15:04:05"), stored.Title)
+	if w.sinks.Empty() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	for _, err := range w.sinks.Deliver(ctx, stored, time.Now()) {
+		cliErrf(w.env.Stderr, "warning: %s\n", err)
+	}
+}
+
+// shownProjects is the canonical roots of the registered projects a live
+// instance has open.
+func (w *watchSession) shownProjects() map[string]bool {
+	instances, err := uirequest.ListInstances(w.env.StateDir)
+	if err != nil || len(instances) == 0 {
+		return nil
+	}
+	keys := make(map[string]bool, len(instances))
+	for _, in := range instances {
+		keys[in.ProjectKey] = true
+	}
+	projects, err := loadRegisteredProjects(w.env.StateDir)
+	if err != nil {
+		return nil
+	}
+	shown := make(map[string]bool)
+	for _, p := range projects {
+		if keys[p.Key] && p.Path != "" {
+			shown[workspaceinventory.CanonicalPath(p.Path)] = true
+		}
+	}
+	return shown
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/marcus/sidecar/internal/agentwatch"
+	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/notify"
+	"github.com/marcus/sidecar/internal/workspaceinventory"
+)
+
+// noPanes is a tmux with nothing running and no repositories to inventory.
+type noPanes struct{}
+
+func (noPanes) Output(context.Context, string, ...string) ([]byte, error) { return nil, nil }
+
+func watchEnv(t *testing.T) Env {
+	t.Helper()
+	env, _, _ := notifyEnv(t)
+	config.SetTestConfigPath(filepath.Join(t.TempDir(), "config.json"))
+	t.Cleanup(config.ResetTestConfigPath)
+	newCollector = func() workspaceinventory.Collector { return workspaceinventory.Collector{Runner: noPanes{}} }
+	t.Cleanup(func() {
+		newCollector = func() workspaceinventory.Collector { return workspaceinventory.Collector{} }
+	})
+	return env
+}
+
+func TestWatchUsageErrors(t *testing.T) {
+	env, _, errOut := notifyEnv(t)
+	for _, args := range [][]string{{"--interval"}, {"--interval", "soon"}, {"--interval=10ms"}, {"--bogus"}} {
+		if code := runWatch(env, args); code != 2 {
+			t.Fatalf("watch %v = %d, want 2 (stderr %q)", args, code, errOut.String())
+		}
+	}
+}
+
+func TestWatchRefusesASecondWatcher(t *testing.T) {
+	env := watchEnv(t)
+	unlock, err := lockWatch(env.StateDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := runWatch(env, []string{"--once"}); code != 3 {
+		t.Fatalf("second watcher = %d, want 3", code)
+	}
+	unlock()
+	if code := runWatch(env, []string{"--once"}); code != 0 {
+		t.Fatalf("watch --once after release = %d, want 0", code)
+	}
+}
+
+func TestWatchFilesAndWithdrawsWithNoInstanceRunning(t *testing.T) {
+	env, _, _ := notifyEnv(t)
+	store, err := notify.Open(env.StateDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+	w := &watchSession{env: env, store: store}
+
+	n := notify.Normalize(notify.Notification{ID: notify.NewID(), Source: notify.SourceWaiting, Title: "Shell 1 is waiting"}, time.Now())
+	w.handle(agentwatch.Round{Post: []agentwatch.Post{{Notification: n, ProjectRoot: t.TempDir()}}}, nil)
+	all, err := notify.ReadAll(notify.Path(env.StateDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notify.Unread(all)) != 1 {
+		t.Fatalf("log = %+v, want the waiting notification", all)
+	}
+
+	// An id the log never saw is a post left to a running instance.
+	w.handle(agentwatch.Round{Dismiss: []string{n.ID, "n-unknown"}}, nil)
+	all, err = notify.ReadAll(notify.Path(env.StateDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notify.Unread(all)) != 0 {
+		t.Fatalf("log = %+v, want it withdrawn", all)
+	}
+}
@@ -0,0 +1,854 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/marcus/sidecar/internal/activitystore"
+	"github.com/marcus/sidecar/internal/controlapi"
+	"github.com/marcus/sidecar/internal/gitstate"
+	"github.com/marcus/sidecar/internal/notify"
+	"github.com/marcus/sidecar/internal/tty"
+	"github.com/marcus/sidecar/internal/workspaceinventory"
+	"github.com/marcus/sidecar/internal/workspaceops"
+)
+
+// workspaceStopGrace is how long stop waits after Ctrl-C before closing a
+// worktree agent's session, as the workspace plugin's stop does.
+const workspaceStopGrace = 2 * time.Second
+
+func runWorkspaceRoot(env Env, args []string) int {
+	cmd := RootCommand().FindSubcommand("workspace")
+	if len(args) == 0 || isHelp(args[0]) {
+		_, _ = fmt.Fprint(env.Stdout, RenderHelp(cmd))
+		return 0
+	}
+	sub := cmd.FindSubcommand(args[0])
+	if sub != nil && sub.Run != nil {
+		return sub.Run(env, args[1:])
+	}
+	cliErrf(env.Stderr, "unknown workspace command %q\n\n%s", args[0], RenderHelp(cmd))
+	return 2
+}
+
+// workspaceEntry is one workspace as the workspace commands report it. The
+// id is the inventory's, stable across runs, and is the surest reference to
+// pass back to another workspace command.
+type workspaceEntry struct {
+	ID      string `json:"id"`
+	Project string `json:"project"`
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Branch  string `json:"branch,omitempty"`
+	Main    bool   `json:"main,omitempty"`
+	Session string `json:"session,omitempty"`
+	Pane    string `json:"pane,omitempty"`
+	Live    bool   `json:"live"`
+	Agent   string `json:"agent,omitempty"`
+	// State is the agent's lane: working, blocked, done, idle or paused.
+	// Workspaces with no agent have none.
+	State string `json:"state,omitempty"`
+	Task  string `json:"task,omitempty"`
+}
+
+func newWorkspaceEntry(w workspaceinventory.Workspace) workspaceEntry {
+	entry := workspaceEntry{
+		ID: w.ID, Project: w.ProjectRoot, Kind: string(w.Kind), Name: w.Name, Path: w.Path,
+		Branch: w.Branch, Main: w.IsMain, Session: w.TmuxName, Pane: w.PaneID, Live: w.Live,
+		Agent: w.Provider, Task: w.TaskID,
+	}
+	if w.HasAgent() {
+		entry.State = string(w.Presentation.Lane)
+	}
+	return entry
+}
+
+// parseWorkspaceArgs reads the flags every workspace command shares and
+// returns the positional arguments. extra claims a command's own flags. code
+// is non-zero when the caller should return it.
+func parseWorkspaceArgs(env Env, help string, args []string, extra func(arg string, args []string, i int) (next int, handled bool, code int)) (positional []string, projectFlag string, jsonOutput bool, code int, done bool) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case isHelp(arg):
+			_, _ = fmt.Fprint(env.Stdout, help)
+			return nil, "", false, 0, true
+		case arg == "--json":
+			jsonOutput = true
+		case arg == "--project" || strings.HasPrefix(arg, "--project="):
+			value, next, ok := takeFlagArg(arg, args, i, "--project")
+			if !ok || value == "" {
+				cliErrf(env.Stderr, "--project requires a project name\n\n%s", help)
+				return nil, "", false, 2, true
+			}
+			projectFlag, i = value, next
+		case arg == "--":
+			// Everything after -- is positional, so send-keys can type text
+			// that starts with a dash.
+			positional = append(positional, args[i+1:]...)
+			return positional, projectFlag, jsonOutput, 0, false
+		default:
+			if extra != nil {
+				next, handled, c := extra(arg, args, i)
+				if c != 0 {
+					return nil, "", false, c, true
+				}
+				if handled {
+					i = next
+					continue
+				}
+			}
+			if strings.HasPrefix(arg, "-") {
+				cliErrf(env.Stderr, "unknown option %q\n\n%s", arg, help)
+				return nil, "", false, 2, true
+			}
+			positional = append(positional, arg)
+		}
+	}
+	return positional, projectFlag, jsonOutput, 0, false
+}
+
+// resolveWorkspaceProjects is the registered projects a workspace command
+// reads: --project's, every one with all, or the one containing the working
+// directory. A worktree the project never recorded still finds it through
+// its main worktree. ref, when it is an inventory id, names its own project,
+// so an id copied from `list --all` works from anywhere.
+func resolveWorkspaceProjects(env Env, projectFlag, ref string, all bool) ([]registeredProject, error) {
+	projects, err := loadRegisteredProjects(env.StateDir)
+	if err != nil {
+		return nil, err
+	}
+	if all {
+		var out []registeredProject
+		for _, p := range projects {
+			if p.Path != "" {
+				out = append(out, p)
+			}
+		}
+		return out, nil
+	}
+	if projectFlag != "" {
+		proj, err := matchProject(projects, projectFlag)
+		if err != nil {
+			return nil, err
+		}
+		if proj.Path == "" {
+			return nil, &destError{code: 1, msg: fmt.Sprintf("project %q has no recorded path", projectFlag)}
+		}
+		return []registeredProject{proj}, nil
+	}
+	if proj, ok := projectForWorkspaceID(projects, ref); ok {
+		return []registeredProject{proj}, nil
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, &destError{code: 1, msg: "resolve current directory: " + err.Error()}
+	}
+	if proj, _, ok := uniqueProjectContaining(projects, wd); ok && proj.Path != "" {
+		return []registeredProject{proj}, nil
+	}
+	ctx := env.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if main := workspaceops.MainWorktreePath(ctx, wd); main != "" {
+		for _, p := range projects {
+			if p.Path != "" && canonicalOpenPath(p.Path) == canonicalOpenPath(main) {
+				return []registeredProject{p}, nil
+			}
+		}
+	}
+	return nil, &destError{code: 2, msg: unregisteredCreateProject}
+}
+
+// projectForWorkspaceID finds the project an inventory id belongs to. Ids are
+// the project's canonical root, the kind, and the workspace key.
+func projectForWorkspaceID(projects []registeredProject, ref string) (registeredProject, bool) {
+	for _, kind := range []workspaceinventory.Kind{workspaceinventory.KindWorktree, workspaceinventory.KindShell} {
+		root, _, ok := strings.Cut(ref, ":"+string(kind)+":")
+		if !ok || root == "" {
+			continue
+		}
+		for _, p := range projects {
+			if p.Path != "" && workspaceinventory.CanonicalPath(p.Path) == root {
+				return p, true
+			}
+		}
+	}
+	return registeredProject{}, false
+}
+
+// collectWorkspaces inventories projects and observes their panes once, the
+// way a single board refresh does. Agent states read from the activity store
+// are a starting point only: nothing here writes it back, because one capture
+// is not enough to move a lane and `sidecar watch` owns the store.
+func collectWorkspaces(ctx context.Context, env Env, projects []registeredProject) ([]workspaceinventory.Workspace, []error, error) {
+	registered, err := loadRegisteredProjects(env.StateDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	roots := make([]string, 0, len(registered))
+	for _, p := range registered {
+		if p.Path != "" {
+			roots = append(roots, p.Path)
+		}
+	}
+	collector := newCollector().WithDefaults()
+	collector = collector.SeedTrackers(activitystore.Load(filepath.Join(env.StateDir, activitystore.FileName), time.Now()))
+	results := make([]workspaceinventory.ProjectResult, 0, len(projects))
+	for _, p := range projects {
+		results = append(results, collector.CollectProjectInventory(ctx, filepath.Base(p.Path), p.Path))
+	}
+	panes, err := collector.ListPanes(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	refresh := collector.ForRefresh(4, workspaceinventory.BuildShellClaims(results))
+	var (
+		workspaces []workspaceinventory.Workspace
+		warnings   []error
+	)
+	for _, inventory := range results {
+		if inventory.Err != nil {
+			warnings = append(warnings, fmt.Errorf("%s: %w", inventory.ProjectRoot, inventory.Err))
+		}
+		status := refresh.RefreshProjectStatus(ctx, inventory, roots, panes)
+		workspaces = append(workspaces, status.Workspaces...)
+	}
+	return workspaces, warnings, nil
+}
+
+// matchWorkspace resolves ref against workspaces: an exact id or tmux
+// session first, then a name, then a branch, then a path. The first tier with
+// any match decides, and more than one match there is ambiguous.
+func matchWorkspace(workspaces []workspaceinventory.Workspace, ref string) (workspaceinventory.Workspace, error) {
+	wantPath := ""
+	if abs, err := filepath.Abs(ref); err == nil && (filepath.IsAbs(ref) || strings.ContainsRune(ref, filepath.Separator) || ref == ".") {
+		wantPath = workspaceinventory.CanonicalPath(abs)
+	}
+	tiers := []func(workspaceinventory.Workspace) bool{
+		func(w workspaceinventory.Workspace) bool {
+			return w.ID == ref || (w.TmuxName != "" && w.TmuxName == ref)
+		},
+		func(w workspaceinventory.Workspace) bool { return w.Name == ref },
+		func(w workspaceinventory.Workspace) bool { return w.Branch != "" && w.Branch == ref },
+		func(w workspaceinventory.Workspace) bool {
+			// A shell's path is the project root; only a worktree is named
+			// by where it lives.
+			return wantPath != "" && w.Kind == workspaceinventory.KindWorktree && workspaceinventory.CanonicalPath(w.Path) == wantPath
+		},
+	}
+	for _, match := range tiers {
+		var hits []workspaceinventory.Workspace
+		for _, w := range workspaces {
+			if match(w) {
+				hits = append(hits, w)
+			}
+		}
+		switch len(hits) {
+		case 0:
+			continue
+		case 1:
+			return hits[0], nil
+		default:
+			ids := make([]string, 0, len(hits))
+			for _, w := range hits {
+				ids = append(ids, w.ID)
+			}
+			return workspaceinventory.Workspace{}, &destError{code: 3, msg: fmt.Sprintf("workspace %q matches more than one workspace (%s); pass an id", ref, strings.Join(ids, ", "))}
+		}
+	}
+	return workspaceinventory.Workspace{}, &destError{code: 3, msg: fmt.Sprintf("no workspace %q", ref)}
+}
+
+// findWorkspace resolves the one workspace a command acts on, reporting any
+// failure to stderr. code is non-zero when the caller should return it.
+func findWorkspace(env Env, projectFlag, ref string) (workspaceinventory.Workspace, int) {
+	ctx := env.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	projects, err := resolveWorkspaceProjects(env, projectFlag, ref, false)
+	if err != nil {
+		cliErrln(env.Stderr, err)
+		return workspaceinventory.Workspace{}, destExitCode(err)
+	}
+	workspaces, warnings, err := collectWorkspaces(ctx, env, projects)
+	if err != nil {
+		cliErrln(env.Stderr, err)
+		return workspaceinventory.Workspace{}, 1
+	}
+	w, err := matchWorkspace(workspaces, ref)
+	if err != nil {
+		for _, warning := range warnings {
+			cliErrf(env.Stderr, "warning: %s\n", warning)
+		}
+		cliErrln(env.Stderr, err)
+		return workspaceinventory.Workspace{}, destExitCode(err)
+	}
+	return w, 0
+}
+
+// workspaceSession is the tmux session a workspace's terminal runs in, or ""
+// when nothing is running. A worktree agent started outside the pane the
+// inventory correlated is still found by the session names Sidecar gives it.
+func workspaceSession(w workspaceinventory.Workspace) string {
+	if w.Live && w.TmuxName != "" {
+		return w.TmuxName
+	}
+	if w.Kind == workspaceinventory.KindShell {
+		if workspaceops.SessionExists(w.TmuxName) {
+			return w.TmuxName
+		}
+		return ""
+	}
+	for _, name := range workspaceops.WorktreeSessionNames(w.Path, w.Name) {
+		if workspaceops.SessionExists(name) {
+			return name
+		}
+	}
+	return ""
+}
+
+func writeWorkspaceJSON(env Env, v any) int {
+	if err := json.NewEncoder(env.Stdout).Encode(v); err != nil {
+		cliErrln(env.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func runWorkspaceList(env Env, args []string) int {
+	help := RenderHelp(RootCommand().FindSubcommand("workspace").FindSubcommand("list"))
+
+	all := false
+	positional, projectFlag, jsonOutput, code, done := parseWorkspaceArgs(env, help, args, func(arg string, _ []string, i int) (int, bool, int) {
+		if arg == "--all" {
+			all = true
+			return i, true, 0
+		}
+		return i, false, 0
+	})
+	if done {
+		return code
+	}
+	if len(positional) != 0 {
+		cliErrf(env.Stderr, "workspace list takes no arguments\n\n%s", help)
+		return 2
+	}
+	if all && projectFlag != "" {
+		cliErrf(env.Stderr, "--all and --project cannot be combined\n\n%s", help)
+		return 2
+	}
+
+	ctx := env.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	projects, err := resolveWorkspaceProjects(env, projectFlag, "", all)
+	if err != nil {
+		cliErrln(env.Stderr, err)
+		return destExitCode(err)
+	}
+	workspaces, warnings, err := collectWorkspaces(ctx, env, projects)
+	if err != nil {
+		cliErrln(env.Stderr, err)
+		return 1
+	}
+	for _, warning := range warnings {
+		cliErrf(env.Stderr, "warning: %s\n", warning)
+	}
+	items := make([]workspaceEntry, 0, len(workspaces))
+	for _, w := range workspaces {
+		items = append(items, newWorkspaceEntry(w))
+	}
+
+	if jsonOutput {
+		out := struct {
+			Project string           `json:"project,omitempty"`
+			Items   []workspaceEntry `json:"items"`
+		}{Items: items}
+		if !all && len(projects) == 1 {
+			out.Project = workspaceinventory.CanonicalPath(projects[0].Path)
+		}
+		return writeWorkspaceJSON(env, out)
+	}
+
+	if len(items) == 0 {
+		_, _ = fmt.Fprintln(env.Stdout, "No workspaces.")
+		return 0
+	}
+	for _, item := range items {
+		state := item.State
+		if state == "" {
+			state = "-"
+		}
+		line := fmt.Sprintf("%-8s  %-24s  %-7s", item.Kind, item.Name, state)
+		if item.Kind == string(workspaceinventory.KindWorktree) {
+			line += "  " + item.Path
+			if item.Branch != "" {
+				line += "  (" + item.Branch + ")"
+			}
+		} else if item.Session != "" {
+			line += "  " + item.Session
+		}
+		if all {
+			line += "  [" + filepath.Base(item.Project) + "]"
+		}
+		_, _ = fmt.Fprintln(env.Stdout, strings.TrimRight(line, " "))
+	}
+	return 0
+}
+
+func runWorkspaceShow(env Env, args []string) int {
+	help := RenderHelp(RootCommand().FindSubcommand("workspace").FindSubcommand("show"))
+
+	positional, projectFlag, jsonOutput, code, done := parseWorkspaceArgs(env, help, args, nil)
+	if done {
+		return code
+	}
+	if len(positional) != 1 {
+		cliErrf(env.Stderr, "workspace show requires exactly one workspace\n\n%s", help)
+		return 2
+	}
+	w, code := findWorkspace(env, projectFlag, positional[0])
+	if code != 0 {
+		return code
+	}
+
+	ctx := env.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	out := struct {
+		workspaceEntry
+		Head      string               `json:"head,omitempty"`
+		Operation string               `json:"operation,omitempty"`
+		Git       *controlapi.GitState `json:"git,omitempty"`
+	}{workspaceEntry: newWorkspaceEntry(w)}
+	if w.Kind == workspaceinventory.KindWorktree && !w.IsMissing {
+		out.Head = workspaceops.BranchOID(ctx, w.ProjectRoot, w.Branch)
+		if state := workspaceops.WorktreeOperationState(ctx, w.Path); state != "clean" {
+			out.Operation = state
+		}
+		if snapshot, err := gitstate.Load(ctx, w.Path); err == nil {
+			out.Git = workspaceGitState(snapshot)
+		}
+	}
+
+	if jsonOutput {
+		return writeWorkspaceJSON(env, out)
+	}
+	field := func(name, value string) {
+		if value != "" {
+			_, _ = fmt.Fprintf(env.Stdout, "%-10s %s\n", name+":", value)
+		}
+	}
+	field("Name", out.Name)
+	field("ID", out.ID)
+	field("Kind", out.Kind)
+	field("Path", out.Path)
+	field("Branch", out.Branch)
+	field("Head", out.Head)
+	field("Operation", out.Operation)
+	field("Agent", out.Agent)
+	field("State", out.State)
+	field("Task", out.Task)
+	field("Session", out.Session)
+	field("Live", strconv.FormatBool(out.Live))
+	if out.Git != nil {
+		c := out.Git.Counts
+		field("Changes", fmt.Sprintf("%d modified, %d staged, %d untracked, %d conflicted", c.Modified, c.Staged, c.Untracked, c.Conflicted))
+	}
+	return 0
+}
+
+// workspaceGitState is a snapshot in the control API's git topic shape, so a
+// script reads one format whichever way it asked.
+func workspaceGitState(s *gitstate.Snapshot) *controlapi.GitState {
+	state := &controlapi.GitState{Root: s.Root, Files: []controlapi.GitFile{}}
+	for _, path := range s.Paths() {
+		rel, err := filepath.Rel(s.Root, path)
+		if err != nil {
+			continue
+		}
+		flags := s.File(path)
+		state.Files = append(state.Files, controlapi.GitFile{Path: filepath.ToSlash(rel), States: flags.Names()})
+		if flags&gitstate.Modified != 0 {
+			state.Counts.Modified++
+		}
+		if flags&gitstate.Staged != 0 {
+			state.Counts.Staged++
+		}
+		if flags&gitstate.Untracked != 0 {
+			state.Counts.Untracked++
+		}
+		if flags&gitstate.Conflicted != 0 {
+			state.Counts.Conflicted++
+		}
+	}
+	return state
+}
+
+func runWorkspaceSendKeys(env Env, args []string) int {
+	help := RenderHelp(RootCommand().FindSubcommand("workspace").FindSubcommand("send-keys"))
+
+	enter := false
+	positional, projectFlag, jsonOutput, code, done := parseWorkspaceArgs(env, help, args, func(arg string, _ []string, i int) (int, bool, int) {
+		if arg == "--enter" {
+			enter = true
+			return i, true, 0
+		}
+		return i, false, 0
+	})
+	if done {
+		return code
+	}
+	if len(positional) == 0 {
+		cliErrf(env.Stderr, "workspace send-keys requires a workspace\n\n%s", help)
+		return 2
+	}
+	text := strings.Join(positional[1:], " ")
+	if text == "" && !enter {
+		cliErrf(env.Stderr, "workspace send-keys requires text, --enter, or both\n\n%s", help)
+		return 2
+	}
+	w, code := findWorkspace(env, projectFlag, positional[0])
+	if code != 0 {
+		return code
+	}
+	session := workspaceSession(w)
+	if session == "" {
+		cliErrf(env.Stderr, "workspace %q has no running session\n", w.Name)
+		return 4
+	}
+
+	var strokes []notify.Keystroke
+	if text != "" {
+		strokes = append(strokes, notify.Keystroke{Keys: text, Literal: true})
+	}
+	if enter {
+		strokes = append(strokes, notify.Keystroke{Keys: "Enter"})
+	}
+	if err := workspaceops.AnswerAgent(env.Ctx, session, strokes); err != nil {
+		cliErrln(env.Stderr, err)
+		return 1
+	}
+	if jsonOutput {
+		return writeWorkspaceJSON(env, struct {
+			ID      string `json:"id"`
+			Session string `json:"session"`
+			Sent    bool   `json:"sent"`
+		}{ID: w.ID, Session: session, Sent: true})
+	}
+	_, _ = fmt.Fprintf(env.Stdout, "Sent to %s.\n", w.Name)
+	return 0
+}
+
+func runWorkspaceCapture(env Env, args []string) int {
+	help := RenderHelp(RootCommand().FindSubcommand("workspace").FindSubcommand("capture"))
+
+	lines := 0
+	keepANSI := false
+	positional, projectFlag, jsonOutput, code, done := parseWorkspaceArgs(env, help, args, func(arg string, args []string, i int) (int, bool, int) {
+		switch {
+		case arg == "--ansi":
+			keepANSI = true
+			return i, true, 0
+		case arg == "--lines" || strings.HasPrefix(arg, "--lines="):
+			value, next, ok := takeFlagArg(arg, args, i, "--lines")
+			n, err := strconv.Atoi(value)
+			if !ok || err != nil || n < 0 {
+				cliErrf(env.Stderr, "--lines requires a non-negative number\n\n%s", help)
+				return i, false, 2
+			}
+			lines = n
+			return next, true, 0
+		}
+		return i, false, 0
+	})
+	if done {
+		return code
+	}
+	if len(positional) != 1 {
+		cliErrf(env.Stderr, "workspace capture requires exactly one workspace\n\n%s", help)
+		return 2
+	}
+	w, code := findWorkspace(env, projectFlag, positional[0])
+	if code != 0 {
+		return code
+	}
+	target := w.PaneID
+	if target == "" || !w.Live {
+		target = workspaceSession(w)
+	}
+	if target == "" {
+		cliErrf(env.Stderr, "workspace %q has no running session\n", w.Name)
+		return 4
+	}
+
+	output, err := tty.CapturePaneOutput(target, lines)
+	if err != nil {
+		cliErrln(env.Stderr, err)
+		return 1
+	}
+	if !keepANSI {
+		output = ansi.Strip(output)
+	}
+	if jsonOutput {
+		return writeWorkspaceJSON(env, struct {
+			ID     string `json:"id"`
+			Target string `json:"target"`
+			Output string `json:"output"`
+		}{ID: w.ID, Target: target, Output: output})
+	}
+	_, _ = fmt.Fprint(env.Stdout, output)
+	if output != "" && !strings.HasSuffix(output, "\n") {
+		_, _ = fmt.Fprintln(env.Stdout)
+	}
+	return 0
+}
+
+func runWorkspaceStop(env Env, args []string) int {
+	help := RenderHelp(RootCommand().FindSubcommand("workspace").FindSubcommand("stop"))
+
+	positional, projectFlag, jsonOutput, code, done := parseWorkspaceArgs(env, help, args, nil)
+	if done {
+		return code
+	}
+	if len(positional) != 1 {
+		cliErrf(env.Stderr, "workspace stop requires exactly one workspace\n\n%s", help)
+		return 2
+	}
+	w, code := findWorkspace(env, projectFlag, positional[0])
+	if code != 0 {
+		return code
+	}
+	session := workspaceSession(w)
+	if session == "" {
+		cliErrf(env.Stderr, "workspace %q has no running session\n", w.Name)
+		return 4
+	}
+
+	// A shell outlives its agent: Ctrl-C ends the agent's turn and leaves
+	// the user's shell. A worktree session exists for its agent, so it is
+	// closed if Ctrl-C does not end it.
+	if w.Kind == workspaceinventory.KindShell {
+		err := workspaceops.InterruptSession(env.Ctx, session)
+		if err != nil {
+			cliErrln(env.Stderr, err)
+			return 1
+		}
+	} else if err := workspaceops.StopWorktreeAgent(env.Ctx, session, workspaceStopGrace); err != nil {
+		cliErrln(env.Stderr, err)
+		return 1
+	}
+	if jsonOutput {
+		return writeWorkspaceJSON(env, struct {
+			ID      string `json:"id"`
+			Session string `json:"session"`
+			Stopped bool   `json:"stopped"`
+		}{ID: w.ID, Session: session, Stopped: true})
+	}
+	_, _ = fmt.Fprintf(env.Stdout, "Stopped %s.\n", w.Name)
+	return 0
+}
+
+// worktreeActionState is the refusal input for a worktree the inventory
+// found.
+func worktreeActionState(w workspaceinventory.Workspace) *workspaceops.WorktreeActionState {
+	return &workspaceops.WorktreeActionState{
+		Path: w.Path, Branch: w.Branch,
+		IsMain: w.IsMain, IsBare: w.IsBare, IsDetached: w.IsDetached, IsLocked: w.IsLocked,
+		IsMissing: w.IsMissing, IsPrunable: w.IsPrunable,
+	}
+}
+
+func runWorkspaceMerge(env Env, args []string) int {
+	help := RenderHelp(RootCommand().FindSubcommand("workspace").FindSubcommand("merge"))
+
+	into := ""
+	positional, projectFlag, jsonOutput, code, done := parseWorkspaceArgs(env, help, args, func(arg string, args []string, i int) (int, bool, int) {
+		if arg != "--into" && !strings.HasPrefix(arg, "--into=") {
+			return i, false, 0
+		}
+		value, next, ok := takeFlagArg(arg, args, i, "--into")
+		if !ok || value == "" {
+			cliErrf(env.Stderr, "--into requires a branch\n\n%s", help)
+			return i, false, 2
+		}
+		into = value
+		return next, true, 0
+	})
+	if done {
+		return code
+	}
+	if len(positional) != 1 {
+		cliErrf(env.Stderr, "workspace merge requires exactly one worktree\n\n%s", help)
+		return 2
+	}
+	w, code := findWorkspace(env, projectFlag, positional[0])
+	if code != 0 {
+		return code
+	}
+	if w.Kind != workspaceinventory.KindWorktree {
+		cliErrf(env.Stderr, "workspace %q is a shell; only a worktree can be merged\n", w.Name)
+		return 4
+	}
+	if refusal := workspaceops.WorktreeActionRefusal(worktreeActionState(w), workspaceops.WorktreeActionMerge); refusal != "" {
+		cliErrln(env.Stderr, refusal)
+		return 4
+	}
+
+	ctx := env.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if into == "" {
+		into = workspaceops.DefaultBranch(ctx, w.ProjectRoot)
+	}
+	if into == w.Branch {
+		cliErrf(env.Stderr, "%s cannot be merged into itself\n", w.Branch)
+		return 4
+	}
+	op, err := workspaceops.PreflightDirectMerge(ctx, w.ProjectRoot, w.Path, w.Branch, into)
+	if err != nil {
+		cliErrf(env.Stderr, "merge refused: %s\n", err)
+		return 4
+	}
+	op = workspaceops.RunDirectMerge(ctx, op)
+	switch {
+	case op.Recovery == workspaceops.DirectMergeRecoveryConflict:
+		// Nobody is here to resolve it, so the target goes back to where it
+		// was rather than being left mid-merge.
+		aborted := workspaceops.AbortDirectMerge(ctx, op)
+		if !aborted.Aborted {
+			cliErrf(env.Stderr, "merge of %s into %s conflicted and could not be aborted: %s\n", w.Branch, into, aborted.Err)
+			return 1
+		}
+		cliErrf(env.Stderr, "merge of %s into %s conflicted and was aborted; merge it in Sidecar to resolve the conflict\n", w.Branch, into)
+		return 4
+	case op.Recovery == workspaceops.DirectMergeRecoveryPushFailure:
+		cliErrf(env.Stderr, "merged %s into %s locally at %s, but the push to %s failed: %s\n", w.Branch, into, op.MergeOID, op.Remote, op.Err)
+		return 1
+	case op.Err != nil:
+		cliErrf(env.Stderr, "merge failed: %s\n", op.Err)
+		return 1
+	}
+
+	if jsonOutput {
+		return writeWorkspaceJSON(env, struct {
+			ID     string `json:"id"`
+			Source string `json:"source"`
+			Target string `json:"target"`
+			Remote string `json:"remote"`
+			Merge  string `json:"merge"`
+		}{ID: w.ID, Source: w.Branch, Target: into, Remote: op.Remote, Merge: op.MergeOID})
+	}
+	_, _ = fmt.Fprintf(env.Stdout, "Merged %s into %s and pushed to %s.\n", w.Branch, into, op.Remote)
+	return 0
+}
+
+func runWorkspaceDelete(env Env, args []string) int {
+	help := RenderHelp(RootCommand().FindSubcommand("workspace").FindSubcommand("delete"))
+
+	force := false
+	deleteBranch := false
+	positional, projectFlag, jsonOutput, code, done := parseWorkspaceArgs(env, help, args, func(arg string, _ []string, i int) (int, bool, int) {
+		switch arg {
+		case "--force":
+			force = true
+		case "--delete-branch":
+			deleteBranch = true
+		default:
+			return i, false, 0
+		}
+		return i, true, 0
+	})
+	if done {
+		return code
+	}
+	if len(positional) != 1 {
+		cliErrf(env.Stderr, "workspace delete requires exactly one workspace\n\n%s", help)
+		return 2
+	}
+	w, code := findWorkspace(env, projectFlag, positional[0])
+	if code != 0 {
+		return code
+	}
+
+	if w.Kind == workspaceinventory.KindShell {
+		if deleteBranch {
+			cliErrf(env.Stderr, "workspace %q is a shell and has no branch to delete\n", w.Name)
+			return 2
+		}
+		if err := workspaceops.DeleteManagedShell(w.ProjectRoot, w.TmuxName, w.Namespace); err != nil {
+			cliErrln(env.Stderr, err)
+			return 1
+		}
+		return reportWorkspaceDeleted(env, w, jsonOutput, false)
+	}
+
+	if refusal := workspaceops.WorktreeActionRefusal(worktreeActionState(w), workspaceops.WorktreeActionDelete); refusal != "" {
+		cliErrln(env.Stderr, refusal)
+		return 4
+	}
+	ctx := env.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if deleteBranch && workspaceops.IsDefaultBranch(ctx, w.ProjectRoot, w.Branch) {
+		cliErrf(env.Stderr, "refusing to delete the default branch %q\n", w.Branch)
+		return 4
+	}
+	oid := workspaceops.BranchOID(ctx, w.ProjectRoot, w.Branch)
+	err := workspaceops.DeleteWorktree(ctx, workspaceops.WorktreeRemoval{
+		RepoPath:    w.ProjectRoot,
+		ProjectRoot: w.ProjectRoot,
+		Path:        w.Path,
+		Branch:      w.Branch,
+		ExpectedOID: oid,
+		Force:       force,
+	})
+	if errors.Is(err, workspaceops.ErrWorktreeDirty) {
+		cliErrf(env.Stderr, "%s; pass --force to delete it anyway\n", err)
+		return 4
+	}
+	if err != nil {
+		cliErrln(env.Stderr, err)
+		return 1
+	}
+	if deleteBranch {
+		if err := workspaceops.DeleteLocalBranch(ctx, workspaceops.BranchDeletion{RepoPath: w.ProjectRoot, Branch: w.Branch, ExpectedOID: oid, Force: force}); err != nil {
+			cliErrf(env.Stderr, "deleted the worktree, but not branch %s: %s\n", w.Branch, err)
+			return 1
+		}
+	}
+	return reportWorkspaceDeleted(env, w, jsonOutput, deleteBranch)
+}
+
+func reportWorkspaceDeleted(env Env, w workspaceinventory.Workspace, jsonOutput, branchDeleted bool) int {
+	if jsonOutput {
+		return writeWorkspaceJSON(env, struct {
+			ID            string `json:"id"`
+			Deleted       bool   `json:"deleted"`
+			BranchDeleted bool   `json:"branchDeleted,omitempty"`
+		}{ID: w.ID, Deleted: true, BranchDeleted: branchDeleted})
+	}
+	if branchDeleted {
+		_, _ = fmt.Fprintf(env.Stdout, "Deleted %s and branch %s.\n", w.Name, w.Branch)
+		return 0
+	}
+	_, _ = fmt.Fprintf(env.Stdout, "Deleted %s.\n", w.Name)
+	return 0
+}
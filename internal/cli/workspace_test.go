@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/projectdir"
+	"github.com/marcus/sidecar/internal/tmuxenv"
+	"github.com/marcus/sidecar/internal/tty"
+	"github.com/marcus/sidecar/internal/workspaceinventory"
+)
+
+// workspaceRunner answers the inventory's git and tmux calls for one project.
+type workspaceRunner struct {
+	worktrees string
+	panes     string
+}
+
+func (r workspaceRunner) Output(_ context.Context, name string, _ ...string) ([]byte, error) {
+	if name == "tmux" {
+		return []byte(r.panes), nil
+	}
+	return []byte(r.worktrees), nil
+}
+
+// workspaceProject registers a project named app with a main worktree, a
+// feature worktree and a copilot shell whose pane is live.
+func workspaceProject(t *testing.T) (env Env, out, errOut *strings.Builder, root, feature string) {
+	t.Helper()
+	base, _, _ := notifyEnv(t)
+	out, errOut = &strings.Builder{}, &strings.Builder{}
+	env = Env{Stdout: out, Stderr: errOut, StateDir: base.StateDir}
+	config.SetTestStateDir(env.StateDir)
+	t.Cleanup(config.ResetTestStateDir)
+
+	root = workspaceinventory.CanonicalPath(filepath.Join(t.TempDir(), "app"))
+	feature = workspaceinventory.CanonicalPath(filepath.Join(t.TempDir(), "app-feature"))
+	for _, dir := range []string{root, feature} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	projectState, err := projectdir.ResolveWithBase(env.StateDir, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := `{"version":1,"shells":[{"tmuxName":"sidecar-sh-app-1","displayName":"Shell 1","namespace":"` + tmuxenv.Namespace() + `","agentType":"copilot"}]}`
+	if err := os.WriteFile(filepath.Join(projectState, "shells.json"), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := workspaceRunner{
+		worktrees: "worktree " + root + "\nHEAD abc\nbranch refs/heads/main\n\n" +
+			"worktree " + feature + "\nHEAD def\nbranch refs/heads/feature\n",
+		panes: "%1\tsidecar-sh-app-1\t" + root + "\tcopilot\tcopilot\t0",
+	}
+	newCollector = func() workspaceinventory.Collector {
+		return workspaceinventory.Collector{
+			Runner:  runner,
+			Capture: func(string, int) (string, tty.PaneState, error) { return "esc again to cancel", tty.PaneState{}, nil },
+			Now:     time.Now,
+		}
+	}
+	t.Cleanup(func() {
+		newCollector = func() workspaceinventory.Collector { return workspaceinventory.Collector{} }
+	})
+	return env, out, errOut, root, feature
+}
+
+func TestWorkspaceUsageErrors(t *testing.T) {
+	env, _, errOut := notifyEnv(t)
+	for _, tc := range []struct {
+		run  func(Env, []string) int
+		args []string
+	}{
+		{runWorkspaceRoot, []string{"bogus"}},
+		{runWorkspaceList, []string{"extra"}},
+		{runWorkspaceList, []string{"--all", "--project", "app"}},
+		{runWorkspaceShow, nil},
+		{runWorkspaceSendKeys, []string{"feature"}},
+		{runWorkspaceCapture, []string{"--lines", "-1", "feature"}},
+		{runWorkspaceMerge, []string{"--into"}},
+		{runWorkspaceDelete, []string{"--bogus", "feature"}},
+	} {
+		if code := tc.run(env, tc.args); code != 2 {
+			t.Fatalf("%v = %d, want 2 (stderr %q)", tc.args, code, errOut.String())
+		}
+	}
+}
+
+func TestWorkspaceListReportsWorktreesAndShells(t *testing.T) {
+	env, out, errOut, root, feature := workspaceProject(t)
+	if code := runWorkspaceList(env, []string{"--project", "app", "--json"}); code != 0 {
+		t.Fatalf("list = %d, stderr %q", code, errOut.String())
+	}
+	var got struct {
+		Project string           `json:"project"`
+		Items   []workspaceEntry `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(out.String()), &got); err != nil {
+		t.Fatalf("list output %q: %v", out.String(), err)
+	}
+	if got.Project != root || len(got.Items) != 3 {
+		t.Fatalf("list = %+v, want three workspaces of %s", got, root)
+	}
+	byName := make(map[string]workspaceEntry)
+	for _, item := range got.Items {
+		byName[item.Name] = item
+	}
+	if main := byName["app"]; !main.Main || main.Branch != "main" || main.State != "" {
+		t.Fatalf("main worktree = %+v", main)
+	}
+	if wt := byName["app-feature"]; wt.Path != feature || wt.Branch != "feature" || wt.Kind != "worktree" {
+		t.Fatalf("feature worktree = %+v", wt)
+	}
+	if shell := byName["Shell 1"]; shell.Kind != "shell" || !shell.Live || shell.Session != "sidecar-sh-app-1" || shell.Agent != "copilot" || shell.State != "working" {
+		t.Fatalf("shell = %+v", shell)
+	}
+}
+
+func TestWorkspaceRefsResolveByIdNameBranchAndPath(t *testing.T) {
+	env, out, errOut, root, feature := workspaceProject(t)
+	for _, ref := range []string{root + ":worktree:" + feature, "app-feature", "feature", feature} {
+		out.Reset()
+		if code := runWorkspaceShow(env, []string{"--project", "app", "--json", ref}); code != 0 {
+			t.Fatalf("show %q = %d, stderr %q", ref, code, errOut.String())
+		}
+		var got workspaceEntry
+		if err := json.Unmarshal([]byte(out.String()), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Path != feature {
+			t.Fatalf("show %q = %+v, want the feature worktree", ref, got)
+		}
+	}
+
+	// An id names its project, so it needs no --project.
+	out.Reset()
+	if code := runWorkspaceShow(env, []string{"--json", root + ":shell:sidecar-sh-app-1"}); code != 0 {
+		t.Fatalf("show by id = %d, stderr %q", code, errOut.String())
+	}
+
+	if code := runWorkspaceShow(env, []string{"--project", "app", "nosuch"}); code != 3 {
+		t.Fatalf("show nosuch = %d, want 3", code)
+	}
+}
+
+func TestWorkspaceDeleteAndMergeRefuseTheMainWorktree(t *testing.T) {
+	env, _, errOut, _, _ := workspaceProject(t)
+	if code := runWorkspaceDelete(env, []string{"--project", "app", "--force", "main"}); code != 4 {
+		t.Fatalf("delete main = %d, want 4 (stderr %q)", code, errOut.String())
+	}
+	if code := runWorkspaceMerge(env, []string{"--project", "app", "main"}); code != 4 {
+		t.Fatalf("merge main = %d, want 4 (stderr %q)", code, errOut.String())
+	}
+	if code := runWorkspaceMerge(env, []string{"--project", "app", "Shell 1"}); code != 4 {
+		t.Fatalf("merge shell = %d, want 4 (stderr %q)", code, errOut.String())
+	}
+	if !strings.Contains(errOut.String(), "main worktree") {
+		t.Fatalf("stderr %q, want the refusal", errOut.String())
+	}
+}
@@ -60,6 +60,7 @@ type ProjectConfig struct {
 	LastOpenInApp string               `json:"lastOpenInApp,omitempty"` // last app used to open this project (e.g. "vscode", "goland")
 	OpenIn        string               `json:"openIn,omitempty"`        // preferred "open in" app for this project; last-used is the fallback
 	WorktreeSetup *WorktreeSetupConfig `json:"worktreeSetup,omitempty"` // optional per-project setup policy
+	Commit        *CommitConfig        `json:"commit,omitempty"`        // optional per-project commit template
 }
 
 // WorktreeSetupForProject returns the project override when present, otherwise
@@ -76,6 +77,20 @@ func (c *Config) WorktreeSetupForProject(projectPath string) WorktreeSetupConfig
 	return c.Plugins.Workspace.WorktreeSetup
 }
 
+// CommitForProject returns the project's commit template when it has one,
+// otherwise the Git plugin's default.
+func (c *Config) CommitForProject(projectPath string) CommitConfig {
+	if c == nil {
+		return CommitConfig{}
+	}
+	for _, project := range c.Projects.List {
+		if filepath.Clean(ExpandPath(project.Path)) == filepath.Clean(projectPath) && project.Commit != nil {
+			return *project.Commit
+		}
+	}
+	return c.Plugins.GitStatus.Commit
+}
+
 // PluginsConfig holds per-plugin configuration.
 type PluginsConfig struct {
 	GitStatus     GitStatusPluginConfig     `json:"git-status"`
@@ -119,6 +134,42 @@ type TasksPluginConfig struct {
 type GitStatusPluginConfig struct {
 	Enabled         bool          `json:"enabled"`
 	RefreshInterval time.Duration `json:"refreshInterval"`
+	Commit          CommitConfig  `json:"commit"`
+}
+
+// CommitConfig shapes the commit modal: a conventional-commit header picker,
+// the trailers added to each commit, signing, and the lint rules a message is
+// checked against before it is committed.
+type CommitConfig struct {
+	// Conventional shows type and scope pickers that write the
+	// "type(scope): " prefix of the subject, and lints for that header.
+	Conventional bool     `json:"conventional,omitempty"`
+	Types        []string `json:"types,omitempty"`  // Conventional types; the standard set when empty
+	Scopes       []string `json:"scopes,omitempty"` // Scopes the picker offers
+	// Template prefills the message of a new commit.
+	Template string `json:"template,omitempty"`
+	// NoTrailers stops the Co-authored-by trailer for the worktree's agent and
+	// the Refs trailer for its linked task being added by default.
+	NoTrailers bool `json:"noTrailers,omitempty"`
+	// CoAuthors maps an agent type to the identity its Co-authored-by trailer
+	// names, e.g. {"codex": "Codex <codex@example.com>"}. Agents with no
+	// identity, here or built in, get no trailer.
+	CoAuthors map[string]string `json:"coAuthors,omitempty"`
+	// Sign turns the signing toggle on by default; git's commit.gpgSign does
+	// too.
+	Sign bool             `json:"sign,omitempty"`
+	Lint CommitLintConfig `json:"lint,omitempty"`
+}
+
+// CommitLintConfig selects the commit-msg lint rules.
+type CommitLintConfig struct {
+	Disabled bool `json:"disabled,omitempty"`
+	// SubjectMaxLength defaults to 72 when zero; negative turns the rule off.
+	SubjectMaxLength int `json:"subjectMaxLength,omitempty"`
+	// BodyMaxLineLength defaults to 100 when zero; negative turns it off.
+	BodyMaxLineLength int `json:"bodyMaxLineLength,omitempty"`
+	// RequireScope flags conventional headers without a scope.
+	RequireScope bool `json:"requireScope,omitempty"`
 }
 
 // FileBrowserPluginConfig configures the file browser plugin.
@@ -74,11 +74,21 @@ type rawConfig struct {
 }
 
 type rawNotificationsConfig struct {
-	Sources map[string]rawNotificationSourceConfig `json:"sources"`
+	Sources      map[string]rawNotificationSourceConfig `json:"sources"`
+	Rules        []NotificationRuleConfig               `json:"rules"`
+	QuietHours   []QuietHoursConfig                     `json:"quietHours"`
+	DoNotDisturb bool                                   `json:"doNotDisturb"`
+	Sinks        []NotificationSinkConfig               `json:"sinks"`
 }
 
 type rawNotificationSourceConfig struct {
-	Expiry string `json:"expiry"`
+	Expiry   string `json:"expiry"`
+	Toast    *bool  `json:"toast"`
+	Bell     bool   `json:"bell"`
+	Label    string `json:"label"`
+	Glyph    string `json:"glyph"`
+	Hue      string `json:"hue"`
+	Priority int    `json:"priority"`
 }
 
 type rawSelectionConfig struct {
@@ -126,6 +136,7 @@ type rawProjectConfig struct {
 	LastOpenInApp string               `json:"lastOpenInApp,omitempty"`
 	OpenIn        string               `json:"openIn,omitempty"`
 	WorktreeSetup *WorktreeSetupConfig `json:"worktreeSetup,omitempty"`
+	Commit        *CommitConfig        `json:"commit,omitempty"`
 }
 
 type rawPluginsConfig struct {
@@ -181,8 +192,9 @@ type rawSidebarDisplayConfig struct {
 }
 
 type rawGitStatusConfig struct {
-	Enabled         *bool  `json:"enabled"`
-	RefreshInterval string `json:"refreshInterval"`
+	Enabled         *bool         `json:"enabled"`
+	RefreshInterval string        `json:"refreshInterval"`
+	Commit          *CommitConfig `json:"commit"`
 }
 
 type rawTDMonitorConfig struct {
@@ -292,6 +304,9 @@ func mergeConfig(cfg *Config, raw *rawConfig) {
 			cfg.Plugins.GitStatus.RefreshInterval = d
 		}
 	}
+	if raw.Plugins.GitStatus.Commit != nil {
+		cfg.Plugins.GitStatus.Commit = *raw.Plugins.GitStatus.Commit
+	}
 
 	// TD Monitor
 	if raw.Plugins.TDMonitor.Enabled != nil {
@@ -492,6 +507,12 @@ func mergeConfig(cfg *Config, raw *rawConfig) {
 			cfg.Notifications.Sources[id] = NotificationSourceConfig(src)
 		}
 	}
+	if raw.Notifications != nil {
+		cfg.Notifications.Rules = raw.Notifications.Rules
+		cfg.Notifications.QuietHours = raw.Notifications.QuietHours
+		cfg.Notifications.DoNotDisturb = raw.Notifications.DoNotDisturb
+		cfg.Notifications.Sinks = raw.Notifications.Sinks
+	}
 
 	// Features
 	if raw.Features.Flags != nil {
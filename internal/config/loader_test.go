@@ -668,3 +668,26 @@ func TestLoadFrom_NotesDefaultEditorNormalizesCurrentLegacyAndUnknownValues(t *t
 		})
 	}
 }
+
+func TestLoadFrom_CommitConfigProjectOverride(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "repo")
+	path := filepath.Join(root, "config.json")
+	data := `{
+  "projects": {"list": [{"name":"repo","path":"` + project + `","commit":{"conventional":true,"scopes":["api","ui"],"lint":{"requireScope":true}}}]},
+  "plugins": {"git-status": {"commit":{"template":"WIP: ","coAuthors":{"codex":"Codex <codex@example.com>"}}}}
+}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("LoadFrom failed: %v", err)
+	}
+	if got := cfg.CommitForProject(project); !got.Conventional || len(got.Scopes) != 2 || !got.Lint.RequireScope || got.Template != "" {
+		t.Fatalf("project commit config = %+v", got)
+	}
+	if got := cfg.CommitForProject(filepath.Join(root, "other")); got.Conventional || got.Template != "WIP: " || got.CoAuthors["codex"] == "" {
+		t.Fatalf("global commit config = %+v", got)
+	}
+}
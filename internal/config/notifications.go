@@ -8,35 +8,156 @@ import (
 
 // NotificationsConfig is the app-level `notifications` section.
 //
-// Phase 1.5 populates only the per-source toast expiry: the defaults live in
-// internal/notify's source registry, and this is how a user lengthens or
-// shortens one without a rebuild. There is deliberately no configui page yet —
-// Phase 4 renders the full per-source table (toast / centre / bell / expiry)
-// over this same struct — but the values are user-editable in config.json from
-// day one, which is the point.
+// For a built-in source an entry overrides its toast expiry and whether it
+// toasts at all: the defaults live in internal/notify's source registry, and
+// this is how a user changes one without a rebuild. An entry with a label under
+// any other id registers a source of its own — a CI script, a test watcher, a
+// deploy hook — with its own section in the notification centre.
+//
+// Rules route individual notifications by what they say rather than where they
+// come from, quiet hours and do-not-disturb hold back everything that would
+// interrupt, and all of it is shown on the Notifications configuration page.
+// `sidecar notify source add` writes the same source entries.
 //
 // Example:
 //
 //	"notifications": {
 //	  "sources": {
 //	    "agent":   { "expiry": "20s" },
-//	    "session": { "expiry": "sticky" }
-//	  }
+//	    "session": { "expiry": "sticky" },
+//	    "deploy":  { "label": "DEPLOYS", "glyph": "▲", "hue": "warning", "priority": 35 },
+//	    "tests":   { "label": "TESTS", "toast": false },
+//	    "ci":      { "bell": true }
+//	  },
+//	  "rules": [
+//	    { "source": ["tests"], "severity": ["error"], "route": "toast" },
+//	    { "match": "(?i)flaky", "route": "suppress" },
+//	    { "source": ["waiting"], "escalateAfter": "5m" }
+//	  ],
+//	  "quietHours": [
+//	    { "days": ["mon", "tue", "wed", "thu", "fri"], "from": "22:00", "to": "07:00" }
+//	  ],
+//	  "sinks": [
+//	    { "kind": "desktop" },
+//	    { "kind": "webhook", "url": "https://ntfy.sh/my-agents", "format": "ntfy",
+//	      "filter": [{ "source": ["waiting", "ci"] }], "duringQuiet": true }
+//	  ]
 //	}
 type NotificationsConfig struct {
 	// Sources is keyed by notification source id (`agent`, `waiting`,
-	// `session`, `tasks`, `td`, `system`). An unknown key is kept rather than
-	// dropped: internal/notify decides what a source id means, and a config
-	// written by a newer build must survive a round trip through an older one.
+	// `ci`, `session`, `tasks`, `td`, `system`, or a custom one). An unknown
+	// key is kept rather than dropped: internal/notify decides what a source
+	// id means, and a config written by a newer build must survive a round
+	// trip through an older one.
 	Sources map[string]NotificationSourceConfig `json:"sources,omitempty"`
+	// Rules are tried in order and the first that matches a notification
+	// decides its route; a notification no rule matches takes its source's.
+	Rules []NotificationRuleConfig `json:"rules,omitempty"`
+	// QuietHours are the local-time windows in which nothing toasts or rings.
+	QuietHours []QuietHoursConfig `json:"quietHours,omitempty"`
+	// DoNotDisturb holds back toasts and bells until it is turned off again.
+	DoNotDisturb bool `json:"doNotDisturb,omitempty"`
+	// Sinks deliver notifications beyond the Sidecar terminal: to the
+	// desktop, through the terminal emulator, or to a webhook.
+	Sinks []NotificationSinkConfig `json:"sinks,omitempty"`
+}
+
+// Empty reports whether the section says nothing, so Save can leave it out.
+func (c NotificationsConfig) Empty() bool {
+	return len(c.Sources) == 0 && len(c.Rules) == 0 && len(c.QuietHours) == 0 && !c.DoNotDisturb && len(c.Sinks) == 0
 }
 
-// NotificationSourceConfig is the per-source overrides.
+// NotificationSourceConfig is the per-source overrides, and for a custom source
+// its whole definition.
 type NotificationSourceConfig struct {
 	// Expiry is how long a toast from this source stays on screen: any Go
 	// duration string ("12s", "1m30s"), or "sticky" / "0" for a toast with no
 	// countdown that waits for the user.
 	Expiry string `json:"expiry,omitempty"`
+	// Toast false files the source's notifications into the centre without
+	// putting them on screen. Unset means the source toasts.
+	Toast *bool `json:"toast,omitempty"`
+	// Bell rings the terminal bell as well as toasting.
+	Bell bool `json:"bell,omitempty"`
+
+	// The rest defines a custom source and is ignored for a built-in one.
+	// Label names its section in the notification centre; an entry without one
+	// registers nothing.
+	Label string `json:"label,omitempty"`
+	// Glyph marks its notifications; one cell wide.
+	Glyph string `json:"glyph,omitempty"`
+	// Hue is a theme palette name: primary, secondary, accent, success,
+	// warning, error, info or muted.
+	Hue string `json:"hue,omitempty"`
+	// Priority breaks ties between equally severe notifications; the built-in
+	// sources run from 10 (system) to 60 (waiting).
+	Priority int `json:"priority,omitempty"`
+}
+
+// NotificationRuleConfig routes the notifications it matches. Every field it
+// sets must match; a rule that sets none matches everything. What each field
+// means is internal/notify's to decide (see notify.Rule).
+type NotificationRuleConfig struct {
+	// Source and Severity match any of the listed ids.
+	Source   []string `json:"source,omitempty"`
+	Severity []string `json:"severity,omitempty"`
+	// Project is a glob over the posting project's path or directory name.
+	Project string `json:"project,omitempty"`
+	// Provider is the agent kind that raised the notification ("claude").
+	Provider string `json:"provider,omitempty"`
+	// Match is a regular expression over the title and body.
+	Match string `json:"match,omitempty"`
+	// Route is toast, bell, centre or suppress. Empty keeps the source's
+	// route, for a rule that only escalates.
+	Route string `json:"route,omitempty"`
+	// EscalateAfter toasts a matched notification that is still unread this
+	// long after it was posted, whatever its route, as a Go duration.
+	EscalateAfter string `json:"escalateAfter,omitempty"`
+}
+
+// NotificationSinkConfig is one delivery sink. A sink delivers what would
+// toast: nothing suppressed, nothing routed to the centre only, and nothing
+// during quiet hours or do-not-disturb unless DuringQuiet says otherwise.
+type NotificationSinkConfig struct {
+	// Kind is desktop (notify-send), osc (a terminal escape the emulator
+	// turns into a system notification) or webhook.
+	Kind string `json:"kind"`
+	// Name identifies the sink in logs; it defaults to the kind.
+	Name string `json:"name,omitempty"`
+
+	// Command replaces notify-send for a desktop sink. It is run with the
+	// same arguments, so a wrapper script can stand in on other platforms.
+	Command string `json:"command,omitempty"`
+	// OSC is 9 (iTerm2, WezTerm, Windows Terminal) or 777 (urxvt, foot,
+	// Ghostty); 9 when unset.
+	OSC int `json:"osc,omitempty"`
+	// URL and Format are a webhook's endpoint and payload: json (the
+	// notification itself), ntfy or slack.
+	URL     string            `json:"url,omitempty"`
+	Format  string            `json:"format,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	// Retries is how many times a failed webhook delivery is tried again,
+	// with a doubling pause between tries; 2 when unset, -1 for none.
+	Retries int `json:"retries,omitempty"`
+
+	// Filter narrows the sink to the notifications one of these matches. Its
+	// entries are rule conditions without a route.
+	Filter []NotificationRuleConfig `json:"filter,omitempty"`
+	// PerMinute caps deliveries; the excess is dropped rather than queued. 10
+	// when unset.
+	PerMinute int `json:"perMinute,omitempty"`
+	// DuringQuiet delivers through quiet hours and do-not-disturb, for a sink
+	// that reaches the user somewhere else.
+	DuringQuiet bool `json:"duringQuiet,omitempty"`
+}
+
+// QuietHoursConfig is one quiet window, in local time. From after To wraps
+// past midnight; Days names the day a window starts on, and an empty list
+// means every day.
+type QuietHoursConfig struct {
+	Days []string `json:"days,omitempty"`
+	From string   `json:"from"`
+	To   string   `json:"to"`
 }
 
 // StickyExpiry is the sentinel a zero duration carries: a source whose toasts
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -43,3 +44,40 @@ func TestAbsentNotificationsSectionResolvesToNoOverrides(t *testing.T) {
 		t.Fatalf("default config carries overrides: %v", got)
 	}
 }
+
+func TestNotificationRulesAndQuietHoursSurviveASave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	SetTestConfigPath(path)
+	defer ResetTestConfigPath()
+
+	if err := SaveNotifications(func(n *NotificationsConfig) {
+		n.Rules = []NotificationRuleConfig{{Source: []string{"ci"}, Severity: []string{"error"}, Route: "bell", EscalateAfter: "10m"}}
+		n.QuietHours = []QuietHoursConfig{{Days: []string{"sat", "sun"}, From: "00:00", To: "00:00"}}
+		n.DoNotDisturb = true
+	}); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadFrom(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := cfg.Notifications
+	if len(got.Rules) != 1 || got.Rules[0].Route != "bell" || got.Rules[0].EscalateAfter != "10m" || got.Rules[0].Severity[0] != "error" {
+		t.Fatalf("rules = %+v", got.Rules)
+	}
+	if len(got.QuietHours) != 1 || got.QuietHours[0].Days[1] != "sun" || !got.DoNotDisturb {
+		t.Fatalf("quiet = %+v, dnd = %v", got.QuietHours, got.DoNotDisturb)
+	}
+
+	// Turning everything off again removes the section.
+	if err := SaveNotifications(func(n *NotificationsConfig) { *n = NotificationsConfig{} }); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "notifications") {
+		t.Fatalf("an empty section was written:\n%s", data)
+	}
+}
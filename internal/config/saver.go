@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"reflect"
 )
 
 // saveConfig is the JSON-marshaling intermediary that uses string durations.
@@ -66,8 +67,9 @@ type saveFileBrowserConfig struct {
 }
 
 type saveGitStatusConfig struct {
-	Enabled         *bool  `json:"enabled,omitempty"`
-	RefreshInterval string `json:"refreshInterval,omitempty"`
+	Enabled         *bool         `json:"enabled,omitempty"`
+	RefreshInterval string        `json:"refreshInterval,omitempty"`
+	Commit          *CommitConfig `json:"commit,omitempty"`
 }
 
 type saveTDMonitorConfig struct {
@@ -111,6 +113,7 @@ func toSaveConfig(cfg *Config) saveConfig {
 			GitStatus: saveGitStatusConfig{
 				Enabled:         &cfg.Plugins.GitStatus.Enabled,
 				RefreshInterval: cfg.Plugins.GitStatus.RefreshInterval.String(),
+				Commit:          saveCommitConfig(cfg.Plugins.GitStatus.Commit),
 			},
 			TDMonitor: saveTDMonitorConfig{
 				Enabled:         &cfg.Plugins.TDMonitor.Enabled,
@@ -220,6 +223,13 @@ func Save(cfg *Config) error {
 	} else {
 		delete(raw, "terminalResources")
 	}
+	// notifications follows the same rule: a source removed with `sidecar
+	// notify source remove` must not come back from the old section.
+	if !cfg.Notifications.Empty() {
+		fields["notifications"] = cfg.Notifications
+	} else {
+		delete(raw, "notifications")
+	}
 	for key, val := range fields {
 		b, err := json.Marshal(val)
 		if err != nil {
@@ -376,3 +386,23 @@ func SavePlugins(mutate func(*PluginsConfig)) error {
 	}
 	return Save(cfg)
 }
+
+// SaveNotifications applies a change to the notifications section and writes
+// it. Like the other helpers it reloads first, so registering a source from the
+// CLI never overwrites an edit made to the file since it was read.
+func SaveNotifications(mutate func(*NotificationsConfig)) error {
+	cfg, err := Load()
+	if err != nil {
+		return err
+	}
+	mutate(&cfg.Notifications)
+	return Save(cfg)
+}
+
+// saveCommitConfig omits a commit template that is all defaults.
+func saveCommitConfig(c CommitConfig) *CommitConfig {
+	if reflect.ValueOf(c).IsZero() {
+		return nil
+	}
+	return &c
+}
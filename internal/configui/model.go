@@ -1173,6 +1173,8 @@ func (m *Model) buildDetail(originX, inner, paneHeight int) ([]string, bool) {
 		m.buildAgents(builder)
 	case route.Page == PageTerminal:
 		m.buildTerminal(builder)
+	case route.Page == PageNotifications:
+		m.buildNotifications(builder)
 	case route.Page == PagePanels:
 		m.buildPanels(builder)
 	case route.Page == PageAdvanced:
@@ -0,0 +1,67 @@
+package configui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/notify"
+)
+
+func TestNotificationsPageRendersSourcesQuietAndRules(t *testing.T) {
+	m := workspaceFixture(t, func(cfg *config.Config) {
+		cfg.Notifications.QuietHours = []config.QuietHoursConfig{{Days: []string{"sat", "sun"}, From: "22:00", To: "09:00"}}
+		cfg.Notifications.Rules = []config.NotificationRuleConfig{
+			{Source: []string{"ci"}, Severity: []string{"error"}, Route: "bell", EscalateAfter: "10m"},
+			{Match: "(", Route: "suppress"},
+		}
+		cfg.Notifications.Sinks = []config.NotificationSinkConfig{
+			{Kind: "desktop"},
+			{Kind: "webhook", URL: "https://ntfy.sh/secret-topic", Format: "ntfy", DuringQuiet: true},
+			{Kind: "pager"},
+		}
+	})
+	m.Open(PageNotifications)
+	view := ansi.Strip(m.View(160, 60))
+
+	for _, want := range []string{
+		"Sources", "? WAITING", "Toast · sticky", "◆ AGENTS", "Toast · 12s",
+		"Quiet", "Do not disturb", "Sat, Sun 22:00–09:00",
+		"Rules", "1. source ci, severity error → bell, escalate after 10m",
+		"2. matching /(/ → suppress — ignored",
+		"Delivery", "Desktop via notify-send", "Webhook to ntfy.sh (ntfy), also when quiet",
+		"pager — ignored",
+		"still lands in the centre",
+	} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("Notifications is missing %q:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "secret-topic") {
+		t.Fatal("a webhook's path was shown")
+	}
+}
+
+func TestNotificationsPageSavesRoutesAndDoNotDisturb(t *testing.T) {
+	t.Cleanup(func() { notify.ApplyConfig(config.NotificationsConfig{}) })
+	m := workspaceFixture(t, nil)
+	m.Open(PageNotifications)
+
+	activate(t, m, regionNotifyDND)
+	if !loadSaved(t).Notifications.DoNotDisturb {
+		t.Fatal("do not disturb was not saved")
+	}
+
+	if msg := saveSourceRoute(notify.SourceCI, "CI")(m, dropdownOption{id: string(notify.RouteBell), label: "Toast + bell"})(); msg.(ConfigSavedMsg).Err != "" {
+		t.Fatal(msg)
+	}
+	if !loadSaved(t).Notifications.Sources["ci"].Bell {
+		t.Fatal("the bell route was not saved")
+	}
+	// Back to the default leaves nothing behind for the source.
+	saveSourceRoute(notify.SourceCI, "CI")(m, dropdownOption{id: string(notify.RouteToast), label: "Toast"})()
+	if _, ok := loadSaved(t).Notifications.Sources["ci"]; ok {
+		t.Fatal("a default route left an entry behind")
+	}
+}
@@ -0,0 +1,231 @@
+package configui
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/notify"
+	"github.com/marcus/sidecar/internal/notifysink"
+)
+
+// Notifications is what may interrupt the user, and when. Each source has a
+// route — toast, toast and ring the bell, or wait in the centre — and the
+// quiet block holds all of them back for a while. Rules and quiet-hours
+// schedules are read back here but written in config.json: a rule is a
+// small program, and a form that could say everything a rule says would be
+// harder to read than the JSON. Whatever is held back still lands in the
+// centre and counts in the corner.
+
+const (
+	regionNotifySource = "config-notifications-source-"
+	regionNotifyDND    = "config-notifications-dnd"
+
+	notifyControlWidth = 28
+)
+
+// sourceRouteOptions are the routes a whole source can take. Suppress is a
+// rule's to decide about particular notifications, never a whole source's:
+// a source nobody hears from belongs out of config, not filed unseen.
+func sourceRouteOptions() []dropdownOption {
+	return []dropdownOption{
+		{id: string(notify.RouteToast), label: "Toast"},
+		{id: string(notify.RouteBell), label: "Toast + bell", desc: "rings the terminal bell"},
+		{id: string(notify.RouteCentre), label: "Centre only", desc: "waits unread in the centre"},
+	}
+}
+
+// sourceExpiry is how the page states a source's toast lifetime.
+func sourceExpiry(id notify.SourceID) string {
+	if d := notify.ExpiryFor(id); d > 0 {
+		return d.String()
+	}
+	return "sticky"
+}
+
+// saveSourceRoute writes a source's route into its entry. Toast is the
+// default and clears both keys; an entry left with nothing in it is removed,
+// so the section only ever says what the user changed.
+func saveSourceRoute(id notify.SourceID, label string) func(*Model, dropdownOption) tea.Cmd {
+	return func(_ *Model, opt dropdownOption) tea.Cmd {
+		return SaveCmd(label+": "+opt.label, func() error {
+			return config.SaveNotifications(func(n *config.NotificationsConfig) {
+				if n.Sources == nil {
+					n.Sources = map[string]config.NotificationSourceConfig{}
+				}
+				entry := n.Sources[string(id)]
+				entry.Toast, entry.Bell = nil, false
+				switch notify.Route(opt.id) {
+				case notify.RouteBell:
+					entry.Bell = true
+				case notify.RouteCentre:
+					off := false
+					entry.Toast = &off
+				}
+				if entry == (config.NotificationSourceConfig{}) {
+					delete(n.Sources, string(id))
+					return
+				}
+				n.Sources[string(id)] = entry
+			})
+		})
+	}
+}
+
+// describeQuietHours is one window as a person would say it.
+func describeQuietHours(c config.QuietHoursConfig) string {
+	days := "Every day"
+	if len(c.Days) > 0 {
+		names := make([]string, len(c.Days))
+		for i, d := range c.Days {
+			d = strings.ToLower(strings.TrimSpace(d))
+			if d != "" {
+				d = strings.ToUpper(d[:1]) + d[1:]
+			}
+			names[i] = d
+		}
+		days = strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s %s–%s", days, c.From, c.To)
+}
+
+// describeRule is one rule as a line: its conditions, then what it does.
+func describeRule(c config.NotificationRuleConfig) string {
+	var when []string
+	if len(c.Source) > 0 {
+		when = append(when, "source "+strings.Join(c.Source, "/"))
+	}
+	if len(c.Severity) > 0 {
+		when = append(when, "severity "+strings.Join(c.Severity, "/"))
+	}
+	if c.Project != "" {
+		when = append(when, "project "+c.Project)
+	}
+	if c.Provider != "" {
+		when = append(when, "provider "+c.Provider)
+	}
+	if c.Match != "" {
+		when = append(when, "matching /"+c.Match+"/")
+	}
+	if len(when) == 0 {
+		when = append(when, "everything")
+	}
+	var then []string
+	if c.Route != "" {
+		then = append(then, c.Route)
+	}
+	if c.EscalateAfter != "" {
+		then = append(then, "escalate after "+c.EscalateAfter)
+	}
+	return strings.Join(when, ", ") + " → " + strings.Join(then, ", ")
+}
+
+// describeSink is one delivery sink as a line. A webhook shows its host and
+// never its path, which is where a token usually lives.
+func describeSink(c config.NotificationSinkConfig) string {
+	kind := strings.ToLower(strings.TrimSpace(c.Kind))
+	var line string
+	switch kind {
+	case "desktop":
+		command := c.Command
+		if command == "" {
+			command = notifysink.DefaultDesktopCommand
+		}
+		line = "Desktop via " + command
+	case "osc":
+		code := c.OSC
+		if code == 0 {
+			code = 9
+		}
+		line = fmt.Sprintf("Terminal emulator, OSC %d", code)
+	case "webhook":
+		host := c.URL
+		if u, err := url.Parse(c.URL); err == nil && u.Host != "" {
+			host = u.Host
+		}
+		format := c.Format
+		if format == "" {
+			format = notifysink.FormatJSON
+		}
+		line = "Webhook to " + host + " (" + format + ")"
+	default:
+		line = c.Kind
+	}
+	if c.Name != "" {
+		line = c.Name + ": " + line
+	}
+	if len(c.Filter) > 0 {
+		line += fmt.Sprintf(", %d filter", len(c.Filter))
+		if len(c.Filter) > 1 {
+			line += "s"
+		}
+	}
+	if c.DuringQuiet {
+		line += ", also when quiet"
+	}
+	return line
+}
+
+func (m *Model) buildNotifications(b *paneBuilder) {
+	cfg := m.Config().Notifications
+
+	b.text(PaneTitle(PageTitle(PageNotifications)), "")
+	b.lead("Choose what interrupts you, and when.")
+
+	b.text(SectionHeader("Sources"))
+	for _, src := range notify.Sources() {
+		label := src.Glyph + " " + src.Label
+		current := dropdownLabel(sourceRouteOptions(), string(src.Route())) + " · " + sourceExpiry(src.ID)
+		b.selectRowValue(regionNotifySource+string(src.ID), label, current, notifyControlWidth,
+			sourceRouteOptions(), string(src.Route()), saveSourceRoute(src.ID, src.Label))
+	}
+	b.note("Expiries are set per source in config.json; `sidecar notify source add` registers a source of your own.")
+
+	b.text(SectionHeader("Quiet"))
+	b.toggleRow(regionNotifyDND, "Do not disturb", cfg.DoNotDisturb, func(m *Model) tea.Cmd {
+		enabled := !m.Config().Notifications.DoNotDisturb
+		return SaveCmd(toggleNotice("Do not disturb", enabled), func() error {
+			return config.SaveNotifications(func(n *config.NotificationsConfig) { n.DoNotDisturb = enabled })
+		})
+	})
+	b.help("Holds back toasts and bells until it is off again. z in the notification centre toggles it too.")
+	if len(cfg.QuietHours) == 0 {
+		b.note("No quiet hours. Add windows under notifications.quietHours in config.json.")
+	}
+	for _, w := range cfg.QuietHours {
+		line := describeQuietHours(w)
+		if _, err := notify.ParseQuietWindow(w); err != nil {
+			line = Warning(line + " — ignored: " + err.Error())
+		}
+		b.note(line)
+	}
+
+	b.text(SectionHeader("Rules"))
+	if len(cfg.Rules) == 0 {
+		b.note("No rules: every notification takes its source's route.")
+	}
+	for i, r := range cfg.Rules {
+		line := fmt.Sprintf("%d. %s", i+1, describeRule(r))
+		if _, err := notify.ParseRule(r); err != nil {
+			line = Warning(line + " — ignored: " + err.Error())
+		}
+		b.note(line)
+	}
+
+	b.text(SectionHeader("Delivery"))
+	if len(cfg.Sinks) == 0 {
+		b.note("Only this terminal. Add desktop, osc or webhook sinks under notifications.sinks in config.json.")
+	}
+	for _, sink := range cfg.Sinks {
+		line := describeSink(sink)
+		if _, errs := notifysink.New(config.NotificationsConfig{Sinks: []config.NotificationSinkConfig{sink}}); len(errs) > 0 {
+			line = Warning(line + " — ignored: " + errs[0].Error())
+		}
+		b.note(line)
+	}
+
+	b.blank()
+	b.lead("Rules are written under notifications.rules in config.json and tried in order; the first that matches decides. Whatever is held back still lands in the centre and counts in the corner.")
+}
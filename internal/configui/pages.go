@@ -11,16 +11,17 @@ package configui
 type PageID string
 
 const (
-	PageSetup       PageID = "setup"
-	PageAppearance  PageID = "appearance"
-	PageProjects    PageID = "projects"
-	PageWorkspaces  PageID = "workspaces"
-	PageAgents      PageID = "agents"
-	PageTerminal    PageID = "terminal"
-	PagePanels      PageID = "panels"
-	PageDiagnostics PageID = "diagnostics"
-	PageAdvanced    PageID = "advanced"
-	PageAbout       PageID = "about"
+	PageSetup         PageID = "setup"
+	PageAppearance    PageID = "appearance"
+	PageProjects      PageID = "projects"
+	PageWorkspaces    PageID = "workspaces"
+	PageAgents        PageID = "agents"
+	PageTerminal      PageID = "terminal"
+	PageNotifications PageID = "notifications"
+	PagePanels        PageID = "panels"
+	PageDiagnostics   PageID = "diagnostics"
+	PageAdvanced      PageID = "advanced"
+	PageAbout         PageID = "about"
 )
 
 // DefaultPage is where the gear, the palette command, and `sidecar setup` all
@@ -52,6 +53,7 @@ var groups = []Group{
 			{ID: PageWorkspaces, Title: "Workspaces"},
 			{ID: PageAgents, Title: "Agents"},
 			{ID: PageTerminal, Title: "Terminal"},
+			{ID: PageNotifications, Title: "Notifications"},
 			{ID: PagePanels, Title: "Panels & Integrations"},
 		},
 	},
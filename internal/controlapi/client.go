@@ -0,0 +1,182 @@
+package controlapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+)
+
+// ErrClosed is returned by calls on a client whose connection has gone.
+var ErrClosed = errors.New("control API connection closed")
+
+// Client is a connection to a running instance's API. It is what `sidecar`
+// subcommands and tests use; other clients only need a socket and a JSON
+// encoder.
+type Client struct {
+	nc net.Conn
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  int64
+	pending map[string]chan Response
+	err     error
+
+	events chan Event
+	done   chan struct{}
+}
+
+// Dial connects to the socket at path.
+func Dial(ctx context.Context, path string) (*Client, error) {
+	var d net.Dialer
+	nc, err := d.DialContext(ctx, "unix", path)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{
+		nc:      nc,
+		pending: make(map[string]chan Response),
+		events:  make(chan Event, outboxSize),
+		done:    make(chan struct{}),
+	}
+	go c.readLoop()
+	return c, nil
+}
+
+// Events is every event delivered to this connection's subscriptions. It is
+// closed when the connection is. A client that does not drain it loses events
+// once it is full; since each event is a whole snapshot, the next one to
+// arrive is still correct.
+func (c *Client) Events() <-chan Event { return c.events }
+
+// Close disconnects.
+func (c *Client) Close() error {
+	err := c.nc.Close()
+	<-c.done
+	return err
+}
+
+// Call invokes method and decodes its result into result, which may be nil.
+// A JSON-RPC error comes back as an *Error.
+func (c *Client) Call(ctx context.Context, method string, params, result any) error {
+	req := Request{JSONRPC: Version, Method: method}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		req.Params = data
+	}
+
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return c.err
+	}
+	c.nextID++
+	id := strconv.FormatInt(c.nextID, 10)
+	ch := make(chan Response, 1)
+	c.pending[id] = ch
+	c.mu.Unlock()
+	req.ID = json.RawMessage(id)
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		c.forget(id)
+		return err
+	}
+	c.writeMu.Lock()
+	_, err = c.nc.Write(append(data, '\n'))
+	c.writeMu.Unlock()
+	if err != nil {
+		c.forget(id)
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		c.forget(id)
+		return ctx.Err()
+	case resp, ok := <-ch:
+		if !ok {
+			return ErrClosed
+		}
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result == nil || len(resp.Result) == 0 {
+			return nil
+		}
+		return json.Unmarshal(resp.Result, result)
+	}
+}
+
+// Subscribe subscribes to topics, all of them when none are given, and
+// returns the subscription id its events will carry.
+func (c *Client) Subscribe(ctx context.Context, topics ...Topic) (Subscription, error) {
+	params := SubscribeParams{}
+	for _, topic := range topics {
+		params.Topics = append(params.Topics, string(topic))
+	}
+	var sub Subscription
+	err := c.Call(ctx, "subscribe", params, &sub)
+	return sub, err
+}
+
+func (c *Client) forget(id string) {
+	c.mu.Lock()
+	delete(c.pending, id)
+	c.mu.Unlock()
+}
+
+// message is anything the server sends: a response has an id, an event has
+// a method.
+type message struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *Error          `json:"error"`
+}
+
+func (c *Client) readLoop() {
+	defer close(c.done)
+	scanner := bufio.NewScanner(c.nc)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*maxLine)
+	for scanner.Scan() {
+		var msg message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			continue
+		}
+		if msg.Method == EventMethod {
+			var ev Event
+			if json.Unmarshal(msg.Params, &ev) == nil {
+				select {
+				case c.events <- ev:
+				default:
+				}
+			}
+			continue
+		}
+		c.mu.Lock()
+		ch := c.pending[string(msg.ID)]
+		delete(c.pending, string(msg.ID))
+		c.mu.Unlock()
+		if ch != nil {
+			ch <- Response{JSONRPC: Version, ID: msg.ID, Result: msg.Result, Error: msg.Error}
+		}
+	}
+
+	c.mu.Lock()
+	c.err = ErrClosed
+	for id, ch := range c.pending {
+		close(ch)
+		delete(c.pending, id)
+	}
+	c.mu.Unlock()
+	close(c.events)
+}
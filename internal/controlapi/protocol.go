@@ -0,0 +1,123 @@
+// Package controlapi is the local control API: JSON-RPC 2.0 over a Unix
+// domain socket, served by a running Sidecar instance when the control_api
+// feature is on.
+//
+// It is the request bus without the files. An editor, a status bar or a
+// dashboard connects once, calls the same actions `sidecar open`, `sidecar
+// notify` and `sidecar create` perform, and subscribes to state instead of
+// polling for it. The package is transport only: it frames messages, routes
+// methods to handlers the host registers, and fans published snapshots out to
+// subscribers. What a method does and what a topic holds belong to the host.
+//
+// The wire format is one JSON object per line in each direction. The protocol
+// is documented in docs/reference/control-api.md.
+package controlapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Version is the JSON-RPC version every message carries.
+const Version = "2.0"
+
+// Request is a call from a client. A request without an id is a JSON-RPC
+// notification: it is run, and nothing is written back.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response answers a Request with an id. Exactly one of Result and Error is
+// set.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error is a JSON-RPC error object. Handlers return one to choose the code a
+// client sees; any other error is reported as CodeInternalError.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string { return fmt.Sprintf("%s (%d)", e.Message, e.Code) }
+
+// The JSON-RPC 2.0 codes, and the one server code this API adds.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	// CodeUnavailable is a well-formed call the instance cannot serve right
+	// now: no project open, or the request bus is not running.
+	CodeUnavailable = -32000
+)
+
+// InvalidParams is the error for params a handler cannot use.
+func InvalidParams(format string, args ...any) *Error {
+	return &Error{Code: CodeInvalidParams, Message: fmt.Sprintf(format, args...)}
+}
+
+// Unavailable is the error for a call the instance cannot serve right now.
+func Unavailable(format string, args ...any) *Error {
+	return &Error{Code: CodeUnavailable, Message: fmt.Sprintf(format, args...)}
+}
+
+// EventMethod is the method of every server-to-client notification.
+const EventMethod = "event"
+
+// Event is one published snapshot delivered to a subscription. Every event
+// carries the whole current value of its topic, never a delta, so a client
+// that only wants "now" reads the latest event and ignores the rest.
+type Event struct {
+	Subscription string          `json:"subscription"`
+	Topic        Topic           `json:"topic"`
+	Data         json.RawMessage `json:"data"`
+}
+
+// Topic names a stream of state a client can subscribe to.
+type Topic string
+
+const (
+	// TopicWorkspaces is the project's shells and worktrees.
+	TopicWorkspaces Topic = "workspaces"
+	// TopicAgents is the agent running in each workspace and its lane; it
+	// changes exactly when some agent's status does.
+	TopicAgents Topic = "agents"
+	// TopicNotifications is the notification centre: everything not
+	// dismissed, and which of it is unread.
+	TopicNotifications Topic = "notifications"
+	// TopicGit is the working tree's git status.
+	TopicGit Topic = "git"
+	// TopicPanes is the content panes open beside each surface.
+	TopicPanes Topic = "panes"
+)
+
+// Topics lists every topic, in the order describe reports them.
+func Topics() []Topic {
+	return []Topic{TopicWorkspaces, TopicAgents, TopicNotifications, TopicGit, TopicPanes}
+}
+
+// ParseTopic reads a topic name as a client spells it.
+func ParseTopic(s string) (Topic, error) {
+	topic := Topic(strings.ToLower(strings.TrimSpace(s)))
+	for _, t := range Topics() {
+		if t == topic {
+			return t, nil
+		}
+	}
+	names := make([]string, 0, len(Topics()))
+	for _, t := range Topics() {
+		names = append(names, string(t))
+	}
+	return "", fmt.Errorf("unknown topic %q (one of: %s)", s, strings.Join(names, ", "))
+}
@@ -0,0 +1,569 @@
+package controlapi
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// maxLine bounds one request. Requests are small; this only keeps a client that
+// never sends a newline from growing the buffer without limit.
+const maxLine = 1 << 20
+
+// outboxSize is how many messages a connection may have queued before the
+// server gives up on it. Events are whole snapshots, so a client that falls
+// this far behind has nothing worth catching up on; it is disconnected rather
+// than allowed to stall publishing for everyone else.
+const outboxSize = 256
+
+// Handler runs one method. params is the raw "params" member, nil when the
+// client sent none. The returned value is marshalled as the result; return an
+// *Error to choose the error code.
+//
+// Handlers run on their own goroutine per call, and ctx is cancelled when the
+// connection closes.
+type Handler func(ctx context.Context, params json.RawMessage) (any, error)
+
+// SocketPath is where the instance with pid serves the API under stateDir.
+func SocketPath(stateDir string, pid int) string {
+	return filepath.Join(stateDir, "api", strconv.Itoa(pid)+".sock")
+}
+
+// Server serves the API on one Unix socket.
+type Server struct {
+	ln   net.Listener
+	path string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu        sync.Mutex
+	methods   map[string]Handler
+	snapshots map[Topic]json.RawMessage
+	conns     map[*conn]struct{}
+	closed    bool
+}
+
+// Listen creates the socket at path and returns a server that is not yet
+// serving. The directory is created owner-only and the socket is made 0600:
+// anyone who can connect can drive the instance, the same trust the request
+// bus places in whoever can write its directory. A stale socket left by a
+// crashed instance is replaced.
+func Listen(path string) (*Server, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Server{
+		ln:        ln,
+		path:      path,
+		ctx:       ctx,
+		cancel:    cancel,
+		methods:   make(map[string]Handler),
+		snapshots: make(map[Topic]json.RawMessage),
+		conns:     make(map[*conn]struct{}),
+	}, nil
+}
+
+// Path is the socket the server listens on.
+func (s *Server) Path() string { return s.path }
+
+// Handle registers the handler for method, replacing any earlier one. The
+// built-in methods (describe, subscribe, unsubscribe, state) cannot be
+// replaced.
+func (s *Server) Handle(method string, h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.methods[method] = h
+}
+
+// Serve accepts connections until Close. It always returns a non-nil error;
+// after Close that error is net.ErrClosed.
+func (s *Server) Serve() error {
+	for {
+		nc, err := s.ln.Accept()
+		if err != nil {
+			if s.ctx.Err() != nil {
+				return net.ErrClosed
+			}
+			return err
+		}
+		c := s.newConn(nc)
+		if c == nil {
+			_ = nc.Close()
+			return net.ErrClosed
+		}
+		s.wg.Add(2)
+		go c.writeLoop()
+		go c.readLoop()
+	}
+}
+
+// Close stops accepting, disconnects every client, waits for in-flight
+// handlers to see their context cancelled, and removes the socket.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	conns := make([]*conn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.mu.Unlock()
+
+	s.cancel()
+	err := s.ln.Close()
+	for _, c := range conns {
+		c.close()
+	}
+	s.wg.Wait()
+	if rmErr := os.Remove(s.path); rmErr != nil && !errors.Is(rmErr, os.ErrNotExist) && err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+// Publish sets the current value of topic and sends it to every subscriber
+// of that topic. A value that marshals to the same JSON as the last one is
+// not sent again, so callers may publish on every tick and subscribers still
+// only hear about changes. It reports whether anything was sent.
+func (s *Server) Publish(topic Topic, v any) bool {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed || bytes.Equal(s.snapshots[topic], data) {
+		return false
+	}
+	s.snapshots[topic] = data
+	for c := range s.conns {
+		c.publish(topic, data)
+	}
+	return true
+}
+
+// Snapshot is the last value published for topic, nil if none has been.
+func (s *Server) Snapshot(topic Topic) json.RawMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.snapshots[topic]
+}
+
+func (s *Server) newConn(nc net.Conn) *conn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(s.ctx)
+	c := &conn{
+		s:      s,
+		nc:     nc,
+		ctx:    ctx,
+		cancel: cancel,
+		out:    make(chan []byte, outboxSize),
+		subs:   make(map[string]map[Topic]bool),
+	}
+	s.conns[c] = struct{}{}
+	return c
+}
+
+func (s *Server) dropConn(c *conn) {
+	s.mu.Lock()
+	delete(s.conns, c)
+	s.mu.Unlock()
+}
+
+func (s *Server) handler(method string) Handler {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.methods[method]
+}
+
+func (s *Server) methodNames() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := []string{"describe", "subscribe", "unsubscribe", "state"}
+	for name := range s.methods {
+		if !isBuiltin(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names[4:])
+	return names
+}
+
+func isBuiltin(method string) bool {
+	switch method {
+	case "describe", "subscribe", "unsubscribe", "state":
+		return true
+	}
+	return false
+}
+
+// conn is one client. Reads happen on readLoop, each call runs on its own
+// goroutine, and every write — responses and events alike — goes through out
+// to writeLoop, so a slow client never blocks a handler or a publisher.
+type conn struct {
+	s      *Server
+	nc     net.Conn
+	ctx    context.Context
+	cancel context.CancelFunc
+	out    chan []byte
+
+	// subs is guarded by s.mu: Publish walks it while holding that lock, which
+	// is also what keeps a subscription's first snapshot ahead of any change.
+	subs    map[string]map[Topic]bool
+	nextSub int
+
+	closeOnce sync.Once
+	calls     sync.WaitGroup
+}
+
+func (c *conn) close() {
+	c.closeOnce.Do(func() {
+		c.cancel()
+		_ = c.nc.Close()
+	})
+}
+
+// send queues one message. It never blocks: a full outbox means the client
+// stopped reading, and the connection is closed instead.
+func (c *conn) send(v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	data = append(data, '\n')
+	select {
+	case <-c.ctx.Done():
+	case c.out <- data:
+	default:
+		c.close()
+	}
+}
+
+// publish sends data to each of this connection's subscriptions to topic.
+// The caller holds s.mu.
+func (c *conn) publish(topic Topic, data json.RawMessage) {
+	for id, topics := range c.subs {
+		if topics[topic] {
+			c.sendEvent(id, topic, data)
+		}
+	}
+}
+
+func (c *conn) sendEvent(sub string, topic Topic, data json.RawMessage) {
+	c.send(notification{
+		JSONRPC: Version,
+		Method:  EventMethod,
+		Params:  Event{Subscription: sub, Topic: topic, Data: data},
+	})
+}
+
+type notification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  Event  `json:"params"`
+}
+
+func (c *conn) writeLoop() {
+	defer c.s.wg.Done()
+	w := bufio.NewWriter(c.nc)
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case data := <-c.out:
+			if _, err := w.Write(data); err != nil {
+				c.close()
+				return
+			}
+			// Drain whatever else is already queued before flushing, so a burst
+			// of events costs one write.
+			for drained := false; !drained; {
+				select {
+				case more := <-c.out:
+					if _, err := w.Write(more); err != nil {
+						c.close()
+						return
+					}
+				default:
+					drained = true
+				}
+			}
+			if err := w.Flush(); err != nil {
+				c.close()
+				return
+			}
+		}
+	}
+}
+
+func (c *conn) readLoop() {
+	defer c.s.wg.Done()
+	defer func() {
+		c.close()
+		c.calls.Wait()
+		c.s.dropConn(c)
+	}()
+	scanner := bufio.NewScanner(c.nc)
+	scanner.Buffer(make([]byte, 0, 4096), maxLine)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		c.dispatch(append([]byte(nil), line...))
+	}
+}
+
+func (c *conn) dispatch(line []byte) {
+	if line[0] == '[' {
+		c.reply(nil, nil, &Error{Code: CodeInvalidRequest, Message: "batch requests are not supported"})
+		return
+	}
+	var req Request
+	if err := json.Unmarshal(line, &req); err != nil {
+		c.reply(nil, nil, &Error{Code: CodeParseError, Message: "parse error: " + err.Error()})
+		return
+	}
+	if req.JSONRPC != Version || req.Method == "" {
+		c.reply(req.ID, nil, &Error{Code: CodeInvalidRequest, Message: `invalid request: need "jsonrpc":"2.0" and a method`})
+		return
+	}
+	if isNull(req.Params) {
+		req.Params = nil
+	}
+
+	// subscribe answers and sends its first snapshots under one lock, so it
+	// runs inline; the other built-ins are cheap enough to do the same.
+	switch req.Method {
+	case "describe":
+		c.reply(req.ID, c.describe(), nil)
+		return
+	case "subscribe":
+		c.subscribe(req)
+		return
+	case "unsubscribe":
+		result, err := c.unsubscribe(req.Params)
+		c.reply(req.ID, result, err)
+		return
+	case "state":
+		result, err := c.state(req.Params)
+		c.reply(req.ID, result, err)
+		return
+	}
+
+	h := c.s.handler(req.Method)
+	if h == nil {
+		c.reply(req.ID, nil, &Error{Code: CodeMethodNotFound, Message: "method not found: " + req.Method})
+		return
+	}
+	c.calls.Add(1)
+	go func() {
+		defer c.calls.Done()
+		result, err := runHandler(c.ctx, h, req.Params)
+		c.reply(req.ID, result, err)
+	}()
+}
+
+func runHandler(ctx context.Context, h Handler, params json.RawMessage) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, &Error{Code: CodeInternalError, Message: fmt.Sprintf("handler panic: %v", r)}
+		}
+	}()
+	return h(ctx, params)
+}
+
+// reply answers a request. Notifications (no id) get nothing back, except that
+// a request too broken to have an id still gets its error, with a null id, as
+// JSON-RPC specifies.
+func (c *conn) reply(id json.RawMessage, result any, err error) {
+	if len(id) == 0 && err == nil {
+		return
+	}
+	resp := Response{JSONRPC: Version, ID: id}
+	if len(id) == 0 {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) || (rpcErr.Code != CodeParseError && rpcErr.Code != CodeInvalidRequest) {
+			return
+		}
+		resp.ID = json.RawMessage("null")
+	}
+	if err != nil {
+		resp.Error = asError(err)
+		c.send(resp)
+		return
+	}
+	data, marshalErr := json.Marshal(result)
+	if marshalErr != nil {
+		resp.Error = &Error{Code: CodeInternalError, Message: "encode result: " + marshalErr.Error()}
+	} else {
+		resp.Result = data
+	}
+	c.send(resp)
+}
+
+func asError(err error) *Error {
+	var rpcErr *Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr
+	}
+	return &Error{Code: CodeInternalError, Message: err.Error()}
+}
+
+// Description is the result of describe.
+type Description struct {
+	Version string   `json:"version"`
+	Methods []string `json:"methods"`
+	Topics  []Topic  `json:"topics"`
+}
+
+func (c *conn) describe() Description {
+	return Description{Version: Version, Methods: c.s.methodNames(), Topics: Topics()}
+}
+
+// SubscribeParams are the params of subscribe. No topics means all of them.
+type SubscribeParams struct {
+	Topics []string `json:"topics,omitempty"`
+}
+
+// Subscription is the result of subscribe.
+type Subscription struct {
+	Subscription string  `json:"subscription"`
+	Topics       []Topic `json:"topics"`
+}
+
+func (c *conn) subscribe(req Request) {
+	var params SubscribeParams
+	if err := decodeParams(req.Params, &params); err != nil {
+		c.reply(req.ID, nil, err)
+		return
+	}
+	topics := Topics()
+	if len(params.Topics) > 0 {
+		topics = topics[:0:0]
+		seen := make(map[Topic]bool)
+		for _, name := range params.Topics {
+			topic, err := ParseTopic(name)
+			if err != nil {
+				c.reply(req.ID, nil, InvalidParams("%v", err))
+				return
+			}
+			if !seen[topic] {
+				seen[topic] = true
+				topics = append(topics, topic)
+			}
+		}
+	}
+
+	s := c.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c.nextSub++
+	id := "sub-" + strconv.Itoa(c.nextSub)
+	set := make(map[Topic]bool, len(topics))
+	for _, topic := range topics {
+		set[topic] = true
+	}
+	c.subs[id] = set
+	c.reply(req.ID, Subscription{Subscription: id, Topics: topics}, nil)
+	for _, topic := range topics {
+		if data := s.snapshots[topic]; data != nil {
+			c.sendEvent(id, topic, data)
+		}
+	}
+}
+
+// UnsubscribeParams are the params of unsubscribe.
+type UnsubscribeParams struct {
+	Subscription string `json:"subscription"`
+}
+
+func (c *conn) unsubscribe(raw json.RawMessage) (any, error) {
+	var params UnsubscribeParams
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+	c.s.mu.Lock()
+	defer c.s.mu.Unlock()
+	if _, ok := c.subs[params.Subscription]; !ok {
+		return nil, InvalidParams("no subscription %q on this connection", params.Subscription)
+	}
+	delete(c.subs, params.Subscription)
+	return true, nil
+}
+
+// StateParams are the params of state.
+type StateParams struct {
+	Topic string `json:"topic"`
+}
+
+func (c *conn) state(raw json.RawMessage) (any, error) {
+	var params StateParams
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+	topic, err := ParseTopic(params.Topic)
+	if err != nil {
+		return nil, InvalidParams("%v", err)
+	}
+	data := c.s.Snapshot(topic)
+	if data == nil {
+		return nil, nil
+	}
+	return data, nil
+}
+
+// DecodeParams unmarshals params into v for a handler, rejecting unknown
+// members so a misspelt option is an error rather than silently ignored.
+func DecodeParams(params json.RawMessage, v any) error {
+	if err := decodeParams(params, v); err != nil {
+		return err
+	}
+	return nil
+}
+
+func decodeParams(params json.RawMessage, v any) *Error {
+	if len(params) == 0 {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(params))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return InvalidParams("invalid params: %v", err)
+	}
+	return nil
+}
+
+func isNull(raw json.RawMessage) bool {
+	return len(bytes.TrimSpace(raw)) == 0 || string(bytes.TrimSpace(raw)) == "null"
+}
@@ -0,0 +1,243 @@
+package controlapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startServer serves on a short socket path; t.TempDir paths can exceed the
+// Unix socket limit on macOS.
+func startServer(t *testing.T) *Server {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "scapi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	srv, err := Listen(SocketPath(dir, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = srv.Serve() }()
+	t.Cleanup(func() { _ = srv.Close() })
+	return srv
+}
+
+func dial(t *testing.T, srv *Server) *Client {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	c, err := Dial(ctx, srv.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func nextEvent(t *testing.T, c *Client) Event {
+	t.Helper()
+	select {
+	case ev, ok := <-c.Events():
+		if !ok {
+			t.Fatal("events closed")
+		}
+		return ev
+	case <-time.After(2 * time.Second):
+		t.Fatal("no event")
+	}
+	return Event{}
+}
+
+func TestSocketIsOwnerOnly(t *testing.T) {
+	srv := startServer(t)
+	info, err := os.Stat(srv.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("socket mode = %o, want 600", perm)
+	}
+	dirInfo, err := os.Stat(filepath.Dir(srv.Path()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := dirInfo.Mode().Perm(); perm != 0o700 {
+		t.Fatalf("dir mode = %o, want 700", perm)
+	}
+}
+
+func TestCallRoutesToHandlersAndReportsErrors(t *testing.T) {
+	srv := startServer(t)
+	srv.Handle("echo", func(_ context.Context, params json.RawMessage) (any, error) {
+		var p struct {
+			Text string `json:"text"`
+		}
+		if err := DecodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		if p.Text == "" {
+			return nil, InvalidParams("text is required")
+		}
+		return map[string]string{"text": p.Text}, nil
+	})
+	srv.Handle("boom", func(context.Context, json.RawMessage) (any, error) {
+		return nil, errors.New("it broke")
+	})
+	c := dial(t, srv)
+	ctx := context.Background()
+
+	var got map[string]string
+	if err := c.Call(ctx, "echo", map[string]string{"text": "hi"}, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["text"] != "hi" {
+		t.Fatalf("echo = %v", got)
+	}
+
+	cases := []struct {
+		method string
+		params any
+		code   int
+	}{
+		{"echo", map[string]string{}, CodeInvalidParams},
+		{"echo", map[string]string{"txet": "hi"}, CodeInvalidParams},
+		{"boom", nil, CodeInternalError},
+		{"nope", nil, CodeMethodNotFound},
+	}
+	for _, tc := range cases {
+		err := c.Call(ctx, tc.method, tc.params, nil)
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) || rpcErr.Code != tc.code {
+			t.Errorf("%s(%v) error = %v, want code %d", tc.method, tc.params, err, tc.code)
+		}
+	}
+
+	var desc Description
+	if err := c.Call(ctx, "describe", nil, &desc); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(desc.Methods, ",") != "describe,subscribe,unsubscribe,state,boom,echo" {
+		t.Fatalf("methods = %v", desc.Methods)
+	}
+}
+
+func TestMalformedLinesGetErrorsWithNullID(t *testing.T) {
+	srv := startServer(t)
+	nc, err := net.Dial("unix", srv.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = nc.Close() }()
+	r := bufio.NewReader(nc)
+
+	for _, tc := range []struct {
+		line string
+		code int
+	}{
+		{"{not json", CodeParseError},
+		{`[{"jsonrpc":"2.0","id":1,"method":"describe"}]`, CodeInvalidRequest},
+		{`{"id":1,"method":"describe"}`, CodeInvalidRequest},
+	} {
+		if _, err := nc.Write([]byte(tc.line + "\n")); err != nil {
+			t.Fatal(err)
+		}
+		_ = nc.SetReadDeadline(time.Now().Add(2 * time.Second))
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		var resp Response
+		if err := json.Unmarshal(line, &resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Error == nil || resp.Error.Code != tc.code {
+			t.Errorf("%s: response = %s", tc.line, line)
+		}
+	}
+}
+
+func TestSubscribeSendsSnapshotThenOnlyChanges(t *testing.T) {
+	srv := startServer(t)
+	srv.Publish(TopicGit, map[string]int{"modified": 1})
+	c := dial(t, srv)
+	ctx := context.Background()
+
+	sub, err := c.Subscribe(ctx, TopicGit, TopicAgents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev := nextEvent(t, c)
+	if ev.Subscription != sub.Subscription || ev.Topic != TopicGit || string(ev.Data) != `{"modified":1}` {
+		t.Fatalf("first event = %+v", ev)
+	}
+
+	if srv.Publish(TopicGit, map[string]int{"modified": 1}) {
+		t.Fatal("an unchanged snapshot was published again")
+	}
+	srv.Publish(TopicNotifications, []string{"not subscribed"})
+	srv.Publish(TopicAgents, []string{"claude"})
+	ev = nextEvent(t, c)
+	if ev.Topic != TopicAgents || string(ev.Data) != `["claude"]` {
+		t.Fatalf("second event = %+v", ev)
+	}
+
+	var state json.RawMessage
+	if err := c.Call(ctx, "state", StateParams{Topic: "notifications"}, &state); err != nil {
+		t.Fatal(err)
+	}
+	if string(state) != `["not subscribed"]` {
+		t.Fatalf("state = %s", state)
+	}
+
+	if err := c.Call(ctx, "unsubscribe", UnsubscribeParams{Subscription: sub.Subscription}, nil); err != nil {
+		t.Fatal(err)
+	}
+	srv.Publish(TopicAgents, []string{})
+	select {
+	case ev := <-c.Events():
+		t.Fatalf("event after unsubscribe: %+v", ev)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if _, err := c.Subscribe(ctx, "gti"); err == nil {
+		t.Fatal("subscribing to an unknown topic succeeded")
+	}
+}
+
+func TestCloseRemovesSocketAndEndsClients(t *testing.T) {
+	srv := startServer(t)
+	block := make(chan struct{})
+	srv.Handle("wait", func(ctx context.Context, _ json.RawMessage) (any, error) {
+		close(block)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	c := dial(t, srv)
+	errc := make(chan error, 1)
+	go func() { errc <- c.Call(context.Background(), "wait", nil, nil) }()
+	<-block
+
+	if err := srv.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(srv.Path()); !os.IsNotExist(err) {
+		t.Fatalf("socket still present: %v", err)
+	}
+	select {
+	case err := <-errc:
+		if !errors.Is(err, ErrClosed) {
+			t.Fatalf("in-flight call = %v, want ErrClosed", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("in-flight call did not end")
+	}
+}
@@ -0,0 +1,151 @@
+package controlapi
+
+import (
+	"time"
+
+	"github.com/marcus/sidecar/internal/contentpanes"
+	"github.com/marcus/sidecar/internal/notify"
+)
+
+// The values published on each topic. They are the API's contract, so they
+// are plain data with JSON names a script can rely on rather than the
+// plugin's own structs, and they leave out anything that changes on every
+// poll — an event means something a subscriber can see changed.
+
+// WorkspacesState is the workspaces topic.
+type WorkspacesState struct {
+	// Root is the project root the instance is showing.
+	Root       string      `json:"root"`
+	Workspaces []Workspace `json:"workspaces"`
+}
+
+// Workspace is one shell or worktree.
+type Workspace struct {
+	// Kind is "shell" or "worktree".
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	Path    string `json:"path,omitempty"`
+	Branch  string `json:"branch,omitempty"`
+	Session string `json:"session,omitempty"`
+	// Agent is the type of agent running in it, empty for none.
+	Agent     string `json:"agent,omitempty"`
+	Main      bool   `json:"main,omitempty"`
+	Missing   bool   `json:"missing,omitempty"`
+	Orphaned  bool   `json:"orphaned,omitempty"`
+	Additions int    `json:"additions,omitempty"`
+	Deletions int    `json:"deletions,omitempty"`
+	Ahead     int    `json:"ahead,omitempty"`
+	Behind    int    `json:"behind,omitempty"`
+	PR        string `json:"pr,omitempty"`
+}
+
+// AgentsState is the agents topic.
+type AgentsState struct {
+	Agents []AgentStatus `json:"agents"`
+}
+
+// AgentStatus is one running agent and the lane its status puts it in: the
+// same lane the workspace list and the overview board show.
+type AgentStatus struct {
+	// Workspace is the name of the shell or worktree it runs in, and Kind
+	// which of the two that is.
+	Workspace string `json:"workspace"`
+	Kind      string `json:"kind"`
+	Session   string `json:"session"`
+	Agent     string `json:"agent"`
+	// Lane is working, blocked, done, idle or paused.
+	Lane      string `json:"lane"`
+	Label     string `json:"label"`
+	Attention bool   `json:"attention,omitempty"`
+	// Since is when the agent entered its current state, when known.
+	Since *time.Time `json:"since,omitempty"`
+}
+
+// NotificationsState is the notifications topic, the same shape as
+// `sidecar notify list --json`: every notification not dismissed.
+type NotificationsState struct {
+	Unread int                   `json:"unread"`
+	Items  []notify.Notification `json:"items"`
+}
+
+// GitState is the git topic.
+type GitState struct {
+	Root string `json:"root"`
+	// Files is every changed path, relative to Root and slash-separated.
+	Files  []GitFile `json:"files"`
+	Counts GitCounts `json:"counts"`
+}
+
+// GitFile is one changed path and how it changed: any of "modified",
+// "staged", "untracked" and "conflicted".
+type GitFile struct {
+	Path   string   `json:"path"`
+	States []string `json:"states"`
+}
+
+// GitCounts is how many files carry each state. A file both staged and
+// modified counts in both.
+type GitCounts struct {
+	Modified   int `json:"modified"`
+	Staged     int `json:"staged"`
+	Untracked  int `json:"untracked"`
+	Conflicted int `json:"conflicted"`
+}
+
+// PanesState is the panes topic.
+type PanesState struct {
+	Surfaces []PaneSurface `json:"surfaces"`
+}
+
+// PaneSurface is the content panes open beside one surface: a project
+// plugin, or a workspace's terminal.
+type PaneSurface struct {
+	Surface string `json:"surface"`
+	WorkDir string `json:"workDir,omitempty"`
+	// Layout is the pane layout, the record the instance restores at start
+	// less scroll positions.
+	Layout contentpanes.State `json:"layout"`
+}
+
+// NewPaneSurface is the panes-topic entry for a deck's encoded state, and
+// false when the deck has no pane open. Scroll positions are dropped: they
+// are not which panes are open, and keeping them would publish every wheel
+// tick.
+func NewPaneSurface(surface, workDir string, state contentpanes.State) (PaneSurface, bool) {
+	open := len(state.Hidden) > 0
+	var strip func(*contentpanes.NodeState)
+	strip = func(node *contentpanes.NodeState) {
+		if node == nil {
+			return
+		}
+		if node.Pane != nil {
+			open = true
+			stripScroll(node.Pane)
+		}
+		strip(node.A)
+		strip(node.B)
+	}
+	strip(state.Root)
+	for i := range state.Hidden {
+		stripScroll(&state.Hidden[i])
+	}
+	return PaneSurface{Surface: surface, WorkDir: workDir, Layout: state}, open
+}
+
+func stripScroll(pane *contentpanes.PaneState) {
+	tabs := make([]contentpanes.TabState, len(pane.Tabs))
+	copy(tabs, pane.Tabs)
+	for i := range tabs {
+		tabs[i].Scroll = 0
+	}
+	pane.Tabs = tabs
+}
+
+// PublishMsg asks the host running the API to publish a topic. A plugin
+// cannot reach the server, so it returns this from a command and the app
+// publishes it. For TopicPanes, Data is a []PaneSurface the app merges with
+// the surfaces it hosts itself.
+type PublishMsg struct {
+	Topic Topic
+	Data  any
+}
@@ -50,6 +50,15 @@ var (
 		Description: "Enable inline file editing via tmux in the files plugin",
 	}
 
+	// NativeInlineEdit edits files in Sidecar's built-in editor instead of a
+	// tmux-hosted $EDITOR. It needs neither tmux nor an editor on PATH, and
+	// takes precedence over tmux_inline_edit wherever both could apply.
+	NativeInlineEdit = Feature{
+		Name:        "native_inline_edit",
+		Default:     false,
+		Description: "Edit files in the built-in editor instead of $EDITOR in tmux",
+	}
+
 	// FilesAutoRefresh enables watching expanded directories in the files
 	// plugin and refreshing the tree when they change on disk.
 	FilesAutoRefresh = Feature{
@@ -119,6 +128,24 @@ var (
 		Description: "Recognize and open resources from configured external terminal resource providers",
 	}
 
+	// ControlAPI serves the local control API: JSON-RPC over a Unix socket in
+	// the state dir, announced in the instance file. Off by default — it is an
+	// integration surface, and nothing should listen until someone asks.
+	ControlAPI = Feature{
+		Name:        "control_api",
+		Default:     false,
+		Description: "Serve the local JSON-RPC control API on a Unix socket",
+	}
+
+	// Scripting loads the user's Starlark scripts from the scripts directory
+	// beside the config file. Off by default: a script can commit and type
+	// into agent sessions, so it runs only once the user opts in.
+	Scripting = Feature{
+		Name:        "scripting",
+		Default:     false,
+		Description: "Run user Starlark scripts from the config scripts directory",
+	}
+
 	// CrossProjectOverview gates the cross-project agent overview.
 	CrossProjectOverview = Feature{
 		Name:        "cross_project_overview",
@@ -132,6 +159,7 @@ var allFeatures = []Feature{
 	TmuxInteractiveInput,
 	TmuxFullAttach,
 	TmuxInlineEdit,
+	NativeInlineEdit,
 	FilesAutoRefresh,
 	NotesPlugin,
 	TasksPlugin,
@@ -141,6 +169,8 @@ var allFeatures = []Feature{
 	WorkspaceTerminalPanel,
 	CrossProjectOverview,
 	TerminalResourceProviders,
+	ControlAPI,
+	Scripting,
 }
 
 // defaultValues provides O(1) lookup for feature defaults.
@@ -23,6 +23,11 @@ import (
 const (
 	maxPreviewSize  = 500 * 1024 // 500KB
 	maxPreviewLines = 10000
+
+	// StreamThreshold is the size from which LoadStreamable leaves a text file
+	// unread for the host to stream: past it, even the truncated head costs
+	// noticeable time to highlight, and shows a sliver of the file.
+	StreamThreshold = 4 << 20 // 4MB
 )
 
 // PreviewResult contains the loaded file content.
@@ -33,10 +38,13 @@ type PreviewResult struct {
 	IsBinary         bool
 	IsImage          bool
 	IsTruncated      bool
-	TotalSize        int64
-	ModTime          time.Time
-	Mode             os.FileMode
-	Error            error
+	// IsLarge marks a text file at or above StreamThreshold that
+	// LoadStreamable did not read; the host streams it instead.
+	IsLarge   bool
+	TotalSize int64
+	ModTime   time.Time
+	Mode      os.FileMode
+	Error     error
 }
 
 // PreviewLoadedMsg signals that file preview content is ready.
@@ -83,6 +91,38 @@ func LoadPreview(rootDir, path string, epoch uint64) tea.Cmd {
 	}
 }
 
+// LoadStreamable is LoadPreview for a host that can stream large files: a text
+// file at or above StreamThreshold comes back with IsLarge set and no content,
+// rather than as a truncated head. Only enough of it is read to rule out a
+// binary file.
+func LoadStreamable(rootDir, path string, epoch uint64) tea.Cmd {
+	load := LoadPreview(rootDir, path, epoch)
+	return func() tea.Msg {
+		fullPath := filepath.Join(rootDir, path)
+		info, err := os.Stat(fullPath)
+		if err != nil || info.Size() < StreamThreshold || !info.Mode().IsRegular() || image.IsImageFile(path) {
+			return load()
+		}
+		f, err := os.Open(fullPath)
+		if err != nil {
+			return PreviewLoadedMsg{Epoch: epoch, Path: path, Result: PreviewResult{Error: err}}
+		}
+		defer func() { _ = f.Close() }()
+		head := make([]byte, 512)
+		n, err := f.Read(head)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return PreviewLoadedMsg{Epoch: epoch, Path: path, Result: PreviewResult{Error: err}}
+		}
+		result := PreviewResult{TotalSize: info.Size(), ModTime: info.ModTime(), Mode: info.Mode()}
+		if isBinary(head[:n]) {
+			result.IsBinary = true
+		} else {
+			result.IsLarge = true
+		}
+		return PreviewLoadedMsg{Epoch: epoch, Path: path, Result: result}
+	}
+}
+
 // LoadPreviewFile reads an already-open regular file and takes ownership of it.
 func LoadPreviewFile(file *os.File, path string, epoch uint64) tea.Cmd {
 	return func() tea.Msg {
@@ -0,0 +1,147 @@
+package filestream
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeLines writes n numbered lines and returns the path.
+func writeLines(t *testing.T, n int) string {
+	t.Helper()
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	path := filepath.Join(t.TempDir(), "big.log")
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// indexAll extends ix in small steps until it is done, as a host would.
+func indexAll(t *testing.T, path string, ix Index) Index {
+	t.Helper()
+	for steps := 0; !ix.Done(); steps++ {
+		var err error
+		if ix, _, err = ix.Extend(path, 4096); err != nil {
+			t.Fatal(err)
+		}
+		if steps > 10000 {
+			t.Fatal("indexing did not finish")
+		}
+	}
+	return ix
+}
+
+func TestIndexReadsAnyWindow(t *testing.T) {
+	path := writeLines(t, 5000)
+	ix := indexAll(t, path, Index{})
+	if ix.Lines() != 5000 {
+		t.Fatalf("lines = %d, want 5000", ix.Lines())
+	}
+	if len(ix.marks) != 5000/stride+1 {
+		t.Fatalf("marks = %d, want one per %d lines", len(ix.marks), stride)
+	}
+	got, err := ReadLines(path, ix, 3070, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, ",") != "line 3070,line 3071,line 3072" {
+		t.Fatalf("window = %v", got)
+	}
+	tail, _ := ReadLines(path, ix, 4998, 10)
+	if len(tail) != 2 {
+		t.Fatalf("window past the end = %v", tail)
+	}
+}
+
+func TestExtendFollowsGrowthAndResetsOnTruncate(t *testing.T) {
+	path := writeLines(t, 10)
+	ix := indexAll(t, path, Index{})
+	before := ix
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString("appended\npartial")
+	_ = f.Close()
+	ix, reset, err := ix.Extend(path, IndexStep)
+	if err != nil || reset {
+		t.Fatalf("extend after append: reset=%v err=%v", reset, err)
+	}
+	if ix.Lines() != 12 {
+		t.Fatalf("lines after append = %d, want 12 (partial last line counts)", ix.Lines())
+	}
+	if before.Lines() != 10 {
+		t.Fatal("Extend modified the index it was called on")
+	}
+
+	if err := os.WriteFile(path, []byte("fresh\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ix, reset, err = ix.Extend(path, IndexStep)
+	if err != nil || !reset || ix.Lines() != 1 {
+		t.Fatalf("after truncate: reset=%v lines=%d err=%v", reset, ix.Lines(), err)
+	}
+}
+
+func TestLongLinesAreCapped(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "min.js")
+	content := strings.Repeat("x", 3*MaxLineBytes) + "\nnext\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ix := indexAll(t, path, Index{})
+	lines, err := ReadLines(path, ix, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines[0]) != MaxLineBytes || lines[1] != "next" {
+		t.Fatalf("got %d-byte first line and %q", len(lines[0]), lines[1])
+	}
+}
+
+func TestSearchStepsForwardAndBackward(t *testing.T) {
+	path := writeLines(t, 4000)
+	ix := indexAll(t, path, Index{})
+
+	// A tiny budget forces the host-driven loop the preview runs.
+	from, steps := 0, 0
+	var res SearchResult
+	for {
+		var err error
+		res, err = Search(path, ix, "LINE 3500", from, true, 2048)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Found || res.Done {
+			break
+		}
+		from = res.Next
+		steps++
+	}
+	if res.Found {
+		t.Fatal("upper-case query matched case-insensitively")
+	}
+	if steps == 0 {
+		t.Fatal("search finished in one step despite the budget")
+	}
+
+	res, err := Search(path, ix, "line 3500", 0, true, SearchStep)
+	if err != nil || !res.Found || res.Line != 3500 {
+		t.Fatalf("forward = %+v err=%v", res, err)
+	}
+	res, err = Search(path, ix, "line 1000", 3000, false, SearchStep)
+	if err != nil || !res.Found || res.Line != 1000 {
+		t.Fatalf("backward = %+v err=%v, want line 1000, two index blocks up", res, err)
+	}
+	res, _ = Search(path, ix, "line 0", 0, false, SearchStep)
+	if !res.Done {
+		t.Fatalf("backward from the top = %+v, want done", res)
+	}
+}
@@ -0,0 +1,149 @@
+// Package filestream reads text files too large to load whole. It keeps a
+// sparse index of line offsets instead of the lines themselves, so a preview
+// can show any window of a multi-hundred-megabyte log by seeking to the
+// nearest indexed line and reading forward a few screens.
+//
+// Every operation is a bounded step over an immutable Index value: a host
+// runs one step in a tea.Cmd, installs the result, and issues the next, which
+// is how indexing and search report progress without a goroutine the host
+// would have to cancel.
+package filestream
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"slices"
+)
+
+const (
+	// stride is how many lines apart the index records an offset. Reaching
+	// any line costs one seek plus reading at most stride lines, and a
+	// five-million-line log indexes in about 40KB.
+	stride = 1024
+
+	// IndexStep is how many bytes one indexing step scans.
+	IndexStep = 32 << 20
+
+	// readBuffer is the read size used while scanning.
+	readBuffer = 256 << 10
+)
+
+// Index maps line numbers to byte offsets for one file. The zero value is an
+// empty index that the first Extend starts from the beginning.
+//
+// An Index is a value: Extend returns a new one and never writes to the
+// receiver's memory, so the copy a view is reading stays valid while its
+// successor is being built on another goroutine.
+type Index struct {
+	marks    []int64 // marks[k] is the offset of line k*stride
+	newlines int     // newlines seen in the scanned prefix
+	scanned  int64   // bytes indexed
+	partial  bool    // the scanned prefix ends mid-line
+	size     int64   // file size at the last step
+	info     os.FileInfo
+}
+
+// Lines returns how many lines the indexed prefix holds, counting a final
+// line with no newline.
+func (ix Index) Lines() int {
+	if ix.partial {
+		return ix.newlines + 1
+	}
+	return ix.newlines
+}
+
+// Scanned returns how many bytes have been indexed.
+func (ix Index) Scanned() int64 { return ix.scanned }
+
+// Size returns the file's size when it was last indexed.
+func (ix Index) Size() int64 { return ix.size }
+
+// Done reports whether the whole file, as of the last step, is indexed.
+func (ix Index) Done() bool { return ix.info != nil && ix.scanned >= ix.size }
+
+// Progress returns the indexed fraction of the file, from 0 to 1.
+func (ix Index) Progress() float64 {
+	if ix.size <= 0 {
+		return 1
+	}
+	return float64(ix.scanned) / float64(ix.size)
+}
+
+// Extend indexes up to budget more bytes of the file at path and returns the
+// grown index. A file that grew is picked up where the last step stopped,
+// which is what makes following a log cheap. A file that shrank or was
+// replaced — truncation, log rotation — cannot be extended, so indexing starts
+// over and reset reports it.
+func (ix Index) Extend(path string, budget int64) (next Index, reset bool, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return ix, false, err
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		return ix, false, err
+	}
+	if ix.info != nil && (!os.SameFile(ix.info, info) || info.Size() < ix.scanned) {
+		ix, reset = Index{}, true
+	}
+	ix.info = info
+	ix.size = info.Size()
+	// Clipping forces the appends below to copy, leaving the caller's
+	// backing array untouched.
+	ix.marks = slices.Clip(ix.marks)
+	if len(ix.marks) == 0 {
+		ix.marks = []int64{0}
+	}
+
+	end := min(ix.size, ix.scanned+budget)
+	buf := make([]byte, readBuffer)
+	off := ix.scanned
+	for off < end {
+		n, err := f.ReadAt(buf[:min(int64(len(buf)), end-off)], off)
+		chunk := buf[:n]
+		for base := 0; ; {
+			i := bytes.IndexByte(chunk[base:], '\n')
+			if i < 0 {
+				break
+			}
+			base += i + 1
+			ix.newlines++
+			if ix.newlines%stride == 0 {
+				ix.marks = append(ix.marks, off+int64(base))
+			}
+		}
+		if n > 0 {
+			ix.partial = chunk[n-1] != '\n'
+		}
+		off += int64(n)
+		if err == io.EOF {
+			// The file is shorter than its stat said: it was truncated
+			// mid-step. The next step sees the new size.
+			ix.size = off
+			break
+		}
+		if err != nil {
+			return ix, reset, err
+		}
+	}
+	ix.scanned = off
+	return ix, reset, nil
+}
+
+// seek returns the offset of the nearest indexed line at or before line, and
+// how many lines past it line is.
+func (ix Index) seek(line int) (int64, int) {
+	if line < 0 {
+		line = 0
+	}
+	k := line / stride
+	if k >= len(ix.marks) {
+		k = len(ix.marks) - 1
+	}
+	if k < 0 {
+		return 0, line
+	}
+	return ix.marks[k], line - k*stride
+}
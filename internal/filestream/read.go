@@ -0,0 +1,91 @@
+package filestream
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// MaxLineBytes caps how much of one line is kept. A minified bundle or a
+// single-line JSON dump can be a hundred megabytes on one line, and a preview
+// only ever draws a screen's width of it.
+const MaxLineBytes = 4096
+
+// ReadLines returns up to n lines starting at line start (0-based). Lines
+// longer than MaxLineBytes are cut short.
+func ReadLines(path string, ix Index, start, n int) ([]string, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	r, err := readerAt(f, ix, start)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, n)
+	for len(out) < n {
+		line, _, err := readLine(r)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return out, err
+		}
+		out = append(out, line)
+	}
+	return out, nil
+}
+
+// readLine reads one line, keeping at most MaxLineBytes of it, and returns it
+// without its line ending along with the bytes consumed. io.EOF is returned
+// only when there was no line left at all.
+func readLine(r *bufio.Reader) (string, int64, error) {
+	var b strings.Builder
+	var consumed int64
+	for {
+		frag, err := r.ReadSlice('\n')
+		consumed += int64(len(frag))
+		if room := MaxLineBytes - b.Len(); room > 0 {
+			if len(frag) > room {
+				b.Write(frag[:room])
+			} else {
+				b.Write(frag)
+			}
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if err != nil && (!errors.Is(err, io.EOF) || consumed == 0) {
+			return "", consumed, err
+		}
+		break
+	}
+	line := strings.TrimSuffix(b.String(), "\n")
+	line = strings.TrimSuffix(line, "\r")
+	return strings.ToValidUTF8(line, "�"), consumed, nil
+}
+
+// readerAt positions a reader at the start of line, or at the end of the file
+// when it has fewer lines.
+func readerAt(f *os.File, ix Index, line int) (*bufio.Reader, error) {
+	off, skip := ix.seek(line)
+	if _, err := f.Seek(off, io.SeekStart); err != nil {
+		return nil, err
+	}
+	r := bufio.NewReaderSize(f, readBuffer)
+	for ; skip > 0; skip-- {
+		if _, _, err := readLine(r); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,116 @@
+package filestream
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// SearchStep is how many bytes one search step scans.
+const SearchStep = 16 << 20
+
+// SearchResult is the outcome of one search step.
+type SearchResult struct {
+	// Found reports a match at Line.
+	Found bool
+	Line  int
+	// Done reports that the scan reached the end of the file (or its start,
+	// searching backward) without a match.
+	Done bool
+	// Next is where the following step resumes when neither Found nor Done:
+	// the first unscanned line going forward, or the line below which the
+	// scan continues going backward.
+	Next int
+}
+
+// Search scans for query from line from, forward or backward, reading up to
+// budget bytes. A forward search starts at from itself; a backward search
+// starts at the line before it. A query with no upper-case letters matches
+// case-insensitively, as editors' smart case does. Only the first
+// MaxLineBytes of each line are searched.
+func Search(path string, ix Index, query string, from int, forward bool, budget int64) (SearchResult, error) {
+	if query == "" {
+		return SearchResult{Done: true}, nil
+	}
+	match := matcher(query)
+	f, err := os.Open(path)
+	if err != nil {
+		return SearchResult{}, err
+	}
+	defer func() { _ = f.Close() }()
+	if forward {
+		return searchForward(f, ix, match, from, budget)
+	}
+	return searchBackward(f, ix, match, from, budget)
+}
+
+func matcher(query string) func(string) bool {
+	if strings.ToLower(query) == query {
+		return func(line string) bool { return strings.Contains(strings.ToLower(line), query) }
+	}
+	return func(line string) bool { return strings.Contains(line, query) }
+}
+
+func searchForward(f *os.File, ix Index, match func(string) bool, from int, budget int64) (SearchResult, error) {
+	r, err := readerAt(f, ix, from)
+	if err != nil {
+		return SearchResult{}, err
+	}
+	var read int64
+	for line := from; ; line++ {
+		if read >= budget {
+			return SearchResult{Next: line}, nil
+		}
+		text, n, err := readLine(r)
+		if errors.Is(err, io.EOF) {
+			return SearchResult{Done: true}, nil
+		}
+		if err != nil {
+			return SearchResult{}, err
+		}
+		read += n
+		if match(text) {
+			return SearchResult{Found: true, Line: line}, nil
+		}
+	}
+}
+
+// searchBackward walks index blocks from the one holding from-1 toward the
+// start, keeping the last match in each block, since lines can only be read
+// forward.
+func searchBackward(f *os.File, ix Index, match func(string) bool, from int, budget int64) (SearchResult, error) {
+	var read int64
+	for end := from; end > 0; {
+		start := ((end - 1) / stride) * stride
+		if k := start / stride; k >= len(ix.marks) {
+			start = (len(ix.marks) - 1) * stride
+		}
+		if read >= budget {
+			return SearchResult{Next: end}, nil
+		}
+		r, err := readerAt(f, ix, start)
+		if err != nil {
+			return SearchResult{}, err
+		}
+		hit := -1
+		for line := start; line < end; line++ {
+			text, n, err := readLine(r)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return SearchResult{}, err
+			}
+			read += n
+			if match(text) {
+				hit = line
+			}
+		}
+		if hit >= 0 {
+			return SearchResult{Found: true, Line: hit}, nil
+		}
+		end = start
+	}
+	return SearchResult{Done: true}, nil
+}
@@ -0,0 +1,142 @@
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// apiTimeout bounds one REST call; a forge that stops answering must not hold
+// a merge workflow step open.
+const apiTimeout = 30 * time.Second
+
+// api is a forge's REST API. path is relative to the API root and may carry
+// a query; in and out are JSON bodies, either of which may be nil. An out of
+// type *[]byte receives the response undecoded, for plain-text endpoints.
+type api interface {
+	call(ctx context.Context, method, path string, in, out any) error
+}
+
+// httpAPI calls a REST API directly, authenticating with a token header.
+type httpAPI struct {
+	base   string // API root, without a trailing slash
+	header string // Authentication header name
+	value  string // Its value; no header is sent when empty
+	client *http.Client
+}
+
+func (a *httpAPI) call(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, a.base+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if a.value != "" {
+		req.Header.Set(a.header, a.value)
+	}
+	client := a.client
+	if client == nil {
+		client = &http.Client{Timeout: apiTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s %s: HTTP %d: %s", method, path, resp.StatusCode, apiErrorMessage(data, resp.Status))
+	}
+	return decodeResponse(data, out, method+" "+path)
+}
+
+func decodeResponse(data []byte, out any, what string) error {
+	switch out := out.(type) {
+	case nil:
+		return nil
+	case *[]byte:
+		*out = data
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("%s: parse response: %w", what, err)
+	}
+	return nil
+}
+
+// apiErrorMessage extracts the message GitLab and Gitea put in error bodies.
+func apiErrorMessage(data []byte, status string) string {
+	var body struct {
+		Message any    `json:"message"`
+		Error   string `json:"error"`
+	}
+	if json.Unmarshal(data, &body) == nil {
+		switch m := body.Message.(type) {
+		case string:
+			if m != "" {
+				return m
+			}
+		case nil:
+		default:
+			if encoded, err := json.Marshal(m); err == nil {
+				return string(encoded)
+			}
+		}
+		if body.Error != "" {
+			return body.Error
+		}
+	}
+	return status
+}
+
+// glabAPI calls GitLab's REST API through `glab api`, which carries the
+// user's glab login for the host.
+type glabAPI struct {
+	host string
+	dir  string
+}
+
+func (a *glabAPI) call(ctx context.Context, method, path string, in, out any) error {
+	args := []string{"api", "--hostname", a.host, "--method", method, strings.TrimPrefix(path, "/")}
+	cmd := exec.CommandContext(ctx, "glab", args...)
+	cmd.Dir = a.dir
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		cmd.Args = append(cmd.Args, "--header", "Content-Type: application/json", "--input", "-")
+		cmd.Stdin = bytes.NewReader(data)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	data, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return fmt.Errorf("glab api %s %s: %s: %w", method, path, msg, err)
+	}
+	return decodeResponse(data, out, "glab api "+method+" "+path)
+}
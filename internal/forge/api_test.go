@@ -0,0 +1,153 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// stubForge serves canned JSON by method and escaped path, recording the
+// body of each request and the headers of the last.
+type stubForge struct {
+	responses map[string]string
+	bodies    map[string]map[string]any
+	headers   http.Header
+}
+
+func newStubForge(t *testing.T, responses map[string]string) (*stubForge, *httptest.Server) {
+	s := &stubForge{responses: responses, bodies: map[string]map[string]any{}}
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return s, srv
+}
+
+func (s *stubForge) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.Method + " " + r.URL.EscapedPath()
+	s.headers = r.Header.Clone()
+	if r.Body != nil {
+		var body map[string]any
+		if json.NewDecoder(r.Body).Decode(&body) == nil {
+			s.bodies[key] = body
+		}
+	}
+	resp, ok := s.responses[key]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"404 Not Found"}`))
+		return
+	}
+	_, _ = w.Write([]byte(resp))
+}
+
+// stubRepo is a repository on the stub server's host.
+func stubRepo(srv *httptest.Server, kind Kind, path string) Repo {
+	return Repo{Kind: kind, Host: strings.TrimPrefix(srv.URL, "http://"), Path: path, Scheme: "http"}
+}
+
+func TestGitLabMergeRequestsOverREST(t *testing.T) {
+	t.Setenv("GITLAB_TOKEN", "secret")
+	stub, srv := newStubForge(t, map[string]string{
+		"GET /api/v4/projects/group%2Fapp/merge_requests": `[
+			{"id":900,"iid":3,"title":"Draft: thing","web_url":"u3","state":"opened","draft":true,"source_branch":"topic","target_branch":"main","sha":"aaa","source_project_id":1,"target_project_id":1,"created_at":"2026-01-02T03:04:05.000Z","author":{"username":"ann"}},
+			{"id":901,"iid":4,"title":"fork","web_url":"u4","state":"merged","source_branch":"topic","target_branch":"main","sha":"bbb","merge_commit_sha":null,"squash_commit_sha":"sq","source_project_id":2,"target_project_id":1,"author":{"username":"bo"}}
+		]`,
+		"GET /api/v4/projects/me%2Fapp":                     `{"id":2}`,
+		"GET /api/v4/projects/group%2Fapp":                  `{"id":1}`,
+		"POST /api/v4/projects/me%2Fapp/merge_requests":     `{"id":902,"iid":5,"web_url":"u5","state":"opened","source_branch":"feature","target_branch":"main","sha":"ccc","source_project_id":2,"target_project_id":1}`,
+		"GET /api/v4/projects/group%2Fapp/merge_requests/4": `{"id":901,"iid":4,"state":"merged","source_branch":"topic","target_branch":"main","sha":"bbb","merge_commit_sha":"mmm","source_project_id":2,"target_project_id":1}`,
+	})
+	f := New(stubRepo(srv, GitLab, "group/app"), "")
+	ctx := context.Background()
+
+	open, err := f.ListOpen(ctx, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(open) != 2 || open[0].Number != 3 || !open[0].Draft || open[0].Author != "ann" || open[0].HeadRepo != "group/app" || open[0].HeadOwner != "group" {
+		t.Fatalf("open = %+v", open)
+	}
+	if stub.headers.Get("PRIVATE-TOKEN") != "secret" {
+		t.Fatalf("token header = %q", stub.headers.Get("PRIVATE-TOKEN"))
+	}
+
+	same, err := f.Find(ctx, Head{Ref: "topic"}, "main")
+	if err != nil || same == nil || same.Number != 3 {
+		t.Fatalf("same-project find = %+v, %v", same, err)
+	}
+	fork, err := f.Find(ctx, Head{Owner: "me", Ref: "topic"}, "main")
+	if err != nil || fork == nil || fork.Number != 4 || fork.State != StateMerged || fork.MergeOID != "sq" || fork.HeadRepo != "me/app" {
+		t.Fatalf("fork find = %+v, %v", fork, err)
+	}
+
+	created, err := f.Create(ctx, CreateRequest{Title: "t", Body: "b", Head: Head{Owner: "me", Repo: "me/app", Ref: "feature"}, Base: "main"})
+	if err != nil || created.Number != 5 || created.State != StateOpen {
+		t.Fatalf("create = %+v, %v", created, err)
+	}
+	body := stub.bodies["POST /api/v4/projects/me%2Fapp/merge_requests"]
+	if body["target_project_id"] != float64(1) || body["source_branch"] != "feature" || body["description"] != "b" {
+		t.Fatalf("create body = %v", body)
+	}
+
+	got, err := f.Get(ctx, 4)
+	if err != nil || got.State != StateMerged || got.MergeOID != "mmm" {
+		t.Fatalf("get = %+v, %v", got, err)
+	}
+
+	if _, err := f.Get(ctx, 99); err == nil || !strings.Contains(err.Error(), "HTTP 404") {
+		t.Fatalf("missing merge request err = %v", err)
+	}
+}
+
+func TestGitLabUsesGlabWithoutAToken(t *testing.T) {
+	t.Setenv("GITLAB_TOKEN", "")
+	log := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("FAKE_GLAB_LOG", log)
+	installFakeCLI(t, "glab", `
+printf '%s\n' "$*" >> "$FAKE_GLAB_LOG"
+printf '%s\n' '{"id":1,"iid":8,"state":"closed","source_branch":"x","target_branch":"main","sha":"s","source_project_id":1,"target_project_id":1}'
+`)
+	mr, err := New(Repo{Kind: GitLab, Host: "gitlab.example.com", Path: "team/app"}, t.TempDir()).Get(context.Background(), 8)
+	if err != nil || mr.Number != 8 || mr.State != StateClosed {
+		t.Fatalf("get = %+v, %v", mr, err)
+	}
+	calls, _ := os.ReadFile(log)
+	if want := "api --hostname gitlab.example.com --method GET projects/team%2Fapp/merge_requests/8"; !strings.Contains(string(calls), want) {
+		t.Fatalf("glab calls = %q, want %q", calls, want)
+	}
+}
+
+func TestGiteaPullRequestsOverREST(t *testing.T) {
+	t.Setenv("GITEA_TOKEN", "tok")
+	stub, srv := newStubForge(t, map[string]string{
+		"GET /api/v1/repos/org/svc/pulls": `[
+			{"id":10,"number":1,"title":"WIP: later","html_url":"p1","state":"open","head":{"ref":"topic","sha":"aaa","repo":{"full_name":"org/svc","owner":{"login":"org"}}},"base":{"ref":"main"},"user":{"login":"ann"}},
+			{"id":11,"number":2,"title":"done","html_url":"p2","state":"closed","merged":true,"merge_commit_sha":"mmm","head":{"ref":"topic","sha":"bbb","repo":{"full_name":"me/svc","owner":{"login":"me"}}},"base":{"ref":"main"},"user":{"login":"me"}}
+		]`,
+		"POST /api/v1/repos/org/svc/pulls": `{"id":12,"number":3,"html_url":"p3","state":"open","head":{"ref":"feature","sha":"ccc"},"base":{"ref":"main"}}`,
+	})
+	f := New(stubRepo(srv, Gitea, "org/svc"), "")
+	ctx := context.Background()
+
+	open, err := f.ListOpen(ctx, 30)
+	if err != nil || len(open) != 2 || !open[0].Draft || open[0].Author != "ann" {
+		t.Fatalf("open = %+v, %v", open, err)
+	}
+	if stub.headers.Get("Authorization") != "token tok" {
+		t.Fatalf("auth header = %q", stub.headers.Get("Authorization"))
+	}
+	fork, err := f.Find(ctx, Head{Owner: "me", Ref: "topic"}, "main")
+	if err != nil || fork == nil || fork.Number != 2 || fork.State != StateMerged || fork.MergeOID != "mmm" {
+		t.Fatalf("fork find = %+v, %v", fork, err)
+	}
+	if _, err := f.Create(ctx, CreateRequest{Title: "t", Body: "b", Head: Head{Owner: "me", Ref: "feature"}, Base: "main"}); err != nil {
+		t.Fatal(err)
+	}
+	if body := stub.bodies["POST /api/v1/repos/org/svc/pulls"]; body["head"] != "me:feature" || body["base"] != "main" {
+		t.Fatalf("create body = %v", body)
+	}
+}
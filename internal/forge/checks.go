@@ -0,0 +1,55 @@
+package forge
+
+import "errors"
+
+// CheckState is where one CI check, or a commit's checks taken together,
+// stand.
+type CheckState string
+
+const (
+	CheckQueued  CheckState = "queued"
+	CheckRunning CheckState = "running"
+	CheckFailed  CheckState = "failed"
+	CheckPassed  CheckState = "passed"
+)
+
+// Settled reports whether the state will not change without a new push.
+func (s CheckState) Settled() bool {
+	return s == CheckFailed || s == CheckPassed
+}
+
+// Check is one CI job or commit status reported against a commit.
+type Check struct {
+	// ID names the job whose log CheckLog returns. It is empty for statuses
+	// posted by external CI, whose logs the forge does not keep.
+	ID    string
+	Name  string
+	State CheckState
+	URL   string
+}
+
+// ErrNoLog means the forge keeps no log for a check.
+var ErrNoLog = errors.New("the forge keeps no log for this check")
+
+// Summarize is the state of a commit's checks together: failed as soon as
+// one has failed, otherwise running while any runs, queued while any waits,
+// and passed once all have. It is empty when there are no checks at all.
+func Summarize(checks []Check) CheckState {
+	if len(checks) == 0 {
+		return ""
+	}
+	counts := map[CheckState]int{}
+	for _, c := range checks {
+		counts[c.State]++
+	}
+	switch {
+	case counts[CheckFailed] > 0:
+		return CheckFailed
+	case counts[CheckRunning] > 0:
+		return CheckRunning
+	case counts[CheckQueued] > 0:
+		return CheckQueued
+	default:
+		return CheckPassed
+	}
+}
@@ -0,0 +1,110 @@
+package forge
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	for _, tc := range []struct {
+		states []CheckState
+		want   CheckState
+	}{
+		{nil, ""},
+		{[]CheckState{CheckPassed, CheckPassed}, CheckPassed},
+		{[]CheckState{CheckPassed, CheckQueued}, CheckQueued},
+		{[]CheckState{CheckQueued, CheckRunning}, CheckRunning},
+		{[]CheckState{CheckRunning, CheckFailed, CheckPassed}, CheckFailed},
+	} {
+		checks := make([]Check, 0, len(tc.states))
+		for _, s := range tc.states {
+			checks = append(checks, Check{State: s})
+		}
+		if got := Summarize(checks); got != tc.want {
+			t.Errorf("Summarize(%v) = %q, want %q", tc.states, got, tc.want)
+		}
+	}
+}
+
+func TestGitHubChecksMergeRunsAndStatuses(t *testing.T) {
+	installFakeCLI(t, "gh", `
+case "$*" in
+*check-runs*) printf '%s\n' '{"check_runs":[{"id":11,"name":"test","status":"completed","conclusion":"failure","html_url":"h11","app":{"slug":"github-actions"}},{"id":12,"name":"lint","status":"in_progress","app":{"slug":"other"}}]}' ;;
+*/status*) printf '%s\n' '{"statuses":[{"context":"ci/ext","state":"pending","target_url":"x"}]}' ;;
+"run view --repo o/r --job 11 --log-failed") echo 'test	step	boom' ;;
+esac
+`)
+	f := New(Repo{Kind: GitHub, Host: "github.com", Path: "o/r"}, t.TempDir())
+	checks, err := f.Checks(context.Background(), "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Check{
+		{ID: "11", Name: "test", State: CheckFailed, URL: "h11"},
+		{Name: "lint", State: CheckRunning},
+		{Name: "ci/ext", State: CheckRunning, URL: "x"},
+	}
+	if len(checks) != len(want) {
+		t.Fatalf("checks = %+v", checks)
+	}
+	for i := range want {
+		if checks[i] != want[i] {
+			t.Errorf("check %d = %+v, want %+v", i, checks[i], want[i])
+		}
+	}
+	log, err := f.CheckLog(context.Background(), "11")
+	if err != nil || log != "test\tstep\tboom\n" {
+		t.Fatalf("log = %q, %v", log, err)
+	}
+	if _, err := f.CheckLog(context.Background(), ""); !errors.Is(err, ErrNoLog) {
+		t.Fatalf("status log err = %v", err)
+	}
+}
+
+func TestGitLabPipelineJobsAndTrace(t *testing.T) {
+	t.Setenv("GITLAB_TOKEN", "secret")
+	_, srv := newStubForge(t, map[string]string{
+		"GET /api/v4/projects/g%2Fapp/pipelines":         `[{"id":70}]`,
+		"GET /api/v4/projects/g%2Fapp/pipelines/70/jobs": `[{"id":1,"name":"build","status":"success"},{"id":2,"name":"flaky","status":"failed","allow_failure":true},{"id":3,"name":"test","status":"failed","web_url":"j3"},{"id":4,"name":"deploy","status":"manual"},{"id":5,"name":"e2e","status":"pending"}]`,
+		"GET /api/v4/projects/g%2Fapp/jobs/3/trace":      "line 1\nFAILED\n",
+	})
+	f := New(stubRepo(srv, GitLab, "g/app"), "")
+	checks, err := f.Checks(context.Background(), "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]CheckState, 0, len(checks))
+	for _, c := range checks {
+		got = append(got, c.State)
+	}
+	want := []CheckState{CheckPassed, CheckPassed, CheckFailed, CheckPassed, CheckQueued}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("states = %v, want %v", got, want)
+		}
+	}
+	if checks[2].ID != "3" || checks[2].URL != "j3" {
+		t.Fatalf("failed job = %+v", checks[2])
+	}
+	log, err := f.CheckLog(context.Background(), "3")
+	if err != nil || log != "line 1\nFAILED\n" {
+		t.Fatalf("trace = %q, %v", log, err)
+	}
+}
+
+func TestGiteaCommitStatusChecks(t *testing.T) {
+	t.Setenv("GITEA_TOKEN", "")
+	t.Setenv("FORGEJO_TOKEN", "")
+	_, srv := newStubForge(t, map[string]string{
+		"GET /api/v1/repos/org/svc/commits/abc/status": `{"state":"failure","statuses":[{"context":"ci/build","status":"success"},{"context":"ci/test","status":"failure","target_url":"t"}]}`,
+	})
+	f := New(stubRepo(srv, Gitea, "org/svc"), "")
+	checks, err := f.Checks(context.Background(), "abc")
+	if err != nil || len(checks) != 2 || Summarize(checks) != CheckFailed || checks[1].URL != "t" || checks[1].ID != "" {
+		t.Fatalf("checks = %+v, %v", checks, err)
+	}
+	if _, err := f.CheckLog(context.Background(), "1"); !errors.Is(err, ErrNoLog) {
+		t.Fatalf("log err = %v", err)
+	}
+}
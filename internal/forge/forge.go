@@ -0,0 +1,330 @@
+// Package forge talks to the service hosting a repository's remote — GitHub,
+// GitLab, or Gitea and its fork Forgejo — for the pull request workflow and for
+// links into its web UI. The forge is detected from the remote URL. Each
+// implementation uses the transport its users already have set up: the gh CLI
+// for GitHub, glab or the REST API for GitLab, and the REST API for Gitea.
+//
+// GitLab calls pull requests merge requests; this package says merge request
+// for all three, and Kind.RequestNoun gives the word each forge's users know.
+package forge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Kind is a forge implementation.
+type Kind string
+
+const (
+	GitHub Kind = "github"
+	GitLab Kind = "gitlab"
+	Gitea  Kind = "gitea"
+)
+
+// ParseKind parses a configured forge name. Forgejo speaks Gitea's API.
+func ParseKind(s string) (Kind, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "github":
+		return GitHub, true
+	case "gitlab":
+		return GitLab, true
+	case "gitea", "forgejo":
+		return Gitea, true
+	default:
+		return "", false
+	}
+}
+
+// DetectKind guesses the forge from a hostname. Self-hosted GitLab and Gitea
+// instances conventionally carry the product in their name; any other host
+// needs its kind configured.
+func DetectKind(host string) (Kind, bool) {
+	host = strings.ToLower(host)
+	switch {
+	case host == "github.com" || strings.HasSuffix(host, ".github.com") || strings.HasSuffix(host, ".ghe.com"):
+		return GitHub, true
+	case host == "gitlab.com" || strings.Contains(host, "gitlab"):
+		return GitLab, true
+	case host == "codeberg.org" || strings.Contains(host, "gitea") || strings.Contains(host, "forgejo"):
+		return Gitea, true
+	default:
+		return "", false
+	}
+}
+
+// Title is the forge's product name.
+func (k Kind) Title() string {
+	switch k {
+	case GitLab:
+		return "GitLab"
+	case Gitea:
+		return "Gitea"
+	default:
+		return "GitHub"
+	}
+}
+
+// RequestNoun is what the forge calls a merge request.
+func (k Kind) RequestNoun() string {
+	if k == GitLab {
+		return "merge request"
+	}
+	return "pull request"
+}
+
+// Repo identifies a repository on a forge.
+type Repo struct {
+	Kind Kind
+	Host string // Hostname, with a port only when the web UI is served on one
+	// Path is owner/name; GitLab nests groups, as in group/subgroup/name.
+	Path string
+	// Scheme of the web UI and API; https when empty.
+	Scheme string
+}
+
+// Owner is the user, organization or group path that holds the repository.
+func (r Repo) Owner() string {
+	owner, _ := splitRepoPath(r.Path)
+	return owner
+}
+
+// Name is the repository's own name.
+func (r Repo) Name() string {
+	_, name := splitRepoPath(r.Path)
+	return name
+}
+
+func splitRepoPath(p string) (owner, name string) {
+	i := strings.LastIndex(p, "/")
+	if i < 0 {
+		return "", p
+	}
+	return p[:i], p[i+1:]
+}
+
+// String names the repository as host/path.
+func (r Repo) String() string {
+	return r.Host + "/" + r.Path
+}
+
+// IsZero reports whether r names no repository.
+func (r Repo) IsZero() bool {
+	return r.Path == ""
+}
+
+// SameAs reports whether r and other name the same repository, whatever
+// transport their remotes use.
+func (r Repo) SameAs(other Repo) bool {
+	return strings.EqualFold(hostname(r.Host), hostname(other.Host)) && strings.EqualFold(r.Path, other.Path)
+}
+
+func hostname(host string) string {
+	if h, _, ok := strings.Cut(host, ":"); ok {
+		return h
+	}
+	return host
+}
+
+// WebURL is the repository's home page.
+func (r Repo) WebURL() string {
+	return r.origin() + "/" + r.Path
+}
+
+// origin is the forge's web root, which its REST API hangs off too.
+func (r Repo) origin() string {
+	scheme := r.Scheme
+	if scheme == "" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// CommitURL links to a commit.
+func (r Repo) CommitURL(hash string) string {
+	if r.Kind == GitLab {
+		return r.WebURL() + "/-/commit/" + hash
+	}
+	return r.WebURL() + "/commit/" + hash
+}
+
+var fullOID = regexp.MustCompile(`^[0-9a-f]{40}([0-9a-f]{24})?$`)
+
+// FileURL links to a file at ref (a branch, tag or commit), at line when it
+// is positive.
+func (r Repo) FileURL(ref, file string, line int) string {
+	escaped := escapePath(file)
+	var u string
+	switch r.Kind {
+	case GitLab:
+		u = r.WebURL() + "/-/blob/" + escapePath(ref) + "/" + escaped
+	case Gitea:
+		kind := "branch"
+		if fullOID.MatchString(ref) {
+			kind = "commit"
+		}
+		u = r.WebURL() + "/src/" + kind + "/" + escapePath(ref) + "/" + escaped
+	default:
+		u = r.WebURL() + "/blob/" + escapePath(ref) + "/" + escaped
+	}
+	if line > 0 {
+		u += fmt.Sprintf("#L%d", line)
+	}
+	return u
+}
+
+func escapePath(p string) string {
+	parts := strings.Split(p, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+// MergeRequestURL links to merge request number.
+func (r Repo) MergeRequestURL(number int) string {
+	switch r.Kind {
+	case GitLab:
+		return fmt.Sprintf("%s/-/merge_requests/%d", r.WebURL(), number)
+	case Gitea:
+		return fmt.Sprintf("%s/pulls/%d", r.WebURL(), number)
+	default:
+		return fmt.Sprintf("%s/pull/%d", r.WebURL(), number)
+	}
+}
+
+// HeadRef is the ref in the base repository that holds a merge request's
+// head commit, fetchable whether the branch lives in a fork or not.
+func (r Repo) HeadRef(number int) string {
+	if r.Kind == GitLab {
+		return fmt.Sprintf("refs/merge-requests/%d/head", number)
+	}
+	return fmt.Sprintf("refs/pull/%d/head", number)
+}
+
+// ErrUnknownForge is returned for a remote on a host no forge is detected for.
+var ErrUnknownForge = errors.New("unknown forge")
+
+// ParseRemote parses a git remote URL into the repository it names. A
+// non-empty kind overrides detection from the host, for self-hosted forges.
+func ParseRemote(remoteURL string, kind Kind) (Repo, error) {
+	raw := strings.TrimSpace(remoteURL)
+	if raw == "" {
+		return Repo{}, errors.New("remote URL is empty")
+	}
+	var host, repoPath, scheme string
+	if before, after, ok := strings.Cut(raw, ":"); ok && strings.Contains(before, "@") && !strings.Contains(before, "/") {
+		// scp-like: git@host:owner/repo.git
+		host = before[strings.LastIndex(before, "@")+1:]
+		repoPath = after
+	} else {
+		u, err := url.Parse(raw)
+		if err != nil || u.Hostname() == "" {
+			return Repo{}, fmt.Errorf("remote URL %q is not a hosted repository", remoteURL)
+		}
+		host, repoPath = u.Hostname(), u.Path
+		if u.Scheme == "http" || u.Scheme == "https" {
+			host, scheme = u.Host, u.Scheme
+		}
+	}
+	if kind == "" {
+		detected, ok := DetectKind(hostname(host))
+		if !ok {
+			return Repo{}, fmt.Errorf("%w: %s (set git config sidecar.forge to github, gitlab or gitea)", ErrUnknownForge, hostname(host))
+		}
+		kind = detected
+	}
+	repoPath = strings.TrimSuffix(strings.Trim(path.Clean("/"+repoPath), "/"), ".git")
+	parts := strings.Split(repoPath, "/")
+	if len(parts) < 2 || (kind != GitLab && len(parts) != 2) || slices.Contains(parts, "") {
+		return Repo{}, fmt.Errorf("remote URL %q has no owner/repository identity", remoteURL)
+	}
+	if scheme == "https" {
+		scheme = ""
+	}
+	return Repo{Kind: kind, Host: host, Path: repoPath, Scheme: scheme}, nil
+}
+
+// State is a merge request's lifecycle state, spelled as GitHub reports it.
+type State string
+
+const (
+	StateOpen   State = "OPEN"
+	StateMerged State = "MERGED"
+	StateClosed State = "CLOSED"
+)
+
+// MergeRequest is one merge request, in the terms all forges share.
+type MergeRequest struct {
+	Number    int
+	ID        string // Forge-wide identity that survives renumbering
+	URL       string
+	Title     string
+	Author    string
+	HeadRef   string
+	HeadOID   string
+	HeadOwner string
+	HeadRepo  string // owner/name of the repository holding HeadRef, when known
+	BaseRef   string
+	State     State
+	Draft     bool
+	CreatedAt string // RFC 3339
+	MergedAt  string
+	// MergeOID is the commit that landed the change on the base branch.
+	MergeOID string
+}
+
+// Head names the branch a merge request is opened from. Owner and Repo name
+// the fork that holds it; both are empty for a branch of the base repository.
+type Head struct {
+	Owner string
+	Repo  string // owner/name, for forges that address forks by project
+	Ref   string
+}
+
+// CreateRequest is a merge request to open.
+type CreateRequest struct {
+	Title string
+	Body  string
+	Head  Head
+	Base  string
+}
+
+// Forge is the merge request API of one repository.
+type Forge interface {
+	Repo() Repo
+	// ListOpen returns up to limit open merge requests, newest first.
+	ListOpen(ctx context.Context, limit int) ([]MergeRequest, error)
+	// Find returns the merge request from head into base in any state, or nil
+	// when there is none.
+	Find(ctx context.Context, head Head, base string) (*MergeRequest, error)
+	Create(ctx context.Context, req CreateRequest) (MergeRequest, error)
+	Get(ctx context.Context, number int) (MergeRequest, error)
+	// Checks lists the CI checks reported against commit oid.
+	Checks(ctx context.Context, oid string) ([]Check, error)
+	// CheckLog returns the log of the check whose ID is id, or ErrNoLog.
+	CheckLog(ctx context.Context, id string) (string, error)
+	// ReviewThreads lists the review threads on merge request number's diff.
+	ReviewThreads(ctx context.Context, number int) ([]ReviewThread, error)
+	ReplyToThread(ctx context.Context, number int, thread ReviewThread, body string) error
+	// ResolveThread marks a thread resolved, or returns ErrNotSupported.
+	ResolveThread(ctx context.Context, number int, thread ReviewThread) error
+}
+
+// New returns the forge for repo. dir is the worktree CLI transports run in.
+func New(repo Repo, dir string) Forge {
+	switch repo.Kind {
+	case GitLab:
+		return newGitLab(repo, dir)
+	case Gitea:
+		return newGitea(repo)
+	default:
+		return &gitHub{repo: repo, dir: dir}
+	}
+}
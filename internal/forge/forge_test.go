@@ -0,0 +1,125 @@
+package forge
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseRemoteDetectsForge(t *testing.T) {
+	for _, tc := range []struct {
+		url  string
+		want Repo
+	}{
+		{"git@github.com:owner/repo.git", Repo{Kind: GitHub, Host: "github.com", Path: "owner/repo"}},
+		{"https://github.com/owner/repo", Repo{Kind: GitHub, Host: "github.com", Path: "owner/repo"}},
+		{"ssh://git@gitlab.com/group/sub/app.git", Repo{Kind: GitLab, Host: "gitlab.com", Path: "group/sub/app"}},
+		{"https://gitlab.example.com:8443/team/app.git", Repo{Kind: GitLab, Host: "gitlab.example.com:8443", Path: "team/app"}},
+		{"git@codeberg.org:someone/tool.git", Repo{Kind: Gitea, Host: "codeberg.org", Path: "someone/tool"}},
+		{"http://gitea.local/org/svc.git", Repo{Kind: Gitea, Host: "gitea.local", Path: "org/svc", Scheme: "http"}},
+	} {
+		got, err := ParseRemote(tc.url, "")
+		if err != nil || got != tc.want {
+			t.Errorf("ParseRemote(%q) = %+v, %v; want %+v", tc.url, got, err, tc.want)
+		}
+	}
+
+	if _, err := ParseRemote("git@git.example.com:team/app.git", ""); !errors.Is(err, ErrUnknownForge) {
+		t.Fatalf("unknown host err = %v", err)
+	}
+	if got, err := ParseRemote("git@git.example.com:team/app.git", Gitea); err != nil || got.Kind != Gitea {
+		t.Fatalf("configured kind = %+v, %v", got, err)
+	}
+	for _, bad := range []string{"/tmp/repo.git", "https://github.com/owner", "https://github.com/a/b/c"} {
+		if _, err := ParseRemote(bad, ""); err == nil {
+			t.Errorf("ParseRemote(%q) accepted", bad)
+		}
+	}
+}
+
+func TestRepoURLs(t *testing.T) {
+	const oid = "0123456789abcdef0123456789abcdef01234567"
+	for _, tc := range []struct {
+		repo                Repo
+		commit, file, mr, h string
+	}{
+		{
+			Repo{Kind: GitHub, Host: "github.com", Path: "o/r"},
+			"https://github.com/o/r/commit/" + oid,
+			"https://github.com/o/r/blob/main/dir/a%20b.go#L12",
+			"https://github.com/o/r/pull/7",
+			"refs/pull/7/head",
+		},
+		{
+			Repo{Kind: GitLab, Host: "gitlab.com", Path: "g/s/r"},
+			"https://gitlab.com/g/s/r/-/commit/" + oid,
+			"https://gitlab.com/g/s/r/-/blob/main/dir/a%20b.go#L12",
+			"https://gitlab.com/g/s/r/-/merge_requests/7",
+			"refs/merge-requests/7/head",
+		},
+		{
+			Repo{Kind: Gitea, Host: "codeberg.org", Path: "o/r"},
+			"https://codeberg.org/o/r/commit/" + oid,
+			"https://codeberg.org/o/r/src/branch/main/dir/a%20b.go#L12",
+			"https://codeberg.org/o/r/pulls/7",
+			"refs/pull/7/head",
+		},
+	} {
+		if got := tc.repo.CommitURL(oid); got != tc.commit {
+			t.Errorf("%s CommitURL = %q", tc.repo.Kind, got)
+		}
+		if got := tc.repo.FileURL("main", "dir/a b.go", 12); got != tc.file {
+			t.Errorf("%s FileURL = %q", tc.repo.Kind, got)
+		}
+		if got := tc.repo.MergeRequestURL(7); got != tc.mr {
+			t.Errorf("%s MergeRequestURL = %q", tc.repo.Kind, got)
+		}
+		if got := tc.repo.HeadRef(7); got != tc.h {
+			t.Errorf("%s HeadRef = %q", tc.repo.Kind, got)
+		}
+	}
+	gitea := Repo{Kind: Gitea, Host: "codeberg.org", Path: "o/r"}
+	if got := gitea.FileURL(oid, "a.go", 0); got != "https://codeberg.org/o/r/src/commit/"+oid+"/a.go" {
+		t.Errorf("Gitea FileURL at a commit = %q", got)
+	}
+}
+
+// installFakeCLI puts an executable shell script called name first on PATH.
+func installFakeCLI(t *testing.T, name, body string) {
+	t.Helper()
+	dir := t.TempDir()
+	script := "#!/bin/sh\nset -eu\n" + body + "\n"
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestGitHubCreateAndEnterpriseRepoSelector(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("FAKE_GH_LOG", log)
+	installFakeCLI(t, "gh", `
+printf '%s\n' "$*" >> "$FAKE_GH_LOG"
+case "$1 $2" in
+"pr create") echo 'https://ghe.example.com/base/repo/pull/5' ;;
+"pr view") printf '%s\n' '{"number":5,"url":"https://ghe.example.com/base/repo/pull/5","id":"PR_5","headRefName":"topic","headRefOid":"abc","baseRefName":"main","state":"OPEN","headRepository":{"nameWithOwner":"me/repo"},"headRepositoryOwner":{"login":"me"},"mergeCommit":null}' ;;
+esac
+`)
+	repo := Repo{Kind: GitHub, Host: "ghe.example.com", Path: "base/repo"}
+	mr, err := New(repo, t.TempDir()).Create(context.Background(), CreateRequest{Title: "t", Body: "b", Head: Head{Owner: "me", Ref: "topic"}, Base: "main"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mr.Number != 5 || mr.State != StateOpen || mr.HeadOwner != "me" || mr.HeadRepo != "me/repo" {
+		t.Fatalf("created = %+v", mr)
+	}
+	calls, _ := os.ReadFile(log)
+	for _, want := range []string{"pr create --repo ghe.example.com/base/repo", "--head me:topic", "pr view https://ghe.example.com/base/repo/pull/5 --repo ghe.example.com/base/repo"} {
+		if !strings.Contains(string(calls), want) {
+			t.Fatalf("gh calls missing %q:\n%s", want, calls)
+		}
+	}
+}
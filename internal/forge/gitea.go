@@ -0,0 +1,250 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// gitea speaks the v1 REST API shared by Gitea and Forgejo. GITEA_TOKEN (or
+// FORGEJO_TOKEN) authenticates; without one, public repositories still list.
+type gitea struct {
+	repo Repo
+	api  api
+}
+
+func newGitea(repo Repo) *gitea {
+	token := os.Getenv("GITEA_TOKEN")
+	if token == "" {
+		token = os.Getenv("FORGEJO_TOKEN")
+	}
+	a := &httpAPI{base: repo.origin() + "/api/v1", header: "Authorization"}
+	if token != "" {
+		a.value = "token " + token
+	}
+	return &gitea{repo: repo, api: a}
+}
+
+type giteaBranch struct {
+	Ref  string `json:"ref"`
+	SHA  string `json:"sha"`
+	Repo *struct {
+		FullName string `json:"full_name"`
+		Owner    struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+	} `json:"repo"`
+}
+
+type giteaPR struct {
+	ID             int64       `json:"id"`
+	Number         int         `json:"number"`
+	Title          string      `json:"title"`
+	HTMLURL        string      `json:"html_url"`
+	State          string      `json:"state"`
+	Draft          bool        `json:"draft"`
+	Merged         bool        `json:"merged"`
+	MergedAt       string      `json:"merged_at"`
+	MergeCommitSHA string      `json:"merge_commit_sha"`
+	CreatedAt      string      `json:"created_at"`
+	Head           giteaBranch `json:"head"`
+	Base           giteaBranch `json:"base"`
+	User           struct {
+		Login string `json:"login"`
+	} `json:"user"`
+}
+
+func (pr giteaPR) mergeRequest() MergeRequest {
+	out := MergeRequest{
+		Number: pr.Number, ID: strconv.FormatInt(pr.ID, 10), URL: pr.HTMLURL, Title: pr.Title,
+		Author: pr.User.Login, HeadRef: pr.Head.Ref, HeadOID: pr.Head.SHA, BaseRef: pr.Base.Ref,
+		// Gitea marks drafts by title prefix; Forgejo reports them too.
+		Draft:     pr.Draft || hasWIPPrefix(pr.Title),
+		CreatedAt: pr.CreatedAt, MergedAt: pr.MergedAt, MergeOID: pr.MergeCommitSHA,
+	}
+	if pr.Head.Repo != nil {
+		out.HeadRepo, out.HeadOwner = pr.Head.Repo.FullName, pr.Head.Repo.Owner.Login
+	}
+	switch {
+	case pr.Merged:
+		out.State = StateMerged
+	case pr.State == "open":
+		out.State = StateOpen
+	default:
+		out.State = StateClosed
+	}
+	return out
+}
+
+func hasWIPPrefix(title string) bool {
+	lower := strings.ToLower(title)
+	return strings.HasPrefix(lower, "wip:") || strings.HasPrefix(lower, "[wip]")
+}
+
+func (g *gitea) Repo() Repo { return g.repo }
+
+func (g *gitea) pullsPath() string {
+	return "/repos/" + url.PathEscape(g.repo.Owner()) + "/" + url.PathEscape(g.repo.Name()) + "/pulls"
+}
+
+func (g *gitea) list(ctx context.Context, state string, limit int) ([]giteaPR, error) {
+	q := url.Values{"state": {state}, "sort": {"newest"}, "limit": {strconv.Itoa(limit)}}
+	var raw []giteaPR
+	if err := g.api.call(ctx, http.MethodGet, g.pullsPath()+"?"+q.Encode(), nil, &raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+func (g *gitea) ListOpen(ctx context.Context, limit int) ([]MergeRequest, error) {
+	raw, err := g.list(ctx, "open", limit)
+	if err != nil {
+		return nil, err
+	}
+	mrs := make([]MergeRequest, 0, len(raw))
+	for _, pr := range raw {
+		mrs = append(mrs, pr.mergeRequest())
+	}
+	return mrs, nil
+}
+
+func (g *gitea) Find(ctx context.Context, head Head, base string) (*MergeRequest, error) {
+	// The list API has no head filter; recent requests are enough to find
+	// the one a workspace branch opened.
+	raw, err := g.list(ctx, "all", 50)
+	if err != nil {
+		return nil, err
+	}
+	for _, pr := range raw {
+		mr := pr.mergeRequest()
+		if mr.HeadRef != head.Ref || mr.BaseRef != base {
+			continue
+		}
+		if head.Owner != "" && !strings.EqualFold(mr.HeadOwner, head.Owner) {
+			continue
+		}
+		return &mr, nil
+	}
+	return nil, nil
+}
+
+func (g *gitea) Create(ctx context.Context, req CreateRequest) (MergeRequest, error) {
+	head := req.Head.Ref
+	if req.Head.Owner != "" && !strings.EqualFold(req.Head.Owner, g.repo.Owner()) {
+		head = req.Head.Owner + ":" + req.Head.Ref
+	}
+	body := map[string]string{"head": head, "base": req.Base, "title": req.Title, "body": req.Body}
+	var created giteaPR
+	if err := g.api.call(ctx, http.MethodPost, g.pullsPath(), body, &created); err != nil {
+		return MergeRequest{}, err
+	}
+	return created.mergeRequest(), nil
+}
+
+func (g *gitea) Get(ctx context.Context, number int) (MergeRequest, error) {
+	var raw giteaPR
+	if err := g.api.call(ctx, http.MethodGet, fmt.Sprintf("%s/%d", g.pullsPath(), number), nil, &raw); err != nil {
+		return MergeRequest{}, err
+	}
+	return raw.mergeRequest(), nil
+}
+
+// Checks reads the combined commit status. Gitea and Forgejo Actions report
+// through it as well, but neither serves job logs over the API.
+func (g *gitea) Checks(ctx context.Context, oid string) ([]Check, error) {
+	var combined struct {
+		Statuses []struct {
+			Context   string `json:"context"`
+			Status    string `json:"status"`
+			TargetURL string `json:"target_url"`
+		} `json:"statuses"`
+	}
+	path := "/repos/" + url.PathEscape(g.repo.Owner()) + "/" + url.PathEscape(g.repo.Name()) + "/commits/" + url.PathEscape(oid) + "/status"
+	if err := g.api.call(ctx, http.MethodGet, path, nil, &combined); err != nil {
+		return nil, err
+	}
+	checks := make([]Check, 0, len(combined.Statuses))
+	for _, s := range combined.Statuses {
+		checks = append(checks, commitStateCheck(s.Context, s.Status, s.TargetURL))
+	}
+	return checks, nil
+}
+
+func (g *gitea) CheckLog(context.Context, string) (string, error) {
+	return "", ErrNoLog
+}
+
+type giteaReviewComment struct {
+	ID               int64  `json:"id"`
+	Body             string `json:"body"`
+	Path             string `json:"path"`
+	Position         int    `json:"position"`
+	OriginalPosition int    `json:"original_position"`
+	HTMLURL          string `json:"html_url"`
+	CreatedAt        string `json:"created_at"`
+	User             struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	Resolver *struct {
+		Login string `json:"login"`
+	} `json:"resolver"`
+}
+
+// ReviewThreads gathers the comments of every review. Gitea has no thread
+// object: a conversation is the comments left on one line of one file.
+func (g *gitea) ReviewThreads(ctx context.Context, number int) ([]ReviewThread, error) {
+	reviewsPath := fmt.Sprintf("%s/%d/reviews", g.pullsPath(), number)
+	var reviews []struct {
+		ID int64 `json:"id"`
+	}
+	if err := g.api.call(ctx, http.MethodGet, reviewsPath, nil, &reviews); err != nil {
+		return nil, err
+	}
+	var threads []ReviewThread
+	index := map[string]int{}
+	for _, r := range reviews {
+		var comments []giteaReviewComment
+		if err := g.api.call(ctx, http.MethodGet, fmt.Sprintf("%s/%d/comments", reviewsPath, r.ID), nil, &comments); err != nil {
+			return nil, err
+		}
+		for _, c := range comments {
+			line, onOld := c.Position, false
+			if line == 0 {
+				line, onOld = c.OriginalPosition, true
+			}
+			key := fmt.Sprintf("%s:%d:%t", c.Path, line, onOld)
+			i, ok := index[key]
+			if !ok {
+				i = len(threads)
+				index[key] = i
+				threads = append(threads, ReviewThread{ID: strconv.FormatInt(c.ID, 10), Path: c.Path, Line: line, OnOld: onOld})
+			}
+			threads[i].Resolved = c.Resolver != nil
+			threads[i].Comments = append(threads[i].Comments, ReviewComment{
+				ID: strconv.FormatInt(c.ID, 10), Author: c.User.Login, Body: c.Body, CreatedAt: c.CreatedAt, URL: c.HTMLURL,
+			})
+		}
+	}
+	return threads, nil
+}
+
+// ReplyToThread leaves a comment on the thread's line, which Gitea shows as
+// part of the same conversation.
+func (g *gitea) ReplyToThread(ctx context.Context, number int, thread ReviewThread, body string) error {
+	comment := map[string]any{"path": thread.Path, "body": body}
+	if thread.OnOld {
+		comment["old_position"] = thread.Line
+	} else {
+		comment["new_position"] = thread.Line
+	}
+	review := map[string]any{"event": "COMMENT", "body": "", "comments": []map[string]any{comment}}
+	return g.api.call(ctx, http.MethodPost, fmt.Sprintf("%s/%d/reviews", g.pullsPath(), number), review, nil)
+}
+
+func (g *gitea) ResolveThread(context.Context, int, ReviewThread) error {
+	return fmt.Errorf("resolving a review conversation through the Gitea API: %w", ErrNotSupported)
+}
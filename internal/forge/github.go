@@ -0,0 +1,355 @@
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// gitHub drives the gh CLI, which owns authentication for github.com and
+// GitHub Enterprise hosts alike.
+type gitHub struct {
+	repo Repo
+	dir  string
+}
+
+// ghFields are the `--json` fields a merge request is read with.
+const ghFields = "number,url,id,headRefName,headRefOid,baseRefName,state,mergedAt,headRepository,headRepositoryOwner,mergeCommit"
+
+type ghPR struct {
+	Number         int    `json:"number"`
+	URL            string `json:"url"`
+	ID             string `json:"id"`
+	Title          string `json:"title"`
+	HeadRefName    string `json:"headRefName"`
+	HeadRefOID     string `json:"headRefOid"`
+	BaseRefName    string `json:"baseRefName"`
+	State          string `json:"state"`
+	IsDraft        bool   `json:"isDraft"`
+	CreatedAt      string `json:"createdAt"`
+	MergedAt       string `json:"mergedAt"`
+	HeadRepository struct {
+		NameWithOwner string `json:"nameWithOwner"`
+	} `json:"headRepository"`
+	HeadRepositoryOwner struct {
+		Login string `json:"login"`
+	} `json:"headRepositoryOwner"`
+	Author struct {
+		Login string `json:"login"`
+	} `json:"author"`
+	MergeCommit struct {
+		OID string `json:"oid"`
+	} `json:"mergeCommit"`
+}
+
+func (p ghPR) mergeRequest() MergeRequest {
+	return MergeRequest{
+		Number: p.Number, ID: p.ID, URL: p.URL, Title: p.Title, Author: p.Author.Login,
+		HeadRef: p.HeadRefName, HeadOID: p.HeadRefOID, HeadOwner: p.HeadRepositoryOwner.Login,
+		HeadRepo: p.HeadRepository.NameWithOwner, BaseRef: p.BaseRefName,
+		State: State(strings.ToUpper(p.State)), Draft: p.IsDraft, CreatedAt: p.CreatedAt,
+		MergedAt: p.MergedAt, MergeOID: p.MergeCommit.OID,
+	}
+}
+
+func (g *gitHub) Repo() Repo { return g.repo }
+
+// repoArg is the --repo selector; gh takes a host prefix for Enterprise.
+func (g *gitHub) repoArg() string {
+	if g.repo.Host == "" || strings.EqualFold(g.repo.Host, "github.com") {
+		return g.repo.Path
+	}
+	return g.repo.Host + "/" + g.repo.Path
+}
+
+func (g *gitHub) gh(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "gh", args...)
+	cmd.Dir = g.dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("gh %s: %s: %w", strings.Join(args, " "), msg, err)
+	}
+	return out, nil
+}
+
+func (g *gitHub) ListOpen(ctx context.Context, limit int) ([]MergeRequest, error) {
+	out, err := g.gh(ctx, "pr", "list", "--repo", g.repoArg(),
+		"--json", "number,id,title,headRefName,headRefOid,baseRefName,headRepository,headRepositoryOwner,url,isDraft,createdAt,author",
+		"--limit", strconv.Itoa(limit))
+	if err != nil {
+		return nil, err
+	}
+	var prs []ghPR
+	if err := json.Unmarshal(out, &prs); err != nil {
+		return nil, fmt.Errorf("parse pr list: %w", err)
+	}
+	mrs := make([]MergeRequest, 0, len(prs))
+	for _, pr := range prs {
+		mr := pr.mergeRequest()
+		mr.State = StateOpen
+		mrs = append(mrs, mr)
+	}
+	return mrs, nil
+}
+
+func (g *gitHub) Find(ctx context.Context, head Head, base string) (*MergeRequest, error) {
+	// gh pr list explicitly does not accept owner:branch for --head. Ask for
+	// the supported bare branch selector, then disambiguate forks from the
+	// structured owner/repository fields.
+	out, err := g.gh(ctx, "pr", "list", "--state", "all", "--head", head.Ref, "--base", base, "--limit", "100", "--json", ghFields, "--repo", g.repoArg())
+	if err != nil {
+		return nil, err
+	}
+	var prs []ghPR
+	if err := json.Unmarshal(out, &prs); err != nil {
+		return nil, fmt.Errorf("parse existing pull request: %w", err)
+	}
+	for _, raw := range prs {
+		mr := raw.mergeRequest()
+		if mr.HeadRef != head.Ref || mr.BaseRef != base {
+			continue
+		}
+		if head.Owner != "" && !strings.EqualFold(mr.HeadOwner, head.Owner) {
+			continue
+		}
+		return &mr, nil
+	}
+	return nil, nil
+}
+
+func (g *gitHub) Create(ctx context.Context, req CreateRequest) (MergeRequest, error) {
+	head := req.Head.Ref
+	if req.Head.Owner != "" {
+		head = req.Head.Owner + ":" + req.Head.Ref
+	}
+	out, err := g.gh(ctx, "pr", "create", "--repo", g.repoArg(), "--title", req.Title, "--body", req.Body, "--base", req.Base, "--head", head)
+	if err != nil {
+		return MergeRequest{}, err
+	}
+	return g.view(ctx, strings.TrimSpace(string(out)))
+}
+
+func (g *gitHub) Get(ctx context.Context, number int) (MergeRequest, error) {
+	return g.view(ctx, strconv.Itoa(number))
+}
+
+// view reads one pull request by number or URL.
+func (g *gitHub) view(ctx context.Context, spec string) (MergeRequest, error) {
+	out, err := g.gh(ctx, "pr", "view", spec, "--repo", g.repoArg(), "--json", ghFields)
+	if err != nil {
+		return MergeRequest{}, err
+	}
+	var raw ghPR
+	if err := json.Unmarshal(out, &raw); err != nil {
+		return MergeRequest{}, fmt.Errorf("parse pull request: %w", err)
+	}
+	return raw.mergeRequest(), nil
+}
+
+// ghCheckRun is a check run as the REST API reports it. Runs posted by
+// GitHub Actions are jobs, and a job's ID is its run's ID.
+type ghCheckRun struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+	HTMLURL    string `json:"html_url"`
+	App        struct {
+		Slug string `json:"slug"`
+	} `json:"app"`
+}
+
+func (r ghCheckRun) check() Check {
+	c := Check{Name: r.Name, URL: r.HTMLURL}
+	if r.App.Slug == "github-actions" {
+		c.ID = strconv.FormatInt(r.ID, 10)
+	}
+	switch r.Status {
+	case "completed":
+		switch r.Conclusion {
+		case "success", "neutral", "skipped":
+			c.State = CheckPassed
+		default:
+			c.State = CheckFailed
+		}
+	case "in_progress":
+		c.State = CheckRunning
+	default:
+		c.State = CheckQueued
+	}
+	return c
+}
+
+// commitStateCheck maps a commit status, which GitHub and Gitea share.
+func commitStateCheck(name, state, url string) Check {
+	c := Check{Name: name, URL: url}
+	switch state {
+	case "success", "warning":
+		c.State = CheckPassed
+	case "failure", "error":
+		c.State = CheckFailed
+	default:
+		c.State = CheckRunning
+	}
+	return c
+}
+
+// api runs `gh api` against the repository's host.
+func (g *gitHub) api(ctx context.Context, path string, out any) error {
+	data, err := g.gh(ctx, "api", "--hostname", g.hostname(), path)
+	if err != nil {
+		return err
+	}
+	return decodeResponse(data, out, "gh api "+path)
+}
+
+func (g *gitHub) Checks(ctx context.Context, oid string) ([]Check, error) {
+	commit := "repos/" + g.repo.Path + "/commits/" + oid
+	var runs struct {
+		CheckRuns []ghCheckRun `json:"check_runs"`
+	}
+	if err := g.api(ctx, commit+"/check-runs?per_page=100", &runs); err != nil {
+		return nil, err
+	}
+	// External CI still reports through the older commit status API.
+	var combined struct {
+		Statuses []struct {
+			Context   string `json:"context"`
+			State     string `json:"state"`
+			TargetURL string `json:"target_url"`
+		} `json:"statuses"`
+	}
+	if err := g.api(ctx, commit+"/status?per_page=100", &combined); err != nil {
+		return nil, err
+	}
+	checks := make([]Check, 0, len(runs.CheckRuns)+len(combined.Statuses))
+	for _, r := range runs.CheckRuns {
+		checks = append(checks, r.check())
+	}
+	for _, s := range combined.Statuses {
+		checks = append(checks, commitStateCheck(s.Context, s.State, s.TargetURL))
+	}
+	return checks, nil
+}
+
+func (g *gitHub) CheckLog(ctx context.Context, id string) (string, error) {
+	if id == "" {
+		return "", ErrNoLog
+	}
+	out, err := g.gh(ctx, "run", "view", "--repo", g.repoArg(), "--job", id, "--log-failed")
+	if err != nil {
+		return "", err
+	}
+	if len(bytes.TrimSpace(out)) > 0 {
+		return string(out), nil
+	}
+	// A job that did not fail, or failed outside a step, has no failed-step
+	// log; the whole log is the next best thing.
+	out, err = g.gh(ctx, "run", "view", "--repo", g.repoArg(), "--job", id, "--log")
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// hostname is the GitHub host gh talks to.
+func (g *gitHub) hostname() string {
+	if g.repo.Host == "" {
+		return "github.com"
+	}
+	return g.repo.Host
+}
+
+// graphql runs a GraphQL query through `gh api graphql`. vars are gh's own
+// -f (string) and -F (typed) field flags.
+func (g *gitHub) graphql(ctx context.Context, query string, out any, vars ...string) error {
+	args := append([]string{"api", "graphql", "--hostname", g.hostname(), "-f", "query=" + query}, vars...)
+	data, err := g.gh(ctx, args...)
+	if err != nil {
+		return err
+	}
+	return decodeResponse(data, out, "gh api graphql")
+}
+
+const ghReviewThreadsQuery = `query($owner: String!, $name: String!, $number: Int!) { repository(owner: $owner, name: $name) { pullRequest(number: $number) { reviewThreads(first: 100) { nodes { id isResolved isOutdated path line originalLine diffSide comments(first: 50) { nodes { id body createdAt url author { login } } } } } } } }`
+
+type ghReviewThread struct {
+	ID           string `json:"id"`
+	IsResolved   bool   `json:"isResolved"`
+	IsOutdated   bool   `json:"isOutdated"`
+	Path         string `json:"path"`
+	Line         int    `json:"line"`
+	OriginalLine int    `json:"originalLine"`
+	DiffSide     string `json:"diffSide"`
+	Comments     struct {
+		Nodes []struct {
+			ID        string `json:"id"`
+			Body      string `json:"body"`
+			CreatedAt string `json:"createdAt"`
+			URL       string `json:"url"`
+			Author    struct {
+				Login string `json:"login"`
+			} `json:"author"`
+		} `json:"nodes"`
+	} `json:"comments"`
+}
+
+func (t ghReviewThread) thread() ReviewThread {
+	out := ReviewThread{
+		ID: t.ID, Path: t.Path, Line: t.Line, OnOld: t.DiffSide == "LEFT",
+		Resolved: t.IsResolved, Outdated: t.IsOutdated,
+	}
+	if out.Line == 0 {
+		// An outdated thread has no line in the current diff.
+		out.Line = t.OriginalLine
+	}
+	for _, c := range t.Comments.Nodes {
+		out.Comments = append(out.Comments, ReviewComment{ID: c.ID, Author: c.Author.Login, Body: c.Body, CreatedAt: c.CreatedAt, URL: c.URL})
+	}
+	return out
+}
+
+func (g *gitHub) ReviewThreads(ctx context.Context, number int) ([]ReviewThread, error) {
+	var resp struct {
+		Data struct {
+			Repository struct {
+				PullRequest struct {
+					ReviewThreads struct {
+						Nodes []ghReviewThread `json:"nodes"`
+					} `json:"reviewThreads"`
+				} `json:"pullRequest"`
+			} `json:"repository"`
+		} `json:"data"`
+	}
+	err := g.graphql(ctx, ghReviewThreadsQuery, &resp,
+		"-f", "owner="+g.repo.Owner(), "-f", "name="+g.repo.Name(), "-F", "number="+strconv.Itoa(number))
+	if err != nil {
+		return nil, err
+	}
+	nodes := resp.Data.Repository.PullRequest.ReviewThreads.Nodes
+	threads := make([]ReviewThread, 0, len(nodes))
+	for _, n := range nodes {
+		threads = append(threads, n.thread())
+	}
+	return threads, nil
+}
+
+func (g *gitHub) ReplyToThread(ctx context.Context, _ int, thread ReviewThread, body string) error {
+	const mutation = `mutation($thread: ID!, $body: String!) { addPullRequestReviewThreadReply(input: {pullRequestReviewThreadId: $thread, body: $body}) { comment { id } } }`
+	return g.graphql(ctx, mutation, nil, "-f", "thread="+thread.ID, "-f", "body="+body)
+}
+
+func (g *gitHub) ResolveThread(ctx context.Context, _ int, thread ReviewThread) error {
+	const mutation = `mutation($thread: ID!) { resolveReviewThread(input: {threadId: $thread}) { thread { id } } }`
+	return g.graphql(ctx, mutation, nil, "-f", "thread="+thread.ID)
+}
@@ -0,0 +1,318 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// gitLab speaks GitLab's v4 REST API: directly when GITLAB_TOKEN is set, and
+// otherwise through glab when it is installed, so a glab login is enough.
+type gitLab struct {
+	repo Repo
+	api  api
+}
+
+func newGitLab(repo Repo, dir string) *gitLab {
+	token := os.Getenv("GITLAB_TOKEN")
+	if token == "" {
+		if _, err := exec.LookPath("glab"); err == nil {
+			return &gitLab{repo: repo, api: &glabAPI{host: repo.Host, dir: dir}}
+		}
+	}
+	return &gitLab{repo: repo, api: &httpAPI{base: repo.origin() + "/api/v4", header: "PRIVATE-TOKEN", value: token}}
+}
+
+type gitLabMR struct {
+	ID              int64  `json:"id"`
+	IID             int    `json:"iid"`
+	Title           string `json:"title"`
+	WebURL          string `json:"web_url"`
+	State           string `json:"state"`
+	Draft           bool   `json:"draft"`
+	SourceBranch    string `json:"source_branch"`
+	TargetBranch    string `json:"target_branch"`
+	SHA             string `json:"sha"`
+	MergeCommitSHA  string `json:"merge_commit_sha"`
+	SquashCommitSHA string `json:"squash_commit_sha"`
+	MergedAt        string `json:"merged_at"`
+	CreatedAt       string `json:"created_at"`
+	SourceProjectID int64  `json:"source_project_id"`
+	TargetProjectID int64  `json:"target_project_id"`
+	Author          struct {
+		Username string `json:"username"`
+	} `json:"author"`
+}
+
+// mergeRequest converts mr. headRepo is the source project's path when the
+// caller knows it; a merge request within one project names its own.
+func (mr gitLabMR) mergeRequest(base Repo, headRepo string) MergeRequest {
+	if headRepo == "" && mr.SourceProjectID == mr.TargetProjectID {
+		headRepo = base.Path
+	}
+	out := MergeRequest{
+		Number: mr.IID, ID: strconv.FormatInt(mr.ID, 10), URL: mr.WebURL, Title: mr.Title,
+		Author: mr.Author.Username, HeadRef: mr.SourceBranch, HeadOID: mr.SHA,
+		HeadRepo: headRepo, BaseRef: mr.TargetBranch, Draft: mr.Draft,
+		CreatedAt: mr.CreatedAt, MergedAt: mr.MergedAt,
+	}
+	if headRepo != "" {
+		out.HeadOwner, _ = splitRepoPath(headRepo)
+	}
+	switch mr.State {
+	case "opened":
+		out.State = StateOpen
+	case "merged":
+		out.State = StateMerged
+		// A fast-forward merge has no merge commit; the head itself landed.
+		out.MergeOID = firstNonEmpty(mr.MergeCommitSHA, mr.SquashCommitSHA, mr.SHA)
+	default:
+		out.State = StateClosed
+	}
+	return out
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func projectPath(path string) string {
+	return "/projects/" + url.PathEscape(path)
+}
+
+func (g *gitLab) Repo() Repo { return g.repo }
+
+func (g *gitLab) ListOpen(ctx context.Context, limit int) ([]MergeRequest, error) {
+	q := url.Values{"state": {"opened"}, "order_by": {"created_at"}, "sort": {"desc"}, "per_page": {strconv.Itoa(limit)}}
+	var raw []gitLabMR
+	if err := g.api.call(ctx, http.MethodGet, projectPath(g.repo.Path)+"/merge_requests?"+q.Encode(), nil, &raw); err != nil {
+		return nil, err
+	}
+	mrs := make([]MergeRequest, 0, len(raw))
+	for _, mr := range raw {
+		mrs = append(mrs, mr.mergeRequest(g.repo, ""))
+	}
+	return mrs, nil
+}
+
+// headPath is the project holding head's branch.
+func (g *gitLab) headPath(head Head) string {
+	switch {
+	case head.Repo != "":
+		return head.Repo
+	case head.Owner != "" && !strings.EqualFold(head.Owner, g.repo.Owner()):
+		return head.Owner + "/" + g.repo.Name()
+	default:
+		return g.repo.Path
+	}
+}
+
+func (g *gitLab) projectID(ctx context.Context, path string) (int64, error) {
+	var project struct {
+		ID int64 `json:"id"`
+	}
+	if err := g.api.call(ctx, http.MethodGet, projectPath(path), nil, &project); err != nil {
+		return 0, err
+	}
+	return project.ID, nil
+}
+
+func (g *gitLab) Find(ctx context.Context, head Head, base string) (*MergeRequest, error) {
+	q := url.Values{"state": {"all"}, "source_branch": {head.Ref}, "target_branch": {base}, "per_page": {"100"}}
+	var raw []gitLabMR
+	if err := g.api.call(ctx, http.MethodGet, projectPath(g.repo.Path)+"/merge_requests?"+q.Encode(), nil, &raw); err != nil {
+		return nil, err
+	}
+	headPath := g.headPath(head)
+	var sourceID int64
+	if !strings.EqualFold(headPath, g.repo.Path) {
+		id, err := g.projectID(ctx, headPath)
+		if err != nil {
+			return nil, fmt.Errorf("resolve head project %s: %w", headPath, err)
+		}
+		sourceID = id
+	}
+	for _, mr := range raw {
+		if mr.SourceBranch != head.Ref || mr.TargetBranch != base {
+			continue
+		}
+		if sourceID != 0 && mr.SourceProjectID != sourceID {
+			continue
+		}
+		if sourceID == 0 && mr.SourceProjectID != mr.TargetProjectID {
+			continue
+		}
+		found := mr.mergeRequest(g.repo, headPath)
+		return &found, nil
+	}
+	return nil, nil
+}
+
+func (g *gitLab) Create(ctx context.Context, req CreateRequest) (MergeRequest, error) {
+	body := map[string]any{
+		"source_branch": req.Head.Ref,
+		"target_branch": req.Base,
+		"title":         req.Title,
+		"description":   req.Body,
+	}
+	// A merge request from a fork is opened on the fork's project, aimed at
+	// the base project.
+	project := g.repo.Path
+	headPath := g.headPath(req.Head)
+	if !strings.EqualFold(headPath, g.repo.Path) {
+		target, err := g.projectID(ctx, g.repo.Path)
+		if err != nil {
+			return MergeRequest{}, fmt.Errorf("resolve target project %s: %w", g.repo.Path, err)
+		}
+		body["target_project_id"] = target
+		project = headPath
+	}
+	var created gitLabMR
+	if err := g.api.call(ctx, http.MethodPost, projectPath(project)+"/merge_requests", body, &created); err != nil {
+		return MergeRequest{}, err
+	}
+	return created.mergeRequest(g.repo, headPath), nil
+}
+
+func (g *gitLab) Get(ctx context.Context, number int) (MergeRequest, error) {
+	var raw gitLabMR
+	if err := g.api.call(ctx, http.MethodGet, fmt.Sprintf("%s/merge_requests/%d", projectPath(g.repo.Path), number), nil, &raw); err != nil {
+		return MergeRequest{}, err
+	}
+	return raw.mergeRequest(g.repo, ""), nil
+}
+
+type gitLabJob struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	Status       string `json:"status"`
+	WebURL       string `json:"web_url"`
+	AllowFailure bool   `json:"allow_failure"`
+}
+
+func (j gitLabJob) check() Check {
+	c := Check{ID: strconv.FormatInt(j.ID, 10), Name: j.Name, URL: j.WebURL}
+	switch j.Status {
+	case "running":
+		c.State = CheckRunning
+	case "success", "skipped", "manual":
+		// A manual job waits for a person, not for CI; it must not hold the
+		// pipeline's result open.
+		c.State = CheckPassed
+	case "failed", "canceled":
+		c.State = CheckFailed
+		if j.AllowFailure {
+			c.State = CheckPassed
+		}
+	default:
+		c.State = CheckQueued
+	}
+	return c
+}
+
+func (g *gitLab) Checks(ctx context.Context, oid string) ([]Check, error) {
+	q := url.Values{"sha": {oid}, "order_by": {"id"}, "sort": {"desc"}, "per_page": {"1"}}
+	var pipelines []struct {
+		ID int64 `json:"id"`
+	}
+	if err := g.api.call(ctx, http.MethodGet, projectPath(g.repo.Path)+"/pipelines?"+q.Encode(), nil, &pipelines); err != nil {
+		return nil, err
+	}
+	if len(pipelines) == 0 {
+		return nil, nil
+	}
+	var jobs []gitLabJob
+	path := fmt.Sprintf("%s/pipelines/%d/jobs?per_page=100", projectPath(g.repo.Path), pipelines[0].ID)
+	if err := g.api.call(ctx, http.MethodGet, path, nil, &jobs); err != nil {
+		return nil, err
+	}
+	checks := make([]Check, 0, len(jobs))
+	for _, j := range jobs {
+		checks = append(checks, j.check())
+	}
+	return checks, nil
+}
+
+func (g *gitLab) CheckLog(ctx context.Context, id string) (string, error) {
+	if id == "" {
+		return "", ErrNoLog
+	}
+	var trace []byte
+	if err := g.api.call(ctx, http.MethodGet, projectPath(g.repo.Path)+"/jobs/"+url.PathEscape(id)+"/trace", nil, &trace); err != nil {
+		return "", err
+	}
+	return string(trace), nil
+}
+
+type gitLabDiscussion struct {
+	ID    string `json:"id"`
+	Notes []struct {
+		ID        int64  `json:"id"`
+		Body      string `json:"body"`
+		System    bool   `json:"system"`
+		Resolved  bool   `json:"resolved"`
+		CreatedAt string `json:"created_at"`
+		Author    struct {
+			Username string `json:"username"`
+		} `json:"author"`
+		Position *struct {
+			OldPath string `json:"old_path"`
+			NewPath string `json:"new_path"`
+			OldLine int    `json:"old_line"`
+			NewLine int    `json:"new_line"`
+		} `json:"position"`
+	} `json:"notes"`
+}
+
+func (g *gitLab) discussionsPath(number int) string {
+	return fmt.Sprintf("%s/merge_requests/%d/discussions", projectPath(g.repo.Path), number)
+}
+
+func (g *gitLab) ReviewThreads(ctx context.Context, number int) ([]ReviewThread, error) {
+	var raw []gitLabDiscussion
+	if err := g.api.call(ctx, http.MethodGet, g.discussionsPath(number)+"?per_page=100", nil, &raw); err != nil {
+		return nil, err
+	}
+	mrURL := g.repo.MergeRequestURL(number)
+	var threads []ReviewThread
+	for _, d := range raw {
+		// Only discussions on a diff line are review threads; the rest are
+		// the merge request's conversation and its system notes.
+		if len(d.Notes) == 0 || d.Notes[0].System || d.Notes[0].Position == nil {
+			continue
+		}
+		pos := d.Notes[0].Position
+		t := ReviewThread{ID: d.ID, Path: pos.NewPath, Line: pos.NewLine, Resolved: d.Notes[0].Resolved}
+		if t.Line == 0 {
+			t.Path, t.Line, t.OnOld = pos.OldPath, pos.OldLine, true
+		}
+		for _, n := range d.Notes {
+			t.Comments = append(t.Comments, ReviewComment{
+				ID: strconv.FormatInt(n.ID, 10), Author: n.Author.Username, Body: n.Body,
+				CreatedAt: n.CreatedAt, URL: fmt.Sprintf("%s#note_%d", mrURL, n.ID),
+			})
+		}
+		threads = append(threads, t)
+	}
+	return threads, nil
+}
+
+func (g *gitLab) ReplyToThread(ctx context.Context, number int, thread ReviewThread, body string) error {
+	path := g.discussionsPath(number) + "/" + url.PathEscape(thread.ID) + "/notes"
+	return g.api.call(ctx, http.MethodPost, path, map[string]string{"body": body}, nil)
+}
+
+func (g *gitLab) ResolveThread(ctx context.Context, number int, thread ReviewThread) error {
+	path := g.discussionsPath(number) + "/" + url.PathEscape(thread.ID)
+	return g.api.call(ctx, http.MethodPut, path, map[string]bool{"resolved": true}, nil)
+}
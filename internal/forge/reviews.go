@@ -0,0 +1,40 @@
+package forge
+
+import "errors"
+
+// ReviewComment is one comment in a review thread.
+type ReviewComment struct {
+	ID        string
+	Author    string
+	Body      string
+	CreatedAt string // RFC 3339
+	URL       string
+}
+
+// ReviewThread is a review conversation anchored to one line of a merge
+// request's diff.
+type ReviewThread struct {
+	// ID is what replies and resolutions address the thread by.
+	ID   string
+	Path string
+	// Line is the line the thread is on: in the new file, or in the old one
+	// when OnOld is set because the comment was left on a removed line.
+	Line     int
+	OnOld    bool
+	Resolved bool
+	// Outdated reports that the line has changed since the comment was left;
+	// Line is then where it was.
+	Outdated bool
+	Comments []ReviewComment
+}
+
+// Author is who started the thread.
+func (t ReviewThread) Author() string {
+	if len(t.Comments) == 0 {
+		return ""
+	}
+	return t.Comments[0].Author
+}
+
+// ErrNotSupported means the forge's API has no way to do what was asked.
+var ErrNotSupported = errors.New("not supported by this forge")
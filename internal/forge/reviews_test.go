@@ -0,0 +1,128 @@
+package forge
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGitHubReviewThreadsOverGraphQL(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("FAKE_GH_LOG", log)
+	installFakeCLI(t, "gh", `
+printf '%s\n' "$*" >> "$FAKE_GH_LOG"
+case "$*" in
+*reviewThreads*) printf '%s\n' '{"data":{"repository":{"pullRequest":{"reviewThreads":{"nodes":[
+  {"id":"RT_1","isResolved":false,"isOutdated":false,"path":"main.go","line":12,"originalLine":10,"diffSide":"RIGHT","comments":{"nodes":[{"id":"C1","body":"rename this","createdAt":"2026-01-01T00:00:00Z","url":"u1","author":{"login":"ann"}},{"id":"C2","body":"ok","author":{"login":"me"}}]}},
+  {"id":"RT_2","isResolved":true,"isOutdated":true,"path":"old.go","line":null,"originalLine":4,"diffSide":"LEFT","comments":{"nodes":[]}}
+]}}}}}' ;;
+esac
+`)
+	f := New(Repo{Kind: GitHub, Host: "github.com", Path: "o/r"}, t.TempDir())
+	threads, err := f.ReviewThreads(context.Background(), 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(threads) != 2 {
+		t.Fatalf("threads = %+v", threads)
+	}
+	first := threads[0]
+	if first.ID != "RT_1" || first.Path != "main.go" || first.Line != 12 || first.OnOld || first.Resolved || len(first.Comments) != 2 || first.Author() != "ann" {
+		t.Fatalf("first = %+v", first)
+	}
+	if second := threads[1]; second.Line != 4 || !second.OnOld || !second.Resolved || !second.Outdated {
+		t.Fatalf("outdated thread = %+v", second)
+	}
+
+	if err := f.ReplyToThread(context.Background(), 7, first, "done"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.ResolveThread(context.Background(), 7, first); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(calls) != 3 || !strings.Contains(calls[0], "-F number=7") ||
+		!strings.Contains(calls[1], "addPullRequestReviewThreadReply") || !strings.Contains(calls[1], "-f thread=RT_1 -f body=done") ||
+		!strings.Contains(calls[2], "resolveReviewThread") {
+		t.Fatalf("gh calls = %q", calls)
+	}
+}
+
+func TestGitLabDiffDiscussionsAreThreads(t *testing.T) {
+	t.Setenv("GITLAB_TOKEN", "secret")
+	stub, srv := newStubForge(t, map[string]string{
+		"GET /api/v4/projects/g%2Fapp/merge_requests/3/discussions": `[
+			{"id":"d1","notes":[{"id":11,"body":"general","author":{"username":"ann"}}]},
+			{"id":"d2","notes":[{"id":21,"body":"why?","resolved":false,"author":{"username":"ann"},"position":{"new_path":"a.go","old_path":"a.go","new_line":8,"old_line":null}},{"id":22,"body":"because","author":{"username":"me"}}]},
+			{"id":"d3","notes":[{"id":31,"body":"gone","resolved":true,"author":{"username":"bo"},"position":{"new_path":"b.go","old_path":"b.go","new_line":null,"old_line":5}}]}
+		]`,
+		"POST /api/v4/projects/g%2Fapp/merge_requests/3/discussions/d2/notes": `{"id":23}`,
+		"PUT /api/v4/projects/g%2Fapp/merge_requests/3/discussions/d2":        `{"id":"d2"}`,
+	})
+	f := New(stubRepo(srv, GitLab, "g/app"), "")
+	threads, err := f.ReviewThreads(context.Background(), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(threads) != 2 {
+		t.Fatalf("threads = %+v", threads)
+	}
+	if got := threads[0]; got.ID != "d2" || got.Path != "a.go" || got.Line != 8 || got.OnOld || len(got.Comments) != 2 || !strings.HasSuffix(got.Comments[0].URL, "/-/merge_requests/3#note_21") {
+		t.Fatalf("d2 = %+v", got)
+	}
+	if got := threads[1]; got.Line != 5 || !got.OnOld || !got.Resolved {
+		t.Fatalf("d3 = %+v", got)
+	}
+	if err := f.ReplyToThread(context.Background(), 3, threads[0], "fixed"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.ResolveThread(context.Background(), 3, threads[0]); err != nil {
+		t.Fatal(err)
+	}
+	if body := stub.bodies["POST /api/v4/projects/g%2Fapp/merge_requests/3/discussions/d2/notes"]; body["body"] != "fixed" {
+		t.Fatalf("reply body = %v", body)
+	}
+	if body := stub.bodies["PUT /api/v4/projects/g%2Fapp/merge_requests/3/discussions/d2"]; body["resolved"] != true {
+		t.Fatalf("resolve body = %v", body)
+	}
+}
+
+func TestGiteaGroupsReviewCommentsByLine(t *testing.T) {
+	t.Setenv("GITEA_TOKEN", "")
+	t.Setenv("FORGEJO_TOKEN", "")
+	stub, srv := newStubForge(t, map[string]string{
+		"GET /api/v1/repos/org/svc/pulls/9/reviews":            `[{"id":1},{"id":2}]`,
+		"GET /api/v1/repos/org/svc/pulls/9/reviews/1/comments": `[{"id":10,"body":"nit","path":"x.go","position":3,"user":{"login":"ann"}}]`,
+		"GET /api/v1/repos/org/svc/pulls/9/reviews/2/comments": `[{"id":20,"body":"agreed","path":"x.go","position":3,"user":{"login":"bo"},"resolver":{"login":"bo"}},{"id":21,"body":"old","path":"y.go","original_position":7,"user":{"login":"bo"}}]`,
+		"POST /api/v1/repos/org/svc/pulls/9/reviews":           `{"id":3}`,
+	})
+	f := New(stubRepo(srv, Gitea, "org/svc"), "")
+	threads, err := f.ReviewThreads(context.Background(), 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(threads) != 2 || threads[0].ID != "10" || len(threads[0].Comments) != 2 || !threads[0].Resolved || threads[1].Line != 7 || !threads[1].OnOld {
+		t.Fatalf("threads = %+v", threads)
+	}
+	if err := f.ReplyToThread(context.Background(), 9, threads[1], "still needed"); err != nil {
+		t.Fatal(err)
+	}
+	review := stub.bodies["POST /api/v1/repos/org/svc/pulls/9/reviews"]
+	comments, _ := review["comments"].([]any)
+	if review["event"] != "COMMENT" || len(comments) != 1 {
+		t.Fatalf("review = %v", review)
+	}
+	if c := comments[0].(map[string]any); c["path"] != "y.go" || c["old_position"] != float64(7) || c["body"] != "still needed" {
+		t.Fatalf("comment = %v", c)
+	}
+	if err := f.ResolveThread(context.Background(), 9, threads[0]); !errors.Is(err, ErrNotSupported) {
+		t.Fatalf("resolve err = %v", err)
+	}
+}
@@ -0,0 +1,242 @@
+// Package gitstate is the per-path git status snapshot that more than one
+// surface decorates files with. The git status plugin already runs `git
+// status` whenever the repository moves; it parses the same output into a
+// Snapshot and broadcasts it, so the file browser can mark changed files
+// without running git a second time. A host that hears no broadcast (the git
+// plugin disabled, or not in a repository it knows) loads its own.
+package gitstate
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// State is the set of changes git reports for a path. A directory's state is
+// the union of everything beneath it.
+type State uint8
+
+const (
+	// Modified is a change in the worktree not yet staged, including a
+	// deletion.
+	Modified State = 1 << iota
+	// Staged is a change recorded in the index.
+	Staged
+	// Untracked is a file git does not know.
+	Untracked
+	// Conflicted is an unmerged path.
+	Conflicted
+)
+
+// Changed reports whether the path differs from HEAD in any way.
+func (s State) Changed() bool { return s != 0 }
+
+// Names spells the state for readers outside the process: "modified",
+// "staged", "untracked", "conflicted", in that order.
+func (s State) Names() []string {
+	var names []string
+	for _, flag := range []struct {
+		state State
+		name  string
+	}{{Modified, "modified"}, {Staged, "staged"}, {Untracked, "untracked"}, {Conflicted, "conflicted"}} {
+		if s&flag.state != 0 {
+			names = append(names, flag.name)
+		}
+	}
+	return names
+}
+
+// Snapshot is one `git status` of a repository, indexed by absolute path.
+// It is never modified once loaded, so it can be shared freely.
+type Snapshot struct {
+	// Root is the repository's top-level directory.
+	Root string
+	// Sparse is the worktree's sparse-checkout definition, nil when it has
+	// none. Parse leaves it unset; Load fills it in.
+	Sparse *Sparse
+	files  map[string]State
+	dirs   map[string]State
+}
+
+// File returns the state of the file at the absolute path.
+func (s *Snapshot) File(path string) State {
+	if s == nil {
+		return 0
+	}
+	return s.files[filepath.Clean(path)]
+}
+
+// Dir returns the combined state of everything under the absolute directory.
+func (s *Snapshot) Dir(path string) State {
+	if s == nil {
+		return 0
+	}
+	return s.dirs[filepath.Clean(path)]
+}
+
+// Covers reports whether dir lies inside the snapshot's repository.
+func (s *Snapshot) Covers(dir string) bool {
+	if s == nil || s.Root == "" {
+		return false
+	}
+	rel, err := filepath.Rel(s.Root, dir)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// Len returns how many changed files the snapshot holds.
+func (s *Snapshot) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.files)
+}
+
+// Paths returns every changed file's absolute path, sorted.
+func (s *Snapshot) Paths() []string {
+	if s == nil {
+		return nil
+	}
+	paths := make([]string, 0, len(s.files))
+	for path := range s.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Parse builds a snapshot from `git status --porcelain=v2 -z` output run at
+// root. Untracked directories are rolled up like any other change, so
+// --untracked-files=all gives per-file marks and the default gives one mark
+// on the directory.
+func Parse(root string, output []byte) *Snapshot {
+	s := &Snapshot{
+		Root:  filepath.Clean(root),
+		files: make(map[string]State),
+		dirs:  make(map[string]State),
+	}
+	parts := bytes.Split(output, []byte{0})
+	for i := 0; i < len(parts); i++ {
+		line := string(parts[i])
+		var path string
+		var state State
+		switch {
+		case strings.HasPrefix(line, "1 "):
+			// 1 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <path>
+			fields := strings.SplitN(line, " ", 9)
+			if len(fields) < 9 {
+				continue
+			}
+			path, state = fields[8], xyState(fields[1])
+		case strings.HasPrefix(line, "2 "):
+			// 2 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <X><score> <path>, then
+			// the original path as its own field.
+			fields := strings.SplitN(line, " ", 10)
+			if len(fields) < 10 {
+				continue
+			}
+			path, state = fields[9], xyState(fields[1])
+			i++
+		case strings.HasPrefix(line, "u "):
+			// u <XY> <sub> <m1> <m2> <m3> <mW> <h1> <h2> <h3> <path>
+			fields := strings.SplitN(line, " ", 11)
+			if len(fields) < 11 {
+				continue
+			}
+			path, state = fields[10], Conflicted
+		case strings.HasPrefix(line, "? "):
+			path, state = line[2:], Untracked
+		default:
+			continue
+		}
+		s.add(filepath.Join(s.Root, filepath.FromSlash(strings.TrimSuffix(path, "/"))), state)
+	}
+	return s
+}
+
+// xyState maps porcelain v2's index (X) and worktree (Y) columns to a State.
+func xyState(xy string) State {
+	var s State
+	if len(xy) < 2 {
+		return s
+	}
+	if xy[0] != '.' {
+		s |= Staged
+	}
+	if xy[1] != '.' {
+		s |= Modified
+	}
+	return s
+}
+
+func (s *Snapshot) add(path string, state State) {
+	s.files[path] |= state
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		s.dirs[dir] |= state
+		if dir == s.Root || dir == filepath.Dir(dir) {
+			return
+		}
+	}
+}
+
+// Load runs `git status` for the repository containing dir.
+func Load(ctx context.Context, dir string) (*Snapshot, error) {
+	top, err := git(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	root := spelledRoot(dir, strings.TrimSpace(string(top)))
+	out, err := git(ctx, root, "status", "--porcelain=v2", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+	s := Parse(root, out)
+	s.Sparse = LoadSparse(ctx, root)
+	return s, nil
+}
+
+// spelledRoot returns root as dir spells it. git prints the top level with
+// symlinks resolved (/private/tmp on macOS), and a snapshot keyed that way would
+// match none of the paths a host built from dir.
+func spelledRoot(dir, root string) string {
+	real, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return root
+	}
+	rel, err := filepath.Rel(root, real)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return root
+	}
+	spelled := filepath.Clean(dir)
+	for ; rel != "."; rel = filepath.Dir(rel) {
+		spelled = filepath.Dir(spelled)
+	}
+	return spelled
+}
+
+// git runs a read-only git command. --no-optional-locks keeps a background
+// status from contending with the user's own commands for index.lock.
+func git(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"--no-optional-locks"}, args...)...)
+	cmd.Dir = dir
+	return cmd.Output()
+}
+
+// SnapshotMsg shares a freshly loaded snapshot with every plugin. Hosts adopt
+// it when it Covers their work directory.
+type SnapshotMsg struct {
+	Snapshot *Snapshot
+}
+
+// Publish returns a command that broadcasts s, or nil when there is nothing
+// to share.
+func Publish(s *Snapshot) tea.Cmd {
+	if s == nil {
+		return nil
+	}
+	return func() tea.Msg { return SnapshotMsg{Snapshot: s} }
+}
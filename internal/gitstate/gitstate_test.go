@@ -0,0 +1,179 @@
+package gitstate
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseRollsChangesUpToDirectories(t *testing.T) {
+	out := strings.Join([]string{
+		"2 RM N... 100644 100644 100644 7898 7898 R100 src/new.go",
+		"src/old.go",
+		"1 M. N... 100644 100644 100644 6178 b51e src/pkg/staged.go",
+		"u UU N... 100644 100644 100644 100644 a b c docs/conflict.md",
+		"? scratch/",
+		"? notes.txt",
+		"",
+	}, "\x00")
+	s := Parse("/repo", []byte(out))
+
+	if got := s.File("/repo/src/new.go"); got != Staged|Modified {
+		t.Fatalf("renamed and edited = %v, want staged|modified", got)
+	}
+	if s.File("/repo/src/old.go") != 0 {
+		t.Fatal("a rename's original path was parsed as an entry of its own")
+	}
+	if got := s.File("/repo/src/pkg/staged.go"); got != Staged {
+		t.Fatalf("staged = %v", got)
+	}
+	if got := s.Dir("/repo/src"); got != Staged|Modified {
+		t.Fatalf("src rolls up %v, want staged|modified", got)
+	}
+	if got := s.Dir("/repo"); got != Staged|Modified|Conflicted|Untracked {
+		t.Fatalf("root rolls up %v", got)
+	}
+	if got := s.File("/repo/scratch"); got != Untracked {
+		t.Fatalf("untracked directory = %v", got)
+	}
+	if s.Dir("/repo/lib") != 0 || s.Dir("/") != 0 {
+		t.Fatal("clean or outside directories report changes")
+	}
+	if s.Len() != 5 {
+		t.Fatalf("Len = %d, want 5", s.Len())
+	}
+}
+
+func TestPathsAndNamesSpellTheSnapshot(t *testing.T) {
+	out := strings.Join([]string{
+		"1 MM N... 100644 100644 100644 6178 b51e b.go",
+		"? a.txt",
+		"",
+	}, "\x00")
+	s := Parse("/repo", []byte(out))
+	if got := strings.Join(s.Paths(), ","); got != "/repo/a.txt,/repo/b.go" {
+		t.Fatalf("Paths = %s", got)
+	}
+	if got := strings.Join(s.File("/repo/b.go").Names(), ","); got != "modified,staged" {
+		t.Fatalf("Names = %s", got)
+	}
+	if State(0).Names() != nil {
+		t.Fatal("a clean state has names")
+	}
+}
+
+func TestCovers(t *testing.T) {
+	s := Parse("/repo", nil)
+	for dir, want := range map[string]bool{
+		"/repo": true, "/repo/sub": true, "/repository": false, "/other": false,
+	} {
+		if got := s.Covers(dir); got != want {
+			t.Errorf("Covers(%q) = %v, want %v", dir, got, want)
+		}
+	}
+	var none *Snapshot
+	if none.Covers("/repo") || none.File("/repo/a") != 0 {
+		t.Error("a nil snapshot should describe nothing")
+	}
+}
+
+func TestLoadKeysPathsAsTheCallerSpellsThem(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	real := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = real
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	run("init", "-q")
+	if err := os.MkdirAll(filepath.Join(real, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(real, "sub", "new.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(t.TempDir(), "link")
+	if err := os.Symlink(real, link); err != nil {
+		t.Skip("symlinks unavailable")
+	}
+
+	s, err := Load(context.Background(), filepath.Join(link, "sub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Root != link {
+		t.Fatalf("Root = %q, want the symlinked spelling %q", s.Root, link)
+	}
+	if s.File(filepath.Join(link, "sub", "new.txt")) != Untracked {
+		t.Fatal("untracked file not found under the caller's path")
+	}
+}
+
+func TestSparseConeExcludes(t *testing.T) {
+	s := &Sparse{Cone: true, Patterns: []string{"src/app", "docs"}}
+	for _, tt := range []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"README.md", false, false},
+		{"docs/guide.md", false, false},
+		{"src", true, false},
+		{"src/go.mod", false, false},
+		{"src/app/main.go", false, false},
+		{"src/lib", true, true},
+		{"src/lib/util.go", false, true},
+		{"vendor", true, true},
+		{"vendor/x.go", false, true},
+	} {
+		if got := s.Excludes(tt.path, tt.isDir); got != tt.want {
+			t.Errorf("Excludes(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+	if (&Sparse{Patterns: []string{"/src/"}}).Excludes("vendor/x.go", false) {
+		t.Error("non-cone patterns should exclude nothing")
+	}
+}
+
+func TestLoadReadsSparseCheckout(t *testing.T) {
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	run("init", "-q")
+	for _, f := range []string{"app/main.go", "lib/util.go"} {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(f)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, f), []byte("x\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run("add", ".")
+	run("-c", "user.name=t", "-c", "user.email=t@t", "commit", "-qm", "init")
+	run("sparse-checkout", "set", "app")
+
+	s, err := Load(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Sparse == nil || !s.Sparse.Cone || len(s.Sparse.Patterns) != 1 || s.Sparse.Patterns[0] != "app" {
+		t.Fatalf("sparse = %+v", s.Sparse)
+	}
+	if !s.OutsideSparse(filepath.Join(dir, "lib"), true) || s.OutsideSparse(filepath.Join(dir, "app", "main.go"), false) {
+		t.Fatal("OutsideSparse disagrees with the cone")
+	}
+}
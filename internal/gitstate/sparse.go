@@ -0,0 +1,89 @@
+package gitstate
+
+import (
+	"context"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Sparse is a worktree's sparse-checkout definition. Only cone mode is
+// understood path by path; a worktree with non-cone patterns reports itself
+// sparse but excludes nothing, since matching those patterns takes git itself.
+type Sparse struct {
+	Cone bool
+	// Patterns are the cone's directories, slash-separated and relative to
+	// the top level, or the raw patterns when Cone is false.
+	Patterns []string
+}
+
+// LoadSparse reads the sparse-checkout definition of the worktree at dir. It
+// returns nil when the worktree is not sparse.
+func LoadSparse(ctx context.Context, dir string) *Sparse {
+	out, err := git(ctx, dir, "config", "--type=bool", "--get", "core.sparseCheckout")
+	if err != nil || strings.TrimSpace(string(out)) != "true" {
+		return nil
+	}
+	sparse := &Sparse{}
+	if out, err := git(ctx, dir, "config", "--type=bool", "--get", "core.sparseCheckoutCone"); err == nil {
+		sparse.Cone = strings.TrimSpace(string(out)) == "true"
+	}
+	out, err = git(ctx, dir, "sparse-checkout", "list")
+	if err != nil {
+		// Non-cone patterns only list with a warning on older gits; the
+		// worktree is still sparse.
+		return sparse
+	}
+	for line := range strings.SplitSeq(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			if sparse.Cone {
+				line = strings.Trim(line, "/")
+			}
+			sparse.Patterns = append(sparse.Patterns, line)
+		}
+	}
+	return sparse
+}
+
+// Excludes reports whether the slash-separated path, relative to the top
+// level, lies outside the cone. Files directly in the top level, and in every
+// directory leading to a cone directory, are always checked out.
+func (s *Sparse) Excludes(rel string, isDir bool) bool {
+	if s == nil || !s.Cone {
+		return false
+	}
+	rel = strings.Trim(rel, "/")
+	if rel == "" || rel == "." {
+		return false
+	}
+	dir := rel
+	if !isDir {
+		dir = path.Dir(rel)
+		if dir == "." {
+			return false
+		}
+	}
+	for _, p := range s.Patterns {
+		if rel == p || strings.HasPrefix(rel, p+"/") {
+			return false
+		}
+		// A directory on the way to p, or a file directly inside one.
+		if dir == p || strings.HasPrefix(p, dir+"/") {
+			return false
+		}
+	}
+	return true
+}
+
+// OutsideSparse reports whether the absolute path lies outside the sparse
+// checkout of the snapshot's worktree.
+func (s *Snapshot) OutsideSparse(absPath string, isDir bool) bool {
+	if s == nil || s.Sparse == nil {
+		return false
+	}
+	rel, err := filepath.Rel(s.Root, absPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	return s.Sparse.Excludes(filepath.ToSlash(rel), isDir)
+}
@@ -0,0 +1,461 @@
+package inlineedit
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Pos is a caret position in the buffer: a 0-indexed line and a 0-indexed rune
+// column within that line.
+type Pos struct {
+	Line int
+	Col  int
+}
+
+func (a Pos) before(b Pos) bool {
+	return a.Line < b.Line || (a.Line == b.Line && a.Col < b.Col)
+}
+
+// Buffer is the text model behind the native editor: the document as lines of
+// runes plus one or more carets. Every caret receives the same edit, which is
+// what makes column (multi-cursor) editing work; cursors[0] is the primary
+// caret that owns scrolling, search, and the terminal cursor.
+type Buffer struct {
+	lines    [][]rune
+	cursors  []Pos
+	goalCol  int // column vertical motion tries to return to
+	history  History
+	revision uint64 // bumped on every content change; keys render caches
+	now      func() time.Time
+}
+
+// NewBuffer builds a buffer over text with the caret at the top.
+func NewBuffer(text string) *Buffer {
+	b := &Buffer{now: time.Now}
+	b.setText(text)
+	b.cursors = []Pos{{}}
+	return b
+}
+
+func (b *Buffer) setText(text string) {
+	raw := strings.Split(text, "\n")
+	b.lines = make([][]rune, len(raw))
+	for i, line := range raw {
+		b.lines[i] = []rune(line)
+	}
+	b.revision++
+}
+
+// Text returns the buffer contents joined with newlines.
+func (b *Buffer) Text() string {
+	var sb strings.Builder
+	for i, line := range b.lines {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(string(line))
+	}
+	return sb.String()
+}
+
+// LineCount reports the number of lines (never less than one).
+func (b *Buffer) LineCount() int { return len(b.lines) }
+
+// Line returns line i as a string, or "" when out of range.
+func (b *Buffer) Line(i int) string {
+	if i < 0 || i >= len(b.lines) {
+		return ""
+	}
+	return string(b.lines[i])
+}
+
+// Revision changes whenever the content does.
+func (b *Buffer) Revision() uint64 { return b.revision }
+
+// Cursors returns a copy of every caret, primary first.
+func (b *Buffer) Cursors() []Pos {
+	return append([]Pos(nil), b.cursors...)
+}
+
+// Primary returns the primary caret.
+func (b *Buffer) Primary() Pos { return b.cursors[0] }
+
+// SetCursor collapses to a single caret at pos, clamped to the buffer.
+func (b *Buffer) SetCursor(pos Pos) {
+	b.cursors = []Pos{b.clamp(pos)}
+	b.goalCol = b.cursors[0].Col
+}
+
+// ClearExtraCursors drops every caret but the primary. It reports whether
+// there was anything to drop.
+func (b *Buffer) ClearExtraCursors() bool {
+	if len(b.cursors) <= 1 {
+		return false
+	}
+	b.cursors = b.cursors[:1]
+	return true
+}
+
+// AddCursorVertical adds a caret one line above (delta -1) or below (delta 1)
+// the outermost caret in that direction, at the primary's goal column. It is
+// the column-edit gesture: repeat it to grow a vertical run of carets.
+func (b *Buffer) AddCursorVertical(delta int) bool {
+	edge := b.cursors[0]
+	for _, c := range b.cursors[1:] {
+		if (delta > 0 && c.Line > edge.Line) || (delta < 0 && c.Line < edge.Line) {
+			edge = c
+		}
+	}
+	line := edge.Line + delta
+	if line < 0 || line >= len(b.lines) {
+		return false
+	}
+	next := b.clamp(Pos{Line: line, Col: b.goalCol})
+	for _, c := range b.cursors {
+		if c == next {
+			return false
+		}
+	}
+	b.cursors = append(b.cursors, next)
+	return true
+}
+
+func (b *Buffer) clamp(p Pos) Pos {
+	if p.Line < 0 {
+		p.Line = 0
+	}
+	if p.Line >= len(b.lines) {
+		p.Line = len(b.lines) - 1
+	}
+	if p.Col < 0 {
+		p.Col = 0
+	}
+	if n := len(b.lines[p.Line]); p.Col > n {
+		p.Col = n
+	}
+	return p
+}
+
+// dedupe merges carets that edits have pushed onto the same spot, keeping the
+// primary first.
+func (b *Buffer) dedupe() {
+	seen := make(map[Pos]bool, len(b.cursors))
+	out := b.cursors[:0]
+	for _, c := range b.cursors {
+		if seen[c] {
+			continue
+		}
+		seen[c] = true
+		out = append(out, c)
+	}
+	b.cursors = out
+}
+
+// Motion is a caret movement applied to every caret.
+type Motion int
+
+const (
+	MoveLeft Motion = iota
+	MoveRight
+	MoveUp
+	MoveDown
+	MoveLineStart
+	MoveLineEnd
+	MoveWordLeft
+	MoveWordRight
+	MoveDocStart
+	MoveDocEnd
+)
+
+// Move applies m to every caret. Vertical motion keeps the goal column so a
+// caret passing through a short line returns to where it started.
+func (b *Buffer) Move(m Motion) {
+	for i, c := range b.cursors {
+		b.cursors[i] = b.moved(c, m)
+	}
+	switch m {
+	case MoveUp, MoveDown:
+	default:
+		b.goalCol = b.cursors[0].Col
+	}
+	b.dedupe()
+}
+
+// MoveLines moves every caret n lines (negative is up), for page motion.
+func (b *Buffer) MoveLines(n int) {
+	for i, c := range b.cursors {
+		b.cursors[i] = b.clamp(Pos{Line: c.Line + n, Col: b.goalCol})
+	}
+	b.dedupe()
+}
+
+func (b *Buffer) moved(c Pos, m Motion) Pos {
+	line := b.lines[c.Line]
+	switch m {
+	case MoveLeft:
+		if c.Col > 0 {
+			c.Col--
+		} else if c.Line > 0 {
+			c.Line--
+			c.Col = len(b.lines[c.Line])
+		}
+	case MoveRight:
+		if c.Col < len(line) {
+			c.Col++
+		} else if c.Line < len(b.lines)-1 {
+			c.Line++
+			c.Col = 0
+		}
+	case MoveUp:
+		if c.Line > 0 {
+			return b.clamp(Pos{Line: c.Line - 1, Col: b.goalCol})
+		}
+		c.Col = 0
+	case MoveDown:
+		if c.Line < len(b.lines)-1 {
+			return b.clamp(Pos{Line: c.Line + 1, Col: b.goalCol})
+		}
+		c.Col = len(line)
+	case MoveLineStart:
+		// Home toggles between the first non-blank and column zero.
+		indent := 0
+		for indent < len(line) && (line[indent] == ' ' || line[indent] == '\t') {
+			indent++
+		}
+		if c.Col == indent {
+			c.Col = 0
+		} else {
+			c.Col = indent
+		}
+	case MoveLineEnd:
+		c.Col = len(line)
+	case MoveWordLeft:
+		if c.Col == 0 {
+			return b.moved(c, MoveLeft)
+		}
+		col := c.Col
+		for col > 0 && !isWordRune(line[col-1]) {
+			col--
+		}
+		for col > 0 && isWordRune(line[col-1]) {
+			col--
+		}
+		c.Col = col
+	case MoveWordRight:
+		if c.Col == len(line) {
+			return b.moved(c, MoveRight)
+		}
+		col := c.Col
+		for col < len(line) && !isWordRune(line[col]) {
+			col++
+		}
+		for col < len(line) && isWordRune(line[col]) {
+			col++
+		}
+		c.Col = col
+	case MoveDocStart:
+		return Pos{}
+	case MoveDocEnd:
+		last := len(b.lines) - 1
+		return Pos{Line: last, Col: len(b.lines[last])}
+	}
+	return c
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r > 0x7f
+}
+
+// byPositionDesc orders caret indexes bottom-up so an edit at one caret never
+// shifts the coordinates of a caret still waiting for its turn.
+func (b *Buffer) byPositionDesc() []int {
+	order := make([]int, len(b.cursors))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return b.cursors[order[j]].before(b.cursors[order[i]])
+	})
+	return order
+}
+
+// shiftAfter moves carets other than skip that sit on line at or beyond col
+// by dCol, and every caret below line by dLine.
+func (b *Buffer) shiftAfter(skip, line, col, dLine, dCol int) {
+	for i := range b.cursors {
+		if i == skip {
+			continue
+		}
+		c := &b.cursors[i]
+		switch {
+		case c.Line == line && c.Col >= col:
+			c.Line += dLine
+			c.Col += dCol
+		case c.Line > line:
+			c.Line += dLine
+		}
+	}
+}
+
+// Insert types text at every caret. Newlines in text split lines.
+func (b *Buffer) Insert(text string) {
+	if text == "" {
+		return
+	}
+	kind := EditTyping
+	if len([]rune(text)) > 1 {
+		kind = EditPaste
+	}
+	b.prepare(kind)
+	parts := strings.Split(text, "\n")
+	for _, i := range b.byPositionDesc() {
+		c := b.cursors[i]
+		line := b.lines[c.Line]
+		head := append([]rune(nil), line[:c.Col]...)
+		tail := append([]rune(nil), line[c.Col:]...)
+		if len(parts) == 1 {
+			ins := []rune(parts[0])
+			b.lines[c.Line] = append(append(head, ins...), tail...)
+			b.shiftAfter(i, c.Line, c.Col, 0, len(ins))
+			b.cursors[i].Col += len(ins)
+			continue
+		}
+		added := make([][]rune, len(parts))
+		added[0] = append(head, []rune(parts[0])...)
+		for k := 1; k < len(parts)-1; k++ {
+			added[k] = []rune(parts[k])
+		}
+		lastIns := []rune(parts[len(parts)-1])
+		added[len(parts)-1] = append(append([]rune(nil), lastIns...), tail...)
+		b.lines = append(b.lines[:c.Line], append(added, b.lines[c.Line+1:]...)...)
+		b.shiftAfter(i, c.Line, c.Col, len(parts)-1, len(lastIns)-c.Col)
+		b.cursors[i] = Pos{Line: c.Line + len(parts) - 1, Col: len(lastIns)}
+	}
+	b.changed()
+}
+
+// Newline splits the line at every caret, carrying the line's indentation.
+func (b *Buffer) Newline() {
+	b.prepare(EditTyping)
+	for _, i := range b.byPositionDesc() {
+		c := b.cursors[i]
+		line := b.lines[c.Line]
+		indent := 0
+		for indent < len(line) && indent < c.Col && (line[indent] == ' ' || line[indent] == '\t') {
+			indent++
+		}
+		head := append([]rune(nil), line[:c.Col]...)
+		tail := append(append([]rune(nil), line[:indent]...), line[c.Col:]...)
+		b.lines[c.Line] = head
+		b.lines = append(b.lines[:c.Line+1], append([][]rune{tail}, b.lines[c.Line+1:]...)...)
+		b.shiftAfter(i, c.Line, c.Col, 1, indent-c.Col)
+		b.cursors[i] = Pos{Line: c.Line + 1, Col: indent}
+	}
+	b.changed()
+}
+
+// Backspace deletes the rune before every caret, joining lines at column 0.
+func (b *Buffer) Backspace() {
+	b.deleteAt(true)
+}
+
+// Delete deletes the rune after every caret, joining lines at line end.
+func (b *Buffer) Delete() {
+	b.deleteAt(false)
+}
+
+func (b *Buffer) deleteAt(backward bool) {
+	b.prepare(EditDelete)
+	touched := false
+	for _, i := range b.byPositionDesc() {
+		c := b.cursors[i]
+		line := b.lines[c.Line]
+		switch {
+		case backward && c.Col > 0:
+			b.lines[c.Line] = append(append([]rune(nil), line[:c.Col-1]...), line[c.Col:]...)
+			b.shiftAfter(i, c.Line, c.Col, 0, -1)
+			b.cursors[i].Col--
+		case backward && c.Line > 0:
+			prev := b.lines[c.Line-1]
+			joinCol := len(prev)
+			b.lines[c.Line-1] = append(append([]rune(nil), prev...), line...)
+			b.lines = append(b.lines[:c.Line], b.lines[c.Line+1:]...)
+			b.shiftAfter(i, c.Line, 0, -1, joinCol)
+			b.cursors[i] = Pos{Line: c.Line - 1, Col: joinCol}
+		case !backward && c.Col < len(line):
+			b.lines[c.Line] = append(append([]rune(nil), line[:c.Col]...), line[c.Col+1:]...)
+			b.shiftAfter(i, c.Line, c.Col+1, 0, -1)
+		case !backward && c.Line < len(b.lines)-1:
+			b.lines[c.Line] = append(append([]rune(nil), line...), b.lines[c.Line+1]...)
+			b.lines = append(b.lines[:c.Line+1], b.lines[c.Line+2:]...)
+			b.shiftAfter(i, c.Line+1, 0, -1, c.Col)
+		default:
+			continue
+		}
+		touched = true
+	}
+	if touched {
+		b.changed()
+	}
+}
+
+// replaceRange swaps the runes in [start, end) on a single line for repl and
+// returns the column just past the replacement. It is the primitive search
+// and replace builds on; it does not touch history.
+func (b *Buffer) replaceRange(line, start, end int, repl []rune) int {
+	cur := b.lines[line]
+	next := append(append(append([]rune(nil), cur[:start]...), repl...), cur[end:]...)
+	b.lines[line] = next
+	delta := len(repl) - (end - start)
+	for i := range b.cursors {
+		if c := &b.cursors[i]; c.Line == line && c.Col >= end {
+			c.Col += delta
+		}
+	}
+	return start + len(repl)
+}
+
+func (b *Buffer) changed() {
+	b.revision++
+	b.dedupe()
+	b.goalCol = b.cursors[0].Col
+}
+
+func (b *Buffer) snapshot() Snapshot {
+	return Snapshot{Content: b.Text(), Cursors: b.Cursors()}
+}
+
+func (b *Buffer) prepare(kind EditKind) {
+	b.history.PrepareLazy(kind, b.snapshot, b.now())
+}
+
+func (b *Buffer) restore(snap Snapshot) {
+	b.setText(snap.Content)
+	b.cursors = nil
+	for _, c := range snap.Cursors {
+		b.cursors = append(b.cursors, b.clamp(c))
+	}
+	if len(b.cursors) == 0 {
+		b.cursors = []Pos{{}}
+	}
+	b.goalCol = b.cursors[0].Col
+}
+
+// Undo reverts the last undo unit. It reports whether there was one.
+func (b *Buffer) Undo() bool {
+	prev, ok := b.history.Undo(b.snapshot())
+	if ok {
+		b.restore(prev)
+	}
+	return ok
+}
+
+// Redo re-applies the last undone unit. It reports whether there was one.
+func (b *Buffer) Redo() bool {
+	next, ok := b.history.Redo(b.snapshot())
+	if ok {
+		b.restore(next)
+	}
+	return ok
+}
@@ -0,0 +1,115 @@
+package inlineedit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInsertAtEveryCaret(t *testing.T) {
+	b := NewBuffer("alpha\nbeta\ngamma")
+	b.AddCursorVertical(1)
+	b.AddCursorVertical(1)
+	if got := len(b.Cursors()); got != 3 {
+		t.Fatalf("cursors = %d, want 3", got)
+	}
+	b.Insert("- ")
+	if got, want := b.Text(), "- alpha\n- beta\n- gamma"; got != want {
+		t.Fatalf("text = %q, want %q", got, want)
+	}
+	for i, c := range b.Cursors() {
+		if c.Col != 2 {
+			t.Fatalf("cursor %d col = %d, want 2", i, c.Col)
+		}
+	}
+}
+
+func TestMultiLineInsertShiftsLaterCarets(t *testing.T) {
+	b := NewBuffer("a\nb")
+	b.AddCursorVertical(1)
+	b.Insert("x\ny")
+	if got, want := b.Text(), "x\nya\nx\nyb"; got != want {
+		t.Fatalf("text = %q, want %q", got, want)
+	}
+	cursors := b.Cursors()
+	if cursors[0] != (Pos{Line: 1, Col: 1}) || cursors[1] != (Pos{Line: 3, Col: 1}) {
+		t.Fatalf("cursors = %v", cursors)
+	}
+}
+
+func TestNewlineCarriesIndentation(t *testing.T) {
+	b := NewBuffer("\tif x {")
+	b.Move(MoveLineEnd)
+	b.Newline()
+	if got, want := b.Text(), "\tif x {\n\t"; got != want {
+		t.Fatalf("text = %q, want %q", got, want)
+	}
+}
+
+func TestBackspaceJoinsLines(t *testing.T) {
+	b := NewBuffer("ab\ncd")
+	b.SetCursor(Pos{Line: 1})
+	b.Backspace()
+	if got := b.Text(); got != "abcd" {
+		t.Fatalf("text = %q", got)
+	}
+	if p := b.Primary(); p != (Pos{Line: 0, Col: 2}) {
+		t.Fatalf("caret = %v", p)
+	}
+}
+
+func TestUndoCoalescesTypingBurst(t *testing.T) {
+	b := NewBuffer("")
+	now := time.Unix(0, 0)
+	b.now = func() time.Time { return now }
+	for _, r := range "hello" {
+		b.Insert(string(r))
+		now = now.Add(100 * time.Millisecond)
+	}
+	now = now.Add(2 * time.Second)
+	b.Insert("!")
+
+	if !b.Undo() || b.Text() != "hello" {
+		t.Fatalf("first undo = %q, want hello", b.Text())
+	}
+	if !b.Undo() || b.Text() != "" {
+		t.Fatalf("second undo = %q, want empty", b.Text())
+	}
+	if b.Undo() {
+		t.Fatal("undo past the start should report false")
+	}
+	if !b.Redo() || b.Text() != "hello" {
+		t.Fatalf("redo = %q, want hello", b.Text())
+	}
+}
+
+func TestReplaceAllIsOneUndoUnit(t *testing.T) {
+	b := NewBuffer("foo bar foo\nfoo")
+	if n := b.ReplaceAll("foo", "baz"); n != 3 {
+		t.Fatalf("replaced %d, want 3", n)
+	}
+	if got, want := b.Text(), "baz bar baz\nbaz"; got != want {
+		t.Fatalf("text = %q, want %q", got, want)
+	}
+	b.Undo()
+	if got, want := b.Text(), "foo bar foo\nfoo"; got != want {
+		t.Fatalf("after undo = %q, want %q", got, want)
+	}
+}
+
+func TestFindNextSmartCaseAndWrap(t *testing.T) {
+	b := NewBuffer("Foo foo\nfoo")
+	if got := len(b.FindAll("foo")); got != 3 {
+		t.Fatalf("lower-case query matched %d, want 3", got)
+	}
+	if got := len(b.FindAll("Foo")); got != 1 {
+		t.Fatalf("mixed-case query matched %d, want 1", got)
+	}
+	m, ok := b.FindNext("foo", Pos{Line: 1, Col: 1}, false)
+	if !ok || m.Line != 0 || m.Start != 0 {
+		t.Fatalf("wrapped match = %+v ok=%v", m, ok)
+	}
+	m, ok = b.FindNext("foo", Pos{Line: 0, Col: 4}, true)
+	if !ok || m.Line != 0 || m.Start != 0 {
+		t.Fatalf("backward match = %+v ok=%v", m, ok)
+	}
+}
@@ -0,0 +1,755 @@
+package inlineedit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/cespare/xxhash/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/marcus/sidecar/internal/docview"
+	"github.com/marcus/sidecar/internal/filepreview"
+	"github.com/marcus/sidecar/internal/styles"
+)
+
+// The native editor is the tmux-free half of this package: a Buffer bound to
+// a file, rendered with the preview's syntax highlighting and driven directly
+// by key messages. A Session hosts it the same way it hosts a tmux editor, so
+// a pane opts in by starting one with StartNative and changes nothing else.
+
+const (
+	// maxNativeEditSize bounds what the native editor will load. Larger files
+	// belong to the external editor; the buffer keeps every line in memory
+	// and re-highlights the whole document after each edit.
+	maxNativeEditSize = 4 << 20
+	tabWidth          = 4
+)
+
+var (
+	// ErrExternalChange is returned by Save when the file changed on disk
+	// after it was loaded or last saved. Saving again overwrites it.
+	ErrExternalChange = errors.New("file changed on disk since it was opened")
+	// ErrTooLarge is returned by LoadCode for files over maxNativeEditSize.
+	ErrTooLarge = errors.New("file too large for the built-in editor")
+	// ErrBinary is returned by LoadCode for files that look binary.
+	ErrBinary = errors.New("binary file")
+)
+
+// diskStamp identifies the on-disk content the buffer was last synced with.
+type diskStamp struct {
+	modTime time.Time
+	size    int64
+	sum     uint64
+	missing bool
+}
+
+func stampOf(data []byte, info os.FileInfo) diskStamp {
+	return diskStamp{modTime: info.ModTime(), size: info.Size(), sum: xxhash.Sum64(data)}
+}
+
+// readStamp reads the file's current stamp. A missing file is a valid state
+// (deleted under the editor), not an error.
+func readStamp(path string) (diskStamp, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return diskStamp{missing: true}, nil
+	}
+	if err != nil {
+		return diskStamp{}, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return diskStamp{}, err
+	}
+	return stampOf(data, info), nil
+}
+
+// promptMode is which input line, if any, owns keys.
+type promptMode int
+
+const (
+	promptNone promptMode = iota
+	promptFind
+	promptReplace
+)
+
+// Code is the native editor for one file.
+type Code struct {
+	Path string // absolute path being edited
+
+	buf       *Buffer
+	disk      diskStamp
+	savedText string
+	savedRev  uint64
+	crlf      bool
+	perm      os.FileMode
+	indent    string
+
+	top, left int // first visible line and first visible cell
+	bodyH     int // body rows at the last render, for paging
+	gutterW   int // gutter cells at the last render, for mouse mapping
+	// follow asks the next render to scroll the primary caret into view. The
+	// wheel clears it so a scrolled-away viewport is not yanked back.
+	follow bool
+
+	prompt      promptMode
+	query       string
+	replacement string
+	field       int // 0 = query, 1 = replacement
+
+	// conflict is set when a save was refused because the file changed on
+	// disk; the next save overwrites deliberately.
+	conflict bool
+
+	status      string
+	statusError bool
+
+	hlRev   uint64
+	hlTheme string
+	hlLines []string
+}
+
+// LoadCode reads path into a new native editor with the caret on line
+// (1-indexed; 0 leaves it at the top).
+func LoadCode(path string, line int) (*Code, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a regular file", filepath.Base(path))
+	}
+	if info.Size() > maxNativeEditSize {
+		return nil, ErrTooLarge
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0 {
+		return nil, ErrBinary
+	}
+	text := string(data)
+	crlf := strings.Contains(text, "\r\n")
+	if crlf {
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+	}
+	c := &Code{
+		Path:      path,
+		buf:       NewBuffer(text),
+		disk:      stampOf(data, info),
+		savedText: text,
+		crlf:      crlf,
+		perm:      info.Mode().Perm(),
+		indent:    detectIndent(text),
+	}
+	c.savedRev = c.buf.Revision()
+	c.follow = true
+	if line > 0 {
+		c.buf.SetCursor(Pos{Line: line - 1})
+	}
+	return c, nil
+}
+
+// detectIndent picks tab or four spaces from the file's existing lines.
+func detectIndent(text string) string {
+	tabs, spaces := 0, 0
+	for _, line := range strings.Split(text, "\n") {
+		switch {
+		case strings.HasPrefix(line, "\t"):
+			tabs++
+		case strings.HasPrefix(line, "  "):
+			spaces++
+		}
+	}
+	if tabs > spaces {
+		return "\t"
+	}
+	return "    "
+}
+
+// Buffer exposes the text model, mainly for tests and hosts that inspect it.
+func (c *Code) Buffer() *Buffer { return c.buf }
+
+// Dirty reports whether the buffer differs from what was loaded or saved.
+func (c *Code) Dirty() bool {
+	if c == nil {
+		return false
+	}
+	if c.buf.Revision() == c.savedRev {
+		return false
+	}
+	return c.buf.Text() != c.savedText
+}
+
+// Conflicted reports whether the last save was refused for an external change.
+func (c *Code) Conflicted() bool { return c != nil && c.conflict }
+
+// Status returns the last one-line message and whether it reports an error.
+func (c *Code) Status() (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	return c.status, c.statusError
+}
+
+func (c *Code) setStatus(text string, isError bool) {
+	c.status, c.statusError = text, isError
+}
+
+// Save writes the buffer to disk. It refuses with ErrExternalChange when the
+// file changed since it was loaded or last saved, unless force is set or the
+// previous save was already refused for the same reason.
+func (c *Code) Save(force bool) error {
+	current, err := readStamp(c.Path)
+	if err != nil {
+		c.setStatus("Save failed: "+err.Error(), true)
+		return err
+	}
+	if !force && !c.conflict && !current.sameContent(c.disk) {
+		c.conflict = true
+		c.setStatus("Changed on disk — ctrl+s to overwrite, ctrl+l to reload", true)
+		return ErrExternalChange
+	}
+	text := c.buf.Text()
+	out := text
+	if c.crlf {
+		out = strings.ReplaceAll(text, "\n", "\r\n")
+	}
+	if err := writeAtomic(c.Path, []byte(out), c.perm); err != nil {
+		c.setStatus("Save failed: "+err.Error(), true)
+		return err
+	}
+	info, err := os.Stat(c.Path)
+	if err != nil {
+		c.setStatus("Save failed: "+err.Error(), true)
+		return err
+	}
+	c.disk = stampOf([]byte(out), info)
+	c.savedText = text
+	c.savedRev = c.buf.Revision()
+	c.conflict = false
+	c.setStatus("Saved "+filepath.Base(c.Path), false)
+	return nil
+}
+
+func (s diskStamp) sameContent(other diskStamp) bool {
+	if s.missing || other.missing {
+		return s.missing == other.missing
+	}
+	return s.size == other.size && s.sum == other.sum
+}
+
+// Reload replaces the buffer with the file's current contents, discarding
+// unsaved edits but keeping the caret line.
+func (c *Code) Reload() error {
+	line := c.buf.Primary().Line + 1
+	fresh, err := LoadCode(c.Path, line)
+	if err != nil {
+		c.setStatus("Reload failed: "+err.Error(), true)
+		return err
+	}
+	top := c.top
+	*c = *fresh
+	c.top = top
+	c.setStatus("Reloaded from disk", false)
+	return nil
+}
+
+// writeAtomic replaces path through a temp file in the same directory so a
+// crash mid-write never leaves a truncated file behind. A symlink is followed
+// and its target replaced, so the link itself survives the save, and the
+// target keeps its current mode; perm applies only when it no longer exists.
+func writeAtomic(path string, data []byte, perm os.FileMode) error {
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		path = real
+	}
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	if perm == 0 {
+		perm = 0o644
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".sidecar-*")
+	if err != nil {
+		return err
+	}
+	name := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(name)
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(name)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(name)
+		return err
+	}
+	if err := os.Rename(name, path); err != nil {
+		_ = os.Remove(name)
+		return err
+	}
+	return nil
+}
+
+// HandleKey applies one key press. exit is true when the user asked to leave
+// the editor; the host decides whether that needs a save confirmation.
+func (c *Code) HandleKey(msg tea.KeyPressMsg) (exit bool) {
+	c.follow = true
+	if c.prompt != promptNone {
+		c.handlePromptKey(msg)
+		return false
+	}
+	c.status, c.statusError = "", false
+	switch msg.String() {
+	case "esc":
+		if c.buf.ClearExtraCursors() {
+			return false
+		}
+		return true
+	case "ctrl+q":
+		return true
+	case "ctrl+s":
+		_ = c.Save(false)
+	case "ctrl+l":
+		if c.conflict {
+			_ = c.Reload()
+		}
+	case "ctrl+z":
+		if !c.buf.Undo() {
+			c.setStatus("Nothing to undo", false)
+		}
+	case "ctrl+y", "ctrl+shift+z":
+		if !c.buf.Redo() {
+			c.setStatus("Nothing to redo", false)
+		}
+	case "ctrl+f":
+		c.openPrompt(promptFind)
+	case "ctrl+r":
+		c.openPrompt(promptReplace)
+	case "f3", "ctrl+g":
+		c.findNext(false)
+	case "shift+f3":
+		c.findNext(true)
+	case "alt+down", "ctrl+alt+down":
+		c.buf.AddCursorVertical(1)
+	case "alt+up", "ctrl+alt+up":
+		c.buf.AddCursorVertical(-1)
+	case "up":
+		c.buf.Move(MoveUp)
+	case "down":
+		c.buf.Move(MoveDown)
+	case "left", "ctrl+b":
+		c.buf.Move(MoveLeft)
+	case "right":
+		c.buf.Move(MoveRight)
+	case "home", "ctrl+a":
+		c.buf.Move(MoveLineStart)
+	case "end", "ctrl+e":
+		c.buf.Move(MoveLineEnd)
+	case "ctrl+left", "alt+left", "alt+b":
+		c.buf.Move(MoveWordLeft)
+	case "ctrl+right", "alt+right", "alt+f":
+		c.buf.Move(MoveWordRight)
+	case "ctrl+home":
+		c.buf.Move(MoveDocStart)
+	case "ctrl+end":
+		c.buf.Move(MoveDocEnd)
+	case "pgup":
+		c.buf.MoveLines(-max(c.bodyH-1, 1))
+	case "pgdown":
+		c.buf.MoveLines(max(c.bodyH-1, 1))
+	case "enter", "ctrl+m":
+		c.buf.Newline()
+	case "backspace", "ctrl+h":
+		c.buf.Backspace()
+	case "delete", "ctrl+d":
+		c.buf.Delete()
+	case "tab":
+		c.buf.Insert(c.indent)
+	default:
+		if msg.Text != "" && !msg.Mod.Contains(tea.ModCtrl) && !msg.Mod.Contains(tea.ModAlt) && !msg.Mod.Contains(tea.ModSuper) {
+			c.buf.Insert(msg.Text)
+		}
+	}
+	return false
+}
+
+// Paste inserts pasted text at every caret as one undo unit.
+func (c *Code) Paste(text string) {
+	c.follow = true
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if c.prompt != promptNone {
+		text = strings.ReplaceAll(text, "\n", " ")
+		if c.field == 0 {
+			c.query += text
+		} else {
+			c.replacement += text
+		}
+		return
+	}
+	c.buf.Insert(text)
+}
+
+func (c *Code) openPrompt(mode promptMode) {
+	c.prompt = mode
+	c.field = 0
+	if c.query == "" {
+		c.query = c.wordUnderCaret()
+	}
+}
+
+func (c *Code) wordUnderCaret() string {
+	p := c.buf.Primary()
+	line := c.buf.lines[p.Line]
+	start, end := p.Col, p.Col
+	for start > 0 && isWordRune(line[start-1]) {
+		start--
+	}
+	for end < len(line) && isWordRune(line[end]) {
+		end++
+	}
+	return string(line[start:end])
+}
+
+func (c *Code) handlePromptKey(msg tea.KeyPressMsg) {
+	field := &c.query
+	if c.field == 1 {
+		field = &c.replacement
+	}
+	switch msg.String() {
+	case "esc":
+		c.prompt = promptNone
+	case "tab", "shift+tab":
+		if c.prompt == promptReplace {
+			c.field = 1 - c.field
+		}
+	case "enter":
+		if c.prompt == promptReplace && c.field == 1 {
+			c.replaceCurrent()
+			return
+		}
+		c.findNext(false)
+	case "shift+enter", "ctrl+p":
+		c.findNext(true)
+	case "ctrl+n":
+		c.findNext(false)
+	case "alt+a", "ctrl+alt+enter":
+		if c.prompt == promptReplace {
+			n := c.buf.ReplaceAll(c.query, c.replacement)
+			c.setStatus(fmt.Sprintf("Replaced %d occurrence(s)", n), false)
+		}
+	case "backspace", "ctrl+h":
+		if r := []rune(*field); len(r) > 0 {
+			*field = string(r[:len(r)-1])
+		}
+	case "ctrl+u":
+		*field = ""
+	default:
+		if msg.Text != "" && !msg.Mod.Contains(tea.ModCtrl) && !msg.Mod.Contains(tea.ModAlt) {
+			*field += msg.Text
+		}
+	}
+}
+
+// findNext moves the primary caret to the next (or previous) match.
+func (c *Code) findNext(backward bool) {
+	if c.query == "" {
+		c.openPrompt(promptFind)
+		return
+	}
+	from := c.buf.Primary()
+	if !backward {
+		// Start past a match the caret already sits on.
+		from.Col++
+	}
+	m, ok := c.buf.FindNext(c.query, from, backward)
+	if !ok {
+		c.setStatus("No matches for "+c.query, true)
+		return
+	}
+	c.buf.SetCursor(Pos{Line: m.Line, Col: m.Start})
+}
+
+// replaceCurrent replaces the match under the caret, then finds the next one.
+func (c *Code) replaceCurrent() {
+	p := c.buf.Primary()
+	for _, m := range c.buf.FindAll(c.query) {
+		if m.Line == p.Line && m.Start == p.Col {
+			c.buf.ReplaceMatch(m, c.replacement)
+			c.findNext(false)
+			return
+		}
+	}
+	c.findNext(false)
+}
+
+// ClickAt places a single caret at a body cell (0-indexed, relative to the
+// editor's content origin, gutter included).
+func (c *Code) ClickAt(x, y int) {
+	line := c.top + y
+	if line >= c.buf.LineCount() {
+		line = c.buf.LineCount() - 1
+	}
+	if line < 0 {
+		return
+	}
+	c.buf.SetCursor(Pos{Line: line, Col: runeColForCell(c.buf.lines[line], c.left+x-c.gutterW)})
+	c.follow = true
+}
+
+// Scroll moves the viewport by delta lines without moving the caret.
+func (c *Code) Scroll(delta int) {
+	c.top = max(min(c.top+delta, c.buf.LineCount()-1), 0)
+	c.follow = false
+}
+
+// cellCol is the display cell of rune column col, with tabs expanded.
+func cellCol(line []rune, col int) int {
+	cells := 0
+	for i := 0; i < col && i < len(line); i++ {
+		cells += runeCells(line[i], cells)
+	}
+	return cells
+}
+
+func runeCells(r rune, at int) int {
+	if r == '\t' {
+		return tabWidth - at%tabWidth
+	}
+	return max(runewidth.RuneWidth(r), 1)
+}
+
+// runeColForCell is cellCol's inverse: the rune column a display cell lands on.
+func runeColForCell(line []rune, cell int) int {
+	cells := 0
+	for i, r := range line {
+		w := runeCells(r, cells)
+		if cell < cells+(w+1)/2 {
+			return i
+		}
+		cells += w
+	}
+	return len(line)
+}
+
+func expandTabs(line []rune) string {
+	if !containsRune(line, '\t') {
+		return string(line)
+	}
+	var sb strings.Builder
+	cells := 0
+	for _, r := range line {
+		if r == '\t' {
+			n := tabWidth - cells%tabWidth
+			sb.WriteString(strings.Repeat(" ", n))
+			cells += n
+			continue
+		}
+		sb.WriteRune(r)
+		cells += runeCells(r, cells)
+	}
+	return sb.String()
+}
+
+func containsRune(line []rune, r rune) bool {
+	for _, x := range line {
+		if x == r {
+			return true
+		}
+	}
+	return false
+}
+
+// highlighted returns every line with syntax colour, recomputed only when the
+// buffer or the theme changed.
+func (c *Code) highlighted() []string {
+	theme := styles.GetSyntaxTheme()
+	if c.hlLines != nil && c.hlRev == c.buf.Revision() && c.hlTheme == theme {
+		return c.hlLines
+	}
+	plain := make([]string, c.buf.LineCount())
+	for i, line := range c.buf.lines {
+		plain[i] = expandTabs(line)
+	}
+	lines := plain
+	if out, err := filepreview.Highlight(strings.Join(plain, "\n"), filepath.Ext(c.Path), theme); err == nil {
+		if split := strings.Split(strings.TrimSuffix(out, "\n"), "\n"); len(split) == len(plain) {
+			lines = split
+		}
+	}
+	c.hlLines, c.hlRev, c.hlTheme = lines, c.buf.Revision(), theme
+	return lines
+}
+
+// scrollToPrimary keeps the primary caret inside a width x height body.
+func (c *Code) scrollToPrimary(textW, bodyH int) {
+	p := c.buf.Primary()
+	if p.Line < c.top {
+		c.top = p.Line
+	}
+	if p.Line >= c.top+bodyH {
+		c.top = p.Line - bodyH + 1
+	}
+	cell := cellCol(c.buf.lines[p.Line], p.Col)
+	if cell < c.left {
+		c.left = cell
+	}
+	if textW > 0 && cell >= c.left+textW {
+		c.left = cell - textW + 1
+	}
+}
+
+// span is a highlighted run of display cells on one visible row.
+type span struct {
+	start, end int
+	style      func(...string) string
+}
+
+// View renders the editor body and its status line into width x height cells.
+func (c *Code) View(width, height int) string {
+	if width <= 0 || height <= 0 {
+		return ""
+	}
+	bodyH := max(height-1, 1)
+	gutter := docview.NewGutterForWidth(c.buf.LineCount(), width)
+	textW := width - gutter.Width()
+	c.bodyH, c.gutterW = bodyH, gutter.Width()
+	if c.follow {
+		c.scrollToPrimary(textW, bodyH)
+		c.follow = false
+	}
+
+	lines := c.highlighted()
+	carets := make(map[int][]int)
+	for _, p := range c.buf.cursors {
+		carets[p.Line] = append(carets[p.Line], cellCol(c.buf.lines[p.Line], p.Col))
+	}
+	matches := make(map[int][]Match)
+	if c.prompt != promptNone {
+		for _, m := range c.buf.FindAll(c.query) {
+			if m.Line >= c.top && m.Line < c.top+bodyH {
+				matches[m.Line] = append(matches[m.Line], m)
+			}
+		}
+	}
+
+	rows := make([]string, 0, height)
+	for r := 0; r < bodyH; r++ {
+		li := c.top + r
+		if li >= len(lines) {
+			rows = append(rows, "")
+			continue
+		}
+		var spans []span
+		for _, m := range matches[li] {
+			spans = append(spans, span{
+				start: cellCol(c.buf.lines[li], m.Start) - c.left,
+				end:   cellCol(c.buf.lines[li], m.End) - c.left,
+				style: styles.SearchMatch.Render,
+			})
+		}
+		for _, cell := range carets[li] {
+			spans = append(spans, span{start: cell - c.left, end: cell - c.left + 1, style: styles.TextSelection.Render})
+		}
+		rows = append(rows, gutter.Number(li+1)+overlaySpans(lines[li], expandTabs(c.buf.lines[li]), c.left, textW, spans))
+	}
+	rows = append(rows, c.statusLine(width))
+	return strings.Join(rows, "\n")
+}
+
+// overlaySpans cuts the visible window out of a highlighted line and restyles
+// the given spans (window-relative cells) on top of it. plain is the same line
+// without colour, used for the restyled text. Later spans win where they
+// overlap, which puts carets above search matches.
+func overlaySpans(highlighted, plain string, left, width int, spans []span) string {
+	if width <= 0 {
+		return ""
+	}
+	if len(spans) == 0 {
+		return ansi.Cut(highlighted, left, left+width)
+	}
+	owner := make([]int, width)
+	for i := range owner {
+		owner[i] = -1
+	}
+	for i, s := range spans {
+		for cell := max(s.start, 0); cell < min(s.end, width); cell++ {
+			owner[cell] = i
+		}
+	}
+	plainW := ansi.StringWidth(plain)
+	var sb strings.Builder
+	for cell := 0; cell < width; {
+		end := cell + 1
+		for end < width && owner[end] == owner[cell] {
+			end++
+		}
+		if owner[cell] < 0 {
+			sb.WriteString(ansi.Cut(highlighted, left+cell, left+end))
+		} else {
+			text := ""
+			if left+cell < plainW {
+				text = ansi.Cut(plain, left+cell, min(left+end, plainW))
+			}
+			if pad := (end - cell) - ansi.StringWidth(text); pad > 0 && owner[cell] >= 0 && spans[owner[cell]].end-spans[owner[cell]].start == 1 {
+				// A caret past the end of the line still needs a visible cell.
+				text += strings.Repeat(" ", pad)
+			}
+			sb.WriteString(spans[owner[cell]].style(text))
+		}
+		cell = end
+	}
+	return sb.String()
+}
+
+func (c *Code) statusLine(width int) string {
+	switch c.prompt {
+	case promptFind, promptReplace:
+		count := len(c.buf.FindAll(c.query))
+		query := c.query
+		if c.field == 0 {
+			query += "▏"
+		}
+		line := "Find: " + query
+		if c.prompt == promptReplace {
+			repl := c.replacement
+			if c.field == 1 {
+				repl += "▏"
+			}
+			line += "  Replace: " + repl
+		}
+		hint := fmt.Sprintf("  %d match(es) · enter next · shift+enter prev · esc close", count)
+		if c.prompt == promptReplace {
+			hint = fmt.Sprintf("  %d match(es) · tab field · enter replace · alt+a all · esc close", count)
+		}
+		return ansi.Truncate(styles.Title.Render(line)+styles.Muted.Render(hint), width, "…")
+	}
+	if c.status != "" {
+		style := styles.Muted
+		if c.statusError {
+			style = styles.StatusDeleted
+		}
+		return ansi.Truncate(style.Render(c.status), width, "…")
+	}
+	p := c.buf.Primary()
+	info := fmt.Sprintf("Ln %d, Col %d", p.Line+1, cellCol(c.buf.lines[p.Line], p.Col)+1)
+	if n := len(c.buf.cursors); n > 1 {
+		info += fmt.Sprintf(" · %d carets", n)
+	}
+	if c.Dirty() {
+		info += " · modified"
+	}
+	return ansi.Truncate(styles.Muted.Render(info+"  ctrl+s save · ctrl+f find · ctrl+r replace · alt+↑↓ caret · esc exit"), width, "…")
+}
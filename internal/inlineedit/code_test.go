@@ -0,0 +1,200 @@
+package inlineedit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func writeTemp(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func typeText(c *Code, text string) {
+	for _, r := range text {
+		c.HandleKey(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+}
+
+func TestLoadCodePlacesCaretOnLine(t *testing.T) {
+	path := writeTemp(t, "a.go", "one\ntwo\nthree\n")
+	c, err := LoadCode(path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := c.Buffer().Primary(); p.Line != 1 {
+		t.Fatalf("caret line = %d, want 1", p.Line)
+	}
+	if c.Dirty() {
+		t.Fatal("freshly loaded file reports dirty")
+	}
+}
+
+func TestLoadCodeRefusesBinary(t *testing.T) {
+	path := writeTemp(t, "bin", "ab\x00cd")
+	if _, err := LoadCode(path, 0); !errors.Is(err, ErrBinary) {
+		t.Fatalf("err = %v, want ErrBinary", err)
+	}
+}
+
+func TestSavePreservesCRLFAndMode(t *testing.T) {
+	path := writeTemp(t, "win.txt", "a\r\nb\r\n")
+	c, err := LoadCode(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	typeText(c, "x")
+	if !c.Dirty() {
+		t.Fatal("edit did not mark the buffer dirty")
+	}
+	if err := c.Save(false); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if got, want := string(data), "xa\r\nb\r\n"; got != want {
+		t.Fatalf("saved %q, want %q", got, want)
+	}
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("mode = %v, want 0600", info.Mode().Perm())
+	}
+	if c.Dirty() {
+		t.Fatal("saved buffer still dirty")
+	}
+}
+
+func TestSaveWritesThroughSymlink(t *testing.T) {
+	target := writeTemp(t, "real.conf", "one\n")
+	if err := os.Chmod(target, 0o640); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(t.TempDir(), ".conf")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+	c, err := LoadCode(link, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	typeText(c, "x")
+	if err := c.Save(false); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("save replaced the symlink: %v, %v", info, err)
+	}
+	data, _ := os.ReadFile(target)
+	if got := string(data); got != "xone\n" {
+		t.Fatalf("target = %q", got)
+	}
+	if info, _ := os.Stat(target); info.Mode().Perm() != 0o640 {
+		t.Fatalf("mode = %v, want 0640", info.Mode().Perm())
+	}
+}
+
+func TestSaveRefusesExternalChangeOnce(t *testing.T) {
+	path := writeTemp(t, "f.txt", "base\n")
+	c, err := LoadCode(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	typeText(c, "mine ")
+	if err := os.WriteFile(path, []byte("theirs\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Save(false); !errors.Is(err, ErrExternalChange) {
+		t.Fatalf("first save err = %v, want ErrExternalChange", err)
+	}
+	if !c.Conflicted() {
+		t.Fatal("refused save did not record the conflict")
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "theirs\n" {
+		t.Fatalf("refused save touched the file: %q", data)
+	}
+
+	if err := c.Save(false); err != nil {
+		t.Fatalf("second save err = %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	if string(data) != "mine base\n" {
+		t.Fatalf("overwrite saved %q", data)
+	}
+}
+
+func TestCtrlRReplacesAllFromPrompt(t *testing.T) {
+	path := writeTemp(t, "r.txt", "cat cat\ncat\n")
+	c, err := LoadCode(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.HandleKey(tea.KeyPressMsg{Code: 'r', Mod: tea.ModCtrl})
+	c.HandleKey(tea.KeyPressMsg{Code: 'u', Mod: tea.ModCtrl})
+	typeText(c, "cat")
+	c.HandleKey(tea.KeyPressMsg{Code: tea.KeyTab})
+	typeText(c, "dog")
+	c.HandleKey(tea.KeyPressMsg{Code: 'a', Mod: tea.ModAlt})
+
+	if got, want := c.Buffer().Text(), "dog dog\ndog\n"; got != want {
+		t.Fatalf("text = %q, want %q", got, want)
+	}
+}
+
+func TestEscExitsOnlyWithoutExtraCarets(t *testing.T) {
+	path := writeTemp(t, "e.txt", "a\nb\n")
+	c, err := LoadCode(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.HandleKey(tea.KeyPressMsg{Code: tea.KeyDown, Mod: tea.ModAlt})
+	if exit := c.HandleKey(tea.KeyPressMsg{Code: tea.KeyEscape}); exit {
+		t.Fatal("esc with extra carets should collapse them, not exit")
+	}
+	if exit := c.HandleKey(tea.KeyPressMsg{Code: tea.KeyEscape}); !exit {
+		t.Fatal("esc with one caret should exit")
+	}
+}
+
+func TestViewFitsViewport(t *testing.T) {
+	path := writeTemp(t, "v.txt", strings.Repeat("line\n", 50))
+	c, err := LoadCode(path, 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	view := c.View(30, 10)
+	if got := len(strings.Split(view, "\n")); got != 10 {
+		t.Fatalf("view rows = %d, want 10", got)
+	}
+}
+
+func TestSessionRouteAsksBeforeLeavingDirtyBuffer(t *testing.T) {
+	path := writeTemp(t, "s.txt", "x\n")
+	c, err := LoadCode(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Session{Host: fakeHost{w: 40, h: 10}}
+	s.BeginNative(c, "s.txt")
+	typeText(c, "y")
+
+	if _, alive := s.Route(tea.KeyPressMsg{Code: tea.KeyEscape}); !alive || !s.ShowExitConfirm {
+		t.Fatalf("dirty esc alive=%v confirm=%v, want confirmation", alive, s.ShowExitConfirm)
+	}
+	if outcome, _ := s.HandleConfirmKey("enter"); outcome != OutcomeSave {
+		t.Fatalf("outcome = %v, want save", outcome)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "yx\n" {
+		t.Fatalf("saved %q", data)
+	}
+}
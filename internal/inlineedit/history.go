@@ -0,0 +1,151 @@
+package inlineedit
+
+import "time"
+
+// The undo model is shared by every editor: snapshots of the whole buffer,
+// coalesced into bursts of typing or deleting, bounded by count and bytes.
+
+const (
+	defaultUndoEntries = 200
+	defaultUndoBytes   = 8 << 20
+	typingBurstWindow  = 800 * time.Millisecond
+)
+
+// EditKind groups consecutive mutations into one undo unit.
+type EditKind int
+
+const (
+	EditNone EditKind = iota
+	EditTyping
+	EditDelete
+	EditPaste
+	EditCut
+	EditReplace
+)
+
+// Snapshot is the buffer and every caret before a mutation.
+type Snapshot struct {
+	Content string
+	Cursors []Pos
+}
+
+// History is an undo/redo stack of whole-buffer snapshots. The zero value is
+// ready to use with the default bounds.
+type History struct {
+	// MaxEntries and MaxBytes bound what is kept; zero uses the defaults.
+	MaxEntries int
+	MaxBytes   int
+
+	undo     []Snapshot
+	redo     []Snapshot
+	lastKind EditKind
+	lastAt   time.Time
+	bytes    int
+}
+
+// Prepare records snap as the state before a mutation of the given kind.
+func (h *History) Prepare(kind EditKind, snap Snapshot, now time.Time) {
+	h.PrepareLazy(kind, func() Snapshot { return snap }, now)
+}
+
+// PrepareLazy records the state before a mutation unless it continues the
+// current typing or delete burst, so large buffers pay one copy per undo unit
+// rather than one per key.
+func (h *History) PrepareLazy(kind EditKind, snapshot func() Snapshot, now time.Time) {
+	if kind == EditNone {
+		return
+	}
+	if (kind == EditTyping || kind == EditDelete) && h.lastKind == kind &&
+		!h.lastAt.IsZero() && now.Sub(h.lastAt) < typingBurstWindow {
+		h.lastAt = now
+		return
+	}
+	snap := snapshot()
+	if n := len(h.undo); n > 0 && h.undo[n-1].Content == snap.Content {
+		h.lastKind = kind
+		h.lastAt = now
+		return
+	}
+	h.undo = append(h.undo, snap)
+	h.bytes += len(snap.Content)
+	for _, s := range h.redo {
+		h.bytes -= len(s.Content)
+	}
+	h.redo = nil
+	h.trim()
+	h.lastKind = kind
+	h.lastAt = now
+}
+
+// CanUndo reports whether there is an undo unit.
+func (h *History) CanUndo() bool { return len(h.undo) > 0 }
+
+// CanRedo reports whether there is an undone unit to re-apply.
+func (h *History) CanRedo() bool { return len(h.redo) > 0 }
+
+// Undo pops the last undo unit, saving current for redo.
+func (h *History) Undo(current Snapshot) (Snapshot, bool) {
+	if len(h.undo) == 0 {
+		return Snapshot{}, false
+	}
+	prev := h.undo[len(h.undo)-1]
+	h.undo = h.undo[:len(h.undo)-1]
+	h.bytes -= len(prev.Content)
+	h.redo = append(h.redo, current)
+	h.bytes += len(current.Content)
+	h.trim()
+	h.lastKind = EditNone
+	h.lastAt = time.Time{}
+	return prev, true
+}
+
+// Redo pops the last undone unit, saving current for undo.
+func (h *History) Redo(current Snapshot) (Snapshot, bool) {
+	if len(h.redo) == 0 {
+		return Snapshot{}, false
+	}
+	next := h.redo[len(h.redo)-1]
+	h.redo = h.redo[:len(h.redo)-1]
+	h.bytes -= len(next.Content)
+	h.undo = append(h.undo, current)
+	h.bytes += len(current.Content)
+	h.trim()
+	h.lastKind = EditNone
+	h.lastAt = time.Time{}
+	return next, true
+}
+
+// Clone returns a copy that shares no stack with h.
+func (h *History) Clone() *History {
+	c := *h
+	c.undo = append([]Snapshot(nil), h.undo...)
+	c.redo = append([]Snapshot(nil), h.redo...)
+	return &c
+}
+
+func (h *History) limits() (entries, bytes int) {
+	entries, bytes = h.MaxEntries, h.MaxBytes
+	if entries <= 0 {
+		entries = defaultUndoEntries
+	}
+	if bytes <= 0 {
+		bytes = defaultUndoBytes
+	}
+	return entries, bytes
+}
+
+func (h *History) trim() {
+	maxEntries, maxBytes := h.limits()
+	for len(h.undo) > maxEntries {
+		h.bytes -= len(h.undo[0].Content)
+		h.undo = h.undo[1:]
+	}
+	for h.bytes > maxBytes && len(h.undo) > 1 {
+		h.bytes -= len(h.undo[0].Content)
+		h.undo = h.undo[1:]
+	}
+	for h.bytes > maxBytes && len(h.redo) > 0 {
+		h.bytes -= len(h.redo[0].Content)
+		h.redo = h.redo[1:]
+	}
+}
@@ -0,0 +1,104 @@
+package inlineedit
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEditHistoryTypingBurstIsOneUnit(t *testing.T) {
+	h := &History{}
+	now := time.Now()
+	h.Prepare(EditTyping, Snapshot{Content: "ab"}, now)
+	h.Prepare(EditTyping, Snapshot{Content: "abc"}, now.Add(100*time.Millisecond))
+	h.Prepare(EditTyping, Snapshot{Content: "abcd"}, now.Add(200*time.Millisecond))
+	if len(h.undo) != 1 {
+		t.Fatalf("burst undo entries = %d, want 1", len(h.undo))
+	}
+	if h.undo[0].Content != "ab" {
+		t.Fatalf("burst snapshot = %q, want content before first keystroke", h.undo[0].Content)
+	}
+}
+
+func TestEditHistoryTypingBurstCopiesOneSnapshot(t *testing.T) {
+	h := &History{}
+	now := time.Now()
+	calls := 0
+	snapshot := func() Snapshot {
+		calls++
+		return Snapshot{Content: strings.Repeat("large note", 1000)}
+	}
+	h.PrepareLazy(EditTyping, snapshot, now)
+	h.PrepareLazy(EditTyping, snapshot, now.Add(100*time.Millisecond))
+	h.PrepareLazy(EditTyping, snapshot, now.Add(200*time.Millisecond))
+	if calls != 1 {
+		t.Fatalf("snapshot copies = %d, want one per typing burst", calls)
+	}
+}
+
+func TestEditHistoryPasteIsOwnUnit(t *testing.T) {
+	h := &History{}
+	now := time.Now()
+	h.Prepare(EditTyping, Snapshot{Content: "ab"}, now)
+	h.Prepare(EditPaste, Snapshot{Content: "abc"}, now.Add(50*time.Millisecond))
+	if len(h.undo) != 2 {
+		t.Fatalf("paste after typing = %d units, want 2", len(h.undo))
+	}
+}
+
+func TestEditHistoryExpiredBurstStartsNewUnit(t *testing.T) {
+	h := &History{}
+	now := time.Now()
+	h.Prepare(EditTyping, Snapshot{Content: "a"}, now)
+	h.Prepare(EditTyping, Snapshot{Content: "ab"}, now.Add(typingBurstWindow+time.Millisecond))
+	if len(h.undo) != 2 {
+		t.Fatalf("expired burst = %d units, want 2", len(h.undo))
+	}
+}
+
+func TestEditHistoryUndoRedoRoundTrip(t *testing.T) {
+	h := &History{}
+	h.Prepare(EditPaste, Snapshot{Content: "one", Cursors: []Pos{{Col: 0}}}, time.Now())
+	prev, ok := h.Undo(Snapshot{Content: "two", Cursors: []Pos{{Col: 3}}})
+	if !ok || prev.Content != "one" {
+		t.Fatalf("undo = (%q, %v), want one", prev.Content, ok)
+	}
+	if !h.CanRedo() {
+		t.Fatal("expected redo after undo")
+	}
+	next, ok := h.Redo(Snapshot{Content: "one", Cursors: []Pos{{Col: 0}}})
+	if !ok || next.Content != "two" {
+		t.Fatalf("redo = (%q, %v), want two", next.Content, ok)
+	}
+}
+
+func TestEditHistoryCapsEntriesAndBytes(t *testing.T) {
+	h := &History{}
+	now := time.Now()
+	for i := 0; i < defaultUndoEntries+10; i++ {
+		h.Prepare(EditPaste, Snapshot{Content: strings.Repeat("x", 8) + string(rune('A'+i%26))}, now.Add(time.Duration(i)*time.Second))
+	}
+	if len(h.undo) > defaultUndoEntries {
+		t.Fatalf("undo entries = %d, want <= %d", len(h.undo), defaultUndoEntries)
+	}
+
+	h = &History{}
+	big := strings.Repeat("n", defaultUndoBytes/3)
+	for i := 0; i < 6; i++ {
+		h.Prepare(EditPaste, Snapshot{Content: big + string(rune('0'+i))}, now.Add(time.Duration(i)*time.Second))
+	}
+	if h.bytes > defaultUndoBytes {
+		t.Fatalf("retained bytes = %d, want <= %d", h.bytes, defaultUndoBytes)
+	}
+	if len(h.undo) < 1 {
+		t.Fatal("byte cap dropped every snapshot")
+	}
+
+	h = &History{MaxEntries: 3}
+	for i := 0; i < 5; i++ {
+		h.Prepare(EditPaste, Snapshot{Content: string(rune('a' + i))}, now.Add(time.Duration(i)*time.Second))
+	}
+	if len(h.undo) != 3 {
+		t.Fatalf("undo entries = %d, want the configured 3", len(h.undo))
+	}
+}
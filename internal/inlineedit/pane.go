@@ -5,6 +5,7 @@ import (
 	"time"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/marcus/sidecar/internal/features"
 	"github.com/marcus/sidecar/internal/msg"
 	"github.com/marcus/sidecar/internal/tty"
 )
@@ -25,6 +26,9 @@ type StartedMsg struct {
 	Path        string // the path the host knows the document by
 	Activation  uint64
 	Epoch       uint64
+	// Native carries a loaded built-in editor instead of a tmux session;
+	// SessionName and Editor are empty when it is set.
+	Native *Code
 }
 
 // ExitedMsg reports that a pane's editor session ended.
@@ -49,6 +53,27 @@ type StartOptions struct {
 	Epoch         uint64
 }
 
+// DisabledMessage is the toast shown when Enabled is false. It names both
+// flags, since either one turns inline editing back on.
+var DisabledMessage = "Inline edit is disabled (enable features." + features.NativeInlineEdit.Name +
+	" or features." + features.TmuxInlineEdit.Name + ")"
+
+// Enabled reports whether a pane may open an editor at all: the built-in one
+// or the tmux one.
+func Enabled() bool {
+	return features.IsEnabled(features.NativeInlineEdit.Name) ||
+		features.IsEnabled(features.TmuxInlineEdit.Name)
+}
+
+// StartPreferred opens the built-in editor when native_inline_edit is on and a
+// tmux session otherwise.
+func StartPreferred(opts StartOptions) tea.Cmd {
+	if features.IsEnabled(features.NativeInlineEdit.Name) {
+		return StartNative(opts)
+	}
+	return Start(opts)
+}
+
 // Start launches a tmux editor session for a pane leaf. It reports failures as
 // toasts rather than errors: there is nowhere else in a pane to say them.
 func Start(opts StartOptions) tea.Cmd {
@@ -88,10 +113,50 @@ func Start(opts StartOptions) tea.Cmd {
 	}
 }
 
-// Route hands one message to the live tty model and reports whether the session
+// StartNative loads a file into the built-in editor for a pane leaf. It
+// reports through the same StartedMsg as Start, so a host's start handling
+// stays one path; loading runs off the update loop like a tmux start does.
+func StartNative(opts StartOptions) tea.Cmd {
+	return func() tea.Msg {
+		code, err := LoadCode(opts.AbsPath, opts.Line)
+		if err != nil {
+			return msg.ToastMsg{
+				Message:  fmt.Sprintf("Failed to open editor: %v", err),
+				Duration: 3 * time.Second,
+				IsError:  true,
+			}
+		}
+		return StartedMsg{
+			Surface:    opts.Surface,
+			LeafID:     opts.LeafID,
+			Path:       opts.Path,
+			Activation: opts.Activation,
+			Epoch:      opts.Epoch,
+			Native:     code,
+		}
+	}
+}
+
+// BeginStarted records a StartedMsg as the live session, whichever editor it
+// carries.
+func (s *Session) BeginStarted(m StartedMsg) tea.Cmd {
+	if m.Native != nil {
+		s.BeginNative(m.Native, m.Path)
+		return nil
+	}
+	return s.Begin(m.SessionName, m.Editor, m.Path)
+}
+
+// Route hands one message to the live editor and reports whether the session
 // is still attached afterwards. A host that gets alive=false owes its own exit
 // bookkeeping — the editor quit on its own.
+//
+// A native editor that is asked to exit with unsaved edits opens the exit
+// confirmation instead of reporting alive=false.
 func (s *Session) Route(m tea.Msg) (cmd tea.Cmd, alive bool) {
+	if s != nil && s.Native != nil && s.Active {
+		return s.routeNative(m)
+	}
 	if s == nil || s.Model == nil || !s.Active {
 		return nil, false
 	}
@@ -99,6 +164,31 @@ func (s *Session) Route(m tea.Msg) (cmd tea.Cmd, alive bool) {
 	return cmd, s.Model.IsActive()
 }
 
+func (s *Session) routeNative(m tea.Msg) (tea.Cmd, bool) {
+	switch m := m.(type) {
+	case tea.KeyPressMsg:
+		if !s.Native.HandleKey(m) {
+			return nil, true
+		}
+		if s.Native.Dirty() {
+			s.ShowExitConfirm = true
+			s.ConfirmSelection = 0
+			return nil, true
+		}
+		return nil, false
+	case tea.PasteMsg:
+		s.Native.Paste(m.Content)
+	case tea.MouseWheelMsg:
+		switch m.Button {
+		case tea.MouseWheelUp:
+			s.Native.Scroll(-3)
+		case tea.MouseWheelDown:
+			s.Native.Scroll(3)
+		}
+	}
+	return nil, true
+}
+
 // Outcome is what the exit confirmation decided.
 type Outcome int
 
@@ -131,7 +221,12 @@ func (s *Session) HandleConfirmKey(key string) (outcome Outcome, handled bool) {
 		s.ShowExitConfirm = false
 		switch s.ConfirmSelection {
 		case 0:
-			s.SaveAndQuit()
+			if !s.SaveAndQuit() {
+				// The native write failed or met an external change; stay in
+				// the editor, whose status line now says why.
+				s.ClearPendingClick()
+				return OutcomeCancel, true
+			}
 			return OutcomeSave, true
 		case 1:
 			return OutcomeDiscard, true
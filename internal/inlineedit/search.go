@@ -0,0 +1,126 @@
+package inlineedit
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Match is one search hit: a run of runes on a single line. Queries never span
+// lines, which keeps replace a per-line operation.
+type Match struct {
+	Line  int
+	Start int
+	End   int
+}
+
+// smartCase reports whether a query should match case-sensitively: only when
+// it contains an upper-case letter.
+func smartCase(query string) bool {
+	for _, r := range query {
+		if unicode.IsUpper(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// FindAll returns every non-overlapping match of query in document order.
+func (b *Buffer) FindAll(query string) []Match {
+	if query == "" || strings.Contains(query, "\n") {
+		return nil
+	}
+	needle := []rune(query)
+	fold := !smartCase(query)
+	if fold {
+		needle = []rune(strings.ToLower(query))
+	}
+	var out []Match
+	for li, line := range b.lines {
+		hay := line
+		if fold {
+			hay = []rune(strings.ToLower(string(line)))
+			if len(hay) != len(line) {
+				// Case folding changed the rune count; fall back to exact
+				// matching for this line rather than mis-address columns.
+				hay = line
+			}
+		}
+		for col := 0; col+len(needle) <= len(hay); {
+			if runesEqual(hay[col:col+len(needle)], needle) {
+				out = append(out, Match{Line: li, Start: col, End: col + len(needle)})
+				col += len(needle)
+				continue
+			}
+			col++
+		}
+	}
+	return out
+}
+
+func runesEqual(a, b []rune) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// FindNext returns the first match at or after from (or before it when
+// backward), wrapping around the document.
+func (b *Buffer) FindNext(query string, from Pos, backward bool) (Match, bool) {
+	matches := b.FindAll(query)
+	if len(matches) == 0 {
+		return Match{}, false
+	}
+	if backward {
+		for i := len(matches) - 1; i >= 0; i-- {
+			if (Pos{Line: matches[i].Line, Col: matches[i].Start}).before(from) {
+				return matches[i], true
+			}
+		}
+		return matches[len(matches)-1], true
+	}
+	for _, m := range matches {
+		if !(Pos{Line: m.Line, Col: m.Start}).before(from) {
+			return m, true
+		}
+	}
+	return matches[0], true
+}
+
+// ReplaceMatch replaces one match with repl as its own undo unit and leaves
+// the primary caret after the replacement.
+func (b *Buffer) ReplaceMatch(m Match, repl string) {
+	if m.Line < 0 || m.Line >= len(b.lines) || m.End > len(b.lines[m.Line]) || m.Start > m.End {
+		return
+	}
+	b.prepare(EditReplace)
+	b.ClearExtraCursors()
+	col := b.replaceRange(m.Line, m.Start, m.End, []rune(repl))
+	b.cursors[0] = Pos{Line: m.Line, Col: col}
+	b.changed()
+}
+
+// ReplaceAll replaces every match of query with repl as a single undo unit and
+// returns how many were replaced.
+func (b *Buffer) ReplaceAll(query, repl string) int {
+	matches := b.FindAll(query)
+	if len(matches) == 0 {
+		return 0
+	}
+	b.prepare(EditReplace)
+	b.ClearExtraCursors()
+	with := []rune(repl)
+	// Bottom-up, so earlier matches keep their columns.
+	for i := len(matches) - 1; i >= 0; i-- {
+		m := matches[i]
+		b.replaceRange(m.Line, m.Start, m.End, with)
+	}
+	b.cursors[0] = b.clamp(b.cursors[0])
+	b.changed()
+	return len(matches)
+}
@@ -1,8 +1,13 @@
-// Package inlineedit owns the shared lifecycle of an inline tmux-PTY editor
-// hosted inside a pane: session start bookkeeping, activation-epoch guards,
+// Package inlineedit owns the shared lifecycle of an inline editor hosted
+// inside a pane: session start bookkeeping, activation-epoch guards,
 // stale-start cleanup, mouse/cursor coordinate translation, and the
 // save/discard/cancel exit confirmation.
 //
+// A session drives one of two editors. The tmux-PTY editor runs the user's
+// $EDITOR in a tmux session and mirrors it through a tty model. The native
+// editor (Code) is built in: a buffer with syntax highlighting, undo/redo,
+// search/replace and column carets that needs neither tmux nor $EDITOR.
+//
 // The package is deliberately host-agnostic. It never measures the host's
 // layout: the host supplies its editor viewport and screen origin through the
 // Host contract, so dimension drift is a host bug rather than a mirrored
@@ -33,6 +38,10 @@ type Session struct {
 	Model *tty.Model
 	Host  Host
 
+	// Native, when set, is the built-in editor driving this session instead
+	// of the tmux model. The tmux fields below stay empty while it is set.
+	Native *Code
+
 	// Redraw, when set, is batched after operations that change the editor's
 	// geometry so a host that caches its render can invalidate it.
 	Redraw func() tea.Cmd
@@ -77,8 +86,12 @@ func (s *Session) Target() tty.EditorSession {
 	return tty.EditorSession{Name: s.Name, Editor: s.EditorCmd}
 }
 
-// IsModelActive reports whether the underlying tty model is attached.
+// IsModelActive reports whether the underlying editor is attached: the tty
+// model, or a native editor, which is attached for as long as it is set.
 func (s *Session) IsModelActive() bool {
+	if s != nil && s.Native != nil {
+		return true
+	}
 	return s != nil && s.Model != nil && s.Model.IsActive()
 }
 
@@ -105,10 +118,23 @@ func (s *Session) Begin(name, editorCmd, path string) tea.Cmd {
 	return s.Model.Open(tty.Target{Session: name})
 }
 
+// BeginNative records a loaded native editor as the live session. There is no
+// process to attach, so nothing is returned for the host to run.
+func (s *Session) BeginNative(code *Code, path string) {
+	if s == nil || code == nil {
+		return
+	}
+	s.Active = true
+	s.Native = code
+	s.Name = ""
+	s.EditorCmd = ""
+	s.Path = path
+}
+
 // Reopen re-attaches the tty model to the session already recorded here, used
 // when a host returns to a pane/tab that was left in edit mode.
 func (s *Session) Reopen() tea.Cmd {
-	if s == nil || s.Model == nil || s.Name == "" {
+	if s == nil || s.Native != nil || s.Model == nil || s.Name == "" {
 		return nil
 	}
 	width, height := s.Viewport()
@@ -118,7 +144,7 @@ func (s *Session) Reopen() tea.Cmd {
 
 // ResizeToViewport re-sizes the PTY to the host's current viewport.
 func (s *Session) ResizeToViewport() tea.Cmd {
-	if s == nil || s.Model == nil {
+	if s == nil || s.Native != nil || s.Model == nil {
 		return nil
 	}
 	width, height := s.Viewport()
@@ -159,6 +185,7 @@ func (s *Session) Reset() {
 	s.EditorCmd = ""
 	s.Activation++
 	s.Dragging = false
+	s.Native = nil
 	if s.Model != nil {
 		s.Model.Close()
 	}
@@ -169,24 +196,57 @@ func (s *Session) Exit() {
 	if s == nil {
 		return
 	}
-	s.Target().Kill()
+	if s.Native == nil {
+		s.Target().Kill()
+	}
 	s.Reset()
 }
 
-// IsAlive reports whether the tmux session still exists.
+// IsAlive reports whether the tmux session still exists. A native editor has
+// no process to lose, so it is alive until the session resets.
 func (s *Session) IsAlive() bool {
+	if s != nil && s.Native != nil {
+		return true
+	}
 	if s == nil || s.Name == "" {
 		return false
 	}
 	return s.Target().IsAlive()
 }
 
-// SaveAndQuit asks the editor to write and quit.
-func (s *Session) SaveAndQuit() {
+// SaveAndQuit asks the editor to write and quit. A native editor writes
+// synchronously, so it reports whether the write happened; a tmux editor's
+// quit is fire-and-forget and always reports true.
+func (s *Session) SaveAndQuit() bool {
+	if s != nil && s.Native != nil {
+		return s.Native.Save(false) == nil
+	}
 	if s == nil || s.Name == "" {
-		return
+		return true
 	}
 	s.Target().SaveAndQuit()
+	return true
+}
+
+// View renders the editor body at the host's viewport.
+func (s *Session) View() string {
+	if s == nil {
+		return ""
+	}
+	if s.Native != nil {
+		width, height := s.Viewport()
+		return s.Native.View(width, height)
+	}
+	if s.Model == nil {
+		return ""
+	}
+	return s.Model.View()
+}
+
+// Dirty reports unsaved native edits. A tmux editor owns its own buffer, so
+// from here it never looks dirty.
+func (s *Session) Dirty() bool {
+	return s != nil && s.Native != nil && s.Native.Dirty()
 }
 
 // NativeActive reports whether the embedded terminal owns input right now.
@@ -198,6 +258,9 @@ func (s *Session) NativeActive() bool {
 // PreferredMouseMode returns the terminal's preferred mouse mode when native
 // is true, and all-motion hover otherwise.
 func (s *Session) PreferredMouseMode(native bool) tea.MouseMode {
+	if native && s != nil && s.Native != nil {
+		return tea.MouseModeCellMotion
+	}
 	if native && s != nil && s.Model != nil {
 		return s.Model.PreferredMouseMode()
 	}
@@ -230,7 +293,7 @@ func (s *Session) MouseCoords(x, y int) (col, row int, ok bool) {
 	// than assuming they line up (td-73fa86). A miss from an active editor
 	// means the click landed in letterbox padding, so it must not fall back to
 	// the raw mapping and forward a cell the pane does not have.
-	if s.IsModelActive() {
+	if s.Native == nil && s.IsModelActive() {
 		return s.Model.PaneCoords(relX+1, relY+1)
 	}
 	return relX + 1, relY + 1, true
@@ -238,8 +301,11 @@ func (s *Session) MouseCoords(x, y int) (col, row int, ok bool) {
 
 // Cursor exposes the editor's native cursor in host-local coordinates,
 // clamped to the host rect.
+//
+// The native editor draws its own carets (there may be several), so it has no
+// terminal cursor to expose.
 func (s *Session) Cursor(hostWidth, hostHeight int) *tea.Cursor {
-	if s == nil || s.Model == nil || s.Host == nil {
+	if s == nil || s.Native != nil || s.Model == nil || s.Host == nil {
 		return nil
 	}
 	cursor := s.Model.Cursor()
@@ -261,13 +327,21 @@ func (s *Session) Cursor(hostWidth, hostHeight int) *tea.Cursor {
 
 // SizeIndicator reports what part of a larger tmux pane is hidden, if any.
 func (s *Session) SizeIndicator() string {
-	if s == nil || s.Model == nil {
+	if s == nil || s.Native != nil || s.Model == nil {
 		return ""
 	}
 	return s.Model.SizeIndicator()
 }
 
 func (s *Session) forwardMouse(button, col, row int, release bool) tea.Cmd {
+	if s != nil && s.Native != nil {
+		// The native editor places its caret on press and follows a drag;
+		// release carries no extra meaning.
+		if !release {
+			s.Native.ClickAt(col-1, row-1)
+		}
+		return nil
+	}
 	if s == nil || !s.IsModelActive() || s.Name == "" || !s.Model.PaneMouseReporting() {
 		return nil
 	}
@@ -358,6 +432,14 @@ func (s *Session) EditingHeader(title string) string {
 	var sb strings.Builder
 	sb.WriteString(styles.Title.Render("Editing: " + title))
 	sb.WriteString("  ")
+	if s != nil && s.Native != nil {
+		hint := "(Ctrl+S save, Esc to exit)"
+		if s.Native.Dirty() {
+			hint = "[modified] " + hint
+		}
+		sb.WriteString(styles.Muted.Render(hint))
+		return sb.String()
+	}
 	sb.WriteString(styles.Muted.Render("(Ctrl+\\ or ESC ESC to exit)"))
 	if indicator := s.SizeIndicator(); indicator != "" {
 		sb.WriteString("  ")
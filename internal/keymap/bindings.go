@@ -16,6 +16,8 @@ func DefaultBindings() []Binding {
 		{Key: "W", Command: "switch-worktree", Context: "global"},
 		{Key: "#", Command: "switch-theme", Context: "global"},
 		{Key: "^", Command: "open-in", Context: "global"},
+		// ' is vim's jump-to-mark; the picker lists this project's bookmarks.
+		{Key: "'", Command: "bookmarks", Context: "global"},
 		// K: Overview (Kanban board). Bare O is taken by open-in. Shell delete is D.
 		{Key: "K", Command: "toggle-overview", Context: "global"},
 		{Key: "i", Command: "open-issue", Context: "global"},
@@ -33,6 +35,13 @@ func DefaultBindings() []Binding {
 		// every plugin's way. Handled in handleKeyMsg; registered so the
 		// palette, help and rebinding find it.
 		{Key: "alt+e", Command: "expand-toast", Context: "global"},
+		// A toast's quick actions: the centre's keys behind alt, since a toast
+		// takes no focus. Each acts on the top toast offering it and otherwise
+		// falls through. Handled in handleKeyMsg.
+		{Key: "alt+y", Command: "toast-approve", Context: "global"},
+		{Key: "alt+x", Command: "toast-deny", Context: "global"},
+		{Key: "alt+m", Command: "toast-message", Context: "global"},
+		{Key: "alt+o", Command: "toast-open-diff", Context: "global"},
 		// Comma is the conventional settings key and is otherwise unbound.
 		{Key: ",", Command: "open-configuration", Context: "global"},
 		{Key: "r", Command: "refresh", Context: "global"},
@@ -136,6 +145,13 @@ func DefaultBindings() []Binding {
 		{Key: "1", Command: "jump-target", Context: "notification-centre"},
 		{Key: "d", Command: "dismiss", Context: "notification-centre"},
 		{Key: "D", Command: "dismiss-group", Context: "notification-centre"},
+		// z: do not disturb, the quiet that quiet hours give on a schedule.
+		{Key: "z", Command: "toggle-dnd", Context: "notification-centre"},
+		// Quick actions answer the selected entry's waiting agent in place.
+		{Key: "y", Command: "approve", Context: "notification-centre"},
+		{Key: "x", Command: "deny", Context: "notification-centre"},
+		{Key: "m", Command: "send-message", Context: "notification-centre"},
+		{Key: "o", Command: "open-diff", Context: "notification-centre"},
 		{Key: "esc", Command: "close-notification-centre", Context: "notification-centre"},
 		// The panel is a stop on the focus cycle, so tab moves on from it the
 		// way it moves on from any pane — back to the surface underneath,
@@ -447,10 +463,13 @@ func DefaultBindings() []Binding {
 		{Key: "Z", Command: "stash-pop", Context: "git-status"},
 		{Key: "ctrl+z", Command: "stash-apply", Context: "git-status"},
 		{Key: "O", Command: "open-in-file-browser", Context: "git-status"},
+		{Key: "M", Command: "resolve-conflicts", Context: "git-status"},
 		{Key: "o", Command: "open-in-github", Context: "git-status"},
 		{Key: "y", Command: "yank-file", Context: "git-status"},
 		{Key: "Y", Command: "yank-path", Context: "git-status"},
 		{Key: "D", Command: "discard-changes", Context: "git-status"},
+		{Key: "ctrl+u", Command: "update-submodule", Context: "git-status"},
+		{Key: "ctrl+r", Command: "undo-history", Context: "git-status"},
 		{Key: "\\", Command: "toggle-sidebar", Context: "git-status"},
 		{Key: "+", Command: "resize-pane-grow", Context: "git-status"},
 		{Key: "-", Command: "resize-pane-shrink", Context: "git-status"},
@@ -471,6 +490,9 @@ func DefaultBindings() []Binding {
 		{Key: "N", Command: "prev-match", Context: "git-status-commits"},
 		{Key: "o", Command: "open-in-github", Context: "git-status-commits"},
 		{Key: "v", Command: "toggle-graph", Context: "git-status-commits"},
+		{Key: "R", Command: "rebase", Context: "git-status-commits"},
+		{Key: "a", Command: "commit-actions", Context: "git-status-commits"},
+		{Key: "ctrl+r", Command: "undo-history", Context: "git-status-commits"},
 		{Key: "P", Command: "push", Context: "git-status-commits"},
 		{Key: "L", Command: "pull", Context: "git-status-commits"},
 		{Key: "\\", Command: "toggle-sidebar", Context: "git-status-commits"},
@@ -567,9 +589,59 @@ func DefaultBindings() []Binding {
 		{Key: "esc", Command: "dismiss", Context: "git-error"},
 
 		// Git pull conflict context
+		{Key: "r", Command: "resolve-conflicts", Context: "git-pull-conflict"},
 		{Key: "a", Command: "abort-pull", Context: "git-pull-conflict"},
 		{Key: "esc", Command: "dismiss", Context: "git-pull-conflict"},
 
+		// Git conflict resolver
+		{Key: "o", Command: "take-ours", Context: "git-conflict"},
+		{Key: "t", Command: "take-theirs", Context: "git-conflict"},
+		{Key: "b", Command: "take-both", Context: "git-conflict"},
+		{Key: "B", Command: "take-base", Context: "git-conflict"},
+		{Key: "O", Command: "take-ours-all", Context: "git-conflict"},
+		{Key: "T", Command: "take-theirs-all", Context: "git-conflict"},
+		{Key: "x", Command: "clear-choice", Context: "git-conflict"},
+		{Key: "e", Command: "edit-conflict", Context: "git-conflict"},
+		{Key: "s", Command: "mark-resolved", Context: "git-conflict"},
+		{Key: "c", Command: "continue-operation", Context: "git-conflict"},
+		{Key: "a", Command: "abort-pull", Context: "git-conflict"},
+		{Key: ",", Command: "prev-file", Context: "git-conflict"},
+		{Key: ".", Command: "next-file", Context: "git-conflict"},
+		{Key: "esc", Command: "close", Context: "git-conflict"},
+
+		// Git interactive rebase context
+		{Key: "enter", Command: "run-rebase", Context: "git-rebase"},
+		{Key: "r", Command: "reword", Context: "git-rebase"},
+		{Key: "s", Command: "squash", Context: "git-rebase"},
+		{Key: "f", Command: "fixup", Context: "git-rebase"},
+		{Key: "e", Command: "edit-commit", Context: "git-rebase"},
+		{Key: "d", Command: "drop", Context: "git-rebase"},
+		{Key: "p", Command: "pick", Context: "git-rebase"},
+		{Key: "J", Command: "move-down", Context: "git-rebase"},
+		{Key: "K", Command: "move-up", Context: "git-rebase"},
+		{Key: "esc", Command: "cancel", Context: "git-rebase"},
+
+		// Git commit actions menu, name prompt and reset confirmation
+		{Key: "c", Command: "cherry-pick", Context: "git-commit-actions"},
+		{Key: "v", Command: "revert-commit", Context: "git-commit-actions"},
+		{Key: "b", Command: "branch-here", Context: "git-commit-actions"},
+		{Key: "t", Command: "tag-here", Context: "git-commit-actions"},
+		{Key: "s", Command: "reset-soft", Context: "git-commit-actions"},
+		{Key: "m", Command: "reset-mixed", Context: "git-commit-actions"},
+		{Key: "h", Command: "reset-hard", Context: "git-commit-actions"},
+		{Key: "esc", Command: "cancel", Context: "git-commit-actions"},
+		{Key: "enter", Command: "create-ref", Context: "git-commit-ref"},
+		{Key: "esc", Command: "cancel", Context: "git-commit-ref"},
+		{Key: "y", Command: "confirm-reset", Context: "git-confirm-reset"},
+		{Key: "esc", Command: "cancel", Context: "git-confirm-reset"},
+
+		// Git undo history browser
+		{Key: "u", Command: "undo-selected", Context: "git-undo"},
+		{Key: "enter", Command: "undo-selected", Context: "git-undo"},
+		{Key: "esc", Command: "cancel", Context: "git-undo"},
+		{Key: "y", Command: "confirm-undo", Context: "git-confirm-undo"},
+		{Key: "esc", Command: "cancel", Context: "git-confirm-undo"},
+
 		// Git stash pop context
 		{Key: "y", Command: "confirm-pop", Context: "git-stash-pop"},
 		{Key: "esc", Command: "dismiss", Context: "git-stash-pop"},
@@ -578,6 +650,8 @@ func DefaultBindings() []Binding {
 		{Key: "ctrl+s", Command: "execute-commit", Context: "git-commit"},
 		{Key: "ctrl+enter", Command: "execute-commit", Context: "git-commit"},
 		{Key: "esc", Command: "cancel", Context: "git-commit"},
+		{Key: "ctrl+t", Command: "toggle-trailers", Context: "git-commit"},
+		{Key: "ctrl+g", Command: "toggle-signing", Context: "git-commit"},
 
 		// Git history context
 		{Key: "esc", Command: "close-history", Context: "git-history"},
@@ -594,6 +668,9 @@ func DefaultBindings() []Binding {
 		// collapsed toast stack) stands aside only for a context that has
 		// claimed the key, and an unregistered claim is invisible to that rule.
 		{Key: "alt+e", Command: "expand-all", Context: "conversations-content-search"},
+		// alt+x collapses them, and is registered for the same reason: the
+		// global alt+x denies a waiting agent's toast.
+		{Key: "alt+x", Command: "collapse-all", Context: "conversations-content-search"},
 		{Key: "tab", Command: "switch-pane", Context: "conversations-sidebar"},
 		{Key: "shift+tab", Command: "switch-pane", Context: "conversations-sidebar"},
 		{Key: "a", Command: "new-session", Context: "conversations-sidebar"},
@@ -656,6 +733,10 @@ func DefaultBindings() []Binding {
 		{Key: "a", Command: "create-file", Context: "file-browser-tree"},
 		{Key: "A", Command: "create-dir", Context: "file-browser-tree"},
 		{Key: "D", Command: "delete", Context: "file-browser-tree"},
+		{Key: "space", Command: "toggle-select", Context: "file-browser-tree"},
+		{Key: "V", Command: "visual-select", Context: "file-browser-tree"},
+		{Key: "S", Command: "stage", Context: "file-browser-tree"},
+		{Key: "U", Command: "restore-trash", Context: "file-browser-tree"},
 		{Key: "y", Command: "yank", Context: "file-browser-tree"},
 		{Key: "Y", Command: "copy-path", Context: "file-browser-tree"},
 		{Key: "p", Command: "paste", Context: "file-browser-tree"},
@@ -668,8 +749,10 @@ func DefaultBindings() []Binding {
 		{Key: "e", Command: "edit", Context: "file-browser-tree"},
 		{Key: "E", Command: "edit-external", Context: "file-browser-tree"},
 		{Key: "B", Command: "blame", Context: "file-browser-tree"},
+		{Key: "b", Command: "bookmark", Context: "file-browser-tree"},
 		{Key: "\\", Command: "toggle-sidebar", Context: "file-browser-tree"},
 		{Key: "H", Command: "toggle-ignored", Context: "file-browser-tree"},
+		{Key: "C", Command: "toggle-changed", Context: "file-browser-tree"},
 		{Key: "+", Command: "resize-pane-grow", Context: "file-browser-tree"},
 		{Key: "-", Command: "resize-pane-shrink", Context: "file-browser-tree"},
 
@@ -691,7 +774,10 @@ func DefaultBindings() []Binding {
 		{Key: "e", Command: "edit", Context: "file-browser-preview"},
 		{Key: "E", Command: "edit-external", Context: "file-browser-preview"},
 		{Key: "B", Command: "blame", Context: "file-browser-preview"},
+		{Key: "b", Command: "bookmark", Context: "file-browser-preview"},
 		{Key: "m", Command: "toggle-markdown", Context: "file-browser-preview"},
+		{Key: "v", Command: "toggle-structured", Context: "file-browser-preview"},
+		{Key: "F", Command: "toggle-follow", Context: "file-browser-preview"},
 		{Key: "esc", Command: "back", Context: "file-browser-preview"},
 		{Key: "h", Command: "back", Context: "file-browser-preview"},
 		{Key: "y", Command: "yank-contents", Context: "file-browser-preview"},
@@ -701,6 +787,16 @@ func DefaultBindings() []Binding {
 		{Key: "+", Command: "resize-pane-grow", Context: "file-browser-preview"},
 		{Key: "-", Command: "resize-pane-shrink", Context: "file-browser-preview"},
 
+		// File browser structured preview context (JSON/YAML tree, CSV/TSV table, SQLite)
+		{Key: "enter", Command: "toggle-node", Context: "file-browser-structured"},
+		{Key: "c", Command: "copy-node", Context: "file-browser-structured"},
+		{Key: "s", Command: "sort-column", Context: "file-browser-structured"},
+		{Key: "n", Command: "next-page", Context: "file-browser-structured"},
+		{Key: "p", Command: "prev-page", Context: "file-browser-structured"},
+		{Key: "v", Command: "toggle-structured", Context: "file-browser-structured"},
+		{Key: "tab", Command: "switch-pane", Context: "file-browser-structured"},
+		{Key: "esc", Command: "back", Context: "file-browser-structured"},
+
 		// File browser tree search context
 		{Key: "esc", Command: "cancel", Context: "file-browser-search"},
 		{Key: "enter", Command: "confirm", Context: "file-browser-search"},
@@ -772,6 +868,9 @@ func DefaultBindings() []Binding {
 		{Key: "t", Command: "attach", Context: "workspace-list"},
 		{Key: "S", Command: "stop-agent", Context: "workspace-list"},
 		{Key: "O", Command: "open-in-git", Context: "workspace-list"},
+		{Key: "C", Command: "conflict-matrix", Context: "workspace-list"},
+		{Key: "L", Command: "ci-log", Context: "workspace-list"},
+		{Key: "c", Command: "review-comments", Context: "workspace-list"},
 		{Key: "l", Command: "focus-right", Context: "workspace-list"},
 		{Key: "right", Command: "focus-right", Context: "workspace-list"},
 		{Key: "tab", Command: "switch-pane", Context: "workspace-list"},
@@ -785,6 +884,17 @@ func DefaultBindings() []Binding {
 		{Key: "-", Command: "resize-pane-shrink", Context: "workspace-list"},
 		{Key: "ctrl+t", Command: "toggle-terminal", Context: "workspace-list"},
 
+		// Workspace conflict matrix context
+		{Key: "esc", Command: "close", Context: "workspace-conflicts"},
+
+		// Workspace review threads context
+		{Key: "esc", Command: "close", Context: "workspace-reviews"},
+		{Key: "r", Command: "reply", Context: "workspace-reviews"},
+		{Key: "x", Command: "resolve", Context: "workspace-reviews"},
+		{Key: "a", Command: "send-to-agent", Context: "workspace-reviews"},
+		{Key: "o", Command: "open-in-browser", Context: "workspace-reviews"},
+		{Key: "R", Command: "refresh", Context: "workspace-reviews"},
+
 		// Workspace fetch PR context
 		{Key: "esc", Command: "cancel", Context: "workspace-fetch-pr"},
 		{Key: "enter", Command: "fetch", Context: "workspace-fetch-pr"},
@@ -798,6 +908,7 @@ func DefaultBindings() []Binding {
 		{Key: "s", Command: "merge-stop-watching", Context: "workspace-merge"},
 		{Key: "o", Command: "open-pr", Context: "workspace-merge"},
 		{Key: "y", Command: "copy-pr", Context: "workspace-merge"},
+		{Key: "L", Command: "ci-log", Context: "workspace-merge"},
 
 		// Workspace preview context
 		{Key: "h", Command: "focus-left", Context: "workspace-preview"},
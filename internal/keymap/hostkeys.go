@@ -26,7 +26,7 @@ var HostReservedKeys = map[string]bool{
 // GlobalKeys are the keys sidecar's own root key handler acts on while an
 // ordinary plugin tab is focused: tab cycling and direct tab selection, the
 // palette, diagnostics, the project/worktree/theme switchers, Open In, the
-// issue input, and the quit flow.
+// bookmarks picker, the issue input, and the quit flow.
 //
 // It exists so a plugin can tell "this key collides with sidecar" from "this
 // key is mine alone" without duplicating the host's switch statement, and so
@@ -46,5 +46,5 @@ var GlobalKeys = map[string]bool{
 	"1": true, "2": true, "3": true, "4": true, "5": true, "6": true,
 	"7": true, "8": true, "9": true, "0": true,
 	"?": true, "!": true, "@": true, "K": true, "W": true, "#": true,
-	"^": true, "i": true, "q": true, "ctrl+c": true,
+	"^": true, "'": true, "i": true, "q": true, "ctrl+c": true,
 }
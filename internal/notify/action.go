@@ -0,0 +1,164 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Quick actions
+//
+// A call to action jumps somewhere; a quick action *answers*. The waiting
+// notification is the reason they exist: an agent blocked on "Allow edit?"
+// wants a y, and making the user switch to its shell to type one is the whole
+// cost the notification was supposed to save.
+//
+// Actions are typed, never free-form key strings. A poster names the kind, and
+// what the kind types is decided here, so a notification cannot be made to
+// type an arbitrary command into a shell, and every surface — the toast, the
+// centre, `sidecar notify act` — sends exactly the same keystrokes. Where they
+// go is the notification's Origin: the tmux session it was posted from or
+// about, which is the identity the lane tracker and the CLI already record.
+
+// ActionKind names a quick action.
+type ActionKind string
+
+const (
+	// ActionApprove answers a pending prompt with yes.
+	ActionApprove ActionKind = "approve"
+	// ActionDeny answers a pending prompt with no.
+	ActionDeny ActionKind = "deny"
+	// ActionMessage types a line of the user's text and submits it.
+	ActionMessage ActionKind = "message"
+	// ActionOpenDiff opens the working-tree diff of the origin's checkout. It
+	// types nothing; it is here because "what did it change" is the question
+	// that decides between the other three.
+	ActionOpenDiff ActionKind = "open-diff"
+)
+
+// Action is one quick action carried by a notification.
+type Action struct {
+	Kind ActionKind `json:"kind"`
+}
+
+// ActionKinds lists the kinds in the order they are offered.
+func ActionKinds() []ActionKind {
+	return []ActionKind{ActionApprove, ActionDeny, ActionMessage, ActionOpenDiff}
+}
+
+// ActionKindNames is ActionKinds as strings, for help text and error messages.
+func ActionKindNames() []string {
+	kinds := ActionKinds()
+	out := make([]string, 0, len(kinds))
+	for _, k := range kinds {
+		out = append(out, string(k))
+	}
+	return out
+}
+
+// ParseActionKind reads a kind as a poster or the CLI spells it.
+func ParseActionKind(s string) (ActionKind, error) {
+	kind := ActionKind(strings.ToLower(strings.TrimSpace(s)))
+	for _, k := range ActionKinds() {
+		if k == kind {
+			return k, nil
+		}
+	}
+	return "", fmt.Errorf("unknown action %q (one of: %s)", s, strings.Join(ActionKindNames(), ", "))
+}
+
+// Label is how the action reads on a button or in a key hint.
+func (k ActionKind) Label() string {
+	switch k {
+	case ActionApprove:
+		return "approve"
+	case ActionDeny:
+		return "deny"
+	case ActionMessage:
+		return "message…"
+	case ActionOpenDiff:
+		return "diff"
+	}
+	return string(k)
+}
+
+// Types reports whether the action sends keystrokes, and therefore needs a
+// tmux session to send them to.
+func (k ActionKind) Types() bool {
+	return k == ActionApprove || k == ActionDeny || k == ActionMessage
+}
+
+// WaitingActions are the actions a waiting agent's notification carries.
+func WaitingActions() []Action {
+	return []Action{{Kind: ActionApprove}, {Kind: ActionDeny}, {Kind: ActionMessage}, {Kind: ActionOpenDiff}}
+}
+
+// ActionsOf returns the actions n can actually perform, in offer order: an
+// unknown kind (from a newer build) is dropped, a repeat is dropped, an action
+// that types needs a tmux session in the origin, and open-diff needs a working
+// directory. Nothing is offered once n is dismissed — a dismissed waiting
+// notification is one whose agent has moved on, and a y typed into a shell
+// that is no longer asking is a y typed into whatever it is doing now.
+func ActionsOf(n Notification) []Action {
+	if n.Dismissed() || len(n.Actions) == 0 {
+		return nil
+	}
+	want := map[ActionKind]bool{}
+	for _, a := range n.Actions {
+		want[a.Kind] = true
+	}
+	var out []Action
+	for _, k := range ActionKinds() {
+		if !want[k] {
+			continue
+		}
+		if k.Types() && strings.TrimSpace(n.Origin.TmuxSession) == "" {
+			continue
+		}
+		if k == ActionOpenDiff && strings.TrimSpace(n.Origin.WorkDir) == "" {
+			continue
+		}
+		out = append(out, Action{Kind: k})
+	}
+	return out
+}
+
+// HasAction reports whether kind is among ActionsOf(n).
+func HasAction(n Notification, kind ActionKind) bool {
+	for _, a := range ActionsOf(n) {
+		if a.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrNoMessage is Keystrokes' answer to a message action with nothing to say.
+var ErrNoMessage = errors.New("a message action needs text")
+
+// Keystroke is one tmux send-keys argument: a key name ("Enter", "y"), or with
+// Literal set, text typed exactly as written.
+type Keystroke struct {
+	Keys    string
+	Literal bool
+}
+
+// Keystrokes is what kind types into the origin's session. Approve and deny
+// are the y/n the workspace plugin's own approve and reject keys send; a
+// message is the text followed by Enter, with line breaks flattened so one
+// action cannot submit two lines.
+func Keystrokes(kind ActionKind, message string) ([]Keystroke, error) {
+	switch kind {
+	case ActionApprove:
+		return []Keystroke{{Keys: "y"}, {Keys: "Enter"}}, nil
+	case ActionDeny:
+		return []Keystroke{{Keys: "n"}, {Keys: "Enter"}}, nil
+	case ActionMessage:
+		text := strings.Join(strings.Fields(message), " ")
+		if text == "" {
+			return nil, ErrNoMessage
+		}
+		return []Keystroke{{Keys: text, Literal: true}, {Keys: "Enter"}}, nil
+	}
+	return nil, fmt.Errorf("action %q types nothing", kind)
+}
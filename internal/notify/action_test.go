@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestActionsOfOffersOnlyWhatCanRun(t *testing.T) {
+	n := Notification{Actions: WaitingActions(), Origin: Origin{TmuxSession: "sidecar-ws-auth", WorkDir: "/repo/auth"}}
+	if got := ActionsOf(n); len(got) != 4 {
+		t.Fatalf("actions = %v", got)
+	}
+
+	noShell := n
+	noShell.Origin = Origin{WorkDir: "/repo/auth"}
+	if got := ActionsOf(noShell); len(got) != 1 || got[0].Kind != ActionOpenDiff {
+		t.Fatalf("an origin with no session offered %v", got)
+	}
+
+	odd := Notification{
+		Actions: []Action{{Kind: "reboot"}, {Kind: ActionDeny}, {Kind: ActionApprove}, {Kind: ActionDeny}},
+		Origin:  Origin{TmuxSession: "s"},
+	}
+	got := ActionsOf(odd)
+	if len(got) != 2 || got[0].Kind != ActionApprove || got[1].Kind != ActionDeny {
+		t.Fatalf("unknown, repeated or out-of-order actions survived: %v", got)
+	}
+
+	at := time.Now()
+	n.DismissedAt = &at
+	if HasAction(n, ActionApprove) {
+		t.Fatal("a dismissed notification still offered an action")
+	}
+}
+
+func TestKeystrokes(t *testing.T) {
+	approve, _ := Keystrokes(ActionApprove, "")
+	if len(approve) != 2 || approve[0].Keys != "y" || approve[1].Keys != "Enter" {
+		t.Fatalf("approve = %v", approve)
+	}
+	msg, err := Keystrokes(ActionMessage, "  try\nagain  ")
+	if err != nil || len(msg) != 2 || msg[0] != (Keystroke{Keys: "try again", Literal: true}) {
+		t.Fatalf("message = %v, %v", msg, err)
+	}
+	if _, err := Keystrokes(ActionMessage, " \n "); !errors.Is(err, ErrNoMessage) {
+		t.Fatalf("an empty message = %v", err)
+	}
+	if _, err := Keystrokes(ActionOpenDiff, ""); err == nil {
+		t.Fatal("open-diff typed something")
+	}
+	if _, err := ParseActionKind("Approve"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseActionKind("yes"); err == nil {
+		t.Fatal("an unknown kind parsed")
+	}
+}
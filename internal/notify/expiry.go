@@ -1,6 +1,8 @@
 package notify
 
 import (
+	"log/slog"
+	"sort"
 	"sync"
 	"time"
 
@@ -56,8 +58,33 @@ func ExpiryFor(id SourceID) time.Duration {
 
 // ApplyConfig binds the `notifications` config section to this package. It is
 // the seam between configuration and the model: nothing else in internal/notify
-// reads config, and nothing outside it has to know how an expiry is stored.
+// reads config, and nothing outside it has to know how an expiry is stored, a
+// source registered, or a rule compiled.
 func ApplyConfig(cfg config.NotificationsConfig) {
+	var list []Source
+	routes := map[SourceID]Route{}
+	for id, src := range cfg.Sources {
+		if Builtin(SourceID(id)) {
+			switch {
+			case src.Toast != nil && !*src.Toast:
+				routes[SourceID(id)] = RouteCentre
+			case src.Bell:
+				routes[SourceID(id)] = RouteBell
+			}
+			continue
+		}
+		if s, ok := customFromConfig(id, src); ok {
+			list = append(list, s)
+		}
+	}
+	// Map order is random; the registry's order must not be.
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	for _, err := range SetCustomSources(list) {
+		slog.Warn("notifications: ignoring source", "error", err)
+	}
+	setPolicy(routes)
+	applyRouting(cfg)
+
 	expiries := cfg.SourceExpiries()
 	if len(expiries) == 0 {
 		SetSourceExpiries(nil)
@@ -69,3 +96,22 @@ func ApplyConfig(cfg config.NotificationsConfig) {
 	}
 	SetSourceExpiries(out)
 }
+
+// customFromConfig is the source a config entry registers. Only an entry with
+// a label registers one; an unlabelled entry for an unknown id is an override
+// for a source this build does not have, kept for the build that does.
+func customFromConfig(id string, src config.NotificationSourceConfig) (Source, bool) {
+	if src.Label == "" {
+		return Source{}, false
+	}
+	return Source{
+		ID:            SourceID(id),
+		Label:         src.Label,
+		Glyph:         src.Glyph,
+		Hue:           Hue(src.Hue),
+		Priority:      src.Priority,
+		DefaultExpiry: customDefaultExpiry,
+		Silent:        src.Toast != nil && !*src.Toast,
+		Bell:          src.Bell,
+	}, true
+}
@@ -27,6 +27,13 @@ type PostedMsg struct {
 	Notification Notification
 }
 
+// LaneTransitionMsg reports one settled agent lane change. The workspace
+// plugin sends it beside whatever the transition posted, for listeners that
+// react to lanes rather than to notifications.
+type LaneTransitionMsg struct {
+	Transition LaneTransition
+}
+
 // Alert builds a PostMsg for a source-specific notification. It exists so a
 // producer that needs more than the generic `system` toast — a blocked action,
 // a merge that unwound itself, an agent that fell back — can say which source
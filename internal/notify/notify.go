@@ -18,8 +18,8 @@ import (
 // SourceID names a registered notification source.
 type SourceID string
 
-// The hardcoded source set. External registration is a later phase; the ids
-// are stable because they are written into the JSONL store.
+// The built-in source set. More are registered from config (registry.go); the
+// ids are stable because they are written into the JSONL store.
 const (
 	SourceAgent   SourceID = "agent"
 	SourceWaiting SourceID = "waiting"
@@ -27,6 +27,7 @@ const (
 	SourceTasks   SourceID = "tasks"
 	SourceTD      SourceID = "td"
 	SourceSystem  SourceID = "system"
+	SourceCI      SourceID = "ci"
 )
 
 // Severity ranks a notification within its source.
@@ -84,14 +85,18 @@ type Source struct {
 	// It is the built-in value: ExpiryFor applies the user's configuration on
 	// top of it, and everything that completes a notification asks ExpiryFor.
 	DefaultExpiry time.Duration
+	// Silent sources file into the centre without toasting; Bell sources ring
+	// the terminal bell as they toast. Both are user settings, never built in.
+	Silent bool
+	Bell   bool
 }
 
-// sources is the registry. It is a fixed table rather than a mutable map
-// because Phase 1 has no external registrations; the shape is what makes
-// adding one later a data change instead of a code change.
+// sources is the built-in table. Registered sources live beside it in
+// registry.go; everything that lists sources reads both through registered.
 var sources = []Source{
 	{ID: SourceWaiting, Label: "WAITING", Glyph: "?", Hue: HueWarning, Priority: 60, DefaultExpiry: 0},
 	{ID: SourceAgent, Label: "AGENTS", Glyph: "◆", Hue: HuePrimary, Priority: 50, DefaultExpiry: 12 * time.Second},
+	{ID: SourceCI, Label: "CI", Glyph: "⚙", Hue: HueAccent, Priority: 45, DefaultExpiry: 12 * time.Second},
 	{ID: SourceSession, Label: "SESSIONS", Glyph: "✓", Hue: HueSuccess, Priority: 40, DefaultExpiry: 10 * time.Second},
 	{ID: SourceTD, Label: "TD", Glyph: "■", Hue: HueSecondary, Priority: 30, DefaultExpiry: 10 * time.Second},
 	{ID: SourceTasks, Label: "TASKS", Glyph: "○", Hue: HueInfo, Priority: 20, DefaultExpiry: 10 * time.Second},
@@ -100,14 +105,12 @@ var sources = []Source{
 
 // Sources returns the registered sources, loudest first.
 func Sources() []Source {
-	out := make([]Source, len(sources))
-	copy(out, sources)
-	return out
+	return registered()
 }
 
 // Lookup returns the registered source for id.
 func Lookup(id SourceID) (Source, bool) {
-	for _, s := range sources {
+	for _, s := range registered() {
 		if s.ID == id {
 			return s, true
 		}
@@ -137,8 +140,9 @@ func ValidSource(id SourceID) bool {
 
 // SourceIDs lists the registered ids in registry order, for help text.
 func SourceIDs() []string {
-	out := make([]string, 0, len(sources))
-	for _, s := range sources {
+	all := registered()
+	out := make([]string, 0, len(all))
+	for _, s := range all {
 		out = append(out, string(s.ID))
 	}
 	return out
@@ -212,8 +216,14 @@ type Notification struct {
 	// notification from the centre — suppressed is not dropped.
 	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
 	Origin    Origin     `json:"origin,omitempty"`
+	// Provider is the agent kind ("claude", "codex") behind a notification an
+	// agent's lane raised, for routing rules. Empty for everything else.
+	Provider string `json:"provider,omitempty"`
 	// Sticky means the toast has no countdown and waits for the user.
 	Sticky bool `json:"sticky,omitempty"`
+	// Actions are the quick actions that answer this notification in place
+	// (action.go). ActionsOf decides which of them can run.
+	Actions []Action `json:"actions,omitempty"`
 }
 
 // Read reports whether the notification has been seen.
@@ -235,7 +245,8 @@ func NewID() string {
 
 // Normalize fills in the defaults a poster may leave out: id, creation time,
 // severity, and the source's default expiry. It is called by the store, so
-// every surface gets the same completion whatever path posted the record.
+// every surface gets the same completion whatever path posted the record. A
+// notification a rule suppresses is filed already read (see RouteSuppress).
 func Normalize(n Notification, now time.Time) Notification {
 	now = now.UTC()
 	if n.ID == "" {
@@ -273,5 +284,9 @@ func Normalize(n Notification, now time.Time) Notification {
 		t := n.DismissedAt.UTC()
 		n.DismissedAt = &t
 	}
+	if n.ReadAt == nil && RouteOf(n) == RouteSuppress {
+		t := n.CreatedAt
+		n.ReadAt = &t
+	}
 	return n
 }
@@ -0,0 +1,141 @@
+package notify
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Sources a user or a tool registers sit beside the built-in table rather than
+// in it. The built-in ids are written into every store and every agent's
+// instructions, so no registration may take one over; a custom source only
+// adds a section, and removing it again files its notifications under system
+// (SourceOf) rather than dropping them.
+
+// CustomPriority is the priority a registered source takes when it names none:
+// above system, below everything a user already watches for.
+const CustomPriority = 15
+
+// customDefaultExpiry is how long a custom source's toasts stay on screen when
+// its entry names no expiry, the same as most built-in sources.
+const customDefaultExpiry = 10 * time.Second
+
+var (
+	registryMu sync.RWMutex
+	custom     []Source
+	// policy holds the route configured for built-in sources, centre or
+	// bell; a custom source carries its own in Source.Silent and Source.Bell.
+	policy map[SourceID]Route
+)
+
+var sourceIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// Builtin reports whether id is one of the sources every build knows.
+func Builtin(id SourceID) bool {
+	for _, s := range sources {
+		if s.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseHue reads a palette name as written in config or on the command line.
+func ParseHue(name string) (Hue, bool) {
+	h := Hue(strings.ToLower(strings.TrimSpace(name)))
+	switch h {
+	case HuePrimary, HueSecondary, HueAccent, HueSuccess, HueWarning, HueError, HueInfo, HueMuted:
+		return h, true
+	}
+	return "", false
+}
+
+// Hues lists the palette names, for help text.
+func Hues() []string {
+	return []string{"primary", "secondary", "accent", "success", "warning", "error", "info", "muted"}
+}
+
+// CheckCustom validates a source to be registered and fills in what it may
+// leave out: an upper-case label, the system glyph, a muted hue, and
+// CustomPriority. The id is lower-case letters, digits, - and _, since it is
+// typed on command lines and written into the store.
+func CheckCustom(s Source) (Source, error) {
+	if !sourceIDPattern.MatchString(string(s.ID)) {
+		return Source{}, fmt.Errorf("invalid source id %q (lower-case letters, digits, - and _, at most 32)", s.ID)
+	}
+	if Builtin(s.ID) {
+		return Source{}, fmt.Errorf("%q is a built-in source", s.ID)
+	}
+	s.Label = strings.ToUpper(strings.TrimSpace(s.Label))
+	if s.Label == "" {
+		s.Label = strings.ToUpper(string(s.ID))
+	}
+	s.Glyph = strings.TrimSpace(s.Glyph)
+	if s.Glyph == "" {
+		s.Glyph = "●"
+	}
+	if ansi.StringWidth(s.Glyph) != 1 {
+		return Source{}, fmt.Errorf("glyph %q must be one cell wide", s.Glyph)
+	}
+	if s.Hue == "" {
+		s.Hue = HueMuted
+	} else if h, ok := ParseHue(string(s.Hue)); ok {
+		s.Hue = h
+	} else {
+		return Source{}, fmt.Errorf("unknown hue %q (one of: %s)", s.Hue, strings.Join(Hues(), ", "))
+	}
+	if s.Priority == 0 {
+		s.Priority = CustomPriority
+	}
+	if s.DefaultExpiry < 0 {
+		return Source{}, fmt.Errorf("expiry must not be negative")
+	}
+	return s, nil
+}
+
+// SetCustomSources replaces the registered custom sources. Each is checked as
+// by CheckCustom; one that fails is left out with its error reported, so a bad
+// entry costs the user that source and not the others.
+func SetCustomSources(list []Source) []error {
+	var errs []error
+	next := make([]Source, 0, len(list))
+	for _, s := range list {
+		checked, err := CheckCustom(s)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		next = append(next, checked)
+	}
+	registryMu.Lock()
+	custom = next
+	registryMu.Unlock()
+	return errs
+}
+
+// setPolicy replaces the configured routes of the built-in sources.
+func setPolicy(routes map[SourceID]Route) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	policy = routes
+}
+
+// registered is the built-in table and the custom sources, loudest first.
+func registered() []Source {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	out := make([]Source, 0, len(sources)+len(custom))
+	for _, s := range sources {
+		s.Silent = policy[s.ID] == RouteCentre
+		s.Bell = policy[s.ID] == RouteBell
+		out = append(out, s)
+	}
+	out = append(out, custom...)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Priority > out[j].Priority })
+	return out
+}
@@ -0,0 +1,76 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcus/sidecar/internal/config"
+)
+
+func TestConfiguredSourcesJoinTheRegistry(t *testing.T) {
+	t.Cleanup(func() { ApplyConfig(config.NotificationsConfig{}) })
+	off := false
+	ApplyConfig(config.NotificationsConfig{Sources: map[string]config.NotificationSourceConfig{
+		"deploy": {Label: "Deploys", Glyph: "▲", Hue: "warning", Priority: 35, Expiry: "30s"},
+		"tests":  {Label: "tests", Toast: &off},
+		"td":     {Label: "Hijack", Toast: &off},
+		"bad":    {Label: "Bad", Hue: "plaid"},
+		"later":  {Expiry: "5s"},
+	}})
+
+	deploy, ok := Lookup("deploy")
+	if !ok || deploy.Label != "DEPLOYS" || deploy.Glyph != "▲" || deploy.Hue != HueWarning || deploy.Priority != 35 {
+		t.Fatalf("deploy = %+v, %v", deploy, ok)
+	}
+	if got := ExpiryFor("deploy"); got != 30*time.Second {
+		t.Fatalf("deploy expiry = %s", got)
+	}
+	tests, _ := Lookup("tests")
+	if tests.Priority != CustomPriority || tests.Glyph != "●" || !tests.Silent || ExpiryFor("tests") != customDefaultExpiry {
+		t.Fatalf("tests = %+v", tests)
+	}
+	// A built-in takes only the toast policy; a bad entry and an unlabelled
+	// one register nothing.
+	if td := SourceOf(SourceTD); td.Label != "TD" || !td.Silent {
+		t.Fatalf("td = %+v", td)
+	}
+	if ValidSource("bad") || ValidSource("later") {
+		t.Fatal("an invalid or unlabelled entry was registered")
+	}
+
+	// Loudest first, custom sources among the built-in ones.
+	var ids []string
+	for _, s := range Sources() {
+		ids = append(ids, string(s.ID))
+	}
+	if got := strings.Join(ids, ","); got != "waiting,agent,ci,session,deploy,td,tasks,tests,system" {
+		t.Fatalf("order = %s", got)
+	}
+
+	now := time.Now()
+	if MayToast(Normalize(Notification{Source: "tests", Title: "x"}, now), now) {
+		t.Fatal("a silent source toasted")
+	}
+	if !MayToast(Normalize(Notification{Source: "deploy", Title: "x"}, now), now) {
+		t.Fatal("a registered source did not toast")
+	}
+	groups := GroupBySource([]Notification{{Source: "deploy", Title: "a"}, {Source: SourceAgent, Title: "b"}})
+	if len(groups) != 2 || groups[0].Source.ID != SourceAgent || groups[1].Source.ID != "deploy" {
+		t.Fatalf("groups = %+v", groups)
+	}
+
+	// Removing a source files what it posted under system.
+	ApplyConfig(config.NotificationsConfig{})
+	if SourceOf("deploy").ID != SourceSystem || SourceOf(SourceTD).Silent {
+		t.Fatal("the registry kept a removed source")
+	}
+}
+
+func TestCheckCustomRefusesBuiltinsAndBadIDs(t *testing.T) {
+	for _, s := range []Source{{ID: "agent"}, {ID: "Has Space"}, {ID: "x", Glyph: "ab"}} {
+		if _, err := CheckCustom(s); err == nil {
+			t.Errorf("CheckCustom(%+v) accepted", s)
+		}
+	}
+}
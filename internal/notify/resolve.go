@@ -99,18 +99,27 @@ func louder(a, b Notification) bool {
 }
 
 // MayToast reports whether a notification should currently be on screen as a
-// toast: never seen, never dismissed, and not past its expiry. A sticky
-// notification has no expiry and toasts until it is read or dismissed.
+// toast: never seen, never dismissed, routed to toast, not past its expiry,
+// and not held back by quiet hours or do-not-disturb. A sticky notification
+// has no expiry and toasts until it is read or dismissed; so does one that has
+// escalated, whatever its route.
 func MayToast(n Notification, now time.Time) bool {
-	if n.Dismissed() || n.Read() {
+	if n.Dismissed() || n.Read() || Quiet(now) {
+		return false
+	}
+	if Escalated(n, now) {
+		return true
+	}
+	if r := RouteOf(n); r != RouteToast && r != RouteBell {
 		return false
 	}
 	return !ToastExpired(n, now)
 }
 
-// ToastExpired reports whether a toast's countdown has run out.
+// ToastExpired reports whether a toast's countdown has run out. An escalated
+// notification has none.
 func ToastExpired(n Notification, now time.Time) bool {
-	if n.Sticky || n.ExpiresAt == nil {
+	if n.Sticky || n.ExpiresAt == nil || Escalated(n, now) {
 		return false
 	}
 	return !now.UTC().Before(n.ExpiresAt.UTC())
@@ -154,7 +163,7 @@ func GroupBySource(all []Notification) []Group {
 		byID[id] = append(byID[id], n)
 	}
 	out := make([]Group, 0, len(byID))
-	for _, s := range sources {
+	for _, s := range registered() {
 		items := byID[s.ID]
 		if len(items) == 0 {
 			continue
@@ -0,0 +1,397 @@
+package notify
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/marcus/sidecar/internal/config"
+)
+
+// Routing decides what a notification does when it arrives. A source has a
+// route of its own (toast, or what the user configured for it); rules override
+// it per notification, by what it says rather than where it came from; and
+// quiet hours and do-not-disturb hold back everything that would interrupt
+// without deciding anything else. None of it removes a notification from the
+// centre — suppressed is not dropped.
+//
+// Like the expiries, the rules are package state bound by ApplyConfig, so the
+// store, the CLI and the app all route a notification the same way.
+
+// Route is what a notification does on arrival.
+type Route string
+
+const (
+	// RouteToast puts it on screen. It is every source's default.
+	RouteToast Route = "toast"
+	// RouteBell toasts and rings the terminal bell.
+	RouteBell Route = "bell"
+	// RouteCentre files it unread into the centre without toasting: it counts
+	// in the corner and waits there.
+	RouteCentre Route = "centre"
+	// RouteSuppress files it already read. It is in the centre's history but
+	// neither toasts nor counts.
+	RouteSuppress Route = "suppress"
+)
+
+// Routes lists the routes, for help text and selectors.
+func Routes() []Route { return []Route{RouteToast, RouteBell, RouteCentre, RouteSuppress} }
+
+// ParseRoute reads a route as written in config.
+func ParseRoute(s string) (Route, bool) {
+	r := Route(strings.ToLower(strings.TrimSpace(s)))
+	switch r {
+	case RouteToast, RouteBell, RouteCentre, RouteSuppress:
+		return r, true
+	case "center":
+		return RouteCentre, true
+	}
+	return "", false
+}
+
+// Route is the source's own route: centre when it is configured not to toast,
+// bell when it is configured to ring, toast otherwise.
+func (s Source) Route() Route {
+	switch {
+	case s.Silent:
+		return RouteCentre
+	case s.Bell:
+		return RouteBell
+	}
+	return RouteToast
+}
+
+// Rule routes the notifications it matches. Every condition it sets must hold;
+// a rule with none matches everything, which is how a catch-all is written.
+type Rule struct {
+	Sources    []SourceID
+	Severities []Severity
+	// Project is a glob matched against the poster's working directory and
+	// project key, and against the last element of each, so "sidecar" matches
+	// a checkout wherever it lives.
+	Project string
+	// Provider is the agent kind, compared without case.
+	Provider string
+	// Match is tried against the title and body together.
+	Match *regexp.Regexp
+	// Route is empty for a rule that only escalates: the notification keeps
+	// its source's route.
+	Route Route
+	// EscalateAfter, when set, toasts a matched notification that is still
+	// unread this long after it was posted, whatever its route.
+	EscalateAfter time.Duration
+}
+
+// Matches reports whether the rule applies to n.
+func (r Rule) Matches(n Notification) bool {
+	if len(r.Sources) > 0 && !containsSource(r.Sources, n.Source) {
+		return false
+	}
+	if len(r.Severities) > 0 && !containsSeverity(r.Severities, n.Severity) {
+		return false
+	}
+	if r.Provider != "" && !strings.EqualFold(r.Provider, n.Provider) {
+		return false
+	}
+	if r.Project != "" && !projectMatches(r.Project, n.Origin) {
+		return false
+	}
+	if r.Match != nil && !r.Match.MatchString(n.Title+"\n"+n.Body) {
+		return false
+	}
+	return true
+}
+
+func containsSource(ids []SourceID, id SourceID) bool {
+	for _, s := range ids {
+		if s == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsSeverity(list []Severity, sev Severity) bool {
+	if sev == "" {
+		sev = SeverityInfo
+	}
+	for _, s := range list {
+		if s == sev {
+			return true
+		}
+	}
+	return false
+}
+
+func projectMatches(pattern string, o Origin) bool {
+	for _, p := range []string{o.WorkDir, o.ProjectKey} {
+		if p == "" {
+			continue
+		}
+		if ok, _ := filepath.Match(pattern, p); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, filepath.Base(p)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseRule compiles a configured rule. A rule that names an unknown route or
+// severity, or an unreadable pattern or duration, is an error rather than a
+// rule that quietly matches something else.
+func ParseRule(c config.NotificationRuleConfig) (Rule, error) {
+	r, err := parseRule(c)
+	if err != nil {
+		return Rule{}, err
+	}
+	if r.Route == "" && r.EscalateAfter == 0 {
+		return Rule{}, fmt.Errorf("a rule needs a route or an escalateAfter")
+	}
+	return r, nil
+}
+
+// ParseFilter compiles rule conditions that only select, such as a delivery
+// sink's filter: a route or an escalation there would be a promise nothing
+// keeps, so either is an error.
+func ParseFilter(c config.NotificationRuleConfig) (Rule, error) {
+	if c.Route != "" || c.EscalateAfter != "" {
+		return Rule{}, fmt.Errorf("a filter selects notifications; it takes no route or escalateAfter")
+	}
+	return parseRule(c)
+}
+
+func parseRule(c config.NotificationRuleConfig) (Rule, error) {
+	r := Rule{Project: strings.TrimSpace(c.Project), Provider: strings.TrimSpace(c.Provider)}
+	for _, id := range c.Source {
+		r.Sources = append(r.Sources, SourceID(strings.TrimSpace(id)))
+	}
+	for _, s := range c.Severity {
+		sev := Severity(strings.ToLower(strings.TrimSpace(s)))
+		if sev.Rank() == 0 {
+			return Rule{}, fmt.Errorf("unknown severity %q (info, warning or error)", s)
+		}
+		r.Severities = append(r.Severities, sev)
+	}
+	if r.Project != "" {
+		if _, err := filepath.Match(r.Project, ""); err != nil {
+			return Rule{}, fmt.Errorf("invalid project pattern %q: %w", r.Project, err)
+		}
+	}
+	if c.Match != "" {
+		re, err := regexp.Compile(c.Match)
+		if err != nil {
+			return Rule{}, fmt.Errorf("invalid match %q: %w", c.Match, err)
+		}
+		r.Match = re
+	}
+	if c.Route != "" {
+		route, ok := ParseRoute(c.Route)
+		if !ok {
+			return Rule{}, fmt.Errorf("unknown route %q (toast, bell, centre or suppress)", c.Route)
+		}
+		r.Route = route
+	}
+	if c.EscalateAfter != "" {
+		d, err := time.ParseDuration(c.EscalateAfter)
+		if err != nil || d <= 0 {
+			return Rule{}, fmt.Errorf("invalid escalateAfter %q (a duration such as 10m)", c.EscalateAfter)
+		}
+		r.EscalateAfter = d
+	}
+	return r, nil
+}
+
+// QuietWindow is one stretch of quiet hours, in local wall-clock time.
+type QuietWindow struct {
+	// Days is indexed by time.Weekday: the days the window starts on. All
+	// false means every day.
+	Days [7]bool
+	// From and To are minutes past midnight. From after To wraps past
+	// midnight; From equal to To is the whole day.
+	From, To int
+}
+
+// Contains reports whether t falls inside the window.
+func (w QuietWindow) Contains(t time.Time) bool {
+	t = t.Local()
+	m := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+	switch {
+	case w.From == w.To:
+		return w.on(day)
+	case w.From < w.To:
+		return w.on(day) && m >= w.From && m < w.To
+	case m >= w.From:
+		return w.on(day)
+	case m < w.To:
+		// The early-morning half belongs to the night before.
+		return w.on((day + 6) % 7)
+	}
+	return false
+}
+
+func (w QuietWindow) on(day time.Weekday) bool {
+	if w.Days == [7]bool{} {
+		return true
+	}
+	return w.Days[day]
+}
+
+// ParseQuietWindow reads a configured window: 24-hour "HH:MM" times and day
+// names, of which the first three letters are enough.
+func ParseQuietWindow(c config.QuietHoursConfig) (QuietWindow, error) {
+	var w QuietWindow
+	for _, d := range c.Days {
+		day, ok := parseWeekday(d)
+		if !ok {
+			return QuietWindow{}, fmt.Errorf("unknown day %q", d)
+		}
+		w.Days[day] = true
+	}
+	var err error
+	if w.From, err = parseClock(c.From); err != nil {
+		return QuietWindow{}, err
+	}
+	if w.To, err = parseClock(c.To); err != nil {
+		return QuietWindow{}, err
+	}
+	return w, nil
+}
+
+func parseWeekday(s string) (time.Weekday, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if len(s) < 3 {
+		return 0, false
+	}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if strings.HasPrefix(name, s) {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q (24-hour HH:MM)", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+var (
+	routingMu    sync.RWMutex
+	rules        []Rule
+	quietHours   []QuietWindow
+	doNotDisturb bool
+)
+
+// SetRouting replaces the rules, the quiet hours and the do-not-disturb
+// switch.
+func SetRouting(r []Rule, quiet []QuietWindow, dnd bool) {
+	routingMu.Lock()
+	defer routingMu.Unlock()
+	rules = r
+	quietHours = quiet
+	doNotDisturb = dnd
+}
+
+// applyRouting compiles the routing half of the notifications section. A bad
+// rule or window is skipped with a warning, the same bargain as a bad expiry:
+// one typo must not cost the user the rest of their rules.
+func applyRouting(cfg config.NotificationsConfig) {
+	var compiled []Rule
+	for i, c := range cfg.Rules {
+		r, err := ParseRule(c)
+		if err != nil {
+			slog.Warn("notifications: ignoring rule", "rule", i+1, "error", err)
+			continue
+		}
+		compiled = append(compiled, r)
+	}
+	var windows []QuietWindow
+	for i, c := range cfg.QuietHours {
+		w, err := ParseQuietWindow(c)
+		if err != nil {
+			slog.Warn("notifications: ignoring quiet hours", "window", i+1, "error", err)
+			continue
+		}
+		windows = append(windows, w)
+	}
+	SetRouting(compiled, windows, cfg.DoNotDisturb)
+}
+
+// DoNotDisturb reports whether the do-not-disturb switch is on.
+func DoNotDisturb() bool {
+	routingMu.RLock()
+	defer routingMu.RUnlock()
+	return doNotDisturb
+}
+
+// Quiet reports whether nothing may interrupt right now: do-not-disturb is on,
+// or now falls in quiet hours. A quiet notification still lands in the centre
+// and counts in the corner; it toasts afterwards if it is sticky or escalates.
+func Quiet(now time.Time) bool {
+	routingMu.RLock()
+	defer routingMu.RUnlock()
+	if doNotDisturb {
+		return true
+	}
+	for _, w := range quietHours {
+		if w.Contains(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleFor is the first rule matching n.
+func ruleFor(n Notification) (Rule, bool) {
+	routingMu.RLock()
+	defer routingMu.RUnlock()
+	for _, r := range rules {
+		if r.Matches(n) {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+// RouteOf is n's route: the first matching rule's, or its source's.
+func RouteOf(n Notification) Route {
+	if r, ok := ruleFor(n); ok && r.Route != "" {
+		return r.Route
+	}
+	return n.SourceInfo().Route()
+}
+
+// EscalatesAt is when n toasts if it is still unread, if a rule says it does.
+func EscalatesAt(n Notification) (time.Time, bool) {
+	r, ok := ruleFor(n)
+	if !ok || r.EscalateAfter <= 0 {
+		return time.Time{}, false
+	}
+	return n.CreatedAt.Add(r.EscalateAfter), true
+}
+
+// Escalated reports whether n has gone unread past its escalation time. An
+// escalated notification toasts with no countdown until it is answered.
+func Escalated(n Notification, now time.Time) bool {
+	if n.Read() || n.Dismissed() {
+		return false
+	}
+	at, ok := EscalatesAt(n)
+	return ok && !now.Before(at)
+}
+
+// RingsBell reports whether n's arrival should ring the terminal bell now.
+func RingsBell(n Notification, now time.Time) bool {
+	return RouteOf(n) == RouteBell && !n.Read() && !Quiet(now)
+}
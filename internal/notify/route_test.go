@@ -0,0 +1,134 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marcus/sidecar/internal/config"
+)
+
+func TestRulesRouteFirstMatchOverTheSource(t *testing.T) {
+	t.Cleanup(func() { ApplyConfig(config.NotificationsConfig{}) })
+	off := false
+	ApplyConfig(config.NotificationsConfig{
+		Sources: map[string]config.NotificationSourceConfig{"tasks": {Toast: &off}, "ci": {Bell: true}},
+		Rules: []config.NotificationRuleConfig{
+			{Source: []string{"tasks"}, Severity: []string{"error"}, Route: "toast"},
+			{Match: "(?i)flaky", Route: "suppress"},
+			{Project: "scratch", Route: "centre"},
+			{Provider: "Codex", Route: "bell"},
+			{Route: "sideways"},
+		},
+	})
+
+	cases := []struct {
+		name string
+		n    Notification
+		want Route
+	}{
+		{"a rule overrides a silent source", Notification{Source: SourceTasks, Severity: SeverityError}, RouteToast},
+		{"the source decides when no rule matches", Notification{Source: SourceTasks}, RouteCentre},
+		{"a configured bell", Notification{Source: SourceCI, Title: "build passed"}, RouteBell},
+		{"text match beats the source", Notification{Source: SourceCI, Title: "Flaky test retried"}, RouteSuppress},
+		{"project by directory name", Notification{Source: SourceAgent, Origin: Origin{WorkDir: "/home/me/src/scratch"}}, RouteCentre},
+		{"provider", Notification{Source: SourceAgent, Provider: "codex"}, RouteBell},
+		{"default", Notification{Source: SourceAgent}, RouteToast},
+	}
+	for _, c := range cases {
+		if got := RouteOf(c.n); got != c.want {
+			t.Errorf("%s: route = %s, want %s", c.name, got, c.want)
+		}
+	}
+
+	now := time.Now()
+	flaky := Normalize(Notification{Source: SourceCI, Title: "flaky"}, now)
+	if !flaky.Read() || MayToast(flaky, now) {
+		t.Fatal("a suppressed notification was filed unread")
+	}
+	quiet := Normalize(Notification{Source: SourceTasks, Title: "x"}, now)
+	if quiet.Read() || MayToast(quiet, now) {
+		t.Fatal("a centre notification must file unread and not toast")
+	}
+	if !RingsBell(Normalize(Notification{Source: SourceCI, Title: "x"}, now), now) {
+		t.Fatal("a bell source did not ring")
+	}
+}
+
+func TestQuietHoursAndDoNotDisturbHoldBackToasts(t *testing.T) {
+	t.Cleanup(func() { ApplyConfig(config.NotificationsConfig{}) })
+	ApplyConfig(config.NotificationsConfig{QuietHours: []config.QuietHoursConfig{
+		{Days: []string{"fri"}, From: "22:00", To: "07:00"},
+		{Days: []string{"someday"}, From: "00:00", To: "01:00"},
+	}})
+
+	at := func(day, hour int) time.Time { return time.Date(2026, 10, day, hour, 30, 0, 0, time.Local) }
+	// 2026-10-16 is a Friday.
+	for _, c := range []struct {
+		t     time.Time
+		quiet bool
+	}{
+		{at(16, 21), false},
+		{at(16, 23), true},
+		{at(17, 3), true}, // Saturday morning belongs to Friday night
+		{at(17, 8), false},
+		{at(17, 23), false},
+		{at(15, 3), false},
+	} {
+		if got := Quiet(c.t); got != c.quiet {
+			t.Errorf("Quiet(%s) = %v, want %v", c.t.Format("Mon 15:04"), got, c.quiet)
+		}
+	}
+
+	n := Normalize(Notification{Source: SourceWaiting, Title: "x", CreatedAt: at(16, 23)}, at(16, 23))
+	if MayToast(n, at(16, 23)) {
+		t.Fatal("a notification toasted in quiet hours")
+	}
+	if !MayToast(n, at(17, 8)) {
+		t.Fatal("a sticky notification did not toast once quiet hours ended")
+	}
+	if RingsBell(Notification{Source: SourceCI}, at(16, 23)) {
+		t.Fatal("a bell rang in quiet hours")
+	}
+
+	ApplyConfig(config.NotificationsConfig{DoNotDisturb: true})
+	if !DoNotDisturb() || MayToast(n, at(17, 8)) {
+		t.Fatal("do-not-disturb let a toast through")
+	}
+}
+
+func TestEscalationToastsWhatIsStillUnread(t *testing.T) {
+	t.Cleanup(func() { ApplyConfig(config.NotificationsConfig{}) })
+	ApplyConfig(config.NotificationsConfig{Rules: []config.NotificationRuleConfig{
+		{Source: []string{"ci"}, Route: "centre", EscalateAfter: "10m"},
+	}})
+
+	now := time.Now()
+	n := Normalize(Notification{Source: SourceCI, Title: "deploy failed"}, now)
+	if MayToast(n, now) {
+		t.Fatal("a centre notification toasted on arrival")
+	}
+	later := now.Add(11 * time.Minute)
+	if !Escalated(n, later) || !MayToast(n, later) || ToastExpired(n, later.Add(time.Hour)) {
+		t.Fatal("an unread notification did not escalate to a sticky toast")
+	}
+	read := later
+	n.ReadAt = &read
+	if MayToast(n, later) {
+		t.Fatal("a read notification escalated")
+	}
+}
+
+func TestParseRuleRefusesWhatItCannotHonour(t *testing.T) {
+	for _, c := range []config.NotificationRuleConfig{
+		{Source: []string{"ci"}},
+		{Route: "loud"},
+		{Route: "toast", Severity: []string{"fatal"}},
+		{Route: "toast", Match: "("},
+		{Route: "toast", Project: "["},
+		{EscalateAfter: "soon"},
+	} {
+		if _, err := ParseRule(c); err == nil {
+			t.Errorf("ParseRule(%+v) accepted", c)
+		}
+	}
+}
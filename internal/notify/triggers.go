@@ -12,8 +12,8 @@ import (
 // about the *transitions* between them.
 //
 // It is deliberately state-free with respect to the rest of sidecar: it knows
-// nothing about tmux, plugins, or Bubble Tea. A caller — the workspace plugin
-// today, a headless watcher tomorrow — resolves whatever it already has into
+// nothing about tmux, plugins, or Bubble Tea. A caller — the workspace plugin,
+// or internal/agentwatch behind `sidecar watch` — resolves whatever it has into
 // []LaneObservation and hands the whole set to Observe on whatever cadence it
 // polls at. Everything else (which lanes are worth a notification, how long a
 // state must hold before it counts, what self-dismisses) lives here so both
@@ -62,9 +62,10 @@ type LaneObservation struct {
 	Origin Origin
 }
 
-// LaneEvents is what one Observe call produced: notifications to post, and the
+// LaneEvents is what one Observe call produced: notifications to post, the
 // ids of notifications the tracker itself posted earlier and now considers
-// answered.
+// answered, and every settled lane change whether or not it was worth a
+// notification.
 type LaneEvents struct {
 	// Post are complete notifications, ids already assigned. The ids are
 	// assigned here rather than by the store because the tracker has to be able
@@ -74,11 +75,32 @@ type LaneEvents struct {
 	// stopped waiting. A "needs input" toast that outlives the wait is worse
 	// than no toast at all, so the tracker withdraws its own.
 	Dismiss []string
+	// Transitions are the settled lane changes themselves, in observation
+	// order. They are for listeners with their own rules about what matters
+	// (user scripts); the tracker's notifications are decided above them.
+	Transitions []LaneTransition
 }
 
-// Empty reports whether nothing happened.
+// Empty reports whether there is nothing to post or dismiss. Transitions do
+// not count: most settled changes are deliberately silent.
 func (e LaneEvents) Empty() bool { return len(e.Post) == 0 && len(e.Dismiss) == 0 }
 
+// LaneTransition is one settled lane change of one workspace.
+type LaneTransition struct {
+	Key      string
+	Label    string
+	Context  string
+	Provider string
+	// Previous is the lane the workspace settled in before this one.
+	Previous agentstatus.LaneID
+	Lane     agentstatus.LaneID
+	// Health reports the presentation's health flag in the new lane: in the
+	// paused lane, that the session is missing or failed.
+	Health bool
+	Origin Origin
+	At     time.Time
+}
+
 type laneState struct {
 	lane        agentstatus.LaneID
 	health      bool
@@ -165,6 +187,18 @@ func (t *LaneTracker) Observe(obs []LaneObservation, now time.Time) LaneEvents {
 
 // commit turns one settled lane change into notifications.
 func (t *LaneTracker) commit(st *laneState, prior agentstatus.LaneID, o LaneObservation, now time.Time, events *LaneEvents) {
+	events.Transitions = append(events.Transitions, LaneTransition{
+		Key:      o.Key,
+		Label:    o.Label,
+		Context:  o.Context,
+		Provider: o.Provider,
+		Previous: prior,
+		Lane:     st.lane,
+		Health:   st.health,
+		Origin:   o.Origin,
+		At:       now,
+	})
+
 	// Leaving the blocked lane answers the wait, whatever it moved to.
 	if prior == agentstatus.LaneBlocked && st.waitingID != "" {
 		events.Dismiss = append(events.Dismiss, st.waitingID)
@@ -176,6 +210,7 @@ func (t *LaneTracker) commit(st *laneState, prior agentstatus.LaneID, o LaneObse
 		n := laneNotification(o, now, SourceWaiting, SeverityWarning,
 			fmt.Sprintf("%s needs input", laneName(o)), laneBody(o))
 		n.Sticky = true
+		n.Actions = WaitingActions()
 		st.waitingID = n.ID
 		events.Post = append(events.Post, n)
 
@@ -208,6 +243,7 @@ func laneNotification(o LaneObservation, now time.Time, source SourceID, sev Sev
 		Body:      body,
 		CreatedAt: now.UTC(),
 		Origin:    o.Origin,
+		Provider:  strings.TrimSpace(o.Provider),
 	}
 }
 
@@ -62,6 +62,9 @@ func TestWaitingPostsStickyAndSelfDismisses(t *testing.T) {
 	if waiting.Origin.TmuxSession != "a" {
 		t.Fatalf("origin not carried: %#v", waiting.Origin)
 	}
+	if !HasAction(waiting, ActionApprove) || !HasAction(waiting, ActionMessage) {
+		t.Fatalf("waiting notification carries no quick actions: %v", waiting.Actions)
+	}
 
 	// Answering the prompt withdraws the notification the tracker posted.
 	back := now.Add(10 * time.Second)
@@ -189,3 +192,20 @@ func TestLabelFallsBackToProvider(t *testing.T) {
 		t.Fatalf("laneName = %q", got)
 	}
 }
+
+func TestSettledChangesAreReportedEvenWhenSilent(t *testing.T) {
+	tr := &LaneTracker{Debounce: time.Second}
+	now := time.Unix(7000, 0)
+	tr.Observe([]LaneObservation{obs("a", agentstatus.LaneIdle, false)}, now)
+	ev := settle(t, tr, obs("a", agentstatus.LaneWorking, false), now.Add(time.Second))
+	if !ev.Empty() {
+		t.Fatalf("idle→working posted %#v", ev)
+	}
+	if len(ev.Transitions) != 1 {
+		t.Fatalf("transitions = %#v, want one", ev.Transitions)
+	}
+	got := ev.Transitions[0]
+	if got.Key != "a" || got.Previous != agentstatus.LaneIdle || got.Lane != agentstatus.LaneWorking || got.Label != "Shell 1" {
+		t.Fatalf("transition = %#v", got)
+	}
+}
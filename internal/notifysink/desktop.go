@@ -0,0 +1,75 @@
+package notifysink
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/marcus/sidecar/internal/notify"
+)
+
+// DefaultDesktopCommand is what a desktop sink runs when its entry names no
+// command: libnotify's client, which speaks the freedesktop notification
+// D-Bus interface on every Linux desktop that has one.
+const DefaultDesktopCommand = "notify-send"
+
+// desktopTimeout bounds one notify-send. It returns as soon as the bus has
+// the message, so anything slower is a hung session bus.
+const desktopTimeout = 5 * time.Second
+
+// Desktop posts a freedesktop notification by running notify-send, or a
+// command that takes the same arguments.
+type Desktop struct {
+	command string
+}
+
+// NewDesktop returns a desktop sink running command, or notify-send.
+func NewDesktop(command string) *Desktop {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		command = DefaultDesktopCommand
+	}
+	return &Desktop{command: command}
+}
+
+// desktopUrgency maps a severity onto the freedesktop urgency levels. Low is
+// never used: several notification daemons hide low-urgency notifications
+// entirely, and a delivery nobody sees is not one.
+func desktopUrgency(sev notify.Severity) string {
+	if sev == notify.SeverityError {
+		return "critical"
+	}
+	return "normal"
+}
+
+// Args is the notify-send command line for n, exported for tests and for a
+// wrapper script's author.
+func (d *Desktop) Args(n notify.Notification) []string {
+	src := n.SourceInfo()
+	args := []string{
+		"--app-name=Sidecar",
+		"--urgency=" + desktopUrgency(n.Severity),
+		"--category=sidecar." + string(src.ID),
+		clean(src.Glyph+" "+n.Title, 120),
+	}
+	if body := clean(n.Body, 400); body != "" {
+		args = append(args, body)
+	}
+	return args
+}
+
+// Deliver implements Sink.
+func (d *Desktop) Deliver(ctx context.Context, n notify.Notification) error {
+	ctx, cancel := context.WithTimeout(ctx, desktopTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, d.command, d.Args(n)...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%s: %w: %s", d.command, err, msg)
+		}
+		return fmt.Errorf("%s: %w", d.command, err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package notifysink
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/notify"
+)
+
+// OSC turns a notification into the escape sequence a terminal emulator shows
+// as a system notification. It reaches the desktop with no daemon and no
+// D-Bus, including over ssh, which is what it is for.
+type OSC struct {
+	code int
+	// tmux wraps the sequence for tmux's passthrough, without which tmux
+	// swallows it. The outer tmux needs `allow-passthrough on`.
+	tmux bool
+}
+
+// NewOSC returns an OSC sink speaking code: 9 (iTerm2, WezTerm, Windows
+// Terminal, kitty), 777 (urxvt, foot, Ghostty), or 0 for the default of 9.
+func NewOSC(code int) (*OSC, error) {
+	switch code {
+	case 0:
+		code = 9
+	case 9, 777:
+	default:
+		return nil, fmt.Errorf("unsupported osc %d (9 or 777)", code)
+	}
+	return &OSC{code: code, tmux: os.Getenv("TMUX") != ""}, nil
+}
+
+// Sequence is the escape sequence announcing n.
+func (o *OSC) Sequence(n notify.Notification) string {
+	title := clean(n.Title, 120)
+	body := clean(n.Body, 300)
+	var seq string
+	if o.code == 777 {
+		// Fields are ;-separated, so a ; inside one would start the next.
+		seq = ansi.URxvtExt("notify", strings.ReplaceAll(title, ";", ","), strings.ReplaceAll(body, ";", ","))
+	} else {
+		text := title
+		if body != "" {
+			text += ": " + body
+		}
+		seq = ansi.Notify(text)
+	}
+	if o.tmux {
+		return ansi.TmuxPassthrough(seq)
+	}
+	return seq
+}
@@ -0,0 +1,234 @@
+// Package notifysink delivers notifications beyond the Sidecar terminal: to
+// the desktop through notify-send, to the terminal emulator as an OSC 9/777
+// notification, and to webhooks. It only delivers — what a notification is,
+// how it routes, and whether now is quiet are internal/notify's answers, and a
+// sink asks them rather than keeping its own.
+//
+// Delivery is best effort and never in the way. A sink that fails is logged
+// and skipped; nothing about filing a notification waits on one.
+package notifysink
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/notify"
+)
+
+// Sink delivers one notification somewhere outside the process.
+type Sink interface {
+	Deliver(ctx context.Context, n notify.Notification) error
+}
+
+// defaultPerMinute is a sink's rate limit when its entry names none.
+const defaultPerMinute = 10
+
+// entry is one configured sink with its filter and limit. Exactly one of sink
+// and osc is set: an OSC notification is bytes for the terminal the app
+// writes, not a delivery this package can make on its own.
+type entry struct {
+	name        string
+	sink        Sink
+	osc         *OSC
+	filters     []notify.Rule
+	duringQuiet bool
+	limit       *limiter
+}
+
+// Dispatcher is the configured sinks.
+type Dispatcher struct {
+	entries []*entry
+}
+
+// New builds the sinks a notifications section configures. A sink that cannot
+// be built is left out with its error returned, so one bad entry costs the
+// user that sink and not the others.
+func New(cfg config.NotificationsConfig) (*Dispatcher, []error) {
+	d := &Dispatcher{}
+	var errs []error
+	for i, c := range cfg.Sinks {
+		e, err := newEntry(c)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("sink %d: %w", i+1, err))
+			continue
+		}
+		d.entries = append(d.entries, e)
+	}
+	return d, errs
+}
+
+func newEntry(c config.NotificationSinkConfig) (*entry, error) {
+	kind := strings.ToLower(strings.TrimSpace(c.Kind))
+	e := &entry{name: strings.TrimSpace(c.Name), duringQuiet: c.DuringQuiet}
+	if e.name == "" {
+		e.name = kind
+	}
+	switch kind {
+	case "desktop":
+		e.sink = NewDesktop(c.Command)
+	case "osc":
+		osc, err := NewOSC(c.OSC)
+		if err != nil {
+			return nil, err
+		}
+		e.osc = osc
+	case "webhook":
+		hook, err := NewWebhook(c.URL, c.Format, c.Headers, c.Retries)
+		if err != nil {
+			return nil, err
+		}
+		e.sink = hook
+	default:
+		return nil, fmt.Errorf("unknown kind %q (desktop, osc or webhook)", c.Kind)
+	}
+	for _, f := range c.Filter {
+		rule, err := notify.ParseFilter(f)
+		if err != nil {
+			return nil, err
+		}
+		e.filters = append(e.filters, rule)
+	}
+	perMinute := c.PerMinute
+	if perMinute <= 0 {
+		perMinute = defaultPerMinute
+	}
+	e.limit = &limiter{perMinute: perMinute}
+	return e, nil
+}
+
+// Empty reports whether no sink is configured.
+func (d *Dispatcher) Empty() bool { return d == nil || len(d.entries) == 0 }
+
+// wants reports whether e should deliver n now. A sink delivers what would
+// interrupt: nothing a rule suppressed or routed to the centre, nothing
+// already read, and nothing in quiet hours unless the sink is for elsewhere.
+func (e *entry) wants(n notify.Notification, now time.Time) bool {
+	if n.Read() || n.Dismissed() {
+		return false
+	}
+	if r := notify.RouteOf(n); r != notify.RouteToast && r != notify.RouteBell {
+		return false
+	}
+	if !e.duringQuiet && notify.Quiet(now) {
+		return false
+	}
+	if len(e.filters) == 0 {
+		return true
+	}
+	for _, f := range e.filters {
+		if f.Matches(n) {
+			return true
+		}
+	}
+	return false
+}
+
+// Terminal is the OSC notifications for n, for the caller that owns the
+// terminal to write. It is empty when no OSC sink wants n.
+func (d *Dispatcher) Terminal(n notify.Notification, now time.Time) string {
+	if d.Empty() {
+		return ""
+	}
+	var b strings.Builder
+	for _, e := range d.entries {
+		if e.osc == nil || !e.wants(n, now) || !e.limit.allow(now) {
+			continue
+		}
+		b.WriteString(e.osc.Sequence(n))
+	}
+	return b.String()
+}
+
+// Deliver hands n to every other sink that wants it, concurrently, and
+// returns once all have finished or given up. The errors are for the log: a
+// caller has nothing to do about a webhook that is down.
+func (d *Dispatcher) Deliver(ctx context.Context, n notify.Notification, now time.Time) []error {
+	if d.Empty() {
+		return nil
+	}
+	var (
+		mu   sync.Mutex
+		errs []error
+		wg   sync.WaitGroup
+	)
+	for _, e := range d.entries {
+		if e.sink == nil || !e.wants(n, now) {
+			continue
+		}
+		if !e.limit.allow(now) {
+			mu.Lock()
+			errs = append(errs, fmt.Errorf("%s: rate limited, dropped %s", e.name, n.ID))
+			mu.Unlock()
+			continue
+		}
+		wg.Add(1)
+		go func(e *entry) {
+			defer wg.Done()
+			if err := e.sink.Deliver(ctx, n); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", e.name, err))
+				mu.Unlock()
+			}
+		}(e)
+	}
+	wg.Wait()
+	return errs
+}
+
+// limiter allows perMinute deliveries in any sliding minute. The excess is
+// dropped rather than queued: a burst of forty agent completions is one thing
+// to know about, and a phone that buzzes for the next four minutes is not.
+type limiter struct {
+	mu        sync.Mutex
+	perMinute int
+	sent      []time.Time
+}
+
+func (l *limiter) allow(now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	cutoff := now.Add(-time.Minute)
+	kept := l.sent[:0]
+	for _, t := range l.sent {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	l.sent = kept
+	if len(l.sent) >= l.perMinute {
+		return false
+	}
+	l.sent = append(l.sent, now)
+	return true
+}
+
+// clean makes notification text safe to hand to another program or emulator:
+// control characters (ESC and BEL above all, which would end an OSC sequence
+// early) are dropped, whitespace runs collapse to a space, and the result is
+// bounded.
+func clean(s string, max int) string {
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		switch {
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			space = b.Len() > 0
+		case r < 0x20 || (r >= 0x7f && r < 0xa0):
+		default:
+			if space {
+				b.WriteByte(' ')
+				space = false
+			}
+			b.WriteRune(r)
+		}
+	}
+	out := b.String()
+	if runes := []rune(out); len(runes) > max {
+		out = string(runes[:max-1]) + "…"
+	}
+	return out
+}
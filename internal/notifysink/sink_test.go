@@ -0,0 +1,241 @@
+package notifysink
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/notify"
+)
+
+// stub is a local webhook receiver that answers with the queued statuses, then
+// 200s, and records every request it saw.
+type stub struct {
+	mu       sync.Mutex
+	statuses []int
+	bodies   []string
+	headers  []http.Header
+}
+
+func (s *stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bodies = append(s.bodies, string(body))
+	s.headers = append(s.headers, r.Header.Clone())
+	status := http.StatusOK
+	if len(s.statuses) > 0 {
+		status, s.statuses = s.statuses[0], s.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (s *stub) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.bodies)
+}
+
+func noPause(context.Context, time.Duration) error { return nil }
+
+func newDispatcher(t *testing.T, sinks ...config.NotificationSinkConfig) *Dispatcher {
+	t.Helper()
+	d, errs := New(config.NotificationsConfig{Sinks: sinks})
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	for _, e := range d.entries {
+		if w, ok := e.sink.(*Webhook); ok {
+			w.pause = noPause
+		}
+	}
+	return d
+}
+
+func post(title string) notify.Notification {
+	return notify.Normalize(notify.Notification{Source: notify.SourceCI, Severity: notify.SeverityError, Title: title, Body: "main · run 42"}, time.Now())
+}
+
+func TestWebhookFormats(t *testing.T) {
+	srv := &stub{}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	d := newDispatcher(t,
+		config.NotificationSinkConfig{Kind: "webhook", URL: ts.URL, Headers: map[string]string{"Authorization": "Bearer x"}},
+		config.NotificationSinkConfig{Kind: "webhook", URL: ts.URL, Format: "ntfy"},
+		config.NotificationSinkConfig{Kind: "webhook", URL: ts.URL, Format: "slack"},
+	)
+	if errs := d.Deliver(context.Background(), post("CI failed"), time.Now()); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if srv.count() != 3 {
+		t.Fatalf("%d deliveries, want 3", srv.count())
+	}
+	var sawJSON, sawNtfy, sawSlack bool
+	for i, body := range srv.bodies {
+		h := srv.headers[i]
+		switch {
+		case h.Get("Title") != "":
+			sawNtfy = body == "main · run 42" && h.Get("Priority") == "5" && h.Get("Tags") == "ci"
+		case strings.Contains(body, `"text"`):
+			var p map[string]string
+			_ = json.Unmarshal([]byte(body), &p)
+			sawSlack = p["text"] == "*CI failed*\nmain · run 42"
+		default:
+			var p jsonPayload
+			_ = json.Unmarshal([]byte(body), &p)
+			sawJSON = p.Title == "CI failed" && p.Source == "ci" && p.Severity == "error" && h.Get("Authorization") == "Bearer x"
+		}
+	}
+	if !sawJSON || !sawNtfy || !sawSlack {
+		t.Fatalf("json=%v ntfy=%v slack=%v\n%q", sawJSON, sawNtfy, sawSlack, srv.bodies)
+	}
+}
+
+func TestWebhookRetriesOnlyWhatMightSucceed(t *testing.T) {
+	srv := &stub{statuses: []int{503, 429}}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	d := newDispatcher(t, config.NotificationSinkConfig{Kind: "webhook", URL: ts.URL})
+	if errs := d.Deliver(context.Background(), post("a"), time.Now()); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if srv.count() != 3 {
+		t.Fatalf("%d attempts, want 3 (two retries)", srv.count())
+	}
+
+	srv.statuses = []int{400}
+	if errs := d.Deliver(context.Background(), post("b"), time.Now()); len(errs) != 1 {
+		t.Fatalf("a 400 was not reported: %v", errs)
+	}
+	if srv.count() != 4 {
+		t.Fatal("a 400 was retried")
+	}
+
+	srv.statuses = []int{500, 500, 500, 500}
+	if errs := d.Deliver(context.Background(), post("c"), time.Now()); len(errs) != 1 {
+		t.Fatalf("exhausted retries were not reported: %v", errs)
+	}
+	if srv.count() != 7 {
+		t.Fatalf("%d attempts in all, want 7", srv.count())
+	}
+}
+
+func TestFiltersRateLimitAndQuiet(t *testing.T) {
+	t.Cleanup(func() { notify.ApplyConfig(config.NotificationsConfig{}) })
+	srv := &stub{}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	d := newDispatcher(t, config.NotificationSinkConfig{
+		Kind: "webhook", URL: ts.URL, PerMinute: 2,
+		Filter: []config.NotificationRuleConfig{{Source: []string{"ci"}}},
+	})
+	now := time.Now()
+	d.Deliver(context.Background(), notify.Normalize(notify.Notification{Source: notify.SourceAgent, Title: "x"}, now), now)
+	if srv.count() != 0 {
+		t.Fatal("a filtered-out notification was delivered")
+	}
+	for i := 0; i < 3; i++ {
+		d.Deliver(context.Background(), post("burst"), now)
+	}
+	if srv.count() != 2 {
+		t.Fatalf("%d deliveries past a limit of 2", srv.count())
+	}
+	d.Deliver(context.Background(), post("later"), now.Add(61*time.Second))
+	if srv.count() != 3 {
+		t.Fatal("the limit did not slide")
+	}
+
+	notify.ApplyConfig(config.NotificationsConfig{DoNotDisturb: true})
+	d.Deliver(context.Background(), post("quiet"), now.Add(2*time.Minute))
+	if srv.count() != 3 {
+		t.Fatal("a sink delivered under do-not-disturb")
+	}
+	away := newDispatcher(t, config.NotificationSinkConfig{Kind: "webhook", URL: ts.URL, DuringQuiet: true})
+	away.Deliver(context.Background(), post("phone"), now)
+	if srv.count() != 4 {
+		t.Fatal("a duringQuiet sink was held back")
+	}
+}
+
+func TestDesktopRunsNotifySend(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script stand-in")
+	}
+	dir := t.TempDir()
+	record := filepath.Join(dir, "args")
+	fake := filepath.Join(dir, "notify-send")
+	script := "#!/bin/sh\nfor a in \"$@\"; do printf '%s\\n' \"$a\"; done > " + record + "\n"
+	if err := os.WriteFile(fake, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	d := newDispatcher(t, config.NotificationSinkConfig{Kind: "desktop", Command: fake})
+	if errs := d.Deliver(context.Background(), post("CI \x1b]0;pwned\afailed"), time.Now()); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	data, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "--app-name=Sidecar\n--urgency=critical\n--category=sidecar.ci\n⚙ CI ]0;pwnedfailed\nmain · run 42\n"
+	if string(data) != want {
+		t.Fatalf("notify-send got\n%q\nwant\n%q", data, want)
+	}
+
+	failing := newDispatcher(t, config.NotificationSinkConfig{Kind: "desktop", Command: filepath.Join(dir, "missing")})
+	if errs := failing.Deliver(context.Background(), post("x"), time.Now()); len(errs) != 1 {
+		t.Fatalf("a missing command was not reported: %v", errs)
+	}
+}
+
+func TestOSCSequences(t *testing.T) {
+	n := post("Build; failed\a")
+	nine := &OSC{code: 9}
+	if got := nine.Sequence(n); got != "\x1b]9;Build; failed: main · run 42\a" {
+		t.Fatalf("osc 9 = %q", got)
+	}
+	urxvt := &OSC{code: 777}
+	if got := urxvt.Sequence(n); got != "\x1b]777;notify;Build, failed;main · run 42\a" {
+		t.Fatalf("osc 777 = %q", got)
+	}
+	inTmux := &OSC{code: 9, tmux: true}
+	if got := inTmux.Sequence(n); !strings.HasPrefix(got, "\x1bPtmux;\x1b\x1b]9;") || !strings.HasSuffix(got, "\x1b\\") {
+		t.Fatalf("tmux passthrough = %q", got)
+	}
+
+	d := newDispatcher(t, config.NotificationSinkConfig{Kind: "osc", OSC: 777})
+	if d.Terminal(n, time.Now()) == "" {
+		t.Fatal("an osc sink produced nothing")
+	}
+	if errs := d.Deliver(context.Background(), n, time.Now()); len(errs) > 0 {
+		t.Fatal("Deliver tried to deliver an osc sink")
+	}
+}
+
+func TestNewRefusesBadSinks(t *testing.T) {
+	_, errs := New(config.NotificationsConfig{Sinks: []config.NotificationSinkConfig{
+		{Kind: "pager"},
+		{Kind: "osc", OSC: 99},
+		{Kind: "webhook", URL: "ftp://example.com"},
+		{Kind: "webhook", URL: "https://example.com", Format: "xml"},
+		{Kind: "desktop", Filter: []config.NotificationRuleConfig{{Route: "bell"}}},
+		{Kind: "desktop"},
+	}})
+	if len(errs) != 5 {
+		t.Fatalf("errors = %v", errs)
+	}
+}
@@ -0,0 +1,199 @@
+package notifysink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/marcus/sidecar/internal/notify"
+)
+
+// Webhook payload formats.
+const (
+	// FormatJSON posts the notification itself, for a receiver written
+	// against Sidecar.
+	FormatJSON = "json"
+	// FormatNtfy posts the body as text with ntfy's title, priority and tag
+	// headers, to a topic URL.
+	FormatNtfy = "ntfy"
+	// FormatSlack posts {"text": …}, which Slack incoming webhooks and the
+	// many services compatible with them accept.
+	FormatSlack = "slack"
+)
+
+const (
+	// defaultRetries is how many times a failed delivery is tried again.
+	defaultRetries = 2
+	// firstRetryPause doubles after every further failure.
+	firstRetryPause = time.Second
+	// webhookTimeout bounds one attempt.
+	webhookTimeout = 10 * time.Second
+)
+
+// Webhook posts notifications to an HTTP endpoint.
+type Webhook struct {
+	url     string
+	format  string
+	headers map[string]string
+	retries int
+	client  *http.Client
+	// pause waits between attempts; tests replace it.
+	pause func(ctx context.Context, d time.Duration) error
+}
+
+// NewWebhook validates a webhook sink. retries follows the config's rule: 0
+// is the default, -1 is none.
+func NewWebhook(rawURL, format string, headers map[string]string, retries int) (*Webhook, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("webhook url %q is not an http(s) URL", rawURL)
+	}
+	format = strings.ToLower(strings.TrimSpace(format))
+	switch format {
+	case "":
+		format = FormatJSON
+	case FormatJSON, FormatNtfy, FormatSlack:
+	default:
+		return nil, fmt.Errorf("unknown webhook format %q (json, ntfy or slack)", format)
+	}
+	switch {
+	case retries == 0:
+		retries = defaultRetries
+	case retries < 0:
+		retries = 0
+	}
+	return &Webhook{
+		url:     u.String(),
+		format:  format,
+		headers: headers,
+		retries: retries,
+		client:  &http.Client{Timeout: webhookTimeout},
+		pause:   sleep,
+	}, nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// jsonPayload is FormatJSON's body: the parts of a notification a receiver
+// can act on, without the store's bookkeeping.
+type jsonPayload struct {
+	ID        string          `json:"id"`
+	Source    string          `json:"source"`
+	Severity  string          `json:"severity"`
+	Title     string          `json:"title"`
+	Body      string          `json:"body,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Project   string          `json:"project,omitempty"`
+	Provider  string          `json:"provider,omitempty"`
+	Targets   []notify.Target `json:"targets,omitempty"`
+}
+
+// ntfyPriority maps a severity onto ntfy's 1-5 scale.
+func ntfyPriority(sev notify.Severity) string {
+	switch sev {
+	case notify.SeverityError:
+		return "5"
+	case notify.SeverityWarning:
+		return "4"
+	}
+	return "3"
+}
+
+// request builds the body and headers for n in the sink's format.
+func (w *Webhook) request(n notify.Notification) (body []byte, contentType string, headers map[string]string, err error) {
+	headers = map[string]string{}
+	switch w.format {
+	case FormatNtfy:
+		headers["Title"] = clean(n.Title, 200)
+		headers["Priority"] = ntfyPriority(n.Severity)
+		headers["Tags"] = string(n.Source)
+		text := n.Body
+		if strings.TrimSpace(text) == "" {
+			text = n.Title
+		}
+		return []byte(text), "text/plain; charset=utf-8", headers, nil
+	case FormatSlack:
+		text := "*" + n.Title + "*"
+		if n.Body != "" {
+			text += "\n" + n.Body
+		}
+		body, err = json.Marshal(map[string]string{"text": text})
+		return body, "application/json", headers, err
+	}
+	project := n.Origin.ProjectKey
+	if project == "" {
+		project = n.Origin.WorkDir
+	}
+	body, err = json.Marshal(jsonPayload{
+		ID: n.ID, Source: string(n.Source), Severity: string(n.Severity), Title: n.Title, Body: n.Body,
+		CreatedAt: n.CreatedAt, Project: project, Provider: n.Provider, Targets: n.Targets,
+	})
+	return body, "application/json", headers, err
+}
+
+// Deliver implements Sink. A network error, a 429 or a 5xx is tried again; any
+// other answer is final, since sending the same request again will not change
+// a 400's mind.
+func (w *Webhook) Deliver(ctx context.Context, n notify.Notification) error {
+	body, contentType, headers, err := w.request(n)
+	if err != nil {
+		return err
+	}
+	pause := firstRetryPause
+	for attempt := 0; ; attempt++ {
+		retry, err := w.post(ctx, body, contentType, headers)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= w.retries {
+			return err
+		}
+		if err := w.pause(ctx, pause); err != nil {
+			return err
+		}
+		pause *= 2
+	}
+}
+
+// post makes one attempt, and reports whether a failure is worth retrying.
+func (w *Webhook) post(ctx context.Context, body []byte, contentType string, headers map[string]string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "sidecar-notify")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	// Configured headers last, so an Authorization or a ntfy override wins.
+	for k, v := range w.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("webhook answered %s", resp.Status)
+}
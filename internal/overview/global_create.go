@@ -314,9 +314,11 @@ func (m *Model) ensureCreatePlanModal(modalW int) {
 	if plan == nil {
 		return
 	}
-	sections := []modal.Section{
-		modal.Text(fmt.Sprintf("Create %s at\n%s\n\nFrom %s (%s)\n%s", plan.Branch, plan.Path, plan.SourceRef, shortCreateOID(plan.SourceOID), plan.RemotePolicy)),
+	summary := fmt.Sprintf("Create %s at\n%s\n\nFrom %s (%s)\n%s", plan.Branch, plan.Path, plan.SourceRef, shortCreateOID(plan.SourceOID), plan.RemotePolicy)
+	if plan.Sparse != nil {
+		summary += "\nSparse checkout: " + plan.Sparse.Describe()
 	}
+	sections := []modal.Section{modal.Text(summary)}
 	if m.createError != "" {
 		sections = append(sections, modal.Spacer(), modal.Text("Error: "+m.createError))
 	}
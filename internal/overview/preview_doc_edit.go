@@ -5,7 +5,6 @@ import (
 	"time"
 
 	tea "charm.land/bubbletea/v2"
-	"github.com/marcus/sidecar/internal/features"
 	"github.com/marcus/sidecar/internal/inlineedit"
 	"github.com/marcus/sidecar/internal/mouse"
 	appmsg "github.com/marcus/sidecar/internal/msg"
@@ -117,8 +116,8 @@ func (m *Model) enterPreviewDocEdit() tea.Cmd {
 	if doc == nil || doc.editing() {
 		return nil
 	}
-	if !features.IsEnabled(features.TmuxInlineEdit.Name) {
-		return appmsg.ShowToast("Inline edit is disabled (features.tmux-inline-edit)", 3*time.Second)
+	if !inlineedit.Enabled() {
+		return appmsg.ShowToast(inlineedit.DisabledMessage, 3*time.Second)
 	}
 	abs, rel, ok := doc.editorPath()
 	if !ok {
@@ -133,7 +132,7 @@ func (m *Model) enterPreviewDocEdit() tea.Cmd {
 	if view := doc.view(); view != nil {
 		line = view.TopSourceLine()
 	}
-	return inlineedit.Start(inlineedit.StartOptions{
+	return inlineedit.StartPreferred(inlineedit.StartOptions{
 		Surface:    previewDocEditSurface,
 		AbsPath:    abs,
 		Path:       rel,
@@ -170,7 +169,7 @@ func (m *Model) applyPreviewDocEditStarted(msg inlineedit.StartedMsg) tea.Cmd {
 		}
 	}
 	doc.focused = true
-	return session.Begin(msg.SessionName, msg.Editor, msg.Path)
+	return session.BeginStarted(msg)
 }
 
 func (m *Model) applyPreviewDocEditExited(msg inlineedit.ExitedMsg) tea.Cmd {
@@ -348,8 +347,8 @@ func (m *Model) renderPreviewDocEdit(doc *previewDoc, box termpreview.Box) strin
 	body := ""
 	if session.ShowExitConfirm {
 		body = session.RenderExitConfirm()
-	} else if session.Model != nil {
-		body = session.Model.View()
+	} else {
+		body = session.View()
 	}
 	return header + "\n" + ui.FitBlock(body, box.W, bodyH)
 }
@@ -110,6 +110,14 @@ type FooterStatusProvider interface {
 	FooterStatus() (string, bool)
 }
 
+// UnsavedEditsReporter is an optional capability for plugins that hold edits
+// in memory until the user saves them. The quit confirmation names the files
+// quitting would lose, since a plugin cannot prompt once Stop has been called.
+type UnsavedEditsReporter interface {
+	// UnsavedEdits lists the files with edits that are not written yet.
+	UnsavedEdits() []string
+}
+
 type WorkspaceSelectionKind string
 
 const (
@@ -109,6 +109,9 @@ func (p *Plugin) contentLinksSafe() bool {
 	if p.markdownRenderMode && p.isMarkdownFile() {
 		return false
 	}
+	if p.showingStructured() {
+		return false
+	}
 	return p.activePreviewLoaded()
 }
 
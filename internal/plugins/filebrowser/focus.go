@@ -42,7 +42,7 @@ func (p *Plugin) currentFocusTarget() panelayout.Target {
 // booleans that could drift from it.
 func (p *Plugin) AtFocusCycleEnd(reverse bool) bool {
 	switch p.FocusContext() {
-	case "file-browser-tree", "file-browser-preview":
+	case "file-browser-tree", "file-browser-preview", "file-browser-structured":
 	default:
 		return false
 	}
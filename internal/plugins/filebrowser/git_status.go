@@ -0,0 +1,148 @@
+package filebrowser
+
+import (
+	"context"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/marcus/sidecar/internal/gitstate"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+	"github.com/marcus/sidecar/internal/styles"
+)
+
+// The tree is decorated with the git status the git plugin broadcasts after
+// each of its refreshes (gitstate.SnapshotMsg). Until one arrives — the git
+// plugin may be disabled, or may not have loaded yet — the browser loads its
+// own after each tree build, and stops once the shared snapshots start coming.
+
+// gitStatusTimeout bounds a status the browser loads for itself; a huge
+// untracked tree must not leave a git process running behind the UI.
+const gitStatusTimeout = 10 * time.Second
+
+// gitStatusLoadedMsg carries a status the browser loaded for itself.
+type gitStatusLoadedMsg struct {
+	Epoch    uint64
+	Snapshot *gitstate.Snapshot
+	Err      error
+}
+
+func (m gitStatusLoadedMsg) GetEpoch() uint64 { return m.Epoch }
+
+// loadGitStatus loads a status for the tree unless the git plugin is already
+// sharing one. A load requested while one runs is remembered and issued when
+// it lands, so the result never describes the disk from before a rebuild.
+func (p *Plugin) loadGitStatus() tea.Cmd {
+	if p.gitStatusShared || p.ctx == nil || p.ctx.WorkDir == "" {
+		return nil
+	}
+	if p.gitStatusLoading {
+		p.gitStatusDirty = true
+		return nil
+	}
+	p.gitStatusLoading = true
+	dir, epoch := p.ctx.WorkDir, p.ctx.Epoch
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), gitStatusTimeout)
+		defer cancel()
+		s, err := gitstate.Load(ctx, dir)
+		return gitStatusLoadedMsg{Epoch: epoch, Snapshot: s, Err: err}
+	}
+}
+
+// handleGitStatusLoaded applies a status the browser loaded itself. Outside a
+// repository the load fails and the tree simply stays undecorated.
+func (p *Plugin) handleGitStatusLoaded(msg gitStatusLoadedMsg) tea.Cmd {
+	p.gitStatusLoading = false
+	if msg.Err == nil && !p.gitStatusShared {
+		p.applyGitStatus(msg.Snapshot)
+	}
+	if p.gitStatusDirty {
+		p.gitStatusDirty = false
+		return p.loadGitStatus()
+	}
+	return nil
+}
+
+// handleSharedGitStatus adopts a snapshot broadcast by the git plugin when it
+// describes this project.
+func (p *Plugin) handleSharedGitStatus(msg gitstate.SnapshotMsg) {
+	if p.ctx == nil || !msg.Snapshot.Covers(p.ctx.WorkDir) {
+		return
+	}
+	p.gitStatusShared = true
+	p.applyGitStatus(msg.Snapshot)
+}
+
+// applyGitStatus installs s on the tree, refiltering it when only changed
+// files are shown and keeping the cursor on the entry it was on.
+func (p *Plugin) applyGitStatus(s *gitstate.Snapshot) {
+	p.gitSnapshot = s
+	if p.tree == nil {
+		return
+	}
+	p.tree.Status = s
+	if !p.tree.ChangedOnly {
+		return
+	}
+	var cursorPath string
+	if node := p.tree.GetNode(p.treeCursor); node != nil {
+		cursorPath = node.Path
+	}
+	p.tree.Flatten()
+	p.reanchorTreeCursor(cursorPath)
+}
+
+// toggleChangedOnly switches the tree between every entry and only those git
+// reports changes in (with the directories that lead to them).
+func (p *Plugin) toggleChangedOnly() tea.Cmd {
+	if p.tree == nil {
+		return nil
+	}
+	if !p.changedOnly && p.gitSnapshot == nil {
+		return appmsg.ShowFlash("No git status to filter by")
+	}
+	var cursorPath string
+	if node := p.tree.GetNode(p.treeCursor); node != nil {
+		cursorPath = node.Path
+	}
+	p.changedOnly = !p.changedOnly
+	p.tree.ChangedOnly = p.changedOnly
+	p.tree.Flatten()
+	p.reanchorTreeCursor(cursorPath)
+	if p.changedOnly && p.tree.Len() == 0 {
+		return appmsg.ShowFlash("No changed files")
+	}
+	return nil
+}
+
+// gitMarker is the one-cell status mark drawn at the end of a tree row, and
+// its style. Files show their own state; directories show a dot in the color
+// of the most pressing change beneath them. Paths outside a sparse checkout
+// that carry no index change show a tilde, untracked ones included: git
+// leaves them out of the checkout and refuses to stage them by default.
+func gitMarker(state gitstate.State, outsideSparse bool, node *FileNode) (string, lipgloss.Style) {
+	var mark string
+	var style lipgloss.Style
+	switch {
+	case state&gitstate.Conflicted != 0:
+		mark, style = "U", styles.StatusDeleted
+	case state&gitstate.Modified != 0:
+		mark, style = "M", styles.StatusModified
+	case state&gitstate.Staged != 0:
+		mark, style = "S", styles.StatusStaged
+	case outsideSparse:
+		return "~", styles.FileBrowserIgnored
+	case state&gitstate.Untracked != 0:
+		mark, style = "?", styles.StatusUntracked
+	case node.IsIgnored:
+		mark, style = "!", styles.FileBrowserIgnored
+	default:
+		return "", style
+	}
+	if node.IsDir && state.Changed() {
+		mark = "•"
+	}
+	return mark, style
+}
@@ -0,0 +1,128 @@
+package filebrowser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/marcus/sidecar/internal/gitstate"
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+func newGitStatusPlugin(t *testing.T) (*Plugin, string) {
+	t.Helper()
+	dir := t.TempDir()
+	for _, f := range []string{"clean.go", "edited.go", "pkg/staged.go", "pkg/other.go", "docs/readme.md"} {
+		full := filepath.Join(dir, f)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p := &Plugin{ctx: &plugin.Context{WorkDir: dir}, width: 100, height: 30, treeWidth: 40}
+	p.tree = NewFileTree(dir)
+	if err := p.tree.Build(); err != nil {
+		t.Fatal(err)
+	}
+	return p, dir
+}
+
+func shareStatus(p *Plugin, dir string) {
+	out := strings.Join([]string{
+		"1 .M N... 100644 100644 100644 a b edited.go",
+		"1 M. N... 100644 100644 100644 a b pkg/staged.go",
+		"",
+	}, "\x00")
+	p.Update(gitstate.SnapshotMsg{Snapshot: gitstate.Parse(dir, []byte(out))})
+}
+
+func TestSharedStatusDecoratesTree(t *testing.T) {
+	p, dir := newGitStatusPlugin(t)
+	shareStatus(p, dir)
+	if !p.gitStatusShared || p.loadGitStatus() != nil {
+		t.Fatal("a shared snapshot should stop the browser running git itself")
+	}
+
+	rows := map[string]string{}
+	for _, node := range p.tree.FlatList {
+		rows[node.Path] = ansi.Strip(p.renderTreeNode(node, false, 30))
+	}
+	if row := rows["edited.go"]; !strings.HasSuffix(row, " M") || ansi.StringWidth(row) != 30 {
+		t.Fatalf("modified row = %q, want a right-aligned M", row)
+	}
+	if row := rows["pkg"]; !strings.HasSuffix(row, "•") {
+		t.Fatalf("directory row = %q, want a rolled-up mark", row)
+	}
+	if row := rows["clean.go"]; strings.TrimRight(row, " ") != "  clean.go" {
+		t.Fatalf("clean row = %q, want no mark", row)
+	}
+}
+
+func TestSparseCheckoutMarksPathsOutsideTheCone(t *testing.T) {
+	p, dir := newGitStatusPlugin(t)
+	out := "? docs/readme.md\x00"
+	s := gitstate.Parse(dir, []byte(out))
+	s.Sparse = &gitstate.Sparse{Cone: true, Patterns: []string{"pkg"}}
+	p.Update(gitstate.SnapshotMsg{Snapshot: s})
+
+	rows := map[string]string{}
+	for _, node := range p.tree.FlatList {
+		rows[node.Path] = strings.TrimRight(ansi.Strip(p.renderTreeNode(node, false, 30)), " ")
+	}
+	if row := rows["docs"]; !strings.HasSuffix(row, "~") {
+		t.Fatalf("directory outside the cone = %q, want ~", row)
+	}
+	if row := rows["pkg"]; strings.HasSuffix(row, "~") {
+		t.Fatalf("cone directory = %q, want no mark", row)
+	}
+	if row := rows["clean.go"]; strings.HasSuffix(row, "~") {
+		t.Fatalf("top-level file = %q, want no mark", row)
+	}
+}
+
+func TestSharedStatusForOtherProjectIgnored(t *testing.T) {
+	p, _ := newGitStatusPlugin(t)
+	shareStatus(p, t.TempDir())
+	if p.gitStatusShared || p.tree.Status != nil {
+		t.Fatal("a snapshot of another repository must not decorate this tree")
+	}
+}
+
+func TestChangedOnlyFilter(t *testing.T) {
+	p, dir := newGitStatusPlugin(t)
+	if cmd := p.toggleChangedOnly(); cmd == nil || p.changedOnly {
+		t.Fatal("without a status the filter should explain itself and stay off")
+	}
+
+	shareStatus(p, dir)
+	p.toggleChangedOnly()
+	var paths []string
+	for _, node := range p.tree.FlatList {
+		paths = append(paths, node.Path)
+	}
+	if got := strings.Join(paths, ","); got != "pkg,edited.go" {
+		t.Fatalf("changed-only rows = %s", got)
+	}
+
+	pkg := p.tree.FindByPath("pkg")
+	if err := p.tree.Expand(pkg); err != nil {
+		t.Fatal(err)
+	}
+	paths = paths[:0]
+	for _, node := range p.tree.FlatList {
+		paths = append(paths, node.Path)
+	}
+	if got := strings.Join(paths, ","); got != "pkg,pkg/staged.go,edited.go" {
+		t.Fatalf("expanded changed-only rows = %s", got)
+	}
+
+	p.toggleChangedOnly()
+	if p.tree.Len() != 6 {
+		t.Fatalf("turning the filter off should restore every row (pkg stays open), got %d", p.tree.Len())
+	}
+}
@@ -9,12 +9,15 @@ import (
 
 	"charm.land/bubbles/v2/textinput"
 	tea "charm.land/bubbletea/v2"
+	"github.com/marcus/sidecar/internal/app"
 	"github.com/marcus/sidecar/internal/clip"
 	"github.com/marcus/sidecar/internal/docview"
 	appmsg "github.com/marcus/sidecar/internal/msg"
 	"github.com/marcus/sidecar/internal/plugin"
 	"github.com/marcus/sidecar/internal/state"
+	"github.com/marcus/sidecar/internal/structview"
 	"github.com/marcus/sidecar/internal/ui"
+	"github.com/marcus/sidecar/internal/uirequest"
 )
 
 func (p *Plugin) handleKey(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd) {
@@ -227,7 +230,11 @@ func (p *Plugin) handleTreeKey(key string) (plugin.Plugin, tea.Cmd) {
 		}
 
 	case "R":
-		// Rename file/directory
+		// Rename file/directory, or the selection from a pattern
+		if p.hasSelection() {
+			p.startBulkRename()
+			return p, nil
+		}
 		node := p.tree.GetNode(p.treeCursor)
 		if node != nil && node != p.tree.Root {
 			p.fileOpMode = FileOpRename
@@ -267,13 +274,24 @@ func (p *Plugin) handleTreeKey(key string) (plugin.Plugin, tea.Cmd) {
 			return p.openBlameView(node.Path)
 		}
 
+	case "b":
+		// Bookmark the selected file
+		node := p.tree.GetNode(p.treeCursor)
+		if node != nil && !node.IsDir {
+			return p, app.AddBookmark(uirequest.Target{Kind: uirequest.TargetKindFile, Value: filepath.ToSlash(node.Path)}, "")
+		}
+
 	case "r":
 		// Refresh file tree
 		p.lastRefresh = time.Now()
 		return p, p.refresh()
 
 	case "m":
-		// Move file/directory
+		// Move file/directory, or every selected one into a directory
+		if p.hasSelection() {
+			p.startBulkMove()
+			return p, nil
+		}
 		node := p.tree.GetNode(p.treeCursor)
 		if node != nil && node != p.tree.Root {
 			p.fileOpMode = FileOpMove
@@ -314,22 +332,29 @@ func (p *Plugin) handleTreeKey(key string) (plugin.Plugin, tea.Cmd) {
 		}
 
 	case "D":
-		// Delete file/directory (requires confirmation)
+		// Move file/directory to the trash (requires confirmation)
+		if p.hasSelection() {
+			p.startBulkDelete()
+			return p, nil
+		}
 		node := p.tree.GetNode(p.treeCursor)
 		if node != nil && node != p.tree.Root {
 			p.fileOpMode = FileOpDelete
 			p.fileOpTarget = node
+			p.fileOpPaths = nil
 			p.fileOpConfirmDelete = true
 			p.fileOpError = ""
 			p.fileOpButtonFocus = 1 // Start with confirm button focused
 		}
 
 	case "y":
-		// Yank (mark) file/directory for paste
+		// Yank (mark) file/directory, or the selection, for paste
+		if p.hasSelection() {
+			return p, p.yankSelection()
+		}
 		node := p.tree.GetNode(p.treeCursor)
 		if node != nil && node != p.tree.Root {
-			p.clipboardPath = node.Path
-			p.clipboardIsDir = node.IsDir
+			p.clipboardPaths = []string{node.Path}
 			return p, appmsg.ShowFlash("Marked for copy: " + node.Path)
 		}
 
@@ -342,13 +367,32 @@ func (p *Plugin) handleTreeKey(key string) (plugin.Plugin, tea.Cmd) {
 
 	case "p":
 		// Paste file/directory from clipboard
-		if p.clipboardPath != "" {
+		if len(p.clipboardPaths) > 0 {
 			node := p.tree.GetNode(p.treeCursor)
 			if node != nil {
 				return p, p.doPaste(node)
 			}
 		}
 
+	case "space", " ":
+		// Select the cursor row for a bulk operation
+		return p, p.toggleMark()
+
+	case "V":
+		// Select a range: from here to wherever the cursor goes
+		p.toggleVisual()
+
+	case "esc":
+		p.clearSelection()
+
+	case "S":
+		// Stage the selection, or the cursor row, in git
+		return p, p.stagePaths()
+
+	case "U":
+		// Restore a file from the trash
+		return p, p.listTrash()
+
 	case "s":
 		// Cycle sort mode
 		newMode := p.tree.SortMode.Next()
@@ -444,6 +488,12 @@ func (p *Plugin) handleTreeKey(key string) (plugin.Plugin, tea.Cmd) {
 			}
 		}
 
+	case "C":
+		// Show only files with git changes
+		cmd := p.toggleChangedOnly()
+		p.ensureTreeCursorVisible()
+		return p, cmd
+
 	case "{":
 		return p, p.cycleTab(-1)
 
@@ -458,6 +508,13 @@ func (p *Plugin) handleTreeKey(key string) (plugin.Plugin, tea.Cmd) {
 }
 
 func (p *Plugin) handlePreviewKey(key string) (plugin.Plugin, tea.Cmd) {
+	if handled, cmd := p.handleStructuredKey(key); handled {
+		return p, cmd
+	}
+	if handled, cmd := p.handleStreamKey(key); handled {
+		return p, cmd
+	}
+
 	lines := p.getPreviewLines()
 	visibleHeight := p.visibleContentHeight()
 	maxScroll := len(lines) - visibleHeight
@@ -556,12 +613,19 @@ func (p *Plugin) handlePreviewKey(key string) (plugin.Plugin, tea.Cmd) {
 		}
 
 	case ":":
+		// Line numbers belong to the text; leave a structured view to jump.
+		if p.showingStructured() && p.structuredKind() != structview.KindSQLite {
+			_ = p.toggleStructured()
+		}
 		p.lineJumpMode = true
 		p.lineJumpBuffer = ""
 
 	case "/":
 		// Enter content search mode if we have content to search
 		if len(p.previewLines) > 0 && !p.isBinary {
+			if p.showingStructured() {
+				_ = p.toggleStructured() // Matches are found and drawn in the text
+			}
 			p.selection.Clear() // Clear selection before entering search
 			p.contentSearchMode = true
 			p.contentSearchCommitted = false
@@ -628,6 +692,10 @@ func (p *Plugin) handlePreviewKey(key string) (plugin.Plugin, tea.Cmd) {
 			p.toggleMarkdownRender()
 		}
 
+	case "v":
+		// Toggle the structured view for JSON, YAML, CSV and TSV files
+		return p, p.toggleStructured()
+
 	case "w":
 		// Toggle line wrapping
 		p.previewWrapEnabled = !p.previewWrapEnabled
@@ -640,6 +708,13 @@ func (p *Plugin) handlePreviewKey(key string) (plugin.Plugin, tea.Cmd) {
 			return p.openBlameView(p.previewFile)
 		}
 
+	case "b":
+		// Bookmark the preview at its top visible line
+		if p.previewFile != "" {
+			target := uirequest.Target{Kind: uirequest.TargetKindFile, Value: filepath.ToSlash(p.previewFile), Line: p.previewScroll + 1}
+			return p, app.AddBookmark(target, "")
+		}
+
 	case "{":
 		return p, p.cycleTab(-1)
 
@@ -673,6 +748,11 @@ func (p *Plugin) handlePreviewKey(key string) (plugin.Plugin, tea.Cmd) {
 func (p *Plugin) handleFileOpKey(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd) {
 	key := msg.String()
 
+	// The restore list is a picker, not an input
+	if p.fileOpMode == FileOpRestore {
+		return p, p.handleRestoreKey(key)
+	}
+
 	// Handle delete confirmation mode
 	if p.fileOpConfirmDelete {
 		switch key {
@@ -682,16 +762,17 @@ func (p *Plugin) handleFileOpKey(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd) {
 				// Cancel button focused, treat as cancel
 				p.fileOpMode = FileOpNone
 				p.fileOpTarget = nil
+				p.fileOpPaths = nil
 				p.fileOpError = ""
 				p.fileOpConfirmDelete = false
 				return p, nil
 			}
-			p.fileOpConfirmDelete = false
-			return p, p.doDelete()
+			return p, p.confirmDelete()
 		case "n", "N", "esc":
 			// Cancel delete
 			p.fileOpMode = FileOpNone
 			p.fileOpTarget = nil
+			p.fileOpPaths = nil
 			p.fileOpError = ""
 			p.fileOpConfirmDelete = false
 			return p, nil
@@ -765,13 +846,15 @@ func (p *Plugin) handleFileOpKey(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd) {
 		// Cancel file operation
 		p.fileOpMode = FileOpNone
 		p.fileOpTarget = nil
+		p.fileOpPaths = nil
+		p.renamePlan = nil
 		p.fileOpError = ""
 		p.fileOpShowSuggestions = false
 		return p, nil
 
 	case "up", "ctrl+p":
 		// Navigate suggestions up (for move modal)
-		if p.fileOpMode == FileOpMove && p.fileOpShowSuggestions && len(p.fileOpSuggestions) > 0 {
+		if p.fileOpTakesPath() && p.fileOpShowSuggestions && len(p.fileOpSuggestions) > 0 {
 			p.fileOpSuggestionIdx--
 			if p.fileOpSuggestionIdx < -1 {
 				p.fileOpSuggestionIdx = len(p.fileOpSuggestions) - 1
@@ -782,7 +865,7 @@ func (p *Plugin) handleFileOpKey(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd) {
 
 	case "down", "ctrl+n":
 		// Navigate suggestions down (for move modal)
-		if p.fileOpMode == FileOpMove && p.fileOpShowSuggestions && len(p.fileOpSuggestions) > 0 {
+		if p.fileOpTakesPath() && p.fileOpShowSuggestions && len(p.fileOpSuggestions) > 0 {
 			p.fileOpSuggestionIdx++
 			if p.fileOpSuggestionIdx >= len(p.fileOpSuggestions) {
 				p.fileOpSuggestionIdx = -1
@@ -793,7 +876,7 @@ func (p *Plugin) handleFileOpKey(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd) {
 
 	case "tab":
 		// If suggestions are visible, use tab to complete
-		if p.fileOpMode == FileOpMove && p.fileOpShowSuggestions {
+		if p.fileOpTakesPath() && p.fileOpShowSuggestions {
 			idx := p.fileOpSuggestionIdx
 			if idx < 0 {
 				idx = 0 // Auto-select first if none selected
@@ -830,13 +913,15 @@ func (p *Plugin) handleFileOpKey(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd) {
 		if p.fileOpButtonFocus == 2 {
 			p.fileOpMode = FileOpNone
 			p.fileOpTarget = nil
+			p.fileOpPaths = nil
+			p.renamePlan = nil
 			p.fileOpError = ""
 			p.fileOpShowSuggestions = false
 			return p, nil
 		}
 
 		// If suggestions active and selected, use suggestion
-		if p.fileOpMode == FileOpMove && p.fileOpShowSuggestions && p.fileOpSuggestionIdx >= 0 {
+		if p.fileOpTakesPath() && p.fileOpShowSuggestions && p.fileOpSuggestionIdx >= 0 {
 			if p.fileOpSuggestionIdx < len(p.fileOpSuggestions) {
 				p.fileOpTextInput.SetValue(p.fileOpSuggestions[p.fileOpSuggestionIdx])
 				p.fileOpShowSuggestions = false
@@ -854,6 +939,9 @@ func (p *Plugin) handleFileOpKey(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd) {
 			var cmd tea.Cmd
 			p.fileOpTextInput, cmd = p.fileOpTextInput.Update(msg)
 			p.fileOpError = "" // Clear error on input change
+			if p.fileOpMode == FileOpBulkRename {
+				p.updateRenamePlan()
+			}
 
 			// Update suggestions for move modal on text change
 			if scanCmd := p.updateFileOpSuggestions(); scanCmd != nil {
@@ -866,6 +954,16 @@ func (p *Plugin) handleFileOpKey(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd) {
 	}
 }
 
+// confirmDelete runs the delete the confirmation asked about: the selection
+// to the trash, or the single target.
+func (p *Plugin) confirmDelete() tea.Cmd {
+	p.fileOpConfirmDelete = false
+	if len(p.fileOpPaths) > 0 {
+		return p.doTrash(p.fileOpPaths)
+	}
+	return p.doDelete()
+}
+
 // handleInfoKey handles key input during info modal mode.
 func (p *Plugin) handleInfoKey(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd) {
 	p.ensureInfoModal()
@@ -896,6 +994,10 @@ func (p *Plugin) handleContentSearchKey(msg tea.KeyPressMsg) (plugin.Plugin, tea
 	key := msg.String()
 	text := ui.PrintableKeyText(msg)
 
+	if p.stream != nil {
+		return p, p.handleStreamSearchKey(key, text)
+	}
+
 	// Esc always exits search mode completely
 	if key == "esc" {
 		p.contentSearchMode = false
@@ -1263,6 +1365,11 @@ func (p *Plugin) handleLineJumpKey(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd)
 		// Execute jump (1-based input -> 0-based index)
 		target := lineNum - 1
 
+		if p.activePane == PanePreview && p.stream != nil {
+			p.lineJumpMode = false
+			p.lineJumpBuffer = ""
+			return p, p.jumpStreamLine(target)
+		}
 		if p.activePane == PanePreview {
 			// Jump in preview pane
 			lines := p.getPreviewLines()
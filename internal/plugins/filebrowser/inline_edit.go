@@ -26,6 +26,9 @@ type InlineEditStartedMsg struct {
 	Editor        string    // Editor command used (vim, nano, emacs, etc.)
 	Activation    uint64
 	Epoch         uint64
+	// Native is the loaded built-in editor when native_inline_edit is on;
+	// SessionName and Editor are empty then.
+	Native *inlineedit.Code
 }
 
 // InlineEditExitedMsg is sent when inline edit mode exits.
@@ -39,6 +42,10 @@ type InlineEditExitedMsg struct {
 // Creates a tmux session running the user's editor and delegates to tty.Model.
 // lineNo is 0-indexed; converted to 1-indexed for editor.
 func (p *Plugin) enterInlineEditMode(path string, lineNo int) tea.Cmd {
+	if nativeInlineEditEnabled() {
+		return p.enterNativeEditMode(path, lineNo)
+	}
+
 	// Check feature flag
 	if !features.IsEnabled(features.TmuxInlineEdit.Name) {
 		return p.openFile(path)
@@ -100,6 +107,42 @@ func (p *Plugin) enterInlineEditMode(path string, lineNo int) tea.Cmd {
 	}
 }
 
+// enterNativeEditMode loads the file into the built-in editor. Loading runs
+// off the update loop and lands as the same InlineEditStartedMsg a tmux start
+// does, so activation and epoch guards stay one path.
+func (p *Plugin) enterNativeEditMode(path string, lineNo int) tea.Cmd {
+	fullPath := filepath.Join(p.ctx.WorkDir, path)
+	p.edit.Activation++
+	activation := p.edit.Activation
+	epoch := p.ctx.Epoch
+
+	return func() tea.Msg {
+		code, err := inlineedit.LoadCode(fullPath, lineNo+1)
+		if err != nil {
+			return msg.ToastMsg{
+				Message:  fmt.Sprintf("Failed to open editor: %v", err),
+				Duration: 3 * time.Second,
+				IsError:  true,
+			}
+		}
+		var origMtime time.Time
+		if info, err := os.Stat(fullPath); err == nil {
+			origMtime = info.ModTime()
+		}
+		return InlineEditStartedMsg{
+			FilePath:      path,
+			OriginalMtime: origMtime,
+			Activation:    activation,
+			Epoch:         epoch,
+			Native:        code,
+		}
+	}
+}
+
+func nativeInlineEditEnabled() bool {
+	return features.IsEnabled(features.NativeInlineEdit.Name)
+}
+
 // handleInlineEditStarted processes the InlineEditStartedMsg and activates the tty model.
 func (p *Plugin) handleInlineEditStarted(msg InlineEditStartedMsg) tea.Cmd {
 	if msg.Activation != p.edit.Activation || p.ctx == nil || msg.Epoch != p.ctx.Epoch {
@@ -108,6 +151,15 @@ func (p *Plugin) handleInlineEditStarted(msg InlineEditStartedMsg) tea.Cmd {
 	p.activePane = PanePreview
 	p.inlineEditOrigMtime = msg.OriginalMtime
 
+	if msg.Native != nil {
+		// No process, no callbacks: the built-in editor is driven by update.
+		// Its buffer lives on the tab, so the tab is pinned: a preview tab is
+		// replaced by the next navigation and closed by the next tab switch.
+		p.pinTab(p.activeTab)
+		p.editor().BeginNative(msg.Native, msg.FilePath)
+		return nil
+	}
+
 	// Configure the tty model callbacks
 	activation, epoch, filePath := msg.Activation, msg.Epoch, msg.FilePath
 	p.edit.Model.OnExit = func() tea.Cmd {
@@ -134,6 +186,10 @@ func (p *Plugin) handleInlineEditStarted(msg InlineEditStartedMsg) tea.Cmd {
 }
 
 func (p *Plugin) cleanupStaleInlineEditStart(msg InlineEditStartedMsg) tea.Cmd {
+	if msg.Native != nil {
+		// A stale native load holds only memory; dropping it is the cleanup.
+		return nil
+	}
 	// A delayed start may refer to a name that tmux has since reused for the
 	// currently active editor. Never let stale-message cleanup kill the target
 	// that owns the live model activation.
@@ -185,6 +241,10 @@ func (p *Plugin) copyInlineEditorOutputCmd() tea.Cmd {
 // reattachInlineEditSession re-attaches to an existing tmux session after tab switch.
 // Called when returning to a tab that was previously in edit mode.
 func (p *Plugin) reattachInlineEditSession() tea.Cmd {
+	if p.edit.Native != nil {
+		p.activePane = PanePreview
+		return nil
+	}
 	if p.edit.Name == "" {
 		return nil
 	}
@@ -213,6 +273,14 @@ func (p *Plugin) reattachInlineEditSession() tea.Cmd {
 
 // exitInlineEditMode cleans up inline edit state and kills the tmux session.
 func (p *Plugin) exitInlineEditMode() {
+	// The tab no longer has an editor to return to.
+	if p.activeTab >= 0 && p.activeTab < len(p.tabs) && p.tabs[p.activeTab].Path == p.edit.Path {
+		tab := &p.tabs[p.activeTab]
+		tab.EditNative = nil
+		tab.EditSession = ""
+		tab.EditOrigMtime = time.Time{}
+		tab.EditEditor = ""
+	}
 	p.edit.Exit()
 	p.inlineEditOrigMtime = time.Time{}
 }
@@ -321,6 +389,10 @@ func (p *Plugin) calculateInlineEditorHeight() int {
 
 // isInlineEditSupported checks if inline editing can be used for the given file.
 func (p *Plugin) isInlineEditSupported(path string) bool {
+	if nativeInlineEditEnabled() {
+		return !p.isBinary
+	}
+
 	// Check feature flag
 	if !features.IsEnabled(features.TmuxInlineEdit.Name) {
 		return false
@@ -369,9 +441,9 @@ func (p *Plugin) renderInlineEditorContent(visibleHeight int) string {
 	}
 	contentHeight -= 2 // header + empty line
 
-	// Render terminal content from tty model
-	if p.edit.Model != nil {
-		content := p.edit.Model.View()
+	// Render the editor: the native buffer, or terminal content from the tty model
+	if p.edit.Native != nil || p.edit.Model != nil {
+		content := p.edit.View()
 		lines := strings.Split(content, "\n")
 
 		// Limit to content height
@@ -410,7 +482,12 @@ func (p *Plugin) handleExitConfirmationChoice() (*Plugin, tea.Cmd) {
 	case 0: // Save & Exit
 		// Try to send editor-specific save-and-quit commands.
 		// If unknown editor, we still proceed but skip the save attempt.
-		p.edit.SaveAndQuit()
+		if !p.edit.SaveAndQuit() {
+			// The built-in editor could not write (or the file changed on
+			// disk); stay in it, its status line says why.
+			p.edit.ClearPendingClick()
+			return p, nil
+		}
 
 		// Give editor a moment to process, then kill session
 		// (Session may already be dead from quit command, kill-session will fail silently)
@@ -446,6 +523,11 @@ func (p *Plugin) processPendingClickAction() (*Plugin, tea.Cmd) {
 		// User clicked tree pane background - focus tree and refresh preview
 		p.activePane = PaneTree
 		return p, p.loadCurrentTreeItemPreview()
+	case pendingCloseTab:
+		// A tab whose unsaved edits were just saved or discarded.
+		if path, ok := data.(string); ok {
+			return p, p.closeTab(p.findTab(path))
+		}
 	case "preview-tab":
 		// User clicked a tab — switch, or close when the click landed on ×.
 		if _, _, ok := previewTabPayload(data); ok {
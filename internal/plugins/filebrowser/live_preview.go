@@ -38,7 +38,12 @@ func (p *Plugin) previewRefreshSuppressed() bool {
 	}
 	// An overlay is drawn over the preview; reloading behind it buys nothing and
 	// would rebuild state the overlay is reading.
-	return p.infoMode || p.blameMode
+	if p.infoMode || p.blameMode {
+		return true
+	}
+	// A streamed file follows its own changes, indexing only what was
+	// appended; a reload would read its head and start over.
+	return p.stream != nil
 }
 
 // refreshPreview re-reads the previewed file if a change is owed and nothing is
@@ -103,7 +108,7 @@ func (p *Plugin) applyPreviewRefresh(msg previewRefreshedMsg) tea.Cmd {
 	}
 
 	scroll := p.previewScroll
-	p.applyPreviewResult(msg.Result)
+	structuredCmd := p.applyPreviewResult(msg.Result)
 	p.updateActiveTabResult(msg.Result)
 	p.previewScroll = scroll
 	p.clampPreviewScroll()
@@ -114,7 +119,7 @@ func (p *Plugin) applyPreviewRefresh(msg previewRefreshedMsg) tea.Cmd {
 	if p.contentSearchMode && p.contentSearchQuery != "" {
 		p.updateContentMatches()
 	}
-	return followUp
+	return tea.Batch(followUp, structuredCmd)
 }
 
 // adoptPreviewFingerprint records what an explicit load put on screen, so the
@@ -52,6 +52,17 @@ func (p *Plugin) WheelAtBoundary(msg tea.MouseWheelMsg) bool {
 	if inTreePane {
 		surface = regionTreePane
 		bounds = sharedscroll.Bounds{Position: p.treeCursor, Maximum: p.tree.Len() - 1}
+	} else if p.showingStructured() {
+		// The structured view moves a cursor rather than a viewport and has
+		// no boundary worth reporting.
+		return false
+	} else if p.stream != nil {
+		maxScroll := p.streamMaxScroll()
+		if p.stream.indexing {
+			// The end is still moving; scrolling at it is not a boundary yet.
+			maxScroll = max(maxScroll, p.previewScroll+1)
+		}
+		bounds = sharedscroll.Bounds{Position: p.previewScroll, Maximum: maxScroll}
 	} else {
 		maxScroll := len(p.getPreviewLines()) - p.visibleContentHeight()
 		bounds = sharedscroll.Bounds{Position: p.previewScroll, Maximum: maxScroll}
@@ -108,9 +119,10 @@ const (
 	regionPreviewTab  = "preview-tab"  // Preview tab (Data: previewTabHit)
 
 	// File operation modal buttons
-	regionFileOpConfirm    = "file-op-confirm"    // Confirm/Create/Delete/Yes button
-	regionFileOpCancel     = "file-op-cancel"     // Cancel/No button
-	regionFileOpSuggestion = "file-op-suggestion" // Path suggestion item (Data: index)
+	regionFileOpConfirm     = "file-op-confirm"    // Confirm/Create/Delete/Yes button
+	regionFileOpCancel      = "file-op-cancel"     // Cancel/No button
+	regionFileOpSuggestion  = "file-op-suggestion" // Path suggestion item (Data: index)
+	regionFileOpRestoreItem = "file-op-restore"    // Trash restore list row (Data: index)
 )
 
 // handleMouse processes mouse events and dispatches to appropriate handlers.
@@ -122,7 +134,7 @@ func (p *Plugin) handleMouse(msg tea.MouseMsg) (*Plugin, tea.Cmd) {
 	}
 
 	// Handle inline edit mode - mouse events for editor and click-away detection
-	if p.edit.Active && p.edit.Model != nil && p.edit.Model.IsActive() {
+	if p.edit.Active && p.edit.IsModelActive() {
 		p.clearDragState()
 		action := p.mouseHandler.HandleMouse(msg)
 
@@ -257,8 +269,8 @@ func (p *Plugin) handleMouse(msg tea.MouseMsg) (*Plugin, tea.Cmd) {
 			return p, nil
 		}
 
-		// Forward other mouse events to tty model
-		cmd := p.edit.Model.Update(msg)
+		// Forward other mouse events to the editor
+		cmd, _ := p.edit.Route(msg)
 		return p, cmd
 	}
 
@@ -388,8 +400,18 @@ func (p *Plugin) handleMouseClick(action mouse.MouseAction) (*Plugin, tea.Cmd) {
 		if !ok {
 			return p, nil
 		}
-		p.treeCursor = idx
 		p.activePane = PaneTree
+		// Shift-click selects from the cursor to the clicked row; alt-click
+		// toggles the clicked row. Neither arms a drag.
+		if action.Shift && !p.searchMode {
+			p.extendSelectionTo(idx)
+			return p, p.loadPreviewForCursor()
+		}
+		if action.Alt && !p.searchMode {
+			p.toggleMarkAt(idx)
+			return p, nil
+		}
+		p.treeCursor = idx
 		p.ensureTreeCursorVisible()
 		// Arm (but do not start) a drag. Until the movement threshold is
 		// crossed in handleTreeItemDrag this stays a plain click, so click
@@ -441,6 +463,9 @@ func (p *Plugin) handleMouseClick(action mouse.MouseAction) (*Plugin, tea.Cmd) {
 
 	case regionFileOpConfirm:
 		// Click on confirm button in file op modal
+		if p.fileOpConfirmDelete {
+			return p, p.confirmDelete()
+		}
 		if p.fileOpMode != FileOpNone {
 			plug, cmd := p.executeFileOp()
 			return plug.(*Plugin), cmd
@@ -452,6 +477,8 @@ func (p *Plugin) handleMouseClick(action mouse.MouseAction) (*Plugin, tea.Cmd) {
 		if p.fileOpMode != FileOpNone {
 			p.fileOpMode = FileOpNone
 			p.fileOpTarget = nil
+			p.fileOpPaths = nil
+			p.renamePlan = nil
 			p.fileOpError = ""
 			p.fileOpShowSuggestions = false
 			p.fileOpConfirmDelete = false
@@ -460,6 +487,13 @@ func (p *Plugin) handleMouseClick(action mouse.MouseAction) (*Plugin, tea.Cmd) {
 		}
 		return p, nil
 
+	case regionFileOpRestoreItem:
+		// Click picks a trash entry; enter restores it
+		if idx, ok := action.Region.Data.(int); ok && idx >= 0 && idx < len(p.restoreItems) {
+			p.restoreCursor = idx
+		}
+		return p, nil
+
 	case regionFileOpSuggestion:
 		// Click on a path suggestion item
 		if idx, ok := action.Region.Data.(int); ok {
@@ -552,6 +586,14 @@ func (p *Plugin) handleMouseScroll(action mouse.MouseAction) (*Plugin, tea.Cmd)
 		return p, p.schedulePreviewForCursor()
 	}
 
+	if p.showingStructured() {
+		p.scrollStructured(delta)
+		return p, nil
+	}
+	if p.stream != nil {
+		return p, p.scrollStream(delta)
+	}
+
 	// Scroll preview pane
 	lines := p.getPreviewLines()
 	visibleHeight := p.visibleContentHeight()
@@ -15,6 +15,7 @@ import (
 	"github.com/marcus/sidecar/internal/markdown"
 	"github.com/marcus/sidecar/internal/msg"
 	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/trash"
 	"github.com/marcus/sidecar/internal/tty"
 )
 
@@ -206,6 +207,15 @@ func validateMove(srcRel string, srcIsDir bool, dstRel string) string {
 
 // executeFileOp performs the pending file operation.
 func (p *Plugin) executeFileOp() (plugin.Plugin, tea.Cmd) {
+	switch p.fileOpMode {
+	case FileOpBulkMove:
+		return p, p.executeBulkMove()
+	case FileOpBulkRename:
+		return p, p.executeBulkRename()
+	case FileOpRestore:
+		return p, p.handleRestoreKey("enter")
+	}
+
 	input := p.fileOpTextInput.Value()
 
 	// Handle create operations
@@ -409,29 +419,18 @@ func (p *Plugin) doDelete() tea.Cmd {
 			return FileOpErrorMsg{Err: fmt.Errorf("no target selected")}
 		}
 
-		fullPath := filepath.Join(p.ctx.WorkDir, p.fileOpTarget.Path)
-
-		// Validate path is within project (safety check)
-		absPath, err := filepath.Abs(fullPath)
+		// Validate path is within project and is not its root (safety check)
+		fullPath, err := deletablePath(p.ctx.WorkDir, p.fileOpTarget.Path)
 		if err != nil {
-			return FileOpErrorMsg{Err: fmt.Errorf("invalid path")}
-		}
-		absWorkDir, err := filepath.Abs(p.ctx.WorkDir)
-		if err != nil {
-			return FileOpErrorMsg{Err: fmt.Errorf("failed to resolve work directory")}
-		}
-		relPath, err := filepath.Rel(absWorkDir, absPath)
-		if err != nil || strings.HasPrefix(relPath, "..") {
-			return FileOpErrorMsg{Err: fmt.Errorf("cannot delete files outside project directory")}
+			return FileOpErrorMsg{Err: err}
 		}
 
-		// Don't allow deleting the project root
-		if relPath == "." {
-			return FileOpErrorMsg{Err: fmt.Errorf("cannot delete project root")}
+		// Move to the trash rather than removing, so the delete can be undone
+		tr, err := trash.Home()
+		if err != nil {
+			return FileOpErrorMsg{Err: err}
 		}
-
-		// Remove file or directory (recursively for directories)
-		if err := os.RemoveAll(fullPath); err != nil {
+		if _, err := tr.Put(fullPath); err != nil {
 			return FileOpErrorMsg{Err: err}
 		}
 
@@ -439,15 +438,14 @@ func (p *Plugin) doDelete() tea.Cmd {
 	}
 }
 
-// doPaste copies the clipboard file/directory to the target location.
+// doPaste copies the clipboard files/directories to the target location.
 func (p *Plugin) doPaste(targetNode *FileNode) tea.Cmd {
+	paths := p.clipboardPaths
 	return func() tea.Msg {
-		if p.clipboardPath == "" {
+		if len(paths) == 0 {
 			return FileOpErrorMsg{Err: fmt.Errorf("nothing to paste")}
 		}
 
-		srcPath := filepath.Join(p.ctx.WorkDir, p.clipboardPath)
-
 		// Determine destination directory
 		var destDir string
 		if targetNode.IsDir {
@@ -457,66 +455,79 @@ func (p *Plugin) doPaste(targetNode *FileNode) tea.Cmd {
 			destDir = filepath.Join(p.ctx.WorkDir, filepath.Dir(targetNode.Path))
 		}
 
-		// Check if source exists
-		srcInfo, err := os.Stat(srcPath)
-		if err != nil {
-			return FileOpErrorMsg{Err: fmt.Errorf("source not found: %s", filepath.Base(p.clipboardPath))}
+		var msg PasteSuccessMsg
+		for _, rel := range paths {
+			srcPath := filepath.Join(p.ctx.WorkDir, rel)
+			destPath, err := p.pasteOne(srcPath, destDir)
+			if err != nil {
+				return FileOpErrorMsg{Err: err}
+			}
+			msg = PasteSuccessMsg{Src: srcPath, Dst: destPath, Count: msg.Count + 1}
 		}
+		return msg
+	}
+}
 
-		// Generate destination path
-		srcName := filepath.Base(p.clipboardPath)
-		destPath := filepath.Join(destDir, srcName)
+// pasteOne copies srcPath into destDir, renaming the copy on a name clash.
+func (p *Plugin) pasteOne(srcPath, destDir string) (string, error) {
+	// Check if source exists
+	srcInfo, err := os.Stat(srcPath)
+	if err != nil {
+		return "", fmt.Errorf("source not found: %s", filepath.Base(srcPath))
+	}
 
-		// Handle name conflicts by appending _copy or _copy2, etc.
-		if _, err := os.Stat(destPath); err == nil {
-			base := srcName
-			ext := filepath.Ext(srcName)
-			if ext != "" {
-				base = srcName[:len(srcName)-len(ext)]
-			}
-			for i := 1; ; i++ {
-				suffix := "_copy"
-				if i > 1 {
-					suffix = fmt.Sprintf("_copy%d", i)
-				}
-				newName := base + suffix + ext
-				destPath = filepath.Join(destDir, newName)
-				if _, err := os.Stat(destPath); os.IsNotExist(err) {
-					break
-				}
-				if i > 100 {
-					return FileOpErrorMsg{Err: fmt.Errorf("too many copies")}
-				}
-			}
-		}
+	// Generate destination path
+	srcName := filepath.Base(srcPath)
+	destPath := filepath.Join(destDir, srcName)
 
-		// Validate destination is within project
-		absDestPath, err := filepath.Abs(destPath)
-		if err != nil {
-			return FileOpErrorMsg{Err: fmt.Errorf("invalid path")}
-		}
-		absWorkDir, err := filepath.Abs(p.ctx.WorkDir)
-		if err != nil {
-			return FileOpErrorMsg{Err: fmt.Errorf("failed to resolve work directory")}
-		}
-		relPath, err := filepath.Rel(absWorkDir, absDestPath)
-		if err != nil || strings.HasPrefix(relPath, "..") {
-			return FileOpErrorMsg{Err: fmt.Errorf("cannot paste outside project directory")}
+	// Handle name conflicts by appending _copy or _copy2, etc.
+	if _, err := os.Stat(destPath); err == nil {
+		base := srcName
+		ext := filepath.Ext(srcName)
+		if ext != "" {
+			base = srcName[:len(srcName)-len(ext)]
 		}
-
-		// Copy file or directory
-		if srcInfo.IsDir() {
-			if err := copyDir(srcPath, destPath); err != nil {
-				return FileOpErrorMsg{Err: err}
+		for i := 1; ; i++ {
+			suffix := "_copy"
+			if i > 1 {
+				suffix = fmt.Sprintf("_copy%d", i)
 			}
-		} else {
-			if err := copyFile(srcPath, destPath); err != nil {
-				return FileOpErrorMsg{Err: err}
+			newName := base + suffix + ext
+			destPath = filepath.Join(destDir, newName)
+			if _, err := os.Stat(destPath); os.IsNotExist(err) {
+				break
+			}
+			if i > 100 {
+				return "", fmt.Errorf("too many copies")
 			}
 		}
+	}
+
+	// Validate destination is within project
+	absDestPath, err := filepath.Abs(destPath)
+	if err != nil {
+		return "", fmt.Errorf("invalid path")
+	}
+	absWorkDir, err := filepath.Abs(p.ctx.WorkDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve work directory")
+	}
+	relPath, err := filepath.Rel(absWorkDir, absDestPath)
+	if err != nil || strings.HasPrefix(relPath, "..") {
+		return "", fmt.Errorf("cannot paste outside project directory")
+	}
 
-		return PasteSuccessMsg{Src: srcPath, Dst: destPath}
+	// Copy file or directory
+	if srcInfo.IsDir() {
+		if err := copyDir(srcPath, destPath); err != nil {
+			return "", err
+		}
+	} else {
+		if err := copyFile(srcPath, destPath); err != nil {
+			return "", err
+		}
 	}
+	return destPath, nil
 }
 
 // copyFile copies a single file.
@@ -722,7 +733,7 @@ func (p *Plugin) getPathSuggestions(query string) ([]string, tea.Cmd) {
 // updateFileOpSuggestions recomputes the move modal's path auto-complete from
 // the current directory cache, returning any scan command the filter needs.
 func (p *Plugin) updateFileOpSuggestions() tea.Cmd {
-	if p.fileOpMode != FileOpMove {
+	if !p.fileOpTakesPath() {
 		return nil
 	}
 
@@ -741,6 +752,12 @@ func (p *Plugin) updateFileOpSuggestions() tea.Cmd {
 	return cmd
 }
 
+// fileOpTakesPath reports whether the file-op input is a destination path,
+// which gets the suggestion dropdown.
+func (p *Plugin) fileOpTakesPath() bool {
+	return p.fileOpMode == FileOpMove || p.fileOpMode == FileOpBulkMove
+}
+
 // updateSearchMatches finds all files matching the search query using the quick
 // open cache, returning a command that rebuilds the cache when it is missing or
 // stale. Matches come from whatever the cache holds now; the scan result
@@ -1,6 +1,7 @@
 package filebrowser
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"time"
@@ -12,6 +13,7 @@ import (
 	"github.com/marcus/sidecar/internal/docview"
 	"github.com/marcus/sidecar/internal/features"
 	"github.com/marcus/sidecar/internal/filefind"
+	"github.com/marcus/sidecar/internal/gitstate"
 	"github.com/marcus/sidecar/internal/image"
 	"github.com/marcus/sidecar/internal/inlineedit"
 	"github.com/marcus/sidecar/internal/livewatch"
@@ -22,7 +24,9 @@ import (
 	"github.com/marcus/sidecar/internal/plugin"
 	"github.com/marcus/sidecar/internal/projectsearch"
 	"github.com/marcus/sidecar/internal/state"
+	"github.com/marcus/sidecar/internal/structview"
 	"github.com/marcus/sidecar/internal/tabs"
+	"github.com/marcus/sidecar/internal/trash"
 	"github.com/marcus/sidecar/internal/tty"
 	"github.com/marcus/sidecar/internal/ui"
 )
@@ -50,6 +54,9 @@ const (
 	FileOpCreateFile
 	FileOpCreateDir
 	FileOpDelete
+	FileOpBulkMove   // Move every selected node into one directory
+	FileOpBulkRename // Rename every selected node from a pattern
+	FileOpRestore    // Pick a trashed file to put back
 )
 
 // Message types
@@ -99,10 +106,22 @@ type (
 	DeleteSuccessMsg struct {
 		Path string
 	}
-	// PasteSuccessMsg is sent when a file/directory is pasted.
+	// PasteSuccessMsg is sent when a file/directory is pasted. Src and Dst
+	// name the last item when several were; Count says how many.
 	PasteSuccessMsg struct {
-		Src string
-		Dst string
+		Src   string
+		Dst   string
+		Count int
+	}
+	// BulkOpResultMsg reports an operation run over a multi-selection. Done
+	// counts the items handled before Err, if any, stopped the run.
+	BulkOpResultMsg struct {
+		Verb    string // Past tense, for the flash: "Moved", "Trashed"
+		Detail  string // Appended to the flash on success
+		Done    int
+		Total   int
+		Removed []string // Absolute paths that left the project, for tab cleanup
+		Err     error
 	}
 	// DragMoveResultMsg reports the outcome of a move started by a drag-drop.
 	// It is deliberately a distinct type from FileOpSuccessMsg/FileOpErrorMsg:
@@ -154,6 +173,7 @@ type Plugin struct {
 	activePane       FocusPane
 	treeVisible      bool // Toggle tree pane visibility with \
 	showIgnored      bool // Toggle git-ignored file visibility with H
+	changedOnly      bool // Show only entries with git changes, toggled with C
 	paneFocusManaged bool // Set once an outer deck composes Files' pane focus.
 	paneFocusActive  bool // Whether Files' inner active border should be drawn.
 
@@ -191,6 +211,23 @@ type Plugin struct {
 	markdownRenderMode bool               // true=rendered, false=raw
 	markdownRendered   []string           // Cached rendered lines
 
+	// Structured preview state (JSON/YAML tree, CSV/TSV table, SQLite browser)
+	structuredMode bool             // true=structured, false=text
+	structured     structview.Model // Model for the previewed file, nil when it has none
+	structuredErr  error            // Why the previewed file could not be structured
+
+	// Git status decorations for the tree (see git_status.go)
+	gitSnapshot      *gitstate.Snapshot // Latest status, nil outside a repository
+	gitStatusShared  bool               // The git plugin is broadcasting status for this project
+	gitStatusLoading bool               // A status load of our own is running
+	gitStatusDirty   bool               // The tree was rebuilt during that load
+
+	// Streaming preview state (files past filepreview.StreamThreshold)
+	stream              *streamPreview         // Streamed file, nil when the preview is loaded whole
+	streamGen           uint64                 // Bumped per opened stream so stale steps are dropped
+	streamWatcher       *livewatch.PathWatcher // Change signals for the streamed file, kept across files
+	streamWatchStarting bool                   // streamWatcher is being created
+
 	// Image preview state
 	imageRenderer *image.Renderer     // Terminal graphics renderer
 	isImage       bool                // True if current preview is an image
@@ -285,8 +322,16 @@ type Plugin struct {
 	fileOpShowSuggestions bool           // Show suggestions dropdown
 
 	// Clipboard state (yank/paste)
-	clipboardPath  string // Relative path of yanked file/directory
-	clipboardIsDir bool   // Whether yanked item is a directory
+	clipboardPaths []string // Relative paths of yanked files/directories
+
+	// Multi-select state (see selection.go). Marks are kept by path so a
+	// rebuild cannot shift them onto other rows.
+	marked        map[string]bool // Paths marked with space, V or shift-click
+	visualAnchor  string          // Row V was pressed on; "" when not in visual mode
+	fileOpPaths   []string        // The selection a bulk file operation acts on
+	renamePlan    []renameStep    // Batch rename preview for fileOpTextInput
+	restoreItems  []trash.Item    // Trashed files from this project, newest first
+	restoreCursor int             // Selected row in restoreItems
 
 	// File watcher
 	watcher *TreeWatcher
@@ -422,6 +467,14 @@ func (p *Plugin) Init(ctx *plugin.Context) error {
 	p.dirCache.Reset()
 	p.quickOpenMode = false
 	p.closeProjectSearch()
+	p.closeStream()
+	p.gitSnapshot = nil
+	p.gitStatusShared = false
+	p.gitStatusLoading = false
+	p.gitStatusDirty = false
+	p.changedOnly = false
+	p.clearSelection()
+	p.clipboardPaths = nil
 
 	// Initialize markdown renderer
 	renderer, err := markdown.NewRenderer()
@@ -456,6 +509,7 @@ func (p *Plugin) Stop() {
 		p.watcher.Stop()
 		p.watcher = nil
 	}
+	p.stopStreamWatcher()
 	// Kill any active inline edit sessions
 	p.cleanupAllEditSessions()
 	// Save state on shutdown
@@ -682,6 +736,8 @@ func (p *Plugin) refresh() tea.Cmd {
 		RootDir:       p.tree.RootDir,
 		SortMode:      p.tree.SortMode,
 		ShowIgnored:   p.tree.ShowIgnored,
+		ChangedOnly:   p.tree.ChangedOnly,
+		Status:        p.gitSnapshot,
 		ExpandedPaths: p.tree.GetExpandedPaths(),
 	}
 
@@ -720,10 +776,18 @@ func (p *Plugin) applyBuiltTree(tree *FileTree, cursorPath string) {
 			cursorPath = node.Path
 		}
 	}
+	if tree.Status != p.gitSnapshot {
+		// A newer status landed while the tree was being built.
+		tree.Status = p.gitSnapshot
+		if tree.ChangedOnly {
+			tree.Flatten()
+		}
+	}
 	p.tree = tree
 	p.reanchorTreeCursor(cursorPath)
 	p.reresolveFileOpTarget()
 	p.reanchorDragSource()
+	p.pruneSelection()
 }
 
 // reanchorDragSource re-checks an in-flight drag against the tree that just
@@ -840,8 +904,38 @@ func (p *Plugin) update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		return p, nil
 	}
 
+	// Handle the built-in editor - it lives in-process, so there is no session
+	// to poll or die; only keys, paste, wheel and clicks reach it.
+	if p.edit.Active && p.edit.Native != nil {
+		switch msg := msg.(type) {
+		case tea.WindowSizeMsg:
+			p.width = msg.Width
+			p.height = msg.Height
+			return p, nil
+
+		case tea.MouseWheelMsg:
+			cmd, _ := p.edit.Route(msg)
+			return p, cmd
+
+		case tea.MouseMsg:
+			return p.handleMouse(msg)
+
+		case tea.KeyPressMsg, tea.PasteMsg:
+			cmd, alive := p.edit.Route(msg)
+			if !alive {
+				editedFile := p.edit.Path
+				p.exitInlineEditMode()
+				if editedFile != "" {
+					return p, tea.Batch(cmd, LoadPreview(p.ctx.WorkDir, editedFile, p.ctx.Epoch))
+				}
+				return p, tea.Batch(cmd, p.refresh())
+			}
+			return p, cmd
+		}
+	}
+
 	// Handle inline edit mode - delegate most messages to tty model
-	if p.edit.Active && p.edit.Model != nil {
+	if p.edit.Active && p.edit.Native == nil && p.edit.Model != nil {
 		// Check if editor became inactive (vim exited normally)
 		// Also check if tmux session died (handles :wq case before SessionDeadMsg arrives)
 		if !p.edit.Model.IsActive() || !p.isInlineEditSessionAlive() {
@@ -938,11 +1032,14 @@ func (p *Plugin) update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		if msg.Gen != 0 && msg.Gen != p.treeBuildGen {
 			return p, nil
 		}
+		var statusCmd tea.Cmd
 		if msg.Err != nil {
 			p.ctx.Logger.Error("tree build failed", "error", msg.Err)
 		} else if msg.Tree != nil {
 			p.applyBuiltTree(msg.Tree, msg.CursorPath)
 			p.syncWatcherDirs()
+			// Whatever rebuilt the tree may have changed git status too.
+			statusCmd = p.loadGitStatus()
 		}
 		// Handle pending auto-open from file creation
 		if p.pendingOpenFile != "" {
@@ -952,14 +1049,17 @@ func (p *Plugin) update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 			// Restore state after first tree build
 			if !p.stateRestored {
 				p.stateRestored = true
-				return p, tea.Batch(navCmd, p.restoreState())
+				return p, tea.Batch(navCmd, p.restoreState(), statusCmd)
 			}
-			return p, navCmd
+			return p, tea.Batch(navCmd, statusCmd)
 		}
 		// Restore state after first tree build
 		if !p.stateRestored {
 			p.stateRestored = true
-			return p, p.restoreState()
+			return p, tea.Batch(p.restoreState(), statusCmd)
+		}
+		if statusCmd != nil {
+			return p, statusCmd
 		}
 
 	case StateRestoredMsg:
@@ -1045,7 +1145,7 @@ func (p *Plugin) update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 			// measures from here so the next watcher signal is not reported as
 			// a change.
 			p.adoptPreviewFingerprint(msg.Result)
-			p.applyPreviewResult(msg.Result)
+			structuredCmd := p.applyPreviewResult(msg.Result)
 			p.updateActiveTabResult(msg.Result)
 			p.clampPreviewScroll()
 			if p.pendingNavigateLine > 0 && p.pendingNavigatePath == msg.Path &&
@@ -1069,6 +1169,46 @@ func (p *Plugin) update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 					p.scrollToNearestMatch(targetScroll)
 				}
 			}
+			return p, structuredCmd
+		}
+
+	case gitstate.SnapshotMsg:
+		p.handleSharedGitStatus(msg)
+		return p, nil
+
+	case gitStatusLoadedMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		return p, p.handleGitStatusLoaded(msg)
+
+	case streamIndexedMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		return p, p.handleStreamIndexed(msg)
+
+	case streamWindowMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		return p, p.handleStreamWindow(msg)
+
+	case streamSearchedMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		return p, p.handleStreamSearched(msg)
+
+	case streamWatchStartedMsg:
+		return p, p.handleStreamWatchStarted(msg)
+
+	case streamChangedMsg:
+		return p, p.handleStreamChanged()
+
+	case structview.SQLiteMsg:
+		if p.structured != nil {
+			return p, p.structured.Update(msg)
 		}
 
 	case app.RefreshMsg:
@@ -1182,8 +1322,23 @@ func (p *Plugin) update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 
 	case PasteSuccessMsg:
 		// Refresh after paste
+		if msg.Count > 1 {
+			return p, tea.Batch(p.refresh(), appmsg.ShowFlash(fmt.Sprintf("Pasted %d items", msg.Count)))
+		}
 		return p, p.refresh()
 
+	case BulkOpResultMsg:
+		return p, p.handleBulkOpResult(msg)
+
+	case trashListedMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		return p, p.handleTrashListed(msg)
+
+	case restoredMsg:
+		return p, p.handleRestored(msg)
+
 	case GitInfoMsg:
 		p.gitStatus = msg.Status
 		p.gitLastCommit = msg.LastCommit
@@ -1299,11 +1454,16 @@ func (p *Plugin) Commands() []plugin.Command {
 		{ID: "edit", Name: "Edit", Description: "Edit file inline", Category: plugin.CategoryActions, Context: "file-browser-tree", Priority: 2},
 		{ID: "edit-external", Name: "Edit+", Description: "Edit in full terminal", Category: plugin.CategoryActions, Context: "file-browser-tree", Priority: 2},
 		{ID: "blame", Name: "Blame", Description: "Show git blame", Category: plugin.CategoryView, Context: "file-browser-tree", Priority: 3},
+		{ID: "bookmark", Name: "Mark", Description: "Bookmark this file", Category: plugin.CategoryActions, Context: "file-browser-tree", Priority: 5},
 		{ID: "search", Name: "Filter", Description: "Filter files by name", Category: plugin.CategorySearch, Context: "file-browser-tree", Priority: 3},
 		{ID: "close-tab", Name: "Close", Description: "Close active tab", Category: plugin.CategoryActions, Context: "file-browser-tree", Priority: 4},
 		{ID: "create-file", Name: "New", Description: "Create new file", Category: plugin.CategoryActions, Context: "file-browser-tree", Priority: 4},
 		{ID: "create-dir", Name: "Mkdir", Description: "Create new directory", Category: plugin.CategoryActions, Context: "file-browser-tree", Priority: 4},
-		{ID: "delete", Name: "Delete", Description: "Delete file or directory", Category: plugin.CategoryActions, Context: "file-browser-tree", Priority: 4},
+		{ID: "delete", Name: "Delete", Description: "Move file or directory to trash", Category: plugin.CategoryActions, Context: "file-browser-tree", Priority: 4},
+		{ID: "toggle-select", Name: "Select", Description: "Select file for a bulk operation", Category: plugin.CategoryActions, Context: "file-browser-tree", Priority: 5},
+		{ID: "visual-select", Name: "Range", Description: "Select a range of files", Category: plugin.CategoryActions, Context: "file-browser-tree", Priority: 6},
+		{ID: "stage", Name: "Stage", Description: "Stage file or selection in git", Category: plugin.CategoryActions, Context: "file-browser-tree", Priority: 7},
+		{ID: "restore-trash", Name: "Restore", Description: "Restore a file from the trash", Category: plugin.CategoryActions, Context: "file-browser-tree", Priority: 8},
 		{ID: "prev-tab", Name: "Tab←", Description: "Previous tab", Category: plugin.CategoryNavigation, Context: "file-browser-tree", Priority: 5},
 		{ID: "next-tab", Name: "Tab→", Description: "Next tab", Category: plugin.CategoryNavigation, Context: "file-browser-tree", Priority: 5},
 		{ID: "yank", Name: "Yank", Description: "Mark file for copy (use p to paste)", Category: plugin.CategoryActions, Context: "file-browser-tree", Priority: 5},
@@ -1316,6 +1476,7 @@ func (p *Plugin) Commands() []plugin.Command {
 		{ID: "reveal", Name: "Reveal", Description: "Reveal in file manager", Category: plugin.CategoryActions, Context: "file-browser-tree", Priority: 8},
 		{ID: "toggle-sidebar", Name: "Sidebar", Description: "Toggle tree pane visibility", Category: plugin.CategoryView, Context: "file-browser-tree", Priority: 9},
 		{ID: "toggle-ignored", Name: "Ignored", Description: "Toggle git-ignored file visibility", Category: plugin.CategoryView, Context: "file-browser-tree", Priority: 9},
+		{ID: "toggle-changed", Name: "Changed", Description: "Show only files with git changes", Category: plugin.CategoryView, Context: "file-browser-tree", Priority: 9},
 		// Preview pane commands
 		{ID: "quick-open", Name: "Find", Description: "Find a file by name", Category: plugin.CategorySearch, Context: "file-browser-preview", Priority: 1},
 		{ID: "project-search", Name: "Search", Description: "Search the project's contents", Category: plugin.CategorySearch, Context: "file-browser-preview", Priority: 2},
@@ -1325,10 +1486,13 @@ func (p *Plugin) Commands() []plugin.Command {
 		{ID: "prev-tab", Name: "Tab←", Description: "Previous tab", Category: plugin.CategoryNavigation, Context: "file-browser-preview", Priority: 3},
 		{ID: "next-tab", Name: "Tab→", Description: "Next tab", Category: plugin.CategoryNavigation, Context: "file-browser-preview", Priority: 3},
 		{ID: "blame", Name: "Blame", Description: "Show git blame", Category: plugin.CategoryView, Context: "file-browser-preview", Priority: 3},
+		{ID: "bookmark", Name: "Mark", Description: "Bookmark this file at the top visible line", Category: plugin.CategoryActions, Context: "file-browser-preview", Priority: 5},
 		{ID: "search-content", Name: "InFile", Description: "Search this file's contents", Category: plugin.CategorySearch, Context: "file-browser-preview", Priority: 3},
 		{ID: "toggle-wrap", Name: "Wrap", Description: "Toggle line wrapping", Category: plugin.CategoryView, Context: "file-browser-preview", Priority: 3},
 		{ID: "toggle-markdown", Name: "Render", Description: "Toggle markdown rendering", Category: plugin.CategoryActions, Context: "file-browser-preview", Priority: 4},
+		{ID: "toggle-structured", Name: "Struct", Description: "Toggle structured view of JSON, YAML, CSV and TSV", Category: plugin.CategoryView, Context: "file-browser-preview", Priority: 4},
 		{ID: "close-tab", Name: "Close", Description: "Close active tab", Category: plugin.CategoryActions, Context: "file-browser-preview", Priority: 4},
+		{ID: "toggle-follow", Name: "Follow", Description: "Follow a large file as it grows", Category: plugin.CategoryView, Context: "file-browser-preview", Priority: 6},
 		{ID: "back", Name: "Back", Description: "Return to file tree", Category: plugin.CategoryNavigation, Context: "file-browser-preview", Priority: 5},
 		{ID: "refresh", Name: "Refresh", Description: "Refresh file tree", Category: plugin.CategoryActions, Context: "file-browser-preview", Priority: 5},
 		{ID: "rename", Name: "Rename", Description: "Rename file", Category: plugin.CategoryActions, Context: "file-browser-preview", Priority: 6},
@@ -1337,6 +1501,15 @@ func (p *Plugin) Commands() []plugin.Command {
 		{ID: "yank-path", Name: "Path", Description: "Copy file path", Category: plugin.CategoryActions, Context: "file-browser-preview", Priority: 8},
 		{ID: "toggle-sidebar", Name: "Sidebar", Description: "Toggle tree pane visibility", Category: plugin.CategoryView, Context: "file-browser-preview", Priority: 9},
 		{ID: "toggle-ignored", Name: "Ignored", Description: "Toggle git-ignored file visibility", Category: plugin.CategoryView, Context: "file-browser-preview", Priority: 9},
+		// Structured preview commands
+		{ID: "toggle-node", Name: "Fold", Description: "Expand or collapse the selected node", Category: plugin.CategoryNavigation, Context: "file-browser-structured", Priority: 1},
+		{ID: "copy-node", Name: "Copy", Description: "Copy the selected node's path or cell", Category: plugin.CategoryActions, Context: "file-browser-structured", Priority: 2},
+		{ID: "sort-column", Name: "Sort", Description: "Sort the table by the selected column", Category: plugin.CategoryActions, Context: "file-browser-structured", Priority: 2},
+		{ID: "next-page", Name: "Page→", Description: "Next page of database rows", Category: plugin.CategoryNavigation, Context: "file-browser-structured", Priority: 3},
+		{ID: "prev-page", Name: "Page←", Description: "Previous page of database rows", Category: plugin.CategoryNavigation, Context: "file-browser-structured", Priority: 3},
+		{ID: "toggle-structured", Name: "Text", Description: "Show the file as text", Category: plugin.CategoryView, Context: "file-browser-structured", Priority: 4},
+		{ID: "switch-pane", Name: "Tree", Description: "Switch to the file tree", Category: plugin.CategoryNavigation, Context: "file-browser-structured", Priority: 5},
+		{ID: "back", Name: "Back", Description: "Return to file tree", Category: plugin.CategoryNavigation, Context: "file-browser-structured", Priority: 5},
 		// Tree search commands
 		{ID: "confirm", Name: "Go", Description: "Jump to match", Category: plugin.CategoryNavigation, Context: "file-browser-search", Priority: 1},
 		{ID: "cancel", Name: "Cancel", Description: "Cancel search", Category: plugin.CategoryActions, Context: "file-browser-search", Priority: 1},
@@ -1395,6 +1568,9 @@ func (p *Plugin) FocusContext() string {
 		return "file-browser-search"
 	}
 	if p.activePane == PanePreview {
+		if p.showingStructured() {
+			return "file-browser-structured"
+		}
 		return "file-browser-preview"
 	}
 	return "file-browser-tree"
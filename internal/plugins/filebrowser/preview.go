@@ -13,8 +13,10 @@ import (
 type PreviewResult = filepreview.PreviewResult
 type PreviewLoadedMsg = filepreview.PreviewLoadedMsg
 
+// LoadPreview loads a file for the preview pane. Files too large to load whole
+// come back marked IsLarge, and the pane streams them.
 func LoadPreview(rootDir, path string, epoch uint64) tea.Cmd {
-	return filepreview.LoadPreview(rootDir, path, epoch)
+	return filepreview.LoadStreamable(rootDir, path, epoch)
 }
 
 func LoadPreviewFile(file *os.File, path string, epoch uint64) tea.Cmd {
@@ -0,0 +1,794 @@
+package filebrowser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+
+	appmsg "github.com/marcus/sidecar/internal/msg"
+	"github.com/marcus/sidecar/internal/trash"
+)
+
+// Multi-select. Space toggles the cursor row, V starts a range that follows
+// the cursor until V or esc folds it into the marks, and shift-click marks
+// everything between the cursor and the clicked row. With a selection, the
+// tree's file operation keys (D, m, R, y, S) act on every selected node
+// instead of the cursor's.
+
+const (
+	// renamePreviewMax caps the rename preview rows under the file-op bar.
+	renamePreviewMax = 8
+	// restoreListMax caps the visible rows of the trash restore list.
+	restoreListMax = 10
+)
+
+// hasSelection reports whether a bulk selection is active.
+func (p *Plugin) hasSelection() bool {
+	return len(p.marked) > 0 || p.visualAnchor != ""
+}
+
+// clearSelection drops every mark and leaves visual mode.
+func (p *Plugin) clearSelection() {
+	p.marked = nil
+	p.visualAnchor = ""
+}
+
+// visualRange returns the flat-list rows the V range covers. An anchor the
+// tree no longer shows (collapsed away, filtered out) ranges from the cursor.
+func (p *Plugin) visualRange() (lo, hi int, ok bool) {
+	if p.visualAnchor == "" || p.tree == nil {
+		return 0, 0, false
+	}
+	anchor := p.tree.IndexOfPath(p.visualAnchor)
+	if anchor < 0 {
+		anchor = p.treeCursor
+	}
+	return min(anchor, p.treeCursor), max(anchor, p.treeCursor), true
+}
+
+// rowSelected reports whether the node on flat row idx is selected.
+func (p *Plugin) rowSelected(node *FileNode, idx, lo, hi int, visual bool) bool {
+	if node == nil || (p.tree != nil && node == p.tree.Root) {
+		return false
+	}
+	return p.marked[node.Path] || (visual && idx >= lo && idx <= hi)
+}
+
+// selectedPaths returns the selection in tree order, then any marks the tree
+// is not showing. A node inside a selected directory is dropped: the
+// directory carries it, and acting on both would act on it twice.
+func (p *Plugin) selectedPaths() []string {
+	if p.tree == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var paths []string
+	lo, hi, visual := p.visualRange()
+	for i, node := range p.tree.FlatList {
+		if p.rowSelected(node, i, lo, hi, visual) && !seen[node.Path] {
+			seen[node.Path] = true
+			paths = append(paths, node.Path)
+		}
+	}
+	var hidden []string
+	for path := range p.marked {
+		if !seen[path] {
+			hidden = append(hidden, path)
+		}
+	}
+	sort.Strings(hidden)
+	paths = append(paths, hidden...)
+
+	selected := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		selected[path] = true
+	}
+	kept := paths[:0]
+	for _, path := range paths {
+		nested := false
+		for dir := parentDirPath(path); dir != ""; dir = parentDirPath(dir) {
+			if selected[dir] {
+				nested = true
+				break
+			}
+		}
+		if !nested {
+			kept = append(kept, path)
+		}
+	}
+	return kept
+}
+
+// toggleMark flips the cursor row's mark and steps down, so holding space
+// sweeps a run of files.
+func (p *Plugin) toggleMark() tea.Cmd {
+	node := p.tree.GetNode(p.treeCursor)
+	if node == nil || node == p.tree.Root {
+		return nil
+	}
+	if p.marked[node.Path] {
+		delete(p.marked, node.Path)
+	} else {
+		p.setMark(node.Path)
+	}
+	if p.treeCursor < p.tree.Len()-1 {
+		p.treeCursor++
+		p.ensureTreeCursorVisible()
+		return p.schedulePreviewForCursor()
+	}
+	return nil
+}
+
+func (p *Plugin) setMark(path string) {
+	if p.marked == nil {
+		p.marked = make(map[string]bool)
+	}
+	p.marked[path] = true
+}
+
+// toggleVisual starts a range at the cursor, or folds the current range into
+// the marks.
+func (p *Plugin) toggleVisual() {
+	if p.visualAnchor != "" {
+		p.foldVisualRange()
+		return
+	}
+	if node := p.tree.GetNode(p.treeCursor); node != nil && node != p.tree.Root {
+		p.visualAnchor = node.Path
+	}
+}
+
+func (p *Plugin) foldVisualRange() {
+	lo, hi, ok := p.visualRange()
+	p.visualAnchor = ""
+	if !ok {
+		return
+	}
+	p.markRows(lo, hi)
+}
+
+func (p *Plugin) markRows(lo, hi int) {
+	for i := lo; i <= hi; i++ {
+		if node := p.tree.GetNode(i); node != nil && node != p.tree.Root {
+			p.setMark(node.Path)
+		}
+	}
+}
+
+// extendSelectionTo marks every row from the cursor to idx (shift-click) and
+// moves the cursor there.
+func (p *Plugin) extendSelectionTo(idx int) {
+	if p.tree == nil || idx < 0 || idx >= p.tree.Len() {
+		return
+	}
+	p.markRows(min(p.treeCursor, idx), max(p.treeCursor, idx))
+	p.treeCursor = idx
+	p.ensureTreeCursorVisible()
+}
+
+// toggleMarkAt flips the mark on row idx (alt-click).
+func (p *Plugin) toggleMarkAt(idx int) {
+	node := p.tree.GetNode(idx)
+	if node == nil || node == p.tree.Root {
+		return
+	}
+	if p.marked[node.Path] {
+		delete(p.marked, node.Path)
+		return
+	}
+	p.setMark(node.Path)
+}
+
+// pruneSelection forgets marks on paths that no longer exist, after a rebuild
+// has caught up with a move, delete or checkout.
+func (p *Plugin) pruneSelection() {
+	if p.ctx == nil {
+		return
+	}
+	for path := range p.marked {
+		if _, err := os.Lstat(filepath.Join(p.ctx.WorkDir, path)); err != nil {
+			delete(p.marked, path)
+		}
+	}
+	if p.visualAnchor != "" {
+		if _, err := os.Lstat(filepath.Join(p.ctx.WorkDir, p.visualAnchor)); err != nil {
+			p.visualAnchor = ""
+		}
+	}
+}
+
+// beginBulkOp opens the file-op bar in mode over the current selection.
+func (p *Plugin) beginBulkOp(mode FileOpMode) bool {
+	paths := p.selectedPaths()
+	if len(paths) == 0 {
+		return false
+	}
+	p.fileOpMode = mode
+	p.fileOpPaths = paths
+	p.fileOpTarget = nil
+	p.fileOpError = ""
+	p.fileOpButtonFocus = 0
+	p.fileOpShowSuggestions = false
+	return true
+}
+
+// startBulkDelete asks to move the selection to the trash.
+func (p *Plugin) startBulkDelete() {
+	if p.beginBulkOp(FileOpDelete) {
+		p.fileOpConfirmDelete = true
+		p.fileOpButtonFocus = 1 // Start with confirm button focused
+	}
+}
+
+// startBulkMove asks for the directory to move the selection into.
+func (p *Plugin) startBulkMove() {
+	if !p.beginBulkOp(FileOpBulkMove) {
+		return
+	}
+	p.fileOpTextInput = textinput.New()
+	p.fileOpTextInput.Placeholder = "directory (. for the project root)"
+	if dir := parentDirPath(p.fileOpPaths[0]); dir != "" {
+		p.fileOpTextInput.SetValue(dir + "/")
+	}
+	p.fileOpTextInput.Focus()
+	p.fileOpTextInput.CursorEnd()
+}
+
+// startBulkRename asks for the pattern to rename the selection with.
+func (p *Plugin) startBulkRename() {
+	if !p.beginBulkOp(FileOpBulkRename) {
+		return
+	}
+	p.fileOpTextInput = textinput.New()
+	p.fileOpTextInput.Placeholder = "{name}-{n}{ext} or s/old/new/"
+	p.fileOpTextInput.SetValue("{name}{ext}")
+	p.fileOpTextInput.Focus()
+	p.fileOpTextInput.CursorEnd()
+	// The starting pattern renames nothing; that is not an error yet.
+	p.renamePlan = nil
+}
+
+// yankSelection puts the selection on the copy clipboard.
+func (p *Plugin) yankSelection() tea.Cmd {
+	paths := p.selectedPaths()
+	if len(paths) == 0 {
+		return nil
+	}
+	p.clipboardPaths = paths
+	p.clearSelection()
+	return appmsg.ShowFlash(fmt.Sprintf("Marked %s for copy", countNoun(len(paths))))
+}
+
+// deletablePath resolves rel inside workDir, refusing the project root and
+// anything that escapes it.
+func deletablePath(workDir, rel string) (string, error) {
+	fullPath := filepath.Join(workDir, rel)
+	absPath, err := filepath.Abs(fullPath)
+	if err != nil {
+		return "", fmt.Errorf("invalid path")
+	}
+	absWorkDir, err := filepath.Abs(workDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve work directory")
+	}
+	relPath, err := filepath.Rel(absWorkDir, absPath)
+	if err != nil || strings.HasPrefix(relPath, "..") {
+		return "", fmt.Errorf("cannot delete files outside project directory")
+	}
+	if relPath == "." {
+		return "", fmt.Errorf("cannot delete project root")
+	}
+	return fullPath, nil
+}
+
+// doTrash moves every path to the XDG trash.
+func (p *Plugin) doTrash(paths []string) tea.Cmd {
+	workDir := p.ctx.WorkDir
+	return func() tea.Msg {
+		res := BulkOpResultMsg{Verb: "Trashed", Detail: " (U to restore)", Total: len(paths)}
+		tr, err := trash.Home()
+		if err != nil {
+			res.Err = err
+			return res
+		}
+		for _, rel := range paths {
+			full, err := deletablePath(workDir, rel)
+			if err == nil {
+				_, err = tr.Put(full)
+			}
+			if err != nil {
+				res.Err = err
+				return res
+			}
+			res.Removed = append(res.Removed, full)
+			res.Done++
+		}
+		return res
+	}
+}
+
+// executeBulkMove validates the destination typed for a bulk move and moves
+// the selection into it.
+func (p *Plugin) executeBulkMove() tea.Cmd {
+	input := strings.TrimSpace(p.fileOpTextInput.Value())
+	if input == "" {
+		p.fileOpError = "enter a directory (. for the project root)"
+		return nil
+	}
+	if filepath.IsAbs(input) {
+		p.fileOpError = "absolute paths not allowed"
+		return nil
+	}
+	dir := filepath.Clean(input)
+	if dir == "." {
+		dir = ""
+	}
+	dstDir := filepath.Join(p.ctx.WorkDir, dir)
+	if err := p.validateDestPath(dstDir); err != nil {
+		p.fileOpError = err.Error()
+		return nil
+	}
+	for _, src := range p.fileOpPaths {
+		info, err := os.Lstat(filepath.Join(p.ctx.WorkDir, src))
+		if err != nil {
+			p.fileOpError = "source not found: " + src
+			return nil
+		}
+		if reason := validateMove(src, info.IsDir(), filepath.Join(dir, filepath.Base(src))); reason != "" {
+			p.fileOpError = reason
+			return nil
+		}
+	}
+	info, err := os.Stat(dstDir)
+	if os.IsNotExist(err) {
+		// Same confirmation the single move asks for.
+		p.fileOpConfirmCreate = true
+		p.fileOpConfirmPath = dstDir
+		return nil
+	}
+	if err == nil && !info.IsDir() {
+		p.fileOpError = dir + " is not a directory"
+		return nil
+	}
+	return p.doBulkMove(p.fileOpPaths, dir)
+}
+
+// doBulkMove moves each path into dir (root-relative). It stops at the first
+// failure, leaving the moves already made in place.
+func (p *Plugin) doBulkMove(paths []string, dir string) tea.Cmd {
+	workDir := p.ctx.WorkDir
+	return func() tea.Msg {
+		res := BulkOpResultMsg{Verb: "Moved", Detail: " → " + displayDropDir(dir), Total: len(paths)}
+		for _, rel := range paths {
+			src := filepath.Join(workDir, rel)
+			dst := filepath.Join(workDir, dir, filepath.Base(rel))
+			if _, err := os.Lstat(dst); err == nil {
+				res.Err = fmt.Errorf("destination already exists: %s", filepath.Base(dst))
+				return res
+			}
+			if err := os.Rename(src, dst); err != nil {
+				res.Err = err
+				return res
+			}
+			res.Done++
+		}
+		return res
+	}
+}
+
+// stagePaths runs `git add` for paths, or for the cursor row when nothing is
+// selected.
+func (p *Plugin) stagePaths() tea.Cmd {
+	paths := p.selectedPaths()
+	if len(paths) == 0 {
+		node := p.tree.GetNode(p.treeCursor)
+		if node == nil || node == p.tree.Root {
+			return nil
+		}
+		paths = []string{node.Path}
+	}
+	workDir := p.ctx.WorkDir
+	args := []string{"add", "-A"}
+	if p.gitSnapshot != nil && p.gitSnapshot.Sparse != nil {
+		// Files created outside the sparse checkout are refused without it.
+		args = append(args, "--sparse")
+	}
+	args = append(args, "--")
+	return func() tea.Msg {
+		res := BulkOpResultMsg{Verb: "Staged", Total: len(paths)}
+		ctx, cancel := context.WithTimeout(context.Background(), gitStatusTimeout)
+		defer cancel()
+		cmd := exec.CommandContext(ctx, "git", append(args, paths...)...)
+		cmd.Dir = workDir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			if msg := strings.TrimSpace(string(out)); msg != "" {
+				err = errors.New(firstLine(msg))
+			}
+			res.Err = err
+			return res
+		}
+		res.Done = len(paths)
+		return res
+	}
+}
+
+// handleBulkOpResult closes the bulk operation and reports how it went.
+func (p *Plugin) handleBulkOpResult(msg BulkOpResultMsg) tea.Cmd {
+	p.fileOpMode = FileOpNone
+	p.fileOpPaths = nil
+	p.fileOpTarget = nil
+	p.fileOpError = ""
+	p.fileOpConfirmDelete = false
+	p.renamePlan = nil
+
+	cmds := []tea.Cmd{p.refresh()}
+	for _, path := range msg.Removed {
+		cmds = append(cmds, p.closeTabsForPath(path))
+	}
+	if msg.Err != nil {
+		text := msg.Verb + " failed: " + msg.Err.Error()
+		if msg.Done > 0 {
+			text = fmt.Sprintf("%s %d of %d, then: %s", msg.Verb, msg.Done, msg.Total, msg.Err.Error())
+		}
+		cmds = append(cmds, appmsg.ShowToast(text, 3*time.Second))
+		return tea.Batch(cmds...)
+	}
+	p.clearSelection()
+	cmds = append(cmds, appmsg.ShowFlash(msg.Verb+" "+countNoun(msg.Done)+msg.Detail))
+	return tea.Batch(cmds...)
+}
+
+// renameStep is one rename in a batch, both paths root-relative.
+type renameStep struct {
+	From string
+	To   string
+}
+
+// renameTokenRe matches a batch rename template's placeholders.
+var renameTokenRe = regexp.MustCompile(`\{(name|ext|n)(?::(\d+))?\}`)
+
+// renamer turns a batch rename pattern into a function from a base name and
+// its 1-based position to the new name. A pattern is either a template with
+// {name} (the name without its extension), {ext} (the extension, dot
+// included) and {n} ({n:3} zero-pads to three digits), or a sed-style
+// s/regexp/replacement/ applied to the whole name, with a trailing g to
+// replace every match and \1 or $1 for groups.
+func renamer(pattern string) (func(name string, n int) string, error) {
+	if strings.HasPrefix(pattern, "s/") {
+		expr, repl, global, err := splitSubstitution(pattern[2:])
+		if err != nil {
+			return nil, err
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("bad regexp: %v", err)
+		}
+		return func(name string, _ int) string {
+			if global {
+				return re.ReplaceAllString(name, repl)
+			}
+			loc := re.FindStringSubmatchIndex(name)
+			if loc == nil {
+				return name
+			}
+			return name[:loc[0]] + string(re.ExpandString(nil, repl, name, loc)) + name[loc[1]:]
+		}, nil
+	}
+	return func(name string, n int) string {
+		ext := filepath.Ext(name)
+		if ext == name {
+			ext = "" // A dotfile's name is all stem.
+		}
+		return renameTokenRe.ReplaceAllStringFunc(pattern, func(tok string) string {
+			m := renameTokenRe.FindStringSubmatch(tok)
+			switch m[1] {
+			case "name":
+				return strings.TrimSuffix(name, ext)
+			case "ext":
+				return ext
+			}
+			width, _ := strconv.Atoi(m[2])
+			return fmt.Sprintf("%0*d", width, n)
+		})
+	}, nil
+}
+
+// backrefRe matches sed's \1..\9 group references.
+var backrefRe = regexp.MustCompile(`\\([1-9])`)
+
+// splitSubstitution splits the body of s/expr/repl/flags on unescaped
+// slashes.
+func splitSubstitution(body string) (expr, repl string, global bool, err error) {
+	var parts []string
+	var cur strings.Builder
+	for i := 0; i < len(body); i++ {
+		switch {
+		case body[i] == '\\' && i+1 < len(body) && body[i+1] == '/':
+			cur.WriteByte('/')
+			i++
+		case body[i] == '/':
+			parts = append(parts, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteByte(body[i])
+		}
+	}
+	parts = append(parts, cur.String())
+	if len(parts) != 3 || (parts[2] != "" && parts[2] != "g") {
+		return "", "", false, fmt.Errorf("use s/regexp/replacement/ or s/regexp/replacement/g")
+	}
+	repl = backrefRe.ReplaceAllString(parts[1], "$${$1}")
+	return parts[0], repl, parts[2] == "g", nil
+}
+
+// planRename computes the renames pattern makes to paths. Names the pattern
+// leaves alone are skipped. The plan is returned even when err is set, so
+// the preview can show what the pattern would do.
+func planRename(workDir string, paths []string, pattern string) ([]renameStep, error) {
+	rename, err := renamer(pattern)
+	if err != nil {
+		return nil, err
+	}
+	var steps []renameStep
+	for i, path := range paths {
+		name := filepath.Base(path)
+		if newName := rename(name, i+1); newName != name {
+			steps = append(steps, renameStep{From: path, To: filepath.Join(parentDirPath(path), newName)})
+		}
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("the pattern changes no names")
+	}
+
+	// A target may be another file of the batch only if that file is itself
+	// moving out of the way.
+	moving := make(map[string]bool, len(steps))
+	for _, st := range steps {
+		moving[st.From] = true
+	}
+	targets := make(map[string]string, len(steps))
+	for _, st := range steps {
+		name, newName := filepath.Base(st.From), filepath.Base(st.To)
+		if strings.ContainsAny(newName, `/\`) {
+			return steps, fmt.Errorf("%s: a new name cannot contain a path separator", name)
+		}
+		if err := validateFilename(newName); err != nil {
+			return steps, fmt.Errorf("%s: %v", name, err)
+		}
+		if prev, ok := targets[st.To]; ok {
+			return steps, fmt.Errorf("%s and %s would both be named %s", filepath.Base(prev), name, newName)
+		}
+		targets[st.To] = st.From
+		if _, err := os.Lstat(filepath.Join(workDir, st.To)); err == nil && !moving[st.To] && !isCaseOnlyRename(st.From, st.To) {
+			return steps, fmt.Errorf("%s already exists", st.To)
+		}
+	}
+	return steps, nil
+}
+
+// updateRenamePlan recomputes the batch rename preview from the input.
+func (p *Plugin) updateRenamePlan() {
+	p.renamePlan = nil
+	p.fileOpError = ""
+	steps, err := planRename(p.ctx.WorkDir, p.fileOpPaths, p.fileOpTextInput.Value())
+	p.renamePlan = steps
+	if err != nil {
+		p.fileOpError = err.Error()
+	}
+}
+
+// executeBulkRename runs the previewed batch rename.
+func (p *Plugin) executeBulkRename() tea.Cmd {
+	p.updateRenamePlan()
+	if p.fileOpError != "" {
+		return nil
+	}
+	return doBulkRename(p.ctx.WorkDir, p.renamePlan)
+}
+
+// doBulkRename renames in two passes, every source to a temporary name first,
+// so a pattern that swaps or shifts names (a→b, b→c) never lands one file on
+// another that has not moved yet. Neither pass nor the rollback overwrites a
+// path that exists, so a file created after the plan was made is left alone.
+func doBulkRename(workDir string, steps []renameStep) tea.Cmd {
+	return func() tea.Msg {
+		res := BulkOpResultMsg{Verb: "Renamed", Total: len(steps)}
+		tmps := make([]string, len(steps))
+		for i, st := range steps {
+			tmps[i] = filepath.Join(workDir, st.From) + fmt.Sprintf(".sidecar-rename-%d", i)
+			if err := renameNoReplace(filepath.Join(workDir, st.From), tmps[i]); err != nil {
+				for j := i - 1; j >= 0; j-- {
+					_ = renameNoReplace(tmps[j], filepath.Join(workDir, steps[j].From))
+				}
+				res.Err = err
+				return res
+			}
+		}
+		for i, st := range steps {
+			if err := renameNoReplace(tmps[i], filepath.Join(workDir, st.To)); err != nil {
+				// Back out the steps that landed, newest first, so every
+				// source path is free again before the temporaries go home.
+				for j := i - 1; j >= 0; j-- {
+					_ = renameNoReplace(filepath.Join(workDir, steps[j].To), tmps[j])
+				}
+				for j := range steps {
+					_ = renameNoReplace(tmps[j], filepath.Join(workDir, steps[j].From))
+				}
+				res.Err = err
+				return res
+			}
+			res.Done++
+		}
+		return res
+	}
+}
+
+// renameNoReplace renames from to to, refusing when to already exists.
+func renameNoReplace(from, to string) error {
+	if _, err := os.Lstat(to); err == nil {
+		return &os.LinkError{Op: "rename", Old: from, New: to, Err: fs.ErrExist}
+	}
+	return os.Rename(from, to)
+}
+
+// fileOpListRows is the number of rows drawn under the file-op bar for the
+// rename preview or the restore list.
+func (p *Plugin) fileOpListRows() int {
+	switch p.fileOpMode {
+	case FileOpBulkRename:
+		n := min(len(p.renamePlan), renamePreviewMax)
+		if len(p.renamePlan) > renamePreviewMax {
+			n++ // "+N more"
+		}
+		return n
+	case FileOpRestore:
+		return min(len(p.restoreItems), restoreListMax)
+	}
+	return 0
+}
+
+// trashListedMsg carries the trashed files that came from this project.
+type trashListedMsg struct {
+	Epoch uint64
+	Items []trash.Item
+	Err   error
+}
+
+func (m trashListedMsg) GetEpoch() uint64 { return m.Epoch }
+
+// restoredMsg reports a restore from the trash.
+type restoredMsg struct {
+	Path string // Root-relative
+	Err  error
+}
+
+// listTrash loads the trash entries whose original path is in this project.
+func (p *Plugin) listTrash() tea.Cmd {
+	workDir, epoch := p.ctx.WorkDir, p.ctx.Epoch
+	return func() tea.Msg {
+		tr, err := trash.Home()
+		if err != nil {
+			return trashListedMsg{Epoch: epoch, Err: err}
+		}
+		items, err := tr.List()
+		if err != nil {
+			return trashListedMsg{Epoch: epoch, Err: err}
+		}
+		root, _ := filepath.Abs(workDir)
+		var mine []trash.Item
+		for _, it := range items {
+			if rel, err := filepath.Rel(root, it.Path); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+				mine = append(mine, it)
+			}
+		}
+		return trashListedMsg{Epoch: epoch, Items: mine}
+	}
+}
+
+// handleTrashListed opens the restore list.
+func (p *Plugin) handleTrashListed(msg trashListedMsg) tea.Cmd {
+	if msg.Err != nil {
+		return appmsg.ShowToast("Trash: "+msg.Err.Error(), 3*time.Second)
+	}
+	if len(msg.Items) == 0 {
+		return appmsg.ShowFlash("Nothing from this project in the trash")
+	}
+	p.fileOpMode = FileOpRestore
+	p.fileOpTarget = nil
+	p.fileOpError = ""
+	p.restoreItems = msg.Items
+	p.restoreCursor = 0
+	return nil
+}
+
+// handleRestoreKey drives the restore list.
+func (p *Plugin) handleRestoreKey(key string) tea.Cmd {
+	switch key {
+	case "j", "down", "ctrl+n":
+		if p.restoreCursor < len(p.restoreItems)-1 {
+			p.restoreCursor++
+		}
+	case "k", "up", "ctrl+p":
+		if p.restoreCursor > 0 {
+			p.restoreCursor--
+		}
+	case "enter":
+		if p.restoreCursor < len(p.restoreItems) {
+			return p.doRestore(p.restoreItems[p.restoreCursor])
+		}
+	case "esc", "q":
+		p.closeRestore()
+	}
+	return nil
+}
+
+func (p *Plugin) closeRestore() {
+	p.fileOpMode = FileOpNone
+	p.fileOpError = ""
+	p.restoreItems = nil
+	p.restoreCursor = 0
+}
+
+// doRestore puts it back where it was trashed from.
+func (p *Plugin) doRestore(it trash.Item) tea.Cmd {
+	workDir := p.ctx.WorkDir
+	return func() tea.Msg {
+		rel, err := filepath.Rel(workDir, it.Path)
+		if err != nil {
+			rel = it.Path
+		}
+		tr, err := trash.Home()
+		if err == nil {
+			err = tr.Restore(it)
+		}
+		return restoredMsg{Path: rel, Err: err}
+	}
+}
+
+// handleRestored closes the list on success; a failure stays on the list
+// with the reason, so another entry can be picked.
+func (p *Plugin) handleRestored(msg restoredMsg) tea.Cmd {
+	if msg.Err != nil {
+		p.fileOpError = msg.Err.Error()
+		return nil
+	}
+	p.closeRestore()
+	return tea.Batch(p.refresh(), appmsg.ShowFlash("Restored "+msg.Path))
+}
+
+// restoreWindow returns the slice of restoreItems on screen, keeping the
+// cursor visible, and the index of its first row.
+func (p *Plugin) restoreWindow() (int, []trash.Item) {
+	n := len(p.restoreItems)
+	if n <= restoreListMax {
+		return 0, p.restoreItems
+	}
+	start := min(max(p.restoreCursor-restoreListMax/2, 0), n-restoreListMax)
+	return start, p.restoreItems[start : start+restoreListMax]
+}
+
+// countNoun renders n with "item" or "items".
+func countNoun(n int) string {
+	if n == 1 {
+		return "1 item"
+	}
+	return fmt.Sprintf("%d items", n)
+}
+
+// firstLine returns s up to its first newline.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
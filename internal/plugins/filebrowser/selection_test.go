@@ -0,0 +1,277 @@
+package filebrowser
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+// newSelectionPlugin builds a browser over a small project, with its trash
+// redirected into the test's temp dir.
+func newSelectionPlugin(t *testing.T, files ...string) (*Plugin, string) {
+	t.Helper()
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	dir := t.TempDir()
+	for _, f := range files {
+		full := filepath.Join(dir, f)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(f), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p := New()
+	p.ctx = &plugin.Context{WorkDir: dir}
+	p.width, p.height, p.treeWidth = 100, 30, 40
+	p.tree = NewFileTree(dir)
+	if err := p.tree.Build(); err != nil {
+		t.Fatal(err)
+	}
+	return p, dir
+}
+
+func (p *Plugin) cursorTo(t *testing.T, path string) {
+	t.Helper()
+	idx := p.tree.IndexOfPath(path)
+	if idx < 0 {
+		t.Fatalf("%s is not in the tree", path)
+	}
+	p.treeCursor = idx
+}
+
+// run executes cmd and feeds its message back, as the runtime would, for
+// the one-message commands the bulk operations return.
+func run(t *testing.T, p *Plugin, cmd tea.Cmd) tea.Msg {
+	t.Helper()
+	if cmd == nil {
+		t.Fatal("expected a command")
+	}
+	msg := cmd()
+	p.Update(msg)
+	return msg
+}
+
+func TestSelectionMarksRangesAndDropsNestedPaths(t *testing.T) {
+	p, _ := newSelectionPlugin(t, "a.txt", "b.txt", "c.txt", "pkg/x.go")
+	if err := p.tree.Expand(p.tree.FindByPath("pkg")); err != nil {
+		t.Fatal(err)
+	}
+
+	p.cursorTo(t, "pkg")
+	p.handleTreeKey("space")
+	if !p.marked["pkg"] || p.tree.GetNode(p.treeCursor).Path != "pkg/x.go" {
+		t.Fatal("space should mark the row and step down")
+	}
+	p.handleTreeKey("space")
+	if got := strings.Join(p.selectedPaths(), ","); got != "pkg" {
+		t.Fatalf("a file inside a selected directory should ride along, got %s", got)
+	}
+
+	p.clearSelection()
+	p.cursorTo(t, "a.txt")
+	p.handleTreeKey("V")
+	p.handleTreeKey("j")
+	if got := strings.Join(p.selectedPaths(), ","); got != "a.txt,b.txt" {
+		t.Fatalf("visual range = %s", got)
+	}
+	p.handleTreeKey("V")
+	if p.visualAnchor != "" || !p.marked["a.txt"] || !p.marked["b.txt"] {
+		t.Fatal("leaving visual mode should keep the range as marks")
+	}
+	p.handleTreeKey("esc")
+	if p.hasSelection() {
+		t.Fatal("esc should clear the selection")
+	}
+
+	p.cursorTo(t, "pkg/x.go")
+	p.extendSelectionTo(p.tree.IndexOfPath("c.txt"))
+	if got := strings.Join(p.selectedPaths(), ","); got != "pkg/x.go,a.txt,b.txt,c.txt" {
+		t.Fatalf("shift-click range = %s", got)
+	}
+}
+
+func TestBulkTrashAndRestore(t *testing.T) {
+	p, dir := newSelectionPlugin(t, "a.txt", "b.txt", "keep.txt")
+	p.cursorTo(t, "a.txt")
+	p.handleTreeKey("space")
+	p.handleTreeKey("space")
+
+	p.handleTreeKey("D")
+	if !p.fileOpConfirmDelete || len(p.fileOpPaths) != 2 {
+		t.Fatalf("D with a selection should confirm the bulk delete, paths=%v", p.fileOpPaths)
+	}
+	_, cmd := p.handleFileOpKey(tea.KeyPressMsg{Code: 'y', Text: "y"})
+	res := run(t, p, cmd).(BulkOpResultMsg)
+	if res.Err != nil || res.Done != 2 {
+		t.Fatalf("trash result = %+v", res)
+	}
+	for _, f := range []string{"a.txt", "b.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, f)); !os.IsNotExist(err) {
+			t.Fatalf("%s still in the project", f)
+		}
+	}
+	if p.fileOpMode != FileOpNone || p.hasSelection() {
+		t.Fatal("a finished bulk delete should close the bar and clear the selection")
+	}
+
+	_, cmd = p.handleTreeKey("U")
+	run(t, p, cmd)
+	if p.fileOpMode != FileOpRestore || len(p.restoreItems) != 2 {
+		t.Fatalf("restore list = %+v", p.restoreItems)
+	}
+	p.handleFileOpKey(tea.KeyPressMsg{Code: 'j', Text: "j"})
+	want := p.restoreItems[1].Path
+	_, cmd = p.handleFileOpKey(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if msg := run(t, p, cmd).(restoredMsg); msg.Err != nil {
+		t.Fatal(msg.Err)
+	}
+	if _, err := os.Stat(want); err != nil {
+		t.Fatalf("restored file missing: %v", err)
+	}
+	if p.fileOpMode != FileOpNone {
+		t.Fatal("a successful restore should close the list")
+	}
+}
+
+func TestSingleDeleteGoesToTrash(t *testing.T) {
+	p, dir := newSelectionPlugin(t, "gone.txt")
+	p.cursorTo(t, "gone.txt")
+	p.handleTreeKey("D")
+	_, cmd := p.handleFileOpKey(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if _, ok := run(t, p, cmd).(DeleteSuccessMsg); !ok {
+		t.Fatal("expected the delete to succeed")
+	}
+	msg := p.listTrash()().(trashListedMsg)
+	if len(msg.Items) != 1 || msg.Items[0].Path != filepath.Join(dir, "gone.txt") {
+		t.Fatalf("trash = %+v", msg.Items)
+	}
+}
+
+func TestBulkMoveIntoDirectory(t *testing.T) {
+	p, dir := newSelectionPlugin(t, "a.txt", "b.txt", "dest/.keep")
+	p.cursorTo(t, "a.txt")
+	p.handleTreeKey("space")
+	p.handleTreeKey("space")
+	p.handleTreeKey("m")
+	if p.fileOpMode != FileOpBulkMove {
+		t.Fatal("m with a selection should ask for a directory")
+	}
+	p.fileOpTextInput.SetValue("dest")
+	_, cmd := p.executeFileOp()
+	if res := run(t, p, cmd).(BulkOpResultMsg); res.Err != nil || res.Done != 2 {
+		t.Fatalf("move result = %+v", res)
+	}
+	for _, f := range []string{"dest/a.txt", "dest/b.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, f)); err != nil {
+			t.Fatalf("%s not moved: %v", f, err)
+		}
+	}
+}
+
+func TestPlanRename(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{"a.txt", "b.txt", "c.md"} {
+		if err := os.WriteFile(filepath.Join(dir, f), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	paths := []string{"a.txt", "b.txt"}
+	cases := []struct {
+		pattern string
+		want    string
+		err     string
+	}{
+		{pattern: "img-{n:2}{ext}", want: "a.txt→img-01.txt,b.txt→img-02.txt"},
+		{pattern: `s/(\w)\.txt/\1-old.txt/`, want: "a.txt→a-old.txt,b.txt→b-old.txt"},
+		{pattern: "{name}{ext}", err: "changes no names"},
+		{pattern: "same.txt", err: "would both be named"},
+		{pattern: "c.md", err: "c.md already exists"},
+		{pattern: "s/a/b/", err: "b.txt already exists"},
+		{pattern: "s/[/x/", err: "bad regexp"},
+	}
+	for _, tc := range cases {
+		steps, err := planRename(dir, paths, tc.pattern)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("%q: err = %v, want %q", tc.pattern, err, tc.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tc.pattern, err)
+			continue
+		}
+		var got []string
+		for _, st := range steps {
+			got = append(got, st.From+"→"+st.To)
+		}
+		if strings.Join(got, ",") != tc.want {
+			t.Errorf("%q: plan = %v, want %s", tc.pattern, got, tc.want)
+		}
+	}
+
+	if _, err := planRename(dir, []string{"a.txt"}, "c.md"); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("renaming onto an untouched file should fail, got %v", err)
+	}
+}
+
+func TestBulkRenameSwapsNames(t *testing.T) {
+	p, dir := newSelectionPlugin(t, "one", "two")
+	p.marked = map[string]bool{"one": true, "two": true}
+	p.handleTreeKey("R")
+	if p.fileOpMode != FileOpBulkRename {
+		t.Fatal("R with a selection should ask for a pattern")
+	}
+	p.fileOpTextInput.SetValue(`s/^(one|two)$/x\1/`)
+	p.updateRenamePlan()
+	if p.fileOpError != "" || len(p.renamePlan) != 2 {
+		t.Fatalf("plan = %+v, err = %s", p.renamePlan, p.fileOpError)
+	}
+	if !strings.Contains(p.renderFileOpBar(), "one → xone") {
+		t.Fatal("the bar should preview each rename")
+	}
+	_, cmd := p.executeFileOp()
+	if res := run(t, p, cmd).(BulkOpResultMsg); res.Err != nil {
+		t.Fatal(res.Err)
+	}
+
+	// A swap needs the two-pass rename: each target is the other's source.
+	steps := []renameStep{{From: "xone", To: "xtwo"}, {From: "xtwo", To: "xone"}}
+	if res := doBulkRename(dir, steps)().(BulkOpResultMsg); res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "xone")); string(b) != "two" {
+		t.Fatalf("swap left xone = %q", b)
+	}
+}
+
+func TestBulkRenameRollsBackAChainWithoutOverwriting(t *testing.T) {
+	_, dir := newSelectionPlugin(t, "a", "b", "c")
+	steps := []renameStep{{From: "a", To: "b"}, {From: "b", To: "c"}, {From: "c", To: "d"}}
+	// d appears after the plan was made, so the last step of the second
+	// pass fails once a→b and b→c have already landed.
+	if err := os.WriteFile(filepath.Join(dir, "d"), []byte("keep"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	res := doBulkRename(dir, steps)().(BulkOpResultMsg)
+	if res.Err == nil || !errors.Is(res.Err, fs.ErrExist) {
+		t.Fatalf("renaming onto an existing file should fail, got %v", res.Err)
+	}
+	for name, want := range map[string]string{"a": "a", "b": "b", "c": "c", "d": "keep"} {
+		if b, err := os.ReadFile(filepath.Join(dir, name)); err != nil || string(b) != want {
+			t.Errorf("%s = %q, %v; want %q", name, b, err, want)
+		}
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 4 {
+		t.Errorf("rollback left %d entries, want 4", len(entries))
+	}
+}
@@ -0,0 +1,605 @@
+package filebrowser
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/marcus/sidecar/internal/docview"
+	"github.com/marcus/sidecar/internal/filestream"
+	"github.com/marcus/sidecar/internal/livewatch"
+	"github.com/marcus/sidecar/internal/styles"
+	"github.com/marcus/sidecar/internal/ui"
+)
+
+// Streaming preview shows text files too large to load whole (see
+// filepreview.StreamThreshold). Instead of lines it holds a sparse line index
+// that is built in the background, plus the few screens of lines around the
+// viewport, read on demand as the user scrolls. previewScroll stays the top
+// line, so tab state, line jumps and project-search navigation work unchanged.
+//
+// Every read is a bounded step in a command: indexing and search report
+// progress between steps and are superseded by bumping a generation rather
+// than cancelled.
+
+// streamWindowScreens is how many screens of lines one window read covers:
+// the viewport plus one above and one below, so small scrolls need no read.
+const streamWindowScreens = 3
+
+// streamPreview is the state of one streamed file.
+type streamPreview struct {
+	path string // absolute path being streamed
+	gen  uint64 // identifies this stream's results
+
+	index    filestream.Index
+	indexing bool
+	err      error
+
+	window    []string // lines read around the viewport
+	windowTop int      // line number of window[0]
+	windowEOF bool     // the window read ran into the end of the file
+	readTop   int      // start of the newest window read, -1 when none is running
+
+	// follow keeps the viewport on the last line as the file grows.
+	follow bool
+
+	scan     *streamScan // running search, nil when idle
+	scanSeq  uint64      // identifies the newest scan
+	match    int         // line of the last match, -1 when none
+	noMatch  bool        // the last scan reached the end without a match
+	matchFor string      // query the match or miss belongs to
+}
+
+// streamScan is a search running over the file in steps.
+type streamScan struct {
+	query   string
+	forward bool
+	origin  int // line the scan started from, for progress
+	next    int // line the next step resumes at
+}
+
+type (
+	streamIndexedMsg struct {
+		Epoch uint64
+		gen   uint64
+		index filestream.Index
+		reset bool
+		err   error
+	}
+	streamWindowMsg struct {
+		Epoch uint64
+		gen   uint64
+		top   int
+		want  int
+		lines []string
+		err   error
+	}
+	streamSearchedMsg struct {
+		Epoch  uint64
+		gen    uint64
+		seq    uint64
+		result filestream.SearchResult
+		err    error
+	}
+	streamWatchStartedMsg struct {
+		Watcher *livewatch.PathWatcher
+	}
+	streamChangedMsg struct{}
+)
+
+func (m streamIndexedMsg) GetEpoch() uint64  { return m.Epoch }
+func (m streamWindowMsg) GetEpoch() uint64   { return m.Epoch }
+func (m streamSearchedMsg) GetEpoch() uint64 { return m.Epoch }
+
+// streamedPath is the absolute path of the previewed file.
+func (p *Plugin) streamedPath() string {
+	root := ""
+	if p.tree != nil {
+		root = p.tree.RootDir
+	}
+	return filepath.Join(root, p.previewFile)
+}
+
+// openStream starts streaming the previewed file.
+func (p *Plugin) openStream() tea.Cmd {
+	p.streamGen++
+	p.stream = &streamPreview{
+		path:     p.streamedPath(),
+		gen:      p.streamGen,
+		indexing: true,
+		readTop:  -1,
+		match:    -1,
+	}
+	return tea.Batch(p.streamIndexCmd(), p.ensureStreamWindow(), p.watchStream())
+}
+
+// closeStream forgets the streamed file and stops watching it. Results still
+// in flight carry the old generation and are dropped when they land.
+func (p *Plugin) closeStream() {
+	if p.stream == nil {
+		return
+	}
+	p.stream = nil
+	if p.streamWatcher != nil {
+		p.streamWatcher.Watch()
+	}
+}
+
+// watchStream points the stream watcher at the streamed file, starting the
+// watcher the first time a large file is opened.
+func (p *Plugin) watchStream() tea.Cmd {
+	if p.streamWatcher != nil {
+		p.streamWatcher.Watch(livewatch.File(p.stream.path))
+		return nil
+	}
+	if p.streamWatchStarting {
+		return nil
+	}
+	p.streamWatchStarting = true
+	return livewatch.Start(livewatch.Config{
+		// A busy log is appended to continuously; the latency cap is what
+		// keeps follow mode moving while it is.
+		Quiet:      200 * time.Millisecond,
+		MaxLatency: time.Second,
+	}, []livewatch.Target{livewatch.File(p.stream.path)}, func(w *livewatch.PathWatcher, err error) tea.Msg {
+		if err != nil {
+			return streamWatchStartedMsg{}
+		}
+		return streamWatchStartedMsg{Watcher: w}
+	})
+}
+
+// handleStreamWatchStarted adopts the stream watcher and re-aims it at
+// whatever is streaming now, which may be nothing.
+func (p *Plugin) handleStreamWatchStarted(msg streamWatchStartedMsg) tea.Cmd {
+	p.streamWatchStarting = false
+	if msg.Watcher == nil {
+		return nil
+	}
+	if p.stopped {
+		go msg.Watcher.Stop()
+		return nil
+	}
+	if p.streamWatcher != nil && p.streamWatcher != msg.Watcher {
+		go p.streamWatcher.Stop()
+	}
+	p.streamWatcher = msg.Watcher
+	if p.stream != nil {
+		p.streamWatcher.Watch(livewatch.File(p.stream.path))
+	} else {
+		p.streamWatcher.Watch()
+	}
+	return livewatch.Listen(p.streamWatcher, streamChangedMsg{})
+}
+
+// handleStreamChanged re-arms the listener and indexes whatever was appended.
+func (p *Plugin) handleStreamChanged() tea.Cmd {
+	listen := livewatch.Listen(p.streamWatcher, streamChangedMsg{})
+	s := p.stream
+	if s == nil || s.indexing {
+		return listen
+	}
+	s.indexing = true
+	return tea.Batch(listen, p.streamIndexCmd())
+}
+
+// stopStreamWatcher releases the stream watcher when the plugin stops.
+func (p *Plugin) stopStreamWatcher() {
+	p.stream = nil
+	if p.streamWatcher != nil {
+		p.streamWatcher.Stop()
+		p.streamWatcher = nil
+	}
+}
+
+// streamIndexCmd runs one indexing step.
+func (p *Plugin) streamIndexCmd() tea.Cmd {
+	s := p.stream
+	path, ix, gen, epoch := s.path, s.index, s.gen, p.ctx.Epoch
+	return func() tea.Msg {
+		next, reset, err := ix.Extend(path, filestream.IndexStep)
+		return streamIndexedMsg{Epoch: epoch, gen: gen, index: next, reset: reset, err: err}
+	}
+}
+
+// handleStreamIndexed installs an indexing step and schedules the next one
+// until the file, as of now, is fully indexed.
+func (p *Plugin) handleStreamIndexed(msg streamIndexedMsg) tea.Cmd {
+	s := p.stream
+	if s == nil || msg.gen != s.gen {
+		return nil
+	}
+	if msg.err != nil {
+		s.err = msg.err
+		s.indexing = false
+		return nil
+	}
+	s.err = nil
+	prevLines := s.index.Lines()
+	s.index = msg.index
+	p.previewSize = s.index.Size()
+	switch {
+	case msg.reset:
+		// Truncated or rotated: nothing read so far describes the new file.
+		s.window, s.windowEOF = nil, false
+		s.match, s.noMatch = -1, false
+		if !s.follow {
+			p.previewScroll = 0
+		}
+	case s.index.Lines() != prevLines && s.windowTop+len(s.window) >= prevLines-1:
+		// The window reaches the old end, whose last line may have been
+		// partial; read it again with what was appended.
+		s.window, s.windowEOF = nil, false
+	}
+
+	var cmds []tea.Cmd
+	if s.index.Done() {
+		s.indexing = false
+	} else {
+		cmds = append(cmds, p.streamIndexCmd())
+	}
+	if s.follow {
+		p.previewScroll = p.streamMaxScroll()
+	}
+	p.clampPreviewScroll()
+	cmds = append(cmds, p.ensureStreamWindow())
+	return tea.Batch(cmds...)
+}
+
+// streamRows is how many file lines the streamed preview shows; the last row
+// of the pane holds the status line.
+func (p *Plugin) streamRows() int {
+	return max(p.previewSourceRowCapacity()-1, 1)
+}
+
+// streamMaxScroll is the largest top line that still fills the viewport.
+func (p *Plugin) streamMaxScroll() int {
+	return max(p.stream.index.Lines()-p.streamRows(), 0)
+}
+
+// ensureStreamWindow reads the lines around the viewport unless the current
+// window, or the read already running, covers it.
+func (p *Plugin) ensureStreamWindow() tea.Cmd {
+	s := p.stream
+	if s == nil {
+		return nil
+	}
+	rows := p.streamRows()
+	top := p.previewScroll
+	if s.window != nil && top >= s.windowTop &&
+		(top+rows <= s.windowTop+len(s.window) || s.windowEOF) {
+		return nil
+	}
+	start := max(top-rows, 0)
+	want := rows * streamWindowScreens
+	if s.readTop >= 0 && top >= s.readTop && top+rows <= s.readTop+want {
+		return nil
+	}
+	s.readTop = start
+	path, ix, gen, epoch := s.path, s.index, s.gen, p.ctx.Epoch
+	return func() tea.Msg {
+		lines, err := filestream.ReadLines(path, ix, start, want)
+		return streamWindowMsg{Epoch: epoch, gen: gen, top: start, want: want, lines: lines, err: err}
+	}
+}
+
+// handleStreamWindow installs the newest window read; older ones were
+// superseded by a scroll while they ran.
+func (p *Plugin) handleStreamWindow(msg streamWindowMsg) tea.Cmd {
+	s := p.stream
+	if s == nil || msg.gen != s.gen || msg.top != s.readTop {
+		return nil
+	}
+	s.readTop = -1
+	if msg.err != nil {
+		s.err = msg.err
+		return nil
+	}
+	s.window = msg.lines
+	s.windowTop = msg.top
+	s.windowEOF = len(msg.lines) < msg.want
+	return p.ensureStreamWindow()
+}
+
+// streamScrollTo moves the viewport to top and reads what it now shows.
+// Moving anywhere but the last line ends follow mode, as it does in less.
+func (p *Plugin) streamScrollTo(top int) tea.Cmd {
+	limit := p.streamMaxScroll()
+	if p.stream.indexing {
+		// A restored position can lie past what is indexed so far.
+		limit = max(limit, p.previewScroll)
+	}
+	p.previewScroll = max(min(top, limit), 0)
+	if p.previewScroll < p.streamMaxScroll() || p.stream.indexing {
+		p.stream.follow = false
+	}
+	p.saveActiveTabState()
+	return p.ensureStreamWindow()
+}
+
+// handleStreamKey handles preview keys that mean something different for a
+// streamed file. Unhandled keys fall through to the ordinary preview keys.
+func (p *Plugin) handleStreamKey(key string) (bool, tea.Cmd) {
+	if p.stream == nil {
+		return false, nil
+	}
+	rows := p.streamRows()
+	top := p.previewScroll
+	switch key {
+	case "j", "down":
+		return true, p.streamScrollTo(top + 1)
+	case "k", "up":
+		return true, p.streamScrollTo(top - 1)
+	case "ctrl+d":
+		return true, p.streamScrollTo(top + rows/2)
+	case "ctrl+u":
+		return true, p.streamScrollTo(top - rows/2)
+	case "ctrl+f", "pgdown":
+		return true, p.streamScrollTo(top + rows)
+	case "ctrl+b", "pgup":
+		return true, p.streamScrollTo(top - rows)
+	case "g", "home":
+		return true, p.streamScrollTo(0)
+	case "G", "end":
+		return true, p.streamScrollTo(p.streamMaxScroll())
+	case "F":
+		return true, p.toggleStreamFollow()
+	}
+	return false, nil
+}
+
+// toggleStreamFollow turns tail-follow on, jumping to the last line, or off.
+func (p *Plugin) toggleStreamFollow() tea.Cmd {
+	s := p.stream
+	if s.follow {
+		s.follow = false
+		return nil
+	}
+	cmd := p.streamScrollTo(p.streamMaxScroll())
+	s.follow = true
+	return cmd
+}
+
+// scrollStream scrolls the streamed preview by delta lines (mouse wheel).
+func (p *Plugin) scrollStream(delta int) tea.Cmd {
+	return p.streamScrollTo(p.previewScroll + delta)
+}
+
+// jumpStreamLine scrolls so 0-based line is at the top of the viewport.
+func (p *Plugin) jumpStreamLine(line int) tea.Cmd {
+	return p.streamScrollTo(line)
+}
+
+// handleStreamSearchKey handles the content-search bar over a streamed file.
+// The query is not matched as it is typed, since a match can be anywhere in
+// gigabytes; enter starts a scan from the top of the viewport, and n/N scan on
+// from the last match.
+func (p *Plugin) handleStreamSearchKey(key, text string) tea.Cmd {
+	s := p.stream
+	if key == "esc" {
+		p.contentSearchMode = false
+		p.contentSearchCommitted = false
+		p.contentSearchQuery = ""
+		s.scan = nil
+		s.match, s.noMatch, s.matchFor = -1, false, ""
+		return nil
+	}
+
+	if !p.contentSearchCommitted {
+		switch key {
+		case "enter":
+			if p.contentSearchQuery == "" {
+				return nil
+			}
+			p.contentSearchCommitted = true
+			return p.startStreamSearch(p.previewScroll, true)
+		case "backspace":
+			if p.contentSearchQuery != "" {
+				runes := []rune(p.contentSearchQuery)
+				p.contentSearchQuery = string(runes[:len(runes)-1])
+			}
+		default:
+			if text != "" {
+				p.contentSearchQuery += text
+			}
+		}
+		return nil
+	}
+
+	switch key {
+	case "n":
+		from := p.previewScroll
+		if s.match >= 0 {
+			from = s.match + 1
+		}
+		return p.startStreamSearch(from, true)
+	case "N":
+		from := p.previewScroll
+		if s.match >= 0 {
+			from = s.match
+		}
+		return p.startStreamSearch(from, false)
+	case "enter":
+		p.contentSearchMode = false
+		p.contentSearchCommitted = false
+		return nil
+	}
+	_, cmd := p.handleStreamKey(key)
+	return cmd
+}
+
+// startStreamSearch starts a scan for the current query, superseding any scan
+// still running.
+func (p *Plugin) startStreamSearch(from int, forward bool) tea.Cmd {
+	s := p.stream
+	s.scanSeq++
+	s.scan = &streamScan{query: p.contentSearchQuery, forward: forward, origin: from, next: from}
+	s.noMatch = false
+	return p.streamSearchCmd()
+}
+
+// streamSearchCmd runs one step of the current scan.
+func (p *Plugin) streamSearchCmd() tea.Cmd {
+	s := p.stream
+	scan := *s.scan
+	path, ix, gen, seq, epoch := s.path, s.index, s.gen, s.scanSeq, p.ctx.Epoch
+	return func() tea.Msg {
+		res, err := filestream.Search(path, ix, scan.query, scan.next, scan.forward, filestream.SearchStep)
+		return streamSearchedMsg{Epoch: epoch, gen: gen, seq: seq, result: res, err: err}
+	}
+}
+
+// handleStreamSearched applies one scan step: jump to a match, report a miss,
+// or run the next step.
+func (p *Plugin) handleStreamSearched(msg streamSearchedMsg) tea.Cmd {
+	s := p.stream
+	if s == nil || msg.gen != s.gen || msg.seq != s.scanSeq || s.scan == nil {
+		return nil
+	}
+	scan := s.scan
+	switch {
+	case msg.err != nil:
+		s.scan = nil
+		s.err = msg.err
+		return nil
+	case msg.result.Found:
+		s.scan = nil
+		s.match, s.matchFor = msg.result.Line, scan.query
+		// Leave a little context above the match rather than pinning it to
+		// the first row.
+		return p.streamScrollTo(msg.result.Line - p.streamRows()/3)
+	case msg.result.Done:
+		s.scan = nil
+		s.noMatch, s.matchFor = true, scan.query
+		return nil
+	}
+	scan.next = msg.result.Next
+	return p.streamSearchCmd()
+}
+
+// scanProgress is the fraction of its range the running scan has covered.
+func (s *streamPreview) scanProgress() float64 {
+	scan := s.scan
+	if scan.forward {
+		total := s.index.Lines() - scan.origin
+		if total <= 0 {
+			return 0
+		}
+		return min(float64(scan.next-scan.origin)/float64(total), 1)
+	}
+	if scan.origin <= 0 {
+		return 1
+	}
+	return float64(scan.origin-scan.next) / float64(scan.origin)
+}
+
+// renderStream draws the viewport of a streamed file and its status line.
+func (p *Plugin) renderStream(visibleHeight int) string {
+	s := p.stream
+	rows := max(visibleHeight-1, 1)
+	total := s.index.Lines()
+	gutter := docview.NewGutter(max(total, p.previewScroll+rows))
+	_, maxLineWidth := p.previewTextWidths()
+
+	query := ""
+	if p.contentSearchQuery != "" && (p.contentSearchCommitted || s.matchFor != "") {
+		query = p.contentSearchQuery
+	}
+
+	var sb strings.Builder
+	for i := p.previewScroll; i < p.previewScroll+rows; i++ {
+		if i >= total && !s.indexing {
+			break
+		}
+		text, ok := s.line(i)
+		if !ok && i >= total {
+			break
+		}
+		text = ansi.Truncate(ui.ExpandTabs(text, 8), maxLineWidth, "")
+		switch {
+		case !ok:
+			text = styles.Muted.Render("…")
+		case query != "":
+			text = highlightQuery(text, query, i == s.match)
+		}
+		sb.WriteString(gutter.Number(i + 1))
+		sb.WriteString(text)
+		sb.WriteString("\n")
+	}
+	// Pad so the status line sits on the pane's last row.
+	for n := strings.Count(sb.String(), "\n"); n < rows; n++ {
+		sb.WriteString("\n")
+	}
+	sb.WriteString(styles.Muted.Render(ansi.Truncate(p.streamStatus(), p.previewContentWidth(), "…")))
+	return sb.String()
+}
+
+// line returns line i from the window, if the window holds it.
+func (s *streamPreview) line(i int) (string, bool) {
+	if s.window == nil || i < s.windowTop || i >= s.windowTop+len(s.window) {
+		return "", false
+	}
+	return s.window[i-s.windowTop], true
+}
+
+// streamStatus describes the position, indexing and search state.
+func (p *Plugin) streamStatus() string {
+	s := p.stream
+	total := s.index.Lines()
+	parts := []string{fmt.Sprintf("line %d of %d", min(p.previewScroll+1, max(total, 1)), total)}
+	if s.indexing {
+		parts = append(parts, fmt.Sprintf("indexing %d%%", int(s.index.Progress()*100)))
+	}
+	if s.follow {
+		parts = append(parts, "following")
+	}
+	switch {
+	case s.scan != nil:
+		parts = append(parts, fmt.Sprintf("searching %q %d%%", s.scan.query, int(s.scanProgress()*100)))
+	case s.noMatch:
+		parts = append(parts, fmt.Sprintf("no more matches for %q", s.matchFor))
+	case s.match >= 0:
+		parts = append(parts, fmt.Sprintf("match at line %d", s.match+1))
+	}
+	if s.err != nil {
+		parts = append(parts, s.err.Error())
+	}
+	return strings.Join(parts, " · ")
+}
+
+// highlightQuery marks each occurrence of query in a plain line, with the same
+// smart case filestream.Search matches by.
+func highlightQuery(line, query string, current bool) string {
+	hay := line
+	if strings.ToLower(query) == query {
+		hay = strings.ToLower(line)
+		// Lower-casing can change a line's byte length; offsets found in it
+		// would then not index the original.
+		if len(hay) != len(line) {
+			return line
+		}
+	}
+	style := styles.SearchMatch
+	if current {
+		style = styles.SearchMatchCurrent
+	}
+	var b strings.Builder
+	rest := 0
+	for {
+		i := strings.Index(hay[rest:], query)
+		if i < 0 {
+			break
+		}
+		start := rest + i
+		end := start + len(query)
+		b.WriteString(line[rest:start])
+		b.WriteString(style.Render(line[start:end]))
+		rest = end
+	}
+	b.WriteString(line[rest:])
+	return b.String()
+}
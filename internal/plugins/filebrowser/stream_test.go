@@ -0,0 +1,181 @@
+package filebrowser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/marcus/sidecar/internal/filepreview"
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+// newStreamPlugin writes a log just past the streaming threshold and returns
+// a plugin previewing it, streamed and fully indexed.
+func newStreamPlugin(t *testing.T) (*Plugin, string, int) {
+	t.Helper()
+	dir := t.TempDir()
+	var b strings.Builder
+	n := 0
+	for b.Len() < filepreview.StreamThreshold {
+		fmt.Fprintf(&b, "line %07d\n", n)
+		n++
+	}
+	path := filepath.Join(dir, "big.log")
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := LoadPreview(dir, "big.log", 0)().(PreviewLoadedMsg)
+	if !loaded.Result.IsLarge || len(loaded.Result.Lines) != 0 {
+		t.Fatalf("a file past the threshold should load unread, got %+v", loaded.Result)
+	}
+	p := &Plugin{
+		ctx:          &plugin.Context{WorkDir: dir},
+		tree:         &FileTree{RootDir: dir},
+		previewFile:  "big.log",
+		previewWidth: 80,
+		width:        120,
+		height:       30,
+		activePane:   PanePreview,
+	}
+	settleStream(t, p, p.applyPreviewResult(loaded.Result))
+	return p, path, n
+}
+
+// settleStream runs cmd and everything its messages lead to, as the runtime
+// would. The change watcher is stopped as soon as it starts: its listener
+// blocks until the file changes, which these tests drive by hand.
+func settleStream(t *testing.T, p *Plugin, cmd tea.Cmd) {
+	t.Helper()
+	queue := []tea.Cmd{cmd}
+	for steps := 0; len(queue) > 0; steps++ {
+		if steps > 10000 {
+			t.Fatal("stream commands did not settle")
+		}
+		c := queue[0]
+		queue = queue[1:]
+		if c == nil {
+			continue
+		}
+		switch msg := c().(type) {
+		case nil:
+		case tea.BatchMsg:
+			queue = append(queue, msg...)
+		case streamWatchStartedMsg:
+			if msg.Watcher != nil {
+				msg.Watcher.Stop()
+			}
+			p.streamWatchStarting = false
+		default:
+			_, next := p.Update(msg)
+			queue = append(queue, next)
+		}
+	}
+}
+
+func streamView(p *Plugin) string {
+	return ansi.Strip(p.renderPreviewPane(p.height))
+}
+
+func TestStreamedPreviewShowsWindowAndJumps(t *testing.T) {
+	p, _, n := newStreamPlugin(t)
+	if p.stream == nil || p.stream.indexing || p.stream.index.Lines() != n {
+		t.Fatalf("expected a finished index of %d lines, got %+v", n, p.stream)
+	}
+	view := streamView(p)
+	if !strings.Contains(view, "line 0000000") || !strings.Contains(view, fmt.Sprintf("line 1 of %d", n)) {
+		t.Fatalf("first window not rendered:\n%s", view)
+	}
+
+	p.lineJumpMode = true
+	p.lineJumpBuffer = "300000"
+	_, cmd := p.handleLineJumpKey(tea.KeyPressMsg{Code: tea.KeyEnter})
+	settleStream(t, p, cmd)
+	if view := streamView(p); !strings.Contains(view, "line 0299999") {
+		t.Fatalf("jump did not show line 300000:\n%s", view)
+	}
+
+	_, cmd = p.handlePreviewKey("G")
+	settleStream(t, p, cmd)
+	if view := streamView(p); !strings.Contains(view, fmt.Sprintf("line %07d", n-1)) {
+		t.Fatalf("G did not reach the last line:\n%s", view)
+	}
+}
+
+func TestStreamedSearchScansToMatch(t *testing.T) {
+	p, _, _ := newStreamPlugin(t)
+	p.contentSearchMode = true
+	p.contentSearchQuery = "line 0250000"
+	settleStream(t, p, p.handleStreamSearchKey("enter", ""))
+	if p.stream.match != 250000 {
+		t.Fatalf("match = %d, want 250000", p.stream.match)
+	}
+	if view := streamView(p); !strings.Contains(view, "line 0250000") || !strings.Contains(view, "match at line 250001") {
+		t.Fatalf("match not on screen:\n%s", view)
+	}
+
+	settleStream(t, p, p.handleStreamSearchKey("n", "n"))
+	if view := streamView(p); !strings.Contains(view, `no more matches for "line 0250000"`) {
+		t.Fatalf("expected a miss after the only match:\n%s", view)
+	}
+	settleStream(t, p, p.handleStreamSearchKey("N", "N"))
+	if p.stream.match != 250000 {
+		t.Fatalf("N did not find the match above, match = %d", p.stream.match)
+	}
+}
+
+func TestStreamFollowPicksUpAppendsAndRotation(t *testing.T) {
+	p, path, n := newStreamPlugin(t)
+	_, cmd := p.handlePreviewKey("F")
+	settleStream(t, p, cmd)
+	if !p.stream.follow || p.previewScroll != p.streamMaxScroll() {
+		t.Fatal("F should follow from the last line")
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString("appended one\nappended two\n")
+	_ = f.Close()
+	settleStream(t, p, p.handleStreamChanged())
+	if p.stream.index.Lines() != n+2 {
+		t.Fatalf("lines = %d, want %d", p.stream.index.Lines(), n+2)
+	}
+	if view := streamView(p); !strings.Contains(view, "appended two") || !strings.Contains(view, "following") {
+		t.Fatalf("follow did not show the appended lines:\n%s", view)
+	}
+
+	_, cmd = p.handlePreviewKey("k")
+	settleStream(t, p, cmd)
+	if p.stream.follow {
+		t.Fatal("scrolling up should stop following")
+	}
+
+	if err := os.WriteFile(path, []byte("rotated\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	settleStream(t, p, p.handleStreamChanged())
+	if p.stream.index.Lines() != 1 || p.previewScroll != 0 {
+		t.Fatalf("truncation should re-index from the top, lines=%d scroll=%d", p.stream.index.Lines(), p.previewScroll)
+	}
+	if view := streamView(p); !strings.Contains(view, "rotated") {
+		t.Fatalf("rotated file not shown:\n%s", view)
+	}
+}
+
+func TestStreamedPreviewSkipsLiveReload(t *testing.T) {
+	p, _, _ := newStreamPlugin(t)
+	if !p.previewRefreshSuppressed() {
+		t.Fatal("a streamed file follows its own changes; a reload would start it over")
+	}
+	p.applyPreviewResult(PreviewResult{Lines: []string{"small"}})
+	if p.stream != nil {
+		t.Fatal("a file loaded whole should end the stream")
+	}
+}
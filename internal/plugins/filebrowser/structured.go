@@ -0,0 +1,129 @@
+package filebrowser
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/marcus/sidecar/internal/msg"
+	"github.com/marcus/sidecar/internal/structview"
+)
+
+// Structured preview renders data files as structures rather than text: JSON
+// and YAML as a collapsible tree, CSV and TSV as a sortable table, SQLite as a
+// read-only table browser. Like rendered markdown it is a mode the user
+// toggles, and the choice carries across files for the session. SQLite is the
+// exception: its only text rendering is "Binary file", so a database always
+// opens in the browser.
+
+var errStreamedStructure = errors.New("file is too large to load whole")
+
+// structuredKind reports the structured rendering the previewed file has.
+func (p *Plugin) structuredKind() structview.Kind {
+	if p.previewFile == "" {
+		return structview.KindNone
+	}
+	return structview.Detect(p.previewFile)
+}
+
+// showingStructured reports whether the preview pane is drawing a structured
+// model instead of the file's text.
+func (p *Plugin) showingStructured() bool {
+	if p.structured == nil {
+		return false
+	}
+	return p.structuredMode || p.structuredKind() == structview.KindSQLite
+}
+
+// buildStructured replaces the structured model for the current preview and
+// returns the command that starts it loading, if it loads asynchronously.
+// Text formats are parsed only while the mode is on, as markdown is only
+// rendered while its mode is on.
+func (p *Plugin) buildStructured() tea.Cmd {
+	root := ""
+	if p.tree != nil {
+		root = p.tree.RootDir
+	}
+	fullPath := filepath.Join(root, p.previewFile)
+	kind := p.structuredKind()
+	// A database changes under the browser whenever its application writes,
+	// and each write reloads the preview. Rebuilding would throw the reader
+	// back to the table list every time, so an open browser on the same file
+	// is kept; it reads the file afresh on every page anyway.
+	if db, ok := p.structured.(*structview.SQLite); ok && kind == structview.KindSQLite && db.Path() == fullPath && p.isBinary {
+		return nil
+	}
+	p.structured = nil
+	p.structuredErr = nil
+	if kind == structview.KindNone || p.previewError != nil || p.isImage {
+		return nil
+	}
+	if kind == structview.KindSQLite {
+		// A .db that reads as text is some other format; leave it as text.
+		if !p.isBinary {
+			return nil
+		}
+	} else if !p.structuredMode || p.isBinary {
+		return nil
+	} else if p.stream != nil {
+		// A streamed file is never held whole, and a tree or table needs it
+		// all.
+		p.structuredErr = errStreamedStructure
+		return nil
+	}
+	model, err := structview.Load(fullPath, strings.Join(p.previewLines, "\n"), p.isTruncated)
+	if err != nil {
+		p.structuredErr = err
+		return nil
+	}
+	if model == nil {
+		return nil
+	}
+	p.structured = model
+	return model.Init()
+}
+
+// toggleStructured switches a data file between its structured and text
+// renderings.
+func (p *Plugin) toggleStructured() tea.Cmd {
+	kind := p.structuredKind()
+	if kind == structview.KindNone || kind == structview.KindSQLite {
+		return nil
+	}
+	p.structuredMode = !p.structuredMode
+	if !p.structuredMode {
+		p.structured = nil
+		p.structuredErr = nil
+		return nil
+	}
+	p.selection.Clear()
+	cmd := p.buildStructured()
+	if p.structuredErr != nil {
+		return msg.ShowFlash("Can't show structure: " + p.structuredErr.Error())
+	}
+	return cmd
+}
+
+// handleStructuredKey offers a preview-pane key to the structured model first.
+// Keys it does not use fall through to the ordinary preview bindings, so tabs,
+// editing and pane switching work the same in either rendering.
+func (p *Plugin) handleStructuredKey(key string) (bool, tea.Cmd) {
+	if !p.showingStructured() {
+		return false, nil
+	}
+	return p.structured.HandleKey(key)
+}
+
+// scrollStructured turns wheel motion into cursor motion in the structured
+// model, the way the wheel moves the tree pane's cursor.
+func (p *Plugin) scrollStructured(delta int) {
+	key := "j"
+	if delta < 0 {
+		key, delta = "k", -delta
+	}
+	for i := 0; i < delta; i++ {
+		p.structured.HandleKey(key)
+	}
+}
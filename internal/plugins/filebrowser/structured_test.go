@@ -0,0 +1,90 @@
+package filebrowser
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/marcus/sidecar/internal/structview"
+)
+
+func TestToggleStructuredShowsJSONTree(t *testing.T) {
+	p := &Plugin{
+		previewFile:  "config.json",
+		previewLines: []string{`{"server": {"port": 8080}}`},
+		previewWidth: 80,
+		height:       30,
+		activePane:   PanePreview,
+	}
+	if p.showingStructured() {
+		t.Fatal("structured view should start off, like rendered markdown")
+	}
+
+	p.toggleStructured()
+	if !p.showingStructured() {
+		t.Fatal("toggle did not switch to the structured view")
+	}
+	if got := p.FocusContext(); got != "file-browser-structured" {
+		t.Fatalf("FocusContext = %q", got)
+	}
+	view := ansi.Strip(p.renderPreviewPane(20))
+	if !strings.Contains(view, "[json tree]") || !strings.Contains(view, "port: 8080") {
+		t.Fatalf("preview did not render the tree:\n%s", view)
+	}
+
+	// Keys the tree does not use still reach the preview's own bindings.
+	p.handlePreviewKey("v")
+	if p.showingStructured() || p.FocusContext() != "file-browser-preview" {
+		t.Fatal("v did not return to the text view")
+	}
+}
+
+func TestStructuredParseErrorKeepsText(t *testing.T) {
+	p := &Plugin{
+		previewFile:  "broken.json",
+		previewLines: []string{`{"a": `},
+	}
+	if cmd := p.toggleStructured(); cmd == nil {
+		t.Fatal("a parse failure should explain itself with a flash")
+	}
+	if p.showingStructured() {
+		t.Fatal("an unparseable file must stay as text")
+	}
+}
+
+func TestSQLitePreviewOpensBrowserWithoutToggle(t *testing.T) {
+	dir := t.TempDir()
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT)`); err != nil {
+		t.Fatal(err)
+	}
+	_ = db.Close()
+	info, err := os.Stat(filepath.Join(dir, "app.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Plugin{tree: &FileTree{RootDir: dir}, previewFile: "app.sqlite", previewWidth: 80, height: 30}
+	cmd := p.applyPreviewResult(PreviewResult{IsBinary: true, TotalSize: info.Size()})
+	if cmd == nil || !p.showingStructured() {
+		t.Fatal("a database should open in the browser and start reading its schema")
+	}
+	p.structured.Update(cmd().(structview.SQLiteMsg))
+	view := ansi.Strip(p.renderPreviewPane(20))
+	if !strings.Contains(view, "notes") || strings.Contains(view, "Binary file") {
+		t.Fatalf("preview did not list the table:\n%s", view)
+	}
+
+	// A live reload of the same database keeps the browser where it was.
+	before := p.structured
+	if cmd := p.applyPreviewResult(PreviewResult{IsBinary: true, TotalSize: info.Size()}); cmd != nil || p.structured != before {
+		t.Fatal("reloading the database rebuilt the browser")
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"time"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/marcus/sidecar/internal/inlineedit"
 	"github.com/marcus/sidecar/internal/mouse"
+	"github.com/marcus/sidecar/internal/plugin"
 	"github.com/marcus/sidecar/internal/tabs"
 	"github.com/marcus/sidecar/internal/tty"
 )
@@ -27,9 +29,10 @@ type FileTab struct {
 	IsPreview bool // Ephemeral preview tab, replaced on next j/k navigation
 
 	// Edit state (persisted when switching away from inline editor)
-	EditSession   string    // Tmux session name (empty if not in edit mode)
-	EditOrigMtime time.Time // Original file mtime when editing started
-	EditEditor    string    // Editor command used (vim, nano, etc.)
+	EditSession   string           // Tmux session name (empty if not in edit mode)
+	EditOrigMtime time.Time        // Original file mtime when editing started
+	EditEditor    string           // Editor command used (vim, nano, etc.)
+	EditNative    *inlineedit.Code // Built-in editor buffer (nil unless native edit)
 }
 
 type tabHit struct {
@@ -241,6 +244,9 @@ func (p *Plugin) closeTab(index int) tea.Cmd {
 	if index < 0 || index >= len(p.tabs) {
 		return nil
 	}
+	if p.tabHasUnsavedEdit(index) {
+		return p.confirmCloseTab(index)
+	}
 
 	// Kill any tmux session associated with this tab
 	p.killTabEditSession(index)
@@ -290,9 +296,9 @@ func (p *Plugin) applyActiveTab() tea.Cmd {
 	}
 
 	if tab.Loaded {
-		p.applyPreviewResult(tab.Result)
+		cmd := p.applyPreviewResult(tab.Result)
 		p.clampPreviewScroll()
-		return nil
+		return cmd
 	}
 
 	return LoadPreview(p.ctx.WorkDir, tab.Path, p.ctx.Epoch)
@@ -334,7 +340,9 @@ func (p *Plugin) syncTreeSelection(path string) {
 	}
 }
 
-func (p *Plugin) applyPreviewResult(result PreviewResult) {
+// applyPreviewResult installs loaded content. The returned command starts any
+// structured model that loads asynchronously.
+func (p *Plugin) applyPreviewResult(result PreviewResult) tea.Cmd {
 	p.selection.Clear()
 	p.previewLines = result.Lines
 	p.previewHighlighted = result.HighlightedLines
@@ -355,9 +363,28 @@ func (p *Plugin) applyPreviewResult(result PreviewResult) {
 	if p.markdownRenderMode && p.isMarkdownFile() {
 		p.renderMarkdownContent()
 	}
+
+	var streamCmd tea.Cmd
+	switch {
+	case !result.IsLarge:
+		p.closeStream()
+	case p.stream == nil || p.stream.path != p.streamedPath():
+		streamCmd = p.openStream()
+	}
+	return tea.Batch(streamCmd, p.buildStructured())
 }
 
 func (p *Plugin) clampPreviewScroll() {
+	if p.stream != nil {
+		// Until indexing finishes the line count is a lower bound, and a
+		// restored or navigated-to line past it is still valid.
+		p.previewScroll = max(p.previewScroll, 0)
+		if !p.stream.indexing {
+			p.previewScroll = min(p.previewScroll, p.streamMaxScroll())
+		}
+		p.saveActiveTabState()
+		return
+	}
 	lines := p.getPreviewLines()
 	visibleHeight := p.visibleContentHeight()
 	maxScroll := len(lines) - visibleHeight
@@ -390,6 +417,8 @@ func (p *Plugin) resetPreviewModes() {
 	p.blameModalWidth = 0
 	p.markdownRendered = nil
 	p.imageResult = nil
+	p.structured = nil
+	p.structuredErr = nil
 }
 
 func (p *Plugin) resetPreviewContent() {
@@ -402,6 +431,7 @@ func (p *Plugin) resetPreviewContent() {
 	p.previewModTime = time.Time{}
 	p.previewMode = 0
 	p.isImage = false
+	p.closeStream()
 }
 
 func (p *Plugin) renderPreviewTabs(width int) string {
@@ -504,10 +534,11 @@ func (p *Plugin) saveEditStateToTab() {
 	if len(p.tabs) == 0 || p.activeTab < 0 || p.activeTab >= len(p.tabs) {
 		return
 	}
-	if !p.edit.Active || p.edit.Name == "" {
+	if !p.edit.Active || (p.edit.Name == "" && p.edit.Native == nil) {
 		return
 	}
 	tab := &p.tabs[p.activeTab]
+	tab.EditNative = p.edit.Native
 	tab.EditSession = p.edit.Name
 	tab.EditOrigMtime = p.inlineEditOrigMtime
 	tab.EditEditor = p.edit.EditorCmd
@@ -523,6 +554,7 @@ func (p *Plugin) clearPluginEditState() {
 	p.edit.EditorCmd = ""
 	p.edit.Activation++
 	p.edit.Dragging = false
+	p.edit.Native = nil
 	p.edit.Model.Close()
 }
 
@@ -533,6 +565,14 @@ func (p *Plugin) restoreEditStateFromTab() bool {
 		return false
 	}
 	tab := &p.tabs[p.activeTab]
+	if tab.EditNative != nil {
+		// The built-in editor's buffer lives on the tab; nothing can die.
+		p.edit.Active = true
+		p.edit.Native = tab.EditNative
+		p.edit.Path = tab.Path
+		p.inlineEditOrigMtime = tab.EditOrigMtime
+		return true
+	}
 	if tab.EditSession == "" {
 		return false
 	}
@@ -553,6 +593,62 @@ func (p *Plugin) restoreEditStateFromTab() bool {
 	return true
 }
 
+// pendingCloseTab is the pending action of an exit confirmation opened by
+// closing a tab; its data is the tab's path.
+const pendingCloseTab = "close-tab"
+
+// tabHasUnsavedEdit reports a built-in editor buffer with unsaved edits on the
+// tab, whether it is the live editor or detached on the tab.
+func (p *Plugin) tabHasUnsavedEdit(index int) bool {
+	if index == p.activeTab && p.edit.Active && p.edit.Dirty() {
+		return true
+	}
+	native := p.tabs[index].EditNative
+	return native != nil && native.Dirty()
+}
+
+// confirmCloseTab brings the tab's editor back and asks whether to save,
+// discard or keep its edits. The tab closes once the editor is left.
+func (p *Plugin) confirmCloseTab(index int) tea.Cmd {
+	var cmd tea.Cmd
+	if index != p.activeTab {
+		p.saveActiveTabState()
+		if p.edit.Active {
+			p.saveEditStateToTab()
+			p.clearPluginEditState()
+		}
+		g := p.tabGroup()
+		g.Select(index)
+		p.applyTabGroup(g)
+		cmd = p.applyActiveTab()
+	} else if !p.edit.Active {
+		p.restoreEditStateFromTab()
+		p.activePane = PanePreview
+	}
+	p.edit.ShowExitConfirm = true
+	p.edit.ConfirmSelection = 0
+	p.edit.PendingClickRegion = pendingCloseTab
+	p.edit.PendingClickData = p.tabs[index].Path
+	return cmd
+}
+
+var _ plugin.UnsavedEditsReporter = (*Plugin)(nil)
+
+// UnsavedEdits lists the files with unsaved edits in a built-in editor, live
+// or detached on a tab, for the quit confirmation.
+func (p *Plugin) UnsavedEdits() []string {
+	var paths []string
+	if p.edit.Active && p.edit.Dirty() {
+		paths = append(paths, p.edit.Path)
+	}
+	for _, tab := range p.tabs {
+		if tab.EditNative != nil && tab.EditNative != p.edit.Native && tab.EditNative.Dirty() {
+			paths = append(paths, tab.Path)
+		}
+	}
+	return paths
+}
+
 // killTabEditSession kills the tmux session for a tab if it has one.
 func (p *Plugin) killTabEditSession(index int) {
 	if index < 0 || index >= len(p.tabs) {
@@ -563,6 +659,7 @@ func (p *Plugin) killTabEditSession(index int) {
 	tab.EditSession = ""
 	tab.EditOrigMtime = time.Time{}
 	tab.EditEditor = ""
+	tab.EditNative = nil
 	p.tabs[index] = tab
 }
 
@@ -714,6 +811,7 @@ func (p *Plugin) cleanupAllEditSessions() {
 			p.tabs[i].EditOrigMtime = time.Time{}
 			p.tabs[i].EditEditor = ""
 		}
+		p.tabs[i].EditNative = nil
 	}
 }
 
@@ -667,6 +667,51 @@ exit 0
 	}
 }
 
+// A native edit keeps its buffer on the tab, so the preview tab it starts in
+// is pinned: switching tabs would otherwise close it and drop the buffer.
+func TestNativeEditPinsItsTabAndConfirmsCloseWhenDirty(t *testing.T) {
+	tmpDir := t.TempDir()
+	p := createTabTestPlugin(t, tmpDir)
+	p.edit = inlineedit.Session{Model: tty.New(nil)}
+	_ = p.openTab("README.md", TabOpenNew)
+	_ = p.openTab("main.go", TabOpenPreview)
+	code, err := inlineedit.LoadCode(filepath.Join(tmpDir, "main.go"), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.handleInlineEditStarted(InlineEditStartedMsg{FilePath: "main.go", Activation: p.edit.Activation, Native: code})
+	if p.tabs[1].IsPreview {
+		t.Fatal("the edited tab should be pinned")
+	}
+	code.Paste("// edited\n")
+
+	// Detach by switching away; the buffer stays on its tab.
+	p.saveEditStateToTab()
+	p.clearPluginEditState()
+	_ = p.switchTab(0)
+	if len(p.tabs) != 2 || p.tabs[1].EditNative != code {
+		t.Fatalf("switching tabs lost the edit: %+v", tabPaths(p.tabs))
+	}
+	if got := p.UnsavedEdits(); len(got) != 1 || got[0] != "main.go" {
+		t.Fatalf("UnsavedEdits = %v", got)
+	}
+
+	// Closing it asks first, in its own editor.
+	_ = p.closeTab(1)
+	if len(p.tabs) != 2 || p.activeTab != 1 || !p.edit.ShowExitConfirm || p.edit.Native != code {
+		t.Fatalf("closing a dirty tab should confirm: tabs=%v active=%d confirm=%v",
+			tabPaths(p.tabs), p.activeTab, p.edit.ShowExitConfirm)
+	}
+	p.edit.ConfirmSelection = 1 // Exit without saving
+	p.handleExitConfirmationChoice()
+	if len(p.tabs) != 1 || p.tabs[0].Path != "README.md" || p.edit.Active {
+		t.Fatalf("discarding should close the tab: tabs=%v editing=%v", tabPaths(p.tabs), p.edit.Active)
+	}
+	if data, _ := os.ReadFile(filepath.Join(tmpDir, "main.go")); string(data) != "package main" {
+		t.Fatalf("discard wrote %q", data)
+	}
+}
+
 func closeTabsFixture(t *testing.T) *Plugin {
 	t.Helper()
 	p := createTabTestPlugin(t, t.TempDir())
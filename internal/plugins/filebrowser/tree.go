@@ -2,6 +2,7 @@ package filebrowser
 
 import (
 	"github.com/marcus/sidecar/internal/filefind"
+	"github.com/marcus/sidecar/internal/gitstate"
 	"os"
 	"path/filepath"
 	"sort"
@@ -62,6 +63,11 @@ type FileTree struct {
 	gitIgnore   *filefind.GitIgnore
 	SortMode    SortMode // Current sort mode
 	ShowIgnored bool     // Whether to include ignored files in FlatList
+	ChangedOnly bool     // Whether FlatList keeps only entries with git changes
+
+	// Status is the git status rows are decorated and filtered with, nil
+	// outside a repository or before the first status has loaded.
+	Status *gitstate.Snapshot
 }
 
 // NewFileTree creates a new file tree rooted at the given directory.
@@ -103,7 +109,9 @@ type BuildSpec struct {
 	RootDir       string
 	SortMode      SortMode
 	ShowIgnored   bool
-	ExpandedPaths map[string]bool // Directories to re-expand after loading
+	ChangedOnly   bool
+	Status        *gitstate.Snapshot // Shared and never modified, so safe to hand over
+	ExpandedPaths map[string]bool    // Directories to re-expand after loading
 }
 
 // BuildTree constructs a brand-new tree from spec. It shares no state with any
@@ -113,6 +121,8 @@ func BuildTree(spec BuildSpec) (*FileTree, error) {
 	t := NewFileTree(spec.RootDir)
 	t.SortMode = spec.SortMode
 	t.ShowIgnored = spec.ShowIgnored
+	t.ChangedOnly = spec.ChangedOnly
+	t.Status = spec.Status
 
 	if err := t.Build(); err != nil {
 		return nil, err
@@ -279,6 +289,9 @@ func (t *FileTree) flattenNode(node *FileNode) {
 		if !t.ShowIgnored && child.IsIgnored {
 			continue
 		}
+		if t.ChangedOnly && !t.GitState(child).Changed() {
+			continue
+		}
 		t.FlatList = append(t.FlatList, child)
 		if child.IsDir && child.IsExpanded {
 			t.flattenNode(child)
@@ -286,6 +299,30 @@ func (t *FileTree) flattenNode(node *FileNode) {
 	}
 }
 
+// GitState returns the git changes at node: its own for a file, and the union
+// of everything beneath it for a directory.
+func (t *FileTree) GitState(node *FileNode) gitstate.State {
+	if t.Status == nil || node == nil {
+		return 0
+	}
+	path := filepath.Join(t.RootDir, node.Path)
+	if node.IsDir {
+		// An untracked directory git did not descend into is reported as
+		// the directory itself.
+		return t.Status.Dir(path) | t.Status.File(path)
+	}
+	return t.Status.File(path)
+}
+
+// OutsideSparse reports whether node lies outside the worktree's sparse
+// checkout, where git neither checks files out nor stages them by default.
+func (t *FileTree) OutsideSparse(node *FileNode) bool {
+	if t.Status == nil || node == nil {
+		return false
+	}
+	return t.Status.OutsideSparse(filepath.Join(t.RootDir, node.Path), node.IsDir)
+}
+
 // GetNode returns the node at the given index, or nil if out of bounds.
 func (t *FileTree) GetNode(index int) *FileNode {
 	if index < 0 || index >= len(t.FlatList) {
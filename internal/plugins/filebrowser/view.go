@@ -129,6 +129,8 @@ func (p *Plugin) inputBarHeight() int {
 		// up by one row per suggestion, so a drop released on the row the user
 		// was looking at moved the file into a different directory entirely.
 		h += p.fileOpSuggestionRows()
+		// The rename preview and the restore list sit in the same place.
+		h += p.fileOpListRows()
 	}
 	if p.lineJumpMode {
 		h += inputBarRows
@@ -139,7 +141,7 @@ func (p *Plugin) inputBarHeight() int {
 // fileOpSuggestionRows is the number of screen rows the move dialog's path
 // suggestion dropdown occupies (0 when it is not showing).
 func (p *Plugin) fileOpSuggestionRows() int {
-	if p.fileOpMode != FileOpMove || !p.fileOpShowSuggestions {
+	if !p.fileOpTakesPath() || !p.fileOpShowSuggestions {
 		return 0
 	}
 	return len(p.fileOpSuggestions)
@@ -313,7 +315,7 @@ func (p *Plugin) renderNormalPanes() string {
 
 	// Use interactive gradient when in inline edit mode
 	var rightPane string
-	if p.edit.Active && p.edit.Model != nil && p.edit.Model.IsActive() {
+	if p.edit.Active && p.edit.IsModelActive() {
 		rightPane = styles.RenderPanelWithGradient(previewContent, p.previewWidth, paneHeight, styles.GetInteractiveGradient())
 	} else {
 		rightPane = styles.RenderPanel(previewContent, p.previewWidth, paneHeight, previewActive)
@@ -383,7 +385,7 @@ func (p *Plugin) renderNormalPanes() string {
 	}
 
 	// Register individual preview lines for text selection (LAST for highest priority)
-	if p.previewFile != "" && !p.isBinary && len(p.previewLines) > 0 {
+	if p.previewFile != "" && !p.isBinary && len(p.previewLines) > 0 && !p.showingStructured() {
 		previewContentStartY := paneY + 3 // border(1) + header(2 lines)
 		contentStart := p.previewScroll
 		contentEnd := contentStart + innerHeight
@@ -452,12 +454,19 @@ func (p *Plugin) renderTreeSearchBar() string {
 // renderFileOpBar renders the file operation input bar (move/rename/create/delete).
 func (p *Plugin) renderFileOpBar() string {
 	// Handle delete confirmation mode
+	if p.fileOpConfirmDelete && len(p.fileOpPaths) > 0 {
+		return p.renderFileOpConfirmation(fmt.Sprintf("Move %s to trash?", countNoun(len(p.fileOpPaths))))
+	}
 	if p.fileOpConfirmDelete && p.fileOpTarget != nil {
 		itemType := "file"
 		if p.fileOpTarget.IsDir {
 			itemType = "directory"
 		}
-		return p.renderFileOpConfirmation(fmt.Sprintf("Delete %s '%s'?", itemType, p.fileOpTarget.Name))
+		return p.renderFileOpConfirmation(fmt.Sprintf("Move %s '%s' to trash?", itemType, p.fileOpTarget.Name))
+	}
+
+	if p.fileOpMode == FileOpRestore {
+		return p.renderRestoreList()
 	}
 
 	// Handle confirmation mode for directory creation (during move)
@@ -475,6 +484,10 @@ func (p *Plugin) renderFileOpBar() string {
 		prompt = "New file: "
 	case FileOpCreateDir:
 		prompt = "New dir: "
+	case FileOpBulkMove:
+		prompt = fmt.Sprintf("Move %s to: ", countNoun(len(p.fileOpPaths)))
+	case FileOpBulkRename:
+		prompt = fmt.Sprintf("Rename %s: ", countNoun(len(p.fileOpPaths)))
 	default:
 		return ""
 	}
@@ -489,10 +502,14 @@ func (p *Plugin) renderFileOpBar() string {
 	}
 
 	// Show suggestion dropdown for move mode
-	if p.fileOpMode == FileOpMove && p.fileOpShowSuggestions && len(p.fileOpSuggestions) > 0 {
+	if p.fileOpTakesPath() && p.fileOpShowSuggestions && len(p.fileOpSuggestions) > 0 {
 		lines = append(lines, p.renderFileOpSuggestions())
 	}
 
+	if p.fileOpMode == FileOpBulkRename {
+		lines = append(lines, p.renderRenamePreview()...)
+	}
+
 	return lipgloss.JoinVertical(lipgloss.Left, lines...)
 }
 
@@ -540,6 +557,50 @@ func (p *Plugin) renderFileOpConfirmation(message string) string {
 	return styles.ModalTitle.Render(sb.String())
 }
 
+// renderRenamePreview renders what the batch rename pattern would do, one
+// "old → new" row per renamed node.
+func (p *Plugin) renderRenamePreview() []string {
+	var lines []string
+	for i, st := range p.renamePlan {
+		if i == renamePreviewMax {
+			lines = append(lines, styles.Muted.Render(fmt.Sprintf(" +%d more", len(p.renamePlan)-renamePreviewMax)))
+			break
+		}
+		line := " " + filepath.Base(st.From) + " → " + filepath.Base(st.To)
+		lines = append(lines, styles.Muted.Render(ansi.Truncate(line, max(p.width-1, 10), "…")))
+	}
+	return lines
+}
+
+// renderRestoreList renders the trash restore picker: a title line, then
+// one row per trashed file from this project, newest first.
+func (p *Plugin) renderRestoreList() string {
+	lines := []string{styles.ModalTitle.Render(" Restore from trash  enter restore · esc cancel")}
+	if p.fileOpError != "" {
+		lines = append(lines, styles.StatusDeleted.Render(" "+p.fileOpError))
+	}
+	topY := p.fileOpSuggestionsTopY()
+	start, items := p.restoreWindow()
+	for i, it := range items {
+		rel, err := filepath.Rel(p.ctx.WorkDir, it.Path)
+		if err != nil {
+			rel = it.Path
+		}
+		if it.IsDir {
+			rel += "/"
+		}
+		line := ansi.Truncate(" "+rel+"  "+RelativeTime(it.DeletedAt), max(p.width-1, 10), "…")
+		if start+i == p.restoreCursor {
+			line = styles.ListItemSelected.Render(line)
+		} else {
+			line = styles.Muted.Render(line)
+		}
+		lines = append(lines, line)
+		p.mouseHandler.HitMap.AddRect(regionFileOpRestoreItem, 0, topY+i, p.width, 1, start+i)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
 // renderFileOpSuggestions renders the path suggestion dropdown.
 func (p *Plugin) renderFileOpSuggestions() string {
 	var lines []string
@@ -581,6 +642,18 @@ func (p *Plugin) renderTreePane(visibleHeight int) string {
 			sb.WriteString(" ")
 			sb.WriteString(styles.Muted.Render("[ignored: hidden]"))
 		}
+		if p.changedOnly {
+			sb.WriteString(" ")
+			sb.WriteString(styles.Muted.Render("[changed only]"))
+		}
+		if p.hasSelection() {
+			label := fmt.Sprintf("[%d selected]", len(p.selectedPaths()))
+			if p.visualAnchor != "" {
+				label = fmt.Sprintf("[visual: %d]", len(p.selectedPaths()))
+			}
+			sb.WriteString(" ")
+			sb.WriteString(styles.StatusInProgress.Render(label))
+		}
 	}
 	sb.WriteString("\n")
 
@@ -625,6 +698,7 @@ func (p *Plugin) renderTreePane(visibleHeight int) string {
 	}
 
 	maxWidth := treeNodeWidth(p.treeWidth)
+	lo, hi, visual := p.visualRange()
 
 	var treeSB strings.Builder
 	for i := p.treeScrollOff; i < end; i++ {
@@ -634,7 +708,7 @@ func (p *Plugin) renderTreePane(visibleHeight int) string {
 		}
 
 		selected := i == p.treeCursor
-		line := p.renderTreeNode(node, selected, maxWidth)
+		line := p.renderTreeRow(node, selected, p.rowSelected(node, i, lo, hi, visual), maxWidth)
 
 		treeSB.WriteString(line)
 		// Don't add newline after last line
@@ -777,6 +851,12 @@ func treeColumn(rendered string, width int) string {
 
 // renderTreeNode renders a single tree node.
 func (p *Plugin) renderTreeNode(node *FileNode, selected bool, maxWidth int) string {
+	return p.renderTreeRow(node, selected, p.marked[node.Path], maxWidth)
+}
+
+// renderTreeRow renders one tree row. marked paints the multi-select
+// highlight under the row's own colors; the cursor highlight wins over it.
+func (p *Plugin) renderTreeRow(node *FileNode, selected, marked bool, maxWidth int) string {
 	// Indentation
 	indent := strings.Repeat("  ", node.Depth)
 
@@ -794,8 +874,18 @@ func (p *Plugin) renderTreeNode(node *FileNode, selected bool, maxWidth int) str
 	// Widths are measured in display cells, not bytes: a CJK or emoji filename
 	// is wider than its byte length suggests, and slicing it by bytes cuts
 	// mid-rune.
+	// The git status mark sits in the row's last cell, with a space before it.
+	mark, markStyle := "", lipgloss.Style{}
+	if p.tree != nil {
+		mark, markStyle = gitMarker(p.tree.GitState(node), p.tree.OutsideSparse(node), node)
+	}
+	markWidth := 0
+	if mark != "" {
+		markWidth = 2
+	}
+
 	prefixLen := ansi.StringWidth(indent) + ansi.StringWidth(icon)
-	availableWidth := maxWidth - prefixLen
+	availableWidth := maxWidth - prefixLen - markWidth
 	if availableWidth < 3 {
 		availableWidth = 3
 	}
@@ -806,21 +896,29 @@ func (p *Plugin) renderTreeNode(node *FileNode, selected bool, maxWidth int) str
 		displayName = ansi.Truncate(displayName, availableWidth, "…")
 	}
 
-	// Name styling
+	// Name styling. A changed file takes its mark's color, as it does in
+	// the git status plugin.
 	var name string
-	if node.IsDir {
-		name = styles.FileBrowserDir.Render(displayName)
-	} else if node.IsIgnored {
+	if node.IsIgnored {
 		name = styles.FileBrowserIgnored.Render(displayName)
+	} else if node.IsDir {
+		name = styles.FileBrowserDir.Render(displayName)
+	} else if mark != "" {
+		name = markStyle.Render(displayName)
 	} else {
 		name = styles.FileBrowserFile.Render(displayName)
 	}
 
 	line := fmt.Sprintf("%s%s%s", indent, styles.FileBrowserIcon.Render(icon), name)
+	markPad := ""
+	if mark != "" {
+		markPad = strings.Repeat(" ", max(maxWidth-ansi.StringWidth(indent+icon+displayName)-1, 1))
+		line += markPad + markStyle.Render(mark)
+	}
 
 	// Plain text version, padded, for any full-width highlight.
 	fullWidth := func() string {
-		plainLine := indent + icon + displayName
+		plainLine := indent + icon + displayName + markPad + mark
 		if w := ansi.StringWidth(plainLine); w < maxWidth {
 			plainLine += strings.Repeat(" ", maxWidth-w)
 		}
@@ -852,6 +950,12 @@ func (p *Plugin) renderTreeNode(node *FileNode, selected bool, maxWidth int) str
 	if selected {
 		return styles.ListItemSelected.Render(fullWidth())
 	}
+	if marked {
+		if w := ansi.StringWidth(line); w < maxWidth {
+			line += strings.Repeat(" ", maxWidth-w)
+		}
+		return ui.InjectSelectionBackground(line)
+	}
 	return line
 }
 
@@ -862,7 +966,7 @@ func (p *Plugin) renderPreviewPane(visibleHeight int) string {
 	p.bars[sbPreview] = scrollbarBar{}
 
 	// Handle inline edit mode - render editor within preview pane
-	if p.edit.Active && p.edit.Model != nil && p.edit.Model.IsActive() {
+	if p.edit.Active && p.edit.IsModelActive() {
 		return p.renderInlineEditorContent(visibleHeight)
 	}
 	// The caller passes the panel's full inner height. Source rows begin only
@@ -892,6 +996,9 @@ func (p *Plugin) renderPreviewPane(visibleHeight int) string {
 		if p.isMarkdownFile() && p.markdownRenderMode {
 			header += " [rendered]"
 		}
+		if p.showingStructured() {
+			header += " [" + p.structured.Label() + "]"
+		}
 	}
 	sb.WriteString(styles.Title.Render(header))
 
@@ -927,11 +1034,21 @@ func (p *Plugin) renderPreviewPane(visibleHeight int) string {
 		return sb.String()
 	}
 
+	if p.showingStructured() {
+		sb.WriteString(p.structured.View(p.previewContentWidth(), visibleHeight))
+		return sb.String()
+	}
+
 	if p.isBinary {
 		sb.WriteString(styles.Muted.Render("Binary file"))
 		return sb.String()
 	}
 
+	if p.stream != nil {
+		sb.WriteString(p.renderStream(visibleHeight))
+		return sb.String()
+	}
+
 	// Determine which lines to display
 	var lines []string
 	showLineNumbers := true
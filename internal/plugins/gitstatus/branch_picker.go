@@ -1,6 +1,7 @@
 package gitstatus
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -11,6 +12,7 @@ import (
 	"github.com/marcus/sidecar/internal/plugin"
 	"github.com/marcus/sidecar/internal/styles"
 	"github.com/marcus/sidecar/internal/ui"
+	"github.com/marcus/sidecar/internal/undojournal"
 )
 
 const (
@@ -89,7 +91,9 @@ func (p *Plugin) doSwitchBranch(branchName string) tea.Cmd {
 	workDir := p.repoRoot
 	epoch := p.currentEpoch()
 	return func() tea.Msg {
+		record := undojournal.Begin(context.Background(), workDir, "switch to "+branchName, undojournal.Options{Head: true})
 		err := CheckoutBranch(workDir, branchName)
+		record(err)
 		if err != nil {
 			return BranchErrorMsg{Epoch: epoch, Err: err}
 		}
@@ -139,7 +139,7 @@ func (p *Plugin) diffTextReady() bool {
 		return full != nil && len(full.Lines) > 0
 	}
 	if parsed != nil {
-		return !parsed.Binary && len(parsed.Hunks) > 0
+		return !parsed.Binary && parsed.LFS == nil && len(parsed.Hunks) > 0
 	}
 	return p.viewMode == ViewModeDiff && strings.TrimSpace(raw) != ""
 }
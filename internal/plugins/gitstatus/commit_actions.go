@@ -0,0 +1,570 @@
+package gitstatus
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/marcus/sidecar/internal/app"
+	"github.com/marcus/sidecar/internal/modal"
+	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/styles"
+	"github.com/marcus/sidecar/internal/ui"
+	"github.com/marcus/sidecar/internal/undojournal"
+)
+
+const (
+	commitActionsListID     = "commit-actions-list"
+	commitActionsPrimaryID  = "commit-actions-action"
+	commitActionPickPrefix  = "commit-action-pick:"
+	commitActionRevert      = "commit-action-revert"
+	commitActionBranch      = "commit-action-branch"
+	commitActionTag         = "commit-action-tag"
+	commitActionResetPrefix = "commit-action-reset:"
+
+	commitRefInputID  = "commit-ref-name"
+	commitRefActionID = "commit-ref-action"
+	commitRefCreateID = "commit-ref-create"
+	commitRefCancelID = "commit-ref-cancel"
+
+	commitResetConfirmID = "commit-reset-confirm"
+	commitResetCancelID  = "commit-reset-cancel"
+)
+
+// commitActionStage is the modal the commit actions flow is showing.
+type commitActionStage int
+
+const (
+	commitActionsMenu  commitActionStage = iota // Action list
+	commitActionsRef                            // Branch or tag name prompt
+	commitActionsReset                          // Reset confirmation
+)
+
+// commitActionState is the state of the commit actions modals, opened on a
+// commit in the history list. Every action it starts is a serialized write.
+type commitActionState struct {
+	commit     *Commit
+	stage      commitActionStage
+	returnMode ViewMode
+
+	// Other worktrees' branches the commit can be cherry-picked onto
+	targets       []app.WorktreeInfo
+	targetsLoaded bool
+
+	menuFocus  int
+	menuModal  *modal.Modal
+	menuWidth  int
+	refKind    operationKind // operationBranch or operationTag
+	refInput   textinput.Model
+	refError   string
+	refModal   *modal.Modal
+	resetMode  string // "soft", "mixed" or "hard"
+	resetModal *modal.Modal
+}
+
+// CherryPickTargetsLoadedMsg delivers the worktrees a commit can be
+// cherry-picked onto.
+type CherryPickTargetsLoadedMsg struct {
+	Epoch   uint64
+	Hash    string
+	Targets []app.WorktreeInfo
+}
+
+func (m CherryPickTargetsLoadedMsg) GetEpoch() uint64 { return m.Epoch }
+
+// openCommitActions opens the action menu on the selected commit.
+func (p *Plugin) openCommitActions() tea.Cmd {
+	if !p.hasSelectedCommit() {
+		return nil
+	}
+	if p.writeInProgress() {
+		return p.writeBusyToast()
+	}
+	commit := p.activeCommits()[p.selectedCommitIndex()]
+	p.commitActions = &commitActionState{commit: commit, returnMode: p.viewMode}
+	p.viewMode = ViewModeCommitActions
+	return p.loadCherryPickTargets(commit.Hash)
+}
+
+func (p *Plugin) closeCommitActions() {
+	returnMode := ViewModeStatus
+	if p.commitActions != nil {
+		returnMode = p.commitActions.returnMode
+	}
+	p.commitActions = nil
+	p.viewMode = returnMode
+}
+
+// loadCherryPickTargets lists the other worktrees of the repository that
+// have a branch checked out. The commit history is HEAD's, so the current
+// worktree already contains every commit in it.
+func (p *Plugin) loadCherryPickTargets(hash string) tea.Cmd {
+	repoRoot := p.repoRoot
+	epoch := p.currentEpoch()
+	return func() tea.Msg {
+		var targets []app.WorktreeInfo
+		for _, wt := range app.GetWorktrees(repoRoot) {
+			if wt.Branch != "" && !sameDir(wt.Path, repoRoot) {
+				targets = append(targets, wt)
+			}
+		}
+		return CherryPickTargetsLoadedMsg{Epoch: epoch, Hash: hash, Targets: targets}
+	}
+}
+
+func sameDir(a, b string) bool {
+	if ra, err := filepath.EvalSymlinks(a); err == nil {
+		a = ra
+	}
+	if rb, err := filepath.EvalSymlinks(b); err == nil {
+		b = rb
+	}
+	return filepath.Clean(a) == filepath.Clean(b)
+}
+
+func (p *Plugin) handleCherryPickTargets(msg CherryPickTargetsLoadedMsg) {
+	s := p.commitActions
+	if s == nil || s.commit.Hash != msg.Hash {
+		return
+	}
+	s.targets = msg.Targets
+	s.targetsLoaded = true
+	s.menuFocus = 0
+	s.menuModal = nil
+}
+
+// commitActionItems lists the menu: one cherry-pick entry per target, then
+// the actions on the current branch.
+func (p *Plugin) commitActionItems() []modal.ListItem {
+	s := p.commitActions
+	var items []modal.ListItem
+	for i, t := range s.targets {
+		items = append(items, modal.ListItem{
+			ID:    commitActionPickPrefix + strconv.Itoa(i),
+			Label: fmt.Sprintf("Cherry-pick onto %s (%s)", t.Branch, filepath.Base(t.Path)),
+		})
+	}
+	return append(items,
+		modal.ListItem{ID: commitActionRevert, Label: "Revert in a new commit"},
+		modal.ListItem{ID: commitActionBranch, Label: "Create branch here…"},
+		modal.ListItem{ID: commitActionTag, Label: "Create tag here…"},
+		modal.ListItem{ID: commitActionResetPrefix + "soft", Label: "Reset --soft to here"},
+		modal.ListItem{ID: commitActionResetPrefix + "mixed", Label: "Reset --mixed to here"},
+		modal.ListItem{ID: commitActionResetPrefix + "hard", Label: "Reset --hard to here"},
+	)
+}
+
+func (p *Plugin) ensureCommitActionsModal() {
+	s := p.commitActions
+	modalW := min(max(ui.ModalWidthMedium, 56), p.width-4)
+	modalW = max(modalW, 20)
+	if s.menuModal != nil && s.menuWidth == modalW {
+		return
+	}
+	s.menuWidth = modalW
+
+	items := p.commitActionItems()
+	s.menuFocus = min(s.menuFocus, len(items)-1)
+	title := "Commit " + s.commit.ShortHash
+	s.menuModal = modal.New(title,
+		modal.WithWidth(modalW),
+		modal.WithPrimaryAction(commitActionsPrimaryID),
+		modal.WithHints(false),
+	).
+		AddSection(modal.Text(styles.Muted.Render(truncateLine(s.commit.Subject, modalW-4)))).
+		AddSection(modal.When(func() bool { return !s.targetsLoaded }, modal.Text(styles.Muted.Render("Looking for other worktrees…")))).
+		AddSection(modal.Spacer()).
+		AddSection(modal.List(commitActionsListID, items, &s.menuFocus, modal.WithMaxVisible(len(items)))).
+		AddSection(modal.Spacer()).
+		AddSection(modal.Text(styles.Muted.Render("c/v/b/t · s/m/h reset · Enter to select · Esc to cancel")))
+}
+
+// updateCommitActions handles keys in whichever commit actions modal is open.
+func (p *Plugin) updateCommitActions(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd) {
+	s := p.commitActions
+	if s == nil {
+		p.viewMode = ViewModeStatus
+		return p, nil
+	}
+	switch s.stage {
+	case commitActionsRef:
+		return p, p.updateCommitRef(msg)
+	case commitActionsReset:
+		return p, p.updateConfirmReset(msg)
+	}
+
+	p.ensureCommitActionsModal()
+	switch msg.String() {
+	case "esc", "q":
+		p.closeCommitActions()
+		return p, nil
+	case "c":
+		if !s.targetsLoaded {
+			return p, app.ShowFlash("Still looking for other worktrees…")
+		}
+		switch len(s.targets) {
+		case 0:
+			return p, app.ShowFlash("No other worktree has a branch to cherry-pick onto")
+		case 1:
+			return p, p.runCommitAction(commitActionPickPrefix + "0")
+		}
+		s.menuFocus = 0 // Targets are listed first
+		return p, nil
+	case "v":
+		return p, p.runCommitAction(commitActionRevert)
+	case "b":
+		return p, p.runCommitAction(commitActionBranch)
+	case "t":
+		return p, p.runCommitAction(commitActionTag)
+	case "s":
+		return p, p.runCommitAction(commitActionResetPrefix + "soft")
+	case "m":
+		return p, p.runCommitAction(commitActionResetPrefix + "mixed")
+	case "h":
+		return p, p.runCommitAction(commitActionResetPrefix + "hard")
+	}
+
+	action, cmd := s.menuModal.HandleKey(msg)
+	switch action {
+	case "":
+		return p, cmd
+	case "cancel":
+		p.closeCommitActions()
+		return p, nil
+	case commitActionsPrimaryID:
+		items := p.commitActionItems()
+		if s.menuFocus < 0 || s.menuFocus >= len(items) {
+			return p, nil
+		}
+		return p, p.runCommitAction(items[s.menuFocus].ID)
+	}
+	return p, p.runCommitAction(action)
+}
+
+// runCommitAction starts a menu action: cherry-pick and revert run at once,
+// the others open their prompt.
+func (p *Plugin) runCommitAction(id string) tea.Cmd {
+	s := p.commitActions
+	c := s.commit
+	switch {
+	case strings.HasPrefix(id, commitActionPickPrefix):
+		i, err := strconv.Atoi(strings.TrimPrefix(id, commitActionPickPrefix))
+		if err != nil || i < 0 || i >= len(s.targets) {
+			return nil
+		}
+		if p.writeInProgress() {
+			return p.writeBusyToast()
+		}
+		target := s.targets[i]
+		p.closeCommitActions()
+		return p.startWrite(operationRequest{
+			Kind:            operationCherryPick,
+			Args:            []string{"cherry-pick", c.Hash},
+			WorkDir:         target.Path,
+			Done:            fmt.Sprintf("Cherry-picked %s onto %s", c.ShortHash, target.Branch),
+			AbortOnConflict: true,
+			Undo:            &undojournal.Options{Head: true},
+		}, selectionIdentity{})
+	case id == commitActionRevert:
+		if p.writeInProgress() {
+			return p.writeBusyToast()
+		}
+		args := []string{"revert", "--no-edit"}
+		if len(c.ParentHashes) > 1 {
+			args = append(args, "-m", "1") // Revert a merge against its first parent
+		}
+		p.closeCommitActions()
+		return p.startWrite(operationRequest{
+			Kind: operationRevert,
+			Args: append(args, c.Hash),
+			Done: "Reverted " + c.ShortHash,
+			Undo: &undojournal.Options{Head: true},
+		}, selectionIdentity{})
+	case id == commitActionBranch:
+		p.openCommitRef(operationBranch)
+	case id == commitActionTag:
+		p.openCommitRef(operationTag)
+	case strings.HasPrefix(id, commitActionResetPrefix):
+		s.resetMode = strings.TrimPrefix(id, commitActionResetPrefix)
+		s.resetModal = nil
+		s.stage = commitActionsReset
+	}
+	return nil
+}
+
+// openCommitRef prompts for the name of a branch or tag at the commit.
+func (p *Plugin) openCommitRef(kind operationKind) {
+	s := p.commitActions
+	s.stage = commitActionsRef
+	s.refKind = kind
+	s.refInput = textinput.New()
+	s.refInput.CharLimit = 200
+	s.refInput.SetWidth(36)
+	s.refInput.Prompt = ""
+	s.refError = ""
+	s.refModal = nil
+}
+
+func (p *Plugin) ensureCommitRefModal() {
+	s := p.commitActions
+	if s.refModal != nil {
+		return
+	}
+	title := "Create Branch"
+	if s.refKind == operationTag {
+		title = "Create Tag"
+	}
+	modalW := max(min(56, p.width-4), 20)
+	s.refModal = modal.New(title,
+		modal.WithWidth(modalW),
+		modal.WithPrimaryAction(commitRefActionID),
+		modal.WithInitialFocus(commitRefInputID),
+		modal.WithHints(false),
+	).
+		AddSection(modal.Text("At " + styles.Subtitle.Render(s.commit.ShortHash) + " " + truncateLine(s.commit.Subject, modalW-16))).
+		AddSection(modal.Spacer()).
+		AddSection(modal.InputWithLabel(commitRefInputID, "Name:", &s.refInput)).
+		AddSection(modal.When(func() bool { return s.refError != "" }, modal.Custom(func(int, string, string) modal.RenderedSection {
+			return modal.RenderedSection{Content: lipgloss.NewStyle().Foreground(styles.Error).Render("Error: " + s.refError)}
+		}, nil))).
+		AddSection(modal.Spacer()).
+		AddSection(modal.Buttons(
+			modal.Btn(" Create ", commitRefCreateID),
+			modal.Btn(" Cancel ", commitRefCancelID),
+		))
+}
+
+func (p *Plugin) updateCommitRef(msg tea.KeyPressMsg) tea.Cmd {
+	s := p.commitActions
+	p.ensureCommitRefModal()
+	if s.refModal.FocusedID() == commitRefInputID {
+		s.refError = ""
+	}
+	action, cmd := s.refModal.HandleKey(msg)
+	switch action {
+	case "cancel", commitRefCancelID:
+		s.stage = commitActionsMenu
+		return nil
+	case commitRefActionID, commitRefCreateID:
+		return p.createCommitRef()
+	}
+	return cmd
+}
+
+// createCommitRef creates the branch or tag; neither is checked out.
+func (p *Plugin) createCommitRef() tea.Cmd {
+	s := p.commitActions
+	name := strings.TrimSpace(s.refInput.Value())
+	if err := validateRefName(name); err != "" {
+		s.refError = err
+		return nil
+	}
+	if p.writeInProgress() {
+		return p.writeBusyToast()
+	}
+	req := operationRequest{
+		Kind: operationBranch,
+		Args: []string{"branch", "--", name, s.commit.Hash},
+		Done: fmt.Sprintf("Created branch %s at %s", name, s.commit.ShortHash),
+		Undo: &undojournal.Options{Refs: []string{"refs/heads/" + name}},
+	}
+	if s.refKind == operationTag {
+		req.Kind = operationTag
+		req.Args = []string{"tag", "--", name, s.commit.Hash}
+		req.Done = fmt.Sprintf("Tagged %s as %s", s.commit.ShortHash, name)
+		req.Undo = &undojournal.Options{Refs: []string{"refs/tags/" + name}}
+	}
+	p.closeCommitActions()
+	return p.startWrite(req, selectionIdentity{})
+}
+
+// validateRefName rejects the names git would, for the cases worth a message
+// before the write starts. Git remains the authority.
+func validateRefName(name string) string {
+	switch {
+	case name == "":
+		return "name is required"
+	case strings.HasPrefix(name, "-"):
+		return "name cannot start with -"
+	case strings.ContainsAny(name, " \t~^:?*[\\"):
+		return "name cannot contain spaces or ~^:?*[\\"
+	case strings.Contains(name, ".."), strings.HasSuffix(name, "."), strings.HasSuffix(name, ".lock"):
+		return "name is not a valid ref"
+	}
+	return ""
+}
+
+// resetSummary describes what resetting to the commit drops from the branch.
+func (p *Plugin) resetSummary() (dropped, pushed int, known bool) {
+	hash := p.commitActions.commit.Hash
+	for i, c := range p.recentCommits {
+		if c.Hash == hash {
+			for _, newer := range p.recentCommits[:i] {
+				if newer.Pushed {
+					pushed++
+				}
+			}
+			return i, pushed, true
+		}
+	}
+	return 0, 0, false
+}
+
+func (p *Plugin) ensureResetModal() {
+	s := p.commitActions
+	if s.resetModal != nil {
+		return
+	}
+	branch := "HEAD"
+	if p.pushStatus != nil && p.pushStatus.CurrentBranch != "" {
+		branch = p.pushStatus.CurrentBranch
+	}
+	modalW := max(min(60, p.width-10), 20)
+
+	sections := []modal.Section{
+		modal.Text(fmt.Sprintf("Move %s to %s", styles.Subtitle.Render(branch), styles.Subtitle.Render(s.commit.ShortHash))),
+		modal.Text(styles.Muted.Render(truncateLine(s.commit.Subject, modalW-4))),
+		modal.Spacer(),
+	}
+	dropped, pushed, known := p.resetSummary()
+	switch {
+	case !known:
+		sections = append(sections, modal.Text("Every commit after it leaves the branch."))
+	case dropped == 0:
+		sections = append(sections, modal.Text("No commits leave the branch."))
+	default:
+		sections = append(sections, modal.Text(fmt.Sprintf("%d newer commit(s) leave the branch.", dropped)))
+	}
+	if pushed > 0 {
+		warn := lipgloss.NewStyle().Foreground(styles.Warning).Bold(true).Render("Warning: ")
+		sections = append(sections, modal.Text(warn+fmt.Sprintf("%d of them are already pushed.", pushed)))
+	}
+	switch s.resetMode {
+	case "soft":
+		sections = append(sections, modal.Text(styles.Muted.Render("Their changes stay staged.")))
+	case "mixed":
+		sections = append(sections, modal.Text(styles.Muted.Render("Their changes stay in the working tree, unstaged.")))
+	case "hard":
+		sections = append(sections, modal.Text(styles.StatusDeleted.Render("Their changes and all uncommitted changes are discarded!")))
+	}
+	sections = append(sections,
+		modal.Spacer(),
+		modal.Buttons(
+			modal.Btn(" Reset ", commitResetConfirmID, modal.BtnDanger()),
+			modal.Btn(" Cancel ", commitResetCancelID),
+		),
+	)
+
+	opts := []modal.Option{modal.WithWidth(modalW)}
+	if s.resetMode == "hard" {
+		opts = append(opts, modal.WithVariant(modal.VariantDanger))
+	}
+	m := modal.New("Reset --"+s.resetMode, opts...)
+	for _, section := range sections {
+		m = m.AddSection(section)
+	}
+	s.resetModal = m
+}
+
+func (p *Plugin) updateConfirmReset(msg tea.KeyPressMsg) tea.Cmd {
+	s := p.commitActions
+	p.ensureResetModal()
+	if msg.String() == "y" {
+		return p.confirmReset()
+	}
+	action, cmd := s.resetModal.HandleKey(msg)
+	switch action {
+	case commitResetConfirmID:
+		return p.confirmReset()
+	case "cancel", commitResetCancelID:
+		s.stage = commitActionsMenu
+		return nil
+	}
+	return cmd
+}
+
+func (p *Plugin) confirmReset() tea.Cmd {
+	if p.writeInProgress() {
+		return p.writeBusyToast()
+	}
+	s := p.commitActions
+	req := operationRequest{
+		Kind: operationReset,
+		Args: []string{"reset", "--" + s.resetMode, s.commit.Hash},
+		Done: fmt.Sprintf("Reset (%s) to %s", s.resetMode, s.commit.ShortHash),
+		Undo: &undojournal.Options{Head: true, Index: true},
+	}
+	if s.resetMode == "hard" {
+		// A hard reset also overwrites uncommitted edits to tracked files.
+		req.Undo.Files = p.trackedChangePaths()
+	}
+	p.closeCommitActions()
+	return p.startWrite(req, selectionIdentity{})
+}
+
+// activeCommitActionsModal returns the modal of the current stage.
+func (p *Plugin) activeCommitActionsModal() *modal.Modal {
+	s := p.commitActions
+	if s == nil {
+		return nil
+	}
+	switch s.stage {
+	case commitActionsRef:
+		p.ensureCommitRefModal()
+		return s.refModal
+	case commitActionsReset:
+		p.ensureResetModal()
+		return s.resetModal
+	}
+	p.ensureCommitActionsModal()
+	return s.menuModal
+}
+
+// renderCommitActions overlays the current stage's modal on the status view.
+func (p *Plugin) renderCommitActions() string {
+	background := p.renderThreePaneView()
+	m := p.activeCommitActionsModal()
+	if m == nil {
+		return background
+	}
+	return ui.OverlayModal(background, m.Render(p.width, p.height, p.mouseHandler), p.width, p.height)
+}
+
+// handleCommitActionsMouse routes clicks to the current stage's modal.
+func (p *Plugin) handleCommitActionsMouse(msg tea.MouseMsg) (plugin.Plugin, tea.Cmd) {
+	m := p.activeCommitActionsModal()
+	if m == nil {
+		return p, nil
+	}
+	s := p.commitActions
+	action := m.HandleMouse(msg, p.mouseHandler)
+	switch s.stage {
+	case commitActionsRef:
+		switch action {
+		case commitRefCreateID:
+			return p, p.createCommitRef()
+		case commitRefCancelID:
+			s.stage = commitActionsMenu
+		}
+	case commitActionsReset:
+		switch action {
+		case commitResetConfirmID:
+			return p, p.confirmReset()
+		case commitResetCancelID:
+			s.stage = commitActionsMenu
+		}
+	default:
+		switch action {
+		case "", commitActionsListID:
+		case "cancel":
+			p.closeCommitActions()
+		default:
+			return p, p.runCommitAction(action)
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,166 @@
+package gitstatus
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+)
+
+// openActionsOn opens the commit actions menu on the i-th recent commit and
+// applies the worktree lookup it starts.
+func openActionsOn(t *testing.T, p *Plugin, i int) {
+	t.Helper()
+	p.cursor = len(p.tree.AllEntries()) + i
+	_, cmd := p.Update(rebaseKey('a'))
+	if p.viewMode != ViewModeCommitActions || p.FocusContext() != "git-commit-actions" {
+		t.Fatalf("a should open the commit actions, mode %v", p.viewMode)
+	}
+	targets, ok := cmd().(CherryPickTargetsLoadedMsg)
+	if !ok {
+		t.Fatalf("expected the worktree lookup, got %T", targets)
+	}
+	p.Update(targets)
+}
+
+// runWrite executes the write a command started and applies its result.
+func runWrite(t *testing.T, p *Plugin, cmd tea.Cmd) operationResultMsg {
+	t.Helper()
+	if cmd == nil || p.activeOperation == nil {
+		t.Fatal("no write was started")
+	}
+	result, ok := cmd().(operationResultMsg)
+	if !ok {
+		t.Fatalf("expected an operation result, got %T", result)
+	}
+	p.Update(result)
+	if p.activeOperation != nil {
+		t.Fatal("the result did not finish the operation")
+	}
+	return result
+}
+
+func TestCommitActionsBranchTagRevertReset(t *testing.T) {
+	repo := rebaseRepo(t)
+	p := rebasePlugin(t, repo)
+	addB := p.recentCommits[1]
+
+	// Branch and tag at "add b" through the name prompt.
+	for _, kind := range []rune{'b', 't'} {
+		openActionsOn(t, p, 1)
+		p.Update(rebaseKey(kind))
+		if p.FocusContext() != "git-commit-ref" || !p.ConsumesTextInput() {
+			t.Fatalf("%c should prompt for a name, context %s", kind, p.FocusContext())
+		}
+		p.commitActions.refInput.SetValue("bad name")
+		if _, cmd := p.Update(tea.KeyPressMsg{Code: tea.KeyEnter}); cmd != nil || p.commitActions.refError == "" {
+			t.Fatal("a name with a space should be refused before git runs")
+		}
+		p.commitActions.refInput.SetValue("at-b")
+		_, cmd := p.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+		if result := runWrite(t, p, cmd); result.Err != nil {
+			t.Fatal(result.Err)
+		}
+	}
+	if got := strings.TrimSpace(runGitTest(t, repo, "rev-parse", "refs/heads/at-b", "refs/tags/at-b")); got != addB.Hash+"\n"+addB.Hash {
+		t.Fatalf("branch and tag should point at %s:\n%s", addB.Hash, got)
+	}
+
+	// Revert "add c": a new commit removes c.txt.
+	openActionsOn(t, p, 0)
+	_, cmd := p.Update(rebaseKey('v'))
+	if p.viewMode != ViewModeStatus {
+		t.Fatalf("revert should close the menu, mode %v", p.viewMode)
+	}
+	if result := runWrite(t, p, cmd); result.Err != nil || result.Done != "Reverted "+p.recentCommits[0].ShortHash {
+		t.Fatalf("revert result = %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "c.txt")); !os.IsNotExist(err) {
+		t.Fatal("revert should have removed c.txt")
+	}
+
+	// Reset --hard to "add b" asks first and counts what leaves the branch.
+	commits, _, err := GetCommitHistoryWithPushStatus(repo, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.recentCommits = commits
+	openActionsOn(t, p, 2)
+	p.Update(rebaseKey('h'))
+	if p.FocusContext() != "git-confirm-reset" {
+		t.Fatalf("reset should ask for confirmation, context %s", p.FocusContext())
+	}
+	view := p.renderCommitActions()
+	for _, want := range []string{"Reset --hard", "2 newer commit(s) leave the branch", "discarded"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("confirmation is missing %q:\n%s", want, view)
+		}
+	}
+	p.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if p.viewMode != ViewModeCommitActions || p.commitActions.stage != commitActionsMenu {
+		t.Fatal("esc should step back to the menu")
+	}
+	p.Update(rebaseKey('h'))
+	_, cmd = p.Update(rebaseKey('y'))
+	if result := runWrite(t, p, cmd); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+	if head := strings.TrimSpace(runGitTest(t, repo, "rev-parse", "HEAD")); head != addB.Hash {
+		t.Fatalf("HEAD = %s, want %s", head, addB.Hash)
+	}
+}
+
+func TestCherryPickOntoAnotherWorktree(t *testing.T) {
+	repo := rebaseRepo(t)
+	other := filepath.Join(t.TempDir(), "other")
+	runGitTest(t, repo, "worktree", "add", "-q", "-b", "other", other, "HEAD~2")
+	p := rebasePlugin(t, repo)
+
+	// Until the lookup lands, "c" says so rather than that there is no target.
+	p.cursor = len(p.tree.AllEntries())
+	p.Update(rebaseKey('a'))
+	if _, cmd := p.Update(rebaseKey('c')); cmd == nil || p.activeOperation != nil {
+		t.Fatal("c before the targets load should flash and start nothing")
+	} else if flash, ok := cmd().(appmsg.FlashMsg); !ok || !strings.Contains(flash.Text, "Still looking") {
+		t.Fatalf("flash = %+v", flash)
+	}
+	p.closeCommitActions()
+
+	openActionsOn(t, p, 0)
+	if s := p.commitActions; len(s.targets) != 1 || s.targets[0].Branch != "other" {
+		t.Fatalf("targets = %+v", s.targets)
+	}
+	if !strings.Contains(p.renderCommitActions(), "Cherry-pick onto other") {
+		t.Fatal("the menu should offer the other worktree's branch")
+	}
+	_, cmd := p.Update(rebaseKey('c'))
+	result := runWrite(t, p, cmd)
+	if result.Err != nil || result.WorkDir != other {
+		t.Fatalf("cherry-pick result = %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(other, "c.txt")); err != nil {
+		t.Fatal("c.txt should have been picked into the other worktree")
+	}
+	if got := strings.TrimSpace(runGitTest(t, repo, "log", "-1", "--format=%s", "other")); got != "add c" {
+		t.Fatalf("other's tip = %q", got)
+	}
+
+	// A conflicting pick is backed out: the other worktree is not ours to resolve.
+	if err := os.WriteFile(filepath.Join(other, "b.txt"), []byte("theirs\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGitTest(t, other, "add", ".")
+	runGitTest(t, other, "commit", "-qm", "their b")
+	openActionsOn(t, p, 1)
+	_, cmd = p.Update(rebaseKey('c'))
+	result = runWrite(t, p, cmd)
+	if result.Err == nil || !strings.Contains(result.Err.Error(), "aborted") {
+		t.Fatalf("a conflicting pick should fail and abort, got %v", result.Err)
+	}
+	if op := ConflictOperation(other); op != "" {
+		t.Fatalf("the other worktree was left in a %s", op)
+	}
+}
@@ -0,0 +1,255 @@
+package gitstatus
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/projectdir"
+)
+
+// DefaultCommitTypes are the conventional-commit types offered when the
+// project configures none.
+var DefaultCommitTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+// defaultCoAuthors are the Co-authored-by identities agents sign their own
+// commits with. Agents missing here get a trailer only through the
+// commit.coAuthors config.
+var defaultCoAuthors = map[string]string{
+	"claude": "Claude <noreply@anthropic.com>",
+	"aider":  "aider <noreply@aider.chat>",
+	"amp":    "Amp <amp@ampcode.com>",
+}
+
+const (
+	defaultSubjectMaxLength  = 72
+	defaultBodyMaxLineLength = 100
+)
+
+// ConventionalHeader is the parsed subject line of a conventional commit:
+// type(scope)!: description.
+type ConventionalHeader struct {
+	Type        string
+	Scope       string
+	Breaking    bool
+	Description string
+}
+
+var conventionalHeaderRe = regexp.MustCompile(`^([A-Za-z]+)(?:\(([^()]*)\))?(!)?: ?(.*)$`)
+
+// ParseConventionalHeader splits a subject line into its conventional parts.
+func ParseConventionalHeader(subject string) (ConventionalHeader, bool) {
+	m := conventionalHeaderRe.FindStringSubmatch(subject)
+	if m == nil {
+		return ConventionalHeader{}, false
+	}
+	return ConventionalHeader{Type: m[1], Scope: m[2], Breaking: m[3] != "", Description: m[4]}, true
+}
+
+func (h ConventionalHeader) prefix() string {
+	if h.Type == "" {
+		return ""
+	}
+	p := h.Type
+	if h.Scope != "" {
+		p += "(" + h.Scope + ")"
+	}
+	if h.Breaking {
+		p += "!"
+	}
+	return p + ": "
+}
+
+// withConventionalHeader rewrites the type and scope at the start of
+// message's subject, keeping its description, breaking mark and body. An
+// empty type removes the header.
+func withConventionalHeader(message, typ, scope string) string {
+	subject, rest, hasRest := strings.Cut(message, "\n")
+	h, ok := ParseConventionalHeader(subject)
+	if !ok {
+		h = ConventionalHeader{Description: subject}
+	}
+	h.Type, h.Scope = typ, scope
+	if typ == "" {
+		h.Scope, h.Breaking = "", false
+	}
+	out := h.prefix() + h.Description
+	if hasRest {
+		out += "\n" + rest
+	}
+	return out
+}
+
+// LintIssue is one commit-msg rule a message breaks.
+type LintIssue struct {
+	Rule    string
+	Message string
+}
+
+// LintCommitMessage checks message against the rules cfg selects.
+func LintCommitMessage(message string, cfg config.CommitConfig) []LintIssue {
+	if cfg.Lint.Disabled {
+		return nil
+	}
+	var issues []LintIssue
+	add := func(rule, format string, args ...any) {
+		issues = append(issues, LintIssue{Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+	lines := strings.Split(strings.TrimRight(message, "\n"), "\n")
+	subject := strings.TrimSpace(lines[0])
+	if subject == "" {
+		add("subject-empty", "Subject line is empty")
+		return issues
+	}
+	if limit := lintLimit(cfg.Lint.SubjectMaxLength, defaultSubjectMaxLength); limit > 0 && len([]rune(subject)) > limit {
+		add("subject-max-length", "Subject is %d characters; keep it to %d", len([]rune(subject)), limit)
+	}
+	if strings.HasSuffix(subject, ".") {
+		add("subject-full-stop", "Subject ends with a period")
+	}
+	if len(lines) > 1 && strings.TrimSpace(lines[1]) != "" {
+		add("body-leading-blank", "Leave a blank line between the subject and the body")
+	}
+	if limit := lintLimit(cfg.Lint.BodyMaxLineLength, defaultBodyMaxLineLength); limit > 0 {
+		for i, line := range lines[1:] {
+			// A single long token, such as a URL, cannot be wrapped.
+			if len([]rune(line)) > limit && strings.Contains(strings.TrimSpace(line), " ") {
+				add("body-max-line-length", "Body line %d is longer than %d characters", i+2, limit)
+				break
+			}
+		}
+	}
+	if !cfg.Conventional {
+		return issues
+	}
+	h, ok := ParseConventionalHeader(subject)
+	if !ok {
+		add("header-conventional", "Subject should start with type(scope): ")
+		return issues
+	}
+	if types := commitTypes(cfg); !slices.Contains(types, h.Type) {
+		add("type-enum", "Type %q is not one of %s", h.Type, strings.Join(types, ", "))
+	}
+	if h.Scope == "" && cfg.Lint.RequireScope {
+		add("scope-empty", "A scope is required")
+	}
+	if h.Scope != "" && len(cfg.Scopes) > 0 && !slices.Contains(cfg.Scopes, h.Scope) {
+		add("scope-enum", "Scope %q is not one of %s", h.Scope, strings.Join(cfg.Scopes, ", "))
+	}
+	if strings.TrimSpace(h.Description) == "" {
+		add("subject-empty", "Description after the type is empty")
+	}
+	return issues
+}
+
+func lintLimit(configured, fallback int) int {
+	if configured == 0 {
+		return fallback
+	}
+	return configured
+}
+
+func commitTypes(cfg config.CommitConfig) []string {
+	if len(cfg.Types) > 0 {
+		return cfg.Types
+	}
+	return DefaultCommitTypes
+}
+
+// SigningStatus is git's commit signing setup for a worktree.
+type SigningStatus struct {
+	Default bool   // commit.gpgSign is on
+	Format  string // openpgp, ssh or x509
+	Key     string // user.signingKey; empty lets git pick one
+}
+
+// Describe renders the status for the commit modal.
+func (s SigningStatus) Describe(on bool) string {
+	if !on {
+		return "off"
+	}
+	d := "on · " + s.Format
+	if s.Key == "" {
+		return d + " · default key"
+	}
+	key := s.Key
+	if s.Format == "ssh" {
+		key = filepath.Base(key)
+	}
+	return d + " · " + truncateMiddle(key, 24)
+}
+
+func truncateMiddle(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	half := (n - 1) / 2
+	return string(r[:half]) + "…" + string(r[len(r)-half:])
+}
+
+// CommitContext is what the commit modal learns about the worktree when it
+// opens: the agent and task it was created for, and how git signs.
+type CommitContext struct {
+	Agent   string
+	TaskID  string
+	Signing SigningStatus
+}
+
+// LoadCommitContext reads the worktree's recorded agent and linked task and
+// git's signing config.
+func LoadCommitContext(projectRoot, workDir string) CommitContext {
+	var c CommitContext
+	if dir, ok := projectdir.LookupWorktree(projectRoot, workDir); ok {
+		if data, err := os.ReadFile(filepath.Join(dir, "agent")); err == nil {
+			c.Agent = strings.TrimSpace(string(data))
+		}
+		if data, err := os.ReadFile(filepath.Join(dir, "task")); err == nil {
+			c.TaskID = strings.TrimSpace(string(data))
+		}
+	}
+	c.Signing.Default = gitConfigValue(workDir, "--type=bool", "commit.gpgsign") == "true"
+	c.Signing.Format = gitConfigValue(workDir, "gpg.format")
+	if c.Signing.Format == "" {
+		c.Signing.Format = "openpgp"
+	}
+	c.Signing.Key = gitConfigValue(workDir, "user.signingkey")
+	return c
+}
+
+func gitConfigValue(workDir string, args ...string) string {
+	cmd := gitReadOnly(append([]string{"config", "--get"}, args...)...)
+	cmd.Dir = workDir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// CommitTrailers returns the trailers a commit in this worktree gets: the
+// agent as co-author and the linked task as a reference.
+func CommitTrailers(c CommitContext, cfg config.CommitConfig) []string {
+	var trailers []string
+	if identity := coAuthorFor(c.Agent, cfg); identity != "" {
+		trailers = append(trailers, "Co-authored-by: "+identity)
+	}
+	if c.TaskID != "" {
+		trailers = append(trailers, "Refs: "+c.TaskID)
+	}
+	return trailers
+}
+
+func coAuthorFor(agent string, cfg config.CommitConfig) string {
+	if agent == "" {
+		return ""
+	}
+	if identity, ok := cfg.CoAuthors[agent]; ok {
+		return identity
+	}
+	return defaultCoAuthors[agent]
+}
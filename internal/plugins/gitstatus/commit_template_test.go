@@ -0,0 +1,125 @@
+package gitstatus
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/marcus/sidecar/internal/config"
+)
+
+func lintRules(issues []LintIssue) []string {
+	rules := make([]string, len(issues))
+	for i, issue := range issues {
+		rules[i] = issue.Rule
+	}
+	return rules
+}
+
+func TestLintCommitMessage(t *testing.T) {
+	conventional := config.CommitConfig{Conventional: true, Scopes: []string{"api"}, Lint: config.CommitLintConfig{RequireScope: true}}
+	tests := []struct {
+		name    string
+		message string
+		cfg     config.CommitConfig
+		want    []string
+	}{
+		{"clean", "Fix the parser\n\nIt dropped the last token.", config.CommitConfig{}, nil},
+		{"full stop and no blank line", "Fix the parser.\nIt dropped the last token.", config.CommitConfig{}, []string{"subject-full-stop", "body-leading-blank"}},
+		{"long subject", strings.Repeat("x", 73), config.CommitConfig{}, []string{"subject-max-length"}},
+		{"long subject allowed", strings.Repeat("x", 73), config.CommitConfig{Lint: config.CommitLintConfig{SubjectMaxLength: -1}}, nil},
+		{"long body line", "Fix\n\n" + strings.Repeat("word ", 25), config.CommitConfig{}, []string{"body-max-line-length"}},
+		{"long url", "Fix\n\nhttps://example.com/" + strings.Repeat("x", 100), config.CommitConfig{}, nil},
+		{"conventional", "fix(api): drop the last token", conventional, nil},
+		{"missing header", "drop the last token", conventional, []string{"header-conventional"}},
+		{"unknown type and scope", "oops(db): drop it", conventional, []string{"type-enum", "scope-enum"}},
+		{"missing scope", "fix: drop it", conventional, []string{"scope-empty"}},
+		{"disabled", "Fix the parser.", config.CommitConfig{Lint: config.CommitLintConfig{Disabled: true}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lintRules(LintCommitMessage(tt.message, tt.cfg)); !slices.Equal(got, tt.want) {
+				t.Fatalf("rules = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithConventionalHeader(t *testing.T) {
+	tests := []struct {
+		message, typ, scope, want string
+	}{
+		{"drop the last token", "fix", "", "fix: drop the last token"},
+		{"fix: drop it\n\nbody", "feat", "api", "feat(api): drop it\n\nbody"},
+		{"fix(api)!: drop it", "fix", "ui", "fix(ui)!: drop it"},
+		{"fix(api): drop it", "", "api", "drop it"},
+		{"", "docs", "", "docs: "},
+	}
+	for _, tt := range tests {
+		if got := withConventionalHeader(tt.message, tt.typ, tt.scope); got != tt.want {
+			t.Errorf("withConventionalHeader(%q, %q, %q) = %q, want %q", tt.message, tt.typ, tt.scope, got, tt.want)
+		}
+	}
+}
+
+func TestCommitTrailers(t *testing.T) {
+	c := CommitContext{Agent: "claude", TaskID: "td-42"}
+	want := []string{"Co-authored-by: Claude <noreply@anthropic.com>", "Refs: td-42"}
+	if got := CommitTrailers(c, config.CommitConfig{}); !slices.Equal(got, want) {
+		t.Fatalf("trailers = %v", got)
+	}
+	cfg := config.CommitConfig{CoAuthors: map[string]string{"codex": "Codex <codex@example.com>"}}
+	if got := CommitTrailers(CommitContext{Agent: "codex"}, cfg); !slices.Equal(got, []string{"Co-authored-by: Codex <codex@example.com>"}) {
+		t.Fatalf("configured trailers = %v", got)
+	}
+	if got := CommitTrailers(CommitContext{Agent: "unknown"}, config.CommitConfig{}); len(got) != 0 {
+		t.Fatalf("an agent with no identity should add nothing, got %v", got)
+	}
+}
+
+func TestExecuteCommitWithOptionsAddsTrailers(t *testing.T) {
+	repo := rebaseRepo(t)
+	if err := os.WriteFile(filepath.Join(repo, "d.txt"), []byte("d\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGitTest(t, repo, "add", "d.txt")
+	sign := false
+	opts := CommitOptions{Sign: &sign, Trailers: []string{"Co-authored-by: Amp <amp@ampcode.com>", "Refs: td-7"}}
+	if _, err := ExecuteCommitWithOptions(context.Background(), repo, "feat: add d", opts); err != nil {
+		t.Fatal(err)
+	}
+	body := runGitTest(t, repo, "log", "-1", "--format=%B")
+	for _, want := range []string{"feat: add d", "Co-authored-by: Amp <amp@ampcode.com>", "Refs: td-7"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("commit message is missing %q:\n%s", want, body)
+		}
+	}
+
+	// Amending keeps the trailers and does not add a commit.
+	opts.Amend = true
+	if _, err := ExecuteCommitWithOptions(context.Background(), repo, "feat: add d.txt", opts); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(runGitTest(t, repo, "log", "-1", "--format=%s%n%(trailers:key=Refs,valueonly)")); got != "feat: add d.txt\ntd-7" {
+		t.Fatalf("amended commit = %q", got)
+	}
+	if got := strings.TrimSpace(runGitTest(t, repo, "rev-list", "--count", "HEAD")); got != "5" {
+		t.Fatalf("amend should not add a commit, have %s", got)
+	}
+}
+
+func TestTryCommitHoldsBackLintedMessageOnce(t *testing.T) {
+	p := rebasePlugin(t, rebaseRepo(t))
+	p.viewMode = ViewModeCommit
+	p.initCommitTextarea()
+	p.commitMessage.SetValue("Fix the parser.")
+	if cmd := p.tryCommit(); cmd != nil || !strings.Contains(p.commitError, "1 lint issue") {
+		t.Fatalf("first submit should stop on lint, error %q", p.commitError)
+	}
+	if cmd := p.tryCommit(); cmd == nil || !p.commitInProgress {
+		t.Fatal("a second submit of the same message should commit")
+	}
+}
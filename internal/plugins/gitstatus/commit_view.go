@@ -2,9 +2,13 @@ package gitstatus
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/marcus/sidecar/internal/config"
 	"github.com/marcus/sidecar/internal/modal"
 	"github.com/marcus/sidecar/internal/styles"
 	"github.com/marcus/sidecar/internal/ui"
@@ -13,8 +17,125 @@ import (
 const (
 	commitMessageID = "commit-message"
 	commitActionID  = "execute-commit"
+	commitTypeID    = "commit-type"
+	commitScopeID   = "commit-scope"
 )
 
+// commitTemplate is the commit modal's state beyond the message: the
+// conventional header pickers, trailers, signing and lint.
+type commitTemplate struct {
+	cfg        config.CommitConfig
+	context    CommitContext
+	loaded     bool
+	typeInput  textinput.Model
+	typeIdx    int
+	scopeInput textinput.Model
+	scopeIdx   int
+	trailers   bool   // Add CommitTrailers to the commit
+	sign       bool   // Sign the commit
+	lintAck    string // Message whose lint issues a second submit commits past
+}
+
+// CommitContextLoadedMsg delivers the worktree's agent, task and signing setup
+// to an open commit modal.
+type CommitContextLoadedMsg struct {
+	Epoch   uint64
+	Context CommitContext
+}
+
+func (m CommitContextLoadedMsg) GetEpoch() uint64 { return m.Epoch }
+
+// resetCommitTemplate reads the project's commit config for a fresh modal.
+func (p *Plugin) resetCommitTemplate() {
+	var cfg config.CommitConfig
+	if p.ctx != nil {
+		cfg = p.ctx.Config.CommitForProject(p.ctx.ProjectRoot)
+	}
+	t := commitTemplate{cfg: cfg, trailers: !cfg.NoTrailers, sign: cfg.Sign}
+	t.typeInput = textinput.New()
+	t.typeInput.Placeholder = "type"
+	t.scopeInput = textinput.New()
+	t.scopeInput.Placeholder = "scope (optional)"
+	p.commitTemplate = t
+}
+
+// loadCommitContext looks up the trailers and signing state for the modal.
+func (p *Plugin) loadCommitContext() tea.Cmd {
+	epoch := p.currentEpoch()
+	workDir := p.repoRoot
+	projectRoot := workDir
+	if p.ctx != nil && p.ctx.ProjectRoot != "" {
+		projectRoot = p.ctx.ProjectRoot
+	}
+	return func() tea.Msg {
+		return CommitContextLoadedMsg{Epoch: epoch, Context: LoadCommitContext(projectRoot, workDir)}
+	}
+}
+
+func (p *Plugin) handleCommitContextLoaded(msg CommitContextLoadedMsg) {
+	t := &p.commitTemplate
+	t.context = msg.Context
+	t.loaded = true
+	t.sign = t.cfg.Sign || msg.Context.Signing.Default
+}
+
+// commitOptions are the flags the commit runs with.
+func (p *Plugin) commitOptions() CommitOptions {
+	t := p.commitTemplate
+	opts := CommitOptions{Amend: p.commitAmend}
+	if !t.loaded {
+		return opts
+	}
+	sign := t.sign
+	opts.Sign = &sign
+	if t.trailers {
+		opts.Trailers = CommitTrailers(t.context, t.cfg)
+	}
+	return opts
+}
+
+func (p *Plugin) conventionalPickers() bool {
+	return p.commitTemplate.cfg.Conventional && !p.rewording()
+}
+
+// syncConventionalHeader writes the picked type and scope into the subject.
+// A type still being typed is left out until it names a known type.
+func (p *Plugin) syncConventionalHeader() {
+	t := &p.commitTemplate
+	typ := strings.TrimSpace(t.typeInput.Value())
+	if typ != "" && !slices.Contains(commitTypes(t.cfg), typ) {
+		return
+	}
+	message := p.commitMessage.Value()
+	if typ == "" {
+		if _, ok := ParseConventionalHeader(firstLine(message)); !ok {
+			return
+		}
+	}
+	if updated := withConventionalHeader(message, typ, strings.TrimSpace(t.scopeInput.Value())); updated != message {
+		p.commitMessage.SetValue(updated)
+	}
+}
+
+// syncConventionalPickers fills the pickers from a message set in code, such
+// as the amend prefill.
+func (p *Plugin) syncConventionalPickers() {
+	t := &p.commitTemplate
+	h, _ := ParseConventionalHeader(firstLine(p.commitMessage.Value()))
+	t.typeInput.SetValue(h.Type)
+	t.scopeInput.SetValue(h.Scope)
+	t.typeIdx = max(0, slices.Index(commitTypes(t.cfg), h.Type))
+	t.scopeIdx = max(0, slices.Index(t.cfg.Scopes, h.Scope))
+}
+
+func dropdownItems(values []string) []modal.DropdownItem {
+	items := make([]modal.DropdownItem, len(values))
+	for i, v := range values {
+		items[i] = modal.DropdownItem{ID: v, Label: v}
+	}
+	return items
+}
+
 // commitModalWidth returns the width for the commit modal content.
 func (p *Plugin) commitModalWidth() int {
 	w := p.width - 8 // 4-char margin each side
@@ -37,12 +158,25 @@ func (p *Plugin) ensureCommitModal() {
 		modal.WithWidth(modalW),
 		modal.WithPrimaryAction(commitActionID),
 		modal.WithHints(false),
+		modal.WithInitialFocus(commitMessageID),
 	).
 		AddSection(p.commitHeaderSection()).
 		AddSection(p.commitStagedSection()).
-		AddSection(modal.Spacer()).
+		AddSection(modal.Spacer())
+	if p.conventionalPickers() {
+		t := &p.commitTemplate
+		p.commitModal.
+			AddSection(modal.Text("Type")).
+			AddSection(modal.Combo(commitTypeID, &t.typeInput, dropdownItems(commitTypes(t.cfg)), &t.typeIdx,
+				modal.WithComboSubmitOnEnter(false), modal.WithOpenOnFocus(false))).
+			AddSection(modal.Text("Scope")).
+			AddSection(modal.Combo(commitScopeID, &t.scopeInput, dropdownItems(t.cfg.Scopes), &t.scopeIdx,
+				modal.WithComboSubmitOnEnter(false), modal.WithOpenOnFocus(false)))
+	}
+	p.commitModal.
 		AddSection(modal.Textarea(commitMessageID, &p.commitMessage, 4)).
 		AddSection(modal.When(p.showCommitAmendToggle, modal.CheckboxDisplay("Amend last commit", &p.commitAmend, "ctrl+a"))).
+		AddSection(p.commitTemplateSection()).
 		AddSection(p.commitStatusSection()).
 		AddSection(modal.Buttons(
 			modal.Btn(p.commitButtonLabel(), commitActionID),
@@ -51,13 +185,16 @@ func (p *Plugin) ensureCommitModal() {
 }
 
 func (p *Plugin) showCommitAmendToggle() bool {
-	if len(p.recentCommits) == 0 {
+	if len(p.recentCommits) == 0 || p.rewording() {
 		return false
 	}
 	return p.tree.HasStagedFiles()
 }
 
 func (p *Plugin) commitButtonLabel() string {
+	if p.rewording() {
+		return " Reword "
+	}
 	if p.commitAmend {
 		return " Amend "
 	}
@@ -70,13 +207,15 @@ func (p *Plugin) commitHeaderSection() modal.Section {
 		fileCount := len(p.tree.Staged)
 
 		titleText := " Commit "
-		if p.commitAmend {
+		if p.rewording() {
+			titleText = " Reword "
+		} else if p.commitAmend {
 			titleText = " Amend "
 		}
 		title := styles.Title.Render(titleText)
 
 		statsStr := ""
-		if fileCount > 0 {
+		if fileCount > 0 && !p.rewording() {
 			statsStr = fmt.Sprintf("[%d: +%d -%d]", fileCount, additions, deletions)
 		}
 		statsRendered := styles.Muted.Render(statsStr)
@@ -97,6 +236,12 @@ func (p *Plugin) commitStagedSection() modal.Section {
 	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
 		var sb strings.Builder
 
+		if p.rewording() {
+			step := p.rebase.rewording
+			sb.WriteString(styles.Muted.Render(fmt.Sprintf("New message for %s, applied when the rebase runs", step.ShortHash)))
+			return modal.RenderedSection{Content: sb.String()}
+		}
+
 		fileCount := len(p.tree.Staged)
 		if p.commitAmend && fileCount == 0 {
 			sb.WriteString(styles.Muted.Render("Message-only amend (no staged changes)"))
@@ -153,6 +298,31 @@ func (p *Plugin) commitStagedSection() modal.Section {
 	}, nil)
 }
 
+// commitTemplateSection shows the trailers and signing a commit gets, and the
+// lint issues in the message so far.
+func (p *Plugin) commitTemplateSection() modal.Section {
+	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
+		t := p.commitTemplate
+		var lines []string
+		if t.loaded && !p.rewording() {
+			if trailers := CommitTrailers(t.context, t.cfg); len(trailers) > 0 {
+				text := strings.Join(trailers, " · ")
+				if !t.trailers {
+					text = "off"
+				}
+				lines = append(lines, truncateLine(styles.Muted.Render("Trailers: ")+text+styles.Muted.Render(" (ctrl+t)"), contentWidth))
+			}
+			lines = append(lines, truncateLine(styles.Muted.Render("Signing: ")+t.context.Signing.Describe(t.sign)+styles.Muted.Render(" (ctrl+g)"), contentWidth))
+		}
+		if message := strings.TrimSpace(p.commitMessage.Value()); message != "" {
+			for _, issue := range LintCommitMessage(message, t.cfg) {
+				lines = append(lines, truncateLine(styles.StatusModified.Render("⚠ "+issue.Message), contentWidth))
+			}
+		}
+		return modal.RenderedSection{Content: strings.Join(lines, "\n")}
+	}, nil)
+}
+
 func (p *Plugin) commitStatusSection() modal.Section {
 	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
 		lines := make([]string, 0, 2)
@@ -172,7 +342,12 @@ func (p *Plugin) commitStatusSection() modal.Section {
 
 // renderCommitModal renders the commit modal overlaid on the status view.
 func (p *Plugin) renderCommitModal() string {
-	background := p.renderThreePaneView()
+	var background string
+	if p.rewording() {
+		background = p.renderRebaseView()
+	} else {
+		background = p.renderThreePaneView()
+	}
 	p.ensureCommitModal()
 	if p.commitModal == nil {
 		return background
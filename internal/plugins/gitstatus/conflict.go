@@ -0,0 +1,292 @@
+package gitstatus
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ConflictChoice is how a single conflict hunk has been resolved.
+type ConflictChoice int
+
+const (
+	ChoiceUnresolved ConflictChoice = iota
+	ChoiceOurs                      // Keep our side
+	ChoiceTheirs                    // Keep their side
+	ChoiceBoth                      // Ours followed by theirs
+	ChoiceBase                      // The common ancestor (diff3/zdiff3 markers only)
+)
+
+// String returns the short label shown next to a hunk.
+func (c ConflictChoice) String() string {
+	switch c {
+	case ChoiceOurs:
+		return "ours"
+	case ChoiceTheirs:
+		return "theirs"
+	case ChoiceBoth:
+		return "both"
+	case ChoiceBase:
+		return "base"
+	default:
+		return "unresolved"
+	}
+}
+
+// Conflict marker prefixes. Git writes exactly seven characters unless the
+// conflict-marker-size attribute says otherwise, which is rare enough that the
+// resolver leaves such files to the editor.
+const (
+	markerOurs   = "<<<<<<<"
+	markerBase   = "|||||||"
+	markerSep    = "======="
+	markerTheirs = ">>>>>>>"
+)
+
+// ConflictHunk is one <<<<<<< ... >>>>>>> block.
+type ConflictHunk struct {
+	Ours        []string
+	Base        []string
+	Theirs      []string
+	OursLabel   string // Text after <<<<<<<, e.g. "HEAD"
+	BaseLabel   string
+	TheirsLabel string // Text after >>>>>>>, e.g. "origin/main"
+	HasBase     bool   // Written with merge.conflictStyle=diff3 or zdiff3
+	Line        int    // 1-based line of the <<<<<<< marker in the file
+	Choice      ConflictChoice
+
+	diff *ParsedDiff // Memoized side-by-side view (see sideBySide)
+}
+
+// ConflictSegment is a run of the file: plain lines, or a conflict.
+type ConflictSegment struct {
+	Lines []string
+	Hunk  *ConflictHunk
+}
+
+// ConflictFile is a conflicted file split into plain text and hunks.
+type ConflictFile struct {
+	Segments []ConflictSegment
+	Hunks    []*ConflictHunk
+
+	trailingNewline bool
+}
+
+// markerLine reports whether line is the given conflict marker, which is the
+// marker itself optionally followed by a space and a label.
+func markerLine(line, marker string) (string, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	if !strings.HasPrefix(line, marker) {
+		return "", false
+	}
+	rest := line[len(marker):]
+	if rest == "" {
+		return "", true
+	}
+	if rest[0] != ' ' {
+		return "", false
+	}
+	return rest[1:], true
+}
+
+// ParseConflicts splits content at its conflict markers. A file without
+// markers parses to a single plain segment and no hunks.
+func ParseConflicts(content string) (*ConflictFile, error) {
+	cf := &ConflictFile{trailingNewline: strings.HasSuffix(content, "\n")}
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if content == "" {
+		lines = nil
+	}
+
+	var plain []string
+	flush := func() {
+		if len(plain) > 0 {
+			cf.Segments = append(cf.Segments, ConflictSegment{Lines: plain})
+			plain = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		label, ok := markerLine(lines[i], markerOurs)
+		if !ok {
+			plain = append(plain, lines[i])
+			continue
+		}
+		h := &ConflictHunk{OursLabel: label, Line: i + 1}
+		section := &h.Ours
+		closed := false
+		for i++; i < len(lines); i++ {
+			line := lines[i]
+			if _, nested := markerLine(line, markerOurs); nested {
+				return nil, fmt.Errorf("line %d: conflict opened inside the conflict at line %d", i+1, h.Line)
+			}
+			if label, ok := markerLine(line, markerBase); ok && section == &h.Ours {
+				h.HasBase = true
+				h.BaseLabel = label
+				section = &h.Base
+				continue
+			}
+			if strings.TrimSuffix(line, "\r") == markerSep && section != &h.Theirs {
+				section = &h.Theirs
+				continue
+			}
+			if label, ok := markerLine(line, markerTheirs); ok && section == &h.Theirs {
+				h.TheirsLabel = label
+				closed = true
+				break
+			}
+			*section = append(*section, line)
+		}
+		if !closed {
+			return nil, fmt.Errorf("line %d: conflict is never closed", h.Line)
+		}
+		flush()
+		cf.Segments = append(cf.Segments, ConflictSegment{Hunk: h})
+		cf.Hunks = append(cf.Hunks, h)
+	}
+	flush()
+	return cf, nil
+}
+
+// Unresolved returns how many hunks still need a choice.
+func (cf *ConflictFile) Unresolved() int {
+	n := 0
+	for _, h := range cf.Hunks {
+		if h.Choice == ChoiceUnresolved {
+			n++
+		}
+	}
+	return n
+}
+
+// Result renders the file with every chosen hunk replaced by its choice.
+// Unresolved hunks keep their markers, so writing a partial result loses
+// nothing.
+func (cf *ConflictFile) Result() string {
+	var out []string
+	for _, seg := range cf.Segments {
+		if seg.Hunk == nil {
+			out = append(out, seg.Lines...)
+			continue
+		}
+		out = append(out, seg.Hunk.lines()...)
+	}
+	if len(out) == 0 {
+		return ""
+	}
+	s := strings.Join(out, "\n")
+	if cf.trailingNewline {
+		s += "\n"
+	}
+	return s
+}
+
+// lines returns the hunk as it should appear in the result.
+func (h *ConflictHunk) lines() []string {
+	switch h.Choice {
+	case ChoiceOurs:
+		return h.Ours
+	case ChoiceTheirs:
+		return h.Theirs
+	case ChoiceBoth:
+		return append(append([]string(nil), h.Ours...), h.Theirs...)
+	case ChoiceBase:
+		return h.Base
+	}
+	out := []string{withLabel(markerOurs, h.OursLabel)}
+	out = append(out, h.Ours...)
+	if h.HasBase {
+		out = append(out, withLabel(markerBase, h.BaseLabel))
+		out = append(out, h.Base...)
+	}
+	out = append(out, markerSep)
+	out = append(out, h.Theirs...)
+	return append(out, withLabel(markerTheirs, h.TheirsLabel))
+}
+
+func withLabel(marker, label string) string {
+	if label == "" {
+		return marker
+	}
+	return marker + " " + label
+}
+
+// sideBySide returns the hunk as a one-hunk diff, ours on the left and
+// theirs on the right, so the regular side-by-side renderer (word diffs
+// included) can draw it. Line numbers are those of the conflicted file.
+func (h *ConflictHunk) sideBySide() *ParsedDiff {
+	if h.diff != nil {
+		return h.diff
+	}
+	hunk := Hunk{OldStart: h.Line + 1, OldCount: len(h.Ours), NewCount: len(h.Theirs)}
+	for i, l := range h.Ours {
+		hunk.Lines = append(hunk.Lines, DiffLine{Type: LineRemove, OldLineNo: h.Line + 1 + i, Content: l})
+	}
+	theirsStart := h.Line + len(h.Ours) + 2
+	if h.HasBase {
+		theirsStart += len(h.Base) + 1
+	}
+	hunk.NewStart = theirsStart
+	for i, l := range h.Theirs {
+		hunk.Lines = append(hunk.Lines, DiffLine{Type: LineAdd, NewLineNo: theirsStart + i, Content: l})
+	}
+	computeWordDiffs(&hunk)
+	h.diff = &ParsedDiff{Hunks: []Hunk{hunk}}
+	return h.diff
+}
+
+// ConflictOperation names the operation that left the repository conflicted:
+// "rebase", "merge", "cherry-pick", "revert", or "" when none is pending.
+func ConflictOperation(workDir string) string {
+	if IsRebaseInProgress(workDir) {
+		return "rebase"
+	}
+	for _, probe := range []struct{ ref, op string }{
+		{"MERGE_HEAD", "merge"},
+		{"CHERRY_PICK_HEAD", "cherry-pick"},
+		{"REVERT_HEAD", "revert"},
+	} {
+		if gitPathExists(workDir, probe.ref) {
+			return probe.op
+		}
+	}
+	return ""
+}
+
+// MarkResolved stages path, which is how Git records a conflict as resolved.
+// -A also records a resolution that deletes the file.
+func MarkResolved(workDir, path string) error {
+	return executeGitWrite(workDir, []string{"add", "-A", "--", path})
+}
+
+// AbortOperation runs `git <op> --abort`, returning the repository to where
+// it was before the operation started.
+func AbortOperation(workDir, op string) error {
+	switch op {
+	case "rebase":
+		return AbortRebase(workDir)
+	case "", "merge":
+		return AbortMerge(workDir)
+	}
+	cmd := exec.Command("git", op, "--abort")
+	cmd.Dir = workDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return &RemoteError{Output: string(output), Err: err}
+	}
+	return nil
+}
+
+// ContinueOperation runs `git <op> --continue`. The editor is pinned to
+// true so Git keeps its prepared message instead of waiting on a terminal
+// the TUI owns.
+func ContinueOperation(workDir, op string) (string, error) {
+	cmd := exec.Command("git", op, "--continue")
+	cmd.Dir = workDir
+	cmd.Env = append(os.Environ(), "GIT_EDITOR=true")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", &RemoteError{Output: string(output), Err: err}
+	}
+	return string(output), nil
+}
@@ -0,0 +1,184 @@
+package gitstatus
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+func TestParseConflictsRoundTripsAndResolves(t *testing.T) {
+	content := strings.Join([]string{
+		"head",
+		"<<<<<<< HEAD",
+		"ours 1",
+		"ours 2",
+		"||||||| merged common ancestors",
+		"base",
+		"=======",
+		"theirs",
+		">>>>>>> feature",
+		"middle",
+		"<<<<<<< HEAD",
+		"=======",
+		"added",
+		">>>>>>> feature",
+		"tail",
+	}, "\n") + "\n"
+
+	cf, err := ParseConflicts(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cf.Hunks) != 2 || cf.Unresolved() != 2 {
+		t.Fatalf("hunks = %d, unresolved = %d", len(cf.Hunks), cf.Unresolved())
+	}
+	h := cf.Hunks[0]
+	if !h.HasBase || h.OursLabel != "HEAD" || h.TheirsLabel != "feature" || h.Line != 2 ||
+		strings.Join(h.Ours, ",") != "ours 1,ours 2" || strings.Join(h.Base, ",") != "base" || strings.Join(h.Theirs, ",") != "theirs" {
+		t.Fatalf("first hunk = %+v", h)
+	}
+	if cf.Result() != content {
+		t.Fatalf("an unresolved file should render back unchanged:\n%s", cf.Result())
+	}
+
+	h.Choice = ChoiceBase
+	cf.Hunks[1].Choice = ChoiceBoth
+	want := "head\nbase\nmiddle\nadded\ntail\n"
+	if got := cf.Result(); got != want {
+		t.Fatalf("Result = %q, want %q", got, want)
+	}
+	if line := cf.resultLine(1); line != 4 {
+		t.Fatalf("second hunk starts at result line %d, want 4", line)
+	}
+
+	d := cf.Hunks[0].sideBySide()
+	if len(d.Hunks) != 1 || d.Hunks[0].Lines[0].OldLineNo != 3 || d.Hunks[0].Lines[2].NewLineNo != 8 {
+		t.Fatalf("side-by-side numbering = %+v", d.Hunks[0].Lines)
+	}
+}
+
+func TestParseConflictsRejectsBrokenMarkers(t *testing.T) {
+	for _, content := range []string{
+		"<<<<<<< HEAD\nours\n=======\ntheirs\n",
+		"<<<<<<< HEAD\n<<<<<<< HEAD\n=======\n>>>>>>> x\n",
+	} {
+		if _, err := ParseConflicts(content); err == nil {
+			t.Errorf("expected an error for %q", content)
+		}
+	}
+	cf, err := ParseConflicts("<<<<<<<< eight wide\n")
+	if err != nil || len(cf.Hunks) != 0 {
+		t.Fatalf("a longer marker run is text, got %v, %v", cf, err)
+	}
+}
+
+// conflictRepo returns a repository stopped in a merge with one conflicted
+// file.
+func conflictRepo(t *testing.T) string {
+	t.Helper()
+	repo := initTestRepo(t)
+	write := func(body string) {
+		if err := os.WriteFile(filepath.Join(repo, "a.txt"), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("one\nshared\nthree\n")
+	runGitTest(t, repo, "add", ".")
+	runGitTest(t, repo, "commit", "-qm", "base")
+	runGitTest(t, repo, "checkout", "-qb", "feature")
+	write("one\ntheirs\nthree\n")
+	runGitTest(t, repo, "commit", "-qam", "theirs")
+	runGitTest(t, repo, "checkout", "-q", "main")
+	write("one\nours\nthree\n")
+	runGitTest(t, repo, "commit", "-qam", "ours")
+	cmd := gitReadOnly("merge", "feature")
+	cmd.Dir = repo
+	if out, err := cmd.CombinedOutput(); err == nil {
+		t.Fatalf("merge should conflict: %s", out)
+	}
+	return repo
+}
+
+// pumpConflict runs cmd and feeds the resolver's own messages back in, the
+// way the runtime would, until the chain settles.
+func pumpConflict(t *testing.T, p *Plugin, cmd tea.Cmd) {
+	t.Helper()
+	if cmd == nil {
+		return
+	}
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		for _, c := range msg {
+			pumpConflict(t, p, c)
+		}
+	case ConflictsLoadedMsg, ConflictFileLoadedMsg, ConflictResolvedMsg, ConflictContinuedMsg:
+		_, next := p.Update(msg)
+		pumpConflict(t, p, next)
+	}
+}
+
+func TestConflictResolverStagesAndContinuesMerge(t *testing.T) {
+	repo := conflictRepo(t)
+	p := New()
+	p.ctx = &plugin.Context{Epoch: 1, WorkDir: repo}
+	p.repoRoot, p.hasRepo = repo, true
+	p.tree = NewFileTree(repo)
+	p.width, p.height = 100, 30
+
+	pumpConflict(t, p, p.openConflictResolver(""))
+	r := p.resolver
+	if p.viewMode != ViewModeConflict || r == nil || r.op != "merge" || r.path() != "a.txt" || r.file() == nil {
+		t.Fatalf("resolver did not load the conflict: %+v", r)
+	}
+	if p.FocusContext() != "git-conflict" {
+		t.Fatalf("focus context = %s", p.FocusContext())
+	}
+	view := p.renderConflictView()
+	for _, want := range []string{"Conflict 1/1", "ours", "theirs", "[unresolved]"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("view is missing %q:\n%s", want, view)
+		}
+	}
+
+	_, cmd := p.Update(tea.KeyPressMsg{Code: 'c', Text: "c"})
+	pumpConflict(t, p, cmd)
+	if p.resolver == nil || ConflictOperation(repo) != "merge" {
+		t.Fatal("continue must wait until every file is resolved")
+	}
+
+	p.Update(tea.KeyPressMsg{Code: 't', Text: "t"})
+	_, cmd = p.Update(tea.KeyPressMsg{Code: 's', Text: "s"})
+	pumpConflict(t, p, cmd)
+	if !r.resolved["a.txt"] || len(GetConflictedFiles(repo)) != 0 {
+		t.Fatal("staging should mark the file resolved")
+	}
+	if b, _ := os.ReadFile(filepath.Join(repo, "a.txt")); string(b) != "one\ntheirs\nthree\n" {
+		t.Fatalf("resolved file = %q", b)
+	}
+
+	_, cmd = p.Update(tea.KeyPressMsg{Code: 'c', Text: "c"})
+	pumpConflict(t, p, cmd)
+	if p.resolver != nil || p.viewMode != ViewModeStatus {
+		t.Fatal("a finished merge should close the resolver")
+	}
+	if op := ConflictOperation(repo); op != "" {
+		t.Fatalf("merge still pending: %s", op)
+	}
+	if parents := strings.Fields(runGitTest(t, repo, "log", "-1", "--format=%P")); len(parents) != 2 {
+		t.Fatalf("expected a merge commit, parents = %v", parents)
+	}
+}
+
+func TestAbortOperationKeepsGitsOutput(t *testing.T) {
+	repo := initTestRepo(t)
+	err := AbortOperation(repo, "cherry-pick")
+	var remoteErr *RemoteError
+	if !errors.As(err, &remoteErr) || !strings.Contains(remoteErr.Output, "cherry-pick") {
+		t.Fatalf("abort with nothing in progress = %#v", err)
+	}
+}
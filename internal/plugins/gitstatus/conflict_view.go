@@ -0,0 +1,788 @@
+package gitstatus
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/marcus/sidecar/internal/app"
+	"github.com/marcus/sidecar/internal/mouse"
+	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/styles"
+	"github.com/marcus/sidecar/internal/tty"
+)
+
+const regionConflict = "conflict" // Full-screen conflict resolver
+
+// conflictResolver is the state of the full-screen resolver. Choices live in
+// the parsed files until a file is staged, so moving between files keeps them.
+type conflictResolver struct {
+	op         string   // "merge", "rebase", "cherry-pick" or "revert"
+	files      []string // Conflicted paths when the resolver opened
+	fileIdx    int
+	parsed     map[string]*ConflictFile
+	loadErr    map[string]string
+	missing    map[string]bool // Deleted on one side; nothing to parse
+	resolved   map[string]bool // Staged from the resolver
+	hunkIdx    int
+	scroll     int
+	maxScroll  int
+	follow     bool   // Scroll the selected hunk into view on the next render
+	editing    string // File handed to the editor; re-read when it returns
+	stop       string // Commit a rebase paused at, when it paused without conflicts
+	progress   string // "n/m" through a rebase
+	returnMode ViewMode
+}
+
+func (r *conflictResolver) path() string {
+	if r.fileIdx < 0 || r.fileIdx >= len(r.files) {
+		return ""
+	}
+	return r.files[r.fileIdx]
+}
+
+func (r *conflictResolver) file() *ConflictFile {
+	return r.parsed[r.path()]
+}
+
+func (r *conflictResolver) hunk() *ConflictHunk {
+	cf := r.file()
+	if cf == nil || r.hunkIdx < 0 || r.hunkIdx >= len(cf.Hunks) {
+		return nil
+	}
+	return cf.Hunks[r.hunkIdx]
+}
+
+func (r *conflictResolver) allResolved() bool {
+	for _, f := range r.files {
+		if !r.resolved[f] {
+			return false
+		}
+	}
+	return true
+}
+
+// ConflictsLoadedMsg carries the pending operation and its conflicted files.
+type ConflictsLoadedMsg struct {
+	Epoch uint64
+	Op    string
+	Files []string
+	Focus string // Path to open first, when the resolver was opened on a file
+
+	Stop     string // Commit a rebase is paused at
+	Progress string // "n/m" through a rebase
+}
+
+func (m ConflictsLoadedMsg) GetEpoch() uint64 { return m.Epoch }
+
+// ConflictFileLoadedMsg carries one conflicted file, parsed.
+type ConflictFileLoadedMsg struct {
+	Epoch   uint64
+	Path    string
+	File    *ConflictFile
+	Missing bool
+	Err     error
+}
+
+func (m ConflictFileLoadedMsg) GetEpoch() uint64 { return m.Epoch }
+
+// ConflictResolvedMsg reports staging a resolved file.
+type ConflictResolvedMsg struct {
+	Epoch uint64
+	Path  string
+	Err   error
+}
+
+func (m ConflictResolvedMsg) GetEpoch() uint64 { return m.Epoch }
+
+// ConflictContinuedMsg reports `git <op> --continue`. Remaining is set when
+// the operation stopped again on new conflicts, as a rebase does when a
+// later commit conflicts too; Paused when it stopped without any, at an edit.
+type ConflictContinuedMsg struct {
+	Epoch     uint64
+	Op        string
+	Remaining []string
+	Paused    bool
+	Err       error
+}
+
+func (m ConflictContinuedMsg) GetEpoch() uint64 { return m.Epoch }
+
+// openConflictResolver switches to the resolver and loads the conflicts,
+// starting at focus when it is one of them.
+func (p *Plugin) openConflictResolver(focus string) tea.Cmd {
+	returnMode := p.viewMode
+	if returnMode == ViewModePullConflict || returnMode == ViewModeConflict {
+		returnMode = ViewModeStatus
+	}
+	p.resolver = &conflictResolver{
+		parsed:     make(map[string]*ConflictFile),
+		loadErr:    make(map[string]string),
+		missing:    make(map[string]bool),
+		resolved:   make(map[string]bool),
+		follow:     true,
+		returnMode: returnMode,
+	}
+	p.viewMode = ViewModeConflict
+	p.clearPullConflictModal()
+	return p.loadConflicts(focus)
+}
+
+func (p *Plugin) loadConflicts(focus string) tea.Cmd {
+	workDir := p.repoRoot
+	epoch := p.currentEpoch()
+	return func() tea.Msg {
+		msg := ConflictsLoadedMsg{
+			Epoch: epoch,
+			Op:    ConflictOperation(workDir),
+			Files: GetConflictedFiles(workDir),
+			Focus: focus,
+		}
+		if msg.Op == "rebase" {
+			msg.Stop = rebaseStopSubject(workDir)
+			msg.Progress = rebaseProgress(workDir)
+		}
+		return msg
+	}
+}
+
+func (p *Plugin) loadConflictFile(path string) tea.Cmd {
+	workDir := p.repoRoot
+	epoch := p.currentEpoch()
+	return func() tea.Msg {
+		data, err := os.ReadFile(filepath.Join(workDir, path))
+		if os.IsNotExist(err) {
+			return ConflictFileLoadedMsg{Epoch: epoch, Path: path, Missing: true}
+		}
+		if err != nil {
+			return ConflictFileLoadedMsg{Epoch: epoch, Path: path, Err: err}
+		}
+		cf, err := ParseConflicts(string(data))
+		return ConflictFileLoadedMsg{Epoch: epoch, Path: path, File: cf, Err: err}
+	}
+}
+
+// reloadEditedConflict re-reads the file last handed to the editor. The app
+// refreshes every plugin when the editor exits; other refreshes leave the
+// in-memory choices alone.
+func (p *Plugin) reloadEditedConflict() tea.Cmd {
+	r := p.resolver
+	if r == nil || r.editing == "" {
+		return nil
+	}
+	path := r.editing
+	r.editing = ""
+	return p.loadConflictFile(path)
+}
+
+// closeConflictResolver leaves the resolver. Unstaged choices are dropped;
+// the files on disk are untouched until a file is staged.
+func (p *Plugin) closeConflictResolver() tea.Cmd {
+	returnMode := ViewModeStatus
+	if p.resolver != nil {
+		returnMode = p.resolver.returnMode
+	}
+	p.resolver = nil
+	p.viewMode = returnMode
+	return p.refresh()
+}
+
+// handleConflictMsg handles the resolver's async results. ok is false for
+// messages that are not the resolver's.
+func (p *Plugin) handleConflictMsg(msg tea.Msg) (tea.Cmd, bool) {
+	switch msg := msg.(type) {
+	case ConflictsLoadedMsg:
+		if plugin.IsStale(p.ctx, msg) || p.resolver == nil {
+			return nil, true
+		}
+		r := p.resolver
+		r.op, r.stop, r.progress = msg.Op, msg.Stop, msg.Progress
+		// Files staged from here are no longer conflicted, but they stay in
+		// the list, ticked, so the run reads as one piece of work.
+		known := make(map[string]bool, len(r.files))
+		for _, f := range r.files {
+			known[f] = true
+		}
+		for _, f := range msg.Files {
+			if !known[f] {
+				r.files = append(r.files, f)
+			}
+			delete(r.resolved, f)
+		}
+		if len(r.files) == 0 && r.op != "" {
+			// Paused without conflicts, as a rebase does at an edit stop:
+			// the resolver is where it is continued from.
+			return nil, true
+		}
+		if len(r.files) == 0 {
+			p.resolver = nil
+			p.viewMode = ViewModeStatus
+			return tea.Batch(p.refresh(), app.ShowFlash("No conflicts to resolve")), true
+		}
+		r.fileIdx = 0
+		for i, f := range r.files {
+			if f == msg.Focus {
+				r.fileIdx = i
+			}
+		}
+		return p.selectConflictFile(r.fileIdx), true
+
+	case ConflictFileLoadedMsg:
+		if plugin.IsStale(p.ctx, msg) || p.resolver == nil {
+			return nil, true
+		}
+		r := p.resolver
+		delete(r.loadErr, msg.Path)
+		delete(r.missing, msg.Path)
+		delete(r.parsed, msg.Path)
+		switch {
+		case msg.Err != nil:
+			r.loadErr[msg.Path] = msg.Err.Error()
+		case msg.Missing:
+			r.missing[msg.Path] = true
+		default:
+			r.parsed[msg.Path] = msg.File
+		}
+		if msg.Path == r.path() {
+			r.hunkIdx = min(r.hunkIdx, max(0, len(msg.File.hunksOrNil())-1))
+			r.follow = true
+		}
+		return nil, true
+
+	case ConflictResolvedMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return nil, true
+		}
+		p.auxWriteInProgress = false
+		if msg.Err != nil {
+			return func() tea.Msg {
+				return app.ToastMsg{Message: "Stage failed: " + msg.Err.Error(), Duration: 4 * time.Second, IsError: true}
+			}, true
+		}
+		r := p.resolver
+		if r == nil {
+			return p.refresh(), true
+		}
+		r.resolved[msg.Path] = true
+		delete(r.parsed, msg.Path)
+		flash := app.ShowFlash("Resolved " + msg.Path)
+		if r.allResolved() {
+			flash = app.ShowFlash(fmt.Sprintf("All conflicts resolved · c to continue the %s", conflictOpLabel(r.op)))
+			return tea.Batch(p.refresh(), flash), true
+		}
+		for i := 1; i <= len(r.files); i++ {
+			next := (r.fileIdx + i) % len(r.files)
+			if !r.resolved[r.files[next]] {
+				return tea.Batch(p.refresh(), flash, p.selectConflictFile(next)), true
+			}
+		}
+		return tea.Batch(p.refresh(), flash), true
+
+	case ConflictContinuedMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return nil, true
+		}
+		p.auxWriteInProgress = false
+		if len(msg.Remaining) > 0 || msg.Paused {
+			// The operation moved on and stopped again.
+			returnMode := ViewModeStatus
+			if p.resolver != nil {
+				returnMode = p.resolver.returnMode
+			}
+			p.viewMode = returnMode
+			note := "stopped on new conflicts"
+			if len(msg.Remaining) == 0 {
+				note = "paused for editing"
+			}
+			return tea.Batch(p.openConflictResolver(""), p.refresh(), p.loadRecentCommits(),
+				app.ShowFlash(titleCase(conflictOpLabel(msg.Op))+" "+note)), true
+		}
+		if msg.Err != nil {
+			p.showErrorModal(titleCase(conflictOpLabel(msg.Op))+" Failed", msg.Err)
+			p.resolver = nil
+			return p.refresh(), true
+		}
+		returnMode := ViewModeStatus
+		if p.resolver != nil {
+			returnMode = p.resolver.returnMode
+		}
+		p.resolver = nil
+		p.viewMode = returnMode
+		p.pullConflictFiles = nil
+		p.pullConflictType = ""
+		return tea.Batch(p.refresh(), p.loadRecentCommits(),
+			app.ShowFlash(titleCase(conflictOpLabel(msg.Op))+" continued")), true
+	}
+	return nil, false
+}
+
+func (cf *ConflictFile) hunksOrNil() []*ConflictHunk {
+	if cf == nil {
+		return nil
+	}
+	return cf.Hunks
+}
+
+// conflictOpLabel names op for messages, defaulting to the common case.
+func conflictOpLabel(op string) string {
+	if op == "" {
+		return "merge"
+	}
+	return op
+}
+
+// selectConflictFile moves to file i, loading it unless it is already parsed.
+func (p *Plugin) selectConflictFile(i int) tea.Cmd {
+	r := p.resolver
+	if r == nil || i < 0 || i >= len(r.files) {
+		return nil
+	}
+	if i != r.fileIdx {
+		r.hunkIdx = 0
+	}
+	r.fileIdx = i
+	r.scroll = 0
+	r.follow = true
+	path := r.files[i]
+	if r.resolved[path] || r.parsed[path] != nil {
+		return nil
+	}
+	return p.loadConflictFile(path)
+}
+
+// updateConflict handles keys in the conflict resolver.
+func (p *Plugin) updateConflict(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd) {
+	r := p.resolver
+	if r == nil {
+		p.viewMode = ViewModeStatus
+		return p, nil
+	}
+	key := msg.String()
+	switch key {
+	case "esc", "q":
+		return p, p.closeConflictResolver()
+	case "j", "down", "n":
+		if cf := r.file(); cf != nil && r.hunkIdx < len(cf.Hunks)-1 {
+			r.hunkIdx++
+			r.follow = true
+		}
+		return p, nil
+	case "k", "up", "N":
+		if r.hunkIdx > 0 {
+			r.hunkIdx--
+			r.follow = true
+		}
+		return p, nil
+	case "ctrl+d":
+		r.scroll = min(r.scroll+max(1, p.height/2), r.maxScroll)
+		return p, nil
+	case "ctrl+u":
+		r.scroll = max(0, r.scroll-max(1, p.height/2))
+		return p, nil
+	case ".", "tab":
+		if len(r.files) > 0 {
+			return p, p.selectConflictFile((r.fileIdx + 1) % len(r.files))
+		}
+		return p, nil
+	case ",", "shift+tab":
+		if len(r.files) > 0 {
+			return p, p.selectConflictFile((r.fileIdx - 1 + len(r.files)) % len(r.files))
+		}
+		return p, nil
+	case "o", "t", "b", "B", "x":
+		p.chooseConflictSide(key, false)
+		return p, nil
+	case "O", "T":
+		p.chooseConflictSide(strings.ToLower(key), true)
+		return p, nil
+	case "e":
+		return p, p.editConflictFile()
+	}
+
+	if p.writeInProgress() {
+		switch key {
+		case "s", "enter", "c", "a":
+			return p, p.writeBusyToast()
+		}
+	}
+	switch key {
+	case "s", "enter":
+		return p, p.stageConflictFile()
+	case "c":
+		return p, p.continueConflictOperation()
+	case "a":
+		if r.op == "" {
+			return p, nil
+		}
+		p.pullConflictType = r.op
+		p.pullInProgress = true
+		p.pullConflictFiles = nil
+		p.resolver = nil
+		p.viewMode = ViewModeStatus
+		return p, p.doAbortPull()
+	}
+	return p, nil
+}
+
+// chooseConflictSide records a choice for the selected hunk, or for every
+// hunk still unresolved in the file when all is set.
+func (p *Plugin) chooseConflictSide(key string, all bool) {
+	r := p.resolver
+	cf := r.file()
+	if cf == nil || len(cf.Hunks) == 0 {
+		return
+	}
+	var choice ConflictChoice
+	switch key {
+	case "o":
+		choice = ChoiceOurs
+	case "t":
+		choice = ChoiceTheirs
+	case "b":
+		choice = ChoiceBoth
+	case "B":
+		choice = ChoiceBase
+	case "x":
+		choice = ChoiceUnresolved
+	}
+	if all {
+		for _, h := range cf.Hunks {
+			if h.Choice == ChoiceUnresolved {
+				h.Choice = choice
+			}
+		}
+		return
+	}
+	h := r.hunk()
+	if choice == ChoiceBase && !h.HasBase {
+		return
+	}
+	h.Choice = choice
+	if choice != ChoiceUnresolved && r.hunkIdx < len(cf.Hunks)-1 {
+		r.hunkIdx++
+		r.follow = true
+	}
+}
+
+// editConflictFile writes the choices made so far, then opens the file in
+// the editor at the selected hunk. Whatever the editor saves is re-read when
+// the app refreshes on its return.
+func (p *Plugin) editConflictFile() tea.Cmd {
+	r := p.resolver
+	path := r.path()
+	if path == "" || r.missing[path] || r.resolved[path] {
+		return nil
+	}
+	full := filepath.Join(p.repoRoot, path)
+	line := 0
+	var result string
+	cf := r.file()
+	if cf != nil {
+		result = cf.Result()
+		// Chosen hunks collapse, so count lines in the result up to the
+		// selected hunk rather than trusting its original position.
+		line = cf.resultLine(r.hunkIdx)
+	}
+	r.editing = path
+	return func() tea.Msg {
+		if cf != nil {
+			if err := os.WriteFile(full, []byte(result), 0o644); err != nil {
+				return app.ToastMsg{Message: "Write failed: " + err.Error(), Duration: 4 * time.Second, IsError: true}
+			}
+		}
+		return plugin.OpenFileMsg{Editor: tty.ResolveEditor(), Path: full, LineNo: line}
+	}
+}
+
+// resultLine returns the 1-based line at which hunk i starts in Result.
+func (cf *ConflictFile) resultLine(i int) int {
+	line := 1
+	for _, seg := range cf.Segments {
+		if seg.Hunk == nil {
+			line += len(seg.Lines)
+			continue
+		}
+		if i < len(cf.Hunks) && seg.Hunk == cf.Hunks[i] {
+			return line
+		}
+		line += len(seg.Hunk.lines())
+	}
+	return 0
+}
+
+// stageConflictFile writes the resolved file and stages it.
+func (p *Plugin) stageConflictFile() tea.Cmd {
+	r := p.resolver
+	path := r.path()
+	if path == "" || r.resolved[path] {
+		return nil
+	}
+	cf := r.file()
+	if cf == nil && !r.missing[path] {
+		if e := r.loadErr[path]; e != "" {
+			return app.ShowFlash("Resolve " + path + " in the editor (e)")
+		}
+		return nil
+	}
+	if cf != nil {
+		if n := cf.Unresolved(); n > 0 {
+			return app.ShowFlash(fmt.Sprintf("%d conflict(s) left in %s", n, path))
+		}
+	}
+	var content []byte
+	if cf != nil {
+		content = []byte(cf.Result())
+	}
+	p.auxWriteInProgress = true
+	workDir := p.repoRoot
+	epoch := p.currentEpoch()
+	return func() tea.Msg {
+		if cf != nil {
+			if err := os.WriteFile(filepath.Join(workDir, path), content, 0o644); err != nil {
+				return ConflictResolvedMsg{Epoch: epoch, Path: path, Err: err}
+			}
+		}
+		return ConflictResolvedMsg{Epoch: epoch, Path: path, Err: MarkResolved(workDir, path)}
+	}
+}
+
+// continueConflictOperation runs `git <op> --continue` once every file is
+// resolved.
+func (p *Plugin) continueConflictOperation() tea.Cmd {
+	r := p.resolver
+	if !r.allResolved() {
+		left := 0
+		for _, f := range r.files {
+			if !r.resolved[f] {
+				left++
+			}
+		}
+		return app.ShowFlash(fmt.Sprintf("%d file(s) still conflicted", left))
+	}
+	if r.op == "" {
+		return app.ShowFlash("Resolved · commit to finish")
+	}
+	p.auxWriteInProgress = true
+	workDir := p.repoRoot
+	epoch := p.currentEpoch()
+	op := r.op
+	return func() tea.Msg {
+		_, err := ContinueOperation(workDir, op)
+		msg := ConflictContinuedMsg{Epoch: epoch, Op: op, Err: err}
+		if err != nil {
+			msg.Remaining = GetConflictedFiles(workDir)
+		} else {
+			msg.Paused = ConflictOperation(workDir) != ""
+		}
+		return msg
+	}
+}
+
+// renderConflictView renders the full-screen resolver.
+func (p *Plugin) renderConflictView() string {
+	paneHeight := p.height - 2
+	contentWidth := max(20, p.width-4)
+	p.mouseHandler.Clear()
+	p.mouseHandler.HitMap.AddRect(regionConflict, 0, 0, p.width, p.height, nil)
+
+	r := p.resolver
+	var sb strings.Builder
+	sb.WriteString(p.renderConflictHeader(contentWidth))
+	sb.WriteString("\n")
+	sb.WriteString(p.renderConflictFileStrip(contentWidth))
+	sb.WriteString("\n")
+	sb.WriteString(styles.Muted.Render(strings.Repeat("━", contentWidth)))
+	sb.WriteString("\n")
+
+	visible := max(1, paneHeight-3)
+	body, selStart, selEnd := p.conflictBody(contentWidth)
+	r.maxScroll = max(0, len(body)-visible)
+	if r.follow {
+		if selStart < r.scroll || selEnd-selStart > visible {
+			r.scroll = selStart
+		} else if selEnd > r.scroll+visible {
+			r.scroll = selEnd - visible
+		}
+		r.follow = false
+	}
+	r.scroll = max(0, min(r.scroll, r.maxScroll))
+	end := min(len(body), r.scroll+visible)
+	sb.WriteString(strings.Join(body[r.scroll:end], "\n"))
+	return p.wrapDiffContent(sb.String(), paneHeight)
+}
+
+func (p *Plugin) renderConflictHeader(width int) string {
+	r := p.resolver
+	title := "Resolve conflicts"
+	if r.op != "" {
+		title += " · " + titleCase(r.op)
+	}
+	done := 0
+	for _, f := range r.files {
+		if r.resolved[f] {
+			done++
+		}
+	}
+	status := fmt.Sprintf("%d/%d files resolved", done, len(r.files))
+	if len(r.files) > 0 && done == len(r.files) {
+		status = styles.StatusStaged.Render(status + " · c to continue")
+	} else {
+		status = styles.Muted.Render(status)
+	}
+	head := styles.ModalTitle.Render(title)
+	gap := max(1, width-lipgloss.Width(head)-lipgloss.Width(status))
+	return truncateLine(head+strings.Repeat(" ", gap)+status, width)
+}
+
+// renderConflictFileStrip renders the conflicted files on one line, the
+// current one highlighted and resolved ones ticked.
+func (p *Plugin) renderConflictFileStrip(width int) string {
+	r := p.resolver
+	if len(r.files) == 0 {
+		if r.op != "" {
+			return styles.Muted.Render("No conflicted files")
+		}
+		return styles.Muted.Render("Loading conflicts...")
+	}
+	var parts []string
+	for i, f := range r.files {
+		mark := styles.StatusModified.Render("U")
+		if r.resolved[f] {
+			mark = styles.StatusStaged.Render("✓")
+		}
+		name := f
+		if i == r.fileIdx {
+			name = styles.ListItemSelected.Render(" " + f + " ")
+		}
+		parts = append(parts, mark+" "+name)
+	}
+	return truncateLine(strings.Join(parts, "  "), width)
+}
+
+// conflictBody renders the current file's hunks as lines, returning the line
+// range the selected hunk occupies.
+func (p *Plugin) conflictBody(width int) ([]string, int, int) {
+	r := p.resolver
+	path := r.path()
+	switch {
+	case path == "" && r.op != "":
+		return p.pausedBody(), 0, 0
+	case path == "":
+		return nil, 0, 0
+	case r.resolved[path]:
+		return []string{styles.StatusStaged.Render("Resolved and staged.")}, 0, 1
+	case r.missing[path]:
+		return []string{
+			styles.Muted.Render("Deleted on one side of the " + conflictOpLabel(r.op) + "."),
+			styles.Muted.Render("s stages the deletion; restore the file first to keep it."),
+		}, 0, 2
+	case r.loadErr[path] != "":
+		return []string{
+			styles.StatusDeleted.Render("Cannot parse conflict markers: " + r.loadErr[path]),
+			styles.Muted.Render("e opens the file in your editor."),
+		}, 0, 2
+	}
+	cf := r.file()
+	if cf == nil {
+		return []string{styles.Muted.Render("Loading " + path + "...")}, 0, 1
+	}
+	if len(cf.Hunks) == 0 {
+		return []string{styles.Muted.Render("No conflict markers left. s stages the file as resolved.")}, 0, 1
+	}
+
+	highlighter := p.getHighlighter(path)
+	var lines []string
+	selStart, selEnd := 0, 0
+	for i, h := range cf.Hunks {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		if i == r.hunkIdx {
+			selStart = len(lines)
+		}
+		lines = append(lines, renderConflictHunkHeader(h, i, len(cf.Hunks), i == r.hunkIdx, width))
+		// Drop the renderer's own @@ header; ours carries the labels.
+		rendered := RenderSideBySide(h.sideBySide(), width, 0, len(h.Ours)+len(h.Theirs)+1, 0, highlighter, false)
+		body := strings.Split(strings.TrimSuffix(rendered, "\n"), "\n")
+		if len(body) > 0 {
+			body = body[1:]
+		}
+		lines = append(lines, body...)
+		if h.HasBase {
+			label := "base"
+			if h.BaseLabel != "" {
+				label += " (" + h.BaseLabel + ")"
+			}
+			lines = append(lines, styles.Muted.Render("  ┄ "+label))
+			for _, l := range h.Base {
+				lines = append(lines, styles.Muted.Render(truncateLine("  │ "+l, width)))
+			}
+		}
+		if i == r.hunkIdx {
+			selEnd = len(lines)
+		}
+	}
+	return lines, selStart, selEnd
+}
+
+// pausedBody explains a stop with nothing to resolve.
+func (p *Plugin) pausedBody() []string {
+	r := p.resolver
+	where := ""
+	if r.stop != "" {
+		where = " at " + r.stop
+	}
+	lines := []string{styles.StatusInProgress.Render(titleCase(r.op) + " paused" + where + ".")}
+	if r.progress != "" {
+		lines = append(lines, styles.Muted.Render("Step "+r.progress+"."))
+	}
+	return append(lines, "",
+		styles.Muted.Render("esc to make changes and amend (A); M brings you back here."),
+		styles.Muted.Render("c continues the "+r.op+", a aborts it."),
+	)
+}
+
+func renderConflictHunkHeader(h *ConflictHunk, i, n int, selected bool, width int) string {
+	cursor := "  "
+	if selected {
+		cursor = "▸ "
+	}
+	ours, theirs := h.OursLabel, h.TheirsLabel
+	if ours == "" {
+		ours = "ours"
+	}
+	if theirs == "" {
+		theirs = "theirs"
+	}
+	text := fmt.Sprintf("%sConflict %d/%d · line %d · %s ◀ ▶ %s", cursor, i+1, n, h.Line, ours, theirs)
+	var choice string
+	if h.Choice == ChoiceUnresolved {
+		choice = styles.StatusModified.Render("[unresolved]")
+	} else {
+		choice = styles.StatusStaged.Render("[" + h.Choice.String() + " ✓]")
+	}
+	style := hunkHeaderStyle()
+	if selected {
+		style = style.Bold(true)
+	}
+	budget := max(1, width-lipgloss.Width(choice)-1)
+	return style.Render(truncateLine(text, budget)) + " " + choice
+}
+
+// handleConflictMouse scrolls the resolver with the wheel.
+func (p *Plugin) handleConflictMouse(msg tea.MouseMsg) (*Plugin, tea.Cmd) {
+	r := p.resolver
+	if r == nil {
+		return p, nil
+	}
+	action := p.mouseHandler.HandleMouse(msg)
+	switch action.Type {
+	case mouse.ActionScrollUp, mouse.ActionScrollDown:
+		r.scroll = max(0, min(r.scroll+action.Delta, r.maxScroll))
+	}
+	return p, nil
+}
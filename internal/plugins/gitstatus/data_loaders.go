@@ -1,6 +1,8 @@
 package gitstatus
 
 import (
+	"strings"
+
 	tea "charm.land/bubbletea/v2"
 )
 
@@ -14,16 +16,19 @@ func (p *Plugin) loadDiff(path string, staged bool, status FileStatus) tea.Cmd {
 	requestID := p.nextPreviewID()
 	p.fullScreenPreviewRequestID = requestID
 	epoch := p.ctx.Epoch
-	workDir := p.repoRoot
+	workDir, rel := p.gitLocation(path)
+	span := p.tree.RangeFor(path, staged)
 	return func() tea.Msg {
 		var rawDiff string
 		var err error
 
 		// Untracked files need special handling - create new file diff
 		if status == StatusUntracked {
-			rawDiff, err = GetNewFileDiff(workDir, path)
+			rawDiff, err = GetNewFileDiff(workDir, rel)
+		} else if span != "" {
+			rawDiff, err = GetRangeDiff(workDir, rel, span)
 		} else {
-			rawDiff, err = GetDiff(workDir, path, staged)
+			rawDiff, err = GetDiff(workDir, rel, staged)
 		}
 		if err != nil {
 			return DiffLoadedMsg{Epoch: epoch, RequestID: requestID, Err: err}
@@ -38,16 +43,19 @@ func (p *Plugin) loadInlineDiff(path string, staged bool, status FileStatus) tea
 	requestID := p.nextPreviewID()
 	p.inlinePreviewRequestID = requestID
 	epoch := p.ctx.Epoch
-	workDir := p.repoRoot
+	workDir, rel := p.gitLocation(path)
+	span := p.tree.RangeFor(path, staged)
 	return func() tea.Msg {
 		var rawDiff string
 		var err error
 
 		// Untracked files need special handling - create new file diff
 		if status == StatusUntracked {
-			rawDiff, err = GetNewFileDiff(workDir, path)
+			rawDiff, err = GetNewFileDiff(workDir, rel)
+		} else if span != "" {
+			rawDiff, err = GetRangeDiff(workDir, rel, span)
 		} else {
-			rawDiff, err = GetDiff(workDir, path, staged)
+			rawDiff, err = GetDiff(workDir, rel, staged)
 		}
 		if err != nil {
 			return InlineDiffLoadedMsg{Epoch: epoch, RequestID: requestID, File: path, Staged: staged, Raw: "", Parsed: nil}
@@ -209,6 +217,10 @@ func (p *Plugin) loadFullFileDiff(path string, staged bool, status FileStatus, c
 	}
 	epoch := p.ctx.Epoch
 	workDir := p.repoRoot
+	// Changes inside a submodule are read from the submodule; commits are
+	// always this repository's.
+	fileDir, rel := p.gitLocation(path)
+	span := p.tree.RangeFor(path, staged)
 	return func() tea.Msg {
 		var oldContent, newContent, rawDiff string
 
@@ -217,24 +229,29 @@ func (p *Plugin) loadFullFileDiff(path string, staged bool, status FileStatus, c
 			oldContent, _ = GetFileContentAtRef(workDir, path, commitHash+"~1")
 			newContent, _ = GetFileContentAtRef(workDir, path, commitHash)
 			rawDiff, _ = GetCommitDiff(workDir, commitHash, path, "")
+		} else if from, to, ok := strings.Cut(span, ".."); ok {
+			// A file inside a submodule's new commits: old = recorded, new = checked out
+			oldContent, _ = GetFileContentAtRef(fileDir, rel, from)
+			newContent, _ = GetFileContentAtRef(fileDir, rel, to)
+			rawDiff, _ = GetRangeDiff(fileDir, rel, span)
 		} else if status == StatusUntracked {
 			// Untracked file: old is empty, new is working tree
 			oldContent = ""
-			newContent, _ = GetWorkingTreeFileContent(workDir, path)
-			rawDiff, _ = GetNewFileDiff(workDir, path)
+			newContent, _ = GetWorkingTreeFileContent(fileDir, rel)
+			rawDiff, _ = GetNewFileDiff(fileDir, rel)
 		} else if staged {
 			// Staged file: old = HEAD, new = index
-			oldContent, _ = GetFileContentAtRef(workDir, path, "HEAD")
-			newContent, _ = GetFileContentFromIndex(workDir, path)
-			rawDiff, _ = GetDiff(workDir, path, true)
+			oldContent, _ = GetFileContentAtRef(fileDir, rel, "HEAD")
+			newContent, _ = GetFileContentFromIndex(fileDir, rel)
+			rawDiff, _ = GetDiff(fileDir, rel, true)
 		} else {
 			// Modified file: old = index (or HEAD if not staged), new = working tree
-			oldContent, _ = GetFileContentFromIndex(workDir, path)
+			oldContent, _ = GetFileContentFromIndex(fileDir, rel)
 			if oldContent == "" {
-				oldContent, _ = GetFileContentAtRef(workDir, path, "HEAD")
+				oldContent, _ = GetFileContentAtRef(fileDir, rel, "HEAD")
 			}
-			newContent, _ = GetWorkingTreeFileContent(workDir, path)
-			rawDiff, _ = GetDiff(workDir, path, false)
+			newContent, _ = GetWorkingTreeFileContent(fileDir, rel)
+			rawDiff, _ = GetDiff(fileDir, rel, false)
 		}
 
 		parsed, _ := ParseUnifiedDiff(rawDiff)
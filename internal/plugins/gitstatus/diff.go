@@ -8,9 +8,10 @@ import (
 	"strings"
 )
 
-// GetDiff returns the diff for a file.
+// GetDiff returns the diff for a file. A submodule's diff is the diff of
+// the changes inside it rather than of the commit ids it records.
 func GetDiff(workDir, path string, staged bool) (string, error) {
-	args := []string{"diff"}
+	args := []string{"diff", "--submodule=diff"}
 	if staged {
 		args = append(args, "--cached")
 	}
@@ -35,7 +36,7 @@ func GetDiff(workDir, path string, staged bool) (string, error) {
 // GetDiffFromHead returns the diff for a file comparing HEAD to working tree.
 // This captures both staged and unstaged changes in a single diff.
 func GetDiffFromHead(workDir, path string) (string, error) {
-	args := []string{"diff", "HEAD", "--", path}
+	args := []string{"diff", "--submodule=diff", "HEAD", "--", path}
 
 	cmd := gitReadOnly(args...)
 	cmd.Dir = workDir
@@ -0,0 +1,86 @@
+package gitstatus
+
+import (
+	"strings"
+
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/styles"
+)
+
+// LineNote is a remark drawn under one line of a diff, such as a review
+// comment. Notes take rows of their own but are not diff lines: scroll
+// positions, which count diff lines, do not move because of them.
+type LineNote struct {
+	// Line is the number in the new file, or in the old one when Old is set.
+	Line   int
+	Old    bool
+	Header string
+	Body   string
+	// Dim draws the note muted, as for a resolved thread.
+	Dim bool
+}
+
+// maxNoteBodyRows caps how much of one note is drawn inline.
+const maxNoteBodyRows = 4
+
+type noteAnchor struct {
+	line int
+	old  bool
+}
+
+type noteIndex map[noteAnchor][]LineNote
+
+func (d *ParsedDiff) noteIndex() noteIndex {
+	if len(d.Notes) == 0 {
+		return nil
+	}
+	idx := make(noteIndex, len(d.Notes))
+	for _, n := range d.Notes {
+		a := noteAnchor{line: n.Line, old: n.Old}
+		idx[a] = append(idx[a], n)
+	}
+	return idx
+}
+
+// newSide and oldSide are the notes anchored to a line's number in each file.
+func (idx noteIndex) newSide(line *DiffLine) []LineNote {
+	if idx == nil || line == nil || line.NewLineNo == 0 {
+		return nil
+	}
+	return idx[noteAnchor{line: line.NewLineNo}]
+}
+
+func (idx noteIndex) oldSide(line *DiffLine) []LineNote {
+	if idx == nil || line == nil || line.OldLineNo == 0 {
+		return nil
+	}
+	return idx[noteAnchor{line: line.OldLineNo, old: true}]
+}
+
+// noteRows draws notes indented by indent columns within width.
+func noteRows(notes []LineNote, indent, width int) []string {
+	avail := width - indent - 2
+	if len(notes) == 0 || avail < 8 {
+		return nil
+	}
+	pad := strings.Repeat(" ", indent)
+	var rows []string
+	for _, n := range notes {
+		bar, header, body := lipgloss.NewStyle().Foreground(styles.Accent), lipgloss.NewStyle().Bold(true), styles.Body
+		if n.Dim {
+			bar, header, body = styles.Muted, styles.Muted, styles.Muted
+		}
+		prefix := pad + bar.Render("▍") + " "
+		rows = append(rows, prefix+header.Render(truncateLine(n.Header, avail)))
+		text := strings.ReplaceAll(ansi.Strip(strings.TrimSpace(n.Body)), "\t", "    ")
+		lines := strings.Split(ansi.Wrap(text, avail, ""), "\n")
+		if len(lines) > maxNoteBodyRows {
+			lines = append(lines[:maxNoteBodyRows-1], "…")
+		}
+		for _, l := range lines {
+			rows = append(rows, prefix+body.Render(truncateLine(l, avail)))
+		}
+	}
+	return rows
+}
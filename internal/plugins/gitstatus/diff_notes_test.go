@@ -0,0 +1,81 @@
+package gitstatus
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func notedDiff() *ParsedDiff {
+	return &ParsedDiff{
+		OldFile: "a.go",
+		NewFile: "a.go",
+		Hunks: []Hunk{{
+			OldStart: 1, OldCount: 2, NewStart: 1, NewCount: 2,
+			Lines: []DiffLine{
+				{Type: LineContext, OldLineNo: 1, NewLineNo: 1, Content: "package a"},
+				{Type: LineRemove, OldLineNo: 2, Content: "var x = 1"},
+				{Type: LineAdd, NewLineNo: 2, Content: "var x = 2"},
+			},
+		}},
+		Notes: []LineNote{
+			{Line: 2, Header: "@ann", Body: "why two?"},
+			{Line: 2, Old: true, Header: "@bo", Body: "keep one", Dim: true},
+		},
+	}
+}
+
+func renderedLines(s string) []string {
+	return strings.Split(strings.TrimRight(ansi.Strip(s), "\n"), "\n")
+}
+
+func TestRenderLineDiffDrawsNotesUnderTheirLines(t *testing.T) {
+	lines := renderedLines(RenderLineDiff(notedDiff(), 80, 0, 20, 0, nil, false))
+	want := []string{"@@", "package a", "var x = 1", "@bo", "keep one", "var x = 2", "@ann", "why two?"}
+	if len(lines) != len(want) {
+		t.Fatalf("lines = %q", lines)
+	}
+	for i, w := range want {
+		if !strings.Contains(lines[i], w) {
+			t.Errorf("row %d = %q, want %q", i, lines[i], w)
+		}
+	}
+
+	// Notes are not diff lines: scrolling past the removed line skips it and
+	// its note alike, and the row budget still holds.
+	lines = renderedLines(RenderLineDiff(notedDiff(), 80, 3, 2, 0, nil, false))
+	if len(lines) != 2 || !strings.Contains(lines[0], "var x = 2") || !strings.Contains(lines[1], "@ann") {
+		t.Fatalf("scrolled = %q", lines)
+	}
+}
+
+func TestRenderSideBySideDrawsNotesUnderTheirPair(t *testing.T) {
+	lines := renderedLines(RenderSideBySide(notedDiff(), 100, 0, 20, 0, nil, false))
+	var pair int
+	for i, l := range lines {
+		if strings.Contains(l, "var x = 1") && strings.Contains(l, "var x = 2") {
+			pair = i
+		}
+	}
+	if pair == 0 || len(lines) < pair+5 {
+		t.Fatalf("lines = %q", lines)
+	}
+	for i, w := range []string{"@bo", "keep one", "@ann", "why two?"} {
+		if !strings.Contains(lines[pair+1+i], w) {
+			t.Errorf("row %d = %q, want %q", pair+1+i, lines[pair+1+i], w)
+		}
+	}
+}
+
+func TestNoteRowsCapLongBodies(t *testing.T) {
+	rows := noteRows([]LineNote{{Header: "h", Body: strings.Repeat("word ", 100)}}, 2, 40)
+	if len(rows) != 1+maxNoteBodyRows || !strings.HasSuffix(ansi.Strip(rows[len(rows)-1]), "…") {
+		t.Fatalf("rows = %q", rows)
+	}
+	for _, r := range rows {
+		if w := ansi.StringWidth(r); w > 40 {
+			t.Errorf("row %q is %d wide", r, w)
+		}
+	}
+}
@@ -51,6 +51,10 @@ type ParsedDiff struct {
 	NewFile string
 	Binary  bool
 	Hunks   []Hunk
+	// LFS is set when both sides are Git LFS pointers.
+	LFS *LFSDiff
+	// Notes are drawn under the lines they are anchored to; see LineNote.
+	Notes []LineNote
 
 	// Memoized whole-diff scans. Both are pure functions of Hunks, and both are
 	// called on every frame, so computing them once keeps redraw cost
@@ -329,6 +333,7 @@ func ParseUnifiedDiff(diff string) (*ParsedDiff, error) {
 	for i := range parsed.Hunks {
 		computeWordDiffs(&parsed.Hunks[i])
 	}
+	parsed.LFS = detectLFSDiff(parsed)
 
 	return parsed, nil
 }
@@ -428,8 +433,8 @@ type FullFileDiff struct {
 // It interleaves unchanged regions (context) with hunk changes to produce a complete
 // side-by-side view of both files with changes highlighted.
 func BuildFullFileDiff(oldContent, newContent string, parsed *ParsedDiff) *FullFileDiff {
-	// Binary files can't be rendered as full-file diff
-	if parsed != nil && parsed.Binary {
+	// Binary files and LFS pointers can't be rendered as full-file diff
+	if parsed != nil && (parsed.Binary || parsed.LFS != nil) {
 		return nil
 	}
 
@@ -2,6 +2,7 @@ package gitstatus
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"charm.land/lipgloss/v2"
@@ -62,6 +63,9 @@ func fileHeaderStyle() lipgloss.Style {
 // highlighter is optional - if nil, no syntax highlighting is applied.
 // wrapEnabled wraps long lines instead of truncating them.
 func RenderLineDiff(diff *ParsedDiff, width, startLine, maxLines, horizontalOffset int, highlighter *SyntaxHighlighter, wrapEnabled bool) string {
+	if diff != nil && diff.LFS != nil {
+		return styles.Muted.Render(renderLFSDiff(diff.LFS))
+	}
 	if diff == nil || diff.Binary {
 		if diff != nil && diff.Binary {
 			return styles.Muted.Render(" Binary file differs")
@@ -87,6 +91,7 @@ func RenderLineDiff(diff *ParsedDiff, width, startLine, maxLines, horizontalOffs
 
 	contentWidth := width - (lineNoWidth*2 + 4) // Two line numbers + separators
 	isFirstHunk := true
+	notes := diff.noteIndex()
 
 	for _, hunk := range diff.Hunks {
 		// Skip until we reach the start line
@@ -178,6 +183,17 @@ func RenderLineDiff(diff *ParsedDiff, width, startLine, maxLines, horizontalOffs
 				sb.WriteString("\n")
 				rendered++
 			}
+			if notes != nil {
+				lineNotes := slices.Concat(notes.oldSide(&line), notes.newSide(&line))
+				for _, row := range noteRows(lineNotes, lineNoWidth*2+4, width) {
+					if rendered >= maxLines {
+						break
+					}
+					sb.WriteString(row)
+					sb.WriteString("\n")
+					rendered++
+				}
+			}
 		}
 
 		if rendered >= maxLines {
@@ -192,6 +208,9 @@ func RenderLineDiff(diff *ParsedDiff, width, startLine, maxLines, horizontalOffs
 // highlighter is optional - if nil, no syntax highlighting is applied.
 // wrapEnabled wraps long lines instead of truncating them.
 func RenderSideBySide(diff *ParsedDiff, width, startLine, maxLines, horizontalOffset int, highlighter *SyntaxHighlighter, wrapEnabled bool) string {
+	if diff != nil && diff.LFS != nil {
+		return styles.Muted.Render(renderLFSDiff(diff.LFS))
+	}
 	if diff == nil || diff.Binary {
 		if diff != nil && diff.Binary {
 			return styles.Muted.Render(" Binary file differs")
@@ -214,6 +233,7 @@ func RenderSideBySide(diff *ParsedDiff, width, startLine, maxLines, horizontalOf
 		Align(lipgloss.Right)
 
 	isFirstHunk := true
+	notes := diff.noteIndex()
 	for hi := range diff.Hunks {
 		hunk := &diff.Hunks[hi]
 		if rendered >= maxLines {
@@ -351,6 +371,17 @@ func RenderSideBySide(diff *ParsedDiff, width, startLine, maxLines, horizontalOf
 				sb.WriteString("\n")
 				rendered++
 			}
+			if notes != nil {
+				lineNotes := slices.Concat(notes.oldSide(pair.left), notes.newSide(pair.right))
+				for _, row := range noteRows(lineNotes, lineNoWidth+2, width) {
+					if rendered >= maxLines {
+						break
+					}
+					sb.WriteString(row)
+					sb.WriteString("\n")
+					rendered++
+				}
+			}
 			lineNum++
 		}
 	}
@@ -1,9 +1,11 @@
 package gitstatus
 
 import (
+	"context"
 	"strings"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/marcus/sidecar/internal/undojournal"
 )
 
 func (p *Plugin) loadAmendMessage() tea.Cmd {
@@ -18,6 +20,20 @@ func (p *Plugin) loadAmendMessage() tea.Cmd {
 	}
 }
 
+// loadCommitMessage fills the commit modal with hash's message, reusing the
+// amend prefill path.
+func (p *Plugin) loadCommitMessage(hash string) tea.Cmd {
+	p.amendMessageRequestID++
+	requestID := p.amendMessageRequestID
+	p.amendMessageLoading = true
+	epoch := p.currentEpoch()
+	workDir := p.repoRoot
+	return func() tea.Msg {
+		message, err := getCommitMessage(workDir, hash)
+		return AmendMessageLoadedMsg{Epoch: epoch, RequestID: requestID, Message: message, Err: err}
+	}
+}
+
 func (p *Plugin) currentEpoch() uint64 {
 	if p.ctx == nil {
 		return 0
@@ -25,30 +41,22 @@ func (p *Plugin) currentEpoch() uint64 {
 	return p.ctx.Epoch
 }
 
-// doCommit executes the git commit asynchronously.
-func (p *Plugin) doCommit(message string) tea.Cmd {
+// doCommit executes the git commit, or amend, asynchronously.
+func (p *Plugin) doCommit(message string, opts CommitOptions) tea.Cmd {
 	workDir := p.repoRoot
 	epoch := p.currentEpoch()
-	return func() tea.Msg {
-		hash, err := ExecuteCommit(workDir, message)
-		if err != nil {
-			return CommitErrorMsg{Epoch: epoch, Err: err}
-		}
-		// Extract first line as subject
-		subject := strings.Split(message, "\n")[0]
-		return CommitSuccessMsg{Epoch: epoch, Hash: hash, Subject: subject}
+	operation := "commit"
+	if opts.Amend {
+		operation = "amend"
 	}
-}
-
-// doAmend executes git commit --amend asynchronously.
-func (p *Plugin) doAmend(message string) tea.Cmd {
-	workDir := p.repoRoot
-	epoch := p.currentEpoch()
 	return func() tea.Msg {
-		hash, err := ExecuteAmend(workDir, message)
+		record := undojournal.Begin(context.Background(), workDir, operation, undojournal.Options{Head: true, Index: true})
+		hash, err := ExecuteCommitWithOptions(context.Background(), workDir, message, opts)
+		record(err)
 		if err != nil {
 			return CommitErrorMsg{Epoch: epoch, Err: err}
 		}
+		// Extract first line as subject
 		subject := strings.Split(message, "\n")[0]
 		return CommitSuccessMsg{Epoch: epoch, Hash: hash, Subject: subject}
 	}
@@ -72,7 +80,9 @@ func (p *Plugin) doPushForce() tea.Cmd {
 	workDir := p.repoRoot
 	epoch := p.currentEpoch()
 	return func() tea.Msg {
+		record := undojournal.Begin(context.Background(), workDir, "force push", undojournal.Options{Push: forcePushUndo(workDir)})
 		output, err := ExecutePushForce(workDir)
+		record(err)
 		if err != nil {
 			return PushErrorMsg{Epoch: epoch, Err: err}
 		}
@@ -121,7 +131,9 @@ func (p *Plugin) doStashPop() tea.Cmd {
 	workDir := p.repoRoot
 	epoch := p.currentEpoch()
 	return func() tea.Msg {
+		record := undojournal.Begin(context.Background(), workDir, "stash pop", undojournal.Options{Stash: "stash@{0}"})
 		err := StashPop(workDir)
+		record(err)
 		return StashResultMsg{Epoch: epoch, Operation: "pop", Ref: "stash@{0}", Err: err}
 	}
 }
@@ -158,7 +170,9 @@ func (p *Plugin) doPull() tea.Cmd {
 	workDir := p.repoRoot
 	epoch := p.currentEpoch()
 	return func() tea.Msg {
+		record := undojournal.Begin(context.Background(), workDir, "pull", undojournal.Options{Head: true})
 		output, err := ExecutePull(workDir)
+		record(err)
 		if err != nil {
 			return PullErrorMsg{Epoch: epoch, Err: err, Strategy: "merge"}
 		}
@@ -171,7 +185,9 @@ func (p *Plugin) doPullRebase() tea.Cmd {
 	workDir := p.repoRoot
 	epoch := p.currentEpoch()
 	return func() tea.Msg {
+		record := undojournal.Begin(context.Background(), workDir, "pull --rebase", undojournal.Options{Head: true})
 		output, err := ExecutePullRebase(workDir)
+		record(err)
 		if err != nil {
 			return PullErrorMsg{Epoch: epoch, Err: err, Strategy: "rebase"}
 		}
@@ -184,7 +200,9 @@ func (p *Plugin) doPullFFOnly() tea.Cmd {
 	workDir := p.repoRoot
 	epoch := p.currentEpoch()
 	return func() tea.Msg {
+		record := undojournal.Begin(context.Background(), workDir, "pull --ff-only", undojournal.Options{Head: true})
 		output, err := ExecutePullFFOnly(workDir)
+		record(err)
 		if err != nil {
 			return PullErrorMsg{Epoch: epoch, Err: err, Strategy: "ff-only"}
 		}
@@ -197,7 +215,9 @@ func (p *Plugin) doPullAutostash() tea.Cmd {
 	workDir := p.repoRoot
 	epoch := p.currentEpoch()
 	return func() tea.Msg {
+		record := undojournal.Begin(context.Background(), workDir, "pull --autostash", undojournal.Options{Head: true})
 		output, err := ExecutePullAutostash(workDir)
+		record(err)
 		if err != nil {
 			return PullErrorMsg{Epoch: epoch, Err: err, Strategy: "autostash"}
 		}
@@ -211,13 +231,7 @@ func (p *Plugin) doAbortPull() tea.Cmd {
 	epoch := p.currentEpoch()
 	conflictType := p.pullConflictType
 	return func() tea.Msg {
-		var err error
-		if conflictType == "rebase" {
-			err = AbortRebase(workDir)
-		} else {
-			err = AbortMerge(workDir)
-		}
-		if err != nil {
+		if err := AbortOperation(workDir, conflictType); err != nil {
 			return PullErrorMsg{Epoch: epoch, Err: err}
 		}
 		return PullAbortedMsg{Epoch: epoch}
@@ -238,20 +252,22 @@ func (p *Plugin) doDiscard(entry *FileEntry) tea.Cmd {
 		return p.writeBusyToast()
 	}
 	p.auxWriteInProgress = true
-	workDir := p.repoRoot
+	workDir, path := p.gitLocation(entry.Path)
 	epoch := p.currentEpoch()
 	return func() tea.Msg {
+		record := undojournal.Begin(context.Background(), workDir, "discard", undojournal.Options{Index: true, Files: []string{path}})
 		var err error
 		if entry.Status == StatusUntracked {
 			// Remove untracked file
-			err = DiscardUntracked(workDir, entry.Path)
+			err = DiscardUntracked(workDir, path)
 		} else if entry.Staged {
 			// Unstage and restore staged file
-			err = DiscardStaged(workDir, entry.Path)
+			err = DiscardStaged(workDir, path)
 		} else {
 			// Restore modified file
-			err = DiscardModified(workDir, entry.Path)
+			err = DiscardModified(workDir, path)
 		}
+		record(err)
 		if err != nil {
 			return DiscardResultMsg{Epoch: epoch, Err: err}
 		}
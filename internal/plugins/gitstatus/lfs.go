@@ -0,0 +1,241 @@
+package gitstatus
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/marcus/sidecar/internal/docview"
+)
+
+// lfsPointerVersion is the first line of every Git LFS pointer file.
+const lfsPointerVersion = "version https://git-lfs.github.com/spec/v1"
+
+// maxLFSPointerSize bounds what is worth parsing as a pointer; the spec caps
+// pointer files at 1024 bytes.
+const maxLFSPointerSize = 1024
+
+// maxLFSLookups bounds how many changed LFS files a status load reads
+// pointers for, so a bulk change to a media directory stays cheap.
+const maxLFSLookups = 64
+
+// LFSPointer is the small text file git stores in place of an LFS object.
+type LFSPointer struct {
+	OID  string // sha256 of the object
+	Size int64
+}
+
+// LFSObject is what the status tree knows about a changed file stored in LFS:
+// its pointer, and whether the object itself is in the local LFS store.
+type LFSObject struct {
+	Pointer LFSPointer
+	Fetched bool
+}
+
+// Label is the short description drawn in place of line counts.
+func (o *LFSObject) Label() string {
+	label := "LFS " + docview.FormatSize(o.Pointer.Size)
+	if !o.Fetched {
+		label += " not fetched"
+	}
+	return label
+}
+
+// ParseLFSPointer parses content as an LFS pointer file.
+func ParseLFSPointer(content string) (LFSPointer, bool) {
+	if len(content) > maxLFSPointerSize || !strings.HasPrefix(content, lfsPointerVersion) {
+		return LFSPointer{}, false
+	}
+	var p LFSPointer
+	for line := range strings.SplitSeq(content, "\n") {
+		key, value, _ := strings.Cut(strings.TrimSpace(line), " ")
+		switch key {
+		case "oid":
+			p.OID, _ = strings.CutPrefix(value, "sha256:")
+		case "size":
+			p.Size, _ = strconv.ParseInt(value, 10, 64)
+		}
+	}
+	if len(p.OID) != 64 {
+		return LFSPointer{}, false
+	}
+	return p, true
+}
+
+// lfsObjectPath is where git-lfs keeps the object in the repository's
+// common git dir.
+func lfsObjectPath(commonDir, oid string) string {
+	return filepath.Join(commonDir, "lfs", "objects", oid[:2], oid[2:4], oid)
+}
+
+// loadLFSContext marks the changed files git stores in LFS with their pointer
+// and fetch state. A staged entry describes the index's pointer. An unstaged or
+// untracked entry describes the worktree file: its pointer when it was never
+// smudged, and otherwise the content itself, which is on disk and so fetched.
+func (t *FileTree) loadLFSContext(ctx context.Context) {
+	byPath := make(map[string][]*FileEntry)
+	staged := make(map[*FileEntry]bool)
+	var paths []string
+	for i, list := range [][]*FileEntry{t.Staged, t.Modified, t.Untracked} {
+		for _, entry := range list {
+			if entry.IsFolder || entry.Submodule != nil || entry.Status == StatusDeleted {
+				continue
+			}
+			if byPath[entry.Path] == nil {
+				paths = append(paths, entry.Path)
+			}
+			byPath[entry.Path] = append(byPath[entry.Path], entry)
+			staged[entry] = i == 0
+		}
+	}
+	if len(paths) == 0 {
+		return
+	}
+	cmd := gitReadOnlyContext(ctx, "check-attr", "-z", "--stdin", "filter")
+	cmd.Dir = t.workDir
+	cmd.Stdin = strings.NewReader(strings.Join(paths, "\x00") + "\x00")
+	out, err := cmd.Output()
+	if err != nil {
+		return
+	}
+	// Output is path NUL attribute NUL value NUL, per path.
+	fields := bytes.Split(out, []byte{0})
+	var lfsPaths []string
+	for i := 0; i+2 < len(fields); i += 3 {
+		if string(fields[2+i]) == "lfs" {
+			lfsPaths = append(lfsPaths, string(fields[i]))
+		}
+	}
+	if len(lfsPaths) == 0 {
+		return
+	}
+	commonDir := gitCommonDir(ctx, t.workDir)
+	object := func(p LFSPointer) *LFSObject {
+		obj := &LFSObject{Pointer: p}
+		if commonDir != "" {
+			_, statErr := os.Stat(lfsObjectPath(commonDir, p.OID))
+			obj.Fetched = statErr == nil
+		}
+		return obj
+	}
+	for _, path := range lfsPaths[:min(len(lfsPaths), maxLFSLookups)] {
+		var index, worktree *LFSObject
+		for _, entry := range byPath[path] {
+			if staged[entry] {
+				if index == nil {
+					if p, ok := t.readIndexLFSPointer(ctx, path); ok {
+						index = object(p)
+					}
+				}
+				entry.LFS = index
+				continue
+			}
+			if worktree == nil {
+				worktree = t.readWorktreeLFSObject(path, object)
+			}
+			entry.LFS = worktree
+		}
+	}
+}
+
+// readWorktreeLFSObject describes the worktree copy of an LFS file. A file
+// that is still a pointer names its object; a smudged file is the object's
+// content, so only its size is known without hashing it, and it is local.
+func (t *FileTree) readWorktreeLFSObject(path string, object func(LFSPointer) *LFSObject) *LFSObject {
+	full := filepath.Join(t.workDir, path)
+	info, err := os.Stat(full)
+	if err != nil {
+		return nil
+	}
+	if info.Size() <= maxLFSPointerSize {
+		if data, err := os.ReadFile(full); err == nil {
+			if p, ok := ParseLFSPointer(string(data)); ok {
+				return object(p)
+			}
+		}
+	}
+	return &LFSObject{Pointer: LFSPointer{Size: info.Size()}, Fetched: true}
+}
+
+func (t *FileTree) readIndexLFSPointer(ctx context.Context, path string) (LFSPointer, bool) {
+	cmd := gitReadOnlyContext(ctx, "cat-file", "blob", ":"+path)
+	cmd.Dir = t.workDir
+	out, err := cmd.Output()
+	if err != nil {
+		return LFSPointer{}, false
+	}
+	return ParseLFSPointer(string(out))
+}
+
+func gitCommonDir(ctx context.Context, workDir string) string {
+	cmd := gitReadOnlyContext(ctx, "rev-parse", "--git-common-dir")
+	cmd.Dir = workDir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	dir := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(workDir, dir)
+	}
+	return dir
+}
+
+// LFSDiff is a diff between LFS pointers, which says nothing line by line;
+// it is rendered as the objects' identities and sizes instead.
+type LFSDiff struct {
+	Old *LFSPointer // nil for a new file
+	New *LFSPointer // nil for a deleted file
+}
+
+// detectLFSDiff recognizes a single-hunk diff whose sides are LFS pointers.
+func detectLFSDiff(parsed *ParsedDiff) *LFSDiff {
+	if len(parsed.Hunks) != 1 {
+		return nil
+	}
+	var oldText, newText strings.Builder
+	for _, line := range parsed.Hunks[0].Lines {
+		if line.Type != LineAdd {
+			oldText.WriteString(line.Content + "\n")
+		}
+		if line.Type != LineRemove {
+			newText.WriteString(line.Content + "\n")
+		}
+	}
+	d := &LFSDiff{}
+	if p, ok := ParseLFSPointer(oldText.String()); ok {
+		d.Old = &p
+	}
+	if p, ok := ParseLFSPointer(newText.String()); ok {
+		d.New = &p
+	}
+	// Both sides must be pointers, or absent when the file is added or
+	// deleted; a text file that once held a pointer is diffed normally.
+	if (d.Old == nil && oldText.Len() > 0) || (d.New == nil && newText.Len() > 0) || (d.Old == nil && d.New == nil) {
+		return nil
+	}
+	return d
+}
+
+// renderLFSDiff describes an LFS pointer diff.
+func renderLFSDiff(d *LFSDiff) string {
+	describe := func(p *LFSPointer) string {
+		if p == nil {
+			return "none"
+		}
+		return docview.FormatSize(p.Size) + " · sha256:" + p.OID[:12]
+	}
+	lines := []string{" Git LFS object"}
+	switch {
+	case d.Old == nil:
+		lines = append(lines, " added:   "+describe(d.New))
+	case d.New == nil:
+		lines = append(lines, " deleted: "+describe(d.Old))
+	default:
+		lines = append(lines, " before:  "+describe(d.Old), " after:   "+describe(d.New))
+	}
+	return strings.Join(lines, "\n")
+}
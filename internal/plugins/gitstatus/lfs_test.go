@@ -0,0 +1,136 @@
+package gitstatus
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+const (
+	testLFSOID   = "4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393"
+	testLFSOther = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+)
+
+func lfsPointer(oid string, size int64) string {
+	return lfsPointerVersion + "\noid sha256:" + oid + "\nsize " + strconv.FormatInt(size, 10) + "\n"
+}
+
+func TestParseLFSPointer(t *testing.T) {
+	p, ok := ParseLFSPointer(lfsPointer(testLFSOID, 12345))
+	if !ok || p.OID != testLFSOID || p.Size != 12345 {
+		t.Fatalf("ParseLFSPointer = %+v, %v", p, ok)
+	}
+	for _, content := range []string{
+		"plain text\n",
+		lfsPointerVersion + "\noid sha256:short\nsize 1\n",
+	} {
+		if _, ok := ParseLFSPointer(content); ok {
+			t.Fatalf("parsed %q as a pointer", content)
+		}
+	}
+}
+
+func TestDetectLFSDiff(t *testing.T) {
+	diff := "diff --git a/video.bin b/video.bin\n" +
+		"index 1111111..2222222 100644\n" +
+		"--- a/video.bin\n" +
+		"+++ b/video.bin\n" +
+		"@@ -1,3 +1,3 @@\n" +
+		" " + lfsPointerVersion + "\n" +
+		"-oid sha256:" + testLFSOID + "\n" +
+		"-size 100\n" +
+		"+oid sha256:" + testLFSOther + "\n" +
+		"+size 2048\n"
+	parsed, err := ParseUnifiedDiff(diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.LFS == nil || parsed.LFS.Old.OID != testLFSOID || parsed.LFS.New.Size != 2048 {
+		t.Fatalf("LFS = %+v", parsed.LFS)
+	}
+	if BuildFullFileDiff("", "", parsed) != nil {
+		t.Fatal("full-file diff built for an LFS pointer diff")
+	}
+
+	text := "diff --git a/notes.txt b/notes.txt\n" +
+		"--- a/notes.txt\n" +
+		"+++ b/notes.txt\n" +
+		"@@ -1 +1 @@\n" +
+		"-old\n" +
+		"+new\n"
+	if parsed, err := ParseUnifiedDiff(text); err != nil || parsed.LFS != nil {
+		t.Fatalf("text diff LFS = %+v, err=%v", parsed.LFS, err)
+	}
+}
+
+func TestLoadLFSContextMarksFetchState(t *testing.T) {
+	repo := initTestRepo(t)
+	if err := os.WriteFile(filepath.Join(repo, ".gitattributes"), []byte("*.bin filter=lfs diff=lfs merge=lfs -text\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGitTest(t, repo, "add", ".gitattributes")
+	runGitTest(t, repo, "commit", "-qm", "track bin")
+	// Without git-lfs installed the filter is undefined, so the pointer is
+	// stored and checked out verbatim — as in a clone that never smudged.
+	if err := os.WriteFile(filepath.Join(repo, "video.bin"), []byte(lfsPointer(testLFSOID, 5*1024*1024)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tree := NewFileTree(repo)
+	if err := tree.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if len(tree.Untracked) != 1 || tree.Untracked[0].LFS == nil {
+		t.Fatalf("untracked = %+v", tree.Untracked)
+	}
+	obj := tree.Untracked[0].LFS
+	if obj.Fetched || obj.Label() != "LFS 5.0MB not fetched" {
+		t.Fatalf("object = %+v, label %q", obj, obj.Label())
+	}
+
+	store := lfsObjectPath(filepath.Join(repo, ".git"), testLFSOID)
+	if err := os.MkdirAll(filepath.Dir(store), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(store, []byte("payload"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := tree.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if obj := tree.Untracked[0].LFS; obj == nil || !obj.Fetched {
+		t.Fatalf("object after fetch = %+v", obj)
+	}
+}
+
+func TestLoadLFSContextDescribesASmudgedModificationFromTheWorktree(t *testing.T) {
+	repo := initTestRepo(t)
+	if err := os.WriteFile(filepath.Join(repo, ".gitattributes"), []byte("*.bin filter=lfs diff=lfs merge=lfs -text\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	video := filepath.Join(repo, "video.bin")
+	if err := os.WriteFile(video, []byte(lfsPointer(testLFSOID, 5*1024*1024)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGitTest(t, repo, "add", ".")
+	runGitTest(t, repo, "commit", "-qm", "add video")
+	// A smudged checkout holds the object's content, not the pointer; an
+	// unstaged edit leaves the index still naming the committed object.
+	if err := os.WriteFile(video, bytes.Repeat([]byte{'x'}, 3*1024), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tree := NewFileTree(repo)
+	if err := tree.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if len(tree.Modified) != 1 || tree.Modified[0].LFS == nil {
+		t.Fatalf("modified = %+v", tree.Modified)
+	}
+	if obj := tree.Modified[0].LFS; !obj.Fetched || obj.Label() != "LFS 3.0KB" {
+		t.Fatalf("object = %+v, label %q, want the worktree file", obj, obj.Label())
+	}
+
+}
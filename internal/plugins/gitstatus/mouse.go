@@ -46,6 +46,15 @@ func (p *Plugin) WheelAtBoundary(msg tea.MouseWheelMsg) bool {
 	if p.viewMode == ViewModeDiff {
 		return (sharedscroll.Bounds{Position: p.diffScroll, Maximum: p.diffMaxScroll()}).AtBoundary(action.Delta)
 	}
+	if p.viewMode == ViewModeConflict && p.resolver != nil {
+		return (sharedscroll.Bounds{Position: p.resolver.scroll, Maximum: p.resolver.maxScroll}).AtBoundary(action.Delta)
+	}
+	if p.viewMode == ViewModeRebase && p.rebase != nil {
+		return (sharedscroll.Bounds{Position: p.rebase.cursor, Maximum: len(p.rebase.plan.Steps) - 1}).AtBoundary(action.Delta)
+	}
+	if p.viewMode == ViewModeUndo && p.undo != nil {
+		return (sharedscroll.Bounds{Position: p.undo.cursor, Maximum: max(0, p.undo.rows()-1)}).AtBoundary(action.Delta)
+	}
 	if p.viewMode != ViewModeStatus || p.tree == nil {
 		return false
 	}
@@ -102,6 +111,9 @@ func (p *Plugin) modalWheelAtBoundary(msg tea.MouseWheelMsg) (bounded, ok bool)
 		return p.stashPopModal != nil && p.stashPopModal.WheelAtBoundary(msg, p.mouseHandler), true
 	case ViewModeError:
 		return p.errorModal != nil && p.errorModal.WheelAtBoundary(msg, p.mouseHandler), true
+	case ViewModeCommitActions:
+		m := p.activeCommitActionsModal()
+		return m != nil && m.WheelAtBoundary(msg, p.mouseHandler), true
 	}
 	return false, false
 }
@@ -202,8 +214,8 @@ func (p *Plugin) handleMouseClick(action mouse.MouseAction) (*Plugin, tea.Cmd) {
 			entries := p.tree.AllEntries()
 			if idx < len(entries) {
 				entry := entries[idx]
-				// For folders, toggle expansion on click (not just double-click)
-				if entry.IsFolder {
+				// For folders and submodules, toggle expansion on click (not just double-click)
+				if entry.IsFolder || (entry.Submodule != nil && len(entry.Children) > 0) {
 					p.cursor = idx
 					p.ensureCursorVisible()
 					entry.IsExpanded = !entry.IsExpanded
@@ -496,15 +508,15 @@ func (p *Plugin) handleCommitMouse(msg tea.MouseMsg) (*Plugin, tea.Cmd) {
 	}
 
 	action := p.commitModal.HandleMouse(msg, p.mouseHandler)
+	if p.conventionalPickers() {
+		// Picking a type or scope from a dropdown is a click.
+		p.syncConventionalHeader()
+	}
 	switch action {
 	case commitActionID:
 		return p, p.tryCommit()
 	case "cancel":
-		p.viewMode = ViewModeStatus
-		p.commitAmend = false
-		p.commitError = ""
-		p.commitModal = nil
-		p.commitModalWidthCache = 0
+		p.cancelCommitModal()
 		return p, nil
 	}
 
@@ -599,6 +611,8 @@ func (p *Plugin) handlePullConflictMouse(msg tea.MouseMsg) (*Plugin, tea.Cmd) {
 
 	action := p.pullConflictModal.HandleMouse(msg, p.mouseHandler)
 	switch action {
+	case pullConflictResolveID:
+		return p, p.openConflictResolver("")
 	case pullConflictAbortID:
 		plug, cmd := p.abortPullConflict()
 		return plug.(*Plugin), cmd
@@ -12,6 +12,7 @@ import (
 	"charm.land/lipgloss/v2"
 	"github.com/marcus/sidecar/internal/app"
 	"github.com/marcus/sidecar/internal/gitinit"
+	"github.com/marcus/sidecar/internal/gitstate"
 	"github.com/marcus/sidecar/internal/modal"
 	"github.com/marcus/sidecar/internal/mouse"
 	"github.com/marcus/sidecar/internal/notify"
@@ -43,6 +44,10 @@ const (
 	ViewModeConfirmStashPop                 // Confirm stash pop modal
 	ViewModePullConflict                    // Pull conflict resolution modal
 	ViewModeError                           // Generic error modal for git operation failures
+	ViewModeConflict                        // Full-screen merge conflict resolver
+	ViewModeRebase                          // Full-screen interactive rebase editor
+	ViewModeCommitActions                   // Cherry-pick, revert, reset, branch and tag modals
+	ViewModeUndo                            // Full-screen undo journal and reflog browser
 )
 
 // FocusPane represents which pane is active in the three-pane view.
@@ -144,6 +149,19 @@ type Plugin struct {
 	pullConflictModal *modal.Modal
 	pullConflictWidth int
 
+	// Conflict resolver state (nil unless ViewModeConflict)
+	resolver *conflictResolver
+
+	// Interactive rebase editor state (nil unless ViewModeRebase, or while
+	// the commit modal rewords one of its steps)
+	rebase *rebaseEditor
+
+	// Commit actions state (nil unless ViewModeCommitActions)
+	commitActions *commitActionState
+
+	// Undo history browser state (nil unless ViewModeUndo)
+	undo *undoBrowser
+
 	// View dimensions
 	width  int
 	height int
@@ -190,6 +208,7 @@ type Plugin struct {
 	commitModalWidthCache int
 	amendMessageRequestID uint64
 	amendMessageLoading   bool
+	commitTemplate        commitTemplate
 
 	// Mouse support
 	mouseHandler  *mouse.Handler
@@ -388,6 +407,14 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 			return p.updatePullMenu(msg)
 		case ViewModePullConflict:
 			return p.updatePullConflict(msg)
+		case ViewModeConflict:
+			return p.updateConflict(msg)
+		case ViewModeRebase:
+			return p.updateRebase(msg)
+		case ViewModeCommitActions:
+			return p.updateCommitActions(msg)
+		case ViewModeUndo:
+			return p.updateUndo(msg)
 		case ViewModeConfirmDiscard:
 			return p.updateConfirmDiscard(msg)
 		case ViewModeConfirmStashPop:
@@ -418,6 +445,14 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 			return p.handlePullMenuMouse(msg)
 		case ViewModePullConflict:
 			return p.handlePullConflictMouse(msg)
+		case ViewModeConflict:
+			return p.handleConflictMouse(msg)
+		case ViewModeRebase:
+			return p.handleRebaseMouse(msg)
+		case ViewModeCommitActions:
+			return p.handleCommitActionsMouse(msg)
+		case ViewModeUndo:
+			return p.handleUndoMouse(msg)
 		case ViewModeConfirmDiscard:
 			return p.handleDiscardMouse(msg)
 		case ViewModeConfirmStashPop:
@@ -430,7 +465,7 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		if p.inNoRepoMode() {
 			return p, p.detectRepo()
 		}
-		return p, p.refresh()
+		return p, tea.Batch(p.refresh(), p.reloadEditedConflict())
 
 	case app.PluginFocusedMsg:
 		if p.inNoRepoMode() {
@@ -476,16 +511,35 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		}
 		return p, tea.Batch(p.refresh(), history, p.listenForWatchEvents())
 
+	case CherryPickTargetsLoadedMsg:
+		if !plugin.IsStale(p.ctx, msg) {
+			p.handleCherryPickTargets(msg)
+		}
+		return p, nil
+
 	case operationResultMsg:
 		if plugin.IsStale(p.ctx, msg) || p.activeOperation == nil || p.activeOperation.ID != msg.ID {
 			return p, nil
 		}
 		p.activeOperation = nil
+		var history tea.Cmd
+		if msg.Kind.movesHistory() {
+			history = p.loadRecentCommits()
+		}
 		if msg.Err != nil {
 			p.operationSelection = selectionIdentity{}
+			// A cherry-pick or revert here that stopped on conflicts is
+			// finished in the resolver, like a stopped pull.
+			if history != nil && msg.WorkDir == p.repoRoot && ConflictOperation(p.repoRoot) != "" {
+				note := titleCase(string(msg.Kind)) + " stopped on conflicts"
+				return p, tea.Batch(p.refresh(), history, p.openConflictResolver(""), app.ShowFlash(note))
+			}
 			return p, remoteFailureAlert(titleCase(string(msg.Kind)), msg.Err)
 		}
-		return p, p.refresh()
+		if msg.Done != "" {
+			return p, tea.Batch(p.refresh(), history, app.ShowFlash(msg.Done))
+		}
+		return p, tea.Batch(p.refresh(), history)
 
 	case DiscardResultMsg:
 		if plugin.IsStale(p.ctx, msg) {
@@ -528,11 +582,14 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 			p.cursor = maxCursor
 		}
 		p.restoreOperationSelection()
+		// Other surfaces decorate files with this status rather than running
+		// git themselves.
+		share := gitstate.Publish(msg.Tree.Snapshot)
 		// Auto-load preview for current cursor position after refresh
 		if p.viewMode == ViewModeStatus {
-			return p, tea.Batch(p.autoLoadPreview(true), followUp)
+			return p, tea.Batch(p.autoLoadPreview(true), followUp, share)
 		}
-		return p, followUp
+		return p, tea.Batch(followUp, share)
 
 	case DiffLoadedMsg:
 		if plugin.IsStale(p.ctx, msg) || msg.RequestID != p.fullScreenPreviewRequestID {
@@ -585,9 +642,17 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 			p.commitError = "Load amend message: " + msg.Err.Error()
 			return p, nil
 		}
-		if p.viewMode == ViewModeCommit && p.commitAmend && strings.TrimSpace(p.commitMessage.Value()) == "" {
+		if p.viewMode == ViewModeCommit && (p.commitAmend || p.rewording()) && strings.TrimSpace(p.commitMessage.Value()) == "" {
 			p.commitMessage.SetValue(msg.Message)
+			p.syncConventionalPickers()
+		}
+		return p, nil
+
+	case CommitContextLoadedMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
 		}
+		p.handleCommitContextLoaded(msg)
 		return p, nil
 
 	case CommitErrorMsg:
@@ -1036,6 +1101,29 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		p.width = msg.Width
 		p.height = msg.Height
 		return p, p.ensureCommitListFilled()
+
+	case ConflictsLoadedMsg, ConflictFileLoadedMsg, ConflictResolvedMsg, ConflictContinuedMsg:
+		cmd, _ := p.handleConflictMsg(msg)
+		return p, cmd
+
+	case RebaseDoneMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		return p, p.handleRebaseDone(msg)
+
+	case UndoHistoryLoadedMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		p.handleUndoHistoryLoaded(msg)
+		return p, nil
+
+	case UndoDoneMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		return p, p.handleUndoDone(msg)
 	}
 
 	return p, nil
@@ -1098,6 +1186,14 @@ func (p *Plugin) View(width, height int) string {
 			content = p.renderPullMenu()
 		case ViewModePullConflict:
 			content = p.renderPullConflict()
+		case ViewModeConflict:
+			content = p.renderConflictView()
+		case ViewModeRebase:
+			content = p.renderRebaseView()
+		case ViewModeCommitActions:
+			content = p.renderCommitActions()
+		case ViewModeUndo:
+			content = p.renderUndoView()
 		case ViewModeConfirmDiscard:
 			content = p.renderConfirmDiscard()
 		case ViewModeConfirmStashPop:
@@ -1150,6 +1246,7 @@ func (p *Plugin) Commands() []plugin.Command {
 		{ID: "push", Name: "Push", Description: "Push commits to remote", Category: plugin.CategoryGit, Context: "git-status", Priority: 2},
 		{ID: "open-file", Name: "Open", Description: "Open file in editor", Category: plugin.CategoryActions, Context: "git-status", Priority: 3},
 		{ID: "discard-changes", Name: "Discard", Description: "Discard changes to file", Category: plugin.CategoryGit, Context: "git-status", Priority: 3},
+		{ID: "update-submodule", Name: "Submodule", Description: "Check the submodule out at its recorded commit", Category: plugin.CategoryGit, Context: "git-status", Priority: 4},
 		{ID: "branch-picker", Name: "Branch", Description: "Switch branch", Category: plugin.CategoryGit, Context: "git-status", Priority: 3},
 		{ID: "fetch", Name: "Fetch", Description: "Fetch from remote", Category: plugin.CategoryGit, Context: "git-status", Priority: 3},
 		{ID: "pull", Name: "Pull", Description: "Pull from remote", Category: plugin.CategoryGit, Context: "git-status", Priority: 3},
@@ -1158,7 +1255,9 @@ func (p *Plugin) Commands() []plugin.Command {
 		{ID: "stash-pop", Name: "Pop", Description: "Pop latest stash", Category: plugin.CategoryGit, Context: "git-status", Priority: 4},
 		{ID: "stash-apply", Name: "Apply", Description: "Apply latest stash", Category: plugin.CategoryGit, Context: "git-status", Priority: 4},
 		{ID: "open-in-file-browser", Name: "Browse", Description: "Open file in file browser", Category: plugin.CategoryNavigation, Context: "git-status", Priority: 4},
-		{ID: "open-in-github", Name: "GitHub", Description: "Open commit in GitHub", Category: plugin.CategoryActions, Context: "git-status", Priority: 4},
+		{ID: "open-in-github", Name: "Web", Description: "Open commit on the forge", Category: plugin.CategoryActions, Context: "git-status", Priority: 4},
+		{ID: "resolve-conflicts", Name: "Resolve", Description: "Resolve merge conflicts", Category: plugin.CategoryGit, Context: "git-status", Priority: 4},
+		{ID: "undo-history", Name: "Undo", Description: "Browse and undo recent writes and HEAD moves", Category: plugin.CategoryGit, Context: "git-status", Priority: 4},
 		{ID: "toggle-sidebar", Name: "Sidebar", Description: "Toggle sidebar visibility", Category: plugin.CategoryView, Context: "git-status", Priority: 5},
 		// git-status-commits context (recent commits in sidebar)
 		{ID: "view-commit", Name: "View", Description: "View commit details", Category: plugin.CategoryView, Context: "git-status-commits", Priority: 1},
@@ -1173,8 +1272,11 @@ func (p *Plugin) Commands() []plugin.Command {
 		{ID: "prev-match", Name: "Prev", Description: "Previous search match", Category: plugin.CategoryNavigation, Context: "git-status-commits", Priority: 4},
 		{ID: "yank-commit", Name: "Yank", Description: "Copy commit as markdown", Category: plugin.CategoryActions, Context: "git-status-commits", Priority: 3},
 		{ID: "yank-id", Name: "YankID", Description: "Copy commit ID", Category: plugin.CategoryActions, Context: "git-status-commits", Priority: 3},
-		{ID: "open-in-github", Name: "GitHub", Description: "Open commit in GitHub", Category: plugin.CategoryActions, Context: "git-status-commits", Priority: 3},
+		{ID: "open-in-github", Name: "Web", Description: "Open commit on the forge", Category: plugin.CategoryActions, Context: "git-status-commits", Priority: 3},
 		{ID: "toggle-graph", Name: "Graph", Description: "Toggle commit graph display", Category: plugin.CategoryView, Context: "git-status-commits", Priority: 2},
+		{ID: "rebase", Name: "Rebase", Description: "Interactively rebase unpushed commits down to this one", Category: plugin.CategoryGit, Context: "git-status-commits", Priority: 3},
+		{ID: "commit-actions", Name: "Actions", Description: "Cherry-pick, revert, reset to, branch or tag this commit", Category: plugin.CategoryGit, Context: "git-status-commits", Priority: 2},
+		{ID: "undo-history", Name: "Undo", Description: "Browse and undo recent writes and HEAD moves", Category: plugin.CategoryGit, Context: "git-status-commits", Priority: 4},
 		{ID: "toggle-sidebar", Name: "Sidebar", Description: "Toggle sidebar visibility", Category: plugin.CategoryView, Context: "git-status-commits", Priority: 5},
 		// git-history-search context (commit search modal)
 		{ID: "select", Name: "Select", Description: "Jump to selected match", Category: plugin.CategoryActions, Context: "git-history-search", Priority: 1},
@@ -1190,7 +1292,7 @@ func (p *Plugin) Commands() []plugin.Command {
 		{ID: "back", Name: "Back", Description: "Return to sidebar", Category: plugin.CategoryNavigation, Context: "git-commit-preview", Priority: 1},
 		{ID: "yank-commit", Name: "Yank", Description: "Copy commit as markdown", Category: plugin.CategoryActions, Context: "git-commit-preview", Priority: 3},
 		{ID: "yank-id", Name: "YankID", Description: "Copy commit ID", Category: plugin.CategoryActions, Context: "git-commit-preview", Priority: 3},
-		{ID: "open-in-github", Name: "GitHub", Description: "Open commit in GitHub", Category: plugin.CategoryActions, Context: "git-commit-preview", Priority: 3},
+		{ID: "open-in-github", Name: "Web", Description: "Open commit on the forge", Category: plugin.CategoryActions, Context: "git-commit-preview", Priority: 3},
 		{ID: "open-in-file-browser", Name: "Browse", Description: "Open file in file browser", Category: plugin.CategoryNavigation, Context: "git-commit-preview", Priority: 3},
 		{ID: "toggle-sidebar", Name: "Sidebar", Description: "Toggle sidebar visibility", Category: plugin.CategoryView, Context: "git-commit-preview", Priority: 4},
 		// git-status-diff context (inline diff pane)
@@ -1210,6 +1312,8 @@ func (p *Plugin) Commands() []plugin.Command {
 		// git-commit context
 		{ID: "execute-commit", Name: "Commit", Description: "Create commit with message", Category: plugin.CategoryGit, Context: "git-commit", Priority: 1},
 		{ID: "cancel", Name: "Cancel", Description: "Cancel commit", Category: plugin.CategoryActions, Context: "git-commit", Priority: 1},
+		{ID: "toggle-trailers", Name: "Trailers", Description: "Add or leave out the co-author and task trailers", Category: plugin.CategoryGit, Context: "git-commit", Priority: 3},
+		{ID: "toggle-signing", Name: "Sign", Description: "Sign the commit or not", Category: plugin.CategoryGit, Context: "git-commit", Priority: 3},
 		// git-push-menu context
 		{ID: "push", Name: "Push", Description: "Push to remote", Category: plugin.CategoryGit, Context: "git-push-menu", Priority: 1},
 		{ID: "force-push", Name: "Force", Description: "Force push", Category: plugin.CategoryGit, Context: "git-push-menu", Priority: 1},
@@ -1222,8 +1326,56 @@ func (p *Plugin) Commands() []plugin.Command {
 		{ID: "pull-autostash", Name: "Autostash", Description: "Pull rebase + autostash", Category: plugin.CategoryGit, Context: "git-pull-menu", Priority: 1},
 		{ID: "cancel", Name: "Cancel", Description: "Cancel", Category: plugin.CategoryNavigation, Context: "git-pull-menu", Priority: 2},
 		// git-pull-conflict context
+		{ID: "resolve-conflicts", Name: "Resolve", Description: "Resolve conflicts in Sidecar", Category: plugin.CategoryGit, Context: "git-pull-conflict", Priority: 1},
 		{ID: "abort-pull", Name: "Abort", Description: "Abort merge/rebase", Category: plugin.CategoryGit, Context: "git-pull-conflict", Priority: 1},
 		{ID: "dismiss", Name: "Dismiss", Description: "Dismiss and resolve manually", Category: plugin.CategoryNavigation, Context: "git-pull-conflict", Priority: 2},
+		// git-conflict context (conflict resolver)
+		{ID: "take-ours", Name: "Ours", Description: "Keep our side of the conflict", Category: plugin.CategoryGit, Context: "git-conflict", Priority: 1},
+		{ID: "take-theirs", Name: "Theirs", Description: "Keep their side of the conflict", Category: plugin.CategoryGit, Context: "git-conflict", Priority: 1},
+		{ID: "take-both", Name: "Both", Description: "Keep ours, then theirs", Category: plugin.CategoryGit, Context: "git-conflict", Priority: 2},
+		{ID: "take-base", Name: "Base", Description: "Keep the common ancestor", Category: plugin.CategoryGit, Context: "git-conflict", Priority: 3},
+		{ID: "take-ours-all", Name: "All ours", Description: "Keep our side for every open conflict in the file", Category: plugin.CategoryGit, Context: "git-conflict", Priority: 3},
+		{ID: "take-theirs-all", Name: "All theirs", Description: "Keep their side for every open conflict in the file", Category: plugin.CategoryGit, Context: "git-conflict", Priority: 3},
+		{ID: "clear-choice", Name: "Unset", Description: "Mark the conflict unresolved again", Category: plugin.CategoryGit, Context: "git-conflict", Priority: 4},
+		{ID: "edit-conflict", Name: "Edit", Description: "Edit the result in your editor", Category: plugin.CategoryActions, Context: "git-conflict", Priority: 2},
+		{ID: "mark-resolved", Name: "Stage", Description: "Write and stage the resolved file", Category: plugin.CategoryGit, Context: "git-conflict", Priority: 1},
+		{ID: "continue-operation", Name: "Continue", Description: "Continue the merge or rebase", Category: plugin.CategoryGit, Context: "git-conflict", Priority: 1},
+		{ID: "abort-pull", Name: "Abort", Description: "Abort merge/rebase", Category: plugin.CategoryGit, Context: "git-conflict", Priority: 3},
+		{ID: "prev-file", Name: "Prev", Description: "Previous conflicted file", Category: plugin.CategoryNavigation, Context: "git-conflict", Priority: 4},
+		{ID: "next-file", Name: "Next", Description: "Next conflicted file", Category: plugin.CategoryNavigation, Context: "git-conflict", Priority: 4},
+		{ID: "close", Name: "Close", Description: "Close the resolver", Category: plugin.CategoryNavigation, Context: "git-conflict", Priority: 2},
+		// git-rebase context (interactive rebase editor)
+		{ID: "run-rebase", Name: "Run", Description: "Run the rebase", Category: plugin.CategoryGit, Context: "git-rebase", Priority: 1},
+		{ID: "reword", Name: "Reword", Description: "Edit the commit message", Category: plugin.CategoryEdit, Context: "git-rebase", Priority: 1},
+		{ID: "squash", Name: "Squash", Description: "Meld into the older commit, keeping both messages", Category: plugin.CategoryGit, Context: "git-rebase", Priority: 1},
+		{ID: "fixup", Name: "Fixup", Description: "Meld into the older commit, dropping this message", Category: plugin.CategoryGit, Context: "git-rebase", Priority: 2},
+		{ID: "edit-commit", Name: "Edit", Description: "Stop at this commit to amend it", Category: plugin.CategoryGit, Context: "git-rebase", Priority: 2},
+		{ID: "drop", Name: "Drop", Description: "Remove the commit", Category: plugin.CategoryGit, Context: "git-rebase", Priority: 2},
+		{ID: "pick", Name: "Pick", Description: "Keep the commit as is", Category: plugin.CategoryGit, Context: "git-rebase", Priority: 3},
+		{ID: "move-down", Name: "Older", Description: "Move the commit earlier in history", Category: plugin.CategoryNavigation, Context: "git-rebase", Priority: 3},
+		{ID: "move-up", Name: "Newer", Description: "Move the commit later in history", Category: plugin.CategoryNavigation, Context: "git-rebase", Priority: 3},
+		{ID: "cancel", Name: "Cancel", Description: "Close without rebasing", Category: plugin.CategoryNavigation, Context: "git-rebase", Priority: 2},
+		// git-commit-actions context (commit actions menu)
+		{ID: "cherry-pick", Name: "Pick", Description: "Cherry-pick onto another worktree's branch", Category: plugin.CategoryGit, Context: "git-commit-actions", Priority: 1},
+		{ID: "revert-commit", Name: "Revert", Description: "Revert in a new commit", Category: plugin.CategoryGit, Context: "git-commit-actions", Priority: 1},
+		{ID: "branch-here", Name: "Branch", Description: "Create a branch at this commit", Category: plugin.CategoryGit, Context: "git-commit-actions", Priority: 2},
+		{ID: "tag-here", Name: "Tag", Description: "Create a tag at this commit", Category: plugin.CategoryGit, Context: "git-commit-actions", Priority: 2},
+		{ID: "reset-soft", Name: "Soft", Description: "Reset to here, keeping changes staged", Category: plugin.CategoryGit, Context: "git-commit-actions", Priority: 3},
+		{ID: "reset-mixed", Name: "Mixed", Description: "Reset to here, keeping changes unstaged", Category: plugin.CategoryGit, Context: "git-commit-actions", Priority: 3},
+		{ID: "reset-hard", Name: "Hard", Description: "Reset to here, discarding changes", Category: plugin.CategoryGit, Context: "git-commit-actions", Priority: 3},
+		{ID: "cancel", Name: "Cancel", Description: "Close", Category: plugin.CategoryNavigation, Context: "git-commit-actions", Priority: 2},
+		// git-commit-ref context (branch or tag name prompt)
+		{ID: "create-ref", Name: "Create", Description: "Create the branch or tag", Category: plugin.CategoryGit, Context: "git-commit-ref", Priority: 1},
+		{ID: "cancel", Name: "Cancel", Description: "Back to the actions", Category: plugin.CategoryNavigation, Context: "git-commit-ref", Priority: 2},
+		// git-confirm-reset context (reset confirmation modal)
+		{ID: "confirm-reset", Name: "Reset", Description: "Confirm the reset", Category: plugin.CategoryGit, Context: "git-confirm-reset", Priority: 1},
+		{ID: "cancel", Name: "Cancel", Description: "Back to the actions", Category: plugin.CategoryNavigation, Context: "git-confirm-reset", Priority: 2},
+		// git-undo context (undo history browser)
+		{ID: "undo-selected", Name: "Undo", Description: "Undo the selected write, or reset to the selected reflog entry", Category: plugin.CategoryGit, Context: "git-undo", Priority: 1},
+		{ID: "cancel", Name: "Close", Description: "Close the undo history", Category: plugin.CategoryNavigation, Context: "git-undo", Priority: 2},
+		// git-confirm-undo context (undo confirmation modal)
+		{ID: "confirm-undo", Name: "Undo", Description: "Confirm the undo", Category: plugin.CategoryGit, Context: "git-confirm-undo", Priority: 1},
+		{ID: "cancel", Name: "Cancel", Description: "Back to the undo history", Category: plugin.CategoryNavigation, Context: "git-confirm-undo", Priority: 2},
 		// git-error context (error modal)
 		{ID: "pull-from-error", Name: "Pull", Description: "Pull from remote", Category: plugin.CategoryGit, Context: "git-error", Priority: 1},
 		{ID: "dismiss", Name: "Dismiss", Description: "Dismiss error", Category: plugin.CategoryNavigation, Context: "git-error", Priority: 1},
@@ -1267,6 +1419,25 @@ func (p *Plugin) FocusContext() string {
 		return "git-pull-menu"
 	case ViewModePullConflict:
 		return "git-pull-conflict"
+	case ViewModeConflict:
+		return "git-conflict"
+	case ViewModeRebase:
+		return "git-rebase"
+	case ViewModeCommitActions:
+		if p.commitActions != nil {
+			switch p.commitActions.stage {
+			case commitActionsRef:
+				return "git-commit-ref"
+			case commitActionsReset:
+				return "git-confirm-reset"
+			}
+		}
+		return "git-commit-actions"
+	case ViewModeUndo:
+		if p.undo != nil && p.undo.confirming {
+			return "git-confirm-undo"
+		}
+		return "git-undo"
 	case ViewModeError:
 		return "git-error"
 	case ViewModeConfirmStashPop:
@@ -1290,6 +1461,9 @@ func (p *Plugin) FocusContext() string {
 // ConsumesTextInput reports whether the plugin is currently in a mode where
 // printable keys should be treated as text input.
 func (p *Plugin) ConsumesTextInput() bool {
+	if p.viewMode == ViewModeCommitActions && p.commitActions != nil && p.commitActions.stage == commitActionsRef {
+		return true
+	}
 	return p.viewMode == ViewModeCommit || p.historySearchMode || p.pathFilterMode
 }
 
@@ -1739,6 +1913,7 @@ func (p *Plugin) initCommitTextarea() {
 	p.commitButtonHover = false
 	p.commitModal = nil
 	p.commitModalWidthCache = 0
+	p.resetCommitTemplate()
 }
 
 // confirmStashPop fetches the latest stash and shows the confirm modal.
@@ -0,0 +1,299 @@
+package gitstatus
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// RebaseAction is the todo command for one commit in an interactive rebase.
+type RebaseAction string
+
+const (
+	RebasePick   RebaseAction = "pick"
+	RebaseReword RebaseAction = "reword"
+	RebaseEdit   RebaseAction = "edit"
+	RebaseSquash RebaseAction = "squash"
+	RebaseFixup  RebaseAction = "fixup"
+	RebaseDrop   RebaseAction = "drop"
+)
+
+// folds reports whether the action melds the commit into the one before it.
+func (a RebaseAction) folds() bool {
+	return a == RebaseSquash || a == RebaseFixup
+}
+
+// RebaseStep is one commit of the plan and what to do with it.
+type RebaseStep struct {
+	Hash      string
+	ShortHash string
+	Subject   string
+	Action    RebaseAction
+	Message   string // New message for RebaseReword
+}
+
+// RebasePlan is an interactive rebase before it runs. Steps are newest
+// first, the order the history list shows; the todo Git reads is the reverse.
+type RebasePlan struct {
+	Base  string // Parent of the oldest commit; "" rebases from the root
+	Steps []*RebaseStep
+
+	original []string // Hashes in their starting order
+}
+
+// NewRebasePlan plans a rebase of commits[0..upTo], which must start at HEAD
+// and be newest first. Pushed commits and merges are refused: rewriting the
+// first breaks everyone who pulled them, and the todo cannot express the
+// second without --rebase-merges.
+func NewRebasePlan(commits []*Commit, upTo int) (*RebasePlan, error) {
+	if upTo < 0 || upTo >= len(commits) {
+		return nil, fmt.Errorf("no commit selected")
+	}
+	plan := &RebasePlan{}
+	for _, c := range commits[:upTo+1] {
+		if c.Pushed {
+			return nil, fmt.Errorf("%s is already pushed; only unpushed commits can be rebased", c.ShortHash)
+		}
+		if c.IsMerge {
+			return nil, fmt.Errorf("%s is a merge; rebase it from the command line", c.ShortHash)
+		}
+		plan.Steps = append(plan.Steps, &RebaseStep{Hash: c.Hash, ShortHash: c.ShortHash, Subject: c.Subject, Action: RebasePick})
+		plan.original = append(plan.original, c.Hash)
+	}
+	if parents := commits[upTo].ParentHashes; len(parents) > 0 {
+		plan.Base = parents[0]
+	}
+	return plan, nil
+}
+
+// Move shifts step i by delta (negative is newer), returning its new index.
+func (pl *RebasePlan) Move(i, delta int) int {
+	j := i + delta
+	if i < 0 || i >= len(pl.Steps) || j < 0 || j >= len(pl.Steps) {
+		return i
+	}
+	for i != j {
+		next := i + 1
+		if j < i {
+			next = i - 1
+		}
+		pl.Steps[i], pl.Steps[next] = pl.Steps[next], pl.Steps[i]
+		i = next
+	}
+	return j
+}
+
+// todoOrder returns the steps oldest first.
+func (pl *RebasePlan) todoOrder() []*RebaseStep {
+	out := make([]*RebaseStep, len(pl.Steps))
+	for i, s := range pl.Steps {
+		out[len(pl.Steps)-1-i] = s
+	}
+	return out
+}
+
+// Validate reports why the plan cannot run, if it cannot.
+func (pl *RebasePlan) Validate() error {
+	changed := false
+	for i, s := range pl.Steps {
+		if s.Action != RebasePick || s.Hash != pl.original[i] {
+			changed = true
+		}
+		if s.Action == RebaseReword && strings.TrimSpace(s.Message) == "" {
+			return fmt.Errorf("%s needs a message", s.ShortHash)
+		}
+	}
+	if !changed {
+		return fmt.Errorf("nothing to change yet")
+	}
+	for _, s := range pl.todoOrder() {
+		if s.Action == RebaseDrop {
+			continue
+		}
+		if s.Action.folds() {
+			return fmt.Errorf("%s has no earlier commit to %s into", s.ShortHash, s.Action)
+		}
+		break
+	}
+	return nil
+}
+
+// RebaseResult is one commit of the history the plan will produce.
+type RebaseResult struct {
+	ShortHash string // Of the commit the result starts from
+	Subject   string
+	Folded    int  // Commits squashed or fixed up into it
+	Stops     bool // The rebase pauses here for editing
+	Reworded  bool
+}
+
+// Preview returns the resulting history, newest first.
+func (pl *RebasePlan) Preview() []RebaseResult {
+	var out []RebaseResult
+	for _, s := range pl.todoOrder() {
+		switch {
+		case s.Action == RebaseDrop:
+			continue
+		case s.Action.folds() && len(out) > 0:
+			out[len(out)-1].Folded++
+			continue
+		}
+		r := RebaseResult{ShortHash: s.ShortHash, Subject: s.Subject, Stops: s.Action == RebaseEdit}
+		if s.Action == RebaseReword {
+			r.Subject = strings.SplitN(strings.TrimSpace(s.Message), "\n", 2)[0]
+			r.Reworded = true
+		}
+		out = append(out, r)
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
+
+// Todo renders the plan as a git-rebase-todo. A reword runs as a pick plus an
+// exec that amends the message from msgFile(step), placed after any commits
+// squashed into it so the new message is the one that survives.
+func (pl *RebasePlan) Todo(msgFile func(*RebaseStep) string) string {
+	var sb strings.Builder
+	var reword *RebaseStep
+	flush := func() {
+		if reword != nil {
+			fmt.Fprintf(&sb, "exec git commit --amend --only --allow-empty --quiet -F %s\n", shellQuote(msgFile(reword)))
+			reword = nil
+		}
+	}
+	for _, s := range pl.todoOrder() {
+		if !s.Action.folds() {
+			flush()
+		}
+		action := s.Action
+		if action == RebaseReword {
+			action = RebasePick
+			reword = s
+		}
+		fmt.Fprintf(&sb, "%s %s %s\n", action, s.Hash, s.Subject)
+	}
+	flush()
+	return sb.String()
+}
+
+// shellQuote quotes s for the POSIX shell Git runs exec lines and
+// GIT_SEQUENCE_EDITOR through.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// gitPath resolves a path inside the repository's git directory.
+func gitPath(workDir, name string) (string, error) {
+	cmd := gitReadOnly("rev-parse", "--git-path", name)
+	cmd.Dir = workDir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	path := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workDir, path)
+	}
+	return path, nil
+}
+
+// RunRebase runs the plan with `git rebase -i`, handing Git the generated
+// todo through GIT_SEQUENCE_EDITOR. Reword messages are written beside the
+// todo in the git directory, since an edit stop means they are read long
+// after this returns. A rebase that stops, for a conflict or an edit, returns
+// with the rebase still in progress.
+func RunRebase(workDir string, pl *RebasePlan) (string, error) {
+	dir, err := gitPath(workDir, "sidecar-rebase")
+	if err != nil {
+		return "", err
+	}
+	_ = os.RemoveAll(dir)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	var writeErr error
+	msgFile := func(s *RebaseStep) string {
+		path := filepath.Join(dir, "msg-"+s.Hash)
+		if err := os.WriteFile(path, []byte(strings.TrimSpace(s.Message)+"\n"), 0o600); err != nil && writeErr == nil {
+			writeErr = err
+		}
+		return path
+	}
+	todo := filepath.Join(dir, "todo")
+	if err := os.WriteFile(todo, []byte(pl.Todo(msgFile)), 0o600); err != nil {
+		return "", err
+	}
+	if writeErr != nil {
+		return "", writeErr
+	}
+
+	args := []string{"rebase", "-i", "--autostash"}
+	if pl.Base == "" {
+		args = append(args, "--root")
+	} else {
+		args = append(args, pl.Base)
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = workDir
+	cmd.Env = append(os.Environ(),
+		"GIT_SEQUENCE_EDITOR=cp "+shellQuote(todo),
+		"GIT_EDITOR=true",
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", &RemoteError{Output: string(output), Err: err}
+	}
+	return string(output), nil
+}
+
+// rebaseStopSubject describes where a stopped rebase is waiting, from the
+// commit it last applied.
+func rebaseStopSubject(workDir string) string {
+	path, err := gitPath(workDir, "rebase-merge/done")
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	fields := strings.SplitN(lines[len(lines)-1], " ", 3)
+	if len(fields) < 2 {
+		return ""
+	}
+	hash := fields[1]
+	if len(hash) > 7 {
+		hash = hash[:7]
+	}
+	if len(fields) == 3 {
+		return hash + " " + fields[2]
+	}
+	return hash
+}
+
+// rebaseProgress returns "n/m" for a rebase in progress, or "".
+func rebaseProgress(workDir string) string {
+	read := func(name string) int {
+		path, err := gitPath(workDir, "rebase-merge/"+name)
+		if err != nil {
+			return 0
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return 0
+		}
+		n, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+		return n
+	}
+	done, total := read("msgnum"), read("end")
+	if total == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", done, total)
+}
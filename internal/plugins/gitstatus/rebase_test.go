@@ -0,0 +1,206 @@
+package gitstatus
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+func testPlanCommits() []*Commit {
+	return []*Commit{
+		{Hash: "ccc", ShortHash: "ccc", Subject: "third", ParentHashes: []string{"bbb"}},
+		{Hash: "bbb", ShortHash: "bbb", Subject: "second", ParentHashes: []string{"aaa"}},
+		{Hash: "aaa", ShortHash: "aaa", Subject: "first", ParentHashes: []string{"base"}},
+		{Hash: "base", ShortHash: "base", Subject: "pushed", Pushed: true},
+	}
+}
+
+func TestRebasePlanTodoAndPreview(t *testing.T) {
+	commits := testPlanCommits()
+	if _, err := NewRebasePlan(commits, 3); err == nil {
+		t.Fatal("a pushed commit must not be rebased")
+	}
+	plan, err := NewRebasePlan(commits, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Base != "base" || len(plan.Steps) != 3 {
+		t.Fatalf("plan = %+v", plan)
+	}
+	if err := plan.Validate(); err == nil {
+		t.Fatal("an untouched plan has nothing to do")
+	}
+
+	// Move "first" to the top, then back down: the order must round-trip.
+	if i := plan.Move(2, -2); i != 0 || plan.Steps[0].Subject != "first" {
+		t.Fatalf("Move up = %d, steps[0] = %s", i, plan.Steps[0].Subject)
+	}
+	plan.Move(0, 2)
+
+	plan.Steps[2].Action = RebaseSquash
+	if err := plan.Validate(); err == nil || !strings.Contains(err.Error(), "no earlier commit") {
+		t.Fatalf("squashing the oldest commit should fail, got %v", err)
+	}
+	plan.Steps[2].Action = RebaseReword
+	if err := plan.Validate(); err == nil {
+		t.Fatal("a reword needs a message")
+	}
+	plan.Steps[2].Message = "first, reworded\n\nbody"
+	plan.Steps[1].Action = RebaseFixup
+	plan.Steps[0].Action = RebaseDrop
+	if err := plan.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	todo := plan.Todo(func(s *RebaseStep) string { return "/tmp/msg-" + s.Hash })
+	want := "pick aaa first\n" +
+		"fixup bbb second\n" +
+		"exec git commit --amend --only --allow-empty --quiet -F '/tmp/msg-aaa'\n" +
+		"drop ccc third\n"
+	if todo != want {
+		t.Fatalf("todo =\n%s\nwant\n%s", todo, want)
+	}
+
+	preview := plan.Preview()
+	if len(preview) != 1 || preview[0].Subject != "first, reworded" || preview[0].Folded != 1 || !preview[0].Reworded {
+		t.Fatalf("preview = %+v", preview)
+	}
+}
+
+// rebaseRepo returns a repository with three unpushed commits on one file
+// each: a.txt, b.txt, c.txt.
+func rebaseRepo(t *testing.T) string {
+	t.Helper()
+	repo := initTestRepo(t)
+	for _, name := range []string{"a", "b", "c"} {
+		if err := os.WriteFile(filepath.Join(repo, name+".txt"), []byte(name+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		runGitTest(t, repo, "add", ".")
+		runGitTest(t, repo, "commit", "-qm", "add "+name)
+	}
+	return repo
+}
+
+func rebasePlugin(t *testing.T, repo string) *Plugin {
+	t.Helper()
+	p := New()
+	p.ctx = &plugin.Context{Epoch: 1, WorkDir: repo}
+	p.repoRoot, p.hasRepo = repo, true
+	p.tree = NewFileTree(repo)
+	p.width, p.height = 120, 30
+	commits, _, err := GetCommitHistoryWithPushStatus(repo, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.recentCommits = commits
+	return p
+}
+
+func rebaseKey(r rune) tea.KeyPressMsg {
+	return tea.KeyPressMsg{Code: r, Text: string(r)}
+}
+
+func TestInteractiveRebaseSquashRewordDrop(t *testing.T) {
+	repo := rebaseRepo(t)
+	p := rebasePlugin(t, repo)
+
+	// Open on "add a", the oldest of the three.
+	p.cursor = len(p.tree.AllEntries()) + 2
+	if !p.cursorOnCommit() || p.selectedCommitIndex() != 2 {
+		t.Fatalf("cursor is not on the third commit: %d", p.selectedCommitIndex())
+	}
+	p.Update(rebaseKey('R'))
+	if p.viewMode != ViewModeRebase || p.rebase == nil || p.FocusContext() != "git-rebase" {
+		t.Fatalf("rebase editor did not open: mode %v", p.viewMode)
+	}
+	e := p.rebase
+	if e.cursor != 2 || e.plan.Steps[2].Subject != "add a" {
+		t.Fatalf("cursor should start on the oldest commit: %+v", e)
+	}
+
+	// Reword "add a" through the commit modal.
+	_, cmd := p.Update(rebaseKey('r'))
+	if p.viewMode != ViewModeCommit || !p.rewording() {
+		t.Fatal("reword should open the commit modal")
+	}
+	if msg, ok := cmd().(AmendMessageLoadedMsg); !ok || msg.Message != "add a" {
+		t.Fatalf("reword should prefill the old message, got %#v", msg)
+	} else {
+		p.Update(msg)
+	}
+	if !strings.Contains(p.renderCommitModal(), "Reword") {
+		t.Fatal("the modal should be titled Reword")
+	}
+	p.commitMessage.SetValue("add a and b")
+	p.Update(tea.KeyPressMsg{Code: 's', Mod: tea.ModCtrl})
+	if p.viewMode != ViewModeRebase || e.plan.Steps[2].Action != RebaseReword || e.plan.Steps[2].Message != "add a and b" {
+		t.Fatalf("reword was not recorded: %+v", e.plan.Steps[2])
+	}
+
+	// Squash "add b" into it and drop "add c".
+	p.Update(rebaseKey('k'))
+	p.Update(rebaseKey('f'))
+	p.Update(rebaseKey('k'))
+	p.Update(rebaseKey('d'))
+	view := p.renderRebaseView()
+	for _, want := range []string{"Interactive rebase", "Result", "add a and b", "(+1)"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("view is missing %q:\n%s", want, view)
+		}
+	}
+
+	_, cmd = p.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatalf("enter should run the rebase: %v", e.plan.Validate())
+	}
+	done, ok := cmd().(RebaseDoneMsg)
+	if !ok || done.Err != nil || done.Stopped {
+		t.Fatalf("rebase result = %+v", done)
+	}
+	p.Update(done)
+	if p.rebase != nil || p.viewMode != ViewModeStatus || p.auxWriteInProgress {
+		t.Fatal("a finished rebase should close the editor")
+	}
+
+	log := runGitTest(t, repo, "log", "--format=%s", "main")
+	if got := strings.Fields(strings.ReplaceAll(log, " ", "_")); strings.Join(got, ",") != "add_a_and_b,root" {
+		t.Fatalf("history = %q", log)
+	}
+	files := runGitTest(t, repo, "ls-files")
+	if strings.Join(strings.Fields(files), ",") != "a.txt,b.txt" {
+		t.Fatalf("files = %q", files)
+	}
+}
+
+func TestInteractiveRebaseEditStopPausesResolver(t *testing.T) {
+	repo := rebaseRepo(t)
+	p := rebasePlugin(t, repo)
+	plan, err := NewRebasePlan(p.recentCommits, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan.Steps[1].Action = RebaseEdit
+	p.rebase = &rebaseEditor{plan: plan, returnMode: ViewModeStatus}
+	p.viewMode = ViewModeRebase
+
+	done, ok := p.runRebase()().(RebaseDoneMsg)
+	if !ok || done.Err != nil || !done.Stopped {
+		t.Fatalf("an edit should stop the rebase: %+v", done)
+	}
+	_, cmd := p.Update(done)
+	pumpConflict(t, p, cmd)
+	if p.viewMode != ViewModeConflict || p.resolver == nil || p.resolver.op != "rebase" {
+		t.Fatalf("a stopped rebase should open the paused resolver, mode %v", p.viewMode)
+	}
+
+	_, cmd = p.Update(rebaseKey('c'))
+	pumpConflict(t, p, cmd)
+	if op := ConflictOperation(repo); op != "" {
+		t.Fatalf("continue should finish the rebase, still in %s", op)
+	}
+}
@@ -0,0 +1,332 @@
+package gitstatus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/marcus/sidecar/internal/app"
+	"github.com/marcus/sidecar/internal/mouse"
+	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/styles"
+	"github.com/marcus/sidecar/internal/undojournal"
+)
+
+const regionRebaseStep = "rebase-step" // Row in the interactive rebase editor
+
+// rebaseEditor is the state of the interactive rebase editor.
+type rebaseEditor struct {
+	plan       *RebasePlan
+	cursor     int
+	scroll     int
+	rewording  *RebaseStep // Step whose message the commit modal is editing
+	returnMode ViewMode
+}
+
+// RebaseDoneMsg reports a finished or stopped interactive rebase.
+type RebaseDoneMsg struct {
+	Epoch   uint64
+	Steps   int
+	Stopped bool // The rebase is still in progress: a conflict or an edit stop
+	Err     error
+}
+
+func (m RebaseDoneMsg) GetEpoch() uint64 { return m.Epoch }
+
+// openRebaseEditor plans a rebase of every commit from HEAD down to the
+// selected one.
+func (p *Plugin) openRebaseEditor() tea.Cmd {
+	if p.historyFilterActive {
+		return app.ShowFlash("Clear the history filter to rebase")
+	}
+	if !p.hasSelectedCommit() {
+		return nil
+	}
+	if op := ConflictOperation(p.repoRoot); op != "" {
+		return app.ShowFlash(titleCase(op) + " in progress · M to resolve it first")
+	}
+	plan, err := NewRebasePlan(p.recentCommits, p.selectedCommitIndex())
+	if err != nil {
+		return app.ShowFlash(err.Error())
+	}
+	p.rebase = &rebaseEditor{plan: plan, cursor: len(plan.Steps) - 1, returnMode: p.viewMode}
+	p.viewMode = ViewModeRebase
+	return nil
+}
+
+func (p *Plugin) closeRebaseEditor() {
+	returnMode := ViewModeStatus
+	if p.rebase != nil {
+		returnMode = p.rebase.returnMode
+	}
+	p.rebase = nil
+	p.viewMode = returnMode
+}
+
+// updateRebase handles keys in the interactive rebase editor.
+func (p *Plugin) updateRebase(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd) {
+	e := p.rebase
+	if e == nil {
+		p.viewMode = ViewModeStatus
+		return p, nil
+	}
+	steps := e.plan.Steps
+	switch msg.String() {
+	case "esc", "q":
+		p.closeRebaseEditor()
+		return p, nil
+	case "j", "down":
+		e.cursor = min(e.cursor+1, len(steps)-1)
+	case "k", "up":
+		e.cursor = max(e.cursor-1, 0)
+	case "J", "ctrl+down":
+		e.cursor = e.plan.Move(e.cursor, 1)
+	case "K", "ctrl+up":
+		e.cursor = e.plan.Move(e.cursor, -1)
+	case "p":
+		steps[e.cursor].Action = RebasePick
+	case "s":
+		steps[e.cursor].Action = RebaseSquash
+	case "f":
+		steps[e.cursor].Action = RebaseFixup
+	case "e":
+		steps[e.cursor].Action = RebaseEdit
+	case "d":
+		steps[e.cursor].Action = RebaseDrop
+	case "r":
+		return p, p.startReword(steps[e.cursor])
+	case "enter", "ctrl+s":
+		return p, p.runRebase()
+	}
+	return p, nil
+}
+
+// startReword opens the commit modal on the step's message. Submitting it
+// records the message in the plan; nothing is committed until the rebase runs.
+func (p *Plugin) startReword(step *RebaseStep) tea.Cmd {
+	p.rebase.rewording = step
+	p.viewMode = ViewModeCommit
+	p.initCommitTextarea()
+	if step.Message != "" {
+		p.commitMessage.SetValue(step.Message)
+		return nil
+	}
+	return p.loadCommitMessage(step.Hash)
+}
+
+// finishReword stores the modal's message on the step being reworded.
+func (p *Plugin) finishReword(message string) {
+	e := p.rebase
+	e.rewording.Message = message
+	e.rewording.Action = RebaseReword
+	e.rewording = nil
+	p.closeCommitModal(ViewModeRebase)
+}
+
+// cancelReword leaves the step as it was.
+func (p *Plugin) cancelReword() {
+	p.rebase.rewording = nil
+	p.closeCommitModal(ViewModeRebase)
+}
+
+func (p *Plugin) rewording() bool {
+	return p.rebase != nil && p.rebase.rewording != nil
+}
+
+// runRebase validates the plan and runs it.
+func (p *Plugin) runRebase() tea.Cmd {
+	plan := p.rebase.plan
+	if err := plan.Validate(); err != nil {
+		return app.ShowFlash("Rebase: " + err.Error())
+	}
+	if p.writeInProgress() {
+		return p.writeBusyToast()
+	}
+	p.auxWriteInProgress = true
+	workDir := p.repoRoot
+	epoch := p.currentEpoch()
+	return func() tea.Msg {
+		record := undojournal.Begin(context.Background(), workDir, "rebase", undojournal.Options{Head: true})
+		_, err := RunRebase(workDir, plan)
+		stopped := IsRebaseInProgress(workDir)
+		if stopped {
+			// A rebase stopped on a conflict has already rewritten
+			// history; it is recorded so it stays undoable once continued.
+			record(nil)
+		} else {
+			record(err)
+		}
+		return RebaseDoneMsg{Epoch: epoch, Steps: len(plan.Steps), Stopped: stopped, Err: err}
+	}
+}
+
+// handleRebaseDone routes a stopped rebase into the conflict resolver, which
+// is where it is continued from.
+func (p *Plugin) handleRebaseDone(msg RebaseDoneMsg) tea.Cmd {
+	p.auxWriteInProgress = false
+	p.closeRebaseEditor()
+	reload := tea.Batch(p.refresh(), p.loadRecentCommits())
+	if msg.Stopped {
+		note := "Rebase paused"
+		if msg.Err != nil {
+			note = "Rebase stopped: " + firstLine(msg.Err.Error())
+		}
+		return tea.Batch(reload, p.openConflictResolver(""), app.ShowFlash(note))
+	}
+	if msg.Err != nil {
+		p.showErrorModal("Rebase Failed", msg.Err)
+		return reload
+	}
+	return tea.Batch(reload, app.ShowFlash(fmt.Sprintf("Rebased %d commit(s)", msg.Steps)))
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
+
+// renderRebaseView renders the editor: the todo on the left, the history it
+// produces on the right.
+func (p *Plugin) renderRebaseView() string {
+	paneHeight := p.height - 2
+	contentWidth := max(20, p.width-4)
+	p.mouseHandler.Clear()
+	e := p.rebase
+	plan := e.plan
+
+	var sb strings.Builder
+	onto := "the root commit"
+	if plan.Base != "" {
+		onto = plan.Base[:min(7, len(plan.Base))]
+	}
+	title := styles.ModalTitle.Render("Interactive rebase")
+	info := styles.Muted.Render(fmt.Sprintf(" · %d commit(s) onto %s", len(plan.Steps), onto))
+	sb.WriteString(truncateLine(title+info, contentWidth))
+	sb.WriteString("\n")
+	sb.WriteString(styles.Muted.Render(strings.Repeat("━", contentWidth)))
+	sb.WriteString("\n")
+
+	visible := max(1, paneHeight-5)
+	if e.cursor < e.scroll {
+		e.scroll = e.cursor
+	} else if e.cursor >= e.scroll+visible {
+		e.scroll = e.cursor - visible + 1
+	}
+
+	leftW := contentWidth
+	rightW := 0
+	if contentWidth >= 80 {
+		leftW = contentWidth * 3 / 5
+		rightW = contentWidth - leftW - 3
+	}
+
+	var left []string
+	for i := e.scroll; i < len(plan.Steps) && i < e.scroll+visible; i++ {
+		left = append(left, renderRebaseStep(plan.Steps[i], i == e.cursor, leftW))
+		// Y: panel border (1) + title and rule (2); X: border and padding.
+		p.mouseHandler.HitMap.AddRect(regionRebaseStep, 2, 3+i-e.scroll, leftW, 1, i)
+	}
+	body := strings.Join(left, "\n")
+	if rightW > 0 {
+		body = lipgloss.JoinHorizontal(lipgloss.Top,
+			lipgloss.NewStyle().Width(leftW).Render(body),
+			styles.Muted.Render(" │ "),
+			p.renderRebasePreview(rightW, visible),
+		)
+	}
+	sb.WriteString(body)
+	sb.WriteString("\n\n")
+	if err := plan.Validate(); err != nil {
+		sb.WriteString(styles.Muted.Render(truncateLine("Not ready: "+err.Error(), contentWidth)))
+	} else {
+		sb.WriteString(styles.StatusStaged.Render("enter runs the rebase"))
+	}
+	return p.wrapDiffContent(sb.String(), paneHeight)
+}
+
+func renderRebaseStep(s *RebaseStep, selected bool, width int) string {
+	cursor := "  "
+	if selected {
+		cursor = "▸ "
+	}
+	var style lipgloss.Style
+	switch s.Action {
+	case RebaseDrop:
+		style = styles.StatusDeleted
+	case RebaseSquash, RebaseFixup:
+		style = styles.StatusModified
+	case RebaseReword, RebaseEdit:
+		style = styles.StatusInProgress
+	default:
+		style = styles.Muted
+	}
+	subject := s.Subject
+	if s.Action == RebaseReword {
+		subject = firstLine(s.Message)
+	}
+	action := style.Render(fmt.Sprintf("%-6s", s.Action))
+	line := cursor + action + " " + styles.Muted.Render(s.ShortHash) + " " + subject
+	line = truncateLine(line, width)
+	if selected {
+		return styles.ListItemSelected.Render(padToWidth(line, width))
+	}
+	return line
+}
+
+func (p *Plugin) renderRebasePreview(width, rows int) string {
+	lines := []string{styles.Muted.Render("Result")}
+	results := p.rebase.plan.Preview()
+	if len(results) == 0 {
+		lines = append(lines, styles.Muted.Render("(every commit dropped)"))
+	}
+	for i, r := range results {
+		if i >= rows-1 {
+			lines = append(lines, styles.Muted.Render(fmt.Sprintf("… %d more", len(results)-i)))
+			break
+		}
+		var notes []string
+		if r.Folded > 0 {
+			notes = append(notes, fmt.Sprintf("+%d", r.Folded))
+		}
+		if r.Stops {
+			notes = append(notes, "stops")
+		}
+		line := "● " + r.Subject
+		if len(notes) > 0 {
+			line += " " + styles.Muted.Render("("+strings.Join(notes, ", ")+")")
+		}
+		lines = append(lines, truncateLine(line, width))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// handleRebaseMouse selects rows on click and reorders them by dragging.
+func (p *Plugin) handleRebaseMouse(msg tea.MouseMsg) (*Plugin, tea.Cmd) {
+	e := p.rebase
+	if e == nil {
+		return p, nil
+	}
+	action := p.mouseHandler.HandleMouse(msg)
+	switch action.Type {
+	case mouse.ActionClick:
+		if action.Region != nil && action.Region.ID == regionRebaseStep {
+			if idx, ok := action.Region.Data.(int); ok {
+				e.cursor = idx
+				p.mouseHandler.StartDrag(action.X, action.Y, regionRebaseStep, idx)
+			}
+		}
+	case mouse.ActionDrag:
+		if action.DragStartID == regionRebaseStep && action.Region != nil && action.Region.ID == regionRebaseStep {
+			if idx, ok := action.Region.Data.(int); ok && idx != e.cursor {
+				e.cursor = e.plan.Move(e.cursor, idx-e.cursor)
+			}
+		}
+	case mouse.ActionScrollUp:
+		e.cursor = max(e.cursor-1, 0)
+	case mouse.ActionScrollDown:
+		e.cursor = min(e.cursor+1, len(e.plan.Steps)-1)
+	}
+	return p, nil
+}
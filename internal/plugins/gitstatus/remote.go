@@ -125,23 +125,16 @@ func AbortRebase(workDir string) error {
 
 // IsRebaseInProgress checks if a rebase is currently in progress.
 func IsRebaseInProgress(workDir string) bool {
-	cmd := gitReadOnly("rev-parse", "--git-path", "rebase-merge")
-	cmd.Dir = workDir
-	output, err := cmd.Output()
-	if err != nil {
-		return false
-	}
-	path := strings.TrimSpace(string(output))
-	if _, err := os.Stat(path); err == nil {
-		return true
-	}
-	cmd = gitReadOnly("rev-parse", "--git-path", "rebase-apply")
-	cmd.Dir = workDir
-	output, err = cmd.Output()
+	return gitPathExists(workDir, "rebase-merge") || gitPathExists(workDir, "rebase-apply")
+}
+
+// gitPathExists reports whether the named file exists inside the repository's
+// git directory. rev-parse answers relative to workDir, not to our own cwd.
+func gitPathExists(workDir, name string) bool {
+	path, err := gitPath(workDir, name)
 	if err != nil {
 		return false
 	}
-	path = strings.TrimSpace(string(output))
 	_, err = os.Stat(path)
 	return err == nil
 }
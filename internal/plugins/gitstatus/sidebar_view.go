@@ -271,7 +271,11 @@ func (p *Plugin) renderSidebarSection(title string, entries []*FileEntry, lineNu
 	*lineNum++
 	*currentY++
 
+	var rows []*FileEntry
 	for _, entry := range entries {
+		rows = appendExpanded(rows, entry)
+	}
+	for _, entry := range rows {
 		if *lineNum >= maxLines {
 			break
 		}
@@ -357,6 +361,22 @@ func (p *Plugin) renderSidebarEntry(entry *FileEntry, selected bool, maxWidth in
 		statsPlain = fmt.Sprintf("+%d/-%d", entry.DiffStats.Additions, entry.DiffStats.Deletions)
 		statsStr = styles.Muted.Render(statsPlain)
 	}
+	// A pointer's line counts say nothing; show the object instead.
+	if entry.LFS != nil {
+		statsPlain = entry.LFS.Label()
+		statsStr = styles.Muted.Render(statsPlain)
+		if !entry.LFS.Fetched {
+			statsStr = styles.StatusModified.Render(statsPlain)
+		}
+	}
+	if entry.Sparse {
+		statsPlain = strings.TrimSpace(statsPlain + " sparse")
+		statsStr = strings.TrimSpace(statsStr + " " + styles.Muted.Render("sparse"))
+	}
+
+	if entry.Submodule != nil {
+		return p.renderSubmoduleEntry(entry, status, selected, maxWidth)
+	}
 
 	// Handle folder entries specially
 	if entry.IsFolder {
@@ -422,6 +442,45 @@ func (p *Plugin) renderSidebarEntry(entry *FileEntry, selected bool, maxWidth in
 	return styles.ListItemNormal.Render(line)
 }
 
+// renderSubmoduleEntry renders a submodule's row: an expand indicator when
+// its own changes are loaded, its path, and what changed inside it.
+func (p *Plugin) renderSubmoduleEntry(entry *FileEntry, status string, selected bool, maxWidth int) string {
+	indicator := ""
+	if len(entry.Children) > 0 {
+		indicator = "▶ "
+		if entry.IsExpanded {
+			indicator = "▼ "
+		}
+	}
+	hint := entry.Submodule.Describe()
+	if from, to, ok := strings.Cut(entry.Range, ".."); ok {
+		hint += " " + shortHash(from) + ".." + shortHash(to)
+	}
+	name := entry.Path + "/"
+	available := maxWidth - 2 - len(indicator) - len(hint) - 1
+	if available < 8 {
+		hint, available = "", maxWidth-2-len(indicator)
+	}
+	if len(name) > available && available > 3 {
+		name = "…" + name[len(name)-available+1:]
+	}
+	if selected {
+		plainLine := fmt.Sprintf("%s %s%s", string(entry.Status), indicator, name)
+		if hint != "" {
+			plainLine += " " + hint
+		}
+		if len(plainLine) < maxWidth {
+			plainLine += strings.Repeat(" ", maxWidth-len(plainLine))
+		}
+		return styles.ListItemSelected.Render(plainLine)
+	}
+	line := fmt.Sprintf("%s %s%s", status, indicator, name)
+	if hint != "" {
+		line += " " + styles.Muted.Render(hint)
+	}
+	return styles.ListItemNormal.Render(line)
+}
+
 // renderRecentCommits renders the recent commits section in the sidebar.
 // maxVisible is the maximum number of commits that can be displayed.
 func (p *Plugin) renderRecentCommits(currentY *int, maxVisible int) string {
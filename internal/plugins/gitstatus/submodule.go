@@ -0,0 +1,270 @@
+package gitstatus
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	appmsg "github.com/marcus/sidecar/internal/msg"
+)
+
+// operationSubmoduleUpdate checks a submodule out at the commit its
+// superproject records, initializing it first when needed.
+const operationSubmoduleUpdate operationKind = "submodule update"
+
+// SubmoduleState is what porcelain v2 reports for a gitlink entry in its
+// <sub> field ("S<c><m><u>").
+type SubmoduleState struct {
+	CommitChanged bool // Checked out at a commit other than the recorded one
+	Modified      bool // Tracked changes inside the submodule
+	Untracked     bool // Untracked files inside the submodule
+	HeadCommit    string
+	IndexCommit   string // The commits HEAD and the index record for it
+}
+
+// parseSubmoduleField parses porcelain v2's <sub> field; "N..." is an
+// ordinary path.
+func parseSubmoduleField(field string) *SubmoduleState {
+	if len(field) != 4 || field[0] != 'S' {
+		return nil
+	}
+	return &SubmoduleState{
+		CommitChanged: field[1] == 'C',
+		Modified:      field[2] == 'M',
+		Untracked:     field[3] == 'U',
+	}
+}
+
+// Describe lists what changed in the submodule.
+func (s *SubmoduleState) Describe() string {
+	var parts []string
+	if s.CommitChanged {
+		parts = append(parts, "new commits")
+	} else if s.commitStaged() {
+		parts = append(parts, "staged commits")
+	}
+	if s.Modified {
+		parts = append(parts, "modified")
+	}
+	if s.Untracked {
+		parts = append(parts, "untracked")
+	}
+	if len(parts) == 0 {
+		return "submodule"
+	}
+	return "submodule: " + strings.Join(parts, ", ")
+}
+
+// commitStaged reports whether the index records another commit than HEAD for
+// a submodule HEAD already has.
+func (s *SubmoduleState) commitStaged() bool {
+	return strings.Trim(s.HeadCommit, "0") != "" && s.HeadCommit != s.IndexCommit
+}
+
+// loadSubmodulesContext loads the changes of each changed submodule as
+// children of its entry: the files changed across the commits it moved by,
+// staged or checked out, and for the worktree entry the submodule's own
+// status. Child paths stay relative to this repository, and Repo names the
+// submodule whose git commands they need.
+func (t *FileTree) loadSubmodulesContext(ctx context.Context) {
+	loaded := make(map[string]bool)
+	for _, list := range [][]*FileEntry{t.Staged, t.Modified} {
+		for _, entry := range list {
+			sub := entry.Submodule
+			if sub == nil {
+				continue
+			}
+			dir := filepath.Join(t.workDir, entry.Path)
+			var children []*FileEntry
+			var nested []string
+			if !entry.Staged && (sub.Modified || sub.Untracked) {
+				tree, err := LoadFileTreeContext(ctx, dir)
+				if err == nil {
+					for _, list := range [][]*FileEntry{tree.Staged, tree.Modified, tree.Untracked} {
+						for _, child := range list {
+							if child.IsFolder {
+								children = append(children, child.Children...)
+								continue
+							}
+							children = append(children, child)
+						}
+					}
+					nested = tree.submodules
+				}
+			}
+			entry.Range = submoduleRange(ctx, dir, entry)
+			children = append(children, rangeEntries(ctx, dir, entry, children)...)
+			if len(children) == 0 {
+				continue
+			}
+			prefixSubmoduleEntries(children, entry.Path)
+			entry.Children = children
+			if !loaded[entry.Path] {
+				loaded[entry.Path] = true
+				t.submodules = append(t.submodules, entry.Path)
+			}
+			for _, n := range nested {
+				t.submodules = append(t.submodules, entry.Path+"/"+n)
+			}
+		}
+	}
+}
+
+// submoduleRange returns the "old..new" commits a submodule entry moved by:
+// HEAD's to the index's for the staged entry, and the index's to the one
+// checked out for the worktree entry. It is empty when the commit is unchanged.
+func submoduleRange(ctx context.Context, dir string, entry *FileEntry) string {
+	sub := entry.Submodule
+	if entry.Staged {
+		if !sub.commitStaged() {
+			return ""
+		}
+		return sub.HeadCommit + ".." + sub.IndexCommit
+	}
+	if !sub.CommitChanged {
+		return ""
+	}
+	cmd := gitReadOnlyContext(ctx, "rev-parse", "HEAD")
+	cmd.Dir = dir
+	head, err := cmd.Output()
+	if err != nil || sub.IndexCommit == "" {
+		return ""
+	}
+	return sub.IndexCommit + ".." + strings.TrimSpace(string(head))
+}
+
+// rangeEntries lists the files changed across a submodule entry's commit
+// range, leaving out paths its worktree changes already list.
+func rangeEntries(ctx context.Context, dir string, entry *FileEntry, worktree []*FileEntry) []*FileEntry {
+	if entry.Range == "" {
+		return nil
+	}
+	cmd := gitReadOnlyContext(ctx, "diff", "--name-status", "-z", "--no-renames", entry.Range)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	listed := make(map[string]bool, len(worktree))
+	for _, child := range worktree {
+		listed[child.Path] = true
+	}
+	var entries []*FileEntry
+	fields := strings.Split(string(out), "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		status, path := fields[i], fields[i+1]
+		if status == "" || listed[path] {
+			continue
+		}
+		entries = append(entries, &FileEntry{
+			Path:     path,
+			Status:   FileStatus(status[:1]),
+			Staged:   entry.Staged,
+			Unstaged: !entry.Staged,
+			Range:    entry.Range,
+		})
+	}
+	return entries
+}
+
+// prefixSubmoduleEntries moves entries loaded in the submodule at prefix into
+// this repository's paths, along with the children of nested submodules.
+func prefixSubmoduleEntries(entries []*FileEntry, prefix string) {
+	for _, entry := range entries {
+		entry.Path = prefix + "/" + entry.Path
+		if entry.OldPath != "" {
+			entry.OldPath = prefix + "/" + entry.OldPath
+		}
+		if entry.Repo != "" {
+			entry.Repo = prefix + "/" + entry.Repo
+		} else {
+			entry.Repo = prefix
+		}
+		if entry.Submodule != nil {
+			prefixSubmoduleEntries(entry.Children, prefix)
+		}
+	}
+}
+
+// RepoFor returns the repository a path's git commands run in, relative to
+// the tree's root ("" for the tree's own), and the path within it.
+func (t *FileTree) RepoFor(path string) (repo, rel string) {
+	for _, sub := range t.submodules {
+		if strings.HasPrefix(path, sub+"/") && len(sub) > len(repo) {
+			repo = sub
+		}
+	}
+	if repo == "" {
+		return "", path
+	}
+	return repo, strings.TrimPrefix(path, repo+"/")
+}
+
+// RangeFor returns the commit range a file inside a submodule changed across,
+// or "" for a file whose change is in a worktree or index.
+func (t *FileTree) RangeFor(path string, staged bool) string {
+	if t == nil {
+		return ""
+	}
+	var find func(entries []*FileEntry) string
+	find = func(entries []*FileEntry) string {
+		for _, entry := range entries {
+			if entry.Submodule != nil {
+				if r := find(entry.Children); r != "" {
+					return r
+				}
+				continue
+			}
+			if entry.Path == path && entry.Staged == staged && entry.Range != "" {
+				return entry.Range
+			}
+		}
+		return ""
+	}
+	if r := find(t.Staged); r != "" {
+		return r
+	}
+	return find(t.Modified)
+}
+
+// GetRangeDiff returns the diff of path across a commit range.
+func GetRangeDiff(workDir, path, span string) (string, error) {
+	cmd := gitReadOnly("diff", span, "--", path)
+	cmd.Dir = workDir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// gitLocation returns the directory to run git in for path, and path as
+// that repository spells it.
+func (p *Plugin) gitLocation(path string) (workDir, rel string) {
+	if p.tree == nil {
+		return p.repoRoot, path
+	}
+	repo, rel := p.tree.RepoFor(path)
+	if repo == "" {
+		return p.repoRoot, path
+	}
+	return filepath.Join(p.repoRoot, repo), rel
+}
+
+// updateSubmodule runs `git submodule update --init --recursive` for the
+// submodule entry.
+func (p *Plugin) updateSubmodule(entry *FileEntry) tea.Cmd {
+	if entry.Submodule == nil {
+		return appmsg.ShowFlash("Not a submodule")
+	}
+	if p.activeOperation != nil {
+		return p.writeBusyToast()
+	}
+	workDir, rel := p.gitLocation(entry.Path)
+	return p.startWrite(operationRequest{
+		Kind:    operationSubmoduleUpdate,
+		Args:    []string{"submodule", "update", "--init", "--recursive", "--", rel},
+		WorkDir: workDir,
+	}, selectionIdentity{path: entry.Path})
+}
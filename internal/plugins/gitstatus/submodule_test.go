@@ -0,0 +1,161 @@
+package gitstatus
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// submoduleRepo returns a superproject with the rebaseRepo history checked
+// out as submodule "sub" as its only content.
+func submoduleRepo(t *testing.T) string {
+	t.Helper()
+	child := rebaseRepo(t)
+	repo := initTestRepo(t)
+	runGitTest(t, repo, "-c", "protocol.file.allow=always", "submodule", "add", "-q", child, "sub")
+	runGitTest(t, repo, "commit", "-qm", "add sub")
+	return repo
+}
+
+func TestParseSubmoduleField(t *testing.T) {
+	if got := parseSubmoduleField("N..."); got != nil {
+		t.Fatalf("ordinary path parsed as submodule: %+v", got)
+	}
+	got := parseSubmoduleField("SC.U")
+	if got == nil || !got.CommitChanged || got.Modified || !got.Untracked {
+		t.Fatalf("SC.U = %+v", got)
+	}
+	if d := got.Describe(); d != "submodule: new commits, untracked" {
+		t.Fatalf("Describe = %q", d)
+	}
+}
+
+func TestSubmoduleChangesLoadAsChildren(t *testing.T) {
+	repo := submoduleRepo(t)
+	if err := os.WriteFile(filepath.Join(repo, "sub", "a.txt"), []byte("changed\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tree := NewFileTree(repo)
+	if err := tree.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if len(tree.Modified) != 1 {
+		t.Fatalf("modified = %d entries, want the submodule alone", len(tree.Modified))
+	}
+	sub := tree.Modified[0]
+	if sub.Path != "sub" || sub.Submodule == nil || !sub.Submodule.Modified || sub.Submodule.CommitChanged {
+		t.Fatalf("submodule entry = %+v (state %+v)", sub, sub.Submodule)
+	}
+	if len(sub.Children) != 1 || sub.Children[0].Path != "sub/a.txt" || sub.Children[0].Repo != "sub" {
+		t.Fatalf("children = %+v", sub.Children)
+	}
+	if repoDir, rel := tree.RepoFor("sub/a.txt"); repoDir != "sub" || rel != "a.txt" {
+		t.Fatalf("RepoFor = %q, %q", repoDir, rel)
+	}
+	if repoDir, rel := tree.RepoFor("a.txt"); repoDir != "" || rel != "a.txt" {
+		t.Fatalf("RepoFor top-level = %q, %q", repoDir, rel)
+	}
+
+	sub.IsExpanded = true
+	entries := tree.AllEntries()
+	if len(entries) != 2 || entries[1].Path != "sub/a.txt" {
+		t.Fatalf("expanded entries = %d", len(entries))
+	}
+}
+
+func TestSubmoduleChildDiffRunsInTheSubmodule(t *testing.T) {
+	repo := submoduleRepo(t)
+	if err := os.WriteFile(filepath.Join(repo, "sub", "b.txt"), []byte("b\nmore\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p := rebasePlugin(t, repo)
+	if err := p.tree.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	workDir, rel := p.gitLocation("sub/b.txt")
+	if workDir != filepath.Join(repo, "sub") || rel != "b.txt" {
+		t.Fatalf("gitLocation = %q, %q", workDir, rel)
+	}
+	diff, err := GetDiff(workDir, rel, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseUnifiedDiff(diff)
+	if err != nil || len(parsed.Hunks) != 1 {
+		t.Fatalf("diff = %q, err=%v", diff, err)
+	}
+}
+
+func TestSubmoduleNewCommitsShowInItsDiff(t *testing.T) {
+	repo := submoduleRepo(t)
+	subDir := filepath.Join(repo, "sub")
+	if err := os.WriteFile(filepath.Join(subDir, "d.txt"), []byte("d\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGitTest(t, subDir, "add", ".")
+	runGitTest(t, subDir, "commit", "-qm", "add d")
+
+	tree := NewFileTree(repo)
+	if err := tree.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if len(tree.Modified) != 1 || tree.Modified[0].Submodule == nil || !tree.Modified[0].Submodule.CommitChanged {
+		t.Fatalf("modified = %+v", tree.Modified)
+	}
+	diff, err := GetDiff(repo, "sub", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseUnifiedDiff(diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, hunk := range parsed.Hunks {
+		for _, line := range hunk.Lines {
+			found = found || (line.Type == LineAdd && line.Content == "d")
+		}
+	}
+	if !found {
+		t.Fatalf("submodule diff does not show the new commit's content:\n%s", diff)
+	}
+
+	// With no worktree changes inside, the new commits' files are what expands.
+	sub := tree.Modified[0]
+	if len(sub.Children) != 1 || sub.Children[0].Path != "sub/d.txt" || sub.Children[0].Range != sub.Range || sub.Range == "" {
+		t.Fatalf("children = %+v, range %q", sub.Children, sub.Range)
+	}
+	span := tree.RangeFor("sub/d.txt", false)
+	rangeDiff, err := GetRangeDiff(subDir, "d.txt", span)
+	if err != nil || !strings.Contains(rangeDiff, "+d") {
+		t.Fatalf("range %q diff = %q, err=%v", span, rangeDiff, err)
+	}
+}
+
+func TestSubmoduleStagedCommitsExpand(t *testing.T) {
+	repo := submoduleRepo(t)
+	subDir := filepath.Join(repo, "sub")
+	runGitTest(t, subDir, "rm", "-q", "a.txt")
+	runGitTest(t, subDir, "commit", "-qm", "drop a")
+	runGitTest(t, repo, "add", "sub")
+
+	tree := NewFileTree(repo)
+	if err := tree.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if len(tree.Staged) != 1 || len(tree.Modified) != 0 {
+		t.Fatalf("staged = %+v, modified = %+v", tree.Staged, tree.Modified)
+	}
+	sub := tree.Staged[0]
+	if d := sub.Submodule.Describe(); d != "submodule: staged commits" {
+		t.Fatalf("Describe = %q", d)
+	}
+	if len(sub.Children) != 1 || sub.Children[0].Path != "sub/a.txt" || sub.Children[0].Status != StatusDeleted || !sub.Children[0].Staged {
+		t.Fatalf("children = %+v", sub.Children)
+	}
+	if tree.RangeFor("sub/a.txt", true) == "" {
+		t.Fatal("a staged commit's file has no range")
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+
+	"github.com/marcus/sidecar/internal/gitstate"
 )
 
 // FileStatus represents the git status of a file.
@@ -37,7 +39,12 @@ type FileEntry struct {
 	DiffStats  DiffStats
 	IsExpanded bool
 	IsFolder   bool         // True if this represents an untracked folder
-	Children   []*FileEntry // Files within this folder (when IsFolder is true)
+	Children   []*FileEntry // Files within this folder, or changes within this submodule
+	Submodule  *SubmoduleState
+	Repo       string     // Submodule, relative to the tree's root, an entry inside one belongs to
+	Range      string     // "old..new" commits a submodule, or a file inside one, changed across
+	LFS        *LFSObject // Set when git stores the file in LFS
+	Sparse     bool       // Outside the worktree's sparse checkout
 }
 
 // DiffStats holds addition/deletion counts.
@@ -52,6 +59,14 @@ type FileTree struct {
 	Modified  []*FileEntry
 	Untracked []*FileEntry
 	workDir   string
+
+	// Snapshot is the same status indexed by path, which is the form the file
+	// browser decorates its tree with; it is broadcast after each load.
+	Snapshot *gitstate.Snapshot
+
+	// submodules are the submodules, nested ones included, whose changes
+	// were loaded as children.
+	submodules []string
 }
 
 // NewFileTree creates an empty file tree for the given work directory.
@@ -75,11 +90,15 @@ func LoadFileTreeContext(ctx context.Context, workDir string) (*FileTree, error)
 		return nil, err
 	}
 
-	tree := &FileTree{workDir: workDir}
+	tree := &FileTree{workDir: workDir, Snapshot: gitstate.Parse(workDir, output)}
+	tree.Snapshot.Sparse = gitstate.LoadSparse(ctx, workDir)
 	if err := tree.parseStatus(output); err != nil {
 		return nil, err
 	}
 	_ = tree.loadDiffStatsContext(ctx)
+	tree.loadLFSContext(ctx)
+	tree.markSparse()
+	tree.loadSubmodulesContext(ctx)
 	tree.groupUntrackedFolders()
 	return tree, nil
 }
@@ -98,6 +117,8 @@ func (t *FileTree) RefreshContext(ctx context.Context) error {
 	t.Staged = snapshot.Staged
 	t.Modified = snapshot.Modified
 	t.Untracked = snapshot.Untracked
+	t.Snapshot = snapshot.Snapshot
+	t.submodules = snapshot.submodules
 
 	return nil
 }
@@ -174,7 +195,11 @@ func (t *FileTree) parseOrdinaryEntry(line string) *FileEntry {
 	path := fields[8]
 
 	entry := &FileEntry{
-		Path: path,
+		Path:      path,
+		Submodule: parseSubmoduleField(fields[2]),
+	}
+	if entry.Submodule != nil {
+		entry.Submodule.HeadCommit, entry.Submodule.IndexCommit = fields[6], fields[7]
 	}
 
 	// X = index status, Y = worktree status
@@ -216,8 +241,9 @@ func (t *FileTree) parseRenamedEntry(line string) *FileEntry {
 	}
 
 	entry := &FileEntry{
-		Path:   path,
-		Status: status,
+		Path:      path,
+		Status:    status,
+		Submodule: parseSubmoduleField(fields[2]),
 	}
 	if len(xy) >= 2 {
 		entry.Staged = xy[0] != '.'
@@ -253,9 +279,10 @@ func (t *FileTree) addEntry(entry *FileEntry) {
 		// File has both staged and unstaged changes
 		// Add a copy to modified list
 		modEntry := &FileEntry{
-			Path:     entry.Path,
-			Status:   entry.Status,
-			Unstaged: true,
+			Path:      entry.Path,
+			Status:    entry.Status,
+			Unstaged:  true,
+			Submodule: entry.Submodule,
 		}
 		t.Modified = append(t.Modified, modEntry)
 	}
@@ -426,22 +453,59 @@ func (t *FileTree) Summary() string {
 // Folder entries are included, and if expanded, their children follow.
 func (t *FileTree) AllEntries() []*FileEntry {
 	var all []*FileEntry
-	all = append(all, t.Staged...)
-	all = append(all, t.Modified...)
+	for _, list := range [][]*FileEntry{t.Staged, t.Modified, t.Untracked} {
+		for _, entry := range list {
+			all = appendExpanded(all, entry)
+		}
+	}
+	return all
+}
 
-	// For untracked, handle folder expansion
-	for _, entry := range t.Untracked {
-		all = append(all, entry)
-		if entry.IsFolder && entry.IsExpanded {
-			all = append(all, entry.Children...)
+// appendExpanded appends entry and, while it is expanded, the files of an
+// untracked folder or the changes inside a submodule, recursing into nested
+// submodules.
+func appendExpanded(all []*FileEntry, entry *FileEntry) []*FileEntry {
+	all = append(all, entry)
+	if entry.IsExpanded && (entry.IsFolder || entry.Submodule != nil) {
+		for _, child := range entry.Children {
+			all = appendExpanded(all, child)
 		}
 	}
 	return all
 }
 
+// markSparse flags the changed paths outside the worktree's sparse checkout.
+func (t *FileTree) markSparse() {
+	sparse := t.Snapshot.Sparse
+	if sparse == nil {
+		return
+	}
+	for _, list := range [][]*FileEntry{t.Staged, t.Modified, t.Untracked} {
+		for _, entry := range list {
+			entry.Sparse = sparse.Excludes(entry.Path, strings.HasSuffix(entry.Path, "/"))
+		}
+	}
+}
+
+// StageArgs returns the `git add` arguments that stage paths, including
+// paths outside a sparse checkout.
+func (t *FileTree) StageArgs(all bool, paths ...string) []string {
+	args := []string{"add"}
+	if all {
+		args = append(args, "-A")
+	}
+	if t != nil && t.Snapshot != nil && t.Snapshot.Sparse != nil {
+		args = append(args, "--sparse")
+	}
+	if len(paths) > 0 {
+		args = append(append(args, "--"), paths...)
+	}
+	return args
+}
+
 // StageFile stages a file.
 func (t *FileTree) StageFile(path string) error {
-	cmd := exec.Command("git", "add", "--", path)
+	cmd := exec.Command("git", t.StageArgs(false, path)...)
 	cmd.Dir = t.workDir
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -468,7 +532,7 @@ func (t *FileTree) StageAll() error {
 
 // StageAllContext stages all changes and observes ctx while Git runs.
 func (t *FileTree) StageAllContext(ctx context.Context) error {
-	cmd := exec.CommandContext(ctx, "git", "add", "-A")
+	cmd := exec.CommandContext(ctx, "git", t.StageArgs(true)...)
 	cmd.Dir = t.workDir
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -535,18 +599,38 @@ func ExecuteCommit(workDir, message string) (string, error) {
 
 // ExecuteCommitContext executes a commit and observes ctx while Git runs.
 func ExecuteCommitContext(ctx context.Context, workDir, message string) (string, error) {
-	cmd := exec.CommandContext(ctx, "git", "commit", "-m", message)
-	cmd.Dir = workDir
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return "", &CommitError{Output: string(output), Err: err}
-	}
-	return parseCommitHash(string(output)), nil
+	return ExecuteCommitWithOptions(ctx, workDir, message, CommitOptions{})
 }
 
 // ExecuteAmend executes a git commit --amend with the given message.
 func ExecuteAmend(workDir, message string) (string, error) {
-	cmd := exec.Command("git", "commit", "--amend", "-m", message)
+	return ExecuteCommitWithOptions(context.Background(), workDir, message, CommitOptions{Amend: true})
+}
+
+// CommitOptions are the commit modal's choices beyond the message.
+type CommitOptions struct {
+	Amend    bool
+	Sign     *bool    // Force signing on or off; nil leaves it to git's config
+	Trailers []string // "Key: value" trailers git appends to the message
+}
+
+// ExecuteCommitWithOptions commits message with opts.
+func ExecuteCommitWithOptions(ctx context.Context, workDir, message string, opts CommitOptions) (string, error) {
+	args := []string{"commit", "-m", message}
+	if opts.Amend {
+		args = append(args, "--amend")
+	}
+	if opts.Sign != nil {
+		if *opts.Sign {
+			args = append(args, "-S")
+		} else {
+			args = append(args, "--no-gpg-sign")
+		}
+	}
+	for _, t := range opts.Trailers {
+		args = append(args, "--trailer", t)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
 	cmd.Dir = workDir
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -557,7 +641,12 @@ func ExecuteAmend(workDir, message string) (string, error) {
 
 // getLastCommitMessage returns the message of the most recent commit.
 func getLastCommitMessage(workDir string) (string, error) {
-	cmd := gitReadOnly("log", "-1", "--format=%B")
+	return getCommitMessage(workDir, "HEAD")
+}
+
+// getCommitMessage returns the full message of the given commit.
+func getCommitMessage(workDir, rev string) (string, error) {
+	cmd := gitReadOnly("log", "-1", "--format=%B", rev)
 	cmd.Dir = workDir
 	output, err := cmd.Output()
 	if err != nil {
@@ -2,6 +2,8 @@ package gitstatus
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -495,3 +497,45 @@ func TestTotalCount(t *testing.T) {
 		t.Errorf("expected 6, got %d", tree.TotalCount())
 	}
 }
+
+func TestSparseCheckoutFlagsAndStagesPathsOutsideTheCone(t *testing.T) {
+	repo := rebaseRepo(t)
+	for _, dir := range []string{"app", "docs"} {
+		if err := os.MkdirAll(filepath.Join(repo, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(repo, dir, "file"), []byte(dir+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runGitTest(t, repo, "add", ".")
+	runGitTest(t, repo, "commit", "-qm", "dirs")
+	runGitTest(t, repo, "sparse-checkout", "set", "--cone", "app")
+	if err := os.MkdirAll(filepath.Join(repo, "docs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "docs", "new"), []byte("new\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "app", "new"), []byte("new\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tree := NewFileTree(repo)
+	if err := tree.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	sparse := map[string]bool{}
+	for _, entry := range tree.Untracked {
+		sparse[entry.Path] = entry.Sparse
+	}
+	if len(sparse) != 2 || !sparse["docs/new"] || sparse["app/new"] {
+		t.Fatalf("sparse flags = %v", sparse)
+	}
+	if err := tree.StageFile("docs/new"); err != nil {
+		t.Fatalf("staging outside the cone: %v", err)
+	}
+	if got := runGitTest(t, repo, "diff", "--cached", "--name-only"); strings.TrimSpace(got) != "docs/new" {
+		t.Fatalf("staged = %q", got)
+	}
+}
@@ -0,0 +1,513 @@
+package gitstatus
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/marcus/sidecar/internal/app"
+	"github.com/marcus/sidecar/internal/modal"
+	"github.com/marcus/sidecar/internal/mouse"
+	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/styles"
+	"github.com/marcus/sidecar/internal/ui"
+	"github.com/marcus/sidecar/internal/undojournal"
+)
+
+const (
+	regionUndoRow = "undo-row" // Row in the undo history browser
+	reflogLimit   = 200
+
+	undoConfirmID = "undo-confirm"
+	undoCancelID  = "undo-cancel"
+)
+
+// ReflogEntry is one move of HEAD from git reflog.
+type ReflogEntry struct {
+	Hash     string
+	Selector string // HEAD@{n}
+	Subject  string // What moved HEAD, e.g. "commit: add a"
+	When     time.Time
+}
+
+// GetReflog returns HEAD's reflog, newest first.
+func GetReflog(workDir string, limit int) ([]ReflogEntry, error) {
+	cmd := gitReadOnly("reflog", "-n", strconv.Itoa(limit), "--format=%H%x00%gd%x00%gs%x00%ct", "HEAD")
+	cmd.Dir = workDir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	var entries []ReflogEntry
+	for line := range strings.SplitSeq(strings.TrimSpace(string(out)), "\n") {
+		parts := strings.SplitN(line, "\x00", 4)
+		if len(parts) != 4 {
+			continue
+		}
+		secs, _ := strconv.ParseInt(parts[3], 10, 64)
+		entries = append(entries, ReflogEntry{Hash: parts[0], Selector: parts[1], Subject: parts[2], When: time.Unix(secs, 0)})
+	}
+	return entries, nil
+}
+
+// undoBrowser is the state of the undo history: Sidecar's journal of writes
+// above HEAD's reflog. Rows index the journal first, then the reflog.
+type undoBrowser struct {
+	entries    []undojournal.Entry
+	reflog     []ReflogEntry
+	loaded     bool
+	err        string
+	cursor     int
+	scroll     int
+	returnMode ViewMode
+
+	// confirming shows the confirmation for the selected row's undo, for
+	// undos that move HEAD, push or overwrite worktree files.
+	confirming   bool
+	confirmModal *modal.Modal
+}
+
+func (b *undoBrowser) rows() int {
+	return len(b.entries) + len(b.reflog)
+}
+
+// UndoHistoryLoadedMsg delivers the journal and reflog for the browser.
+type UndoHistoryLoadedMsg struct {
+	Epoch   uint64
+	Entries []undojournal.Entry
+	Reflog  []ReflogEntry
+	Err     error
+}
+
+func (m UndoHistoryLoadedMsg) GetEpoch() uint64 { return m.Epoch }
+
+// UndoDoneMsg reports a finished journal undo.
+type UndoDoneMsg struct {
+	Epoch     uint64
+	Operation string
+	Err       error
+}
+
+func (m UndoDoneMsg) GetEpoch() uint64 { return m.Epoch }
+
+// openUndoBrowser shows the undo history and loads it.
+func (p *Plugin) openUndoBrowser() tea.Cmd {
+	p.undo = &undoBrowser{returnMode: p.viewMode}
+	p.viewMode = ViewModeUndo
+	return p.loadUndoHistory()
+}
+
+func (p *Plugin) closeUndoBrowser() {
+	returnMode := ViewModeStatus
+	if p.undo != nil {
+		returnMode = p.undo.returnMode
+	}
+	p.undo = nil
+	p.viewMode = returnMode
+}
+
+func (p *Plugin) loadUndoHistory() tea.Cmd {
+	workDir := p.repoRoot
+	epoch := p.currentEpoch()
+	return func() tea.Msg {
+		entries, err := undojournal.Load(context.Background(), workDir)
+		if err != nil {
+			return UndoHistoryLoadedMsg{Epoch: epoch, Err: err}
+		}
+		reflog, err := GetReflog(workDir, reflogLimit)
+		return UndoHistoryLoadedMsg{Epoch: epoch, Entries: entries, Reflog: reflog, Err: err}
+	}
+}
+
+func (p *Plugin) handleUndoHistoryLoaded(msg UndoHistoryLoadedMsg) {
+	b := p.undo
+	if b == nil {
+		return
+	}
+	b.loaded = true
+	b.entries, b.reflog = msg.Entries, msg.Reflog
+	b.err = ""
+	if msg.Err != nil {
+		b.err = msg.Err.Error()
+	}
+	b.cursor = min(b.cursor, max(0, b.rows()-1))
+}
+
+// updateUndo handles keys in the undo history browser.
+func (p *Plugin) updateUndo(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd) {
+	b := p.undo
+	if b == nil {
+		p.viewMode = ViewModeStatus
+		return p, nil
+	}
+	if b.confirming {
+		return p, p.updateConfirmUndo(msg)
+	}
+	switch msg.String() {
+	case "esc", "q":
+		p.closeUndoBrowser()
+	case "j", "down":
+		b.cursor = min(b.cursor+1, max(0, b.rows()-1))
+	case "k", "up":
+		b.cursor = max(b.cursor-1, 0)
+	case "g", "home":
+		b.cursor = 0
+	case "G", "end":
+		b.cursor = max(0, b.rows()-1)
+	case "u", "enter":
+		return p, p.undoSelected()
+	}
+	return p, nil
+}
+
+// undoSelected undoes the selected row, asking first when the undo moves
+// HEAD, force-pushes or overwrites worktree files. A reflog row always moves
+// HEAD.
+func (p *Plugin) undoSelected() tea.Cmd {
+	b := p.undo
+	if b == nil || b.cursor >= b.rows() {
+		return nil
+	}
+	if p.writeInProgress() {
+		return p.writeBusyToast()
+	}
+	if b.cursor < len(b.entries) {
+		entry := b.entries[b.cursor]
+		if entry.Undone {
+			return app.ShowFlash(titleCase(entry.Operation) + " was already undone")
+		}
+		if !undoNeedsConfirm(entry) {
+			return p.runUndo()
+		}
+	}
+	b.confirming = true
+	b.confirmModal = nil
+	return nil
+}
+
+// undoNeedsConfirm reports whether undoing e touches a remote, moves HEAD or
+// overwrites files in the worktree.
+func undoNeedsConfirm(e undojournal.Entry) bool {
+	return e.Push != nil || e.Worktree || len(e.Files) > 0 || (e.MovesHead && e.Head != "")
+}
+
+// runUndo restores the selected journal entry, or resets HEAD back to the
+// selected reflog entry. reset --keep refuses rather than overwrite
+// uncommitted edits, and is itself journaled, so it can be undone in turn.
+func (p *Plugin) runUndo() tea.Cmd {
+	b := p.undo
+	if b == nil || b.cursor >= b.rows() {
+		return nil
+	}
+	if p.writeInProgress() {
+		return p.writeBusyToast()
+	}
+	if b.cursor >= len(b.entries) {
+		r := b.reflog[b.cursor-len(b.entries)]
+		short := r.Hash[:min(7, len(r.Hash))]
+		p.closeUndoBrowser()
+		return p.startWrite(operationRequest{
+			Kind: operationReset,
+			Args: []string{"reset", "--keep", r.Hash},
+			Done: fmt.Sprintf("Reset to %s (%s)", short, r.Selector),
+			Undo: &undojournal.Options{Head: true, Index: true},
+		}, selectionIdentity{})
+	}
+	entry := b.entries[b.cursor]
+	p.auxWriteInProgress = true
+	p.closeUndoBrowser()
+	epoch := p.currentEpoch()
+	return func() tea.Msg {
+		err := undojournal.Undo(context.Background(), entry)
+		return UndoDoneMsg{Epoch: epoch, Operation: entry.Operation, Err: err}
+	}
+}
+
+func (p *Plugin) cancelUndoConfirm() {
+	if p.undo != nil {
+		p.undo.confirming = false
+		p.undo.confirmModal = nil
+	}
+}
+
+func (p *Plugin) updateConfirmUndo(msg tea.KeyPressMsg) tea.Cmd {
+	b := p.undo
+	p.ensureUndoConfirmModal()
+	if msg.String() == "y" {
+		return p.runUndo()
+	}
+	action, cmd := b.confirmModal.HandleKey(msg)
+	switch action {
+	case undoConfirmID:
+		return p.runUndo()
+	case "cancel", undoCancelID:
+		p.cancelUndoConfirm()
+		return nil
+	}
+	return cmd
+}
+
+// ensureUndoConfirmModal builds the confirmation for the selected row, naming
+// the branch, commit and remote the undo moves.
+func (p *Plugin) ensureUndoConfirmModal() {
+	b := p.undo
+	if b.confirmModal != nil {
+		return
+	}
+	modalW := max(min(64, p.width-10), 20)
+	warn := lipgloss.NewStyle().Foreground(styles.Warning).Bold(true).Render("Warning: ")
+
+	var title string
+	var sections []modal.Section
+	if b.cursor >= len(b.entries) {
+		r := b.reflog[b.cursor-len(b.entries)]
+		branch := "HEAD"
+		if p.pushStatus != nil && p.pushStatus.CurrentBranch != "" {
+			branch = p.pushStatus.CurrentBranch
+		}
+		short := r.Hash[:min(7, len(r.Hash))]
+		title = "Reset to " + short
+		sections = append(sections,
+			modal.Text(fmt.Sprintf("Move %s to %s (%s)", styles.Subtitle.Render(branch), styles.Subtitle.Render(short), r.Selector)),
+			modal.Text(styles.Muted.Render(truncateLine(r.Subject, modalW-4))),
+			modal.Spacer(),
+			modal.Text(styles.Muted.Render("reset --keep refuses rather than overwrite uncommitted edits.")),
+		)
+	} else {
+		e := b.entries[b.cursor]
+		title = "Undo " + e.Operation
+		branch := e.Branch
+		if branch == "" {
+			branch = "detached HEAD"
+		}
+		switch {
+		case e.Worktree:
+			line := "Recreate worktree " + styles.Subtitle.Render(filepath.Base(e.Dir))
+			if e.Head != "" {
+				line += fmt.Sprintf(" on %s at %s", styles.Subtitle.Render(branch), styles.Subtitle.Render(shortHash(e.Head)))
+			}
+			sections = append(sections, modal.Text(line))
+		case e.MovesHead && e.Head != "":
+			sections = append(sections, modal.Text(fmt.Sprintf("Move %s back to %s", styles.Subtitle.Render(branch), styles.Subtitle.Render(shortHash(e.Head)))))
+		}
+		if e.Push != nil {
+			sections = append(sections, modal.Text(warn+fmt.Sprintf("force-push %s back to %s",
+				styles.Subtitle.Render(e.Push.Remote+"/"+e.Push.Branch), styles.Subtitle.Render(shortHash(e.Push.Old)))))
+		}
+		if n := len(e.Files); n == 1 {
+			sections = append(sections, modal.Text(styles.StatusDeleted.Render("Overwrites "+e.Files[0].Path+" in the working tree.")))
+		} else if n > 1 {
+			sections = append(sections, modal.Text(styles.StatusDeleted.Render(fmt.Sprintf("Overwrites %d files in the working tree.", n))))
+		}
+	}
+	sections = append(sections,
+		modal.Spacer(),
+		modal.Buttons(
+			modal.Btn(" Undo ", undoConfirmID, modal.BtnDanger()),
+			modal.Btn(" Cancel ", undoCancelID),
+		),
+	)
+	m := modal.New(title, modal.WithWidth(modalW), modal.WithVariant(modal.VariantDanger))
+	for _, section := range sections {
+		m = m.AddSection(section)
+	}
+	b.confirmModal = m
+}
+
+func shortHash(oid string) string {
+	return oid[:min(7, len(oid))]
+}
+
+func (p *Plugin) handleUndoDone(msg UndoDoneMsg) tea.Cmd {
+	p.auxWriteInProgress = false
+	reload := tea.Batch(p.refresh(), p.loadRecentCommits())
+	if msg.Err != nil {
+		p.showErrorModal("Undo Failed", msg.Err)
+		return reload
+	}
+	return tea.Batch(reload, app.ShowFlash("Undid "+msg.Operation))
+}
+
+// trackedChangePaths lists tracked files with uncommitted changes, which a
+// hard reset overwrites.
+func (p *Plugin) trackedChangePaths() []string {
+	if p.tree == nil {
+		return nil
+	}
+	var paths []string
+	seen := make(map[string]bool)
+	for _, e := range append(append([]*FileEntry(nil), p.tree.Staged...), p.tree.Modified...) {
+		if !seen[e.Path] {
+			seen[e.Path] = true
+			paths = append(paths, e.Path)
+		}
+	}
+	return paths
+}
+
+// forcePushUndo records where the remote branch stood before a force push,
+// from its remote-tracking ref. It is nil when there is nothing to restore.
+func forcePushUndo(workDir string) *undojournal.Push {
+	remote := GetRemoteName(workDir)
+	cmd := gitReadOnly("symbolic-ref", "--short", "-q", "HEAD")
+	cmd.Dir = workDir
+	out, err := cmd.Output()
+	branch := strings.TrimSpace(string(out))
+	if remote == "" || err != nil || branch == "" {
+		return nil
+	}
+	cmd = gitReadOnly("rev-parse", "--verify", "-q", "refs/remotes/"+remote+"/"+branch)
+	cmd.Dir = workDir
+	out, err = cmd.Output()
+	if err != nil {
+		return nil
+	}
+	return &undojournal.Push{Remote: remote, Branch: branch, Old: strings.TrimSpace(string(out))}
+}
+
+// renderUndoView renders the journal of Sidecar's writes above HEAD's reflog.
+func (p *Plugin) renderUndoView() string {
+	paneHeight := p.height - 2
+	contentWidth := max(20, p.width-4)
+	p.mouseHandler.Clear()
+	b := p.undo
+
+	var lines []string
+	rowAt := make(map[int]int) // Line index to row index, for mouse hits
+	title := styles.ModalTitle.Render("Undo history")
+	info := styles.Muted.Render(" · u or enter undoes the selected write")
+	lines = append(lines, truncateLine(title+info, contentWidth), styles.Muted.Render(strings.Repeat("━", contentWidth)))
+
+	switch {
+	case !b.loaded:
+		lines = append(lines, styles.Muted.Render("Loading…"))
+	case b.err != "":
+		lines = append(lines, styles.StatusDeleted.Render(truncateLine(b.err, contentWidth)))
+	}
+	if b.loaded {
+		lines = append(lines, styles.Muted.Render("Sidecar writes"))
+		if len(b.entries) == 0 {
+			lines = append(lines, styles.Muted.Render("  Nothing recorded yet"))
+		}
+		for i, e := range b.entries {
+			rowAt[len(lines)] = i
+			lines = append(lines, renderUndoEntry(e, i == b.cursor, contentWidth))
+		}
+		lines = append(lines, "", styles.Muted.Render("HEAD reflog · resets with --keep"))
+		for i, r := range b.reflog {
+			row := len(b.entries) + i
+			rowAt[len(lines)] = row
+			lines = append(lines, renderReflogEntry(r, row == b.cursor, contentWidth))
+		}
+	}
+
+	// Keep the cursor's line in view below the two header lines.
+	visible := max(1, paneHeight-4)
+	cursorLine := 0
+	for line, row := range rowAt {
+		if row == b.cursor {
+			cursorLine = line
+		}
+	}
+	body := lines[2:]
+	cursorLine -= 2
+	if cursorLine < b.scroll {
+		b.scroll = max(0, cursorLine)
+	} else if cursorLine >= b.scroll+visible {
+		b.scroll = cursorLine - visible + 1
+	}
+	b.scroll = min(b.scroll, max(0, len(body)-visible))
+	end := min(len(body), b.scroll+visible)
+	for i := b.scroll; i < end; i++ {
+		if row, ok := rowAt[i+2]; ok {
+			// Y: panel border (1) + title and rule (2); X: border and padding.
+			p.mouseHandler.HitMap.AddRect(regionUndoRow, 2, 3+i-b.scroll, contentWidth, 1, row)
+		}
+	}
+	out := append(lines[:2:2], body[b.scroll:end]...)
+	view := p.wrapDiffContent(strings.Join(out, "\n"), paneHeight)
+	if !b.confirming {
+		return view
+	}
+	p.ensureUndoConfirmModal()
+	return ui.OverlayModal(view, b.confirmModal.Render(p.width, p.height, p.mouseHandler), p.width, p.height)
+}
+
+func renderUndoEntry(e undojournal.Entry, selected bool, width int) string {
+	cursor := "  "
+	if selected {
+		cursor = "▸ "
+	}
+	op := styles.StatusModified.Render(e.Operation)
+	if e.Undone {
+		op = styles.Muted.Render(e.Operation + " (undone)")
+	}
+	line := cursor + styles.Muted.Render(fmt.Sprintf("%-14s", RelativeTime(e.Time))) + " " + op
+	if summary := e.Summary(); summary != "" {
+		line += styles.Muted.Render(" · restores " + summary)
+	}
+	line = truncateLine(line, width)
+	if selected {
+		return styles.ListItemSelected.Render(padToWidth(line, width))
+	}
+	return line
+}
+
+func renderReflogEntry(r ReflogEntry, selected bool, width int) string {
+	cursor := "  "
+	if selected {
+		cursor = "▸ "
+	}
+	short := r.Hash[:min(7, len(r.Hash))]
+	line := cursor + styles.Muted.Render(fmt.Sprintf("%-14s", RelativeTime(r.When))) + " " +
+		styles.Muted.Render(short) + " " + r.Subject
+	line = truncateLine(line, width)
+	if selected {
+		return styles.ListItemSelected.Render(padToWidth(line, width))
+	}
+	return line
+}
+
+// handleUndoMouse selects rows on click and moves the cursor on scroll.
+func (p *Plugin) handleUndoMouse(msg tea.MouseMsg) (*Plugin, tea.Cmd) {
+	b := p.undo
+	if b == nil {
+		return p, nil
+	}
+	if b.confirming {
+		p.ensureUndoConfirmModal()
+		switch b.confirmModal.HandleMouse(msg, p.mouseHandler) {
+		case undoConfirmID:
+			return p, p.runUndo()
+		case undoCancelID:
+			p.cancelUndoConfirm()
+		}
+		return p, nil
+	}
+	action := p.mouseHandler.HandleMouse(msg)
+	switch action.Type {
+	case mouse.ActionClick:
+		if action.Region != nil && action.Region.ID == regionUndoRow {
+			if row, ok := action.Region.Data.(int); ok {
+				b.cursor = row
+			}
+		}
+	case mouse.ActionDoubleClick:
+		if action.Region != nil && action.Region.ID == regionUndoRow {
+			if row, ok := action.Region.Data.(int); ok {
+				b.cursor = row
+				return p, p.undoSelected()
+			}
+		}
+	case mouse.ActionScrollUp:
+		b.cursor = max(b.cursor-1, 0)
+	case mouse.ActionScrollDown:
+		b.cursor = min(b.cursor+1, max(0, b.rows()-1))
+	}
+	return p, nil
+}
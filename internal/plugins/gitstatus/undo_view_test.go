@@ -0,0 +1,113 @@
+package gitstatus
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// openUndo opens the undo history and applies the load it starts.
+func openUndo(t *testing.T, p *Plugin) {
+	t.Helper()
+	_, cmd := p.Update(tea.KeyPressMsg{Code: 'r', Mod: tea.ModCtrl})
+	if p.viewMode != ViewModeUndo || p.FocusContext() != "git-undo" {
+		t.Fatalf("ctrl+r should open the undo history, mode %v", p.viewMode)
+	}
+	loaded, ok := cmd().(UndoHistoryLoadedMsg)
+	if !ok || loaded.Err != nil {
+		t.Fatalf("expected the history, got %+v", loaded)
+	}
+	p.Update(loaded)
+}
+
+func TestUndoHistoryRestoresDiscardAndReflog(t *testing.T) {
+	repo := rebaseRepo(t)
+	p := rebasePlugin(t, repo)
+	path := filepath.Join(repo, "a.txt")
+	if err := os.WriteFile(path, []byte("edited\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Discard the edit; the journal keeps it.
+	p.Update(p.doDiscard(&FileEntry{Path: "a.txt", Status: StatusModified})())
+	if data, _ := os.ReadFile(path); string(data) != "a\n" {
+		t.Fatalf("discard left %q", data)
+	}
+
+	openUndo(t, p)
+	if len(p.undo.entries) != 1 || p.undo.entries[0].Operation != "discard" {
+		t.Fatalf("journal = %+v", p.undo.entries)
+	}
+	view := p.renderUndoView()
+	for _, want := range []string{"Undo history", "discard", "restores a.txt", "commit: add c"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("view is missing %q:\n%s", want, view)
+		}
+	}
+	// Restoring a.txt overwrites the worktree, so it asks first.
+	if _, cmd := p.Update(rebaseKey('u')); cmd != nil || p.FocusContext() != "git-confirm-undo" {
+		t.Fatalf("undoing a discard should ask first, context %q", p.FocusContext())
+	}
+	if view := p.renderUndoView(); !strings.Contains(view, "Overwrites a.txt") {
+		t.Fatalf("confirmation should name the file:\n%s", view)
+	}
+	_, cmd := p.Update(rebaseKey('y'))
+	done, ok := cmd().(UndoDoneMsg)
+	if !ok || done.Err != nil {
+		t.Fatalf("undo result = %+v", done)
+	}
+	p.Update(done)
+	if data, _ := os.ReadFile(path); string(data) != "edited\n" {
+		t.Fatalf("undo restored %q", data)
+	}
+	if p.viewMode != ViewModeStatus || p.writeInProgress() {
+		t.Fatal("the undo should close the browser and finish the write")
+	}
+
+	// The entry is spent; a reflog row resets HEAD back to "add a".
+	openUndo(t, p)
+	if !p.undo.entries[0].Undone {
+		t.Fatal("the discard should be marked undone")
+	}
+	if _, cmd := p.Update(rebaseKey('u')); cmd == nil || p.viewMode != ViewModeUndo {
+		t.Fatal("undoing a spent entry should only flash")
+	}
+	if err := os.WriteFile(path, []byte("a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p.undo.cursor = len(p.undo.entries) + 2 // HEAD@{2}: "add a"
+	p.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	view = p.renderUndoView()
+	for _, want := range []string{"Reset to", "HEAD@{2}", "add a"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("reset confirmation is missing %q:\n%s", want, view)
+		}
+	}
+	p.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if p.FocusContext() != "git-undo" {
+		t.Fatalf("esc should return to the history, context %q", p.FocusContext())
+	}
+	p.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	_, cmd = p.Update(rebaseKey('y'))
+	if result := runWrite(t, p, cmd); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+	if got := strings.TrimSpace(runGitTest(t, repo, "log", "-1", "--format=%s")); got != "add a" {
+		t.Fatalf("HEAD is at %q", got)
+	}
+
+	// The reset was journaled in turn, so it can be undone too.
+	openUndo(t, p)
+	if p.undo.entries[0].Operation != string(operationReset) {
+		t.Fatalf("newest entry = %+v", p.undo.entries[0])
+	}
+	p.Update(rebaseKey('u'))
+	_, cmd = p.Update(rebaseKey('y'))
+	p.Update(cmd())
+	if got := strings.TrimSpace(runGitTest(t, repo, "log", "-1", "--format=%s")); got != "add c" {
+		t.Fatalf("undoing the reset left HEAD at %q", got)
+	}
+}
@@ -1,6 +1,7 @@
 package gitstatus
 
 import (
+	"fmt"
 	"log/slog"
 	"strings"
 
@@ -167,7 +168,8 @@ func (p *Plugin) updateStatus(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd) {
 				if entry.IsFolder && len(entry.Children) > 0 {
 					selectionPath = entry.Children[0].Path
 				}
-				return p, p.beginWrite(operationStage, []string{"add", "--", entry.Path}, selectionIdentity{path: selectionPath, wantStaged: true})
+				stageArgs := func(rel string) []string { return p.tree.StageArgs(false, rel) }
+				return p, p.beginPathWrite(operationStage, entry.Path, stageArgs, selectionIdentity{path: selectionPath, wantStaged: true})
 			}
 		}
 
@@ -178,7 +180,8 @@ func (p *Plugin) updateStatus(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd) {
 				if p.activeOperation != nil {
 					return p, p.writeBusyToast()
 				}
-				return p, p.beginWrite(operationUnstage, []string{"restore", "--staged", "--", entry.Path}, selectionIdentity{path: entry.Path})
+				unstageArgs := func(rel string) []string { return []string{"restore", "--staged", "--", rel} }
+				return p, p.beginPathWrite(operationUnstage, entry.Path, unstageArgs, selectionIdentity{path: entry.Path})
 			}
 		}
 
@@ -215,18 +218,34 @@ func (p *Plugin) updateStatus(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd) {
 			}
 		} else if len(entries) > 0 && p.cursor < len(entries) {
 			entry := entries[p.cursor]
-			if entry.IsFolder {
-				// Toggle folder expansion
+			if entry.IsFolder || len(entry.Children) > 0 {
+				// Toggle folder or submodule expansion
 				entry.IsExpanded = !entry.IsExpanded
 				// Reload diff for this folder
 				return p, p.autoLoadDiff()
 			}
+			if entry.Status == StatusUnmerged {
+				return p, p.openConflictResolver(entry.Path)
+			}
 			return p, p.openFile(entry.Path)
 		}
 
 	case "r":
 		return p, tea.Batch(p.refresh(), p.loadRecentCommits())
 
+	case "R":
+		if p.cursorOnCommit() {
+			return p, p.openRebaseEditor()
+		}
+		return p, nil
+
+	case "M":
+		var focus string
+		if !p.cursorOnCommit() && p.cursor < len(entries) {
+			focus = entries[p.cursor].Path
+		}
+		return p, p.openConflictResolver(focus)
+
 	case "S":
 		// Stage all files
 		if p.activeOperation != nil {
@@ -236,7 +255,7 @@ func (p *Plugin) updateStatus(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd) {
 		if p.cursor < len(entries) {
 			selection = selectionIdentity{path: entries[p.cursor].Path, wantStaged: true}
 		}
-		return p, p.beginWrite(operationStageAll, []string{"add", "-A"}, selection)
+		return p, p.beginWrite(operationStageAll, p.tree.StageArgs(true), selection)
 
 	case "U":
 		// Unstage all files
@@ -271,7 +290,11 @@ func (p *Plugin) updateStatus(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd) {
 		if p.tree.HasStagedFiles() {
 			p.viewMode = ViewModeCommit
 			p.initCommitTextarea()
-			return p, nil
+			if template := p.commitTemplate.cfg.Template; template != "" {
+				p.commitMessage.SetValue(template)
+				p.syncConventionalPickers()
+			}
+			return p, p.loadCommitContext()
 		}
 
 	case "A":
@@ -280,7 +303,7 @@ func (p *Plugin) updateStatus(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd) {
 			p.commitAmend = true
 			p.viewMode = ViewModeCommit
 			p.initCommitTextarea()
-			return p, p.loadAmendMessage()
+			return p, tea.Batch(p.loadAmendMessage(), p.loadCommitContext())
 		}
 
 	case "P":
@@ -305,11 +328,17 @@ func (p *Plugin) updateStatus(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd) {
 		}
 
 	case "o":
-		// Open commit in GitHub (when on commit in sidebar)
+		// Open commit on the forge (when on commit in sidebar)
 		if p.cursorOnCommit() {
 			return p, p.openCommitInGitHub()
 		}
 
+	case "ctrl+u":
+		if !p.cursorOnCommit() && p.cursor < len(entries) {
+			return p, p.updateSubmodule(entries[p.cursor])
+		}
+		return p, nil
+
 	case "D":
 		// Discard changes (confirm modal) - only for modified/staged files, not commits
 		if !p.cursorOnCommit() && len(entries) > 0 && p.cursor < len(entries) {
@@ -338,6 +367,9 @@ func (p *Plugin) updateStatus(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd) {
 		// Apply latest stash (non-destructive, stash entry preserved)
 		return p, p.doStashApply()
 
+	case "ctrl+r":
+		return p, p.openUndoBrowser()
+
 	case "b":
 		// Open branch picker
 		p.branchReturnMode = p.viewMode
@@ -424,6 +456,11 @@ func (p *Plugin) updateStatus(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd) {
 			return p, nil
 		}
 
+	case "a":
+		if p.cursorOnCommit() {
+			return p, p.openCommitActions()
+		}
+
 	case "v":
 		// Toggle commit graph display (only when on commits)
 		if p.cursorOnCommit() {
@@ -742,7 +779,7 @@ func (p *Plugin) updateCommitPreviewPane(msg tea.KeyPressMsg) (plugin.Plugin, te
 		return p, p.copyCommitIDToClipboard()
 
 	case "o":
-		// Open commit in GitHub
+		// Open commit on the forge
 		return p, p.openCommitInGitHub()
 
 	case "b":
@@ -1045,6 +1082,9 @@ func (p *Plugin) updateCommit(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd) {
 		return p, p.tryCommit()
 
 	case "ctrl+a":
+		if p.rewording() {
+			return p, nil
+		}
 		// Toggle amend mode (only if there are commits to amend and staged files)
 		if len(p.recentCommits) > 0 && p.tree.HasStagedFiles() {
 			p.commitAmend = !p.commitAmend
@@ -1057,6 +1097,14 @@ func (p *Plugin) updateCommit(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd) {
 			}
 		}
 		return p, nil
+
+	case "ctrl+t":
+		p.commitTemplate.trailers = !p.commitTemplate.trailers
+		return p, nil
+
+	case "ctrl+g":
+		p.commitTemplate.sign = !p.commitTemplate.sign
+		return p, nil
 	}
 
 	wasAmend := p.commitAmend
@@ -1067,6 +1115,9 @@ func (p *Plugin) updateCommit(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd) {
 		p.commitModal = nil
 		p.commitModalWidthCache = 0
 	}
+	if focusID == commitTypeID || focusID == commitScopeID {
+		p.syncConventionalHeader()
+	}
 
 	if action == commitActionID && focusID == commitMessageID {
 		return p, cmd
@@ -1076,20 +1127,35 @@ func (p *Plugin) updateCommit(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd) {
 	case commitActionID:
 		return p, p.tryCommit()
 	case "cancel":
-		p.viewMode = ViewModeStatus
-		p.commitAmend = false
-		p.commitError = ""
-		p.commitModal = nil
-		p.commitModalWidthCache = 0
+		p.cancelCommitModal()
 		return p, nil
 	}
 
 	return p, cmd
 }
 
+// cancelCommitModal dismisses the commit modal without committing.
+func (p *Plugin) cancelCommitModal() {
+	if p.rewording() {
+		p.cancelReword()
+		return
+	}
+	p.closeCommitModal(ViewModeStatus)
+}
+
+// closeCommitModal resets the commit modal and returns to mode.
+func (p *Plugin) closeCommitModal(mode ViewMode) {
+	p.viewMode = mode
+	p.commitAmend = false
+	p.commitError = ""
+	p.commitModal = nil
+	p.commitModalWidthCache = 0
+}
+
 // tryCommit attempts to execute the commit (or amend) if message is valid.
 func (p *Plugin) tryCommit() tea.Cmd {
-	if p.writeInProgress() {
+	// A reword is only recorded in the plan; the rebase does the writing.
+	if p.writeInProgress() && !p.rewording() {
 		return p.writeBusyToast()
 	}
 	message := strings.TrimSpace(p.commitMessage.Value())
@@ -1097,11 +1163,19 @@ func (p *Plugin) tryCommit() tea.Cmd {
 		p.commitError = "Commit message cannot be empty"
 		return nil
 	}
-	p.commitInProgress = true
-	if p.commitAmend {
-		return p.doAmend(message)
+	// Lint issues hold the first submit back; submitting the same message
+	// again commits past them.
+	if issues := LintCommitMessage(message, p.commitTemplate.cfg); len(issues) > 0 && p.commitTemplate.lintAck != message {
+		p.commitTemplate.lintAck = message
+		p.commitError = fmt.Sprintf("%d lint issue(s) · submit again to commit anyway", len(issues))
+		return nil
+	}
+	if p.rewording() {
+		p.finishReword(message)
+		return nil
 	}
-	return p.doCommit(message)
+	p.commitInProgress = true
+	return p.doCommit(message, p.commitOptions())
 }
 
 // updatePushMenu handles key events in the push menu.
@@ -1235,6 +1309,8 @@ func (p *Plugin) updatePullConflict(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd
 	}
 
 	switch msg.String() {
+	case "r":
+		return p, p.openConflictResolver("")
 	case "a":
 		// Abort merge/rebase
 		return p.abortPullConflict()
@@ -1245,6 +1321,8 @@ func (p *Plugin) updatePullConflict(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd
 
 	action, cmd := p.pullConflictModal.HandleKey(msg)
 	switch action {
+	case pullConflictResolveID:
+		return p, p.openConflictResolver("")
 	case pullConflictAbortID:
 		return p.abortPullConflict()
 	case "cancel", pullConflictDismissID:
@@ -0,0 +1,83 @@
+package gitstatus
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/marcus/sidecar/internal/app"
+	"github.com/marcus/sidecar/internal/forge"
+	"github.com/marcus/sidecar/internal/msg"
+)
+
+// GetRemoteURL returns the URL for the primary remote (origin).
+func GetRemoteURL(workDir string) string {
+	cmd := gitReadOnly("remote", "get-url", "origin")
+	cmd.Dir = workDir
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// RemoteRepo returns the forge repository origin points at. A configured
+// `sidecar.forge` names the forge of a self-hosted remote.
+func RemoteRepo(workDir string) (forge.Repo, error) {
+	remoteURL := GetRemoteURL(workDir)
+	if remoteURL == "" {
+		return forge.Repo{}, errors.New("no remote configured")
+	}
+	var kind forge.Kind
+	cmd := gitReadOnly("config", "--get", "sidecar.forge")
+	cmd.Dir = workDir
+	if out, err := cmd.Output(); err == nil {
+		kind, _ = forge.ParseKind(string(out))
+	}
+	return forge.ParseRemote(remoteURL, kind)
+}
+
+// openInBrowser opens the URL in the default browser.
+func openInBrowser(url string) tea.Cmd {
+	return func() tea.Msg {
+		var cmd *exec.Cmd
+		switch runtime.GOOS {
+		case "darwin":
+			cmd = exec.Command("open", url)
+		case "windows":
+			cmd = exec.Command("cmd", "/c", "start", url)
+		case "linux":
+			cmd = exec.Command("xdg-open", url)
+		default:
+			return app.ToastMsg{Message: "Unsupported platform", Duration: 3 * time.Second, IsError: true}
+		}
+		if err := cmd.Start(); err != nil {
+			return app.ToastMsg{Message: "Failed to open browser: " + err.Error(), Duration: 3 * time.Second, IsError: true}
+		}
+		return nil
+	}
+}
+
+// openCommitInGitHub opens the current commit on the remote's forge.
+func (p *Plugin) openCommitInGitHub() tea.Cmd {
+	commit := p.getCurrentCommit()
+	if commit == nil {
+		return nil
+	}
+
+	repo, err := RemoteRepo(p.repoRoot)
+	if err != nil {
+		if errors.Is(err, forge.ErrUnknownForge) {
+			return msg.ShowFlash("Remote is not on a known forge")
+		}
+		return msg.ShowFlash("No remote configured")
+	}
+
+	return tea.Batch(
+		openInBrowser(repo.CommitURL(commit.Hash)),
+		msg.ShowFlash("Opening in "+repo.Kind.Title()+"..."),
+	)
+}
@@ -1,13 +1,16 @@
 package gitstatus
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/marcus/sidecar/internal/msg"
 	"github.com/marcus/sidecar/internal/plugin"
+	"github.com/marcus/sidecar/internal/undojournal"
 )
 
 type operationKind string
@@ -17,8 +20,24 @@ const (
 	operationUnstage    operationKind = "unstage"
 	operationStageAll   operationKind = "stage all"
 	operationUnstageAll operationKind = "unstage all"
+	operationCherryPick operationKind = "cherry-pick"
+	operationRevert     operationKind = "revert"
+	operationBranch     operationKind = "create branch"
+	operationTag        operationKind = "create tag"
+	operationReset      operationKind = "reset"
 )
 
+// movesHistory reports whether a write changes commits or refs, so its result
+// reloads the commit list as well as the status.
+func (k operationKind) movesHistory() bool {
+	switch k {
+	case operationCherryPick, operationRevert, operationBranch, operationTag, operationReset:
+		return true
+	default:
+		return false
+	}
+}
+
 // titleCase capitalizes the first letter of each space-separated word in an
 // operationKind (e.g. "stage all" -> "Stage All"). All values are ASCII, so
 // this avoids pulling in golang.org/x/text/cases for ordinary word casing.
@@ -36,17 +55,27 @@ func titleCase(s string) string {
 type gitWriteExecutor func(workDir string, args []string) error
 
 type operationRequest struct {
-	ID    uint64
-	Epoch uint64
-	Kind  operationKind
-	Args  []string
+	ID      uint64
+	Epoch   uint64
+	Kind    operationKind
+	Args    []string
+	WorkDir string // Worktree the write runs in; the plugin's repository when empty
+	Done    string // Flash shown on success
+	// AbortOnConflict backs a stopped cherry-pick or revert out again, for
+	// writes into another worktree whose conflicts this plugin cannot resolve.
+	AbortOnConflict bool
+	// Undo selects what the undo journal saves before the write runs; the
+	// entry is recorded once the write succeeds. Nil records nothing.
+	Undo *undojournal.Options
 }
 
 type operationResultMsg struct {
-	ID    uint64
-	Epoch uint64
-	Kind  operationKind
-	Err   error
+	ID      uint64
+	Epoch   uint64
+	Kind    operationKind
+	WorkDir string
+	Done    string
+	Err     error
 }
 
 func (m operationResultMsg) GetEpoch() uint64 { return m.Epoch }
@@ -66,6 +95,16 @@ func operationProgressLabel(kind operationKind) string {
 		return "Staging all…"
 	case operationUnstageAll:
 		return "Unstaging all…"
+	case operationCherryPick:
+		return "Cherry-picking…"
+	case operationRevert:
+		return "Reverting…"
+	case operationBranch:
+		return "Creating branch…"
+	case operationTag:
+		return "Creating tag…"
+	case operationReset:
+		return "Resetting…"
 	default:
 		return "Git write…"
 	}
@@ -86,16 +125,27 @@ func executeGitWrite(workDir string, args []string) error {
 }
 
 func (p *Plugin) beginWrite(kind operationKind, args []string, selection selectionIdentity) tea.Cmd {
+	return p.startWrite(operationRequest{Kind: kind, Args: args}, selection)
+}
+
+// beginPathWrite is beginWrite for one entry's path, run in the submodule the
+// entry belongs to when it lies inside one.
+func (p *Plugin) beginPathWrite(kind operationKind, path string, args func(rel string) []string, selection selectionIdentity) tea.Cmd {
+	workDir, rel := p.gitLocation(path)
+	return p.startWrite(operationRequest{Kind: kind, Args: args(rel), WorkDir: workDir}, selection)
+}
+
+// startWrite runs req as the single in-flight write. Its result is applied
+// only while it is still the active operation of the current epoch.
+func (p *Plugin) startWrite(req operationRequest, selection selectionIdentity) tea.Cmd {
 	p.nextOperationID++
-	var epoch uint64
+	req.ID = p.nextOperationID
 	if p.ctx != nil {
-		epoch = p.ctx.Epoch
+		req.Epoch = p.ctx.Epoch
 	}
-	req := operationRequest{
-		ID:    p.nextOperationID,
-		Epoch: epoch,
-		Kind:  kind,
-		Args:  append([]string(nil), args...),
+	req.Args = append([]string(nil), req.Args...)
+	if req.WorkDir == "" {
+		req.WorkDir = p.repoRoot
 	}
 	p.activeOperation = &req
 	p.operationSelection = selection
@@ -103,13 +153,25 @@ func (p *Plugin) beginWrite(kind operationKind, args []string, selection selecti
 	if executor == nil {
 		executor = executeGitWrite
 	}
-	workDir := p.repoRoot
 	return func() tea.Msg {
+		record := func(error) {}
+		if req.Undo != nil {
+			record = undojournal.Begin(context.Background(), req.WorkDir, string(req.Kind), *req.Undo)
+		}
+		err := executor(req.WorkDir, req.Args)
+		if err != nil && req.AbortOnConflict {
+			if op := ConflictOperation(req.WorkDir); op != "" && AbortOperation(req.WorkDir, op) == nil {
+				err = fmt.Errorf("%s conflicts in %s; aborted and left unchanged", op, filepath.Base(req.WorkDir))
+			}
+		}
+		record(err)
 		return operationResultMsg{
-			ID:    req.ID,
-			Epoch: req.Epoch,
-			Kind:  req.Kind,
-			Err:   executor(workDir, req.Args),
+			ID:      req.ID,
+			Epoch:   req.Epoch,
+			Kind:    req.Kind,
+			WorkDir: req.WorkDir,
+			Done:    req.Done,
+			Err:     err,
 		}
 	}
 }
@@ -131,7 +193,7 @@ func (p *Plugin) writeInProgress() bool {
 
 func isStatusMutationKey(key string) bool {
 	switch key {
-	case "s", "u", "S", "U", "c", "A", "D", "z", "Z", "ctrl+z", "b", "L", "P", "f":
+	case "s", "u", "S", "U", "c", "A", "D", "z", "Z", "ctrl+z", "ctrl+u", "b", "L", "P", "f":
 		return true
 	default:
 		return false
@@ -145,7 +207,11 @@ func writeBlockedCommand(id string) bool {
 		"stash", "stash-pop", "stash-apply", "confirm-pop",
 		"branch-picker", "pull", "pull-merge", "pull-rebase",
 		"pull-ff-only", "pull-autostash", "abort-pull", "push",
-		"force-push", "push-upstream", "fetch":
+		"force-push", "push-upstream", "fetch",
+		"mark-resolved", "continue-operation", "run-rebase",
+		"commit-actions", "cherry-pick", "revert-commit", "branch-here",
+		"tag-here", "reset-soft", "reset-mixed", "reset-hard", "confirm-reset",
+		"create-ref", "undo-selected", "confirm-undo", "update-submodule":
 		return true
 	default:
 		return false
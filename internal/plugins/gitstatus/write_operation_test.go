@@ -396,6 +396,22 @@ func runGitTest(t *testing.T, dir string, args ...string) string {
 	return string(out)
 }
 
+// initTestRepo creates a repository on main with one empty root commit, under
+// a fixed identity, for tests to add their own commits to.
+func initTestRepo(t *testing.T) string {
+	t.Helper()
+	for _, k := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(k, "Test")
+	}
+	for _, k := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(k, "test@example.com")
+	}
+	repo := t.TempDir()
+	runGitTest(t, repo, "init", "-q", "-b", "main")
+	runGitTest(t, repo, "commit", "-q", "--allow-empty", "-m", "root")
+	return repo
+}
+
 // A refusal fires from every mutation key while a write is in flight, so it
 // must carry a lease. The `waiting` source is sticky by default: without an
 // explicit expiry each impatient keypress would leave a permanent unread entry
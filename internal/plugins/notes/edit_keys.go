@@ -5,6 +5,7 @@ import (
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/marcus/sidecar/internal/clip"
+	"github.com/marcus/sidecar/internal/inlineedit"
 	"github.com/marcus/sidecar/internal/msg"
 	"github.com/marcus/sidecar/internal/ui"
 )
@@ -78,9 +79,16 @@ func (p *Plugin) clearEditSelection() {
 	p.selExtend = false
 }
 
-func (p *Plugin) historyForCurrent() *editHistory {
+// Notes keep a shorter undo history than the code editor, since every note
+// edited in a session keeps its own.
+const (
+	maxEditUndoEntries = 100
+	maxEditUndoBytes   = 2 << 20
+)
+
+func (p *Plugin) historyForCurrent() *inlineedit.History {
 	if p.editHistories == nil {
-		p.editHistories = make(map[string]*editHistory)
+		p.editHistories = make(map[string]*inlineedit.History)
 	}
 	id := "_"
 	if p.editorNote != nil && p.editorNote.ID != "" {
@@ -88,27 +96,30 @@ func (p *Plugin) historyForCurrent() *editHistory {
 	}
 	h := p.editHistories[id]
 	if h == nil {
-		h = &editHistory{}
+		h = &inlineedit.History{MaxEntries: maxEditUndoEntries, MaxBytes: maxEditUndoBytes}
 		p.editHistories[id] = h
 	}
 	return h
 }
 
-func (p *Plugin) snapshot() editSnapshot {
-	return editSnapshot{
-		content: p.editorTextarea.Value(),
-		row:     p.editorTextarea.Line(),
-		col:     p.editorTextarea.Column(),
+func (p *Plugin) snapshot() inlineedit.Snapshot {
+	return inlineedit.Snapshot{
+		Content: p.editorTextarea.Value(),
+		Cursors: []inlineedit.Pos{{Line: p.editorTextarea.Line(), Col: p.editorTextarea.Column()}},
 	}
 }
 
-func (p *Plugin) prepareEdit(kind editOpKind) {
-	p.historyForCurrent().prepareLazy(kind, p.snapshot, time.Now())
+func (p *Plugin) prepareEdit(kind inlineedit.EditKind) {
+	p.historyForCurrent().PrepareLazy(kind, p.snapshot, time.Now())
 }
 
-func (p *Plugin) restoreSnapshot(snap editSnapshot) {
-	p.editorTextarea.SetValue(snap.content)
-	p.setTextareaCursorPosition(snap.row, snap.col)
+func (p *Plugin) restoreSnapshot(snap inlineedit.Snapshot) {
+	p.editorTextarea.SetValue(snap.Content)
+	var caret inlineedit.Pos
+	if len(snap.Cursors) > 0 {
+		caret = snap.Cursors[0]
+	}
+	p.setTextareaCursorPosition(caret.Line, caret.Col)
 	p.clearEditSelection()
 }
 
@@ -117,7 +128,7 @@ func (p *Plugin) continueListOnEnter() (tea.Cmd, bool) {
 	if !ok {
 		return nil, false
 	}
-	p.prepareEdit(editOpTyping)
+	p.prepareEdit(inlineedit.EditTyping)
 	p.editorTextarea.SetValue(next)
 	p.setTextareaCursorPosition(row, col)
 	p.trackTextareaScroll()
@@ -138,7 +149,7 @@ func (p *Plugin) afterContentChange() tea.Cmd {
 }
 
 func (p *Plugin) undoEditorEdit() (pluginResult, tea.Cmd) {
-	prev, ok := p.historyForCurrent().undoTo(p.snapshot())
+	prev, ok := p.historyForCurrent().Undo(p.snapshot())
 	if !ok {
 		return p, nil
 	}
@@ -147,7 +158,7 @@ func (p *Plugin) undoEditorEdit() (pluginResult, tea.Cmd) {
 }
 
 func (p *Plugin) redoEditorEdit() (pluginResult, tea.Cmd) {
-	next, ok := p.historyForCurrent().redoTo(p.snapshot())
+	next, ok := p.historyForCurrent().Redo(p.snapshot())
 	if !ok {
 		return p, nil
 	}
@@ -236,7 +247,7 @@ func (p *Plugin) extendSelectionByMotion(msg tea.KeyPressMsg) (pluginResult, tea
 	return p, cmd
 }
 
-func (p *Plugin) replaceEditorSelection(insert string, kind editOpKind) (pluginResult, tea.Cmd) {
+func (p *Plugin) replaceEditorSelection(insert string, kind inlineedit.EditKind) (pluginResult, tea.Cmd) {
 	if !p.hasEditSelection() {
 		return p, nil
 	}
@@ -249,7 +260,7 @@ func (p *Plugin) replaceEditorSelection(insert string, kind editOpKind) (pluginR
 	return p, p.afterContentChange()
 }
 
-func (p *Plugin) deleteEditorSelection(kind editOpKind) (pluginResult, tea.Cmd) {
+func (p *Plugin) deleteEditorSelection(kind inlineedit.EditKind) (pluginResult, tea.Cmd) {
 	return p.replaceEditorSelection("", kind)
 }
 
@@ -258,7 +269,7 @@ func (p *Plugin) cutEditorSelection() (pluginResult, tea.Cmd) {
 		return p, nil
 	}
 	copyCmd := p.copySelectionCmd()
-	_, delCmd := p.deleteEditorSelection(editOpCut)
+	_, delCmd := p.deleteEditorSelection(inlineedit.EditCut)
 	return p, tea.Batch(copyCmd, delCmd)
 }
 
@@ -5,9 +5,11 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/inlineedit"
 	"github.com/marcus/sidecar/internal/keymap"
 	"github.com/marcus/sidecar/internal/mouse"
 	"github.com/marcus/sidecar/internal/tty"
@@ -248,7 +250,9 @@ func TestNotesCommandsAndBindingsAdvertiseDefaultExplicitAndContextualActions(t
 				p.activePane = PaneEditor
 				p.editorNote = &p.notes[0]
 				p.previewMode = false
-				p.historyForCurrent().redo = []editSnapshot{{content: p.editorTextarea.Value()}}
+				h := p.historyForCurrent()
+				h.Prepare(inlineedit.EditPaste, inlineedit.Snapshot{Content: p.editorTextarea.Value()}, time.Now())
+				h.Undo(inlineedit.Snapshot{Content: p.editorTextarea.Value()})
 			},
 			want: map[string]string{
 				"alt+a": "select-all", "super+a": "select-all", "super+up": "note-start", "super+down": "note-end", "ctrl+y": "redo-edit",
@@ -6,6 +6,7 @@ import (
 
 	"charm.land/bubbles/v2/textarea"
 	tea "charm.land/bubbletea/v2"
+	"github.com/marcus/sidecar/internal/inlineedit"
 	"github.com/marcus/sidecar/internal/msg"
 	"github.com/marcus/sidecar/internal/tty"
 	"github.com/marcus/sidecar/internal/ui"
@@ -70,7 +71,7 @@ type noteMutationSnapshot struct {
 	selExtend        bool
 
 	notePlaces       map[string]notePlace
-	editHistories    map[string]*editHistory
+	editHistories    map[string]*inlineedit.History
 	undoStack        []UndoAction
 	saveQueued       bool
 	saveErr          error
@@ -175,17 +176,14 @@ func cloneNotePlaces(src map[string]notePlace) map[string]notePlace {
 	return dst
 }
 
-func cloneEditHistories(src map[string]*editHistory) map[string]*editHistory {
-	dst := make(map[string]*editHistory, len(src))
+func cloneEditHistories(src map[string]*inlineedit.History) map[string]*inlineedit.History {
+	dst := make(map[string]*inlineedit.History, len(src))
 	for id, history := range src {
 		if history == nil {
 			dst[id] = nil
 			continue
 		}
-		copyHistory := *history
-		copyHistory.undo = append([]editSnapshot(nil), history.undo...)
-		copyHistory.redo = append([]editSnapshot(nil), history.redo...)
-		dst[id] = &copyHistory
+		dst[id] = history.Clone()
 	}
 	return dst
 }
@@ -636,7 +634,7 @@ func moveNoteMapKey(values map[string]notePlace, oldID, newID string) {
 	}
 }
 
-func moveHistoryKey(values map[string]*editHistory, oldID, newID string) {
+func moveHistoryKey(values map[string]*inlineedit.History, oldID, newID string) {
 	if value, ok := values[oldID]; ok {
 		values[newID] = value
 		delete(values, oldID)
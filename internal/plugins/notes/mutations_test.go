@@ -6,8 +6,10 @@ import (
 	"log/slog"
 	"sync"
 	"testing"
+	"time"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/marcus/sidecar/internal/inlineedit"
 	"github.com/marcus/sidecar/internal/plugin"
 	"github.com/marcus/sidecar/internal/ui"
 )
@@ -529,7 +531,9 @@ func TestOptimisticDeleteFailureRestoresExactModalEditorAndUndoState(t *testing.
 	p.previewCursorLine = 3
 	p.previewScrollOff = 2
 	p.notePlaces[notes[0].ID] = notePlace{editRow: 3, editCol: 5, editScrollOff: 2, hasEdit: true}
-	p.editHistories[notes[0].ID] = &editHistory{undo: []editSnapshot{{content: "before", row: 1, col: 2}}, bytes: 6}
+	history := &inlineedit.History{}
+	history.Prepare(inlineedit.EditPaste, inlineedit.Snapshot{Content: "before", Cursors: []inlineedit.Pos{{Line: 1, Col: 2}}}, time.Now())
+	p.editHistories[notes[0].ID] = history
 	p.selection = ui.SelectionState{Active: true, Start: ui.SelectionPoint{Line: 0, Col: 1}, End: ui.SelectionPoint{Line: 0, Col: 3}}
 	p.undoStack = []UndoAction{{Type: UndoArchive, NoteID: notes[1].ID, Title: notes[1].Title}}
 	p.deleteModalNote = &p.notes[0]
@@ -562,7 +566,8 @@ func TestOptimisticDeleteFailureRestoresExactModalEditorAndUndoState(t *testing.
 	if p.selection != beforeSelection || p.notePlaces[notes[0].ID] != beforePlace {
 		t.Fatalf("delete rollback selection/place = %+v %+v", p.selection, p.notePlaces[notes[0].ID])
 	}
-	if len(p.editHistories[notes[0].ID].undo) != 1 || len(p.undoStack) != 1 || p.undoStack[0].Type != UndoArchive {
+	restored := p.editHistories[notes[0].ID].Clone()
+	if prev, ok := restored.Undo(inlineedit.Snapshot{}); !ok || prev.Content != "before" || restored.CanUndo() || len(p.undoStack) != 1 || p.undoStack[0].Type != UndoArchive {
 		t.Fatalf("delete failure corrupted history/undo: history=%+v undo=%+v", p.editHistories[notes[0].ID], p.undoStack)
 	}
 }
@@ -179,7 +179,7 @@ type Plugin struct {
 
 	// Per-note content undo/redo for the built-in editor. The list's
 	// delete/archive stack is separate (undoStack).
-	editHistories    map[string]*editHistory
+	editHistories    map[string]*inlineedit.History
 	lastSavedContent string
 
 	// Task modal state
@@ -338,7 +338,7 @@ func New() *Plugin {
 		markdownView:   true,
 		md:             md,
 		notePlaces:     make(map[string]notePlace),
-		editHistories:  make(map[string]*editHistory),
+		editHistories:  make(map[string]*inlineedit.History),
 		saveActivation: 1,
 	}
 	p.edit.Model = tty.New(nil)
@@ -433,7 +433,7 @@ func (p *Plugin) Init(ctx *plugin.Context) error {
 	p.selection.Clear()
 	p.selAnchor = ui.SelectionPoint{Line: -1, Col: -1}
 	p.selExtend = false
-	p.editHistories = make(map[string]*editHistory)
+	p.editHistories = make(map[string]*inlineedit.History)
 	p.lastSavedContent = ""
 	p.undoErr = nil
 
@@ -1347,7 +1347,7 @@ func (p *Plugin) pasteIntoEditor(content string) (plugin.Plugin, tea.Cmd) {
 	if p.previewMode {
 		p.ensureViewSurface()
 		a := p.viewSurface.At(p.previewCursorLine)
-		p.prepareEdit(editOpPaste)
+		p.prepareEdit(inlineedit.EditPaste)
 		p.insertAtSourceLine(a.SourceLine, content)
 		newRow, newCol := pasteInsertPlace(a.SourceLine, content)
 		if cmd := p.enterEditAt(newRow, newCol); cmd != nil {
@@ -1358,9 +1358,9 @@ func (p *Plugin) pasteIntoEditor(content string) (plugin.Plugin, tea.Cmd) {
 		return p, tea.Batch(cmds...)
 	}
 	if p.hasEditSelection() {
-		return p.replaceEditorSelection(content, editOpPaste)
+		return p.replaceEditorSelection(content, inlineedit.EditPaste)
 	}
-	p.prepareEdit(editOpPaste)
+	p.prepareEdit(inlineedit.EditPaste)
 	p.editorTextarea.InsertString(content)
 	if !p.editorTextarea.Focused() {
 		cmds = append(cmds, p.editorTextarea.Focus())
@@ -1787,14 +1787,14 @@ func (p *Plugin) handleEditorKey(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd) {
 	}
 
 	if p.hasEditSelection() && isDeleteKey(msg) {
-		return p.deleteEditorSelection(editOpReplace)
+		return p.deleteEditorSelection(inlineedit.EditReplace)
 	}
 	if p.hasEditSelection() && isInsertKey(msg) {
 		text := msg.Text
 		if key == "enter" || key == "ctrl+m" {
 			text = "\n"
 		}
-		return p.replaceEditorSelection(text, editOpReplace)
+		return p.replaceEditorSelection(text, inlineedit.EditReplace)
 	}
 
 	if !p.hasEditSelection() && (key == "enter" || key == "ctrl+m") {
@@ -1808,13 +1808,13 @@ func (p *Plugin) handleEditorKey(msg tea.KeyPressMsg) (plugin.Plugin, tea.Cmd) {
 	}
 
 	oldValue := p.editorTextarea.Value()
-	kind := editOpNone
+	kind := inlineedit.EditNone
 	if isInsertKey(msg) {
-		kind = editOpTyping
+		kind = inlineedit.EditTyping
 	} else if isDeleteKey(msg) {
-		kind = editOpDelete
+		kind = inlineedit.EditDelete
 	}
-	if kind != editOpNone {
+	if kind != inlineedit.EditNone {
 		p.prepareEdit(kind)
 	}
 
@@ -3142,13 +3142,13 @@ func (p *Plugin) Commands() []plugin.Command {
 		if p.hasEditSelection() {
 			cmds = append(cmds, plugin.Command{ID: "cut", Name: "Cut", Description: "Cut selection", Category: plugin.CategoryActions, Context: "notes-editor", Priority: 3})
 		}
-		if p.historyForCurrent().canUndo() {
+		if p.historyForCurrent().CanUndo() {
 			cmds = append(cmds, plugin.Command{
 				ID: "undo-edit", Name: "Undo", Description: "Undo last edit", Category: plugin.CategoryActions, Context: "notes-editor", Priority: 3,
 				Handler: func() tea.Cmd { _, cmd := p.undoEditorEdit(); return cmd },
 			})
 		}
-		if p.historyForCurrent().canRedo() {
+		if p.historyForCurrent().CanRedo() {
 			cmds = append(cmds, plugin.Command{
 				ID: "redo-edit", Name: "Redo", Description: "Redo last undone edit", Category: plugin.CategoryActions, Context: "notes-editor", Priority: 4,
 				Handler: func() tea.Cmd { _, cmd := p.redoEditorEdit(); return cmd },
@@ -328,12 +328,12 @@ func TestSyncEditorFromNoteDropsHistory(t *testing.T) {
 	p := newEditPlugin(t, "mine")
 	p.setTextareaCursorPosition(0, 4)
 	typeKey(p, tea.KeyPressMsg{Code: 'x', Text: "x"})
-	if !p.historyForCurrent().canUndo() {
+	if !p.historyForCurrent().CanUndo() {
 		t.Fatal("expected undo after typing")
 	}
 	p.editorNote.Content = "theirs"
 	p.syncEditorFromNote(p.editorNote)
-	if p.historyForCurrent().canUndo() {
+	if p.historyForCurrent().CanUndo() {
 		t.Fatal("sync kept pre-reload undo snapshots")
 	}
 	if got := p.editorTextarea.Value(); got != "theirs" {
@@ -1705,19 +1705,8 @@ func (p *Plugin) StopAgent(wt *Worktree) tea.Cmd {
 			return AgentStoppedMsg{WorkspaceName: wt.Name}
 		}
 
-		sessionName := wt.Agent.TmuxSession
-
-		// Try graceful interrupt first (Ctrl+C)
-		_ = exec.Command("tmux", "send-keys", "-t", sessionName, "C-c").Run()
-
-		// Wait briefly for graceful shutdown
-		time.Sleep(2 * time.Second)
-
-		// Check if still running
-		if sessionExists(sessionName) {
-			// Force kill
-			_ = exec.Command("tmux", "kill-session", "-t", sessionName).Run()
-		}
+		// Ctrl-C first, then a forced close if it is still running.
+		_ = workspaceops.StopWorktreeAgent(context.Background(), wt.Agent.TmuxSession, 2*time.Second)
 
 		return AgentStoppedMsg{WorkspaceName: wt.Name}
 	}
@@ -7,6 +7,7 @@ import (
 
 	tea "charm.land/bubbletea/v2"
 
+	"github.com/marcus/sidecar/internal/features"
 	"github.com/marcus/sidecar/internal/notify"
 )
 
@@ -32,10 +33,15 @@ func (p *Plugin) notifyAgentTransitions(now time.Time) tea.Cmd {
 		return nil
 	}
 	events := p.agentLaneTracker.Observe(p.agentLaneObservations(), now)
-	if events.Empty() {
+	// Only scripts listen to bare transitions; without them every settled
+	// change would be a message nobody reads.
+	if !features.IsEnabled(features.Scripting.Name) {
+		events.Transitions = nil
+	}
+	if events.Empty() && len(events.Transitions) == 0 {
 		return nil
 	}
-	cmds := make([]tea.Cmd, 0, len(events.Post)+len(events.Dismiss))
+	cmds := make([]tea.Cmd, 0, len(events.Post)+len(events.Dismiss)+len(events.Transitions))
 	for _, n := range events.Post {
 		posted := n
 		cmds = append(cmds, func() tea.Msg { return notify.PostMsg{Notification: posted} })
@@ -44,6 +50,10 @@ func (p *Plugin) notifyAgentTransitions(now time.Time) tea.Cmd {
 		dismissed := id
 		cmds = append(cmds, func() tea.Msg { return notify.DismissMsg{ID: dismissed} })
 	}
+	for _, tr := range events.Transitions {
+		settled := tr
+		cmds = append(cmds, func() tea.Msg { return notify.LaneTransitionMsg{Transition: settled} })
+	}
 	return tea.Batch(cmds...)
 }
 
@@ -0,0 +1,424 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/marcus/sidecar/internal/forge"
+	"github.com/marcus/sidecar/internal/notify"
+	"github.com/marcus/sidecar/internal/resource"
+	"github.com/marcus/sidecar/internal/resourceview"
+	"github.com/marcus/sidecar/internal/styles"
+)
+
+const (
+	// ciPollInterval is how often a branch whose checks have not settled is
+	// asked again.
+	ciPollInterval = 30 * time.Second
+	// ciQuietPolls is how many polls a pushed commit may report no checks,
+	// or fail to report at all, before it is taken to have no CI.
+	ciQuietPolls = 10
+	// ciLogTimeout bounds fetching one job log for the resource pane.
+	ciLogTimeout = 60 * time.Second
+)
+
+// ciResourceInstance is the built-in resource provider failed job logs open
+// under. It never reaches the external provider manager.
+const (
+	ciResourceInstance = "sidecar-ci"
+	ciResourceMatcher  = "job-log"
+)
+
+var errNoCITarget = errors.New("branch has not been pushed to a forge")
+
+// CIStatus is the CI state of the commit a workspace branch last pushed.
+type CIStatus struct {
+	Repo   forge.Repo
+	OID    string
+	State  forge.CheckState // Empty until the forge reports a check
+	Checks []forge.Check
+	Err    error
+	// Polls counts the reads of OID, so a commit with no CI stops being
+	// asked about.
+	Polls     int
+	CheckedAt time.Time
+	// trigger is the worktree state the status was read for; a push or a
+	// new pull request changes it and makes the status due again.
+	trigger string
+}
+
+// done reports whether the forge need not be asked about OID again.
+func (s *CIStatus) done() bool {
+	return s.State.Settled() || s.Polls >= ciQuietPolls
+}
+
+// failedCheck is the failed check whose log is worth opening: the first
+// with a log, else the first failed.
+func (s *CIStatus) failedCheck() (forge.Check, bool) {
+	if s == nil {
+		return forge.Check{}, false
+	}
+	var first *forge.Check
+	for i := range s.Checks {
+		c := &s.Checks[i]
+		if c.State != forge.CheckFailed {
+			continue
+		}
+		if c.ID != "" {
+			return *c, true
+		}
+		if first == nil {
+			first = c
+		}
+	}
+	if first == nil {
+		return forge.Check{}, false
+	}
+	return *first, true
+}
+
+// CIStatusMsg carries one read of a worktree's checks.
+type CIStatusMsg struct {
+	OperationScope
+	WorktreeKey string
+	Status      *CIStatus
+	// Unchanged reports that the pushed commit is the one whose settled
+	// checks are already known, so the forge was not asked.
+	Unchanged bool
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m CIStatusMsg) GetEpoch() uint64 { return m.Epoch }
+
+// ciTickMsg re-reads the checks of tracked worktrees.
+type ciTickMsg struct{ Epoch uint64 }
+
+// GetEpoch implements plugin.EpochMessage.
+func (m ciTickMsg) GetEpoch() uint64 { return m.Epoch }
+
+// ciTracked reports whether a worktree's branch has CI worth showing: it was
+// pushed, or has a pull request, and that request has not been merged or
+// closed.
+func ciTracked(wt *Worktree) bool {
+	if wt == nil || wt.IsMain || wt.IsMissing || wt.IsBare || wt.IsDetached {
+		return false
+	}
+	if wt.PRState == "merged" || wt.PRState == "closed" {
+		return false
+	}
+	return wt.Upstream != "" || wt.PRURL != ""
+}
+
+func ciTrigger(wt *Worktree) string {
+	return wt.Upstream + "@" + wt.HEADOID + " " + wt.PRURL
+}
+
+// maybeRefreshCI re-reads the checks of every tracked worktree not read in
+// the last ciPollInterval, or changed since. Settled checks cost only local
+// Git calls: the forge is asked again once the pushed commit moves.
+func (p *Plugin) maybeRefreshCI() tea.Cmd {
+	var cmds []tea.Cmd
+	now := time.Now()
+	tracked := false
+	for _, wt := range p.worktrees {
+		if !ciTracked(wt) {
+			continue
+		}
+		tracked = true
+		key := wt.IdentityKey()
+		if p.ciInFlight[key] {
+			continue
+		}
+		if st := p.ciStatus[key]; st != nil && st.trigger == ciTrigger(wt) && now.Sub(st.CheckedAt) < ciPollInterval {
+			continue
+		}
+		cmds = append(cmds, p.fetchCIStatus(wt))
+	}
+	if tracked && !p.ciTicking {
+		p.ciTicking = true
+		epoch := p.ctx.Epoch
+		cmds = append(cmds, tea.Tick(ciPollInterval, func(time.Time) tea.Msg { return ciTickMsg{Epoch: epoch} }))
+	}
+	return tea.Batch(cmds...)
+}
+
+// refreshCINow re-reads a worktree's checks at once, for the moments a push
+// or a new pull request is known to have changed them.
+func (p *Plugin) refreshCINow(wt *Worktree) tea.Cmd {
+	if !ciTracked(wt) || p.ciInFlight[wt.IdentityKey()] {
+		return nil
+	}
+	return p.fetchCIStatus(wt)
+}
+
+func (p *Plugin) fetchCIStatus(wt *Worktree) tea.Cmd {
+	ctx, scope := p.newOperationScope(wt)
+	key, path, upstream, remote := wt.IdentityKey(), wt.Path, wt.Upstream, wt.Remote
+	trigger := ciTrigger(wt)
+	projectRoot := p.ctx.ProjectRoot
+	known := ""
+	if st := p.ciStatus[key]; st != nil && st.done() {
+		known = st.OID
+	}
+	if p.ciInFlight == nil {
+		p.ciInFlight = make(map[string]bool)
+	}
+	p.ciInFlight[key] = true
+	return func() tea.Msg {
+		st := &CIStatus{trigger: trigger, CheckedAt: time.Now()}
+		repo, oid, err := resolveCITargetContext(ctx, projectRoot, path, upstream, remote)
+		if err == nil && oid == known {
+			return CIStatusMsg{OperationScope: scope, WorktreeKey: key, Status: st, Unchanged: true}
+		}
+		st.Repo, st.OID = repo, oid
+		if err == nil {
+			var checks []forge.Check
+			checks, err = forge.New(repo, path).Checks(ctx, oid)
+			st.Checks, st.State = checks, forge.Summarize(checks)
+		}
+		st.Err = err
+		return CIStatusMsg{OperationScope: scope, WorktreeKey: key, Status: st}
+	}
+}
+
+// resolveCITargetContext finds the commit whose checks a worktree shows and
+// the repository that runs them. The commit is the pushed tip when the
+// branch tracks one, else the head its pull request was opened at.
+func resolveCITargetContext(ctx context.Context, projectRoot, dir, upstream, remote string) (forge.Repo, string, error) {
+	var repo forge.Repo
+	var oid string
+	if upstream != "" && remote != "" {
+		oid, _ = gitOutputContext(ctx, dir, "rev-parse", "--verify", "--quiet", "refs/remotes/"+upstream+"^{commit}")
+		if remoteURL, err := configuredRemoteURLContext(ctx, dir, remote); err == nil {
+			repo, _ = parseRemoteRepositoryContext(ctx, dir, remoteURL)
+		}
+	}
+	if identity := loadPRIdentityContext(ctx, projectRoot, dir); identity.Repository != "" {
+		if oid == "" {
+			oid = identity.HeadOID
+		}
+		// GitHub and Gitea run a fork's pull request checks in the base
+		// repository; GitLab runs them in the fork, which is where the
+		// branch was pushed.
+		if prRepo := identity.forgeRepo(); prRepo.Kind != forge.GitLab || repo.IsZero() {
+			repo = prRepo
+		}
+	}
+	if repo.IsZero() || oid == "" {
+		return forge.Repo{}, "", errNoCITarget
+	}
+	return repo, oid, nil
+}
+
+// applyCIStatus records a read and returns the notification owed when the
+// checks of the same commit have just settled.
+func (p *Plugin) applyCIStatus(msg CIStatusMsg) tea.Cmd {
+	delete(p.ciInFlight, msg.WorktreeKey)
+	next := msg.Status
+	if next == nil {
+		return nil
+	}
+	if p.ciStatus == nil {
+		p.ciStatus = make(map[string]*CIStatus)
+	}
+	prev := p.ciStatus[msg.WorktreeKey]
+	if msg.Unchanged && prev != nil {
+		prev.trigger, prev.CheckedAt = next.trigger, next.CheckedAt
+		return nil
+	}
+	sameCommit := prev != nil && prev.OID == next.OID
+	if sameCommit {
+		next.Polls = prev.Polls + 1
+		if next.Err != nil {
+			// A failed read keeps what was last known about the commit.
+			next.State, next.Checks = prev.State, prev.Checks
+		}
+	} else {
+		next.Polls = 1
+	}
+	p.ciStatus[msg.WorktreeKey] = next
+	if !sameCommit || next.OID == "" || prev.State.Settled() || !next.State.Settled() {
+		return nil
+	}
+	wt := p.findWorktree(msg.WorktreeKey)
+	if wt == nil {
+		return nil
+	}
+	return ciSettledNotification(wt, next)
+}
+
+func ciSettledNotification(wt *Worktree, st *CIStatus) tea.Cmd {
+	n := notify.Notification{Source: notify.SourceCI, Severity: notify.SeverityInfo}
+	if st.State == forge.CheckFailed {
+		n.Severity = notify.SeverityError
+		n.Title = fmt.Sprintf("CI failed on %s", wt.Branch)
+		var names []string
+		for _, c := range st.Checks {
+			if c.State == forge.CheckFailed {
+				names = append(names, c.Name)
+			}
+		}
+		n.Body = "Failed: " + strings.Join(names, ", ")
+		if failed, ok := st.failedCheck(); ok && failed.URL != "" {
+			n.Targets = append(n.Targets, notify.Target{Kind: notify.TargetURL, Value: failed.URL})
+		}
+	} else {
+		n.Title = fmt.Sprintf("CI passed on %s", wt.Branch)
+		n.Body = fmt.Sprintf("%d checks passed", len(st.Checks))
+	}
+	if wt.PRURL != "" {
+		n.Targets = append(n.Targets, notify.Target{Kind: notify.TargetURL, Value: wt.PRURL})
+	}
+	return func() tea.Msg { return notify.PostMsg{Notification: n} }
+}
+
+// ciBadge is the sidebar glyph for a worktree's checks and its style.
+func (p *Plugin) ciBadge(wt *Worktree) (string, func(...string) string) {
+	st := p.ciStatus[wt.IdentityKey()]
+	if st == nil || !ciTracked(wt) {
+		return "", nil
+	}
+	switch st.State {
+	case forge.CheckPassed:
+		return "✓", styles.StatusCompleted.Render
+	case forge.CheckFailed:
+		return "✗", styles.StatusBlocked.Render
+	case forge.CheckRunning:
+		return "●", styles.StatusInProgress.Render
+	case forge.CheckQueued:
+		return "○", styles.StatusPending.Render
+	}
+	return "", nil
+}
+
+// ciLabel is the state line the sidebar and the merge workflow show.
+func ciLabel(st *CIStatus) string {
+	if st == nil || st.State == "" {
+		return ""
+	}
+	counts := map[forge.CheckState]int{}
+	for _, c := range st.Checks {
+		counts[c.State]++
+	}
+	switch st.State {
+	case forge.CheckFailed:
+		return fmt.Sprintf("CI failed (%d of %d)", counts[forge.CheckFailed], len(st.Checks))
+	case forge.CheckRunning, forge.CheckQueued:
+		return fmt.Sprintf("CI %s (%d of %d done)", st.State, counts[forge.CheckPassed]+counts[forge.CheckFailed], len(st.Checks))
+	default:
+		return "CI passed"
+	}
+}
+
+// openCILog opens the log of a worktree's failed job in a Resource pane.
+func (p *Plugin) openCILog(wt *Worktree) tea.Cmd {
+	st := p.ciStatus[wt.IdentityKey()]
+	check, ok := st.failedCheck()
+	if !ok {
+		return func() tea.Msg {
+			return notify.Alert(notify.SourceCI, notify.SeverityInfo, "No failed CI job on "+wt.Branch)
+		}
+	}
+	if check.ID == "" {
+		// External CI keeps its own logs; its status links to them.
+		if check.URL != "" {
+			return openInBrowser(check.URL)
+		}
+		return func() tea.Msg {
+			return notify.Alert(notify.SourceCI, notify.SeverityWarning, check.Name+" has no log on "+st.Repo.Kind.Title())
+		}
+	}
+	root, surface, ok := p.selectedTerminalSurface()
+	if !ok {
+		return nil
+	}
+	return p.openRequestedResourcePaneForSurface(root, surface, ciLogRef(st.Repo, check))
+}
+
+// ciLogRef names a job log as a resource reference. The locator is the
+// repository's web URL, the forge, the job ID and the job's name, so a
+// restored tab resolves without any other state.
+func ciLogRef(repo forge.Repo, check forge.Check) resourceview.Ref {
+	locator := strings.Join([]string{repo.WebURL(), string(repo.Kind), check.ID, check.Name}, " ")
+	if runes := []rune(locator); len(runes) > resource.MaxLocatorChars {
+		locator = string(runes[:resource.MaxLocatorChars])
+	}
+	return resourceview.Ref{Instance: ciResourceInstance, Matcher: ciResourceMatcher, Locator: locator}
+}
+
+func parseCILogLocator(locator string) (forge.Repo, string, string, error) {
+	fields := strings.SplitN(locator, " ", 4)
+	if len(fields) < 3 {
+		return forge.Repo{}, "", "", fmt.Errorf("malformed CI log reference %q", locator)
+	}
+	kind, ok := forge.ParseKind(fields[1])
+	if !ok {
+		return forge.Repo{}, "", "", fmt.Errorf("unknown forge %q", fields[1])
+	}
+	repo, err := forge.ParseRemote(fields[0], kind)
+	if err != nil {
+		return forge.Repo{}, "", "", err
+	}
+	name := fields[2]
+	if len(fields) == 4 {
+		name = fields[3]
+	}
+	return repo, fields[2], name, nil
+}
+
+func init() {
+	resourceview.RegisterBuiltin(ciResourceInstance, resolveCILogRef)
+}
+
+// resolveCILogRef is the resolver for the built-in CI log references. They
+// are routed here by resourceview, so the host's resolver never sees them.
+func resolveCILogRef(modelID int, generation, epoch uint64, ref resource.Reference, refresh bool) tea.Cmd {
+	return func() tea.Msg {
+		msg := resourceview.ResolvedMsg{ModelID: modelID, Generation: generation, Epoch: epoch, Ref: ref, Refresh: refresh}
+		ctx, cancel := context.WithTimeout(context.Background(), ciLogTimeout)
+		defer cancel()
+		msg.Document, msg.Err = resolveCILog(ctx, ref)
+		return msg
+	}
+}
+
+func resolveCILog(ctx context.Context, ref resource.Reference) (resource.Document, error) {
+	repo, id, name, err := parseCILogLocator(ref.Locator)
+	if err != nil {
+		return resource.Document{}, resource.Errorf(resource.CodeInvalidRequest, "%s", err.Error())
+	}
+	log, err := forge.New(repo, "").CheckLog(ctx, id)
+	if errors.Is(err, forge.ErrNoLog) {
+		return resource.Document{}, resource.Errorf(resource.CodeNotFound, "%s", err.Error())
+	}
+	if err != nil {
+		return resource.Document{}, resource.Errorf(resource.CodeUnavailable, "%s", err.Error())
+	}
+	body, truncated := resource.SanitizeBodyText(ciLogTail(ansi.Strip(log), resource.MaxBodyBytes), resource.MaxBodyBytes)
+	return resource.Document{
+		Identity: ref.Locator,
+		Title:    resource.SanitizeLine("CI log · "+name, resource.MaxTitleChars),
+		Subtitle: resource.SanitizeLine(repo.String()+" job "+id, resource.MaxSubtitleChars),
+		Status:   &resource.Status{Label: "failed", Tone: resource.ToneDanger},
+		Body:     &resource.Body{Format: resource.FormatText, Text: body, Truncated: truncated},
+	}, nil
+}
+
+// ciLogTail keeps the end of a log, where a failure is, within maxBytes.
+func ciLogTail(log string, maxBytes int) string {
+	const marker = "… earlier output trimmed\n"
+	if len(log) <= maxBytes {
+		return log
+	}
+	tail := log[len(log)-(maxBytes-len(marker)):]
+	if i := strings.IndexByte(tail, '\n'); i >= 0 {
+		tail = tail[i+1:]
+	}
+	return marker + tail
+}
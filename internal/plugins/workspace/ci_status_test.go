@@ -0,0 +1,147 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/marcus/sidecar/internal/forge"
+	"github.com/marcus/sidecar/internal/notify"
+	"github.com/marcus/sidecar/internal/resource"
+	"github.com/marcus/sidecar/internal/resourceview"
+)
+
+func TestResolveCITargetUsesPushedTip(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	repo := filepath.Join(t.TempDir(), "repo")
+	mustGit(t, filepath.Dir(repo), "init", repo)
+	mustGit(t, repo, "config", "user.email", "sidecar-test@example.com")
+	mustGit(t, repo, "config", "user.name", "Sidecar Test")
+	mustWrite(t, filepath.Join(repo, "a.txt"), "a\n")
+	mustGit(t, repo, "add", "a.txt")
+	mustGit(t, repo, "commit", "-m", "a")
+	pushed := mustGit(t, repo, "rev-parse", "HEAD")
+	mustGit(t, repo, "remote", "add", "origin", "git@github.com:o/r.git")
+	mustGit(t, repo, "update-ref", "refs/remotes/origin/feature", pushed)
+	// A local commit not yet pushed must not be what CI is asked about.
+	mustWrite(t, filepath.Join(repo, "a.txt"), "b\n")
+	mustGit(t, repo, "commit", "-am", "b")
+
+	got, oid, err := resolveCITargetContext(context.Background(), repo, repo, "origin/feature", "origin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if oid != pushed || got.Kind != forge.GitHub || got.Path != "o/r" {
+		t.Fatalf("target = %+v @ %s, want o/r @ %s", got, oid, pushed)
+	}
+	if _, _, err := resolveCITargetContext(context.Background(), repo, repo, "", ""); !errors.Is(err, errNoCITarget) {
+		t.Fatalf("unpushed err = %v", err)
+	}
+}
+
+func TestApplyCIStatusNotifiesOnlyWhenChecksSettle(t *testing.T) {
+	p := New()
+	wt := &Worktree{Name: "feature", Path: "/tmp/feature", Branch: "feature", Upstream: "origin/feature", PRURL: "https://github.com/o/r/pull/7"}
+	p.worktrees = []*Worktree{wt}
+	key := wt.IdentityKey()
+	read := func(oid string, checks ...forge.Check) CIStatusMsg {
+		return CIStatusMsg{WorktreeKey: key, Status: &CIStatus{OID: oid, Checks: checks, State: forge.Summarize(checks)}}
+	}
+
+	if cmd := p.applyCIStatus(read("a1", forge.Check{Name: "test", State: forge.CheckPassed})); cmd != nil {
+		t.Fatal("first read of an already settled commit notified")
+	}
+	if cmd := p.applyCIStatus(read("b2", forge.Check{Name: "test", State: forge.CheckRunning})); cmd != nil {
+		t.Fatal("new commit notified")
+	}
+	if badge, _ := p.ciBadge(wt); badge != "●" {
+		t.Fatalf("running badge = %q", badge)
+	}
+	failed := forge.Check{ID: "9", Name: "test", State: forge.CheckFailed, URL: "https://github.com/o/r/actions/runs/1/job/9"}
+	cmd := p.applyCIStatus(read("b2", failed, forge.Check{Name: "lint", State: forge.CheckPassed}))
+	if cmd == nil {
+		t.Fatal("settled checks did not notify")
+	}
+	post, ok := cmd().(notify.PostMsg)
+	if !ok {
+		t.Fatalf("msg = %T", cmd())
+	}
+	n := post.Notification
+	if n.Source != notify.SourceCI || n.Severity != notify.SeverityError || n.Title != "CI failed on feature" || n.Body != "Failed: test" {
+		t.Fatalf("notification = %+v", n)
+	}
+	if len(n.Targets) != 2 || n.Targets[0].Value != failed.URL || n.Targets[1].Value != wt.PRURL {
+		t.Fatalf("targets = %+v", n.Targets)
+	}
+	if label := ciLabel(p.ciStatus[key]); label != "CI failed (1 of 2)" {
+		t.Fatalf("label = %q", label)
+	}
+
+	// A failed read keeps the settled state rather than re-announcing it.
+	errRead := read("b2")
+	errRead.Status.Err = errors.New("rate limited")
+	if cmd := p.applyCIStatus(errRead); cmd != nil {
+		t.Fatal("failed read notified")
+	}
+	if st := p.ciStatus[key]; st.State != forge.CheckFailed || st.Polls != 3 || !st.done() {
+		t.Fatalf("status after failed read = %+v", st)
+	}
+}
+
+func TestCILogResolverTrimsLogAndBypassesHostResolver(t *testing.T) {
+	installFakeGH(t, `
+case "$*" in
+"run view --repo o/r --job 9 --log-failed") printf 'test\tstep\t\033[31mboom\033[0m\n' ;;
+esac`)
+	ref := ciLogRef(forge.Repo{Kind: forge.GitHub, Host: "github.com", Path: "o/r"}, forge.Check{ID: "9", Name: "unit tests"})
+	repo, id, name, err := parseCILogLocator(ref.Locator)
+	if err != nil || repo.Path != "o/r" || id != "9" || name != "unit tests" {
+		t.Fatalf("parsed = %+v %q %q, %v", repo, id, name, err)
+	}
+
+	if cmd := resourceview.New(nil, nil).Load(1, ref, 3); cmd == nil {
+		t.Fatal("a CI log reference needs no host resolver")
+	}
+	host := false
+	view := resourceview.New(nil, func(int, uint64, uint64, resource.Reference, bool) tea.Cmd {
+		host = true
+		return nil
+	})
+	cmd := view.Load(1, ref, 3)
+	if host || cmd == nil {
+		t.Fatal("a CI log reference reached the host resolver")
+	}
+	msg := cmd().(resourceview.ResolvedMsg)
+	if msg.Err != nil {
+		t.Fatal(msg.Err)
+	}
+	if msg.Document.Title != "CI log · unit tests" || msg.Document.Body == nil || msg.Document.Body.Text != "test\tstep\tboom\n" {
+		t.Fatalf("document = %+v body %+v", msg.Document, msg.Document.Body)
+	}
+}
+
+func TestCILogRefTruncatesLocatorByRunes(t *testing.T) {
+	ref := ciLogRef(forge.Repo{Kind: forge.GitHub, Host: "github.com", Path: "o/r"}, forge.Check{ID: "9", Name: strings.Repeat("é", resource.MaxLocatorChars)})
+	if !utf8.ValidString(ref.Locator) || utf8.RuneCountInString(ref.Locator) != resource.MaxLocatorChars {
+		t.Fatalf("locator = %d runes, valid %v", utf8.RuneCountInString(ref.Locator), utf8.ValidString(ref.Locator))
+	}
+	if !ref.Valid() {
+		t.Fatal("a truncated CI log reference should stay valid")
+	}
+}
+
+func TestCILogTailKeepsEndOfLog(t *testing.T) {
+	log := strings.Repeat("setup\n", 20) + "FAIL: TestX\n"
+	got := ciLogTail(log, 40)
+	if len(got) > 40 || !strings.HasPrefix(got, "… earlier output trimmed\n") || !strings.HasSuffix(got, "FAIL: TestX\n") {
+		t.Fatalf("tail = %q", got)
+	}
+	if got := ciLogTail("short\n", 40); got != "short\n" {
+		t.Fatalf("short log = %q", got)
+	}
+}
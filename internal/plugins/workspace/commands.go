@@ -173,6 +173,11 @@ func (p *Plugin) Commands() []plugin.Command {
 				cmds = append(cmds, plugin.Command{ID: mergeStopWatchingID, Name: "Stop", Description: "Stop watching and keep PR URL", Context: "workspace-merge", Priority: 3})
 				cmds = append(cmds, plugin.Command{ID: "open-pr", Name: "Open", Description: "Open PR in browser", Context: "workspace-merge", Priority: 4})
 				cmds = append(cmds, plugin.Command{ID: "copy-pr", Name: "Copy", Description: "Copy PR URL", Context: "workspace-merge", Priority: 5})
+				if wt := p.mergeState.Worktree; wt != nil {
+					if _, failed := p.ciStatus[wt.IdentityKey()].failedCheck(); failed {
+						cmds = append(cmds, plugin.Command{ID: "ci-log", Name: "Log", Description: "Open the failed CI job's log", Context: "workspace-merge", Priority: 6})
+					}
+				}
 			case MergeStepGeneratePR:
 				cmds = append(cmds,
 					plugin.Command{ID: mergeFallbackDraftID, Name: "Draft", Description: "Use local commit summary", Context: "workspace-merge", Priority: 2},
@@ -235,6 +240,25 @@ func (p *Plugin) Commands() []plugin.Command {
 			{ID: "cancel", Name: "Cancel", Description: "Close file picker", Context: "workspace-file-picker", Priority: 1},
 			{ID: "select", Name: "Jump", Description: "Jump to selected file", Context: "workspace-file-picker", Priority: 2},
 		}
+	case ViewModeConflictMatrix:
+		return []plugin.Command{
+			{ID: "close", Name: "Close", Description: "Close the conflict matrix", Context: "workspace-conflicts", Priority: 1},
+		}
+	case ViewModeReviewThreads:
+		if p.reviewReplying {
+			return []plugin.Command{
+				{ID: "cancel", Name: "Cancel", Description: "Discard the reply", Context: "workspace-reviews", Priority: 1},
+				{ID: "confirm", Name: "Post", Description: "Post the reply", Context: "workspace-reviews", Priority: 2},
+			}
+		}
+		return []plugin.Command{
+			{ID: "close", Name: "Close", Description: "Close review threads", Context: "workspace-reviews", Priority: 1},
+			{ID: "reply", Name: "Reply", Description: "Reply to the thread", Context: "workspace-reviews", Priority: 2},
+			{ID: "resolve", Name: "Resolve", Description: "Resolve the thread", Context: "workspace-reviews", Priority: 3},
+			{ID: "send-to-agent", Name: "Agent", Description: "Send the comment to the worktree's agent", Context: "workspace-reviews", Priority: 4},
+			{ID: "open-in-browser", Name: "Open", Description: "Open the thread in the browser", Context: "workspace-reviews", Priority: 5},
+			{ID: "refresh", Name: "Refresh", Description: "Read the threads again", Context: "workspace-reviews", Priority: 6},
+		}
 	default:
 		// View toggle label changes based on current mode
 		viewToggleName := "Kanban"
@@ -389,6 +413,12 @@ func (p *Plugin) Commands() []plugin.Command {
 			}
 			cmds = append(cmds, plugin.Command{ID: "rename-worktree", Name: "Rename", Description: "Rename worktree", Context: "workspace-list", Priority: 12})
 			cmds = append(cmds, plugin.Command{ID: "open-in-git", Name: "Git", Description: "Open in Git tab", Context: "workspace-list", Priority: 16})
+			if _, failed := p.ciStatus[wt.IdentityKey()].failedCheck(); failed && ciTracked(wt) {
+				cmds = append(cmds, plugin.Command{ID: "ci-log", Name: "CI log", Description: "Open the failed CI job's log", Context: "workspace-list", Priority: 13})
+			}
+			if wt.PRURL != "" {
+				cmds = append(cmds, plugin.Command{ID: "review-comments", Name: "Reviews", Description: "Review threads on the pull request", Context: "workspace-list", Priority: 14})
+			}
 			// Task linking
 			if wt.TaskID != "" {
 				cmds = append(cmds,
@@ -400,6 +430,9 @@ func (p *Plugin) Commands() []plugin.Command {
 				)
 			}
 		}
+		if len(conflictMatrixWorktrees(p.worktrees)) > 0 {
+			cmds = append(cmds, plugin.Command{ID: "conflict-matrix", Name: "Conflicts", Description: "Predict merge conflicts across worktrees", Context: "workspace-list", Priority: 20})
+		}
 		if terminalPanelEnabled() {
 			termName := "Term"
 			if p.termPanelVisible {
@@ -456,6 +489,10 @@ func (p *Plugin) FocusContext() string {
 		return "workspace-fetch-pr"
 	case ViewModeFilePicker:
 		return "workspace-file-picker"
+	case ViewModeConflictMatrix:
+		return "workspace-conflicts"
+	case ViewModeReviewThreads:
+		return "workspace-reviews"
 	default:
 		// A pane-scoped search is its own text-input context: while a query has
 		// focus the document's keys — and the host's root-context q — must not
@@ -526,6 +563,8 @@ func (p *Plugin) ConsumesTextInput() bool {
 		return true
 	case ViewModeMerge:
 		return p.mergeState != nil && p.mergeState.Step == MergeStepEditPR
+	case ViewModeReviewThreads:
+		return p.reviewReplying
 	default:
 		return false
 	}
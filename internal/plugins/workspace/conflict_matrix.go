@@ -0,0 +1,224 @@
+package workspace
+
+import (
+	"fmt"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/marcus/sidecar/internal/styles"
+	"github.com/marcus/sidecar/internal/ui"
+)
+
+// maxMatrixDetailLines caps the hunk list under the matrix.
+const maxMatrixDetailLines = 10
+
+// openConflictMatrix shows predicted conflicts between every pair of
+// worktrees, and between each worktree and its target branch, and starts a
+// fresh prediction: uncommitted edits can change without changing the
+// fingerprint the background trigger watches.
+func (p *Plugin) openConflictMatrix() tea.Cmd {
+	worktrees := conflictMatrixWorktrees(p.worktrees)
+	if len(worktrees) == 0 {
+		return nil
+	}
+	p.conflictMatrixReturnMode = p.viewMode
+	p.viewMode = ViewModeConflictMatrix
+	p.conflictMatrixRow, p.conflictMatrixCol = 0, len(worktrees)
+	if sel := p.selectedWorktree(); sel != nil {
+		for i, wt := range worktrees {
+			if wt.IdentityKey() == sel.IdentityKey() {
+				p.conflictMatrixRow = i
+			}
+		}
+	}
+	p.conflictFingerprint = ""
+	return p.maybePredictConflicts()
+}
+
+// handleConflictMatrixKeys moves the cell cursor; the detail under the matrix
+// follows it.
+func (p *Plugin) handleConflictMatrixKeys(msg tea.KeyPressMsg) tea.Cmd {
+	n := len(conflictMatrixWorktrees(p.worktrees))
+	if n == 0 {
+		p.viewMode = p.conflictMatrixReturnMode
+		return nil
+	}
+	switch msg.String() {
+	case "esc", "q", "C":
+		p.viewMode = p.conflictMatrixReturnMode
+	case "j", "down":
+		p.conflictMatrixRow = min(p.conflictMatrixRow+1, n-1)
+	case "k", "up":
+		p.conflictMatrixRow = max(p.conflictMatrixRow-1, 0)
+	case "l", "right":
+		p.conflictMatrixCol = min(p.conflictMatrixCol+1, n)
+	case "h", "left":
+		p.conflictMatrixCol = max(p.conflictMatrixCol-1, 0)
+	}
+	p.conflictMatrixRow = min(p.conflictMatrixRow, n-1)
+	p.conflictMatrixCol = min(p.conflictMatrixCol, n)
+	return nil
+}
+
+// renderConflictMatrixModal overlays the matrix on the list. Rows and columns
+// are the active worktrees, numbered; the last column is each row's target
+// branch.
+func (p *Plugin) renderConflictMatrixModal(background string) string {
+	worktrees := conflictMatrixWorktrees(p.worktrees)
+	if len(worktrees) == 0 {
+		return background
+	}
+	const nameWidth, cellWidth = 18, 5
+
+	var sb strings.Builder
+	sb.WriteString(styles.ModalTitle.Render("Conflict Matrix"))
+	switch {
+	case p.conflictsPredicting:
+		sb.WriteString(styles.Muted.Render("  predicting…"))
+	case !p.conflictsPredicted:
+		sb.WriteString(styles.Muted.Render("  dirty-path overlaps only"))
+	}
+	sb.WriteString("\n\n")
+
+	header := strings.Repeat(" ", nameWidth+3)
+	for i := range worktrees {
+		header += fmt.Sprintf("%*d", cellWidth, i+1)
+	}
+	header += fmt.Sprintf("%*s", cellWidth+1, "base")
+	sb.WriteString(styles.Muted.Render(header))
+	sb.WriteString("\n")
+
+	for r, wt := range worktrees {
+		name := wt.Name
+		if lipgloss.Width(name) > nameWidth {
+			name = p.truncateCache.Truncate(name, nameWidth-1, "") + "…"
+		}
+		line := fmt.Sprintf("%2d %-*s", r+1, nameWidth, name)
+		for c := 0; c <= len(worktrees); c++ {
+			cell := p.conflictMatrixCell(worktrees, r, c)
+			if c == len(worktrees) {
+				cell = " " + cell
+			}
+			line += cell
+		}
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(p.conflictMatrixDetail(worktrees))
+
+	modalWidth := min(max(nameWidth+3+cellWidth*(len(worktrees)+1)+8, 60), p.width-10)
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(styles.Primary).
+		Padding(1, 2).
+		Width(modalWidth)
+	return ui.OverlayModal(background, modalStyle.Render(sb.String()), p.width, p.height)
+}
+
+// conflictMatrixCell renders one cell: the number of conflicting regions
+// (files, for overlaps), "·" for a clean merge, "─" on the diagonal.
+func (p *Plugin) conflictMatrixCell(worktrees []*Worktree, r, c int) string {
+	const cellWidth = 5
+	text := "·"
+	var style lipgloss.Style
+	switch {
+	case c == r:
+		text, style = "─", styles.Muted
+	default:
+		if conflict := p.matrixConflict(worktrees, r, c); conflict != nil {
+			n := len(conflict.Hunks)
+			if !conflict.Predicted {
+				n = len(conflict.Files)
+			}
+			text, style = fmt.Sprintf("%d", n), styles.StatusModified
+		} else {
+			style = styles.Muted
+		}
+	}
+	text = fmt.Sprintf("%*s", cellWidth, text)
+	if r == p.conflictMatrixRow && c == p.conflictMatrixCol {
+		return styles.ListItemSelected.Render(text)
+	}
+	return style.Render(text)
+}
+
+// matrixConflict returns the conflict in cell (r, c), where c == len(worktrees)
+// is the target-branch column.
+func (p *Plugin) matrixConflict(worktrees []*Worktree, r, c int) *Conflict {
+	key := worktrees[r].IdentityKey()
+	if c == len(worktrees) {
+		return conflictBetween(p.conflicts, key, "")
+	}
+	if c == r {
+		return nil
+	}
+	return conflictBetween(p.conflicts, key, worktrees[c].IdentityKey())
+}
+
+// conflictMatrixDetail lists the conflicting regions of the selected cell.
+func (p *Plugin) conflictMatrixDetail(worktrees []*Worktree) string {
+	r, c := p.conflictMatrixRow, p.conflictMatrixCol
+	wt := worktrees[r]
+	var title string
+	switch {
+	case c == len(worktrees):
+		target := wt.BaseBranch
+		if target == "" {
+			target = "target branch"
+		}
+		title = wt.Name + " → " + target
+	case c == r:
+		return styles.Muted.Render("Select another worktree or the base column.")
+	default:
+		title = wt.Name + " ↔ " + worktrees[c].Name
+	}
+
+	// Snapshots leave untracked files out, so say whose are not predicted.
+	cell := []*Worktree{wt}
+	if c < len(worktrees) {
+		cell = append(cell, worktrees[c])
+	}
+	var untracked []string
+	for _, w := range cell {
+		if p.conflictsPredicted && p.conflictsUntracked[w.IdentityKey()] {
+			untracked = append(untracked, w.Name)
+		}
+	}
+	untrackedNote := ""
+	if len(untracked) > 0 {
+		untrackedNote = "\n" + styles.Muted.Render("Untracked files in "+strings.Join(untracked, " and ")+" are left out; the prediction is approximate for them.")
+	}
+
+	conflict := p.matrixConflict(worktrees, r, c)
+	if conflict == nil {
+		return title + "\n" + styles.Muted.Render("Merges cleanly") + untrackedNote
+	}
+	var lines []string
+	if !conflict.Predicted {
+		lines = append(lines, title+styles.Muted.Render("  (dirty in both)"))
+		for _, f := range conflict.Files {
+			lines = append(lines, "  "+f)
+		}
+	} else {
+		lines = append(lines, title)
+		for _, h := range conflict.Hunks {
+			if h.Line == 0 {
+				lines = append(lines, "  "+h.File+styles.Muted.Render("  whole file"))
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("  %s:%d", h.File, h.Line)+
+				styles.Muted.Render(fmt.Sprintf("  %d vs %d lines", h.Ours, h.Theirs)))
+		}
+		if first := p.findWorktree(conflict.Worktrees[0]); first != nil && c != len(worktrees) {
+			lines = append(lines, styles.Muted.Render("Line numbers are in "+first.Name+"."))
+		}
+	}
+	if len(lines) > maxMatrixDetailLines+1 {
+		more := len(lines) - maxMatrixDetailLines - 1
+		lines = append(lines[:maxMatrixDetailLines+1], styles.Muted.Render(fmt.Sprintf("  … %d more", more)))
+	}
+	return strings.Join(lines, "\n") + untrackedNote
+}
@@ -0,0 +1,314 @@
+package workspace
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/marcus/sidecar/internal/plugins/gitstatus"
+)
+
+// errMergeTreeUnsupported means Git predates `merge-tree --write-tree`
+// (2.38), so only path overlaps can be reported.
+var errMergeTreeUnsupported = errors.New("git merge-tree --write-tree is not available")
+
+// conflictTip is the commit a worktree would merge: HEAD, or a snapshot
+// commit of HEAD plus its uncommitted changes to tracked files when it is
+// dirty.
+type conflictTip struct {
+	Key       string
+	Name      string
+	Target    string // Base branch the worktree will merge into
+	Commit    string
+	Untracked bool // Has untracked files the snapshot leaves out
+}
+
+// predictConflictsCmd runs merge-tree for every pair of active worktrees and
+// for each worktree against its target branch. The result replaces the
+// path-overlap approximation computed during refresh.
+func (p *Plugin) predictConflictsCmd() tea.Cmd {
+	worktrees := conflictMatrixWorktrees(p.worktrees)
+	if len(worktrees) == 0 {
+		p.conflictsPredicting = false
+		return nil
+	}
+	type input struct{ key, name, path, base string }
+	inputs := make([]input, 0, len(worktrees))
+	for _, wt := range worktrees {
+		inputs = append(inputs, input{wt.IdentityKey(), wt.Name, wt.Path, wt.BaseBranch})
+	}
+	repoDir := p.ctx.ProjectRoot
+	if repoDir == "" {
+		repoDir = p.ctx.WorkDir
+	}
+	ctx, scope := p.newOperationScope(nil)
+	p.conflictOperationID = scope.OperationID
+	p.conflictsPredicting = true
+	return func() tea.Msg {
+		tips := make([]conflictTip, 0, len(inputs))
+		for _, in := range inputs {
+			commit, untracked, err := worktreeTipCommit(ctx, in.path)
+			if err != nil {
+				continue // Unreadable worktree; leave it out of the matrix
+			}
+			base := in.base
+			if base == "" {
+				base = detectDefaultBranchContext(ctx, in.path)
+			}
+			tips = append(tips, conflictTip{Key: in.key, Name: in.name, Target: base, Commit: commit, Untracked: untracked})
+		}
+		conflicts, err := predictConflicts(ctx, repoDir, tips)
+		var untracked []string
+		for _, tip := range tips {
+			if tip.Untracked {
+				untracked = append(untracked, tip.Key)
+			}
+		}
+		return ConflictsDetectedMsg{OperationScope: scope, Conflicts: conflicts, Predicted: err == nil, Untracked: untracked, Err: err}
+	}
+}
+
+// conflictMatrixWorktrees returns the worktrees conflicts are predicted
+// between: every linked worktree that exists on disk. The main worktree is
+// where work lands, so it is represented by each worktree's target branch.
+func conflictMatrixWorktrees(worktrees []*Worktree) []*Worktree {
+	var out []*Worktree
+	for _, wt := range worktrees {
+		if wt.IsMain || wt.IsMissing || wt.IsBare {
+			continue
+		}
+		out = append(out, wt)
+	}
+	return out
+}
+
+// conflictFingerprint summarizes the worktree state conflict prediction
+// depends on, so status refreshes that change nothing do not rerun it.
+func conflictFingerprint(worktrees []*Worktree) string {
+	var sb strings.Builder
+	for _, wt := range conflictMatrixWorktrees(worktrees) {
+		fmt.Fprintf(&sb, "%s@%s>%s", wt.IdentityKey(), wt.HEADOID, wt.BaseBranch)
+		if wt.Changes != nil {
+			sb.WriteString(":" + strings.Join(wt.Changes.Dirty, ","))
+		}
+		if wt.Stats != nil {
+			fmt.Fprintf(&sb, "+%d-%d", wt.Stats.Additions, wt.Stats.Deletions)
+		}
+		sb.WriteString(";")
+	}
+	return sb.String()
+}
+
+// worktreeTipCommit returns HEAD for a clean worktree. For a dirty one it
+// commits the changes to tracked files through a copy of the worktree's index,
+// so the agent working there never sees a lock or a change. Untracked files
+// are left out — build outputs and datasets would otherwise be written into
+// the object database on every status change — and reported instead, so the
+// prediction can say it is approximate for them. The snapshot commit is
+// unreferenced and left for gc.
+func worktreeTipCommit(ctx context.Context, dir string) (commit string, untracked bool, err error) {
+	head, err := gitOutputContext(ctx, dir, "rev-parse", "--verify", "HEAD^{commit}")
+	if err != nil {
+		return "", false, err
+	}
+	// No optional locks: status must not refresh the agent's index either.
+	status, err := gitOutputContext(ctx, dir, "--no-optional-locks", "status", "--porcelain", "--untracked-files=normal")
+	if err != nil {
+		return "", false, err
+	}
+	tracked := false
+	for _, line := range strings.Split(status, "\n") {
+		switch {
+		case strings.HasPrefix(line, "??"):
+			untracked = true
+		case line != "":
+			tracked = true
+		}
+	}
+	if !tracked {
+		return head, untracked, nil
+	}
+
+	indexPath, err := gitOutputContext(ctx, dir, "rev-parse", "--git-path", "index")
+	if err != nil {
+		return "", false, err
+	}
+	if !filepath.IsAbs(indexPath) {
+		indexPath = filepath.Join(dir, indexPath)
+	}
+	tmp, err := os.CreateTemp("", "sidecar-conflict-index-*")
+	if err != nil {
+		return "", false, err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+	if src, err := os.Open(indexPath); err == nil {
+		_, err = io.Copy(tmp, src)
+		src.Close()
+		if err != nil {
+			tmp.Close()
+			return "", false, err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return "", false, err
+	}
+
+	env := []string{
+		"GIT_INDEX_FILE=" + tmpPath,
+		"GIT_AUTHOR_NAME=sidecar", "GIT_AUTHOR_EMAIL=sidecar@localhost",
+		"GIT_COMMITTER_NAME=sidecar", "GIT_COMMITTER_EMAIL=sidecar@localhost",
+	}
+	if _, err := gitOutputEnvContext(ctx, dir, env, "add", "-u"); err != nil {
+		return "", false, err
+	}
+	tree, err := gitOutputEnvContext(ctx, dir, env, "write-tree")
+	if err != nil {
+		return "", false, err
+	}
+	commit, err = gitOutputEnvContext(ctx, dir, env, "commit-tree", tree, "-p", head, "-m", "sidecar conflict prediction snapshot")
+	return commit, untracked, err
+}
+
+// predictConflicts merges every pair of tips, and each tip into its target,
+// without touching any worktree. Pairs that merge cleanly produce nothing.
+func predictConflicts(ctx context.Context, repoDir string, tips []conflictTip) ([]Conflict, error) {
+	var result []Conflict
+	for i := 0; i < len(tips); i++ {
+		for j := i + 1; j < len(tips); j++ {
+			hunks, err := mergeTreeConflicts(ctx, repoDir, tips[i].Commit, tips[j].Commit)
+			if err != nil {
+				return nil, err
+			}
+			if len(hunks) > 0 {
+				result = append(result, newPredictedConflict([]string{tips[i].Key, tips[j].Key}, "", hunks))
+			}
+		}
+	}
+	for _, tip := range tips {
+		if tip.Target == "" {
+			continue
+		}
+		target, err := gitOutputContext(ctx, repoDir, "rev-parse", "--verify", "--quiet", tip.Target+"^{commit}")
+		if err != nil || target == "" {
+			continue // Target branch not present locally
+		}
+		hunks, err := mergeTreeConflicts(ctx, repoDir, tip.Commit, target)
+		if err != nil {
+			return nil, err
+		}
+		if len(hunks) > 0 {
+			result = append(result, newPredictedConflict([]string{tip.Key}, tip.Target, hunks))
+		}
+	}
+	return result, nil
+}
+
+func newPredictedConflict(keys []string, target string, hunks []ConflictHunk) Conflict {
+	c := Conflict{Worktrees: keys, Target: target, Hunks: hunks, Predicted: true}
+	seen := make(map[string]bool)
+	for _, h := range hunks {
+		if !seen[h.File] {
+			seen[h.File] = true
+			c.Files = append(c.Files, h.File)
+		}
+	}
+	return c
+}
+
+// mergeTreeConflicts runs `git merge-tree --write-tree` on two commits and
+// returns the conflicting regions, read back from the conflict markers in the
+// merged tree. A file that conflicts without markers (modify/delete, binary)
+// yields one hunk with Line 0.
+func mergeTreeConflicts(ctx context.Context, repoDir, ours, theirs string) ([]ConflictHunk, error) {
+	cmd := exec.CommandContext(ctx, "git", "merge-tree", "--write-tree", "--no-messages", "-z", ours, theirs)
+	cmd.Dir = repoDir
+	recordGitProcess(ctx, nil)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err == nil {
+		return nil, nil // Merges cleanly
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return nil, err
+	}
+	switch exitErr.ExitCode() {
+	case 1:
+		// Conflicts; parsed below.
+	case 129:
+		return nil, errMergeTreeUnsupported
+	default:
+		return nil, fmt.Errorf("git merge-tree: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	tree, paths := parseMergeTreeOutput(string(output))
+	var hunks []ConflictHunk
+	for _, path := range paths {
+		content, err := gitOutputRawContext(ctx, repoDir, "cat-file", "blob", tree+":"+path)
+		fileHunks := conflictHunks(path, content)
+		if err != nil || len(fileHunks) == 0 {
+			hunks = append(hunks, ConflictHunk{File: path})
+			continue
+		}
+		hunks = append(hunks, fileHunks...)
+	}
+	return hunks, nil
+}
+
+// parseMergeTreeOutput splits `merge-tree --write-tree -z` output into the
+// result tree and the conflicted paths, each once and sorted.
+func parseMergeTreeOutput(output string) (string, []string) {
+	fields := strings.Split(output, "\x00")
+	if len(fields) == 0 {
+		return "", nil
+	}
+	tree := strings.TrimSpace(fields[0])
+	seen := make(map[string]bool)
+	var paths []string
+	for _, f := range fields[1:] {
+		if f == "" {
+			break // End of the conflicted file info section
+		}
+		// "<mode> <object> <stage>\t<path>"
+		_, path, ok := strings.Cut(f, "\t")
+		if !ok || seen[path] {
+			continue
+		}
+		seen[path] = true
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return tree, paths
+}
+
+// conflictHunks locates each conflict in a merged file. Line numbers are in
+// the first side's version: merged lines outside a conflict and the first
+// side's lines inside one advance it, the other side's do not.
+func conflictHunks(path, content string) []ConflictHunk {
+	cf, err := gitstatus.ParseConflicts(content)
+	if err != nil {
+		return nil
+	}
+	var hunks []ConflictHunk
+	line := 0
+	for _, seg := range cf.Segments {
+		if seg.Hunk == nil {
+			line += len(seg.Lines)
+			continue
+		}
+		hunks = append(hunks, ConflictHunk{File: path, Line: line + 1, Ours: len(seg.Hunk.Ours), Theirs: len(seg.Hunk.Theirs)})
+		line += len(seg.Hunk.Ours)
+	}
+	return hunks
+}
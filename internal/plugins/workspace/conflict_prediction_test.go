@@ -0,0 +1,173 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+func TestConflictHunks(t *testing.T) {
+	content := strings.Join([]string{
+		"one",
+		"<<<<<<< ours",
+		"a1",
+		"a2",
+		"||||||| base",
+		"b",
+		"=======",
+		"t1",
+		">>>>>>> theirs",
+		"two",
+		"<<<<<<< ours",
+		"=======",
+		"t2",
+		"t3",
+		">>>>>>> theirs",
+	}, "\n")
+	got := conflictHunks("f.go", content)
+	want := []ConflictHunk{
+		{File: "f.go", Line: 2, Ours: 2, Theirs: 1},
+		{File: "f.go", Line: 5, Ours: 0, Theirs: 2},
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("hunks = %+v, want %+v", got, want)
+	}
+
+	tree, paths := parseMergeTreeOutput("abc123\x00100644 1111 2\tb.go\x00100644 2222 3\tb.go\x00100644 3333 2\ta.go\x00\x00")
+	if tree != "abc123" || strings.Join(paths, ",") != "a.go,b.go" {
+		t.Fatalf("tree = %q, paths = %v", tree, paths)
+	}
+}
+
+// predictionRepo returns a repository whose lib.go has two functions, with
+// worktrees "a" (a committed edit to one()) and "b" (an uncommitted edit to
+// two()) and "c" (an uncommitted edit to one() that disagrees with a).
+func predictionRepo(t *testing.T) (string, map[string]string) {
+	t.Helper()
+	for _, k := range []string{"GIT_CONFIG_GLOBAL", "GIT_CONFIG_SYSTEM"} {
+		t.Setenv(k, "/dev/null")
+	}
+	root := t.TempDir()
+	main := filepath.Join(root, "main")
+	mustGit(t, root, "init", "-q", "-b", "main", main)
+	mustGit(t, main, "config", "user.email", "test@example.com")
+	mustGit(t, main, "config", "user.name", "Test")
+	lib := "package lib\n\nfunc one() int {\n\treturn 1\n}\n\nfunc pad() {}\n\nfunc two() int {\n\treturn 2\n}\n"
+	mustWrite(t, filepath.Join(main, "lib.go"), lib)
+	mustGit(t, main, "add", ".")
+	mustGit(t, main, "commit", "-qm", "seed")
+
+	dirs := map[string]string{}
+	for _, name := range []string{"a", "b", "c"} {
+		dirs[name] = filepath.Join(root, name)
+		mustGit(t, main, "worktree", "add", "-q", "-b", name, dirs[name])
+	}
+	mustWrite(t, filepath.Join(dirs["a"], "lib.go"), strings.Replace(lib, "return 1", "return 10", 1))
+	mustGit(t, dirs["a"], "commit", "-qam", "one returns ten")
+	mustWrite(t, filepath.Join(dirs["b"], "lib.go"), strings.Replace(lib, "return 2", "return 20", 1))
+	mustWrite(t, filepath.Join(dirs["c"], "lib.go"), strings.Replace(lib, "return 1", "return 11", 1))
+
+	// main moves two() on as well, so b conflicts with its target.
+	mustWrite(t, filepath.Join(main, "lib.go"), strings.Replace(lib, "return 2", "return 22", 1))
+	mustGit(t, main, "commit", "-qam", "two returns twenty-two")
+	return main, dirs
+}
+
+func TestPredictConflictsUsesMergeTree(t *testing.T) {
+	main, dirs := predictionRepo(t)
+	ctx := context.Background()
+
+	// Untracked files stay out of the snapshot and the object database.
+	mustWrite(t, filepath.Join(dirs["c"], "build.out"), "artifact\n")
+
+	var tips []conflictTip
+	for _, name := range []string{"a", "b", "c"} {
+		commit, untracked, err := worktreeTipCommit(ctx, dirs[name])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if untracked != (name == "c") {
+			t.Fatalf("%s: untracked = %v", name, untracked)
+		}
+		tips = append(tips, conflictTip{Key: name, Name: name, Target: "main", Commit: commit, Untracked: untracked})
+	}
+	if got := mustGit(t, dirs["c"], "status", "--porcelain"); got != "M lib.go\n?? build.out" {
+		t.Fatalf("snapshotting must leave the worktree's index alone, status = %q", got)
+	}
+	if got := mustGit(t, dirs["c"], "ls-tree", "--name-only", tips[2].Commit); got != "lib.go" {
+		t.Fatalf("snapshot tree = %q, want the tracked file alone", got)
+	}
+
+	conflicts, err := predictConflicts(ctx, main, tips)
+	if errors.Is(err, errMergeTreeUnsupported) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c := conflictBetween(conflicts, "a", "b"); c != nil {
+		t.Fatalf("edits to different functions of one file must not conflict: %+v", c)
+	}
+	ac := conflictBetween(conflicts, "a", "c")
+	if ac == nil || !ac.Predicted || len(ac.Hunks) != 1 || ac.Hunks[0].File != "lib.go" || ac.Hunks[0].Line != 4 {
+		t.Fatalf("a and c both rewrite one(): %+v", ac)
+	}
+	if c := conflictBetween(conflicts, "b", ""); c == nil || c.Target != "main" {
+		t.Fatalf("b's uncommitted two() conflicts with main: %+v", conflicts)
+	}
+	if c := conflictBetween(conflicts, "a", ""); c != nil {
+		t.Fatalf("a merges cleanly into main: %+v", c)
+	}
+}
+
+func TestConflictMatrixShowsPredictions(t *testing.T) {
+	p := New()
+	p.ctx = &plugin.Context{Epoch: 1, WorkDir: t.TempDir()}
+	p.width, p.height = 120, 40
+	p.worktrees = []*Worktree{
+		{Key: "main", Name: "main", IsMain: true},
+		{Key: "a", Name: "alpha", BaseBranch: "main"},
+		{Key: "b", Name: "beta", BaseBranch: "main"},
+	}
+	p.conflictOperationID = "op"
+	msg := ConflictsDetectedMsg{
+		OperationScope: OperationScope{Epoch: 1, OperationID: "op"},
+		Predicted:      true,
+		Untracked:      []string{"b"},
+		Conflicts: []Conflict{
+			newPredictedConflict([]string{"a", "b"}, "", []ConflictHunk{{File: "lib.go", Line: 4, Ours: 1, Theirs: 1}}),
+		},
+	}
+	p.Update(msg)
+	if !p.conflictsPredicted || conflictHunkCount("a", p.conflicts) != 1 {
+		t.Fatalf("prediction was not applied: %+v", p.conflicts)
+	}
+	p.setOverlapConflicts(nil)
+	if len(p.conflicts) != 1 {
+		t.Fatal("a refresh's path overlaps must not replace predictions")
+	}
+
+	p.viewMode = ViewModeConflictMatrix
+	p.conflictMatrixReturnMode = ViewModeList
+	if p.FocusContext() != "workspace-conflicts" {
+		t.Fatalf("focus context = %s", p.FocusContext())
+	}
+	p.conflictMatrixRow, p.conflictMatrixCol = 0, 2
+	p.Update(tea.KeyPressMsg{Code: 'h', Text: "h"})
+	view := p.renderConflictMatrixModal("")
+	for _, want := range []string{"Conflict Matrix", "alpha ↔ beta", "lib.go:4", "1 vs 1 lines", "Untracked files in beta"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("matrix is missing %q:\n%s", want, view)
+		}
+	}
+	p.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if p.viewMode != ViewModeList {
+		t.Fatalf("esc should close the matrix, mode = %v", p.viewMode)
+	}
+}
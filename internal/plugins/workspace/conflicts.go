@@ -1,21 +1,45 @@
 package workspace
 
-// Conflict represents a file conflict between worktrees.
+import tea "charm.land/bubbletea/v2"
+
+// Conflict represents a file conflict between worktrees, or between one
+// worktree and its target branch.
 type Conflict struct {
-	Worktrees []string // Stable keys of worktrees with overlapping dirty files
-	Files     []string // List of conflicting files
+	Worktrees []string       // Stable keys of the worktrees involved
+	Target    string         // Target branch, when the conflict is with it (one worktree)
+	Files     []string       // List of conflicting files
+	Hunks     []ConflictHunk // Conflicting regions; nil for path overlaps
+	Predicted bool           // From merge-tree rather than dirty-path overlap
+}
+
+// ConflictHunk is one region both sides of a predicted merge changed.
+type ConflictHunk struct {
+	File   string
+	Line   int // 1-based line in the first side's version; 0 for whole-file conflicts
+	Ours   int // Lines on the first side
+	Theirs int // Lines on the second side
 }
 
 // ConflictsDetectedMsg signals that conflicts have been detected.
 type ConflictsDetectedMsg struct {
 	OperationScope
 	Conflicts []Conflict
+	Predicted bool // Conflicts come from merge-tree; false keeps the overlap fallback
+	// Untracked lists the worktrees whose untracked files the prediction
+	// left out.
+	Untracked []string
 	Err       error
 }
 
+// GetEpoch implements plugin.EpochMessage.
+func (m ConflictsDetectedMsg) GetEpoch() uint64 { return m.Epoch }
+
 // conflictCandidate identifies a worktree for conflict detection.
 type conflictCandidate struct{ Key, Name, Path string }
 
+// detectConflictsFromChanges flags worktrees whose dirty files overlap. It is
+// cheap enough to run on every refresh and stands in until merge-tree
+// prediction (conflict_prediction.go) reports, or when Git is too old for it.
 func detectConflictsFromChanges(worktrees []*Worktree) []Conflict {
 	candidates := make([]conflictCandidate, 0, len(worktrees))
 	filesByKey := make(map[string][]string, len(worktrees))
@@ -38,6 +62,25 @@ func detectConflictsFromChanges(worktrees []*Worktree) []Conflict {
 	return result
 }
 
+// setOverlapConflicts applies refresh's path overlaps unless merge-tree
+// predictions, which are strictly better, are already showing.
+func (p *Plugin) setOverlapConflicts(conflicts []Conflict) {
+	if !p.conflictsPredicted {
+		p.conflicts = conflicts
+	}
+}
+
+// maybePredictConflicts reruns merge-tree prediction when a worktree's status
+// has changed since the last run.
+func (p *Plugin) maybePredictConflicts() tea.Cmd {
+	fingerprint := conflictFingerprint(p.worktrees)
+	if fingerprint == p.conflictFingerprint {
+		return nil
+	}
+	p.conflictFingerprint = fingerprint
+	return p.predictConflictsCmd()
+}
+
 // intersection returns the common elements between two slices.
 func intersection(a, b []string) []string {
 	set := make(map[string]bool)
@@ -111,3 +154,33 @@ func (p *Plugin) getConflictingWorktrees(worktreeKey string, conflicts []Conflic
 	}
 	return others
 }
+
+// conflictBetween returns the conflict for a pair of worktrees, or for one
+// worktree against its target when other is "".
+func conflictBetween(conflicts []Conflict, key, other string) *Conflict {
+	for i := range conflicts {
+		c := &conflicts[i]
+		switch {
+		case other == "" && c.Target != "" && len(c.Worktrees) == 1 && c.Worktrees[0] == key:
+			return c
+		case other != "" && c.Target == "" && len(c.Worktrees) == 2 &&
+			(c.Worktrees[0] == key && c.Worktrees[1] == other || c.Worktrees[0] == other && c.Worktrees[1] == key):
+			return c
+		}
+	}
+	return nil
+}
+
+// conflictHunkCount returns how many conflicting regions involve a worktree.
+func conflictHunkCount(worktreeKey string, conflicts []Conflict) int {
+	n := 0
+	for _, c := range conflicts {
+		for _, wt := range c.Worktrees {
+			if wt == worktreeKey {
+				n += len(c.Hunks)
+				break
+			}
+		}
+	}
+	return n
+}
@@ -0,0 +1,172 @@
+package workspace
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/marcus/sidecar/internal/controlapi"
+	"github.com/marcus/sidecar/internal/features"
+)
+
+// publishControlAPI offers the control API this plugin's share of its topics:
+// the workspaces, the agents in them, and the content panes beside the
+// selected one. It is swept once per update, like lane transitions, and only
+// returns a command for a topic whose value changed since the last sweep.
+// User scripts read the workspaces topic too, so either feature turns it on.
+func (p *Plugin) publishControlAPI() tea.Cmd {
+	if p == nil || (!features.IsEnabled(features.ControlAPI.Name) && !features.IsEnabled(features.Scripting.Name)) {
+		return nil
+	}
+	var cmds []tea.Cmd
+	for _, topic := range []struct {
+		topic controlapi.Topic
+		data  any
+	}{
+		{controlapi.TopicWorkspaces, p.controlAPIWorkspaces()},
+		{controlapi.TopicAgents, p.controlAPIAgents()},
+		{controlapi.TopicPanes, p.controlAPIPanes()},
+	} {
+		data, err := json.Marshal(topic.data)
+		if err != nil || bytes.Equal(p.controlAPIPublished[topic.topic], data) {
+			continue
+		}
+		if p.controlAPIPublished == nil {
+			p.controlAPIPublished = make(map[controlapi.Topic][]byte)
+		}
+		p.controlAPIPublished[topic.topic] = data
+		msg := controlapi.PublishMsg{Topic: topic.topic, Data: topic.data}
+		cmds = append(cmds, func() tea.Msg { return msg })
+	}
+	return tea.Batch(cmds...)
+}
+
+func (p *Plugin) controlAPIWorkspaces() controlapi.WorkspacesState {
+	state := controlapi.WorkspacesState{Workspaces: []controlapi.Workspace{}}
+	if p.ctx != nil {
+		state.Root = p.ctx.ProjectRoot
+	}
+	for _, wt := range p.worktrees {
+		if wt == nil {
+			continue
+		}
+		ws := controlapi.Workspace{
+			Kind:     "worktree",
+			Name:     wt.Name,
+			Path:     wt.Path,
+			Branch:   wt.Branch,
+			Main:     wt.IsMain,
+			Missing:  wt.IsMissing,
+			Orphaned: wt.IsOrphaned,
+			PR:       wt.PRURL,
+		}
+		if wt.Agent != nil {
+			ws.Agent = string(wt.Agent.Type)
+			ws.Session = wt.Agent.TmuxSession
+		}
+		if wt.Stats != nil {
+			ws.Additions, ws.Deletions = wt.Stats.Additions, wt.Stats.Deletions
+			ws.Ahead, ws.Behind = wt.Stats.Ahead, wt.Stats.Behind
+		}
+		state.Workspaces = append(state.Workspaces, ws)
+	}
+	for _, shell := range p.controlAPIShells() {
+		ws := controlapi.Workspace{
+			Kind:     "shell",
+			Name:     shell.Name,
+			Path:     shell.WorkDir,
+			Session:  shell.TmuxName,
+			Orphaned: shell.IsOrphaned,
+		}
+		if shell.Agent != nil {
+			ws.Agent = string(shell.Agent.Type)
+		}
+		state.Workspaces = append(state.Workspaces, ws)
+	}
+	return state
+}
+
+// controlAPIShells is every shell the plugin knows: the top section first,
+// then the nested ones by parent worktree.
+func (p *Plugin) controlAPIShells() []*ShellSession {
+	shells := make([]*ShellSession, 0, len(p.shells))
+	for _, shell := range p.shells {
+		if shell != nil {
+			shells = append(shells, shell)
+		}
+	}
+	parents := make([]string, 0, len(p.nestedByWorkDir))
+	for parent := range p.nestedByWorkDir {
+		parents = append(parents, parent)
+	}
+	sort.Strings(parents)
+	for _, parent := range parents {
+		for _, shell := range p.nestedByWorkDir[parent] {
+			if shell != nil {
+				shells = append(shells, shell)
+			}
+		}
+	}
+	return shells
+}
+
+func (p *Plugin) controlAPIAgents() controlapi.AgentsState {
+	state := controlapi.AgentsState{Agents: []controlapi.AgentStatus{}}
+	for _, wt := range p.worktrees {
+		if wt == nil || wt.Agent == nil {
+			continue
+		}
+		status := agentStatusPresentation(wt)
+		state.Agents = append(state.Agents, controlapi.AgentStatus{
+			Workspace: wt.Name,
+			Kind:      "worktree",
+			Session:   wt.Agent.TmuxSession,
+			Agent:     string(wt.Agent.Type),
+			Lane:      string(status.Lane),
+			Label:     status.Label,
+			Attention: status.Attention,
+			Since:     sinceOf(status.ChangedAt),
+		})
+	}
+	for _, shell := range p.controlAPIShells() {
+		if shell.Agent == nil {
+			continue
+		}
+		status := shellAgentStatusPresentation(shell)
+		state.Agents = append(state.Agents, controlapi.AgentStatus{
+			Workspace: shell.Name,
+			Kind:      "shell",
+			Session:   shell.TmuxName,
+			Agent:     string(shell.Agent.Type),
+			Lane:      string(status.Lane),
+			Label:     status.Label,
+			Attention: status.Attention,
+			Since:     sinceOf(status.ChangedAt),
+		})
+	}
+	return state
+}
+
+// controlAPIPanes is the panes beside the selected workspace. The plugin has
+// one deck, and it follows the selection.
+func (p *Plugin) controlAPIPanes() []controlapi.PaneSurface {
+	surfaces := []controlapi.PaneSurface{}
+	root, identity, ok := p.selectedTerminalSurface()
+	if !ok || p.contentDeck == nil {
+		return surfaces
+	}
+	if surface, open := controlapi.NewPaneSurface(identity, root, p.contentDeck.Encode()); open {
+		surfaces = append(surfaces, surface)
+	}
+	return surfaces
+}
+
+func sinceOf(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
@@ -89,6 +89,9 @@ func (p *Plugin) ensureCreateOperationModal() {
 		"Branch: " + plan.Branch,
 		"Remote: " + plan.RemotePolicy,
 	}
+	if plan.Sparse != nil {
+		lines = append(lines, "Sparse checkout: "+plan.Sparse.Describe())
+	}
 	p.createOperationModal = modal.New("Confirm Worktree Creation", modal.WithWidth(modalW), modal.WithPrimaryAction(createConfirmID), modal.WithHints(false)).
 		AddSection(modal.Text(strings.Join(lines, "\n"))).
 		AddSection(modal.Spacer())
@@ -37,6 +37,7 @@ type CreateOperationPlan struct {
 	RunHook        bool
 	HookPath       string
 	HookRequired   bool
+	Sparse         *workspaceops.SparseCheckout
 }
 
 type pendingCreationJournal struct {
@@ -187,6 +188,7 @@ const (
 	CreateOutcomeAgent    CreateOutcomeKind = "agent-metadata"
 	CreateOutcomeEnv      CreateOutcomeKind = "env-copy"
 	CreateOutcomeHook     CreateOutcomeKind = "setup-hook"
+	CreateOutcomeSparse   CreateOutcomeKind = "sparse-checkout"
 )
 
 // CreateSetupOutcome records one independently recoverable setup action.
@@ -247,6 +249,7 @@ func createPlanFromShared(plan *workspaceops.WorktreePlan) *CreateOperationPlan
 		TaskID: plan.TaskID, TaskTitle: plan.TaskTitle, AgentType: AgentType(plan.AgentType), SkipPerms: plan.SkipPerms,
 		CopyEnv: plan.CopyEnv, EnvFiles: append([]string(nil), plan.EnvFiles...),
 		RunHook: plan.RunHook, HookPath: plan.HookPath, HookRequired: plan.HookRequired,
+		Sparse: plan.Sparse,
 	}
 }
 
@@ -262,6 +265,7 @@ func sharedCreatePlan(plan *CreateOperationPlan) *workspaceops.WorktreePlan {
 		TaskID: plan.TaskID, TaskTitle: plan.TaskTitle, AgentType: string(plan.AgentType), SkipPerms: plan.SkipPerms,
 		CopyEnv: plan.CopyEnv, EnvFiles: append([]string(nil), plan.EnvFiles...),
 		RunHook: plan.RunHook, HookPath: plan.HookPath, HookRequired: plan.HookRequired,
+		Sparse: plan.Sparse,
 	}
 }
 
@@ -216,6 +216,11 @@ func (p *Plugin) paintDiffFileFor(view *workspacediff.View, name, raw string, mo
 	if (err != nil || parsed == nil) && mode != workspacediff.ViewFullFile {
 		return ""
 	}
+	if parsed != nil && view != nil && view.Target.Kind == workspacediff.TargetWorkingTree {
+		// Review threads are anchored to the branch's own lines, which a
+		// commit or range tab does not show.
+		parsed.Notes = p.reviewNotes(view.WorkDir, name)
+	}
 	highlighter := gitstatus.NewSyntaxHighlighter(name)
 	switch mode {
 	case workspacediff.ViewFullFile:
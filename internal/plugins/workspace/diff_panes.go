@@ -428,7 +428,10 @@ func (p *Plugin) loadDiffView(view *workspacediff.View, root, surface string) te
 		view.Error = "range tab has no revisions"
 		return nil
 	default:
-		return workspacediff.LoadSnapshotCmdAt(root, p.selectedDiffBaseRef(), workspaceID, p.ctx.Epoch, view.Target.Identity())
+		return tea.Batch(
+			workspacediff.LoadSnapshotCmdAt(root, p.selectedDiffBaseRef(), workspaceID, p.ctx.Epoch, view.Target.Identity()),
+			p.maybeFetchReviews(p.worktreeAt(root)),
+		)
 	}
 }
 
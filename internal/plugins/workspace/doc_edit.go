@@ -5,7 +5,6 @@ import (
 	"time"
 
 	tea "charm.land/bubbletea/v2"
-	"github.com/marcus/sidecar/internal/features"
 	"github.com/marcus/sidecar/internal/inlineedit"
 	"github.com/marcus/sidecar/internal/mouse"
 	appmsg "github.com/marcus/sidecar/internal/msg"
@@ -165,8 +164,8 @@ func (p *Plugin) enterDocEdit(doc *docPane) tea.Cmd {
 	if doc == nil || doc.editing() {
 		return nil
 	}
-	if !features.IsEnabled(features.TmuxInlineEdit.Name) {
-		return appmsg.ShowToast("Inline edit is disabled (features.tmux-inline-edit)", 3*time.Second)
+	if !inlineedit.Enabled() {
+		return appmsg.ShowToast(inlineedit.DisabledMessage, 3*time.Second)
 	}
 	abs, rel, ok := doc.editorPath()
 	if !ok {
@@ -185,7 +184,7 @@ func (p *Plugin) enterDocEdit(doc *docPane) tea.Cmd {
 	if p.ctx != nil {
 		epoch = p.ctx.Epoch
 	}
-	return inlineedit.Start(inlineedit.StartOptions{
+	return inlineedit.StartPreferred(inlineedit.StartOptions{
 		Surface:    docEditSurface,
 		LeafID:     doc.leafID,
 		AbsPath:    abs,
@@ -229,7 +228,7 @@ func (p *Plugin) applyDocEditStarted(msg inlineedit.StartedMsg) tea.Cmd {
 	}
 	p.activePane = PanePreview
 	p.focusLeaf(doc.leafID)
-	return session.Begin(msg.SessionName, msg.Editor, msg.Path)
+	return session.BeginStarted(msg)
 }
 
 // applyDocEditExited tears the leaf's editor down and re-reads the file it was
@@ -451,11 +450,7 @@ func (p *Plugin) renderDocEditBody(doc *docPane, width, height int) string {
 	if session.ShowExitConfirm {
 		return ui.FitBlock(session.RenderExitConfirm(), width, height)
 	}
-	body := ""
-	if session.Model != nil {
-		body = session.Model.View()
-	}
-	return ui.FitBlock(body, width, height)
+	return ui.FitBlock(session.View(), width, height)
 }
 
 // docEditCursor is the focused editor's native cursor in plugin-local
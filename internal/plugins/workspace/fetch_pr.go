@@ -1,9 +1,7 @@
 package workspace
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"os/exec"
@@ -12,44 +10,28 @@ import (
 	"time"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/marcus/sidecar/internal/forge"
 	"github.com/marcus/sidecar/internal/projectdir"
 )
 
-// fetchPRList runs gh pr list and returns open PRs.
+// fetchPRList lists the open pull requests of the base branch's repository.
 func (p *Plugin) fetchPRList() tea.Cmd {
 	workDir := p.ctx.WorkDir
 	ctx, scope := p.newLifecycleScope(nil)
 	return func() tea.Msg {
 		baseBranch := detectDefaultBranchContext(ctx, workDir)
-		_, repository, err := resolveBaseTopologyContext(ctx, workDir, baseBranch)
+		_, repo, err := resolveBaseTopologyContext(ctx, workDir, baseBranch)
 		if err != nil {
 			return FetchPRListMsg{OperationScope: scope, Err: err}
 		}
-		cmd := exec.CommandContext(ctx, "gh", "pr", "list",
-			"--repo", repository,
-			"--json", "number,id,title,headRefName,headRefOid,baseRefName,headRepository,headRepositoryOwner,url,isDraft,createdAt,author",
-			"--limit", "30",
-		)
-		cmd.Dir = workDir
-		var stderr bytes.Buffer
-		cmd.Stderr = &stderr
-		output, err := cmd.Output()
+		mrs, err := forge.New(repo, workDir).ListOpen(ctx, 30)
 		if err != nil {
-			errMsg := strings.TrimSpace(stderr.String())
-			if errMsg == "" {
-				errMsg = err.Error()
-			}
-			return FetchPRListMsg{OperationScope: scope, Err: fmt.Errorf("gh pr list: %s", errMsg)}
-		}
-
-		var prs []PRListItem
-		if err := json.Unmarshal(output, &prs); err != nil {
-			return FetchPRListMsg{OperationScope: scope, Err: fmt.Errorf("parse pr list: %w", err)}
+			return FetchPRListMsg{OperationScope: scope, Err: err}
 		}
-		for i := range prs {
-			prs[i].Repository = repository
+		prs := make([]PRListItem, 0, len(mrs))
+		for _, mr := range mrs {
+			prs = append(prs, prListItemFromMergeRequest(mr, repo))
 		}
-
 		return FetchPRListMsg{OperationScope: scope, PRs: prs}
 	}
 }
@@ -69,7 +51,8 @@ func (p *Plugin) fetchAndCreateWorktree(pr PRListItem) tea.Cmd {
 		if pr.Number <= 0 || pr.Repository == "" || pr.Branch == "" || pr.BaseBranch == "" || pr.HeadOID == "" {
 			return FetchPRDoneMsg{OperationScope: scope, Err: fmt.Errorf("pull request identity is incomplete; refresh the PR list and retry")}
 		}
-		baseRemote, err := resolveRemoteForRepositoryContext(ctx, workDir, pr.Repository)
+		repo := pr.identity().forgeRepo()
+		baseRemote, err := resolveRemoteForRepositoryContext(ctx, workDir, repo)
 		if err != nil {
 			return FetchPRDoneMsg{OperationScope: scope, Err: err}
 		}
@@ -79,7 +62,7 @@ func (p *Plugin) fetchAndCreateWorktree(pr PRListItem) tea.Cmd {
 		}
 		tempRef := tempPrefix + "/head"
 		tempBaseRef := tempPrefix + "/base"
-		refspec := fmt.Sprintf("+refs/pull/%d/head:%s", pr.Number, tempRef)
+		refspec := fmt.Sprintf("+%s:%s", repo.HeadRef(pr.Number), tempRef)
 		baseRefspec := fmt.Sprintf("+refs/heads/%s:%s", pr.BaseBranch, tempBaseRef)
 		fetchCmd := exec.CommandContext(ctx, "git", "fetch", baseRemote, refspec, baseRefspec)
 		fetchCmd.Dir = workDir
@@ -90,7 +73,7 @@ func (p *Plugin) fetchAndCreateWorktree(pr PRListItem) tea.Cmd {
 		defer deleteTemporaryPRRef(workDir, tempBaseRef)
 		fetchedOID, err := gitOutputContext(ctx, workDir, "rev-parse", tempRef)
 		if err != nil || fetchedOID != pr.HeadOID {
-			return FetchPRDoneMsg{OperationScope: scope, Err: fmt.Errorf("fetched PR head changed: GitHub reported %s, fetched %s", pr.HeadOID, fetchedOID)}
+			return FetchPRDoneMsg{OperationScope: scope, Err: fmt.Errorf("fetched PR head changed: %s reported %s, fetched %s", repo.Kind.Title(), pr.HeadOID, fetchedOID)}
 		}
 		if _, err := gitOutputContext(ctx, workDir, "rev-parse", tempBaseRef); err != nil {
 			return FetchPRDoneMsg{OperationScope: scope, Err: fmt.Errorf("pull request base %q is not present in %s: %w", pr.BaseBranch, baseRemote, err)}
@@ -225,7 +208,7 @@ func (p *Plugin) filteredFetchPRItems() []PRListItem {
 	for _, pr := range p.fetchPRItems {
 		if strings.Contains(strings.ToLower(pr.Title), query) ||
 			strings.Contains(strings.ToLower(pr.Branch), query) ||
-			strings.Contains(strings.ToLower(pr.Author.Login), query) ||
+			strings.Contains(strings.ToLower(pr.Author), query) ||
 			strings.Contains(fmt.Sprintf("#%d", pr.Number), query) {
 			matches = append(matches, pr)
 		}
@@ -106,7 +106,7 @@ func (p *Plugin) fetchPRContentSection() modal.Section {
 				// Format: #42  fix-auth-flow  @claude  2h ago  [draft]
 				num := fmt.Sprintf("#%d", pr.Number)
 				branch := pr.Branch
-				author := "@" + pr.Author.Login
+				author := "@" + pr.Author
 				age := formatPRAge(pr.CreatedAt)
 
 				// Truncate branch if needed
@@ -171,7 +171,7 @@ func (p *Plugin) renderFetchPRModal(width, height int) string {
 	return ui.OverlayModal(background, modalContent, width, height)
 }
 
-// formatPRAge parses a GitHub timestamp and returns relative age.
+// formatPRAge parses a forge timestamp and returns relative age.
 func formatPRAge(createdAt string) string {
 	t, err := time.Parse(time.RFC3339, createdAt)
 	if err != nil {
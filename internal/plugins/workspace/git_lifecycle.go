@@ -5,7 +5,6 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
@@ -85,335 +84,67 @@ func readGitWorktreesContext(ctx context.Context, repoPath string) ([]gitWorktre
 	return result, scanner.Err()
 }
 
-func cloneDirectMergeOperation(op *DirectMergeOperation) *DirectMergeOperation {
-	if op == nil {
-		return nil
-	}
-	clone := *op
-	clone.Completed = append([]string(nil), op.Completed...)
-	return &clone
-}
-
-// DirectMergeRecovery describes the safe actions available after a failed merge.
-type DirectMergeRecovery string
+// The direct merge itself is workspaceops', shared with `sidecar workspace
+// merge`; these names keep the workflow reading as it always has.
+type (
+	DirectMergeRecovery  = workspaceops.DirectMergeRecovery
+	DirectMergeOperation = workspaceops.DirectMergeOperation
+)
 
 const (
-	DirectMergeRecoveryNone        DirectMergeRecovery = ""
-	DirectMergeRecoveryConflict    DirectMergeRecovery = "conflict"
-	DirectMergeRecoveryPushFailure DirectMergeRecovery = "push-failure"
+	DirectMergeRecoveryNone        = workspaceops.DirectMergeRecoveryNone
+	DirectMergeRecoveryConflict    = workspaceops.DirectMergeRecoveryConflict
+	DirectMergeRecoveryPushFailure = workspaceops.DirectMergeRecoveryPushFailure
 )
 
-// DirectMergeOperation is the immutable context and accumulated result of a merge.
-type DirectMergeOperation struct {
-	runContext   context.Context
-	SourcePath   string
-	TargetPath   string
-	SourceBranch string
-	TargetBranch string
-	Remote       string
-	SourceOID    string
-	TargetOID    string // target HEAD captured during visible preflight
-	PreMergeOID  string // target HEAD immediately before merge, after ff-only update
-	MergeOID     string
-	Completed    []string
-	Recovery     DirectMergeRecovery
-	GitState     string
-	Err          error
-	Aborted      bool
-}
+func cloneDirectMergeOperation(op *DirectMergeOperation) *DirectMergeOperation { return op.Clone() }
 
 func preflightDirectMerge(repoPath, sourcePath, sourceBranch, targetBranch string) (*DirectMergeOperation, error) {
 	return preflightDirectMergeContext(context.Background(), repoPath, sourcePath, sourceBranch, targetBranch)
 }
 
 func preflightDirectMergeContext(ctx context.Context, repoPath, sourcePath, sourceBranch, targetBranch string) (*DirectMergeOperation, error) {
-	worktrees, err := readGitWorktreesContext(ctx, repoPath)
-	if err != nil {
-		return nil, fmt.Errorf("inventory worktrees: %w", err)
-	}
-	sourcePath = canonicalGitPath(sourcePath)
-	var source, target *gitWorktreeState
-	for i := range worktrees {
-		wt := &worktrees[i]
-		if wt.Path == sourcePath {
-			source = wt
-		}
-		if wt.Branch == targetBranch {
-			if target != nil {
-				return nil, fmt.Errorf("target branch %q is checked out more than once", targetBranch)
-			}
-			target = wt
-		}
-	}
-	if source == nil {
-		return nil, fmt.Errorf("source worktree %q is not registered", sourcePath)
-	}
-	if source.Branch != sourceBranch || source.Detached || source.Bare || source.Locked || source.Prunable {
-		return nil, fmt.Errorf("source worktree is not a safe checkout of %q", sourceBranch)
-	}
-	if target == nil {
-		return nil, fmt.Errorf("target branch %q must be checked out in a worktree", targetBranch)
-	}
-	if target.Path == source.Path {
-		return nil, fmt.Errorf("source and target resolve to the same worktree")
-	}
-	if target.Detached || target.Bare || target.Locked || target.Prunable {
-		return nil, fmt.Errorf("target worktree is not safe to update")
-	}
-	for _, checkout := range []*gitWorktreeState{source, target} {
-		if err := requireCleanContext(ctx, checkout.Path); err != nil {
-			return nil, err
-		}
-		if state := gitOperationStateContext(ctx, checkout.Path); state != "clean" {
-			return nil, fmt.Errorf("worktree %q has a Git operation in progress: %s", checkout.Path, state)
-		}
-	}
-	sourceOID, err := gitOutputContext(ctx, source.Path, "rev-parse", "HEAD")
-	if err != nil {
-		return nil, fmt.Errorf("resolve source HEAD: %w", err)
-	}
-	sourceRefOID, err := gitOutputContext(ctx, repoPath, "rev-parse", "refs/heads/"+sourceBranch)
-	if err != nil || sourceRefOID != sourceOID {
-		return nil, fmt.Errorf("source branch moved or does not match its checkout")
-	}
-	targetOID, err := gitOutputContext(ctx, target.Path, "rev-parse", "HEAD")
-	if err != nil {
-		return nil, fmt.Errorf("resolve target HEAD: %w", err)
-	}
-	targetRefOID, err := gitOutputContext(ctx, repoPath, "rev-parse", "refs/heads/"+targetBranch)
-	if err != nil || targetRefOID != targetOID {
-		return nil, fmt.Errorf("target branch moved or does not match its checkout")
-	}
-	remote, err := resolveBranchRemoteContext(ctx, repoPath, targetBranch)
-	if err != nil {
-		return nil, err
-	}
-	if _, err := gitOutputContext(ctx, repoPath, "ls-remote", "--exit-code", "--heads", remote, "refs/heads/"+targetBranch); err != nil {
-		return nil, fmt.Errorf("remote %q does not expose target branch %q: %w", remote, targetBranch, err)
-	}
-	return &DirectMergeOperation{
-		SourcePath: source.Path, TargetPath: target.Path,
-		SourceBranch: sourceBranch, TargetBranch: targetBranch, Remote: remote,
-		SourceOID: sourceOID, TargetOID: targetOID,
-		Completed: []string{"preflight"},
-	}, nil
+	return workspaceops.PreflightDirectMerge(ctx, repoPath, sourcePath, sourceBranch, targetBranch)
 }
 
 func runDirectMerge(op *DirectMergeOperation) *DirectMergeOperation {
-	return runDirectMergeWithHooks(context.Background(), op, nil, nil)
+	return workspaceops.RunDirectMerge(context.Background(), op)
 }
 
 func runDirectMergeContext(ctx context.Context, op *DirectMergeOperation) *DirectMergeOperation {
-	return runDirectMergeWithHooks(ctx, op, nil, nil)
+	return workspaceops.RunDirectMerge(ctx, op)
 }
 
 func runDirectMergeWithBeforeMerge(op *DirectMergeOperation, beforeMerge func()) *DirectMergeOperation {
-	return runDirectMergeWithHooks(context.Background(), op, nil, beforeMerge)
+	return workspaceops.RunDirectMergeWithHooks(context.Background(), op, nil, beforeMerge)
 }
 
 func runDirectMergeWithBeforePull(op *DirectMergeOperation, beforePull func()) *DirectMergeOperation {
-	return runDirectMergeWithHooks(context.Background(), op, beforePull, nil)
-}
-
-func runDirectMergeWithHooks(ctx context.Context, op *DirectMergeOperation, beforePull, beforeMerge func()) *DirectMergeOperation {
-	if op == nil {
-		return &DirectMergeOperation{Err: fmt.Errorf("missing direct merge operation")}
-	}
-	op.runContext = ctx
-	if err := revalidateDirectMergeContext(ctx, op); err != nil {
-		return failDirectMerge(op, err, DirectMergeRecoveryNone)
-	}
-	if _, err := gitOutputContext(ctx, op.TargetPath, "fetch", op.Remote, op.TargetBranch); err != nil {
-		return failDirectMerge(op, fmt.Errorf("fetch %s: %w", op.Remote, err), DirectMergeRecoveryNone)
-	}
-	op.Completed = append(op.Completed, "fetch")
-	if beforePull != nil {
-		beforePull()
-	}
-	if err := requireCleanContext(ctx, op.TargetPath); err != nil {
-		return failDirectMerge(op, fmt.Errorf("target changed before fast-forward: %w", err), DirectMergeRecoveryNone)
-	}
-	if state := gitOperationStateContext(ctx, op.TargetPath); state != "clean" {
-		return failDirectMerge(op, fmt.Errorf("target started a Git operation before fast-forward: %s", state), DirectMergeRecoveryNone)
-	}
-	if err := requireCheckoutIdentityContext(ctx, op.TargetPath, op.TargetBranch, op.TargetOID); err != nil {
-		return failDirectMerge(op, fmt.Errorf("target checkout changed before fast-forward: %w", err), DirectMergeRecoveryNone)
-	}
-	if _, err := gitOutputContext(ctx, op.TargetPath, "pull", "--ff-only", op.Remote, op.TargetBranch); err != nil {
-		return failDirectMerge(op, fmt.Errorf("fast-forward target: %w", err), DirectMergeRecoveryNone)
-	}
-	op.Completed = append(op.Completed, "fast-forward target")
-	postPullOID, err := gitOutputContext(ctx, op.TargetPath, "rev-parse", "HEAD")
-	if err != nil {
-		return failDirectMerge(op, fmt.Errorf("pin post-pull target HEAD: %w", err), DirectMergeRecoveryNone)
-	}
-	if postPullOID == "" {
-		return failDirectMerge(op, fmt.Errorf("pin post-pull target HEAD: empty OID"), DirectMergeRecoveryNone)
-	}
-	op.PreMergeOID = postPullOID
-	if err := requireCleanContext(ctx, op.TargetPath); err != nil {
-		return failDirectMerge(op, err, DirectMergeRecoveryNone)
-	}
-	if beforeMerge != nil {
-		beforeMerge()
-	}
-	if err := requireCleanContext(ctx, op.SourcePath); err != nil {
-		return failDirectMerge(op, fmt.Errorf("source changed after review: %w", err), DirectMergeRecoveryNone)
-	}
-	if state := gitOperationStateContext(ctx, op.SourcePath); state != "clean" {
-		return failDirectMerge(op, fmt.Errorf("source started a Git operation after review: %s", state), DirectMergeRecoveryNone)
-	}
-	if err := requireCheckoutIdentityContext(ctx, op.SourcePath, op.SourceBranch, op.SourceOID); err != nil {
-		return failDirectMerge(op, fmt.Errorf("source checkout changed before merge: %w", err), DirectMergeRecoveryNone)
-	}
-	if err := requireCheckoutIdentityContext(ctx, op.TargetPath, op.TargetBranch, op.PreMergeOID); err != nil {
-		return failDirectMerge(op, fmt.Errorf("target checkout changed before merge: %w", err), DirectMergeRecoveryNone)
-	}
-	message := fmt.Sprintf("Merge branch '%s'", op.SourceBranch)
-	if _, err := gitOutputContext(ctx, op.TargetPath, "merge", "--no-ff", op.SourceOID, "-m", message); err != nil {
-		if gitOperationStateContext(ctx, op.TargetPath) == "merge" {
-			return failDirectMerge(op, fmt.Errorf("merge conflict: %w", err), DirectMergeRecoveryConflict)
-		}
-		return failDirectMerge(op, fmt.Errorf("merge: %w", err), DirectMergeRecoveryNone)
-	}
-	op.Completed = append(op.Completed, "merge")
-	op.MergeOID, _ = gitOutputContext(ctx, op.TargetPath, "rev-parse", "HEAD")
-	return pushDirectMergeContext(ctx, op)
+	return workspaceops.RunDirectMergeWithHooks(context.Background(), op, beforePull, nil)
 }
 
 func continueDirectMerge(op *DirectMergeOperation) *DirectMergeOperation {
-	return continueDirectMergeContext(context.Background(), op)
+	return workspaceops.ContinueDirectMerge(context.Background(), op)
 }
 
 func continueDirectMergeContext(ctx context.Context, op *DirectMergeOperation) *DirectMergeOperation {
-	if op == nil || op.Recovery != DirectMergeRecoveryConflict {
-		return failDirectMerge(op, fmt.Errorf("no conflicted merge is available to continue"), DirectMergeRecoveryNone)
-	}
-	op.runContext = ctx
-	if err := revalidateConflictRecoveryContext(ctx, op); err != nil {
-		return failDirectMerge(op, err, DirectMergeRecoveryConflict)
-	}
-	if unmerged, _ := gitOutputContext(ctx, op.TargetPath, "diff", "--name-only", "--diff-filter=U"); unmerged != "" {
-		return failDirectMerge(op, fmt.Errorf("resolve all conflicts before continuing: %s", strings.ReplaceAll(unmerged, "\n", ", ")), DirectMergeRecoveryConflict)
-	}
-	cmd := exec.CommandContext(ctx, "git", "-c", "core.editor=true", "merge", "--continue")
-	cmd.Dir = op.TargetPath
-	if out, err := cmd.CombinedOutput(); err != nil {
-		return failDirectMerge(op, fmt.Errorf("continue merge: %s: %w", strings.TrimSpace(string(out)), err), DirectMergeRecoveryConflict)
-	}
-	op.Completed = appendUnique(op.Completed, "merge")
-	op.MergeOID, _ = gitOutputContext(ctx, op.TargetPath, "rev-parse", "HEAD")
-	op.Recovery, op.Err = DirectMergeRecoveryNone, nil
-	return pushDirectMergeContext(ctx, op)
+	return workspaceops.ContinueDirectMerge(ctx, op)
 }
 
 func abortDirectMerge(op *DirectMergeOperation) *DirectMergeOperation {
-	return abortDirectMergeContext(context.Background(), op)
+	return workspaceops.AbortDirectMerge(context.Background(), op)
 }
 
 func abortDirectMergeContext(ctx context.Context, op *DirectMergeOperation) *DirectMergeOperation {
-	if op == nil || op.Recovery != DirectMergeRecoveryConflict {
-		return failDirectMerge(op, fmt.Errorf("no conflicted merge is available to abort"), DirectMergeRecoveryNone)
-	}
-	op.runContext = ctx
-	if err := revalidateConflictRecoveryContext(ctx, op); err != nil {
-		return failDirectMerge(op, err, DirectMergeRecoveryConflict)
-	}
-	if _, err := gitOutputContext(ctx, op.TargetPath, "merge", "--abort"); err != nil {
-		return failDirectMerge(op, fmt.Errorf("abort merge: %w", err), DirectMergeRecoveryConflict)
-	}
-	head, _ := gitOutputContext(ctx, op.TargetPath, "rev-parse", "HEAD")
-	if head != op.PreMergeOID {
-		return failDirectMerge(op, fmt.Errorf("merge aborted but target HEAD is %s, expected %s", head, op.PreMergeOID), DirectMergeRecoveryNone)
-	}
-	op.Recovery, op.Err = DirectMergeRecoveryNone, nil
-	op.Aborted = true
-	op.GitState = currentGitStateContext(ctx, op.TargetPath)
-	return op
+	return workspaceops.AbortDirectMerge(ctx, op)
 }
 
 func retryDirectMergePush(op *DirectMergeOperation) *DirectMergeOperation {
-	return retryDirectMergePushContext(context.Background(), op)
+	return workspaceops.RetryDirectMergePush(context.Background(), op)
 }
 
 func retryDirectMergePushContext(ctx context.Context, op *DirectMergeOperation) *DirectMergeOperation {
-	if op == nil || op.Recovery != DirectMergeRecoveryPushFailure {
-		return failDirectMerge(op, fmt.Errorf("no failed push is available to retry"), DirectMergeRecoveryNone)
-	}
-	op.runContext = ctx
-	if state := gitOperationStateContext(ctx, op.TargetPath); state != "clean" {
-		return failDirectMerge(op, fmt.Errorf("cannot retry push during Git operation: %s", state), DirectMergeRecoveryPushFailure)
-	}
-	if err := requireCleanContext(ctx, op.TargetPath); err != nil {
-		return failDirectMerge(op, err, DirectMergeRecoveryPushFailure)
-	}
-	if err := requireCheckoutIdentityContext(ctx, op.TargetPath, op.TargetBranch, op.MergeOID); err != nil {
-		return failDirectMerge(op, fmt.Errorf("target checkout changed since the failed push: %w", err), DirectMergeRecoveryPushFailure)
-	}
-	return pushDirectMergeContext(ctx, op)
-}
-
-func pushDirectMergeContext(ctx context.Context, op *DirectMergeOperation) *DirectMergeOperation {
-	if op == nil {
-		return failDirectMerge(nil, fmt.Errorf("missing direct merge operation"), DirectMergeRecoveryNone)
-	}
-	op.runContext = ctx
-	if op.MergeOID == "" {
-		return failDirectMerge(op, fmt.Errorf("merge result OID is not pinned"), DirectMergeRecoveryPushFailure)
-	}
-	if state := gitOperationStateContext(ctx, op.TargetPath); state != "clean" {
-		return failDirectMerge(op, fmt.Errorf("cannot push during Git operation: %s", state), DirectMergeRecoveryPushFailure)
-	}
-	if err := requireCleanContext(ctx, op.TargetPath); err != nil {
-		return failDirectMerge(op, err, DirectMergeRecoveryPushFailure)
-	}
-	if err := requireCheckoutIdentityContext(ctx, op.TargetPath, op.TargetBranch, op.MergeOID); err != nil {
-		return failDirectMerge(op, fmt.Errorf("target checkout changed before push: %w", err), DirectMergeRecoveryPushFailure)
-	}
-	refspec := "HEAD:refs/heads/" + op.TargetBranch
-	if _, err := gitOutputContext(ctx, op.TargetPath, "push", op.Remote, refspec); err != nil {
-		return failDirectMerge(op, fmt.Errorf("push %s %s: %w", op.Remote, op.TargetBranch, err), DirectMergeRecoveryPushFailure)
-	}
-	op.Completed = appendUnique(op.Completed, "push")
-	op.Recovery, op.Err = DirectMergeRecoveryNone, nil
-	op.GitState = currentGitStateContext(ctx, op.TargetPath)
-	return op
-}
-
-func revalidateConflictRecoveryContext(ctx context.Context, op *DirectMergeOperation) error {
-	if op.SourceOID == "" || op.PreMergeOID == "" {
-		return fmt.Errorf("conflict recovery identity is incomplete")
-	}
-	if err := requireCheckoutIdentityContext(ctx, op.TargetPath, op.TargetBranch, op.PreMergeOID); err != nil {
-		return fmt.Errorf("refusing recovery because target checkout changed: %w", err)
-	}
-	if state := gitOperationStateContext(ctx, op.TargetPath); state != "merge" {
-		return fmt.Errorf("the target no longer has the expected merge in progress")
-	}
-	mergeHead, err := gitOutputContext(ctx, op.TargetPath, "rev-parse", "MERGE_HEAD")
-	if err != nil || mergeHead != op.SourceOID {
-		return fmt.Errorf("refusing recovery because MERGE_HEAD changed: got %q, expected %q", mergeHead, op.SourceOID)
-	}
-	return nil
-}
-
-func revalidateDirectMergeContext(ctx context.Context, op *DirectMergeOperation) error {
-	checks := []struct{ path, branch, want string }{
-		{op.SourcePath, op.SourceBranch, op.SourceOID},
-		{op.TargetPath, op.TargetBranch, op.TargetOID},
-	}
-	for _, check := range checks {
-		if state := gitOperationStateContext(ctx, check.path); state != "clean" {
-			return fmt.Errorf("worktree %q has a Git operation in progress: %s", check.path, state)
-		}
-		if err := requireCleanContext(ctx, check.path); err != nil {
-			return err
-		}
-		if err := requireCheckoutIdentityContext(ctx, check.path, check.branch, check.want); err != nil {
-			return err
-		}
-	}
-	return nil
+	return workspaceops.RetryDirectMergePush(ctx, op)
 }
 
 func requireCheckoutIdentityContext(ctx context.Context, path, branch, oid string) error {
@@ -444,37 +175,8 @@ func requireCheckoutIdentityContext(ctx context.Context, path, branch, oid strin
 	return nil
 }
 
-func failDirectMerge(op *DirectMergeOperation, err error, recovery DirectMergeRecovery) *DirectMergeOperation {
-	if op == nil {
-		op = &DirectMergeOperation{}
-	}
-	op.Err, op.Recovery = err, recovery
-	if op.TargetPath != "" {
-		ctx := op.runContext
-		if ctx == nil {
-			ctx = context.Background()
-		}
-		op.GitState = currentGitStateContext(ctx, op.TargetPath)
-	}
-	return op
-}
-
 func resolveBranchRemoteContext(ctx context.Context, repoPath, branch string) (string, error) {
-	if remote, err := gitOutputContext(ctx, repoPath, "config", "--get", "branch."+branch+".remote"); err == nil && remote != "" && remote != "." {
-		return remote, nil
-	}
-	remotes, err := gitOutputContext(ctx, repoPath, "remote")
-	if err != nil {
-		return "", fmt.Errorf("list remotes: %w", err)
-	}
-	items := strings.Fields(remotes)
-	if len(items) == 1 {
-		return items[0], nil
-	}
-	if len(items) == 0 {
-		return "", fmt.Errorf("no remote is configured for target branch %q", branch)
-	}
-	return "", fmt.Errorf("target branch %q has no remote and repository has multiple remotes", branch)
+	return workspaceops.ResolveBranchRemote(ctx, repoPath, branch)
 }
 
 func requireCleanContext(ctx context.Context, path string) error {
@@ -511,26 +213,6 @@ func gitOperationStateContext(ctx context.Context, path string) string {
 	return "clean"
 }
 
-func currentGitStateContext(ctx context.Context, path string) string {
-	head, _ := gitOutputContext(ctx, path, "rev-parse", "--short", "HEAD")
-	branch, _ := gitOutputContext(ctx, path, "branch", "--show-current")
-	status, _ := gitOutputContext(ctx, path, "status", "--short")
-	state := fmt.Sprintf("target %s at %s; operation: %s", branch, head, gitOperationStateContext(ctx, path))
-	if status == "" {
-		return state + "; working tree clean"
-	}
-	return state + "; status:\n" + status
-}
-
-func appendUnique(items []string, item string) []string {
-	for _, existing := range items {
-		if existing == item {
-			return items
-		}
-	}
-	return append(items, item)
-}
-
 // BaseUpdateResult describes a safe post-merge base refresh.
 type BaseUpdateResult struct {
 	Branch        string
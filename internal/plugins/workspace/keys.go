@@ -48,6 +48,10 @@ func (p *Plugin) handleKeyPress(msg tea.KeyPressMsg) tea.Cmd {
 		return p.handleFetchPRKeys(msg)
 	case ViewModeFilePicker:
 		return p.handleFilePickerKeys(msg)
+	case ViewModeConflictMatrix:
+		return p.handleConflictMatrixKeys(msg)
+	case ViewModeReviewThreads:
+		return p.handleReviewThreadsKeys(msg)
 	case ViewModeInteractive:
 		return p.handleInteractiveKeys(msg)
 	}
@@ -925,6 +929,18 @@ func (p *Plugin) handleListKeys(msg tea.KeyPressMsg) tea.Cmd {
 			}
 			return p.startMergeWorkflow(wt)
 		}
+	case "C":
+		return p.openConflictMatrix()
+	case "c":
+		// Review threads of the selected branch's pull request
+		if wt := p.selectedWorktree(); wt != nil && !p.selectingShell() {
+			return p.openReviewThreads(wt)
+		}
+	case "L":
+		// Open the failed CI job's log of the selected branch
+		if wt := p.selectedWorktree(); wt != nil && !p.selectingShell() {
+			return p.openCILog(wt)
+		}
 	case "O":
 		// Open selected worktree in git tab - switch to worktree and focus git plugin
 		wt := p.selectedWorktree()
@@ -1335,6 +1351,18 @@ func (p *Plugin) handleMergeKeys(msg tea.KeyPressMsg) tea.Cmd {
 			return openInBrowser(p.mergeState.PRURL)
 		}
 
+	case "L":
+		// Open the failed CI job's log. The pane opens behind the modal, so
+		// the workflow closes, as esc would; the sidebar keeps watching CI.
+		if p.mergeState.Step == MergeStepWaitingMerge {
+			wt := p.mergeState.Worktree
+			if _, failed := p.ciStatus[wt.IdentityKey()].failedCheck(); failed {
+				p.cancelMergeWorkflow()
+				p.clearMergeModal()
+				return p.openCILog(wt)
+			}
+		}
+
 	case "y":
 		// Copy PR URL to clipboard (only during WaitingMerge step with a PR URL)
 		if p.mergeState.Step == MergeStepWaitingMerge && p.mergeState.PRURL != "" {
@@ -14,6 +14,7 @@ import (
 	"charm.land/bubbles/v2/textinput"
 	tea "charm.land/bubbletea/v2"
 	"github.com/marcus/sidecar/internal/clip"
+	"github.com/marcus/sidecar/internal/forge"
 	appmsg "github.com/marcus/sidecar/internal/msg"
 	"github.com/marcus/sidecar/internal/plugins/gitstatus"
 )
@@ -489,28 +490,29 @@ func (p *Plugin) pushForMerge(wt *Worktree) tea.Cmd {
 		if err != nil || head != reviewedOID {
 			return MergeStepCompleteMsg{OperationScope: scope, WorkspaceName: name, Step: MergeStepPush, Err: fmt.Errorf("HEAD changed after review; return to review")}
 		}
-		baseRemote, repository, topologyErr := resolveBaseTopologyContext(ctx, path, targetBranch)
+		baseRemote, repo, topologyErr := resolveBaseTopologyContext(ctx, path, targetBranch)
 		if topologyErr != nil {
 			return MergeStepCompleteMsg{OperationScope: scope, WorkspaceName: name, Step: MergeStepPush, Err: topologyErr}
 		}
 		configuredHeadRemote, configuredHeadErr := gitOutputContext(ctx, path, "config", "--get", "branch."+branch+".remote")
 		if configuredHeadErr == nil && configuredHeadRemote == "." {
-			return MergeStepCompleteMsg{OperationScope: scope, WorkspaceName: name, Step: MergeStepPush, Err: fmt.Errorf("head branch %q uses local remote '.'; configure a GitHub push remote", branch)}
+			return MergeStepCompleteMsg{OperationScope: scope, WorkspaceName: name, Step: MergeStepPush, Err: fmt.Errorf("head branch %q uses local remote '.'; configure a forge push remote", branch)}
 		}
 		remote, err := resolveBranchRemoteContext(ctx, path, branch)
 		if err != nil {
-			remote, err = resolveRemoteForRepositoryContext(ctx, path, repository)
+			remote, err = resolveRemoteForRepositoryContext(ctx, path, repo)
 		}
-		pr := PRIdentity{Repository: repository, HeadRef: branch, HeadOID: reviewedOID, BaseRef: targetBranch}
+		pr := PRIdentity{Repository: repo.Path, Forge: string(repo.Kind), Host: repo.Host, HeadRef: branch, HeadOID: reviewedOID, BaseRef: targetBranch}
 		if err == nil {
+			var headRepo forge.Repo
 			remoteURL, urlErr := configuredRemoteURLContext(ctx, path, remote)
 			if urlErr == nil {
-				pr.HeadRepo, urlErr = parseGitHubRepositoryURL(remoteURL)
+				headRepo, urlErr = parseRemoteRepositoryContext(ctx, path, remoteURL)
 			}
 			if urlErr != nil {
 				err = fmt.Errorf("resolve head repository from %s: %w", remote, urlErr)
-			} else if owner, _, ok := strings.Cut(pr.HeadRepo, "/"); ok {
-				pr.HeadOwner = owner
+			} else {
+				pr.HeadRepo, pr.HeadOwner = headRepo.Path, headRepo.Owner()
 			}
 		}
 		if err == nil {
@@ -534,7 +536,8 @@ func (p *Plugin) pushForMerge(wt *Worktree) tea.Cmd {
 	}
 }
 
-// createPR creates a pull request using gh CLI.
+// createPR opens a pull request on the base repository's forge, or adopts
+// the one already open for the branch.
 func (p *Plugin) createPR(wt *Worktree, title, body, targetBranch string) tea.Cmd {
 	scope := p.lifecycleScope(wt)
 	ctx := p.operationCtx
@@ -547,21 +550,22 @@ func (p *Plugin) createPR(wt *Worktree, title, body, targetBranch string) tea.Cm
 		reviewedOID, pushRemote, expectedBaseRemote = p.mergeState.ReviewedOID, p.mergeState.PushRemote, p.mergeState.BaseRemote
 	}
 	return func() tea.Msg {
-		baseRemote, repository, err := resolveBaseTopologyContext(ctx, path, targetBranch)
+		baseRemote, repo, err := resolveBaseTopologyContext(ctx, path, targetBranch)
 		if err != nil {
 			return MergeStepCompleteMsg{OperationScope: scope, WorkspaceName: name, Step: MergeStepCreatePR, Err: err}
 		}
 		if expectedBaseRemote != "" && baseRemote != expectedBaseRemote {
 			return MergeStepCompleteMsg{OperationScope: scope, WorkspaceName: name, Step: MergeStepCreatePR, Err: fmt.Errorf("base remote changed from %s to %s; return to review", expectedBaseRemote, baseRemote)}
 		}
-		if pr.Repository != "" && repository != pr.Repository {
-			return MergeStepCompleteMsg{OperationScope: scope, WorkspaceName: name, Step: MergeStepCreatePR, Err: fmt.Errorf("base repository changed from %s to %s; return to review", pr.Repository, repository)}
+		if pr.Repository != "" && !repo.SameAs(pr.forgeRepo()) {
+			return MergeStepCompleteMsg{OperationScope: scope, WorkspaceName: name, Step: MergeStepCreatePR, Err: fmt.Errorf("base repository changed from %s to %s; return to review", pr.Repository, repo.Path)}
 		}
-		pr.Repository = repository
+		pr.Repository, pr.Forge, pr.Host = repo.Path, string(repo.Kind), repo.Host
+		head := forge.Head{Owner: pr.HeadOwner, Repo: pr.HeadRepo, Ref: pr.HeadRef}
 		if err := revalidateReviewedPushContext(ctx, path, pushRemote, pr.HeadRef, reviewedOID); err != nil {
 			return MergeStepCompleteMsg{OperationScope: scope, WorkspaceName: name, Step: MergeStepCreatePR, Err: err}
 		}
-		existing, err := queryExistingPRContext(ctx, path, repository, pr.HeadOwner, pr.HeadRef, targetBranch)
+		existing, err := queryExistingPRContext(ctx, path, repo, head, targetBranch)
 		if err != nil {
 			return MergeStepCompleteMsg{OperationScope: scope, WorkspaceName: name, Step: MergeStepCreatePR, Err: err}
 		}
@@ -574,26 +578,17 @@ func (p *Plugin) createPR(wt *Worktree, title, body, targetBranch string) tea.Cm
 		if err := revalidateReviewedPushContext(ctx, path, pushRemote, pr.HeadRef, reviewedOID); err != nil {
 			return MergeStepCompleteMsg{OperationScope: scope, WorkspaceName: name, Step: MergeStepCreatePR, Err: err}
 		}
-		head := pr.HeadRef
-		if pr.HeadOwner != "" {
-			head = pr.HeadOwner + ":" + pr.HeadRef
-		}
-		args := []string{"pr", "create", "--repo", repository, "--title", title, "--body", body, "--base", targetBranch, "--head", head}
-		output, err := ghOutputContext(ctx, path, args...)
-		if err != nil {
-			return MergeStepCompleteMsg{OperationScope: scope, WorkspaceName: name, Step: MergeStepCreatePR, Err: err}
-		}
-		prURL := strings.TrimSpace(string(output))
-		created, err := viewPRIdentityContext(ctx, path, prURL, repository)
+		mr, err := forge.New(repo, path).Create(ctx, forge.CreateRequest{Title: title, Body: body, Head: head, Base: targetBranch})
 		if err != nil {
 			return MergeStepCompleteMsg{OperationScope: scope, WorkspaceName: name, Step: MergeStepCreatePR, Err: err}
 		}
+		created := identityFromMergeRequest(mr, repo)
 
 		return MergeStepCompleteMsg{
 			OperationScope: scope,
 			WorkspaceName:  name,
 			Step:           MergeStepCreatePR,
-			Data:           prURL,
+			Data:           created.URL,
 			PR:             created,
 		}
 	}
@@ -909,7 +904,7 @@ func (p *Plugin) schedulePRGenerationTick(wtName string) tea.Cmd {
 	})
 }
 
-// checkPRMerged checks if a PR has been merged using gh CLI.
+// checkPRMerged checks whether the pull request has been merged.
 func (p *Plugin) checkPRMerged(wt *Worktree) tea.Cmd {
 	scope := p.lifecycleScope(wt)
 	ctx := p.operationCtx
@@ -960,7 +955,6 @@ func (p *Plugin) executeDirectMerge(scope OperationScope, name, branch string, o
 	private := cloneDirectMergeOperation(op)
 	return func() tea.Msg {
 		result := runDirectMergeContext(ctx, private)
-		result.runContext = nil
 		return DirectMergeDoneMsg{OperationScope: scope, WorkspaceName: name, BaseBranch: branch, Operation: result, Err: result.Err}
 	}
 }
@@ -988,7 +982,6 @@ func (p *Plugin) recoverDirectMerge(action string) tea.Cmd {
 		case "retry-push":
 			op = retryDirectMergePushContext(ctx, op)
 		}
-		op.runContext = nil
 		return DirectMergeDoneMsg{OperationScope: scope, WorkspaceName: name, BaseBranch: branch, Operation: op, Err: op.Err}
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/marcus/sidecar/internal/agentactivity"
+	"github.com/marcus/sidecar/internal/forge"
 	"github.com/marcus/sidecar/internal/worktreedelete"
 )
 
@@ -336,32 +337,36 @@ type FetchPRDoneMsg struct {
 
 // PRListItem represents an open pull request for the fetch modal.
 type PRListItem struct {
-	Number     int          `json:"number"`
-	NodeID     string       `json:"id"`
-	Title      string       `json:"title"`
-	Branch     string       `json:"headRefName"`
-	HeadOID    string       `json:"headRefOid"`
-	BaseBranch string       `json:"baseRefName"`
-	HeadRepo   ghRepository `json:"headRepository"`
-	HeadOwner  ghOwner      `json:"headRepositoryOwner"`
-	Repository string       `json:"-"`
-	Author     prAuthor     `json:"author"`
-	URL        string       `json:"url"`
-	CreatedAt  string       `json:"createdAt"`
-	IsDraft    bool         `json:"isDraft"`
+	Number     int
+	NodeID     string
+	Title      string
+	Branch     string
+	HeadOID    string
+	BaseBranch string
+	HeadRepo   string
+	HeadOwner  string
+	Repository string
+	Forge      string // As in PRIdentity
+	Host       string
+	Author     string
+	URL        string
+	CreatedAt  string
+	IsDraft    bool
+}
+
+func prListItemFromMergeRequest(mr forge.MergeRequest, repo forge.Repo) PRListItem {
+	return PRListItem{Number: mr.Number, NodeID: mr.ID, Title: mr.Title, Branch: mr.HeadRef,
+		HeadOID: mr.HeadOID, BaseBranch: mr.BaseRef, HeadRepo: mr.HeadRepo, HeadOwner: mr.HeadOwner,
+		Repository: repo.Path, Forge: string(repo.Kind), Host: repo.Host, Author: mr.Author,
+		URL: mr.URL, CreatedAt: mr.CreatedAt, IsDraft: mr.Draft}
 }
 
 func (p PRListItem) identity() PRIdentity {
 	return PRIdentity{Number: p.Number, URL: p.URL, NodeID: p.NodeID, Repository: p.Repository,
-		HeadRef: p.Branch, HeadOwner: p.HeadOwner.Login, HeadRepo: p.HeadRepo.NameWithOwner,
+		Forge: p.Forge, Host: p.Host, HeadRef: p.Branch, HeadOwner: p.HeadOwner, HeadRepo: p.HeadRepo,
 		HeadOID: p.HeadOID, BaseRef: p.BaseBranch, State: "OPEN"}
 }
 
-// prAuthor represents the author field from gh pr list --json.
-type prAuthor struct {
-	Login string `json:"login"`
-}
-
 // InteractivePasteResultMsg reports clipboard paste results for interactive mode.
 type InteractivePasteResultMsg struct {
 	Err         error
@@ -229,6 +229,9 @@ func (p *Plugin) modalWheelAtBoundary(msg tea.MouseWheelMsg) (bounded, ok bool)
 		return p.mergeModal != nil && p.mergeModal.WheelAtBoundary(msg, p.mouseHandler), true
 	case ViewModeCommitForMerge:
 		return p.commitForMergeModal != nil && p.commitForMergeModal.WheelAtBoundary(msg, p.mouseHandler), true
+	case ViewModeConflictMatrix, ViewModeReviewThreads:
+		// These modals take no pointer input, so the wheel has nothing to move.
+		return true, true
 	}
 	return false, false
 }
@@ -311,6 +314,9 @@ func (p *Plugin) handleMouse(msg tea.MouseMsg) tea.Cmd {
 	if p.viewMode == ViewModeCreate {
 		return p.handleCreateModalMouse(msg)
 	}
+	if p.viewMode == ViewModeConflictMatrix || p.viewMode == ViewModeReviewThreads {
+		return nil // Keyboard-driven; the list underneath must not take clicks
+	}
 	if p.viewMode == ViewModeTaskLink {
 		return p.handleTaskLinkModalMouse(msg)
 	}
@@ -12,6 +12,7 @@ import (
 	tea "charm.land/bubbletea/v2"
 	"github.com/marcus/sidecar/internal/contentlink"
 	"github.com/marcus/sidecar/internal/contentpanes"
+	"github.com/marcus/sidecar/internal/controlapi"
 	"github.com/marcus/sidecar/internal/docview"
 	"github.com/marcus/sidecar/internal/features"
 	boardkanban "github.com/marcus/sidecar/internal/kanban"
@@ -473,8 +474,32 @@ type Plugin struct {
 
 	commitStatusWorktree string // Name of worktree for cached status
 
-	// Conflict detection state
-	conflicts []Conflict
+	// Conflict detection state. conflicts starts as dirty-path overlaps from
+	// refresh and is replaced by merge-tree predictions once they arrive.
+	conflicts                []Conflict
+	conflictsPredicted       bool            // conflicts came from merge-tree
+	conflictsPredicting      bool            // A prediction is running
+	conflictsUntracked       map[string]bool // Worktrees whose untracked files the prediction left out
+	conflictOperationID      string          // Scope of the latest prediction
+	conflictFingerprint      string          // Worktree state the latest prediction saw
+	conflictMatrixRow        int             // Cursor in the conflict matrix modal
+	conflictMatrixCol        int
+	conflictMatrixReturnMode ViewMode
+
+	// CI state of pushed branches, keyed by worktree identity. ciTicking is
+	// true while a poll tick is scheduled; see ci_status.go.
+	ciStatus   map[string]*CIStatus
+	ciInFlight map[string]bool
+	ciTicking  bool
+
+	// Pull request review threads, keyed by worktree identity, and the
+	// review modal's state; see review_threads.go.
+	reviews          map[string]*reviewState
+	reviewKey        string
+	reviewCursor     int
+	reviewReplying   bool
+	reviewReplyInput textinput.Model
+	reviewReturnMode ViewMode
 
 	// Create modal state. The chooser lives in workspacecreate.Form;
 	// confirm/recovery still use createOperationModal.
@@ -623,6 +648,9 @@ type Plugin struct {
 	// the plugin's only notification state: the rules live in internal/notify,
 	// and this holds the per-workspace history they debounce against.
 	agentLaneTracker notify.LaneTracker
+	// controlAPIPublished is the JSON last offered to the control API for each
+	// topic, so a sweep that changed nothing sends nothing.
+	controlAPIPublished map[controlapi.Topic][]byte
 	// nestedByWorkDir is the nest projection of the full manifest, keyed by
 	// parent worktree path. Current workDir shells stay out of this map.
 	nestedByWorkDir map[string][]*ShellSession
@@ -888,6 +916,11 @@ func (p *Plugin) Init(ctx *plugin.Context) error {
 	p.agents = make(map[string]*Agent)
 	p.managedSessions = make(map[string]bool)
 	p.worktrees = make([]*Worktree, 0)
+	p.conflicts, p.conflictsPredicted, p.conflictsPredicting = nil, false, false
+	p.conflictsUntracked = nil
+	p.conflictOperationID, p.conflictFingerprint = "", ""
+	p.ciStatus, p.ciInFlight, p.ciTicking = nil, nil, false
+	p.reviews = nil
 	// pendingOverviewSelection is deliberately retained across app-owned Reinit.
 	p.attachedSession = ""
 
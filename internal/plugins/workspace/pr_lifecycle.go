@@ -1,17 +1,14 @@
 package workspace
 
 import (
-	"bytes"
 	"context"
 	"crypto/rand"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"net/url"
-	"os/exec"
-	"path"
 	"strings"
 	"time"
+
+	"github.com/marcus/sidecar/internal/forge"
 )
 
 // PRIdentity is the stable identity Sidecar uses after a pull request is found
@@ -30,41 +27,32 @@ type PRIdentity struct {
 	State      string `json:"state,omitempty"`
 	MergedAt   string `json:"mergedAt,omitempty"`
 	MergeOID   string `json:"mergeCommitOid,omitempty"`
+	// Forge and Host name the service Repository is on. Identities saved
+	// before other forges were supported carry neither and are GitHub's.
+	Forge string `json:"forge,omitempty"`
+	Host  string `json:"host,omitempty"`
 }
 
-type ghRepository struct {
-	NameWithOwner string `json:"nameWithOwner"`
-}
-
-type ghOwner struct {
-	Login string `json:"login"`
-}
-
-type ghCommit struct {
-	OID string `json:"oid"`
-}
-
-type ghPR struct {
-	Number              int          `json:"number"`
-	URL                 string       `json:"url"`
-	ID                  string       `json:"id"`
-	HeadRefName         string       `json:"headRefName"`
-	HeadRefOID          string       `json:"headRefOid"`
-	BaseRefName         string       `json:"baseRefName"`
-	State               string       `json:"state"`
-	MergedAt            string       `json:"mergedAt"`
-	HeadRepository      ghRepository `json:"headRepository"`
-	HeadRepositoryOwner ghOwner      `json:"headRepositoryOwner"`
-	MergeCommit         ghCommit     `json:"mergeCommit"`
+// forgeRepo is the repository the pull request is on.
+func (id PRIdentity) forgeRepo() forge.Repo {
+	kind, ok := forge.ParseKind(id.Forge)
+	if !ok {
+		kind = forge.GitHub
+	}
+	host := id.Host
+	if host == "" {
+		host = "github.com"
+	}
+	return forge.Repo{Kind: kind, Host: host, Path: id.Repository}
 }
 
-func (p ghPR) identity(repository string) PRIdentity {
+func identityFromMergeRequest(mr forge.MergeRequest, repo forge.Repo) PRIdentity {
 	return PRIdentity{
-		Number: p.Number, URL: p.URL, NodeID: p.ID, Repository: repository,
-		HeadRef: p.HeadRefName, HeadOwner: p.HeadRepositoryOwner.Login,
-		HeadRepo: p.HeadRepository.NameWithOwner, HeadOID: p.HeadRefOID,
-		BaseRef: p.BaseRefName, State: strings.ToUpper(p.State),
-		MergedAt: p.MergedAt, MergeOID: p.MergeCommit.OID,
+		Number: mr.Number, URL: mr.URL, NodeID: mr.ID, Repository: repo.Path,
+		Forge: string(repo.Kind), Host: repo.Host,
+		HeadRef: mr.HeadRef, HeadOwner: mr.HeadOwner, HeadRepo: mr.HeadRepo,
+		HeadOID: mr.HeadOID, BaseRef: mr.BaseRef, State: string(mr.State),
+		MergedAt: mr.MergedAt, MergeOID: mr.MergeOID,
 	}
 }
 
@@ -115,7 +103,7 @@ type PRPollResult struct {
 
 func validateMergedPRForCleanupContext(ctx context.Context, dir, reviewedOID, expectedBase string, identity PRIdentity) (bool, error) {
 	if identity.State != "MERGED" || identity.MergeOID == "" {
-		return false, errors.New("GitHub did not return a merged commit OID")
+		return false, errors.New("the forge did not report a merge commit")
 	}
 	if identity.BaseRef != expectedBase {
 		return false, fmt.Errorf("pull request base changed from %q to %q", expectedBase, identity.BaseRef)
@@ -142,7 +130,7 @@ func validateMergedPRForCleanupContext(ctx context.Context, dir, reviewedOID, ex
 	return ancestorErr != nil, nil
 }
 
-func classifyGHError(output string, err error) PRPollKind {
+func classifyForgeError(output string, err error) PRPollKind {
 	if err == nil {
 		return ""
 	}
@@ -150,7 +138,7 @@ func classifyGHError(output string, err error) PRPollKind {
 	switch {
 	case strings.Contains(s, "auth"), strings.Contains(s, "login"), strings.Contains(s, "401"), strings.Contains(s, "403"):
 		return PRPollAuth
-	case strings.Contains(s, "could not resolve host"), strings.Contains(s, "network"), strings.Contains(s, "connection"), strings.Contains(s, "timeout"):
+	case strings.Contains(s, "could not resolve host"), strings.Contains(s, "no such host"), strings.Contains(s, "network"), strings.Contains(s, "connection"), strings.Contains(s, "timeout"):
 		return PRPollNetwork
 	case strings.Contains(s, "not found"), strings.Contains(s, "could not resolve to a repository"), strings.Contains(s, "repository"):
 		return PRPollRepository
@@ -159,52 +147,25 @@ func classifyGHError(output string, err error) PRPollKind {
 	}
 }
 
-func ghOutputContext(ctx context.Context, dir string, args ...string) ([]byte, error) {
-	cmd := exec.CommandContext(ctx, "gh", args...)
-	cmd.Dir = dir
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-	out, err := cmd.Output()
+// forgeKindContext is the forge configured for the repository at dir with
+// `git config sidecar.forge`, for self-hosted forges whose hostname does not
+// say which they are; empty to detect it from the remote URL.
+func forgeKindContext(ctx context.Context, dir string) forge.Kind {
+	configured, err := gitOutputContext(ctx, dir, "config", "--get", "sidecar.forge")
 	if err != nil {
-		msg := strings.TrimSpace(stderr.String())
-		if msg == "" {
-			msg = err.Error()
-		}
-		return nil, fmt.Errorf("gh %s: %s: %w", strings.Join(args, " "), msg, err)
+		return ""
 	}
-	return out, nil
+	kind, _ := forge.ParseKind(configured)
+	return kind
 }
 
-func parseGitHubRepositoryURL(remoteURL string) (string, error) {
-	raw := strings.TrimSpace(remoteURL)
-	if raw == "" {
-		return "", errors.New("remote URL is empty")
-	}
-	var host, repoPath string
-	if before, after, ok := strings.Cut(raw, ":"); ok && strings.Contains(before, "@") && !strings.Contains(before, "/") {
-		host = strings.TrimPrefix(before[strings.LastIndex(before, "@")+1:], "//")
-		repoPath = after
-	} else {
-		u, err := url.Parse(raw)
-		if err != nil || u.Hostname() == "" {
-			return "", fmt.Errorf("remote URL %q is not a GitHub URL", remoteURL)
-		}
-		host, repoPath = u.Hostname(), u.Path
-	}
-	if !strings.EqualFold(host, "github.com") {
-		return "", fmt.Errorf("remote URL %q is not hosted on github.com", remoteURL)
-	}
-	repoPath = strings.TrimSuffix(strings.Trim(path.Clean("/"+repoPath), "/"), ".git")
-	parts := strings.Split(repoPath, "/")
-	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
-		return "", fmt.Errorf("remote URL %q has no owner/repository identity", remoteURL)
-	}
-	return parts[0] + "/" + parts[1], nil
+func parseRemoteRepositoryContext(ctx context.Context, dir, remoteURL string) (forge.Repo, error) {
+	return forge.ParseRemote(remoteURL, forgeKindContext(ctx, dir))
 }
 
 func configuredRemoteURLContext(ctx context.Context, dir, remote string) (string, error) {
 	if remote == "" || remote == "." {
-		return "", fmt.Errorf("remote %q is not a GitHub transport remote", remote)
+		return "", fmt.Errorf("remote %q is not a forge transport remote", remote)
 	}
 	remoteURL, err := gitOutputContext(ctx, dir, "config", "--get", "remote."+remote+".url")
 	if err != nil || remoteURL == "" {
@@ -213,26 +174,26 @@ func configuredRemoteURLContext(ctx context.Context, dir, remote string) (string
 	return remoteURL, nil
 }
 
-func resolveBaseTopologyContext(ctx context.Context, dir, baseBranch string) (remote, repository string, err error) {
+func resolveBaseTopologyContext(ctx context.Context, dir, baseBranch string) (remote string, repo forge.Repo, err error) {
 	if baseBranch == "" {
-		return "", "", errors.New("base branch is empty")
+		return "", forge.Repo{}, errors.New("base branch is empty")
 	}
 	remote, err = gitOutputContext(ctx, dir, "config", "--get", "branch."+baseBranch+".remote")
 	if err != nil || remote == "" {
 		remote, err = gitOutputContext(ctx, dir, "for-each-ref", "--format=%(upstream:remotename)", "refs/heads/"+baseBranch)
 	}
 	if err != nil || remote == "" || remote == "." {
-		return "", "", fmt.Errorf("base branch %q has no GitHub remote/upstream; configure branch.%s.remote", baseBranch, baseBranch)
+		return "", forge.Repo{}, fmt.Errorf("base branch %q has no forge remote/upstream; configure branch.%s.remote", baseBranch, baseBranch)
 	}
 	remoteURL, err := configuredRemoteURLContext(ctx, dir, remote)
 	if err != nil {
-		return "", "", err
+		return "", forge.Repo{}, err
 	}
-	repository, err = parseGitHubRepositoryURL(remoteURL)
+	repo, err = parseRemoteRepositoryContext(ctx, dir, remoteURL)
 	if err != nil {
-		return "", "", fmt.Errorf("resolve base repository from %s: %w", remote, err)
+		return "", forge.Repo{}, fmt.Errorf("resolve base repository from %s: %w", remote, err)
 	}
-	return remote, repository, nil
+	return remote, repo, nil
 }
 
 func newTemporaryPRRefPrefix(number int) (string, error) {
@@ -243,51 +204,27 @@ func newTemporaryPRRefPrefix(number int) (string, error) {
 	return fmt.Sprintf("refs/sidecar/pr/%d/%x", number, token[:]), nil
 }
 
-func queryExistingPRContext(ctx context.Context, dir, repository, headOwner, headRef, baseRef string) (*PRIdentity, error) {
-	// gh pr list explicitly does not accept owner:branch for --head. Ask for
-	// the supported bare branch selector, then disambiguate forks from the
-	// structured owner/repository fields.
-	args := []string{"pr", "list", "--state", "all", "--head", headRef, "--base", baseRef, "--limit", "100", "--json", "number,url,id,headRefName,headRefOid,baseRefName,state,mergedAt,headRepository,headRepositoryOwner,mergeCommit"}
-	if repository != "" {
-		args = append(args, "--repo", repository)
-	}
-	out, err := ghOutputContext(ctx, dir, args...)
-	if err != nil {
+func queryExistingPRContext(ctx context.Context, dir string, repo forge.Repo, head forge.Head, baseRef string) (*PRIdentity, error) {
+	mr, err := forge.New(repo, dir).Find(ctx, head, baseRef)
+	if err != nil || mr == nil {
 		return nil, err
 	}
-	var prs []ghPR
-	if err := json.Unmarshal(out, &prs); err != nil {
-		return nil, fmt.Errorf("parse existing pull request: %w", err)
-	}
-	for _, raw := range prs {
-		id := raw.identity(repository)
-		if id.HeadRef != headRef || id.BaseRef != baseRef {
-			continue
-		}
-		if headOwner != "" && !strings.EqualFold(id.HeadOwner, headOwner) {
-			continue
-		}
-		return &id, nil
-	}
-	return nil, nil
+	id := identityFromMergeRequest(*mr, repo)
+	return &id, nil
 }
 
 func pollPRContext(ctx context.Context, dir string, identity PRIdentity) PRPollResult {
 	if identity.Number <= 0 || identity.Repository == "" {
 		return PRPollResult{Kind: PRPollRepository, Identity: identity, Err: errors.New("pull request repository or number is missing")}
 	}
-	args := []string{"pr", "view", fmt.Sprintf("%d", identity.Number), "--repo", identity.Repository, "--json", "number,url,id,headRefName,headRefOid,baseRefName,state,mergedAt,headRepository,headRepositoryOwner,mergeCommit"}
-	out, err := ghOutputContext(ctx, dir, args...)
+	repo := identity.forgeRepo()
+	mr, err := forge.New(repo, dir).Get(ctx, identity.Number)
 	if err != nil {
-		return PRPollResult{Kind: classifyGHError(err.Error(), err), Identity: identity, Err: err}
+		return PRPollResult{Kind: classifyForgeError(err.Error(), err), Identity: identity, Err: err}
 	}
-	var raw ghPR
-	if err := json.Unmarshal(out, &raw); err != nil {
-		return PRPollResult{Kind: PRPollUnavailable, Identity: identity, Err: fmt.Errorf("parse pull request status: %w", err)}
-	}
-	got := raw.identity(identity.Repository)
+	got := identityFromMergeRequest(mr, repo)
 	if got.Number != identity.Number || (identity.NodeID != "" && got.NodeID != identity.NodeID) {
-		return PRPollResult{Kind: PRPollRepository, Identity: identity, Err: errors.New("GitHub returned a different pull request identity")}
+		return PRPollResult{Kind: PRPollRepository, Identity: identity, Err: fmt.Errorf("%s returned a different %s identity", repo.Kind.Title(), repo.Kind.RequestNoun())}
 	}
 	var kind PRPollKind
 	switch got.State {
@@ -303,22 +240,6 @@ func pollPRContext(ctx context.Context, dir string, identity PRIdentity) PRPollR
 	return PRPollResult{Kind: kind, Identity: got}
 }
 
-func viewPRIdentityContext(ctx context.Context, dir, spec, repository string) (PRIdentity, error) {
-	args := []string{"pr", "view", spec, "--json", "number,url,id,headRefName,headRefOid,baseRefName,state,mergedAt,headRepository,headRepositoryOwner,mergeCommit"}
-	if repository != "" {
-		args = append(args, "--repo", repository)
-	}
-	out, err := ghOutputContext(ctx, dir, args...)
-	if err != nil {
-		return PRIdentity{}, err
-	}
-	var raw ghPR
-	if err := json.Unmarshal(out, &raw); err != nil {
-		return PRIdentity{}, fmt.Errorf("parse pull request identity: %w", err)
-	}
-	return raw.identity(repository), nil
-}
-
 func nextPRPollDelay(attempt int) time.Duration {
 	if attempt < 0 {
 		attempt = 0
@@ -330,24 +251,21 @@ func nextPRPollDelay(attempt int) time.Duration {
 	return delays[attempt]
 }
 
-func resolveRemoteForRepositoryContext(ctx context.Context, dir, repository string) (string, error) {
+func resolveRemoteForRepositoryContext(ctx context.Context, dir string, repo forge.Repo) (string, error) {
 	out, err := gitOutputContext(ctx, dir, "remote")
 	if err != nil {
 		return "", err
 	}
+	kind := forgeKindContext(ctx, dir)
 	var matches []string
 	for _, remote := range strings.Fields(out) {
 		// Read the configured URL rather than `remote get-url`: the latter
-		// expands url.*.insteadOf, hiding the GitHub identity behind local mirrors.
+		// expands url.*.insteadOf, hiding the forge identity behind local mirrors.
 		url, e := gitOutputContext(ctx, dir, "config", "--get", "remote."+remote+".url")
 		if e != nil {
 			continue
 		}
-		normalized := strings.TrimSuffix(strings.TrimSuffix(url, ".git"), "/")
-		normalized = strings.TrimPrefix(normalized, "git@github.com:")
-		normalized = strings.TrimPrefix(normalized, "ssh://git@github.com/")
-		normalized = strings.TrimPrefix(normalized, "https://github.com/")
-		if strings.EqualFold(normalized, repository) {
+		if parsed, e := forge.ParseRemote(url, kind); e == nil && parsed.SameAs(repo) {
 			matches = append(matches, remote)
 		}
 	}
@@ -355,7 +273,7 @@ func resolveRemoteForRepositoryContext(ctx context.Context, dir, repository stri
 		return matches[0], nil
 	}
 	if len(matches) == 0 {
-		return "", fmt.Errorf("no Git remote matches GitHub repository %q", repository)
+		return "", fmt.Errorf("no Git remote matches %s repository %q", repo.Kind.Title(), repo.Path)
 	}
-	return "", fmt.Errorf("multiple Git remotes match GitHub repository %q", repository)
+	return "", fmt.Errorf("multiple Git remotes match %s repository %q", repo.Kind.Title(), repo.Path)
 }
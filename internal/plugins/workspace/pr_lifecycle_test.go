@@ -16,6 +16,7 @@ import (
 	tea "charm.land/bubbletea/v2"
 	"github.com/charmbracelet/x/ansi"
 	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/forge"
 	"github.com/marcus/sidecar/internal/plugin"
 	"github.com/marcus/sidecar/internal/projectdir"
 )
@@ -47,7 +48,8 @@ printf '%s\n' "$*" > "$FAKE_GH_LOG"
 case "$*" in *'--head '*:*) echo 'unsupported owner:branch --head' >&2; exit 9;; esac
 printf '%s\n' '[{"number":41,"url":"wrong","id":"wrong","headRefName":"topic","headRefOid":"bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb","baseRefName":"release/2","state":"OPEN","mergedAt":"","headRepository":{"nameWithOwner":"other/repo"},"headRepositoryOwner":{"login":"other"},"mergeCommit":null},{"number":42,"url":"https://github.com/base/repo/pull/42","id":"PR_node","headRefName":"topic","headRefOid":"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa","baseRefName":"release/2","state":"OPEN","mergedAt":"","headRepository":{"nameWithOwner":"fork/repo"},"headRepositoryOwner":{"login":"fork"},"mergeCommit":null}]'
 `)
-	id, err := queryExistingPRContext(context.Background(), t.TempDir(), "base/repo", "fork", "topic", "release/2")
+	base := forge.Repo{Kind: forge.GitHub, Host: "github.com", Path: "base/repo"}
+	id, err := queryExistingPRContext(context.Background(), t.TempDir(), base, forge.Head{Owner: "fork", Repo: "fork/repo", Ref: "topic"}, "release/2")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -102,7 +104,7 @@ func TestImportPRUsesPullRefForSameRepoAndFork(t *testing.T) {
 			if err := p.Init(&plugin.Context{Epoch: 2, WorkDir: repo, ProjectRoot: repo}); err != nil {
 				t.Fatal(err)
 			}
-			pr := PRListItem{Number: 12, NodeID: "node12", Branch: "topic", HeadOID: head, BaseBranch: "main", Repository: "base/repo", URL: "https://github.com/base/repo/pull/12", HeadRepo: ghRepository{NameWithOwner: tc.headRepo}, HeadOwner: ghOwner{Login: tc.owner}}
+			pr := PRListItem{Number: 12, NodeID: "node12", Branch: "topic", HeadOID: head, BaseBranch: "main", Repository: "base/repo", URL: "https://github.com/base/repo/pull/12", HeadRepo: tc.headRepo, HeadOwner: tc.owner}
 			msg := p.fetchAndCreateWorktree(pr)().(FetchPRDoneMsg)
 			if msg.Err != nil {
 				t.Fatal(msg.Err)
@@ -320,19 +322,25 @@ esac`)
 }
 
 func TestGitHubRemoteParsingAndTemporaryRefs(t *testing.T) {
+	repo := t.TempDir()
+	mustGit(t, repo, "init", "-b", "main")
 	for input, want := range map[string]string{
 		"git@github.com:owner/repo.git":       "owner/repo",
 		"ssh://git@github.com/owner/repo.git": "owner/repo",
 		"https://github.com/owner/repo.git":   "owner/repo",
 	} {
-		got, err := parseGitHubRepositoryURL(input)
-		if err != nil || got != want {
-			t.Fatalf("parse %q = %q, %v", input, got, err)
+		got, err := parseRemoteRepositoryContext(context.Background(), repo, input)
+		if err != nil || got.Path != want || got.Kind != forge.GitHub {
+			t.Fatalf("parse %q = %+v, %v", input, got, err)
 		}
 	}
-	if _, err := parseGitHubRepositoryURL("/tmp/repo.git"); err == nil {
+	if _, err := parseRemoteRepositoryContext(context.Background(), repo, "/tmp/repo.git"); err == nil {
 		t.Fatal("local non-GitHub remote was accepted")
 	}
+	mustGit(t, repo, "config", "sidecar.forge", "gitea")
+	if got, err := parseRemoteRepositoryContext(context.Background(), repo, "git@git.example.com:team/app.git"); err != nil || got.Kind != forge.Gitea || got.Path != "team/app" {
+		t.Fatalf("configured forge = %+v, %v", got, err)
+	}
 	a, err := newTemporaryPRRefPrefix(9)
 	if err != nil {
 		t.Fatal(err)
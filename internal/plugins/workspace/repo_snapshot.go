@@ -1,6 +1,7 @@
 package workspace
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"fmt"
@@ -219,9 +220,18 @@ func loadBranchInventory(ctx context.Context, repoPath string) (map[string]branc
 }
 
 func gitOutputContext(ctx context.Context, dir string, args ...string) (string, error) {
+	return gitOutputEnvContext(ctx, dir, nil, args...)
+}
+
+// gitOutputEnvContext is gitOutputContext with env added to the inherited
+// environment.
+func gitOutputEnvContext(ctx context.Context, dir string, env []string, args ...string) (string, error) {
 	recordGitProcess(ctx, nil)
 	cmd := exec.CommandContext(ctx, "git", args...)
 	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return "", fmt.Errorf("git %s: %s: %w", strings.Join(args, " "), strings.TrimSpace(string(out)), err)
@@ -229,6 +239,21 @@ func gitOutputContext(ctx context.Context, dir string, args ...string) (string,
 	return strings.TrimSpace(string(out)), nil
 }
 
+// gitOutputRawContext returns git's stdout alone and untrimmed, for reading
+// blobs whose leading and trailing lines matter.
+func gitOutputRawContext(ctx context.Context, dir string, args ...string) (string, error) {
+	recordGitProcess(ctx, nil)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %s: %w", strings.Join(args, " "), strings.TrimSpace(stderr.String()), err)
+	}
+	return string(out), nil
+}
+
 func stablePathKey(path string) string {
 	sum := sha256.Sum256([]byte(filepath.Clean(path)))
 	return fmt.Sprintf("%x", sum[:])
@@ -0,0 +1,464 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	app "github.com/marcus/sidecar/internal/app"
+	"github.com/marcus/sidecar/internal/forge"
+	"github.com/marcus/sidecar/internal/plugins/gitstatus"
+	"github.com/marcus/sidecar/internal/styles"
+	"github.com/marcus/sidecar/internal/ui"
+)
+
+const (
+	// reviewRefreshInterval is how long fetched review threads are shown
+	// before the diff asks the forge again.
+	reviewRefreshInterval = 2 * time.Minute
+	// maxReviewListRows caps the thread list in the review modal.
+	maxReviewListRows = 8
+	// maxReviewDetailRows caps the selected thread's conversation.
+	maxReviewDetailRows = 12
+)
+
+var errNoPullRequest = errors.New("branch has no pull request")
+
+// reviewState is what is known about the review threads on a worktree's
+// pull request.
+type reviewState struct {
+	Repo      forge.Repo
+	Number    int
+	Threads   []forge.ReviewThread
+	Err       error
+	FetchedAt time.Time
+	loading   bool
+}
+
+// ReviewThreadsMsg carries the review threads read for a worktree.
+type ReviewThreadsMsg struct {
+	OperationScope
+	WorktreeKey string
+	Repo        forge.Repo
+	Number      int
+	Threads     []forge.ReviewThread
+	Err         error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m ReviewThreadsMsg) GetEpoch() uint64 { return m.Epoch }
+
+// ReviewActionMsg reports a reply or resolution posted to the forge.
+type ReviewActionMsg struct {
+	OperationScope
+	WorktreeKey string
+	Done        string // What happened, for the toast
+	Err         error
+}
+
+// GetEpoch implements plugin.EpochMessage.
+func (m ReviewActionMsg) GetEpoch() uint64 { return m.Epoch }
+
+// maybeFetchReviews reads a worktree's review threads unless a read is in
+// flight or recent. Only a branch with a pull request has any.
+func (p *Plugin) maybeFetchReviews(wt *Worktree) tea.Cmd {
+	if wt == nil || wt.PRURL == "" {
+		return nil
+	}
+	if st := p.reviews[wt.IdentityKey()]; st != nil && (st.loading || time.Since(st.FetchedAt) < reviewRefreshInterval) {
+		return nil
+	}
+	return p.fetchReviews(wt)
+}
+
+func (p *Plugin) fetchReviews(wt *Worktree) tea.Cmd {
+	ctx, scope := p.newOperationScope(wt)
+	key, path := wt.IdentityKey(), wt.Path
+	projectRoot := p.ctx.ProjectRoot
+	if p.reviews == nil {
+		p.reviews = make(map[string]*reviewState)
+	}
+	st := p.reviews[key]
+	if st == nil {
+		st = &reviewState{}
+		p.reviews[key] = st
+	}
+	st.loading = true
+	return func() tea.Msg {
+		msg := ReviewThreadsMsg{OperationScope: scope, WorktreeKey: key}
+		msg.Repo, msg.Number, msg.Err = resolveReviewTargetContext(ctx, projectRoot, path)
+		if msg.Err == nil {
+			msg.Threads, msg.Err = forge.New(msg.Repo, path).ReviewThreads(ctx, msg.Number)
+		}
+		return msg
+	}
+}
+
+// resolveReviewTargetContext finds the pull request a worktree's branch
+// opened, from the identity recorded when it was created or fetched.
+func resolveReviewTargetContext(ctx context.Context, projectRoot, dir string) (forge.Repo, int, error) {
+	identity := loadPRIdentityContext(ctx, projectRoot, dir)
+	if identity.Number == 0 || identity.Repository == "" {
+		return forge.Repo{}, 0, errNoPullRequest
+	}
+	return identity.forgeRepo(), identity.Number, nil
+}
+
+func (p *Plugin) applyReviewThreads(msg ReviewThreadsMsg) {
+	st := p.reviews[msg.WorktreeKey]
+	if st == nil {
+		return
+	}
+	st.loading = false
+	st.FetchedAt = time.Now()
+	st.Err = msg.Err
+	if msg.Err != nil {
+		// A failed read keeps the threads already shown.
+		return
+	}
+	st.Repo, st.Number, st.Threads = msg.Repo, msg.Number, msg.Threads
+	p.reviewCursor = min(p.reviewCursor, max(len(st.Threads)-1, 0))
+}
+
+// reviewNotes are the inline annotations for one file of a worktree's diff.
+// Outdated threads are left out: the line they name is no longer the one
+// the diff shows.
+func (p *Plugin) reviewNotes(worktreePath, file string) []gitstatus.LineNote {
+	if worktreePath == "" || file == "" {
+		return nil
+	}
+	wt := p.worktreeAt(worktreePath)
+	if wt == nil {
+		return nil
+	}
+	st := p.reviews[wt.IdentityKey()]
+	if st == nil {
+		return nil
+	}
+	var notes []gitstatus.LineNote
+	for _, t := range st.Threads {
+		if t.Path != file || t.Outdated || len(t.Comments) == 0 {
+			continue
+		}
+		notes = append(notes, gitstatus.LineNote{
+			Line: t.Line, Old: t.OnOld, Header: reviewThreadHeader(t), Body: reviewThreadBody(t), Dim: t.Resolved,
+		})
+	}
+	return notes
+}
+
+// worktreeAt is the worktree checked out at path.
+func (p *Plugin) worktreeAt(path string) *Worktree {
+	if path == "" {
+		return nil
+	}
+	for _, wt := range p.worktrees {
+		if wt.Path == path {
+			return wt
+		}
+	}
+	return nil
+}
+
+func reviewThreadHeader(t forge.ReviewThread) string {
+	header := "💬 @" + t.Author()
+	if n := len(t.Comments) - 1; n == 1 {
+		header += " · 1 reply"
+	} else if n > 1 {
+		header += fmt.Sprintf(" · %d replies", n)
+	}
+	if t.Resolved {
+		header += " · resolved"
+	}
+	if t.Outdated {
+		header += " · outdated"
+	}
+	return header
+}
+
+// reviewThreadBody is the first comment, then each reply after its author.
+func reviewThreadBody(t forge.ReviewThread) string {
+	var sb strings.Builder
+	for i, c := range t.Comments {
+		if i == 0 {
+			sb.WriteString(c.Body)
+			continue
+		}
+		fmt.Fprintf(&sb, "\n↳ @%s: %s", c.Author, c.Body)
+	}
+	return sb.String()
+}
+
+// reviewThreadLocation is the thread's file:line.
+func reviewThreadLocation(t forge.ReviewThread) string {
+	loc := fmt.Sprintf("%s:%d", t.Path, t.Line)
+	if t.OnOld {
+		loc += " (removed line)"
+	}
+	return loc
+}
+
+// reviewAgentPrompt is what "send to agent" types into the agent's session:
+// one line, since a newline would submit it part-way.
+func reviewAgentPrompt(t forge.ReviewThread) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Address this review comment on %s from @%s:", reviewThreadLocation(t), t.Author())
+	for i, c := range t.Comments {
+		if i > 0 {
+			fmt.Fprintf(&sb, " Reply from @%s:", c.Author)
+		}
+		sb.WriteString(" ")
+		sb.WriteString(strings.Join(strings.Fields(c.Body), " "))
+	}
+	return sb.String()
+}
+
+// openReviewThreads shows the review threads of the selected worktree's pull
+// request, read afresh.
+func (p *Plugin) openReviewThreads(wt *Worktree) tea.Cmd {
+	if wt == nil {
+		return nil
+	}
+	if wt.PRURL == "" {
+		return func() tea.Msg {
+			return app.ToastMsg{Message: wt.Name + " has no pull request", Duration: 3 * time.Second, IsError: true}
+		}
+	}
+	p.reviewKey = wt.IdentityKey()
+	p.reviewCursor = 0
+	p.reviewReplying = false
+	p.reviewReturnMode = p.viewMode
+	p.viewMode = ViewModeReviewThreads
+	if st := p.reviews[p.reviewKey]; st != nil && st.loading {
+		return nil
+	}
+	return p.fetchReviews(wt)
+}
+
+func (p *Plugin) closeReviewThreads() {
+	p.viewMode = p.reviewReturnMode
+	p.reviewReplying = false
+	p.reviewReplyInput = textinput.Model{}
+}
+
+// selectedReviewThread is the thread under the modal's cursor.
+func (p *Plugin) selectedReviewThread() (*Worktree, *reviewState, *forge.ReviewThread) {
+	wt := p.findWorktree(p.reviewKey)
+	st := p.reviews[p.reviewKey]
+	if wt == nil || st == nil || p.reviewCursor < 0 || p.reviewCursor >= len(st.Threads) {
+		return wt, st, nil
+	}
+	return wt, st, &st.Threads[p.reviewCursor]
+}
+
+func (p *Plugin) handleReviewThreadsKeys(msg tea.KeyPressMsg) tea.Cmd {
+	wt, st, thread := p.selectedReviewThread()
+	if wt == nil {
+		p.closeReviewThreads()
+		return nil
+	}
+	if p.reviewReplying {
+		switch msg.String() {
+		case "esc":
+			p.reviewReplying = false
+			p.reviewReplyInput = textinput.Model{}
+			return nil
+		case "enter":
+			body := strings.TrimSpace(p.reviewReplyInput.Value())
+			p.reviewReplying = false
+			p.reviewReplyInput = textinput.Model{}
+			if body == "" || thread == nil {
+				return nil
+			}
+			t := *thread
+			return p.reviewAction(wt, st, "Reply posted", func(ctx context.Context, f forge.Forge, number int) error {
+				return f.ReplyToThread(ctx, number, t, body)
+			})
+		}
+		var cmd tea.Cmd
+		p.reviewReplyInput, cmd = p.reviewReplyInput.Update(msg)
+		return cmd
+	}
+	n := 0
+	if st != nil {
+		n = len(st.Threads)
+	}
+	switch msg.String() {
+	case "esc", "q", "c":
+		p.closeReviewThreads()
+	case "j", "down":
+		p.reviewCursor = min(p.reviewCursor+1, max(n-1, 0))
+	case "k", "up":
+		p.reviewCursor = max(p.reviewCursor-1, 0)
+	case "r":
+		if thread != nil {
+			p.reviewReplyInput = textinput.New()
+			p.reviewReplyInput.Placeholder = "Reply to @" + thread.Author()
+			p.reviewReplyInput.CharLimit = 2000
+			p.reviewReplying = true
+			return p.reviewReplyInput.Focus()
+		}
+	case "x":
+		if thread != nil && !thread.Resolved {
+			t := *thread
+			return p.reviewAction(wt, st, "Thread resolved", func(ctx context.Context, f forge.Forge, number int) error {
+				return f.ResolveThread(ctx, number, t)
+			})
+		}
+	case "a":
+		if thread != nil {
+			return p.sendReviewToAgent(wt, *thread)
+		}
+	case "o":
+		if thread != nil && len(thread.Comments) > 0 && thread.Comments[0].URL != "" {
+			return openInBrowser(thread.Comments[0].URL)
+		}
+	case "R":
+		if st == nil || !st.loading {
+			return p.fetchReviews(wt)
+		}
+	}
+	return nil
+}
+
+// reviewAction posts to the forge and reads the threads again afterwards.
+func (p *Plugin) reviewAction(wt *Worktree, st *reviewState, done string, act func(context.Context, forge.Forge, int) error) tea.Cmd {
+	if st == nil || st.Number == 0 {
+		return nil
+	}
+	ctx, scope := p.newOperationScope(wt)
+	key, path, repo, number := wt.IdentityKey(), wt.Path, st.Repo, st.Number
+	return func() tea.Msg {
+		err := act(ctx, forge.New(repo, path), number)
+		return ReviewActionMsg{OperationScope: scope, WorktreeKey: key, Done: done, Err: err}
+	}
+}
+
+func (p *Plugin) applyReviewAction(msg ReviewActionMsg) tea.Cmd {
+	if msg.Err != nil {
+		return func() tea.Msg {
+			return app.ToastMsg{Message: msg.Err.Error(), Duration: 5 * time.Second, IsError: true}
+		}
+	}
+	cmds := []tea.Cmd{func() tea.Msg { return app.ToastMsg{Message: msg.Done, Duration: 3 * time.Second} }}
+	if wt := p.findWorktree(msg.WorktreeKey); wt != nil {
+		cmds = append(cmds, p.fetchReviews(wt))
+	}
+	return tea.Batch(cmds...)
+}
+
+// sendReviewToAgent types the thread, with its file and line, into the
+// worktree's agent session.
+func (p *Plugin) sendReviewToAgent(wt *Worktree, thread forge.ReviewThread) tea.Cmd {
+	if wt.Agent == nil {
+		return func() tea.Msg {
+			return app.ToastMsg{Message: "No agent running in " + wt.Name, Duration: 3 * time.Second, IsError: true}
+		}
+	}
+	send := p.SendText(wt, reviewAgentPrompt(thread))
+	return func() tea.Msg {
+		if res, ok := send().(SendTextResultMsg); ok && res.Err != nil {
+			return app.ToastMsg{Message: "Send to agent: " + res.Err.Error(), Duration: 5 * time.Second, IsError: true}
+		}
+		return app.ToastMsg{Message: "Sent review comment to " + wt.Name + "'s agent", Duration: 3 * time.Second}
+	}
+}
+
+// renderReviewThreadsModal overlays the thread list, with the selected
+// thread's conversation under it.
+func (p *Plugin) renderReviewThreadsModal(background string) string {
+	wt, st, thread := p.selectedReviewThread()
+	if wt == nil {
+		return background
+	}
+	modalWidth := min(max(p.width*3/4, 60), p.width-4)
+	inner := modalWidth - 6
+
+	var sb strings.Builder
+	sb.WriteString(styles.ModalTitle.Render("Review Threads"))
+	sb.WriteString(styles.Muted.Render("  " + wt.Name))
+	if st != nil && st.Number > 0 {
+		sb.WriteString(styles.Muted.Render(fmt.Sprintf(" · #%d", st.Number)))
+	}
+	sb.WriteString("\n\n")
+
+	switch {
+	case st == nil || (st.loading && len(st.Threads) == 0):
+		sb.WriteString(dimText("Loading review threads…"))
+	case st.Err != nil && len(st.Threads) == 0:
+		sb.WriteString(styles.StatusDeleted.Render("Could not read review threads"))
+		sb.WriteString("\n")
+		sb.WriteString(dimText(p.truncateCache.Truncate(st.Err.Error(), inner, "…")))
+	case len(st.Threads) == 0:
+		sb.WriteString(dimText("No review threads"))
+	default:
+		sb.WriteString(p.reviewThreadList(st, inner))
+		sb.WriteString("\n\n")
+		sb.WriteString(p.reviewThreadDetail(*thread, inner))
+	}
+
+	sb.WriteString("\n\n")
+	if p.reviewReplying {
+		p.reviewReplyInput.SetWidth(inner - 2)
+		sb.WriteString(p.reviewReplyInput.View())
+		sb.WriteString("\n")
+		sb.WriteString(dimText("enter post · esc cancel"))
+	} else {
+		sb.WriteString(dimText("r reply · x resolve · a send to agent · o open · R refresh · esc close"))
+	}
+
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(styles.Primary).
+		Padding(1, 2).
+		Width(modalWidth)
+	return ui.OverlayModal(background, modalStyle.Render(sb.String()), p.width, p.height)
+}
+
+// reviewThreadList is a window of the thread list around the cursor.
+func (p *Plugin) reviewThreadList(st *reviewState, width int) string {
+	start := max(0, min(p.reviewCursor-maxReviewListRows/2, len(st.Threads)-maxReviewListRows))
+	end := min(len(st.Threads), start+maxReviewListRows)
+	var lines []string
+	for i := start; i < end; i++ {
+		t := st.Threads[i]
+		mark := "○"
+		if t.Resolved {
+			mark = "✓"
+		}
+		line := fmt.Sprintf("%s %s  @%s", mark, reviewThreadLocation(t), t.Author())
+		line = p.truncateCache.Truncate(line, width, "…")
+		switch {
+		case i == p.reviewCursor:
+			line = styles.ListItemSelected.Render(line)
+		case t.Resolved || t.Outdated:
+			line = styles.Muted.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	if hidden := len(st.Threads) - (end - start); hidden > 0 {
+		lines = append(lines, dimText(fmt.Sprintf("  %d of %d threads", end-start, len(st.Threads))))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// reviewThreadDetail is the selected thread's conversation.
+func (p *Plugin) reviewThreadDetail(t forge.ReviewThread, width int) string {
+	lines := []string{lipgloss.NewStyle().Bold(true).Render(p.truncateCache.Truncate(reviewThreadLocation(t), width, "…"))}
+	for _, c := range t.Comments {
+		lines = append(lines, styles.Muted.Render("@"+c.Author))
+		for _, l := range strings.Split(lipgloss.NewStyle().Width(width-2).Render(strings.TrimSpace(c.Body)), "\n") {
+			lines = append(lines, "  "+l)
+		}
+	}
+	if len(lines) > maxReviewDetailRows {
+		lines = append(lines[:maxReviewDetailRows-1], dimText("  … o opens the thread"))
+	}
+	return strings.Join(lines, "\n")
+}
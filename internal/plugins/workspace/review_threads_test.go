@@ -0,0 +1,103 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/marcus/sidecar/internal/forge"
+	"github.com/marcus/sidecar/internal/plugin"
+)
+
+func reviewedPlugin(t *testing.T) (*Plugin, *Worktree) {
+	t.Helper()
+	p := New()
+	p.ctx = &plugin.Context{Epoch: 1, WorkDir: t.TempDir()}
+	p.width, p.height = 120, 40
+	wt := &Worktree{Name: "feature", Path: t.TempDir(), Branch: "feature", PRURL: "https://github.com/o/r/pull/7"}
+	p.worktrees = []*Worktree{wt}
+	p.reviews = map[string]*reviewState{wt.IdentityKey(): {
+		Repo:   forge.Repo{Kind: forge.GitHub, Host: "github.com", Path: "o/r"},
+		Number: 7,
+		Threads: []forge.ReviewThread{
+			{ID: "RT_1", Path: "main.go", Line: 12, Comments: []forge.ReviewComment{
+				{Author: "ann", Body: "rename\nthis"}, {Author: "me", Body: "why?"},
+			}},
+			{ID: "RT_2", Path: "main.go", Line: 3, OnOld: true, Resolved: true, Comments: []forge.ReviewComment{{Author: "bo", Body: "gone"}}},
+			{ID: "RT_3", Path: "main.go", Line: 40, Outdated: true, Comments: []forge.ReviewComment{{Author: "bo", Body: "stale"}}},
+			{ID: "RT_4", Path: "other.go", Line: 1, Comments: []forge.ReviewComment{{Author: "bo", Body: "elsewhere"}}},
+		},
+	}}
+	return p, wt
+}
+
+func TestReviewNotesAnchorCurrentThreadsToTheirFile(t *testing.T) {
+	p, wt := reviewedPlugin(t)
+	notes := p.reviewNotes(wt.Path, "main.go")
+	if len(notes) != 2 {
+		t.Fatalf("notes = %+v", notes)
+	}
+	if n := notes[0]; n.Line != 12 || n.Old || n.Dim || n.Header != "💬 @ann · 1 reply" || !strings.Contains(n.Body, "↳ @me: why?") {
+		t.Fatalf("open thread note = %+v", n)
+	}
+	if n := notes[1]; n.Line != 3 || !n.Old || !n.Dim || !strings.HasSuffix(n.Header, "· resolved") {
+		t.Fatalf("resolved thread note = %+v", n)
+	}
+	if notes := p.reviewNotes("/elsewhere", "main.go"); notes != nil {
+		t.Fatalf("unknown worktree notes = %+v", notes)
+	}
+}
+
+func TestReviewAgentPromptIsOneLineWithItsLocation(t *testing.T) {
+	p, wt := reviewedPlugin(t)
+	got := reviewAgentPrompt(p.reviews[wt.IdentityKey()].Threads[0])
+	want := "Address this review comment on main.go:12 from @ann: rename this Reply from @me: why?"
+	if got != want {
+		t.Fatalf("prompt = %q, want %q", got, want)
+	}
+	if got := reviewAgentPrompt(p.reviews[wt.IdentityKey()].Threads[1]); !strings.Contains(got, "main.go:3 (removed line)") {
+		t.Fatalf("removed-line prompt = %q", got)
+	}
+}
+
+func TestReviewModalRepliesThroughTheForge(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("FAKE_GH_LOG", log)
+	installFakeGH(t, `printf '%s\n' "$*" >> "$FAKE_GH_LOG"`)
+	p, wt := reviewedPlugin(t)
+	p.reviewKey = wt.IdentityKey()
+	p.viewMode = ViewModeReviewThreads
+
+	if view := ansi.Strip(p.renderReviewThreadsModal("")); !strings.Contains(view, "main.go:12  @ann") || !strings.Contains(view, "#7") {
+		t.Fatalf("modal = %s", view)
+	}
+	p.handleReviewThreadsKeys(tea.KeyPressMsg{Code: 'r', Text: "r"})
+	if !p.reviewReplying || !p.ConsumesTextInput() {
+		t.Fatal("r did not open the reply input")
+	}
+	p.reviewReplyInput.SetValue("done")
+	cmd := p.handleReviewThreadsKeys(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if p.reviewReplying || cmd == nil {
+		t.Fatal("enter did not post the reply")
+	}
+	msg, ok := cmd().(ReviewActionMsg)
+	if !ok || msg.Err != nil || msg.WorktreeKey != wt.IdentityKey() {
+		t.Fatalf("action = %+v", msg)
+	}
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if call := string(data); !strings.Contains(call, "addPullRequestReviewThreadReply") || !strings.Contains(call, "-f thread=RT_1 -f body=done") {
+		t.Fatalf("gh call = %q", call)
+	}
+
+	p.handleReviewThreadsKeys(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if p.viewMode != ViewModeList {
+		t.Fatalf("esc left view mode %v", p.viewMode)
+	}
+}
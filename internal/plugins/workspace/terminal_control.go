@@ -137,6 +137,9 @@ func (p *Plugin) Update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 	if cmd := p.notifyAgentTransitions(time.Now()); cmd != nil {
 		cmds = append(cmds, cmd)
 	}
+	if cmd := p.publishControlAPI(); cmd != nil {
+		cmds = append(cmds, cmd)
+	}
 	return p, tea.Batch(cmds...)
 }
 
@@ -31,6 +31,8 @@ const (
 	ViewModeInteractive                        // Interactive mode (tmux input passthrough)
 	ViewModeFetchPR                            // Fetch remote PR modal
 	ViewModeAgentConfig                        // Agent config modal (start/restart with options)
+	ViewModeConflictMatrix                     // Predicted merge conflicts across worktrees
+	ViewModeReviewThreads                      // Pull request review threads of a worktree
 )
 
 // FocusPane represents which pane is active in the split view.
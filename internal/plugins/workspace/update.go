@@ -222,7 +222,7 @@ func (p *Plugin) update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 
 			p.repoSnapshot = msg.Snapshot
 			p.worktrees = msg.Worktrees
-			p.conflicts = msg.Conflicts
+			p.setOverlapConflicts(msg.Conflicts)
 			p.worktreesLoaded = true
 			p.rebuildNestedShellsFromState()
 			if cmd := p.backfillWorkDirsCmd(); cmd != nil {
@@ -285,11 +285,66 @@ func (p *Plugin) update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 			startValidation := !p.initialReconnectDone
 			ownership := p.currentTerminalOwnership()
 			cmds = append(cmds, p.reconnectAgents(msg.OperationScope, startValidation, ownership))
+
+			// Base branches were loaded above; prediction needs them.
+			if cmd := p.maybePredictConflicts(); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			if cmd := p.maybeRefreshCI(); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
 		}
 
 	case ConflictsDetectedMsg:
+		if plugin.IsStale(p.ctx, msg) || msg.OperationID != p.conflictOperationID || !p.scopeMatches(msg.OperationScope) {
+			return p, nil
+		}
+		p.conflictsPredicting = false
 		if msg.Err == nil {
 			p.conflicts = msg.Conflicts
+			p.conflictsPredicted = msg.Predicted
+			p.conflictsUntracked = make(map[string]bool, len(msg.Untracked))
+			for _, key := range msg.Untracked {
+				p.conflictsUntracked[key] = true
+			}
+		} else if !errors.Is(msg.Err, errMergeTreeUnsupported) {
+			// Try again on the next status change rather than never.
+			p.conflictFingerprint = ""
+		}
+
+	case CIStatusMsg:
+		if plugin.IsStale(p.ctx, msg) || !p.scopeMatches(msg.OperationScope) {
+			delete(p.ciInFlight, msg.WorktreeKey)
+			return p, nil
+		}
+		if cmd := p.applyCIStatus(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		if p.mergeState != nil && p.mergeState.Worktree != nil && p.mergeState.Worktree.IdentityKey() == msg.WorktreeKey {
+			p.clearMergeModal()
+		}
+
+	case ReviewThreadsMsg:
+		if plugin.IsStale(p.ctx, msg) || !p.scopeMatches(msg.OperationScope) {
+			return p, nil
+		}
+		p.applyReviewThreads(msg)
+
+	case ReviewActionMsg:
+		if plugin.IsStale(p.ctx, msg) || !p.scopeMatches(msg.OperationScope) {
+			return p, nil
+		}
+		if cmd := p.applyReviewAction(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case ciTickMsg:
+		if plugin.IsStale(p.ctx, msg) {
+			return p, nil
+		}
+		p.ciTicking = false
+		if cmd := p.maybeRefreshCI(); cmd != nil {
+			cmds = append(cmds, cmd)
 		}
 
 	case StatsLoadedMsg:
@@ -299,6 +354,9 @@ func (p *Plugin) update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		}
 		if wt := p.findWorktree(msg.WorktreeKey); wt != nil {
 			wt.Stats = msg.Stats
+			if cmd := p.maybePredictConflicts(); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
 		}
 
 	case StatsErrorMsg:
@@ -656,7 +714,7 @@ func (p *Plugin) update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 	case PushDoneMsg:
 		// Handle push result notification
 		if msg.Err == nil {
-			cmds = append(cmds, p.refreshWorktrees())
+			cmds = append(cmds, p.refreshWorktrees(), p.refreshCINow(p.findWorktree(msg.WorkspaceName)))
 		}
 
 	// Agent messages
@@ -1639,7 +1697,10 @@ func (p *Plugin) update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 		if wt != nil && msg.Changes != nil {
 			wt.Changes = msg.Changes
 			wt.Stats = msg.Stats
-			p.conflicts = detectConflictsFromChanges(p.worktrees)
+			p.setOverlapConflicts(detectConflictsFromChanges(p.worktrees))
+			if cmd := p.maybePredictConflicts(); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
 		}
 		if msg.Err != nil {
 			// Error checking changes - cancel merge and return to list
@@ -1720,6 +1781,9 @@ func (p *Plugin) update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 						wt.PRURL = msg.Data
 						wt.PRState = normalizeWorktreePRState(msg.PR.State, true)
 						_ = savePRIdentityContext(p.operationCtx, p.ctx.ProjectRoot, wt.Path, msg.PR)
+						// The checks of the commit just pushed are the ones
+						// the waiting step shows.
+						cmds = append(cmds, p.refreshCINow(wt))
 					}
 					switch msg.PR.State {
 					case "CLOSED":
@@ -1749,7 +1813,7 @@ func (p *Plugin) update(msg tea.Msg) (plugin.Plugin, tea.Cmd) {
 
 	case PRGenerationDoneMsg:
 		if p.mergeState != nil && p.mergeState.Worktree.Name == msg.WorkspaceName {
-			// Always stop at an editable form before creating anything on GitHub.
+			// Always stop at an editable form before creating anything on the forge.
 			p.mergeState.PRTitle = msg.Title
 			p.mergeState.PRBody = msg.Body
 			p.mergeState.PRGenerationActive = false
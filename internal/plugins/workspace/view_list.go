@@ -95,6 +95,12 @@ func (p *Plugin) View(width, height int) string {
 	case ViewModeFilePicker:
 		background := p.renderListView(width, height)
 		view = p.renderFilePickerModal(background)
+	case ViewModeConflictMatrix:
+		background := p.renderListView(width, height)
+		view = p.renderConflictMatrixModal(background)
+	case ViewModeReviewThreads:
+		background := p.renderListView(width, height)
+		view = p.renderReviewThreadsModal(background)
 	default:
 		view = p.renderListView(width, height)
 		if p.docInfo != nil {
@@ -297,6 +303,9 @@ func (p *Plugin) renderWorktreeItemKind(wt *Worktree, selected bool, width int,
 	if hasPR {
 		nameMeta = append(nameMeta, workspacelist.RowField{Text: " PR", Rendered: lipgloss.NewStyle().Foreground(styles.Secondary).Render(" PR")})
 	}
+	if glyph, render := p.ciBadge(wt); glyph != "" {
+		nameMeta = append(nameMeta, workspacelist.RowField{Text: " " + glyph, Rendered: render(" " + glyph)})
+	}
 	if hasConflict {
 		nameMeta = append(nameMeta, workspacelist.RowField{Text: " ⚠", Rendered: styles.StatusModified.Render(" ⚠")})
 	}
@@ -332,7 +341,10 @@ func (p *Plugin) renderWorktreeItemKind(wt *Worktree, selected bool, width int,
 	}
 	if hasConflict {
 		conflictFiles := p.getConflictingFiles(wt.IdentityKey(), p.conflicts)
-		if len(conflictFiles) > 0 {
+		if n := conflictHunkCount(wt.IdentityKey(), p.conflicts); p.conflictsPredicted && n > 0 {
+			label := fmt.Sprintf("⚠ %d conflicts", n)
+			after = append(after, workspacelist.RowField{Text: label, Rendered: styles.StatusModified.Render(label)})
+		} else if len(conflictFiles) > 0 {
 			label := fmt.Sprintf("⚠ %d dirty overlaps", len(conflictFiles))
 			after = append(after, workspacelist.RowField{Text: label, Rendered: styles.StatusModified.Render(label)})
 		}
@@ -384,6 +396,9 @@ func (p *Plugin) worktreeStateLabels(wt *Worktree) []string {
 	} else if wt.PRURL != "" {
 		labels = append(labels, "PR unavailable")
 	}
+	if label := ciLabel(p.ciStatus[wt.IdentityKey()]); label != "" && ciTracked(wt) {
+		labels = append(labels, label)
+	}
 	if wt.Changes != nil {
 		switch wt.Changes.State {
 		case LoadStateError:
@@ -2,10 +2,12 @@ package workspace
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"charm.land/lipgloss/v2"
 	"github.com/charmbracelet/x/ansi"
+	"github.com/marcus/sidecar/internal/forge"
 	"github.com/marcus/sidecar/internal/modal"
 	"github.com/marcus/sidecar/internal/styles"
 	"github.com/marcus/sidecar/internal/ui"
@@ -559,7 +561,7 @@ func (p *Plugin) ensureMergeModal() {
 		}
 
 	case MergeStepEditPR:
-		m.AddSection(modal.Text("Review and edit everything GitHub will receive."))
+		m.AddSection(modal.Text("Review and edit everything the forge will receive."))
 		m.AddSection(modal.Text(fmt.Sprintf("%s → %s:%s", p.mergeState.PR.HeadRef, p.mergeState.PR.Repository, p.mergeState.TargetBranch)))
 		m.AddSection(modal.Spacer())
 		m.AddSection(modal.InputWithLabel("merge-pr-title", "Title", &p.mergeState.PRTitleInput))
@@ -591,7 +593,7 @@ func (p *Plugin) ensureMergeModal() {
 			m.AddSection(modal.Text(dimText("  Required after squash/rebase: Git cannot prove the reviewed head is an ancestor.")))
 		}
 		m.AddSection(modal.Checkbox(mergeConfirmRemoteID, "Delete remote branch", &p.mergeState.DeleteRemoteBranch))
-		m.AddSection(modal.Text(dimText("  Removes from GitHub (often auto-deleted)")))
+		m.AddSection(modal.Text(dimText("  Removes from the remote (often auto-deleted)")))
 		m.AddSection(modal.Spacer())
 		m.AddSection(modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
 			return modal.RenderedSection{Content: strings.Repeat("─", min(contentWidth, 60))}
@@ -774,7 +776,7 @@ func (p *Plugin) mergeMethodHintsSection() modal.Section {
 		var sb strings.Builder
 
 		if p.mergeState.MergeMethodOption == 0 {
-			sb.WriteString(dimText("Push the reviewed commit to the resolved remote and create a GitHub PR"))
+			sb.WriteString(dimText("Push the reviewed commit to the resolved remote and open a pull request"))
 		} else {
 			sb.WriteString(dimText(fmt.Sprintf("Merge directly to '%s' without PR", p.mergeState.TargetBranch)))
 			sb.WriteString("\n")
@@ -828,7 +830,11 @@ func (p *Plugin) mergeWaitingSection() modal.Section {
 		if !p.mergeState.PRWatchStopped {
 			sb.WriteString("Watching pull request by repository and number...")
 		}
-		sb.WriteString("\n\n")
+		sb.WriteString("\n")
+		if wt := p.mergeState.Worktree; wt != nil {
+			sb.WriteString(p.mergeCIStatusLines(wt, contentWidth))
+		}
+		sb.WriteString("\n")
 		sb.WriteString(strings.Repeat("─", min(contentWidth, 60)))
 		sb.WriteString("\n\n")
 
@@ -854,6 +860,47 @@ func (p *Plugin) mergeWaitingSection() modal.Section {
 	}, nil)
 }
 
+// mergeCIStatusLines lists the checks of the pushed commit under the
+// waiting step, failed ones first.
+func (p *Plugin) mergeCIStatusLines(wt *Worktree, contentWidth int) string {
+	st := p.ciStatus[wt.IdentityKey()]
+	if st == nil {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n")
+	switch {
+	case st.State != "":
+		glyph, render := p.ciBadge(wt)
+		line := glyph + " " + ciLabel(st)
+		if render != nil {
+			line = render(line)
+		}
+		sb.WriteString(line + "\n")
+	case st.Err != nil:
+		sb.WriteString(dimText("CI: "+ui.TruncateString(st.Err.Error(), max(contentWidth-4, 10))) + "\n")
+		return sb.String()
+	default:
+		sb.WriteString(dimText("CI: no checks reported yet") + "\n")
+		return sb.String()
+	}
+	checks := slices.Clone(st.Checks)
+	rank := map[forge.CheckState]int{forge.CheckFailed: 0, forge.CheckRunning: 1, forge.CheckQueued: 2, forge.CheckPassed: 3}
+	slices.SortStableFunc(checks, func(a, b forge.Check) int { return rank[a.State] - rank[b.State] })
+	const shown = 5
+	for i, c := range checks {
+		if i == shown {
+			sb.WriteString(dimText(fmt.Sprintf("  … %d more", len(checks)-shown)) + "\n")
+			break
+		}
+		sb.WriteString(ui.TruncateString(fmt.Sprintf("  %-7s %s", c.State, c.Name), max(contentWidth, 10)) + "\n")
+	}
+	if _, failed := st.failedCheck(); failed {
+		sb.WriteString(dimText("  L closes this and opens the failed job's log") + "\n")
+	}
+	return sb.String()
+}
+
 // mergePostMergeHeaderSection renders the header for post-merge confirmation.
 func (p *Plugin) mergePostMergeHeaderSection() modal.Section {
 	return modal.Custom(func(contentWidth int, focusID, hoverID string) modal.RenderedSection {
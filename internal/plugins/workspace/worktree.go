@@ -23,6 +23,7 @@ import (
 	"github.com/marcus/sidecar/internal/startuptrace"
 	"github.com/marcus/sidecar/internal/workspaceops"
 	"github.com/marcus/sidecar/internal/worktreedelete"
+	"github.com/marcus/sidecar/internal/worktreestatus"
 )
 
 const maxRefreshConcurrency = 4
@@ -35,7 +36,7 @@ type WorkDirDeletedMsg struct {
 
 // refreshWorktrees returns a command to refresh the worktree list.
 func (p *Plugin) refreshWorktrees() tea.Cmd {
-	workDir := p.ctx.WorkDir
+	workDir, projectRoot := p.ctx.WorkDir, p.ctx.ProjectRoot
 	ctx, scope := p.newOperationScope(nil)
 	p.refreshOperationID = scope.OperationID
 	return func() tea.Msg {
@@ -60,12 +61,37 @@ func (p *Plugin) refreshWorktrees() tea.Cmd {
 				Duration: time.Since(started), Processes: int(processes.Load())}
 		}
 		maxConcurrent := loadRefreshChanges(refreshCtx, worktrees, maxRefreshConcurrency, nil)
+		recordWorktreeStatus(projectRoot, worktrees)
 		conflicts := detectConflictsFromChanges(worktrees)
 		return RefreshDoneMsg{OperationScope: scope, Worktrees: worktrees, Snapshot: snapshot,
 			Conflicts: conflicts, Duration: time.Since(started), Processes: int(processes.Load()), MaxConcurrency: maxConcurrent}
 	}
 }
 
+// recordWorktreeStatus writes the counts this refresh just read for
+// `sidecar status`. A worktree whose status failed is left out rather than
+// reported clean. Failure is silent: the store only spares readers a git run.
+func recordWorktreeStatus(projectRoot string, worktrees []*Worktree) {
+	dir, ok := projectdir.Lookup(projectRoot)
+	if !ok {
+		return
+	}
+	records := make([]worktreestatus.Worktree, 0, len(worktrees))
+	for _, wt := range worktrees {
+		if wt.Changes == nil || wt.Changes.State == LoadStateError {
+			continue
+		}
+		records = append(records, worktreestatus.Worktree{
+			Path:      wt.Path,
+			Branch:    wt.Branch,
+			Staged:    len(wt.Changes.Staged),
+			Unstaged:  len(wt.Changes.Unstaged),
+			Untracked: len(wt.Changes.Untracked),
+		})
+	}
+	_ = worktreestatus.Save(worktreestatus.Path(dir), records, time.Now())
+}
+
 func loadRefreshChanges(ctx context.Context, worktrees []*Worktree, limit int, processes *atomic.Int64) int {
 	if limit < 1 {
 		limit = 1
@@ -39,6 +39,17 @@ const (
 // rather than every host wrapping the resolver to do the same thing.
 type Resolver func(modelID int, generation, epoch uint64, ref resource.Reference, refresh bool) tea.Cmd
 
+// builtins holds the resolvers of provider instances Sidecar answers itself.
+// Their references never reach the host's resolver, so they resolve before
+// provider setup has injected one.
+var builtins = map[string]Resolver{}
+
+// RegisterBuiltin routes every reference under instance to resolve instead
+// of the host's resolver. Call it from init.
+func RegisterBuiltin(instance string, resolve Resolver) {
+	builtins[instance] = resolve
+}
+
 // ResolvedMsg is the result of one resolve. Its identity fields are what stop
 // a late answer from landing in a closed, retargeted, or foreign tab: the host
 // adds its own surface and epoch scoping on top.
@@ -219,12 +230,16 @@ func (m *Model) Refresh() tea.Cmd {
 }
 
 func (m *Model) request(refresh bool) tea.Cmd {
-	if m.resolve == nil || !m.ref.Valid() {
+	resolve := m.resolve
+	if builtin := builtins[m.ref.Instance]; builtin != nil {
+		resolve = builtin
+	}
+	if resolve == nil || !m.ref.Valid() {
 		m.applyError(resource.Errorf(resource.CodeInvalidRequest,
 			"this resource reference is not something Sidecar can resolve"))
 		return nil
 	}
-	return m.resolve(m.modelID, m.generation, m.epoch, m.ref, refresh)
+	return resolve(m.modelID, m.generation, m.epoch, m.ref, refresh)
 }
 
 // Accepts reports whether a result belongs to this model's current request.
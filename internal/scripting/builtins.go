@@ -0,0 +1,313 @@
+package scripting
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+
+	"github.com/marcus/sidecar/internal/gitstate"
+	"github.com/marcus/sidecar/internal/notify"
+	"github.com/marcus/sidecar/internal/workspaceops"
+)
+
+// module is the predeclared `sidecar` module. Registration (command, on) is
+// only allowed while a script loads; actions only once it has.
+func (e *Engine) module() *starlarkstruct.Module {
+	return &starlarkstruct.Module{
+		Name: "sidecar",
+		Members: starlark.StringDict{
+			"command":    starlark.NewBuiltin("command", e.command),
+			"on":         starlark.NewBuiltin("on", e.on),
+			"workspaces": starlark.NewBuiltin("workspaces", e.workspacesBuiltin),
+			"project":    starlark.NewBuiltin("project", e.projectBuiltin),
+			"git_status": starlark.NewBuiltin("git_status", e.gitStatus),
+			"commit":     starlark.NewBuiltin("commit", e.commit),
+			"send_keys":  starlark.NewBuiltin("send_keys", e.sendKeysBuiltin),
+			"notify":     starlark.NewBuiltin("notify", e.notify),
+			"flash":      starlark.NewBuiltin("flash", e.flash),
+		},
+	}
+}
+
+func loading(thread *starlark.Thread, b *starlark.Builtin) error {
+	if c := callOf(thread); c == nil || !c.loading {
+		return fmt.Errorf("sidecar.%s: only allowed while the script loads", b.Name())
+	}
+	return nil
+}
+
+func running(thread *starlark.Thread, b *starlark.Builtin) (*call, error) {
+	c := callOf(thread)
+	if c == nil || c.loading {
+		return nil, fmt.Errorf("sidecar.%s: not allowed while the script loads", b.Name())
+	}
+	return c, nil
+}
+
+func (e *Engine) command(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := loading(thread, b); err != nil {
+		return nil, err
+	}
+	var id, name, description, prompt string
+	var fn starlark.Callable
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "id", &id, "fn", &fn, "name?", &name, "description?", &description, "prompt?", &prompt); err != nil {
+		return nil, err
+	}
+	id = strings.TrimSpace(id)
+	if id == "" || strings.ContainsAny(id, " \t\n") {
+		return nil, fmt.Errorf("sidecar.command: id %q must be a single word", id)
+	}
+	if existing, ok := e.Command(id); ok {
+		return nil, fmt.Errorf("sidecar.command: %q is already registered by %s", id, existing.Script)
+	}
+	if name == "" {
+		name = id
+	}
+	e.commands = append(e.commands, Command{
+		ID:          id,
+		Name:        name,
+		Description: description,
+		Prompt:      prompt,
+		Script:      callOf(thread).script,
+		fn:          fn,
+	})
+	return starlark.None, nil
+}
+
+func (e *Engine) on(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := loading(thread, b); err != nil {
+		return nil, err
+	}
+	var event string
+	var fn starlark.Callable
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "event", &event, "fn", &fn); err != nil {
+		return nil, err
+	}
+	if !slices.Contains(events, event) {
+		return nil, fmt.Errorf("sidecar.on: unknown event %q (want one of %s)", event, strings.Join(events, ", "))
+	}
+	e.hooks = append(e.hooks, hook{event: event, script: callOf(thread).script, fn: fn})
+	return starlark.None, nil
+}
+
+func (e *Engine) workspacesBuiltin(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs); err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := make([]starlark.Value, 0, len(e.workspaces))
+	for _, ws := range e.workspaces {
+		out = append(out, workspaceValue(ws))
+	}
+	return starlark.NewList(out), nil
+}
+
+func (e *Engine) projectBuiltin(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs); err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.root == "" {
+		return starlark.None, nil
+	}
+	return starlark.String(e.root), nil
+}
+
+func (e *Engine) gitStatus(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	c, err := running(thread, b)
+	if err != nil {
+		return nil, err
+	}
+	var path string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "path?", &path); err != nil {
+		return nil, err
+	}
+	dir, err := e.resolvePath(b, path, false)
+	if err != nil {
+		return nil, err
+	}
+	s, err := gitstate.Load(c.ctx, dir)
+	if err != nil {
+		return nil, fmt.Errorf("sidecar.git_status: %s: %w", dir, err)
+	}
+	return statusValue(s), nil
+}
+
+// commit stages (with all=True, everything) and commits in one worktree. It
+// returns False rather than failing when there was nothing to commit, so a
+// loop over every worktree does not stop at the first clean one.
+func (e *Engine) commit(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	c, err := running(thread, b)
+	if err != nil {
+		return nil, err
+	}
+	var path, message string
+	all := true
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "path", &path, "message", &message, "all?", &all); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(message) == "" {
+		return nil, fmt.Errorf("sidecar.commit: message is empty")
+	}
+	dir, err := e.resolvePath(b, path, true)
+	if err != nil {
+		return nil, err
+	}
+	if all {
+		if err := git(c.ctx, dir, "add", "-A"); err != nil {
+			return nil, fmt.Errorf("sidecar.commit: %w", err)
+		}
+	}
+	// --quiet exits 1 exactly when something is staged.
+	if err := git(c.ctx, dir, "diff", "--cached", "--quiet"); err == nil {
+		return starlark.False, nil
+	}
+	if err := git(c.ctx, dir, "commit", "-m", message); err != nil {
+		return nil, fmt.Errorf("sidecar.commit: %w", err)
+	}
+	return starlark.True, nil
+}
+
+func (e *Engine) sendKeysBuiltin(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	c, err := running(thread, b)
+	if err != nil {
+		return nil, err
+	}
+	var session, text string
+	var enter bool
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "session", &session, "text?", &text, "enter?", &enter); err != nil {
+		return nil, err
+	}
+	if text == "" && !enter {
+		return nil, fmt.Errorf("sidecar.send_keys: needs text, enter=True, or both")
+	}
+	if !e.knownSession(session) {
+		return nil, fmt.Errorf("sidecar.send_keys: %q is not a session of a workspace sidecar is showing", session)
+	}
+	var strokes []notify.Keystroke
+	if text != "" {
+		strokes = append(strokes, notify.Keystroke{Keys: text, Literal: true})
+	}
+	if enter {
+		strokes = append(strokes, notify.Keystroke{Keys: "Enter"})
+	}
+	if err := e.sendKeys(c.ctx, session, strokes); err != nil {
+		return nil, fmt.Errorf("sidecar.send_keys: %w", err)
+	}
+	return starlark.None, nil
+}
+
+func (e *Engine) notify(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	c, err := running(thread, b)
+	if err != nil {
+		return nil, err
+	}
+	var title, body string
+	severity, source := string(notify.SeverityInfo), string(notify.SourceAgent)
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "title", &title, "body?", &body, "severity?", &severity, "source?", &source); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(title) == "" {
+		return nil, fmt.Errorf("sidecar.notify: title is empty")
+	}
+	switch notify.Severity(severity) {
+	case notify.SeverityInfo, notify.SeverityWarning, notify.SeverityError:
+	default:
+		return nil, fmt.Errorf("sidecar.notify: unknown severity %q", severity)
+	}
+	if !notify.ValidSource(notify.SourceID(source)) {
+		return nil, fmt.Errorf("sidecar.notify: unknown source %q (want one of %s)", source, strings.Join(notify.SourceIDs(), ", "))
+	}
+	n := notify.Notification{
+		ID:       notify.NewID(),
+		Source:   notify.SourceID(source),
+		Severity: notify.Severity(severity),
+		Title:    title,
+		Body:     body,
+	}
+	e.mu.Lock()
+	e.posted[n.ID] = true
+	e.mu.Unlock()
+	c.notifications = append(c.notifications, n)
+	return starlark.String(n.ID), nil
+}
+
+func (e *Engine) flash(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	c, err := running(thread, b)
+	if err != nil {
+		return nil, err
+	}
+	var message string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "message", &message); err != nil {
+		return nil, err
+	}
+	c.flash = message
+	return starlark.None, nil
+}
+
+// resolvePath checks a script's path against what the app is showing: the
+// project root or a workspace, exactly, or anywhere beneath one unless exact.
+// An empty path is the project root.
+func (e *Engine) resolvePath(b *starlark.Builtin, path string, exact bool) (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if path == "" {
+		path = e.root
+	}
+	if path == "" {
+		return "", fmt.Errorf("sidecar.%s: no project is open", b.Name())
+	}
+	if !filepath.IsAbs(path) {
+		return "", fmt.Errorf("sidecar.%s: %q is not an absolute path", b.Name(), path)
+	}
+	path = filepath.Clean(path)
+	roots := []string{e.root}
+	for _, ws := range e.workspaces {
+		roots = append(roots, ws.Path)
+	}
+	for _, root := range roots {
+		if root == "" {
+			continue
+		}
+		if filepath.Clean(root) == path || (!exact && workspaceops.PathRootedIn(path, root)) {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("sidecar.%s: %s is not a workspace sidecar is showing", b.Name(), path)
+}
+
+func (e *Engine) knownSession(session string) bool {
+	if session == "" {
+		return false
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, ws := range e.workspaces {
+		if ws.Session == session {
+			return true
+		}
+	}
+	return false
+}
+
+// git runs one git command, folding its output into the error.
+func git(ctx context.Context, dir string, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package scripting
+
+import (
+	"path/filepath"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+
+	"github.com/marcus/sidecar/internal/agentstatus"
+	"github.com/marcus/sidecar/internal/controlapi"
+	"github.com/marcus/sidecar/internal/gitstate"
+	"github.com/marcus/sidecar/internal/notify"
+)
+
+// The Observe methods are how the app tells the engine what happened. Each
+// records whatever the queries need, and returns a command running the hooks
+// for the event, or nil when no script listens. Like the lane tracker, the
+// first sight of anything is a baseline: starting sidecar is not an event.
+
+// ObserveWorkspaces adopts the workspace plugin's latest publication and
+// reports each workspace added, removed or changed since the previous one. A
+// different project root starts a new baseline.
+func (e *Engine) ObserveWorkspaces(state controlapi.WorkspacesState) tea.Cmd {
+	if e == nil {
+		return nil
+	}
+	e.mu.Lock()
+	prior, sameRoot := e.workspaces, e.published && state.Root == e.root
+	e.published = true
+	e.root = state.Root
+	e.workspaces = append([]controlapi.Workspace(nil), state.Workspaces...)
+	if !sameRoot {
+		clear(e.gitSeen)
+	}
+	e.mu.Unlock()
+	if !sameRoot || !e.listens(EventWorkspace) {
+		return nil
+	}
+
+	before := make(map[string]controlapi.Workspace, len(prior))
+	for _, ws := range prior {
+		before[workspaceKey(ws)] = ws
+	}
+	var cmds []tea.Cmd
+	for _, ws := range state.Workspaces {
+		key := workspaceKey(ws)
+		old, ok := before[key]
+		delete(before, key)
+		switch {
+		case !ok:
+			cmds = append(cmds, e.dispatch(EventWorkspace, workspaceEvent("added", ws, nil)))
+		case old != ws:
+			cmds = append(cmds, e.dispatch(EventWorkspace, workspaceEvent("changed", ws, &old)))
+		}
+	}
+	for _, ws := range prior {
+		if _, gone := before[workspaceKey(ws)]; gone {
+			cmds = append(cmds, e.dispatch(EventWorkspace, workspaceEvent("removed", ws, nil)))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// workspaceKey is a workspace's identity across publications: a worktree is
+// its path, a shell its session.
+func workspaceKey(ws controlapi.Workspace) string {
+	if ws.Kind == "shell" {
+		return "shell:" + ws.Session
+	}
+	return ws.Kind + ":" + ws.Path
+}
+
+// ObserveAgent reports one settled agent lane change.
+func (e *Engine) ObserveAgent(t notify.LaneTransition) tea.Cmd {
+	if !e.listens(EventAgent) {
+		return nil
+	}
+	return e.dispatch(EventAgent, frozen(starlark.StringDict{
+		"key":      starlark.String(t.Key),
+		"label":    starlark.String(t.Label),
+		"context":  starlark.String(t.Context),
+		"agent":    starlark.String(t.Provider),
+		"previous": starlark.String(t.Previous),
+		"lane":     starlark.String(t.Lane),
+		"failed":   starlark.Bool(t.Lane == agentstatus.LanePaused && t.Health),
+		"session":  starlark.String(t.Origin.TmuxSession),
+		"path":     starlark.String(t.Origin.WorkDir),
+	}))
+}
+
+// ObserveNotification reports a notification the app just stored. One a
+// script posted is not reported, so a hook cannot answer itself forever.
+func (e *Engine) ObserveNotification(n notify.Notification) tea.Cmd {
+	if e == nil {
+		return nil
+	}
+	e.mu.Lock()
+	own := e.posted[n.ID]
+	delete(e.posted, n.ID)
+	e.mu.Unlock()
+	if own || !e.listens(EventNotification) {
+		return nil
+	}
+	return e.dispatch(EventNotification, frozen(starlark.StringDict{
+		"id":       starlark.String(n.ID),
+		"source":   starlark.String(n.Source),
+		"severity": starlark.String(n.Severity),
+		"title":    starlark.String(n.Title),
+		"body":     starlark.String(n.Body),
+		"session":  starlark.String(n.Origin.TmuxSession),
+		"path":     starlark.String(n.Origin.WorkDir),
+	}))
+}
+
+// ObserveGit reports a repository whose status differs from the last snapshot
+// of it. The git plugin republishes on every refresh, changed or not, so the
+// engine keeps a fingerprint per root and stays quiet while it holds.
+func (e *Engine) ObserveGit(s *gitstate.Snapshot) tea.Cmd {
+	if e == nil || s == nil || s.Root == "" {
+		return nil
+	}
+	var b strings.Builder
+	for _, path := range s.Paths() {
+		b.WriteString(path)
+		b.WriteByte(byte('0' + s.File(path)))
+		b.WriteByte(0)
+	}
+	fingerprint := b.String()
+	e.mu.Lock()
+	prior, seen := e.gitSeen[s.Root]
+	e.gitSeen[s.Root] = fingerprint
+	e.mu.Unlock()
+	if !seen || prior == fingerprint || !e.listens(EventGit) {
+		return nil
+	}
+	return e.dispatch(EventGit, statusValue(s))
+}
+
+func workspaceEvent(change string, ws controlapi.Workspace, previous *controlapi.Workspace) starlark.Value {
+	var prev starlark.Value = starlark.None
+	if previous != nil {
+		prev = workspaceValue(*previous)
+	}
+	return frozen(starlark.StringDict{
+		"change":    starlark.String(change),
+		"workspace": workspaceValue(ws),
+		"previous":  prev,
+	})
+}
+
+func workspaceValue(ws controlapi.Workspace) starlark.Value {
+	return frozen(starlark.StringDict{
+		"kind":      starlark.String(ws.Kind),
+		"name":      starlark.String(ws.Name),
+		"path":      starlark.String(ws.Path),
+		"branch":    starlark.String(ws.Branch),
+		"session":   starlark.String(ws.Session),
+		"agent":     starlark.String(ws.Agent),
+		"main":      starlark.Bool(ws.Main),
+		"missing":   starlark.Bool(ws.Missing),
+		"orphaned":  starlark.Bool(ws.Orphaned),
+		"additions": starlark.MakeInt(ws.Additions),
+		"deletions": starlark.MakeInt(ws.Deletions),
+		"ahead":     starlark.MakeInt(ws.Ahead),
+		"behind":    starlark.MakeInt(ws.Behind),
+		"pr":        starlark.String(ws.PR),
+	})
+}
+
+// statusValue is a snapshot as a script sees it: the root, and each changed
+// file relative to it with its state names.
+func statusValue(s *gitstate.Snapshot) starlark.Value {
+	files := starlark.NewDict(s.Len())
+	for _, path := range s.Paths() {
+		rel, err := filepath.Rel(s.Root, path)
+		if err != nil {
+			rel = path
+		}
+		names := s.File(path).Names()
+		states := make([]starlark.Value, 0, len(names))
+		for _, name := range names {
+			states = append(states, starlark.String(name))
+		}
+		_ = files.SetKey(starlark.String(rel), starlark.NewList(states))
+	}
+	return frozen(starlark.StringDict{
+		"root":  starlark.String(s.Root),
+		"files": files,
+	})
+}
+
+// frozen builds a struct every hook can share: payloads cross goroutines.
+func frozen(fields starlark.StringDict) starlark.Value {
+	v := starlarkstruct.FromStringDict(starlarkstruct.Default, fields)
+	v.Freeze()
+	return v
+}
@@ -0,0 +1,310 @@
+// Package scripting runs the user's Starlark scripts. Each *.star file in the
+// scripts directory beside the config file is executed once at startup, and
+// may register palette commands and hooks through the predeclared `sidecar`
+// module:
+//
+//	def commit_all(message):
+//	    for ws in sidecar.workspaces():
+//	        if ws.kind == "worktree":
+//	            sidecar.commit(ws.path, message)
+//
+//	sidecar.command("commit-all", commit_all, name = "Commit all worktrees", prompt = "Commit message")
+//
+//	def on_agent(ev):
+//	    if ev.lane == "done":
+//	        sidecar.flash(ev.label + " finished")
+//
+//	sidecar.on("agent", on_agent)
+//
+// The sandbox is Starlark's own plus three rules: there is no load(), so a
+// script reaches nothing but the sidecar module; every call runs under a step
+// budget and a deadline; and the actions (commit, send_keys, notify, flash)
+// only reach workspaces the app is showing, and only once loading is over.
+//
+// The package knows nothing of the app shell. Commands and hooks run as
+// tea.Cmds on their own goroutines and report back in a ResultMsg, which the
+// app turns into notifications and flash messages.
+package scripting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+
+	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/controlapi"
+	"github.com/marcus/sidecar/internal/notify"
+	"github.com/marcus/sidecar/internal/workspaceops"
+)
+
+const (
+	// DefaultMaxSteps bounds one call's computation. It is generous for
+	// anything that loops over workspaces, and stops a runaway loop in well
+	// under a second.
+	DefaultMaxSteps = 10_000_000
+	// DefaultTimeout bounds one call's wall time, git and tmux included.
+	DefaultTimeout = 30 * time.Second
+)
+
+// Events a script can register a hook for with sidecar.on.
+const (
+	EventAgent        = "agent"
+	EventNotification = "notification"
+	EventGit          = "git"
+	EventWorkspace    = "workspace"
+)
+
+var events = []string{EventAgent, EventNotification, EventGit, EventWorkspace}
+
+// Dir returns the directory scripts are loaded from: `scripts` beside the
+// config file.
+func Dir() string {
+	path := config.ConfigPath()
+	if path == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(path), "scripts")
+}
+
+// Command is a palette command a script registered.
+type Command struct {
+	// ID is what the script named it; the app namespaces it for the keymap.
+	ID          string
+	Name        string
+	Description string
+	// Prompt, when set, asks the user for one line of text first and passes
+	// it to the function as its only argument.
+	Prompt string
+	// Script is the file that registered it.
+	Script string
+	fn     starlark.Callable
+}
+
+type hook struct {
+	event  string
+	script string
+	fn     starlark.Callable
+}
+
+// ResultMsg reports one finished command or hook.
+type ResultMsg struct {
+	// Label names what ran, for error messages: "commit-all.star: command
+	// commit-all".
+	Label string
+	// Notifications are what the script asked to post, ids assigned.
+	Notifications []notify.Notification
+	// Flash is the last sidecar.flash message, if any.
+	Flash string
+	Err   error
+}
+
+// Engine holds the loaded scripts and what they may see of the app.
+type Engine struct {
+	// MaxSteps and Timeout bound each call; zero uses the defaults.
+	MaxSteps uint64
+	Timeout  time.Duration
+
+	// sendKeys types into a tmux session. A test swaps it out.
+	sendKeys func(ctx context.Context, session string, strokes []notify.Keystroke) error
+
+	commands []Command
+	hooks    []hook
+
+	mu         sync.Mutex
+	published  bool
+	root       string
+	workspaces []controlapi.Workspace
+	gitSeen    map[string]string
+	// posted are the ids of notifications scripts filed, so a notification
+	// hook never hears its own posts and cannot feed itself.
+	posted map[string]bool
+}
+
+// New returns an engine with no scripts loaded.
+func New() *Engine {
+	return &Engine{
+		sendKeys: workspaceops.AnswerAgent,
+		gitSeen:  make(map[string]string),
+		posted:   make(map[string]bool),
+	}
+}
+
+// Load returns an engine running every *.star file in dir, in name order.
+// A file that fails to load contributes nothing and is reported in errs; the
+// others still load. A missing directory is no scripts, not an error.
+func Load(dir string) (*Engine, []error) {
+	e := New()
+	if dir == "" {
+		return e, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return e, nil
+		}
+		return e, []error{err}
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".star") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	var errs []error
+	for _, name := range names {
+		src, err := os.ReadFile(filepath.Join(dir, name))
+		if err == nil {
+			err = e.LoadSource(name, src)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return e, errs
+}
+
+// LoadSource executes one script. On error everything the script registered
+// is withdrawn, so a half-loaded script leaves no half of itself behind.
+func (e *Engine) LoadSource(name string, src []byte) error {
+	commands, hooks := len(e.commands), len(e.hooks)
+	thread := e.thread(name, &call{script: name, loading: true, ctx: context.Background()})
+	opts := &syntax.FileOptions{Set: true, While: true, TopLevelControl: true, GlobalReassign: true}
+	globals, err := starlark.ExecFileOptions(opts, thread, name, src, starlark.StringDict{"sidecar": e.module()})
+	if err != nil {
+		e.commands, e.hooks = e.commands[:commands], e.hooks[:hooks]
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	// Frozen, a script's functions can run on several goroutines at once;
+	// state a script wants to keep between calls is not something it gets.
+	globals.Freeze()
+	return nil
+}
+
+// Commands returns the registered commands in load order.
+func (e *Engine) Commands() []Command {
+	if e == nil {
+		return nil
+	}
+	return append([]Command(nil), e.commands...)
+}
+
+// Command returns the registered command with this id.
+func (e *Engine) Command(id string) (Command, bool) {
+	if e == nil {
+		return Command{}, false
+	}
+	for _, c := range e.commands {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return Command{}, false
+}
+
+// Run returns a command that runs a registered command. input is the answer
+// to its prompt and is ignored by a command without one.
+func (e *Engine) Run(id, input string) tea.Cmd {
+	c, ok := e.Command(id)
+	if !ok {
+		return nil
+	}
+	var args starlark.Tuple
+	if c.Prompt != "" {
+		args = starlark.Tuple{starlark.String(input)}
+	}
+	return e.invoke(c.Script, "command "+c.ID, c.fn, args)
+}
+
+// dispatch returns a command running every hook for the event with the
+// payload, or nil when nothing listens.
+func (e *Engine) dispatch(event string, payload starlark.Value) tea.Cmd {
+	if e == nil {
+		return nil
+	}
+	var cmds []tea.Cmd
+	for _, h := range e.hooks {
+		if h.event == event {
+			cmds = append(cmds, e.invoke(h.script, "on "+event, h.fn, starlark.Tuple{payload}))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+func (e *Engine) listens(event string) bool {
+	if e == nil {
+		return false
+	}
+	for _, h := range e.hooks {
+		if h.event == event {
+			return true
+		}
+	}
+	return false
+}
+
+// invoke runs fn on a fresh thread under the step budget and deadline.
+func (e *Engine) invoke(script, label string, fn starlark.Callable, args starlark.Tuple) tea.Cmd {
+	return func() tea.Msg {
+		timeout := e.Timeout
+		if timeout <= 0 {
+			timeout = DefaultTimeout
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		c := &call{script: script, ctx: ctx}
+		thread := e.thread(script, c)
+		stop := context.AfterFunc(ctx, func() { thread.Cancel("time limit exceeded") })
+		defer stop()
+
+		msg := ResultMsg{Label: script + ": " + label}
+		if _, err := starlark.Call(thread, fn, args, nil); err != nil {
+			msg.Err = err
+		}
+		msg.Notifications, msg.Flash = c.notifications, c.flash
+		return msg
+	}
+}
+
+// call is what one running call has asked for so far.
+type call struct {
+	script        string
+	loading       bool
+	ctx           context.Context
+	notifications []notify.Notification
+	flash         string
+}
+
+const callKey = "sidecar.call"
+
+func (e *Engine) thread(script string, c *call) *starlark.Thread {
+	thread := &starlark.Thread{
+		Name: script,
+		Print: func(_ *starlark.Thread, msg string) {
+			slog.Info("script", "script", script, "msg", msg)
+		},
+	}
+	maxSteps := e.MaxSteps
+	if maxSteps == 0 {
+		maxSteps = DefaultMaxSteps
+	}
+	thread.SetMaxExecutionSteps(maxSteps)
+	thread.SetLocal(callKey, c)
+	return thread
+}
+
+func callOf(thread *starlark.Thread) *call {
+	c, _ := thread.Local(callKey).(*call)
+	return c
+}
@@ -0,0 +1,297 @@
+package scripting
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/marcus/sidecar/internal/agentstatus"
+	"github.com/marcus/sidecar/internal/controlapi"
+	"github.com/marcus/sidecar/internal/gitstate"
+	"github.com/marcus/sidecar/internal/notify"
+)
+
+func mustLoad(t *testing.T, src string) *Engine {
+	t.Helper()
+	e := New()
+	if err := e.LoadSource("test.star", []byte(src)); err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+// results runs cmd and every command it batches, collecting the results.
+func results(t *testing.T, cmd tea.Cmd) []ResultMsg {
+	t.Helper()
+	if cmd == nil {
+		return nil
+	}
+	switch msg := cmd().(type) {
+	case ResultMsg:
+		return []ResultMsg{msg}
+	case tea.BatchMsg:
+		var out []ResultMsg
+		for _, c := range msg {
+			out = append(out, results(t, c)...)
+		}
+		return out
+	default:
+		t.Fatalf("unexpected message %#v", msg)
+		return nil
+	}
+}
+
+func only(t *testing.T, cmd tea.Cmd) ResultMsg {
+	t.Helper()
+	got := results(t, cmd)
+	if len(got) != 1 {
+		t.Fatalf("results = %+v, want one", got)
+	}
+	return got[0]
+}
+
+func TestLoadRegistersCommandsAndSkipsBrokenScripts(t *testing.T) {
+	dir := t.TempDir()
+	for name, src := range map[string]string{
+		"a.star":   `sidecar.command("hello", lambda: sidecar.flash("hi"), name = "Say hello")`,
+		"b.star":   "sidecar.command(\"half\", lambda: None)\nfail(\"broken\")",
+		"c.txt":    `this is not a script`,
+		"d.star":   `sidecar.command("ask", lambda text: sidecar.flash(text), prompt = "What?")`,
+		"dup.star": `sidecar.command("hello", lambda: None)`,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	e, errs := Load(dir)
+	if len(errs) != 2 || !strings.Contains(errs[0].Error(), "broken") || !strings.Contains(errs[1].Error(), "already registered by a.star") {
+		t.Fatalf("errs = %v, want the failing and duplicate scripts", errs)
+	}
+	var ids []string
+	for _, c := range e.Commands() {
+		ids = append(ids, c.ID)
+	}
+	if strings.Join(ids, ",") != "hello,ask" {
+		t.Fatalf("commands = %v; a script that failed must withdraw what it registered", ids)
+	}
+	if got := only(t, e.Run("hello", "")); got.Err != nil || got.Flash != "hi" {
+		t.Fatalf("hello = %+v", got)
+	}
+	if got := only(t, e.Run("ask", "typed")); got.Err != nil || got.Flash != "typed" {
+		t.Fatalf("ask = %+v", got)
+	}
+
+	if _, errs := Load(filepath.Join(dir, "missing")); errs != nil {
+		t.Fatalf("missing dir = %v, want no scripts and no error", errs)
+	}
+}
+
+func TestSandboxRefusals(t *testing.T) {
+	for _, src := range []string{
+		`load("other.star", "x")`,
+		`sidecar.flash("at load")`,
+		`sidecar.commit("/tmp", "m")`,
+		`sidecar.on("bogus", lambda ev: None)`,
+		`sidecar.command("two words", lambda: None)`,
+	} {
+		if err := New().LoadSource("t.star", []byte(src)); err == nil {
+			t.Fatalf("%s loaded, want a refusal", src)
+		}
+	}
+
+	e := mustLoad(t, `
+def late():
+    sidecar.command("late", lambda: None)
+sidecar.command("late-register", late)
+
+def spin():
+    while True:
+        pass
+sidecar.command("spin", spin)
+
+def outside():
+    sidecar.commit("/etc", "nope")
+sidecar.command("outside", outside)
+
+sidecar.command("status", lambda: sidecar.git_status())
+
+def type_anywhere():
+    sidecar.send_keys("some-session", "rm -rf ~", enter = True)
+sidecar.command("type", type_anywhere)
+`)
+	e.MaxSteps = 10_000
+	for id, want := range map[string]string{
+		"late-register": "only allowed while the script loads",
+		"spin":          "too many steps",
+		"outside":       "not a workspace sidecar is showing",
+		"status":        "no project is open",
+		"type":          "is not a session",
+	} {
+		got := only(t, e.Run(id, ""))
+		if got.Err == nil || !strings.Contains(got.Err.Error(), want) {
+			t.Fatalf("%s err = %v, want %q", id, got.Err, want)
+		}
+	}
+}
+
+func TestWorkspaceHooksReportChangesAfterTheBaseline(t *testing.T) {
+	e := mustLoad(t, `
+def on_ws(ev):
+    sidecar.flash(ev.change + " " + ev.workspace.name)
+sidecar.on("workspace", on_ws)
+
+def names():
+    sidecar.flash(",".join([ws.name for ws in sidecar.workspaces()]) + "@" + sidecar.project())
+sidecar.command("names", names)
+`)
+	main := controlapi.Workspace{Kind: "worktree", Name: "app", Path: "/src/app", Main: true}
+	feature := controlapi.Workspace{Kind: "worktree", Name: "feature", Path: "/src/feature"}
+	shell := controlapi.Workspace{Kind: "shell", Name: "Shell 1", Session: "sidecar-sh-app-1"}
+
+	if cmd := e.ObserveWorkspaces(controlapi.WorkspacesState{Root: "/src/app", Workspaces: []controlapi.Workspace{main, feature}}); cmd != nil {
+		t.Fatal("the first publication is a baseline, not a change")
+	}
+	if got := only(t, e.Run("names", "")); got.Flash != "app,feature@/src/app" {
+		t.Fatalf("names = %+v", got)
+	}
+
+	feature.Branch = "feature"
+	var flashes []string
+	for _, r := range results(t, e.ObserveWorkspaces(controlapi.WorkspacesState{Root: "/src/app", Workspaces: []controlapi.Workspace{main, feature, shell}})) {
+		flashes = append(flashes, r.Flash)
+	}
+	if strings.Join(flashes, "|") != "changed feature|added Shell 1" {
+		t.Fatalf("flashes = %v", flashes)
+	}
+	if got := only(t, e.ObserveWorkspaces(controlapi.WorkspacesState{Root: "/src/app", Workspaces: []controlapi.Workspace{main, shell}})); got.Flash != "removed feature" {
+		t.Fatalf("removal = %+v", got)
+	}
+	if cmd := e.ObserveWorkspaces(controlapi.WorkspacesState{Root: "/src/other"}); cmd != nil {
+		t.Fatal("a project switch is a new baseline")
+	}
+}
+
+func TestAgentAndNotificationHooks(t *testing.T) {
+	e := mustLoad(t, `
+def on_agent(ev):
+    if ev.previous == "working" and ev.lane == "done":
+        sidecar.notify(ev.label + " is done", body = ev.session, source = "session")
+sidecar.on("agent", on_agent)
+
+def on_note(ev):
+    sidecar.notify("heard " + ev.title)
+sidecar.on("notification", on_note)
+`)
+	got := only(t, e.ObserveAgent(notify.LaneTransition{
+		Label: "Shell 1", Previous: agentstatus.LaneWorking, Lane: agentstatus.LaneDone,
+		Origin: notify.Origin{TmuxSession: "sidecar-sh-app-1"},
+	}))
+	if got.Err != nil || len(got.Notifications) != 1 {
+		t.Fatalf("agent hook = %+v", got)
+	}
+	posted := got.Notifications[0]
+	if posted.Title != "Shell 1 is done" || posted.Body != "sidecar-sh-app-1" || posted.Source != notify.SourceSession || posted.ID == "" {
+		t.Fatalf("posted = %+v", posted)
+	}
+
+	// The script's own post coming back through the store is not news to it.
+	if cmd := e.ObserveNotification(posted); cmd != nil {
+		t.Fatal("a script heard its own notification")
+	}
+	heard := only(t, e.ObserveNotification(notify.Notification{ID: "n-1", Title: "build failed"}))
+	if len(heard.Notifications) != 1 || heard.Notifications[0].Title != "heard build failed" {
+		t.Fatalf("notification hook = %+v", heard)
+	}
+
+	if cmd := e.ObserveGit(gitstate.Parse("/src/app", nil)); cmd != nil {
+		t.Fatal("nothing listens for git, so no command")
+	}
+}
+
+func TestCommitAndGitHooks(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	run("init", "-q")
+	run("config", "user.email", "test@example.com")
+	run("config", "user.name", "Test")
+
+	e := mustLoad(t, `
+def commit_all(message):
+    committed = []
+    for ws in sidecar.workspaces():
+        if ws.kind == "worktree" and sidecar.commit(ws.path, message):
+            committed.append(ws.name)
+    sidecar.flash("committed " + ",".join(committed))
+sidecar.command("commit-all", commit_all, prompt = "Message")
+
+def on_git(status):
+    sidecar.flash(",".join(sorted(status.files.keys())))
+sidecar.on("git", on_git)
+`)
+	e.ObserveWorkspaces(controlapi.WorkspacesState{Root: repo, Workspaces: []controlapi.Workspace{{Kind: "worktree", Name: "app", Path: repo}}})
+	if err := os.WriteFile(filepath.Join(repo, "a.txt"), []byte("a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	snap, err := gitstate.Load(context.Background(), repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmd := e.ObserveGit(snap); cmd != nil {
+		t.Fatal("the first snapshot of a root is a baseline")
+	}
+	if got := only(t, e.Run("commit-all", "first")); got.Err != nil || got.Flash != "committed app" {
+		t.Fatalf("commit-all = %+v", got)
+	}
+	if got := only(t, e.Run("commit-all", "again")); got.Err != nil || got.Flash != "committed " {
+		t.Fatalf("clean commit-all = %+v", got)
+	}
+
+	if err := os.WriteFile(filepath.Join(repo, "b.txt"), []byte("b"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	snap, err = gitstate.Load(context.Background(), repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := only(t, e.ObserveGit(snap)); got.Flash != "b.txt" {
+		t.Fatalf("git hook = %+v", got)
+	}
+	if cmd := e.ObserveGit(snap); cmd != nil {
+		t.Fatal("an unchanged snapshot fired the hook again")
+	}
+}
+
+func TestSendKeysReachesKnownSessions(t *testing.T) {
+	e := mustLoad(t, `sidecar.command("go", lambda: sidecar.send_keys("sidecar-sh-app-1", "continue", enter = True))`)
+	var sent []notify.Keystroke
+	e.sendKeys = func(_ context.Context, session string, strokes []notify.Keystroke) error {
+		if session != "sidecar-sh-app-1" {
+			t.Fatalf("session = %q", session)
+		}
+		sent = strokes
+		return nil
+	}
+	e.ObserveWorkspaces(controlapi.WorkspacesState{Root: "/src/app", Workspaces: []controlapi.Workspace{{Kind: "shell", Name: "Shell 1", Session: "sidecar-sh-app-1"}}})
+	if got := only(t, e.Run("go", "")); got.Err != nil {
+		t.Fatal(got.Err)
+	}
+	if len(sent) != 2 || sent[0].Keys != "continue" || !sent[0].Literal || sent[1].Keys != "Enter" {
+		t.Fatalf("strokes = %+v", sent)
+	}
+}
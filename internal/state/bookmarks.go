@@ -0,0 +1,336 @@
+package state
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/marcus/sidecar/internal/uirequest"
+)
+
+// Bookmarks live in their own file beside the other machine-local state, not
+// in state.json. The CLI adds them while a TUI is running, and state.json is a
+// single in-memory document the TUI rewrites whole, so a bookmark written
+// there by another process would be lost at the TUI's next save. Every write
+// here is a locked read-modify-write of the file instead, and readers always
+// go to disk.
+
+const (
+	bookmarksFile    = "bookmarks.json"
+	bookmarksVersion = 1
+	bookmarkIDPrefix = "bm-"
+
+	// bookmarkLockTimeout matches the notification log's: the same shape of
+	// problem, a small file several sidecar processes rewrite.
+	bookmarkLockTimeout = 5 * time.Second
+)
+
+// ErrBookmarkNotFound is returned when no bookmark matches an id or index.
+var ErrBookmarkNotFound = errors.New("bookmark not found")
+
+// Bookmark is one saved location. Target is the same vocabulary `sidecar open`
+// and the notification centre use, so anything those can show can be pinned.
+// A file target's Value is relative to WorkDir, the checkout it was taken in;
+// Project is the project root the bookmark is filed under, so every worktree
+// of a project shares one list.
+type Bookmark struct {
+	ID      string           `json:"id"`
+	Project string           `json:"project"`
+	WorkDir string           `json:"workDir,omitempty"`
+	Target  uirequest.Target `json:"target"`
+	Label   string           `json:"label,omitempty"`
+	Note    string           `json:"note,omitempty"`
+	// CreatedBy says who left it: "user" from the TUI, or the shell's display
+	// name (or "cli") from the CLI, so "three places for you to review"
+	// reads as such in the picker.
+	CreatedBy string    `json:"createdBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Title is the one-line name a list shows: the label when there is one,
+// otherwise the target itself.
+func (b Bookmark) Title() string {
+	if b.Label != "" {
+		return b.Label
+	}
+	return b.Location()
+}
+
+// Location renders the target the way a user would type it to `sidecar open`.
+func (b Bookmark) Location() string {
+	t := b.Target
+	switch t.Kind {
+	case uirequest.TargetKindFile:
+		if t.Line > 0 {
+			return t.Value + ":" + strconv.Itoa(t.Line)
+		}
+		return t.Value
+	case uirequest.TargetKindDiff:
+		return "diff " + t.Value
+	case uirequest.TargetKindResource:
+		return t.Provider + " " + t.Value
+	case uirequest.TargetKindShell, uirequest.TargetKindSession:
+		return "shell " + t.Value
+	}
+	return t.Value
+}
+
+type bookmarkDoc struct {
+	Version  int                   `json:"version"`
+	Projects map[string][]Bookmark `json:"projects,omitempty"`
+}
+
+// BookmarksPath returns the bookmark file inside a state directory.
+func BookmarksPath(dir string) string {
+	return filepath.Join(dir, bookmarksFile)
+}
+
+// ReadBookmarks returns every project's bookmarks, keyed by project root. A
+// missing file is an empty result, not an error.
+func ReadBookmarks(dir string) (map[string][]Bookmark, error) {
+	doc, err := readBookmarkDoc(BookmarksPath(dir))
+	if err != nil {
+		return nil, err
+	}
+	return doc.Projects, nil
+}
+
+// ProjectBookmarks returns one project's bookmarks, oldest first.
+func ProjectBookmarks(dir, project string) ([]Bookmark, error) {
+	all, err := ReadBookmarks(dir)
+	if err != nil {
+		return nil, err
+	}
+	return all[cleanProject(project)], nil
+}
+
+// AllBookmarks returns every bookmark, grouped by project in path order and
+// oldest first within a project.
+func AllBookmarks(dir string) ([]Bookmark, error) {
+	all, err := ReadBookmarks(dir)
+	if err != nil {
+		return nil, err
+	}
+	projects := make([]string, 0, len(all))
+	for p := range all {
+		projects = append(projects, p)
+	}
+	sort.Strings(projects)
+	var out []Bookmark
+	for _, p := range projects {
+		out = append(out, all[p]...)
+	}
+	return out, nil
+}
+
+// AddBookmark files b under its project and returns it as stored. Pinning a
+// target the project already has updates that bookmark's label and note in
+// place rather than adding a duplicate, so repeating a command is harmless.
+func AddBookmark(dir string, b Bookmark) (Bookmark, error) {
+	b.Project = cleanProject(b.Project)
+	if b.Project == "" {
+		return Bookmark{}, fmt.Errorf("bookmark needs a project")
+	}
+	if b.Target.Kind == "" || strings.TrimSpace(b.Target.Value) == "" {
+		return Bookmark{}, fmt.Errorf("bookmark needs a target")
+	}
+	var stored Bookmark
+	err := updateBookmarks(dir, func(doc *bookmarkDoc) error {
+		list := doc.Projects[b.Project]
+		for i := range list {
+			if list[i].WorkDir == b.WorkDir && list[i].Target == b.Target {
+				if b.Label != "" {
+					list[i].Label = b.Label
+				}
+				if b.Note != "" {
+					list[i].Note = b.Note
+				}
+				stored = list[i]
+				return nil
+			}
+		}
+		if b.ID == "" {
+			b.ID = newBookmarkID()
+		}
+		if b.CreatedAt.IsZero() {
+			b.CreatedAt = time.Now().UTC()
+		}
+		doc.Projects[b.Project] = append(list, b)
+		stored = b
+		return nil
+	})
+	return stored, err
+}
+
+// RemoveBookmark deletes the bookmark with id, from whichever project holds it.
+func RemoveBookmark(dir, id string) (Bookmark, error) {
+	var removed Bookmark
+	err := updateBookmarks(dir, func(doc *bookmarkDoc) error {
+		for project, list := range doc.Projects {
+			for i, b := range list {
+				if b.ID != id {
+					continue
+				}
+				removed = b
+				list = append(list[:i:i], list[i+1:]...)
+				if len(list) == 0 {
+					delete(doc.Projects, project)
+				} else {
+					doc.Projects[project] = list
+				}
+				return nil
+			}
+		}
+		return ErrBookmarkNotFound
+	})
+	return removed, err
+}
+
+// FindBookmark resolves a reference to one bookmark: an id, a unique id
+// prefix, or a 1-based position in list (as `sidecar bookmark list` numbers
+// them).
+func FindBookmark(list []Bookmark, ref string) (Bookmark, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return Bookmark{}, ErrBookmarkNotFound
+	}
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n >= 1 && n <= len(list) {
+			return list[n-1], nil
+		}
+		return Bookmark{}, ErrBookmarkNotFound
+	}
+	for _, b := range list {
+		if b.ID == ref {
+			return b, nil
+		}
+	}
+	if !strings.HasPrefix(ref, bookmarkIDPrefix) {
+		ref = bookmarkIDPrefix + ref
+	}
+	var hit Bookmark
+	hits := 0
+	for _, b := range list {
+		if strings.HasPrefix(b.ID, ref) {
+			hit = b
+			hits++
+		}
+	}
+	switch hits {
+	case 1:
+		return hit, nil
+	case 0:
+		return Bookmark{}, ErrBookmarkNotFound
+	}
+	return Bookmark{}, fmt.Errorf("%q matches %d bookmarks", ref, hits)
+}
+
+func cleanProject(project string) string {
+	if project == "" {
+		return ""
+	}
+	return filepath.Clean(project)
+}
+
+func newBookmarkID() string {
+	var b [4]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return bookmarkIDPrefix + strconv.FormatInt(time.Now().UnixNano()&0xffffffff, 16)
+	}
+	return bookmarkIDPrefix + hex.EncodeToString(b[:])
+}
+
+func readBookmarkDoc(path string) (bookmarkDoc, error) {
+	doc := bookmarkDoc{Version: bookmarksVersion, Projects: map[string][]Bookmark{}}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return doc, nil
+	}
+	if err != nil {
+		return doc, err
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return doc, fmt.Errorf("read %s: %w", filepath.Base(path), err)
+	}
+	if doc.Projects == nil {
+		doc.Projects = map[string][]Bookmark{}
+	}
+	return doc, nil
+}
+
+// updateBookmarks runs fn over the current file under an exclusive lock and
+// writes the result back atomically. fn's error aborts the write.
+func updateBookmarks(dir string, fn func(*bookmarkDoc) error) error {
+	path := BookmarksPath(dir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return withBookmarkLock(path, func() error {
+		doc, err := readBookmarkDoc(path)
+		if err != nil {
+			return err
+		}
+		if err := fn(&doc); err != nil {
+			return err
+		}
+		doc.Version = bookmarksVersion
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return err
+		}
+		tmp, err := os.CreateTemp(dir, "."+bookmarksFile+".*")
+		if err != nil {
+			return err
+		}
+		name := tmp.Name()
+		if _, err := tmp.Write(data); err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(name)
+			return err
+		}
+		if err := tmp.Close(); err != nil {
+			_ = os.Remove(name)
+			return err
+		}
+		if err := os.Rename(name, path); err != nil {
+			_ = os.Remove(name)
+			return err
+		}
+		return nil
+	})
+}
+
+func withBookmarkLock(path string, fn func() error) error {
+	lock, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = syscall.Flock(int(lock.Fd()), syscall.LOCK_UN)
+		_ = lock.Close()
+	}()
+	deadline := time.Now().Add(bookmarkLockTimeout)
+	for {
+		err = syscall.Flock(int(lock.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil {
+			break
+		}
+		if err != syscall.EWOULDBLOCK && err != syscall.EAGAIN {
+			return err
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("bookmarks: lock acquisition timeout after %v", bookmarkLockTimeout)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return fn()
+}
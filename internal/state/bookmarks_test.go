@@ -0,0 +1,103 @@
+package state
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/marcus/sidecar/internal/uirequest"
+)
+
+func fileTarget(path string, line int) uirequest.Target {
+	return uirequest.Target{Kind: uirequest.TargetKindFile, Value: path, Line: line}
+}
+
+func TestAddBookmarkAssignsIDAndDedupes(t *testing.T) {
+	dir := t.TempDir()
+	first, err := AddBookmark(dir, Bookmark{Project: "/p", WorkDir: "/p", Target: fileTarget("a.go", 3)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ID == "" || first.CreatedAt.IsZero() {
+		t.Fatalf("stored bookmark missing id or time: %+v", first)
+	}
+
+	again, err := AddBookmark(dir, Bookmark{Project: "/p", WorkDir: "/p", Target: fileTarget("a.go", 3), Label: "retry loop"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != first.ID || again.Label != "retry loop" {
+		t.Fatalf("re-adding the same target = %+v, want label update of %s", again, first.ID)
+	}
+
+	list, err := ProjectBookmarks(dir, "/p/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 {
+		t.Fatalf("project has %d bookmarks, want 1", len(list))
+	}
+	if got := list[0].Title(); got != "retry loop" {
+		t.Fatalf("title = %q", got)
+	}
+}
+
+func TestAddBookmarkRejectsMissingTarget(t *testing.T) {
+	if _, err := AddBookmark(t.TempDir(), Bookmark{Project: "/p"}); err == nil {
+		t.Fatal("bookmark without a target was accepted")
+	}
+}
+
+func TestRemoveBookmark(t *testing.T) {
+	dir := t.TempDir()
+	b, _ := AddBookmark(dir, Bookmark{Project: "/p", Target: fileTarget("a.go", 0)})
+	if _, err := RemoveBookmark(dir, b.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RemoveBookmark(dir, b.ID); !errors.Is(err, ErrBookmarkNotFound) {
+		t.Fatalf("second remove err = %v, want ErrBookmarkNotFound", err)
+	}
+	all, _ := ReadBookmarks(dir)
+	if len(all) != 0 {
+		t.Fatalf("emptied project left behind: %v", all)
+	}
+}
+
+func TestFindBookmarkByIndexIDAndPrefix(t *testing.T) {
+	list := []Bookmark{{ID: "bm-aa11"}, {ID: "bm-ab22"}, {ID: "bm-cc33"}}
+	cases := map[string]string{"2": "bm-ab22", "bm-cc33": "bm-cc33", "cc": "bm-cc33", "bm-aa": "bm-aa11"}
+	for ref, want := range cases {
+		got, err := FindBookmark(list, ref)
+		if err != nil || got.ID != want {
+			t.Errorf("FindBookmark(%q) = %q, %v; want %q", ref, got.ID, err, want)
+		}
+	}
+	if _, err := FindBookmark(list, "a"); err == nil {
+		t.Error("ambiguous prefix resolved")
+	}
+	if _, err := FindBookmark(list, "4"); !errors.Is(err, ErrBookmarkNotFound) {
+		t.Errorf("out-of-range index err = %v", err)
+	}
+}
+
+func TestAddBookmarkConcurrentWritersKeepEveryEntry(t *testing.T) {
+	dir := t.TempDir()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := AddBookmark(dir, Bookmark{Project: "/p", Target: fileTarget("f.go", i+1)}); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	list, err := ProjectBookmarks(dir, "/p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 8 {
+		t.Fatalf("kept %d bookmarks, want 8", len(list))
+	}
+}
@@ -0,0 +1,97 @@
+package structview
+
+import (
+	"errors"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// errTruncated is why a tree format falls back to text: a prefix of a JSON or
+// YAML document is not a document.
+var errTruncated = errors.New("file is too large to show as a tree")
+
+// cursor is the row cursor every model shares: a selected row and the first
+// row on screen, kept so the selection is always visible.
+type cursor struct {
+	row    int
+	scroll int
+	// page is the height of the last render, which is what half- and
+	// full-page moves step by.
+	page int
+}
+
+// move handles the navigation keys over n rows and reports whether key was
+// one of them.
+func (c *cursor) move(key string, n int) bool {
+	page := c.page
+	if page < 1 {
+		page = 1
+	}
+	switch key {
+	case "j", "down":
+		c.row++
+	case "k", "up":
+		c.row--
+	case "g", "home":
+		c.row = 0
+	case "G", "end":
+		c.row = n - 1
+	case "ctrl+d":
+		c.row += page / 2
+	case "ctrl+u":
+		c.row -= page / 2
+	case "ctrl+f", "pgdown":
+		c.row += page
+	case "ctrl+b", "pgup":
+		c.row -= page
+	default:
+		return false
+	}
+	c.clamp(n)
+	return true
+}
+
+// clamp keeps the cursor on one of n rows.
+func (c *cursor) clamp(n int) {
+	if c.row >= n {
+		c.row = n - 1
+	}
+	if c.row < 0 {
+		c.row = 0
+	}
+}
+
+// window returns the half-open range of n rows to draw in height rows,
+// scrolling just far enough to keep the cursor in view.
+func (c *cursor) window(n, height int) (int, int) {
+	c.page = height
+	c.clamp(n)
+	if height < 1 {
+		return 0, 0
+	}
+	if c.row < c.scroll {
+		c.scroll = c.row
+	}
+	if c.row >= c.scroll+height {
+		c.scroll = c.row - height + 1
+	}
+	if c.scroll > n-height {
+		c.scroll = n - height
+	}
+	if c.scroll < 0 {
+		c.scroll = 0
+	}
+	end := c.scroll + height
+	if end > n {
+		end = n
+	}
+	return c.scroll, end
+}
+
+// fit truncates s, which may carry styling, to width cells.
+func fit(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	return ansi.Truncate(s, width, "…")
+}
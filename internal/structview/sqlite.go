@@ -0,0 +1,291 @@
+package structview
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	tea "charm.land/bubbletea/v2"
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/marcus/sidecar/internal/adapter"
+	"github.com/marcus/sidecar/internal/styles"
+)
+
+// sqlitePageSize is how many rows one page of a table holds.
+const sqlitePageSize = 200
+
+// sqliteObject is a table or view listed in sqlite_master.
+type sqliteObject struct {
+	Name string
+	Type string
+	SQL  string
+}
+
+// SQLite browses a database read-only: a list of its tables and views with
+// the selected one's schema, and a paged table of one object's rows.
+//
+// Every query opens the file through adapter.ReadOnlyDSN and closes it again,
+// off the UI goroutine. Holding a handle open for as long as a preview is on
+// screen would pin the file under whatever application owns it.
+type SQLite struct {
+	path    string
+	objects []sqliteObject
+	list    cursor
+	err     error
+	loading bool
+	// gen counts issued queries; a result from an older one is dropped.
+	gen uint64
+
+	// Open object state. rows is nil while the list is showing.
+	open  *sqliteObject
+	page  int
+	total int64
+	rows  *Table
+}
+
+// SQLiteMsg carries a query result back to the SQLite model that issued it.
+type SQLiteMsg struct {
+	src     *SQLite
+	gen     uint64
+	objects []sqliteObject
+	header  []string
+	rows    [][]string
+	total   int64
+	err     error
+}
+
+// OpenSQLite returns a browser for the database at path. Nothing is read
+// until Init.
+func OpenSQLite(path string) *SQLite {
+	return &SQLite{path: path, loading: true}
+}
+
+// Path returns the database file being browsed.
+func (s *SQLite) Path() string { return s.path }
+
+// Label implements Model.
+func (s *SQLite) Label() string {
+	if s.open != nil {
+		return "sqlite · " + s.open.Name
+	}
+	return "sqlite"
+}
+
+// Init implements Model.
+func (s *SQLite) Init() tea.Cmd {
+	s.gen++
+	s.loading = true
+	src, gen, path := s, s.gen, s.path
+	return func() tea.Msg {
+		objects, err := listObjects(path)
+		return SQLiteMsg{src: src, gen: gen, objects: objects, err: err}
+	}
+}
+
+// Update implements Model.
+func (s *SQLite) Update(m tea.Msg) tea.Cmd {
+	res, ok := m.(SQLiteMsg)
+	if !ok || res.src != s || res.gen != s.gen {
+		return nil
+	}
+	s.loading = false
+	s.err = res.err
+	if res.err != nil {
+		return nil
+	}
+	if s.open == nil {
+		s.objects = res.objects
+		return nil
+	}
+	s.total = res.total
+	s.rows = NewTable(s.Label(), res.header, res.rows)
+	s.rows.note = s.pageNote()
+	return nil
+}
+
+// HandleKey implements Model.
+func (s *SQLite) HandleKey(key string) (bool, tea.Cmd) {
+	if s.open == nil {
+		if s.list.move(key, len(s.objects)) {
+			return true, nil
+		}
+		if key == "enter" && len(s.objects) > 0 {
+			obj := s.objects[s.list.row]
+			s.open = &obj
+			s.page = 0
+			s.rows = nil
+			return true, s.loadPage()
+		}
+		return false, nil
+	}
+	switch key {
+	case "esc", "backspace":
+		s.open, s.rows, s.err = nil, nil, nil
+		s.gen++ // an in-flight page is no longer wanted
+		s.loading = false
+		return true, nil
+	case "n":
+		if int64(s.page+1)*sqlitePageSize < s.total {
+			s.page++
+			return true, s.loadPage()
+		}
+		return true, nil
+	case "p":
+		if s.page > 0 {
+			s.page--
+			return true, s.loadPage()
+		}
+		return true, nil
+	}
+	if s.rows != nil {
+		return s.rows.HandleKey(key)
+	}
+	return false, nil
+}
+
+func (s *SQLite) loadPage() tea.Cmd {
+	s.gen++
+	s.loading = true
+	src, gen, path, name, page := s, s.gen, s.path, s.open.Name, s.page
+	return func() tea.Msg {
+		header, rows, total, err := readPage(path, name, page)
+		return SQLiteMsg{src: src, gen: gen, header: header, rows: rows, total: total, err: err}
+	}
+}
+
+func (s *SQLite) pageNote() string {
+	if s.total <= sqlitePageSize {
+		return fmt.Sprintf("%d rows", s.total)
+	}
+	pages := (s.total + sqlitePageSize - 1) / sqlitePageSize
+	return fmt.Sprintf("page %d/%d of %d rows · n/p to page", s.page+1, pages, s.total)
+}
+
+// View implements Model.
+func (s *SQLite) View(width, height int) string {
+	if s.err != nil {
+		return fit(styles.StatusDeleted.Render("SQLite: "+s.err.Error()), width)
+	}
+	if s.open != nil {
+		if s.rows == nil {
+			return styles.Muted.Render("Loading " + s.open.Name + "…")
+		}
+		return s.rows.View(width, height)
+	}
+	if s.loading {
+		return styles.Muted.Render("Reading schema…")
+	}
+	if len(s.objects) == 0 {
+		return styles.Muted.Render("No tables")
+	}
+
+	// The list takes the top half at most; the selected object's schema fills
+	// the rest, so moving through the list reads the database's structure.
+	listHeight := min(len(s.objects), max(height/2, 3))
+	start, end := s.list.window(len(s.objects), listHeight)
+	var b strings.Builder
+	for i := start; i < end; i++ {
+		obj := s.objects[i]
+		line := fit(obj.Name+" "+styles.Muted.Render(obj.Type), width)
+		if i == s.list.row {
+			line = styles.ListItemSelected.Render(padRight(line, width))
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	b.WriteString(styles.Muted.Render(strings.Repeat("─", width)))
+	schema := strings.Split(strings.TrimSpace(s.objects[s.list.row].SQL), "\n")
+	for i, line := range schema {
+		if i >= height-listHeight-1 {
+			break
+		}
+		b.WriteString("\n")
+		b.WriteString(fit(styles.Code.Render(strings.TrimRight(line, " \t\r")), width))
+	}
+	return b.String()
+}
+
+func listObjects(path string) ([]sqliteObject, error) {
+	db, err := sql.Open("sqlite3", adapter.ReadOnlyDSN(path))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = db.Close() }()
+	rows, err := db.Query(`SELECT name, type, COALESCE(sql, '') FROM sqlite_master
+		WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%'
+		ORDER BY type, name`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []sqliteObject
+	for rows.Next() {
+		var o sqliteObject
+		if err := rows.Scan(&o.Name, &o.Type, &o.SQL); err != nil {
+			return nil, err
+		}
+		out = append(out, o)
+	}
+	return out, rows.Err()
+}
+
+func readPage(path, name string, page int) ([]string, [][]string, int64, error) {
+	db, err := sql.Open("sqlite3", adapter.ReadOnlyDSN(path))
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	defer func() { _ = db.Close() }()
+	ident := quoteIdent(name)
+	var total int64
+	if err := db.QueryRow("SELECT COUNT(*) FROM " + ident).Scan(&total); err != nil {
+		return nil, nil, 0, err
+	}
+	rows, err := db.Query("SELECT * FROM "+ident+" LIMIT ? OFFSET ?", sqlitePageSize, page*sqlitePageSize)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	defer func() { _ = rows.Close() }()
+	header, err := rows.Columns()
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	var out [][]string
+	for rows.Next() {
+		values := make([]any, len(header))
+		ptrs := make([]any, len(header))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, nil, 0, err
+		}
+		record := make([]string, len(values))
+		for i, v := range values {
+			record[i] = formatValue(v)
+		}
+		out = append(out, record)
+	}
+	return header, out, total, rows.Err()
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// formatValue renders one SQLite value as a cell. NULL is spelled out so it
+// cannot be mistaken for an empty string, and blobs that are not text show
+// their size instead of bytes the terminal would mangle.
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		if utf8.Valid(v) {
+			return string(v)
+		}
+		return fmt.Sprintf("<blob %d bytes>", len(v))
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,84 @@
+// Package structview renders data files as navigable structures instead of
+// text: JSON and YAML as a collapsible tree, CSV and TSV as a sortable table,
+// and SQLite databases as a read-only table browser. The Files preview hosts
+// these models; the package knows nothing about panes or plugins, only how to
+// turn a file into rows that fit a width and height.
+package structview
+
+import (
+	"path/filepath"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// Kind names the structured rendering a file gets.
+type Kind int
+
+const (
+	KindNone Kind = iota
+	KindJSON
+	KindYAML
+	KindCSV
+	KindTSV
+	KindSQLite
+)
+
+// Detect returns the structured rendering for path, judged by extension.
+func Detect(path string) Kind {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".jsonc", ".geojson", ".har":
+		return KindJSON
+	case ".yaml", ".yml":
+		return KindYAML
+	case ".csv":
+		return KindCSV
+	case ".tsv", ".tab":
+		return KindTSV
+	case ".db", ".sqlite", ".sqlite3", ".db3":
+		return KindSQLite
+	}
+	return KindNone
+}
+
+// Model is one structured rendering of a file.
+type Model interface {
+	// Init starts any loading the model does off the UI goroutine. Text
+	// formats are parsed up front and return nil.
+	Init() tea.Cmd
+	// Update consumes the model's own asynchronous results.
+	Update(msg tea.Msg) tea.Cmd
+	// HandleKey reacts to a key and reports whether it was consumed, so the
+	// host can fall back to its own bindings for the rest.
+	HandleKey(key string) (bool, tea.Cmd)
+	// View renders at most height rows, each at most width cells wide.
+	View(width, height int) string
+	// Label is the short mode name the host shows in its header.
+	Label() string
+}
+
+// Load builds the model for a file whose text the host has already read.
+// content is ignored for SQLite, which is opened from fullPath instead.
+// truncated says the host only read a prefix of the file. A nil model with a
+// nil error means the file has no structured rendering.
+func Load(fullPath, content string, truncated bool) (Model, error) {
+	switch Detect(fullPath) {
+	case KindJSON:
+		if truncated {
+			return nil, errTruncated
+		}
+		return ParseJSON(content)
+	case KindYAML:
+		if truncated {
+			return nil, errTruncated
+		}
+		return ParseYAML(content)
+	case KindCSV:
+		return ParseDelimited(content, ',', truncated)
+	case KindTSV:
+		return ParseDelimited(content, '\t', truncated)
+	case KindSQLite:
+		return OpenSQLite(fullPath), nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,185 @@
+package structview
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestJSONTreeKeepsKeyOrderAndPaths(t *testing.T) {
+	tr, err := ParseJSON(`{"zeta": 1, "alpha": {"items": [{"name": "x"}], "odd key": true}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, r := range tr.rows {
+		paths = append(paths, r.path)
+	}
+	// Depth two starts open, so alpha's children show but items' elements do not.
+	want := []string{".zeta", ".alpha", ".alpha.items", `.alpha["odd key"]`}
+	if strings.Join(paths, " ") != strings.Join(want, " ") {
+		t.Fatalf("rows = %v, want %v", paths, want)
+	}
+
+	tr.HandleKey("j")
+	tr.HandleKey("j")
+	tr.HandleKey("enter")
+	tr.HandleKey("j")
+	if got := tr.Selected(); got != ".alpha.items[0]" {
+		t.Fatalf("selected = %q, want .alpha.items[0]", got)
+	}
+	tr.HandleKey("left")
+	tr.HandleKey("left")
+	if got := tr.Selected(); got != ".alpha.items" {
+		t.Fatalf("left from a closed node selected %q, want its parent", got)
+	}
+}
+
+func TestJSONRejectsTrailingData(t *testing.T) {
+	if _, err := ParseJSON(`{} {}`); err == nil {
+		t.Fatal("two top-level values parsed as one document")
+	}
+}
+
+func TestYAMLTreeResolvesAliasesAndTypes(t *testing.T) {
+	tr, err := ParseYAML("base: &b\n  port: 80\nweb:\n  <<: *b\nflag: yes\nnone: ~\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr.HandleKey("Z")
+	view := ansi.Strip(tr.View(60, 20))
+	for _, want := range []string{"port: 80", "none: null", "flag: \"yes\""} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("view missing %q:\n%s", want, view)
+		}
+	}
+}
+
+func TestYAMLStreamBecomesArray(t *testing.T) {
+	tr, err := ParseYAML("a: 1\n---\nb: 2\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr.root.kind != nodeArray || len(tr.root.children) != 2 {
+		t.Fatalf("root kind=%v children=%d, want a two-document array", tr.root.kind, len(tr.root.children))
+	}
+	if got := tr.rows[1].path; got != ".[0].a" {
+		t.Fatalf("first document key path = %q", got)
+	}
+}
+
+func TestTableSortsNumbersNumerically(t *testing.T) {
+	tb, err := ParseDelimited("name,size\nb,10\na,9\nc,\n", ',', false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tb.HandleKey("right")
+	tb.HandleKey("s")
+	if got := firstColumn(tb); got != "a b c" {
+		t.Fatalf("ascending = %q, want a b c", got)
+	}
+	tb.HandleKey("s")
+	if got := firstColumn(tb); got != "c b a" {
+		t.Fatalf("descending = %q, want c b a", got)
+	}
+	tb.HandleKey("s")
+	if got := firstColumn(tb); got != "b a c" {
+		t.Fatalf("third press = %q, want file order", got)
+	}
+}
+
+func TestTruncatedCSVDropsPartialRecord(t *testing.T) {
+	tb, err := ParseDelimited("a\tb\n1\t2\n3\t", '\t', true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tb.Len() != 1 {
+		t.Fatalf("rows = %d, want the partial last line dropped", tb.Len())
+	}
+}
+
+func TestTableViewFitsWidthAndFollowsColumn(t *testing.T) {
+	header := []string{"one", "two", "three", "four"}
+	tb := NewTable("csv table", header, [][]string{{strings.Repeat("x", 30), "y", "z", "w"}})
+	tb.HandleKey("right")
+	tb.HandleKey("right")
+	tb.HandleKey("right")
+	view := tb.View(20, 6)
+	for _, line := range strings.Split(view, "\n") {
+		if w := ansi.StringWidth(line); w > 20 {
+			t.Fatalf("line %q is %d wide", ansi.Strip(line), w)
+		}
+	}
+	if !strings.Contains(ansi.Strip(view), "four") {
+		t.Fatalf("selected column scrolled out of view:\n%s", ansi.Strip(view))
+	}
+}
+
+func TestSQLiteListsTablesAndPagesRows(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.db")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, avatar BLOB)`,
+		`INSERT INTO users (name, avatar) VALUES ('ann', x'00ff'), (NULL, NULL)`,
+		`CREATE VIEW names AS SELECT name FROM users`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = db.Close()
+
+	s := OpenSQLite(path)
+	run(t, s, s.Init())
+	if len(s.objects) != 2 || s.objects[0].Name != "users" || s.objects[1].Name != "names" {
+		t.Fatalf("objects = %+v", s.objects)
+	}
+	if view := ansi.Strip(s.View(60, 10)); !strings.Contains(view, "CREATE TABLE users") {
+		t.Fatalf("schema not shown:\n%s", view)
+	}
+
+	_, cmd := s.HandleKey("enter")
+	run(t, s, cmd)
+	if s.rows == nil || s.rows.Len() != 2 {
+		t.Fatalf("rows not loaded: %+v", s.rows)
+	}
+	if got := s.rows.Row(0); got[2] != "<blob 2 bytes>" || s.rows.Row(1)[1] != "NULL" {
+		t.Fatalf("cells = %v / %v", got, s.rows.Row(1))
+	}
+	if handled, _ := s.HandleKey("esc"); !handled || s.open != nil {
+		t.Fatal("esc did not return to the table list")
+	}
+}
+
+func TestSQLiteDropsStaleResults(t *testing.T) {
+	s := OpenSQLite(filepath.Join(t.TempDir(), "missing.db"))
+	stale := s.Init()
+	_ = s.Init()
+	s.Update(stale())
+	if !s.loading {
+		t.Fatal("a superseded result was applied")
+	}
+}
+
+func firstColumn(tb *Table) string {
+	var out []string
+	for i := 0; i < tb.Len(); i++ {
+		out = append(out, tb.Row(i)[0])
+	}
+	return strings.Join(out, " ")
+}
+
+func run(t *testing.T, m Model, cmd tea.Cmd) {
+	t.Helper()
+	if cmd == nil {
+		t.Fatal("expected a load command")
+	}
+	m.Update(cmd())
+}
@@ -0,0 +1,357 @@
+package structview
+
+import (
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/marcus/sidecar/internal/clip"
+	"github.com/marcus/sidecar/internal/msg"
+	"github.com/marcus/sidecar/internal/styles"
+)
+
+// maxColumnWidth caps how wide one column may grow, so a single long free-text
+// field cannot push every other column off screen.
+const maxColumnWidth = 40
+
+// columnGap separates columns.
+const columnGap = "  "
+
+// sortOrder is a column's sort state. Sorting cycles through all three, so
+// the file's own order is always one keypress away.
+type sortOrder int
+
+const (
+	sortNone sortOrder = iota
+	sortAsc
+	sortDesc
+)
+
+// Table is a column-aligned, sortable view of tabular data.
+type Table struct {
+	label   string
+	header  []string
+	rows    [][]string
+	order   []int // display position -> index into rows
+	widths  []int
+	numeric []bool // right-align and compare as numbers
+
+	col     int // selected column
+	colOff  int // first column on screen
+	sortCol int
+	sortBy  sortOrder
+	cur     cursor
+	// note is shown in the status row, e.g. that the file was cut short.
+	note string
+}
+
+// ParseDelimited parses CSV (comma ',') or TSV (comma '\t') text into a table
+// whose first record is the header. When truncated, the last line is dropped:
+// the host read only a prefix, so that record is probably cut in half.
+func ParseDelimited(content string, comma rune, truncated bool) (*Table, error) {
+	if truncated {
+		if i := strings.LastIndexByte(content, '\n'); i >= 0 {
+			content = content[:i]
+		}
+	}
+	r := csv.NewReader(strings.NewReader(content))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	if comma == '\t' {
+		// TSV has no quoting convention; a stray quote is just a character.
+		r.LazyQuotes = false
+	}
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no rows")
+	}
+	label := "csv table"
+	if comma == '\t' {
+		label = "tsv table"
+	}
+	t := NewTable(label, records[0], records[1:])
+	if truncated {
+		t.note = "first " + strconv.Itoa(len(t.rows)) + " rows"
+	}
+	return t, nil
+}
+
+// NewTable builds a table. Rows shorter than the header are padded; a row
+// longer than the header widens the table with unnamed columns.
+func NewTable(label string, header []string, rows [][]string) *Table {
+	cols := len(header)
+	for _, r := range rows {
+		if len(r) > cols {
+			cols = len(r)
+		}
+	}
+	header = pad(header, cols)
+	for i := range rows {
+		rows[i] = pad(rows[i], cols)
+	}
+	t := &Table{label: label, header: header, rows: rows, sortCol: -1}
+	t.order = make([]int, len(rows))
+	for i := range t.order {
+		t.order[i] = i
+	}
+	t.widths = make([]int, cols)
+	t.numeric = make([]bool, cols)
+	for c := 0; c < cols; c++ {
+		w := runewidth.StringWidth(header[c]) + 1 // room for the sort arrow
+		numeric, seen := true, false
+		for _, r := range rows {
+			w = max(w, runewidth.StringWidth(cellText(r[c])))
+			if r[c] == "" {
+				continue
+			}
+			seen = true
+			if _, err := strconv.ParseFloat(strings.TrimSpace(r[c]), 64); err != nil {
+				numeric = false
+			}
+		}
+		t.widths[c] = min(max(w, 1), maxColumnWidth)
+		t.numeric[c] = numeric && seen
+	}
+	return t
+}
+
+func pad(row []string, n int) []string {
+	for len(row) < n {
+		row = append(row, "")
+	}
+	return row
+}
+
+// cellText flattens a cell onto one line for display.
+func cellText(s string) string {
+	if strings.ContainsAny(s, "\r\n\t") {
+		s = strings.NewReplacer("\r\n", "⏎", "\n", "⏎", "\r", "⏎", "\t", " ").Replace(s)
+	}
+	return s
+}
+
+// Init implements Model.
+func (t *Table) Init() tea.Cmd { return nil }
+
+// Update implements Model.
+func (t *Table) Update(tea.Msg) tea.Cmd { return nil }
+
+// Label implements Model.
+func (t *Table) Label() string { return t.label }
+
+// Row returns the record shown at display position i.
+func (t *Table) Row(i int) []string { return t.rows[t.order[i]] }
+
+// Len returns the number of data rows.
+func (t *Table) Len() int { return len(t.rows) }
+
+// HandleKey implements Model.
+func (t *Table) HandleKey(key string) (bool, tea.Cmd) {
+	if t.cur.move(key, len(t.rows)) {
+		return true, nil
+	}
+	switch key {
+	case "right", "l":
+		if t.col < len(t.header)-1 {
+			t.col++
+		}
+	case "left":
+		// From the first column, left is the host's (back to the file tree).
+		if t.col == 0 {
+			return false, nil
+		}
+		t.col--
+	case "s":
+		t.cycleSort(t.col)
+	case "c":
+		if len(t.rows) == 0 || len(t.header) == 0 {
+			return true, nil
+		}
+		value := t.Row(t.cur.row)[t.col]
+		return true, clip.Copy(value, func(r clip.Result) tea.Msg {
+			return msg.FlashMsg{Text: r.Message("Copied cell")}
+		})
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// cycleSort advances col through ascending, descending and file order. The
+// cursor stays on the record it was on, wherever that record lands.
+func (t *Table) cycleSort(col int) {
+	if col < 0 || col >= len(t.header) {
+		return
+	}
+	selected := -1
+	if t.cur.row < len(t.order) {
+		selected = t.order[t.cur.row]
+	}
+	if t.sortCol != col {
+		t.sortCol, t.sortBy = col, sortAsc
+	} else {
+		t.sortBy = (t.sortBy + 1) % 3
+	}
+	for i := range t.order {
+		t.order[i] = i
+	}
+	if t.sortBy != sortNone {
+		numeric := t.numeric[col]
+		sort.SliceStable(t.order, func(a, b int) bool {
+			x, y := t.rows[t.order[a]][col], t.rows[t.order[b]][col]
+			if t.sortBy == sortDesc {
+				x, y = y, x
+			}
+			return compareCells(x, y, numeric)
+		})
+	}
+	for i, r := range t.order {
+		if r == selected {
+			t.cur.row = i
+			break
+		}
+	}
+}
+
+// compareCells orders numbers numerically and text case-insensitively, with
+// empty cells after everything else.
+func compareCells(x, y string, numeric bool) bool {
+	if x == "" || y == "" {
+		return x != "" && y == ""
+	}
+	if numeric {
+		a, errA := strconv.ParseFloat(strings.TrimSpace(x), 64)
+		b, errB := strconv.ParseFloat(strings.TrimSpace(y), 64)
+		if errA == nil && errB == nil {
+			return a < b
+		}
+	}
+	return strings.ToLower(x) < strings.ToLower(y)
+}
+
+// visibleColumns returns the columns from colOff that fit in width, first
+// scrolling colOff so the selected column is among them.
+func (t *Table) visibleColumns(width int) []int {
+	if t.col < t.colOff {
+		t.colOff = t.col
+	}
+	fits := func(from int) []int {
+		var cols []int
+		used := 0
+		for c := from; c < len(t.header); c++ {
+			need := t.widths[c]
+			if len(cols) > 0 {
+				need += len(columnGap)
+			}
+			if len(cols) > 0 && used+need > width {
+				break
+			}
+			cols = append(cols, c)
+			used += need
+		}
+		return cols
+	}
+	cols := fits(t.colOff)
+	for len(cols) > 0 && cols[len(cols)-1] < t.col && t.colOff < t.col {
+		t.colOff++
+		cols = fits(t.colOff)
+	}
+	return cols
+}
+
+// View implements Model.
+func (t *Table) View(width, height int) string {
+	if len(t.header) == 0 {
+		return styles.Muted.Render("(empty)")
+	}
+	cols := t.visibleColumns(width)
+	// Header, rule and status rows frame the data.
+	start, end := t.cur.window(len(t.rows), height-3)
+
+	headStyle := lipgloss.NewStyle().Bold(true).Foreground(styles.Primary)
+	selHead := headStyle.Underline(true)
+	var b strings.Builder
+	var head []string
+	for _, c := range cols {
+		name := t.header[c]
+		if c == t.sortCol {
+			switch t.sortBy {
+			case sortAsc:
+				name += "↑"
+			case sortDesc:
+				name += "↓"
+			}
+		}
+		style := headStyle
+		if c == t.col {
+			style = selHead
+		}
+		head = append(head, style.Render(t.cell(name, c)))
+	}
+	b.WriteString(fit(strings.Join(head, columnGap), width))
+	b.WriteString("\n")
+	ruleWidth := 0
+	for i, c := range cols {
+		if i > 0 {
+			ruleWidth += len(columnGap)
+		}
+		ruleWidth += t.widths[c]
+	}
+	b.WriteString(styles.Muted.Render(strings.Repeat("─", min(ruleWidth, width))))
+	b.WriteString("\n")
+
+	for i := start; i < end; i++ {
+		record := t.Row(i)
+		var cells []string
+		for _, c := range cols {
+			cells = append(cells, t.cell(cellText(record[c]), c))
+		}
+		line := fit(strings.Join(cells, columnGap), width)
+		if i == t.cur.row {
+			line = styles.ListItemSelected.Render(padRight(line, width))
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	for i := end - start; i < height-3; i++ {
+		b.WriteString("\n")
+	}
+	b.WriteString(fit(styles.Muted.Render(t.status()), width))
+	return b.String()
+}
+
+// cell pads or truncates text to column c's width, right-aligning numbers.
+func (t *Table) cell(text string, c int) string {
+	w := t.widths[c]
+	if runewidth.StringWidth(text) > w {
+		text = runewidth.Truncate(text, w, "…")
+	}
+	if t.numeric[c] {
+		return runewidth.FillLeft(text, w)
+	}
+	return runewidth.FillRight(text, w)
+}
+
+func (t *Table) status() string {
+	parts := []string{}
+	if len(t.rows) > 0 {
+		parts = append(parts, fmt.Sprintf("row %d/%d", t.cur.row+1, len(t.rows)))
+	} else {
+		parts = append(parts, "no rows")
+	}
+	parts = append(parts, fmt.Sprintf("col %d/%d %s", t.col+1, len(t.header), t.header[t.col]))
+	if t.note != "" {
+		parts = append(parts, t.note)
+	}
+	return strings.Join(parts, " · ")
+}
@@ -0,0 +1,430 @@
+package structview
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/marcus/sidecar/internal/clip"
+	"github.com/marcus/sidecar/internal/msg"
+	"github.com/marcus/sidecar/internal/styles"
+)
+
+// treeOpenDepth is how deep a freshly parsed document starts expanded: enough
+// to see its shape without unfolding every record of a long list.
+const treeOpenDepth = 2
+
+type nodeKind int
+
+const (
+	nodeObject nodeKind = iota
+	nodeArray
+	nodeString
+	nodeNumber
+	nodeBool
+	nodeNull
+)
+
+// node is one value in a parsed document. Object keys keep their order in the
+// file; a preview that alphabetised them would not match what the reader sees
+// in an editor.
+type node struct {
+	key      string // object key, or "[i]" for an array element
+	path     string // jq-style path from the root, e.g. .items[0].name
+	kind     nodeKind
+	value    string // scalar text as written
+	children []*node
+	open     bool
+	depth    int
+}
+
+func (n *node) container() bool { return n.kind == nodeObject || n.kind == nodeArray }
+
+// Tree is a collapsible view of a JSON or YAML document.
+type Tree struct {
+	format string
+	root   *node
+	rows   []*node // open nodes, flattened in display order
+	cur    cursor
+}
+
+// ParseJSON parses a JSON document into a tree.
+func ParseJSON(content string) (*Tree, error) {
+	dec := json.NewDecoder(strings.NewReader(content))
+	dec.UseNumber()
+	root, err := decodeJSON(dec, "", ".", 0)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("unexpected data after the top-level value")
+	}
+	return newTree("json", root), nil
+}
+
+func decodeJSON(dec *json.Decoder, key, path string, depth int) (*node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	n := &node{key: key, path: path, depth: depth}
+	switch v := tok.(type) {
+	case json.Delim:
+		if v == '{' {
+			n.kind = nodeObject
+			for dec.More() {
+				kt, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				k, _ := kt.(string)
+				child, err := decodeJSON(dec, k, joinKey(path, k), depth+1)
+				if err != nil {
+					return nil, err
+				}
+				n.children = append(n.children, child)
+			}
+		} else {
+			n.kind = nodeArray
+			for i := 0; dec.More(); i++ {
+				child, err := decodeJSON(dec, "["+strconv.Itoa(i)+"]", joinIndex(path, i), depth+1)
+				if err != nil {
+					return nil, err
+				}
+				n.children = append(n.children, child)
+			}
+		}
+		// Closing delimiter.
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+	case string:
+		n.kind, n.value = nodeString, strconv.Quote(v)
+	case json.Number:
+		n.kind, n.value = nodeNumber, v.String()
+	case bool:
+		n.kind, n.value = nodeBool, strconv.FormatBool(v)
+	case nil:
+		n.kind, n.value = nodeNull, "null"
+	}
+	return n, nil
+}
+
+// ParseYAML parses a YAML stream into a tree. A stream of several documents
+// becomes an array with one element per document.
+func ParseYAML(content string) (*Tree, error) {
+	dec := yaml.NewDecoder(strings.NewReader(content))
+	var docs []*yaml.Node
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, &doc)
+	}
+	var root *node
+	switch len(docs) {
+	case 0:
+		root = &node{path: ".", kind: nodeNull, value: "null"}
+	case 1:
+		root = convertYAML(docs[0], "", ".", 0)
+	default:
+		root = &node{path: ".", kind: nodeArray}
+		for i, doc := range docs {
+			root.children = append(root.children, convertYAML(doc, "["+strconv.Itoa(i)+"]", joinIndex(".", i), 1))
+		}
+	}
+	return newTree("yaml", root), nil
+}
+
+func convertYAML(y *yaml.Node, key, path string, depth int) *node {
+	for y.Kind == yaml.DocumentNode && len(y.Content) > 0 {
+		y = y.Content[0]
+	}
+	if y.Kind == yaml.AliasNode && y.Alias != nil {
+		y = y.Alias
+	}
+	n := &node{key: key, path: path, depth: depth}
+	switch y.Kind {
+	case yaml.MappingNode:
+		n.kind = nodeObject
+		for i := 0; i+1 < len(y.Content); i += 2 {
+			k := y.Content[i].Value
+			n.children = append(n.children, convertYAML(y.Content[i+1], k, joinKey(path, k), depth+1))
+		}
+	case yaml.SequenceNode:
+		n.kind = nodeArray
+		for i, c := range y.Content {
+			n.children = append(n.children, convertYAML(c, "["+strconv.Itoa(i)+"]", joinIndex(path, i), depth+1))
+		}
+	default:
+		n.value = y.Value
+		switch y.ShortTag() {
+		case "!!int", "!!float":
+			n.kind = nodeNumber
+		case "!!bool":
+			n.kind = nodeBool
+		case "!!null":
+			n.kind, n.value = nodeNull, "null"
+		default:
+			n.kind = nodeString
+			n.value = strconv.Quote(y.Value)
+		}
+	}
+	return n
+}
+
+var plainKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// joinKey extends a path with an object key, bracket-quoting keys that are
+// not plain identifiers so the result pastes straight into jq or yq.
+func joinKey(path, key string) string {
+	if path == "." {
+		path = ""
+	}
+	if plainKey.MatchString(key) {
+		return path + "." + key
+	}
+	return path + "[" + strconv.Quote(key) + "]"
+}
+
+func joinIndex(path string, i int) string {
+	if path == "." {
+		path = ""
+	}
+	if path == "" {
+		return ".[" + strconv.Itoa(i) + "]"
+	}
+	return path + "[" + strconv.Itoa(i) + "]"
+}
+
+func newTree(format string, root *node) *Tree {
+	var open func(n *node)
+	open = func(n *node) {
+		n.open = n.container() && n.depth < treeOpenDepth
+		for _, c := range n.children {
+			open(c)
+		}
+	}
+	open(root)
+	root.open = true
+	t := &Tree{format: format, root: root}
+	t.flatten()
+	return t
+}
+
+// flatten rebuilds the visible rows. The root itself is not a row: the file
+// is the root, and its children are the top level.
+func (t *Tree) flatten() {
+	t.rows = t.rows[:0]
+	var walk func(n *node)
+	walk = func(n *node) {
+		t.rows = append(t.rows, n)
+		if n.open {
+			for _, c := range n.children {
+				walk(c)
+			}
+		}
+	}
+	if !t.root.container() {
+		t.rows = append(t.rows, t.root)
+		return
+	}
+	for _, c := range t.root.children {
+		walk(c)
+	}
+}
+
+// Init implements Model.
+func (t *Tree) Init() tea.Cmd { return nil }
+
+// Update implements Model.
+func (t *Tree) Update(tea.Msg) tea.Cmd { return nil }
+
+// Label implements Model.
+func (t *Tree) Label() string { return t.format + " tree" }
+
+// Selected returns the path of the node under the cursor.
+func (t *Tree) Selected() string {
+	if len(t.rows) == 0 {
+		return "."
+	}
+	t.cur.clamp(len(t.rows))
+	return t.rows[t.cur.row].path
+}
+
+// HandleKey implements Model.
+func (t *Tree) HandleKey(key string) (bool, tea.Cmd) {
+	if t.cur.move(key, len(t.rows)) {
+		return true, nil
+	}
+	if len(t.rows) == 0 {
+		return false, nil
+	}
+	n := t.rows[t.cur.row]
+	switch key {
+	case "enter", "space", " ":
+		if n.container() {
+			n.open = !n.open
+			t.flatten()
+		}
+	case "right", "l":
+		if n.container() && !n.open {
+			n.open = true
+			t.flatten()
+		} else if n.open && len(n.children) > 0 {
+			t.cur.row++
+		}
+	case "left":
+		// At the top level there is nothing to close or climb to; the host's
+		// own left (back to the file tree) takes over.
+		if n.container() && n.open {
+			n.open = false
+			t.flatten()
+		} else if !t.selectParent(n) {
+			return false, nil
+		}
+	case "Z":
+		t.setOpen(t.root, true)
+	case "z":
+		t.setOpen(t.root, false)
+		t.cur.row = 0
+	case "c":
+		path := n.path
+		return true, clip.Copy(path, func(r clip.Result) tea.Msg {
+			return msg.FlashMsg{Text: r.Message("Copied: " + path)}
+		})
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// selectParent moves the cursor to the row holding n, reporting false for a
+// top-level node.
+func (t *Tree) selectParent(n *node) bool {
+	for i := t.cur.row - 1; i >= 0; i-- {
+		if t.rows[i].depth < n.depth {
+			t.cur.row = i
+			return true
+		}
+	}
+	return false
+}
+
+// setOpen opens or closes every container, keeping the cursor on the node it
+// was on when that node is still visible.
+func (t *Tree) setOpen(n *node, open bool) {
+	var selected *node
+	if t.cur.row < len(t.rows) {
+		selected = t.rows[t.cur.row]
+	}
+	var walk func(n *node)
+	walk = func(n *node) {
+		if n.container() {
+			n.open = open
+		}
+		for _, c := range n.children {
+			walk(c)
+		}
+	}
+	walk(n)
+	t.root.open = true
+	t.flatten()
+	for i, r := range t.rows {
+		if r == selected {
+			t.cur.row = i
+			return
+		}
+	}
+}
+
+// View implements Model.
+func (t *Tree) View(width, height int) string {
+	if len(t.rows) == 0 {
+		return styles.Muted.Render("(empty)")
+	}
+	// The last row is the selected node's path, which is what "c" copies.
+	start, end := t.cur.window(len(t.rows), height-1)
+	keyStyle := lipgloss.NewStyle().Foreground(styles.Primary)
+	var b strings.Builder
+	for i := start; i < end; i++ {
+		n := t.rows[i]
+		var line strings.Builder
+		line.WriteString(strings.Repeat("  ", max(n.depth-1, 0)))
+		switch {
+		case n.container() && n.open:
+			line.WriteString("▾ ")
+		case n.container():
+			line.WriteString("▸ ")
+		default:
+			line.WriteString("  ")
+		}
+		if n.key != "" {
+			line.WriteString(keyStyle.Render(n.key))
+			if !n.container() {
+				line.WriteString(styles.Muted.Render(": "))
+			} else {
+				line.WriteString(" ")
+			}
+		}
+		if n.container() {
+			line.WriteString(styles.Muted.Render(summary(n)))
+		} else {
+			line.WriteString(scalarStyle(n.kind).Render(n.value))
+		}
+		row := fit(line.String(), width)
+		if i == t.cur.row {
+			row = styles.ListItemSelected.Render(padRight(row, width))
+		}
+		b.WriteString(row)
+		b.WriteString("\n")
+	}
+	b.WriteString(fit(styles.Muted.Render(t.rows[t.cur.row].path), width))
+	return b.String()
+}
+
+func summary(n *node) string {
+	count := len(n.children)
+	if n.kind == nodeObject {
+		if count == 1 {
+			return "{1 key}"
+		}
+		return fmt.Sprintf("{%d keys}", count)
+	}
+	if count == 1 {
+		return "[1 item]"
+	}
+	return fmt.Sprintf("[%d items]", count)
+}
+
+func scalarStyle(kind nodeKind) lipgloss.Style {
+	switch kind {
+	case nodeString:
+		return lipgloss.NewStyle().Foreground(styles.Success)
+	case nodeNumber:
+		return lipgloss.NewStyle().Foreground(styles.Accent)
+	case nodeBool:
+		return lipgloss.NewStyle().Foreground(styles.Secondary)
+	}
+	return styles.Muted
+}
+
+func padRight(s string, width int) string {
+	if w := lipgloss.Width(s); w < width {
+		return s + strings.Repeat(" ", width-w)
+	}
+	return s
+}
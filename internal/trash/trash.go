@@ -0,0 +1,267 @@
+// Package trash moves files to the freedesktop.org (XDG) trash and restores
+// them, so a delete from Sidecar is recoverable from Sidecar, the desktop's
+// file manager, or `gio trash --restore`.
+//
+// Only the home trash ($XDG_DATA_HOME/Trash) is used. A file on another
+// device is copied in and then removed, rather than trashed into a per-volume
+// .Trash-$uid directory: slower for huge trees, but it keeps one place to list
+// and restore from.
+package trash
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// infoSuffix is the extension of the metadata file kept per trashed item.
+const infoSuffix = ".trashinfo"
+
+// dateLayout is the spec's DeletionDate format, in local time.
+const dateLayout = "2006-01-02T15:04:05"
+
+// Trash is one trash directory, holding files/ and info/.
+type Trash struct {
+	Dir string
+}
+
+// Item is one trashed file or directory.
+type Item struct {
+	// Name identifies the item inside the trash.
+	Name string
+	// Path is where the item lived, and where Restore puts it back.
+	Path      string
+	DeletedAt time.Time
+	IsDir     bool
+}
+
+// Home returns the user's home trash, honoring XDG_DATA_HOME.
+func Home() (*Trash, error) {
+	data := os.Getenv("XDG_DATA_HOME")
+	if data == "" || !filepath.IsAbs(data) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("trash: %w", err)
+		}
+		data = filepath.Join(home, ".local", "share")
+	}
+	return &Trash{Dir: filepath.Join(data, "Trash")}, nil
+}
+
+func (t *Trash) filesDir() string { return filepath.Join(t.Dir, "files") }
+func (t *Trash) infoDir() string  { return filepath.Join(t.Dir, "info") }
+
+// Put moves the file or directory at path into the trash.
+func (t *Trash) Put(path string) (Item, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return Item{}, err
+	}
+	fi, err := os.Lstat(abs)
+	if err != nil {
+		return Item{}, err
+	}
+	if err := os.MkdirAll(t.filesDir(), 0o700); err != nil {
+		return Item{}, fmt.Errorf("trash: %w", err)
+	}
+	if err := os.MkdirAll(t.infoDir(), 0o700); err != nil {
+		return Item{}, fmt.Errorf("trash: %w", err)
+	}
+
+	// The info file is created first and exclusively: that is what claims a
+	// name, so two trashers can never pick the same one.
+	now := time.Now()
+	name, info, err := t.claim(filepath.Base(abs))
+	if err != nil {
+		return Item{}, err
+	}
+	body := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n", escapePath(abs), now.Format(dateLayout))
+	_, werr := info.WriteString(body)
+	cerr := info.Close()
+	if werr == nil {
+		werr = cerr
+	}
+	if werr == nil {
+		werr = move(abs, filepath.Join(t.filesDir(), name))
+	}
+	if werr != nil {
+		_ = os.Remove(filepath.Join(t.infoDir(), name+infoSuffix))
+		return Item{}, fmt.Errorf("trash %s: %w", filepath.Base(abs), werr)
+	}
+	return Item{Name: name, Path: abs, DeletedAt: now.Truncate(time.Second), IsDir: fi.IsDir()}, nil
+}
+
+// claim creates the info file for the first free variant of base.
+func (t *Trash) claim(base string) (string, *os.File, error) {
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	for i := 1; i < 10000; i++ {
+		name := base
+		if i > 1 {
+			name = stem + "." + strconv.Itoa(i) + ext
+		}
+		if _, err := os.Lstat(filepath.Join(t.filesDir(), name)); err == nil {
+			continue
+		}
+		f, err := os.OpenFile(filepath.Join(t.infoDir(), name+infoSuffix), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("trash: %w", err)
+		}
+		return name, f, nil
+	}
+	return "", nil, fmt.Errorf("trash: no free name for %s", base)
+}
+
+// List returns every item in the trash, newest first. Entries with a missing
+// or unreadable info file are skipped, as file managers do.
+func (t *Trash) List() ([]Item, error) {
+	entries, err := os.ReadDir(t.infoDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var items []Item
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), infoSuffix) {
+			continue
+		}
+		name := strings.TrimSuffix(e.Name(), infoSuffix)
+		it, ok := t.read(name)
+		if !ok {
+			continue
+		}
+		items = append(items, it)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if !items[i].DeletedAt.Equal(items[j].DeletedAt) {
+			return items[i].DeletedAt.After(items[j].DeletedAt)
+		}
+		return items[i].Name > items[j].Name
+	})
+	return items, nil
+}
+
+func (t *Trash) read(name string) (Item, bool) {
+	fi, err := os.Lstat(filepath.Join(t.filesDir(), name))
+	if err != nil {
+		return Item{}, false
+	}
+	f, err := os.Open(filepath.Join(t.infoDir(), name+infoSuffix))
+	if err != nil {
+		return Item{}, false
+	}
+	defer func() { _ = f.Close() }()
+	it := Item{Name: name, IsDir: fi.IsDir()}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		key, value, ok := strings.Cut(sc.Text(), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "Path":
+			if p, err := url.PathUnescape(value); err == nil {
+				it.Path = p
+			}
+		case "DeletionDate":
+			if d, err := time.ParseInLocation(dateLayout, value, time.Local); err == nil {
+				it.DeletedAt = d
+			}
+		}
+	}
+	// Relative paths belong to a per-volume trash; this one never writes them.
+	return it, filepath.IsAbs(it.Path)
+}
+
+// Restore moves it back to its original path. It refuses to overwrite
+// anything that has since been created there.
+func (t *Trash) Restore(it Item) error {
+	if _, err := os.Lstat(it.Path); err == nil {
+		return fmt.Errorf("%s already exists", it.Path)
+	}
+	if err := os.MkdirAll(filepath.Dir(it.Path), 0o755); err != nil {
+		return err
+	}
+	if err := move(filepath.Join(t.filesDir(), it.Name), it.Path); err != nil {
+		return fmt.Errorf("restore %s: %w", filepath.Base(it.Path), err)
+	}
+	return os.Remove(filepath.Join(t.infoDir(), it.Name+infoSuffix))
+}
+
+// escapePath percent-encodes path as the spec asks, leaving separators alone.
+func escapePath(path string) string {
+	return (&url.URL{Path: filepath.ToSlash(path)}).EscapedPath()
+}
+
+// move renames src to dst, copying and then removing when they are on
+// different devices.
+func move(src, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil || !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	if err := copyTree(src, dst); err != nil {
+		_ = os.RemoveAll(dst)
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+// copyTree copies a file, symlink or directory, keeping permissions.
+func copyTree(src, dst string) error {
+	fi, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	switch {
+	case fi.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, dst)
+	case fi.IsDir():
+		if err := os.Mkdir(dst, fi.Mode().Perm()); err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if err := copyTree(filepath.Join(src, e.Name()), filepath.Join(dst, e.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		in, err := os.Open(src)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = in.Close() }()
+		out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, fi.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, in); err != nil {
+			_ = out.Close()
+			return err
+		}
+		return out.Close()
+	}
+}
@@ -0,0 +1,99 @@
+package trash
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPutListRestore(t *testing.T) {
+	tr := &Trash{Dir: filepath.Join(t.TempDir(), "Trash")}
+	project := t.TempDir()
+	file := filepath.Join(project, "a file.txt")
+	dir := filepath.Join(project, "pkg")
+	if err := os.WriteFile(file, []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	fileItem, err := tr.Put(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tr.Put(dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Fatal("trashed file still in place")
+	}
+	info, err := os.ReadFile(filepath.Join(tr.Dir, "info", fileItem.Name+infoSuffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(info), "Path="+escapePath(file)+"\n") || !strings.Contains(string(info), "%20") {
+		t.Fatalf("info file does not record the escaped path:\n%s", info)
+	}
+
+	items, err := tr.List()
+	if err != nil || len(items) != 2 {
+		t.Fatalf("List = %v, %v", items, err)
+	}
+	var got *Item
+	for i := range items {
+		if items[i].Path == file {
+			got = &items[i]
+		}
+	}
+	if got == nil || got.IsDir || got.DeletedAt.IsZero() {
+		t.Fatalf("file item not listed correctly: %+v", items)
+	}
+
+	if err := tr.Restore(*got); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := os.ReadFile(file); err != nil || string(b) != "hello" {
+		t.Fatalf("restore did not bring the file back: %q, %v", b, err)
+	}
+	if items, _ := tr.List(); len(items) != 1 || !items[0].IsDir {
+		t.Fatalf("after restore List = %+v, want only the directory", items)
+	}
+}
+
+func TestPutSameNameTwiceAndRestoreRefusesOverwrite(t *testing.T) {
+	tr := &Trash{Dir: filepath.Join(t.TempDir(), "Trash")}
+	path := filepath.Join(t.TempDir(), "notes.md")
+	var names []string
+	for _, body := range []string{"one", "two"} {
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		it, err := tr.Put(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, it.Name)
+	}
+	if names[0] != "notes.md" || names[1] != "notes.2.md" {
+		t.Fatalf("names = %v", names)
+	}
+
+	items, _ := tr.List()
+	if err := tr.Restore(items[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Restore(items[1]); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("restoring over a live file should fail, got %v", err)
+	}
+}
+
+func TestHomeHonorsXDGDataHome(t *testing.T) {
+	data := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", data)
+	tr, err := Home()
+	if err != nil || tr.Dir != filepath.Join(data, "Trash") {
+		t.Fatalf("Home = %+v, %v", tr, err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/marcus/sidecar/internal/config"
@@ -136,14 +137,38 @@ func ReadRequest(path string) (Request, error) {
 	return req, nil
 }
 
+// localAcks routes the acks of requests raised inside this process (the
+// control API's) back to their waiter instead of onto disk. Every surface
+// acks through WriteAck, so this is the one place that can tell them apart.
+var localAcks sync.Map // request id -> chan Ack
+
+// AwaitAcks claims the acks for reqID: until release is called, WriteAck
+// sends them on the returned channel and writes no file. A request injected
+// with Watcher.Inject has no acks directory for a file to land in, and no
+// other process waiting to read one.
+func AwaitAcks(reqID string) (acks <-chan Ack, release func()) {
+	ch := make(chan Ack, 8)
+	localAcks.Store(reqID, ch)
+	return ch, func() { localAcks.Delete(reqID) }
+}
+
 // WriteAck writes an acknowledgement file for an instance.
 func WriteAck(stateDir, reqID string, action Action, ack Ack) error {
-	if err := config.AssertIsolatedPath(stateDir); err != nil {
-		return err
-	}
 	if ack.At.IsZero() {
 		ack.At = time.Now().UTC()
 	}
+	if ch, ok := localAcks.Load(reqID); ok {
+		select {
+		case ch.(chan Ack) <- ack:
+		default:
+			// Far more surfaces acking than any request has; the waiter has
+			// what it needs from the first few.
+		}
+		return nil
+	}
+	if err := config.AssertIsolatedPath(stateDir); err != nil {
+		return err
+	}
 	acksDir := AcksDirPath(stateDir, reqID, action)
 	if err := os.MkdirAll(acksDir, 0755); err != nil {
 		return err
@@ -215,3 +215,57 @@ func TestWatcherPicksUpNewRequest(t *testing.T) {
 		t.Fatal("timed out waiting for RequestMsg from watcher")
 	}
 }
+
+func TestInjectedRequestAcksStayInProcess(t *testing.T) {
+	stateDir := t.TempDir()
+	t.Setenv("SIDECAR_ISOLATED_STATE", "1")
+
+	w, err := NewWatcher(stateDir)
+	if err != nil {
+		t.Fatalf("NewWatcher: %v", err)
+	}
+	defer w.Stop()
+	w.Start()
+
+	req := Request{ID: NewRequestID(), Action: ActionOpen, Target: Target{Kind: TargetKindFile, Value: "main.go"}}
+	acks, release := AwaitAcks(req.ID)
+	defer release()
+	if !w.Inject(req) {
+		t.Fatal("Inject refused a fresh request")
+	}
+	if w.Inject(req) {
+		t.Fatal("the same request was injected twice")
+	}
+	select {
+	case msg := <-w.Messages():
+		if got := msg.(RequestMsg).Request.ID; got != req.ID {
+			t.Fatalf("delivered %s, want %s", got, req.ID)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("the injected request was never delivered")
+	}
+
+	if err := WriteAck(stateDir, req.ID, req.Action, Ack{Instance: "host-1-app", Status: StatusOpened}); err != nil {
+		t.Fatalf("WriteAck: %v", err)
+	}
+	select {
+	case ack := <-acks:
+		if ack.Status != StatusOpened || ack.At.IsZero() {
+			t.Fatalf("unexpected ack %+v", ack)
+		}
+	default:
+		t.Fatal("the ack did not reach its in-process waiter")
+	}
+	if _, err := os.Stat(AcksDirPath(stateDir, req.ID, req.Action)); !os.IsNotExist(err) {
+		t.Fatalf("a claimed ack was written to disk (stat err %v)", err)
+	}
+
+	// Released, acks go back to disk for whoever else asks.
+	release()
+	if err := WriteAck(stateDir, req.ID, req.Action, Ack{Instance: "host-1-app", Status: StatusOpened}); err != nil {
+		t.Fatalf("WriteAck: %v", err)
+	}
+	if found, _ := ReadAcks(stateDir, req.ID, req.Action); len(found) != 1 {
+		t.Fatalf("released acks = %d on disk, want 1", len(found))
+	}
+}
@@ -23,6 +23,10 @@ type Instance struct {
 	Project    string    `json:"project"`
 	WorkDir    string    `json:"workDir"`
 	StartedAt  time.Time `json:"startedAt"`
+	// Socket is the instance's control API socket, empty unless the
+	// control_api feature is on. It is how a client finds the API without
+	// guessing a path.
+	Socket string `json:"socket,omitempty"`
 }
 
 // InstancesDir returns and ensures the instance-presence directory.
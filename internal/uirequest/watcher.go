@@ -90,6 +90,29 @@ func (w *Watcher) Messages() <-chan tea.Msg {
 	return w.msgChan
 }
 
+// Inject delivers req as if it had been read from the requests directory. It
+// is how an in-process caller — the control API — uses the bus without a
+// file round trip: the request reaches every surface through the same
+// RequestMsg, and its acks come back through AwaitAcks. It reports false when
+// the watcher is stopped or its buffer is full.
+func (w *Watcher) Inject(req Request) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stopped {
+		return false
+	}
+	if _, exists := w.seen[req.ID]; exists {
+		return false
+	}
+	select {
+	case w.msgChan <- RequestMsg{Request: req}:
+		w.seen[req.ID] = time.Now().UTC()
+		return true
+	default:
+		return false
+	}
+}
+
 // Stop terminates the watcher and cleans up resources.
 func (w *Watcher) Stop() {
 	w.mu.Lock()
@@ -0,0 +1,601 @@
+// Package undojournal records what a Git write is about to change, so the
+// write can be undone from Sidecar. Before a discard, stash pop, force push,
+// merge, reset or worktree delete, the caller captures the refs it moves, the
+// index tree, the stash entry it drops and the contents of the files it
+// overwrites, and records the capture once the write has succeeded; file
+// contents and the index go into the repository's object database, so an
+// entry stays restorable until git gc prunes them.
+//
+// The journal is one JSON line per entry in the repository's common git
+// directory, shared by every worktree. It is append-only: an undo appends a
+// marker rather than rewriting the entry, and the file is compacted to the
+// newest entries once it grows past twice the limit. Appends and compaction
+// hold the same file lock, so a line written mid-compaction is not lost.
+package undojournal
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// fileName is the journal's name inside the common git directory.
+const fileName = "sidecar-undo.jsonl"
+
+// MaxEntries is how many entries Load returns and compaction keeps.
+const MaxEntries = 200
+
+// lockTimeout bounds the wait for another process appending to the journal.
+const lockTimeout = 5 * time.Second
+
+// Options selects what Capture saves and what Undo restores. HEAD and the
+// checked-out branch are always recorded, for display.
+type Options struct {
+	// Head restores HEAD and the checked-out branch on undo, for writes that
+	// move them. Writes that leave HEAD alone leave it out, so undoing them
+	// does not also discard commits made since.
+	Head bool
+	// Index saves the index as a tree, restored wholesale on undo.
+	Index bool
+	// Files are worktree-relative paths whose current contents are saved.
+	// Directories are walked; paths that do not exist are recorded as absent
+	// and removed again on undo.
+	Files []string
+	// Refs are full ref names whose current values are saved. A ref that does
+	// not exist yet is deleted on undo.
+	Refs []string
+	// Stash is a stash revision (stash@{0}) the write is about to drop.
+	Stash string
+	// Push records the remote branch a force push is about to overwrite.
+	Push *Push
+	// Worktree marks a capture taken just before the worktree is removed.
+	Worktree bool
+}
+
+// File is one saved worktree file.
+type File struct {
+	Path   string      `json:"path"`
+	Blob   string      `json:"blob,omitempty"`
+	Mode   fs.FileMode `json:"mode,omitempty"`
+	Absent bool        `json:"absent,omitempty"`
+}
+
+// Push is a remote branch before a force push.
+type Push struct {
+	Remote string `json:"remote"`
+	Branch string `json:"branch"`
+	Old    string `json:"old"` // Remote-tracking value before the push
+}
+
+// Entry is the state of one worktree before one write.
+type Entry struct {
+	ID        string            `json:"id"`
+	Time      time.Time         `json:"time"`
+	Operation string            `json:"operation"`
+	Dir       string            `json:"dir"`               // Worktree the write ran in
+	RepoDir   string            `json:"repoDir,omitempty"` // A worktree that survives a worktree delete
+	Branch    string            `json:"branch,omitempty"`  // Checked-out branch; empty when detached
+	Head      string            `json:"head,omitempty"`
+	MovesHead bool              `json:"movesHead,omitempty"`
+	Index     string            `json:"index,omitempty"` // Tree of the index
+	Refs      map[string]string `json:"refs,omitempty"`  // Ref name to value; empty value: absent
+	Stash     string            `json:"stash,omitempty"`
+	StashMsg  string            `json:"stashMessage,omitempty"`
+	Files     []File            `json:"files,omitempty"`
+	Push      *Push             `json:"push,omitempty"`
+	Worktree  bool              `json:"worktree,omitempty"`
+	Undone    bool              `json:"-"`
+}
+
+// Summary describes what undoing the entry restores, for lists.
+func (e Entry) Summary() string {
+	var parts []string
+	switch {
+	case e.Worktree:
+		parts = append(parts, "worktree "+filepath.Base(e.Dir))
+	case e.MovesHead && e.Head != "":
+		at := shortOID(e.Head)
+		if e.Branch != "" {
+			at = e.Branch + " at " + at
+		}
+		parts = append(parts, at)
+	}
+	refs := make([]string, 0, len(e.Refs))
+	for ref := range e.Refs {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+	for _, ref := range refs {
+		name := strings.TrimPrefix(strings.TrimPrefix(ref, "refs/heads/"), "refs/tags/")
+		if oid := e.Refs[ref]; oid != "" {
+			parts = append(parts, name+" at "+shortOID(oid))
+		} else {
+			parts = append(parts, "no "+name)
+		}
+	}
+	if e.Stash != "" {
+		parts = append(parts, "stash "+shortOID(e.Stash))
+	}
+	if n := len(e.Files); n == 1 {
+		parts = append(parts, e.Files[0].Path)
+	} else if n > 1 {
+		parts = append(parts, fmt.Sprintf("%d files", n))
+	}
+	if e.Push != nil {
+		parts = append(parts, e.Push.Remote+"/"+e.Push.Branch+" at "+shortOID(e.Push.Old))
+	}
+	return strings.Join(parts, " · ")
+}
+
+func shortOID(oid string) string {
+	return oid[:min(7, len(oid))]
+}
+
+// marker is the line an undo appends.
+type marker struct {
+	Undone string `json:"undone"`
+}
+
+// Begin captures the worktree at dir before a write and returns the function
+// the write calls with its result. The entry is recorded only when the write
+// succeeded, so the journal never lists a write that did not happen. A failed
+// capture or record never blocks the write.
+func Begin(ctx context.Context, dir, operation string, opts Options) func(writeErr error) {
+	e, err := Capture(ctx, dir, operation, opts)
+	return func(writeErr error) {
+		if err == nil && writeErr == nil {
+			_ = Record(ctx, dir, e)
+		}
+	}
+}
+
+// Snapshot captures the worktree at dir and records it straight away.
+func Snapshot(ctx context.Context, dir, operation string, opts Options) (*Entry, error) {
+	e, err := Capture(ctx, dir, operation, opts)
+	if err != nil {
+		return nil, err
+	}
+	return e, Record(ctx, dir, e)
+}
+
+// Capture saves the state opts selects in dir without recording it.
+func Capture(ctx context.Context, dir, operation string, opts Options) (*Entry, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	e := &Entry{ID: newID(), Time: time.Now(), Operation: operation, Dir: abs, Worktree: opts.Worktree, Push: opts.Push, MovesHead: opts.Head}
+	e.Head, _ = git(ctx, abs, "rev-parse", "--verify", "-q", "HEAD")
+	if ref, _ := git(ctx, abs, "symbolic-ref", "-q", "HEAD"); ref != "" {
+		e.Branch = strings.TrimPrefix(ref, "refs/heads/")
+	}
+	if opts.Index {
+		// write-tree refuses an index with conflicts; there is nothing
+		// consistent to restore then, so the entry simply goes without.
+		e.Index, _ = git(ctx, abs, "write-tree")
+	}
+	for _, ref := range opts.Refs {
+		if e.Refs == nil {
+			e.Refs = make(map[string]string)
+		}
+		e.Refs[ref], _ = git(ctx, abs, "rev-parse", "--verify", "-q", ref)
+	}
+	if opts.Stash != "" {
+		if e.Stash, err = git(ctx, abs, "rev-parse", "--verify", "-q", opts.Stash); err != nil {
+			return nil, fmt.Errorf("resolve %s: %w", opts.Stash, err)
+		}
+		e.StashMsg, _ = git(ctx, abs, "log", "-1", "--format=%s", e.Stash)
+	}
+	if e.Files, err = saveFiles(ctx, abs, opts.Files); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// saveFiles writes each file's contents into the object database.
+func saveFiles(ctx context.Context, dir string, paths []string) ([]File, error) {
+	var files []File
+	seen := make(map[string]bool)
+	add := func(rel string) error {
+		if seen[rel] {
+			return nil
+		}
+		seen[rel] = true
+		info, err := os.Lstat(filepath.Join(dir, rel))
+		if errors.Is(err, fs.ErrNotExist) {
+			files = append(files, File{Path: rel, Absent: true})
+			return nil
+		}
+		if err != nil || !info.Mode().IsRegular() {
+			return err // Symlinks and devices are not saved
+		}
+		blob, err := git(ctx, dir, "hash-object", "-w", "--no-filters", "--", rel)
+		if err != nil {
+			return fmt.Errorf("save %s: %w", rel, err)
+		}
+		files = append(files, File{Path: rel, Blob: blob, Mode: info.Mode().Perm()})
+		return nil
+	}
+	for _, p := range paths {
+		p = filepath.Clean(strings.TrimSuffix(p, "/"))
+		root := filepath.Join(dir, p)
+		info, err := os.Stat(root)
+		if err != nil || !info.IsDir() {
+			if err := add(p); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				if d != nil && d.IsDir() && d.Name() == ".git" {
+					return filepath.SkipDir
+				}
+				return err
+			}
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			return add(rel)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// Record appends e to the journal of the repository containing dir.
+func Record(ctx context.Context, dir string, e *Entry) error {
+	path, err := journalPath(ctx, dir)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return withLock(path, func() error {
+		if err := appendLine(path, data); err != nil {
+			return err
+		}
+		return compact(path)
+	})
+}
+
+// Load returns the repository's entries, newest first, at most MaxEntries.
+func Load(ctx context.Context, dir string) ([]Entry, error) {
+	path, err := journalPath(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := readJournal(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if len(entries) > MaxEntries {
+		entries = entries[len(entries)-MaxEntries:]
+	}
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, err
+}
+
+// Undo restores the state e recorded and marks it undone. Each part restores
+// independently; the first failure stops the undo and is returned, so a
+// partial undo is visible rather than silent.
+func Undo(ctx context.Context, e Entry) error {
+	if e.Undone {
+		return fmt.Errorf("%s was already undone", e.Operation)
+	}
+	dir := e.Dir
+	if e.Worktree {
+		if err := restoreWorktree(ctx, e); err != nil {
+			return err
+		}
+	}
+	for ref, oid := range e.Refs {
+		args := []string{"update-ref", "-m", "sidecar undo " + e.Operation, ref, oid}
+		if oid == "" {
+			args = []string{"update-ref", "-d", ref}
+		}
+		if _, err := git(ctx, dir, args...); err != nil {
+			return fmt.Errorf("restore %s: %w", ref, err)
+		}
+	}
+	if e.MovesHead && !e.Worktree {
+		if err := restoreHead(ctx, e); err != nil {
+			return err
+		}
+	}
+	if e.Index != "" {
+		if _, err := git(ctx, dir, "read-tree", e.Index); err != nil {
+			return fmt.Errorf("restore index: %w", err)
+		}
+		// read-tree drops the stat data, so every file would look modified
+		// to the next command that checks (reset --keep refuses). Refresh
+		// it; the exit status only reports which files really differ.
+		_, _ = git(ctx, dir, "update-index", "-q", "--refresh")
+	}
+	if e.Stash != "" {
+		if list, _ := git(ctx, dir, "log", "-g", "--format=%H", "refs/stash"); !containsLine(list, e.Stash) {
+			if _, err := git(ctx, dir, "stash", "store", "-m", e.StashMsg, e.Stash); err != nil {
+				return fmt.Errorf("restore stash: %w", err)
+			}
+		}
+	}
+	if err := restoreFiles(ctx, dir, e.Files); err != nil {
+		return err
+	}
+	if e.Push != nil {
+		current, _ := git(ctx, dir, "rev-parse", "--verify", "-q", "refs/remotes/"+e.Push.Remote+"/"+e.Push.Branch)
+		lease := "--force-with-lease=refs/heads/" + e.Push.Branch + ":" + current
+		if _, err := git(ctx, dir, "push", lease, e.Push.Remote, e.Push.Old+":refs/heads/"+e.Push.Branch); err != nil {
+			return fmt.Errorf("restore %s/%s: %w", e.Push.Remote, e.Push.Branch, err)
+		}
+	}
+	return markUndone(ctx, dir, e)
+}
+
+// restoreHead puts the worktree back on the recorded branch and commit.
+// reset --keep refuses rather than overwrite local changes a later edit made.
+func restoreHead(ctx context.Context, e Entry) error {
+	if e.Head == "" {
+		return nil
+	}
+	if e.Branch == "" {
+		head, _ := git(ctx, e.Dir, "rev-parse", "--verify", "-q", "HEAD")
+		if head == e.Head {
+			return nil
+		}
+		_, err := git(ctx, e.Dir, "checkout", "--detach", e.Head)
+		return err
+	}
+	if ref, _ := git(ctx, e.Dir, "symbolic-ref", "-q", "HEAD"); ref != "refs/heads/"+e.Branch {
+		if _, err := git(ctx, e.Dir, "switch", e.Branch); err != nil {
+			return fmt.Errorf("switch back to %s: %w", e.Branch, err)
+		}
+	}
+	if head, _ := git(ctx, e.Dir, "rev-parse", "--verify", "-q", "HEAD"); head != e.Head {
+		if _, err := git(ctx, e.Dir, "reset", "--keep", e.Head); err != nil {
+			return fmt.Errorf("move %s back to %s: %w", e.Branch, shortOID(e.Head), err)
+		}
+	}
+	return nil
+}
+
+// restoreWorktree adds a removed worktree back on its branch, recreating the
+// branch when it was deleted too.
+func restoreWorktree(ctx context.Context, e Entry) error {
+	if _, err := os.Stat(e.Dir); err == nil {
+		return fmt.Errorf("%s exists again; move it aside to restore the worktree", e.Dir)
+	}
+	args := []string{"worktree", "add", e.Dir}
+	if e.Branch != "" {
+		if oid, _ := git(ctx, e.RepoDir, "rev-parse", "--verify", "-q", "refs/heads/"+e.Branch); oid == "" {
+			if _, err := git(ctx, e.RepoDir, "update-ref", "refs/heads/"+e.Branch, e.Head); err != nil {
+				return fmt.Errorf("recreate branch %s: %w", e.Branch, err)
+			}
+		}
+		args = append(args, e.Branch)
+	} else {
+		args = append(args, "--detach", e.Head)
+	}
+	if _, err := git(ctx, e.RepoDir, args...); err != nil {
+		return fmt.Errorf("restore worktree: %w", err)
+	}
+	return nil
+}
+
+func restoreFiles(ctx context.Context, dir string, files []File) error {
+	for _, f := range files {
+		path := filepath.Join(dir, f.Path)
+		if f.Absent {
+			if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			continue
+		}
+		data, err := gitRaw(ctx, dir, "cat-file", "blob", f.Blob)
+		if err != nil {
+			return fmt.Errorf("read saved %s: %w", f.Path, err)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		mode := f.Mode
+		if mode == 0 {
+			mode = 0o644
+		}
+		if err := os.WriteFile(path, data, mode); err != nil {
+			return err
+		}
+		if err := os.Chmod(path, mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func markUndone(ctx context.Context, dir string, e Entry) error {
+	// A restored worktree's own dir is fine; a removed one's journal is
+	// reached through the surviving repository.
+	if e.Worktree && e.RepoDir != "" {
+		dir = e.RepoDir
+	}
+	path, err := journalPath(ctx, dir)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(marker{Undone: e.ID})
+	if err != nil {
+		return err
+	}
+	return withLock(path, func() error { return appendLine(path, data) })
+}
+
+func journalPath(ctx context.Context, dir string) (string, error) {
+	common, err := git(ctx, dir, "rev-parse", "--git-common-dir")
+	if err != nil {
+		return "", fmt.Errorf("locate undo journal: %w", err)
+	}
+	if !filepath.IsAbs(common) {
+		common = filepath.Join(dir, common)
+	}
+	return filepath.Join(common, fileName), nil
+}
+
+// withLock runs fn holding an exclusive flock on <path>.lock. Every append
+// and every compaction runs inside one.
+func withLock(path string, fn func() error) error {
+	lock, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = syscall.Flock(int(lock.Fd()), syscall.LOCK_UN)
+		_ = lock.Close()
+	}()
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		err = syscall.Flock(int(lock.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil {
+			break
+		}
+		if err != syscall.EWOULDBLOCK && err != syscall.EAGAIN {
+			return err
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("undo journal: lock acquisition timeout after %v", lockTimeout)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return fn()
+}
+
+func appendLine(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readJournal folds undo markers into the entries they mark, oldest first.
+func readJournal(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var entries []Entry
+	undone := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		var m marker
+		if json.Unmarshal(line, &m) == nil && m.Undone != "" {
+			undone[m.Undone] = true
+			continue
+		}
+		var e Entry
+		if json.Unmarshal(line, &e) != nil || e.ID == "" {
+			continue // A torn or foreign line
+		}
+		entries = append(entries, e)
+	}
+	for i := range entries {
+		entries[i].Undone = undone[entries[i].ID]
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Time.Before(entries[j].Time) })
+	return entries, scanner.Err()
+}
+
+// compact rewrites the journal with its newest MaxEntries entries once it has
+// grown past twice that many lines. The caller holds the journal lock.
+func compact(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil || bytes.Count(data, []byte("\n")) <= 2*MaxEntries {
+		return err
+	}
+	entries, err := readJournal(path)
+	if err != nil {
+		return err
+	}
+	entries = entries[max(0, len(entries)-MaxEntries):]
+	var buf bytes.Buffer
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+		if e.Undone {
+			line, _ = json.Marshal(marker{Undone: e.ID})
+			buf.Write(line)
+			buf.WriteByte('\n')
+		}
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func containsLine(text, line string) bool {
+	for _, l := range strings.Split(text, "\n") {
+		if strings.TrimSpace(l) == line {
+			return true
+		}
+	}
+	return false
+}
+
+func newID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// git runs git in dir and returns its trimmed output; failures carry stderr.
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	out, err := gitRaw(ctx, dir, args...)
+	return strings.TrimSpace(string(out)), err
+}
+
+func gitRaw(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if detail := strings.TrimSpace(stderr.String()); detail != "" {
+			return out, fmt.Errorf("git %s: %s", args[0], detail)
+		}
+		return out, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
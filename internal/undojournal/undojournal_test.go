@@ -0,0 +1,195 @@
+package undojournal
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func run(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func write(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func read(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// repo returns a repository with one commit of a.txt ("one").
+func repo(t *testing.T) string {
+	t.Helper()
+	t.Setenv("GIT_CONFIG_GLOBAL", "/dev/null")
+	t.Setenv("GIT_CONFIG_SYSTEM", "/dev/null")
+	for _, k := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(k, "Test")
+	}
+	for _, k := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(k, "test@example.com")
+	}
+	dir := t.TempDir()
+	run(t, dir, "init", "-q", "-b", "main")
+	write(t, filepath.Join(dir, "a.txt"), "one\n")
+	run(t, dir, "add", ".")
+	run(t, dir, "commit", "-qm", "one")
+	return dir
+}
+
+func TestUndoDiscardRestoresFilesAndIndex(t *testing.T) {
+	dir := repo(t)
+	ctx := context.Background()
+	write(t, filepath.Join(dir, "a.txt"), "staged\n")
+	run(t, dir, "add", "a.txt")
+	write(t, filepath.Join(dir, "a.txt"), "staged and edited\n")
+	if err := os.MkdirAll(filepath.Join(dir, "new"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write(t, filepath.Join(dir, "new", "b.txt"), "untracked\n")
+
+	if _, err := Snapshot(ctx, dir, "discard", Options{Index: true, Files: []string{"a.txt", "new/"}}); err != nil {
+		t.Fatal(err)
+	}
+	run(t, dir, "reset", "-q", "--hard")
+	run(t, dir, "clean", "-qfd")
+
+	entries, err := Load(ctx, dir)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("entries = %+v, err = %v", entries, err)
+	}
+	if got := entries[0].Summary(); !strings.Contains(got, "2 files") {
+		t.Fatalf("summary = %q", got)
+	}
+	if err := Undo(ctx, entries[0]); err != nil {
+		t.Fatal(err)
+	}
+	if got := read(t, filepath.Join(dir, "a.txt")); got != "staged and edited\n" {
+		t.Fatalf("a.txt = %q", got)
+	}
+	if got := read(t, filepath.Join(dir, "new", "b.txt")); got != "untracked\n" {
+		t.Fatalf("b.txt = %q", got)
+	}
+	if got := run(t, dir, "show", ":a.txt"); got != "staged" {
+		t.Fatalf("index a.txt = %q", got)
+	}
+
+	entries, _ = Load(ctx, dir)
+	if !entries[0].Undone {
+		t.Fatal("the entry should be marked undone")
+	}
+	if err := Undo(ctx, entries[0]); err == nil {
+		t.Fatal("an entry is undone once")
+	}
+}
+
+func TestUndoStashPopAndReset(t *testing.T) {
+	dir := repo(t)
+	ctx := context.Background()
+	write(t, filepath.Join(dir, "a.txt"), "stashed\n")
+	run(t, dir, "stash", "push", "-qm", "keep me")
+	stash := run(t, dir, "rev-parse", "stash@{0}")
+	message := run(t, dir, "stash", "list", "--format=%gs")
+
+	if _, err := Snapshot(ctx, dir, "stash pop", Options{Stash: "stash@{0}"}); err != nil {
+		t.Fatal(err)
+	}
+	run(t, dir, "stash", "pop", "-q")
+	run(t, dir, "checkout", "-q", "--", "a.txt")
+
+	write(t, filepath.Join(dir, "a.txt"), "two\n")
+	run(t, dir, "commit", "-qam", "two")
+	if _, err := Snapshot(ctx, dir, "reset", Options{Head: true}); err != nil {
+		t.Fatal(err)
+	}
+	head := run(t, dir, "rev-parse", "HEAD")
+	run(t, dir, "reset", "-q", "--hard", "HEAD~1")
+
+	entries, err := Load(ctx, dir)
+	if err != nil || len(entries) != 2 || entries[0].Operation != "reset" {
+		t.Fatalf("entries should be newest first: %+v", entries)
+	}
+	for _, e := range entries {
+		if err := Undo(ctx, e); err != nil {
+			t.Fatalf("undo %s: %v", e.Operation, err)
+		}
+	}
+	if got := run(t, dir, "rev-parse", "HEAD"); got != head {
+		t.Fatalf("HEAD = %s, want %s", got, head)
+	}
+	if got := run(t, dir, "rev-parse", "stash@{0}"); got != stash {
+		t.Fatalf("stash@{0} = %s, want the dropped %s", got, stash)
+	}
+	if got := run(t, dir, "stash", "list", "--format=%gs"); got != message {
+		t.Fatalf("stash message = %q", got)
+	}
+}
+
+func TestUndoWorktreeDelete(t *testing.T) {
+	dir := repo(t)
+	ctx := context.Background()
+	wt := filepath.Join(t.TempDir(), "feature")
+	run(t, dir, "worktree", "add", "-q", "-b", "feature", wt)
+	write(t, filepath.Join(wt, "a.txt"), "work in progress\n")
+	write(t, filepath.Join(wt, "notes.txt"), "untracked\n")
+
+	e, err := Capture(ctx, wt, "delete worktree", Options{Worktree: true, Refs: []string{"refs/heads/feature"}, Files: []string{"a.txt", "notes.txt"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.RepoDir = dir
+	if err := Record(ctx, dir, e); err != nil {
+		t.Fatal(err)
+	}
+	run(t, dir, "worktree", "remove", "--force", wt)
+	run(t, dir, "branch", "-qD", "feature")
+
+	entries, _ := Load(ctx, dir)
+	if len(entries) != 1 || !strings.Contains(entries[0].Summary(), "worktree feature") {
+		t.Fatalf("entries = %+v", entries)
+	}
+	if err := Undo(ctx, entries[0]); err != nil {
+		t.Fatal(err)
+	}
+	if got := run(t, wt, "symbolic-ref", "--short", "HEAD"); got != "feature" {
+		t.Fatalf("restored worktree is on %q", got)
+	}
+	if got := read(t, filepath.Join(wt, "a.txt")); got != "work in progress\n" {
+		t.Fatalf("a.txt = %q", got)
+	}
+	if got := read(t, filepath.Join(wt, "notes.txt")); got != "untracked\n" {
+		t.Fatalf("notes.txt = %q", got)
+	}
+}
+
+func TestBeginRecordsOnlyASucceededWrite(t *testing.T) {
+	dir := repo(t)
+	ctx := context.Background()
+	Begin(ctx, dir, "reset", Options{Head: true})(errors.New("git reset: refused"))
+	if entries, _ := Load(ctx, dir); len(entries) != 0 {
+		t.Fatalf("a failed write was recorded: %+v", entries)
+	}
+	Begin(ctx, dir, "reset", Options{Head: true})(nil)
+	if entries, _ := Load(ctx, dir); len(entries) != 1 || entries[0].Operation != "reset" {
+		t.Fatalf("entries = %+v", entries)
+	}
+}
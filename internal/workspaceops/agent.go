@@ -249,3 +249,42 @@ func paneIDWithRunner(ctx context.Context, sessionName string, runner TmuxRunner
 	}
 	return strings.TrimSpace(strings.SplitN(string(output), "\n", 2)[0])
 }
+
+// InterruptSession sends Ctrl-C to the session, which stops an agent's turn
+// and leaves the shell around it running.
+func InterruptSession(ctx context.Context, sessionName string) error {
+	return InterruptSessionWithRunner(ctx, sessionName, ExecTmuxRunner{})
+}
+
+func InterruptSessionWithRunner(ctx context.Context, sessionName string, runner TmuxRunner) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if _, err := runner.Run(ctx, "has-session", "-t", sessionName); err != nil {
+		return fmt.Errorf("session %s is not running", sessionName)
+	}
+	if output, err := runner.Run(ctx, "send-keys", "-t", sessionName, "C-c"); err != nil {
+		return fmt.Errorf("interrupt %s: %s: %w", sessionName, strings.TrimSpace(string(output)), err)
+	}
+	return nil
+}
+
+// StopWorktreeAgent stops the agent in a worktree session: Ctrl-C first, and
+// the session is closed if it is still there after grace. It is the stop path
+// behind the workspace plugin and `sidecar workspace stop`.
+func StopWorktreeAgent(ctx context.Context, sessionName string, grace time.Duration) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !SessionExists(sessionName) {
+		return nil
+	}
+	// A session that refuses the interrupt is still closed below.
+	_ = InterruptSession(ctx, sessionName)
+	select {
+	case <-time.After(grace):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return KillWorktreeSession(ctx, sessionName)
+}
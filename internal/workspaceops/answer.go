@@ -0,0 +1,51 @@
+package workspaceops
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/marcus/sidecar/internal/notify"
+)
+
+// AnswerAgent types a notification's quick action into the agent's session.
+// It is the one send path behind the toast, the centre and `sidecar notify
+// act`, so an approve is the same keystrokes whichever surface sent it.
+func AnswerAgent(ctx context.Context, sessionName string, strokes []notify.Keystroke) error {
+	return AnswerAgentWithRunner(ctx, sessionName, strokes, ExecTmuxRunner{})
+}
+
+func AnswerAgentWithRunner(ctx context.Context, sessionName string, strokes []notify.Keystroke, runner TmuxRunner) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	sessionName = strings.TrimSpace(sessionName)
+	if sessionName == "" {
+		return fmt.Errorf("no tmux session to answer")
+	}
+	// Checked first so a closed shell is reported as gone, not as whatever
+	// send-keys happens to print about a missing target.
+	if _, err := runner.Run(ctx, "has-session", "-t", sessionName); err != nil {
+		return fmt.Errorf("session %s is no longer running", sessionName)
+	}
+	for _, stroke := range strokes {
+		args := []string{"send-keys", "-t", sessionName}
+		switch {
+		case !stroke.Literal:
+			args = append(args, stroke.Keys)
+		case strings.Contains(stroke.Keys, ";"):
+			// tmux reads a trailing ; in argv as a command separator, so text
+			// carrying one goes as hex (see tty.SendLiteralToTmux).
+			args = append(args, "-H")
+			for _, b := range []byte(stroke.Keys) {
+				args = append(args, fmt.Sprintf("%02x", b))
+			}
+		default:
+			args = append(args, "-l", stroke.Keys)
+		}
+		if output, err := runner.Run(ctx, args...); err != nil {
+			return fmt.Errorf("send keys: %s: %w", strings.TrimSpace(string(output)), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package workspaceops
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/marcus/sidecar/internal/notify"
+)
+
+func TestAnswerAgentTypesIntoTheSession(t *testing.T) {
+	runner := &fakeTmuxRunner{sessionExists: true}
+	strokes, err := notify.Keystrokes(notify.ActionMessage, "use the v2 API; keep tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := AnswerAgentWithRunner(context.Background(), "sidecar-ws-auth", strokes, runner); err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(flattenCalls(runner.calls), "\n")
+	if !strings.HasPrefix(got, "has-session -t sidecar-ws-auth\nsend-keys -t sidecar-ws-auth -H 75 73 65") {
+		t.Fatalf("a message with a ; was not sent as hex:\n%s", got)
+	}
+	if !strings.HasSuffix(got, "\nsend-keys -t sidecar-ws-auth Enter") {
+		t.Fatalf("the message was not submitted:\n%s", got)
+	}
+
+	runner = &fakeTmuxRunner{sessionExists: true}
+	strokes, _ = notify.Keystrokes(notify.ActionApprove, "")
+	if err := AnswerAgentWithRunner(context.Background(), "s", strokes, runner); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(flattenCalls(runner.calls[1:]), "\n"); got != "send-keys -t s y\nsend-keys -t s Enter" {
+		t.Fatalf("approve sent\n%s", got)
+	}
+}
+
+func TestAnswerAgentRefusesAGoneSession(t *testing.T) {
+	runner := &fakeTmuxRunner{}
+	strokes, _ := notify.Keystrokes(notify.ActionDeny, "")
+	err := AnswerAgentWithRunner(context.Background(), "sidecar-sh-1", strokes, runner)
+	if err == nil || !strings.Contains(err.Error(), "no longer running") {
+		t.Fatalf("err = %v", err)
+	}
+	if len(runner.calls) != 1 {
+		t.Fatalf("keys were sent to a missing session: %v", runner.calls)
+	}
+}
@@ -0,0 +1,202 @@
+package workspaceops
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/marcus/sidecar/internal/config"
+	"github.com/marcus/sidecar/internal/shellstate"
+)
+
+// Creating a workspace from outside the TUI — `sidecar create` and the control
+// API — is the durable half of what the Create Workspace modal does: write the
+// shell or worktree, seed it, and leave selecting it to whichever instance is
+// showing the project. Both callers run exactly this, so a shell created over
+// the socket is the same shell the CLI would have made.
+
+// ShellWorkspaceSpec is one new managed shell in a project.
+type ShellWorkspaceSpec struct {
+	ProjectRoot string
+	// Existing is the project's recorded shells; the next free Shell N and
+	// session name are picked against them.
+	Existing []shellstate.Definition
+	// Name is a display name already normalized with shellstate.NormalizeName.
+	// Empty takes the next Shell N.
+	Name string
+	// Run executes a command in the new shell; Type types one without Enter.
+	Run, Type string
+}
+
+// ShellWorkspace is a shell CreateShellWorkspace made.
+type ShellWorkspace struct {
+	DisplayName, Session string
+	// SeedErr is a Run or Type that failed in a shell that does exist. It is
+	// not a creation failure: the shell is recorded and selectable either way.
+	SeedErr error
+}
+
+// CreateShellWorkspace records and starts a managed shell, then seeds it.
+func CreateShellWorkspace(ctx context.Context, spec ShellWorkspaceSpec) (ShellWorkspace, error) {
+	display, session := ShellNames(spec.ProjectRoot, spec.Existing)
+	if name := strings.TrimSpace(spec.Name); name != "" {
+		display = name
+	}
+	if _, err := CreateManagedShell(ManagedShellSpec{
+		ShellSpec: ShellSpec{
+			WorkDir:     spec.ProjectRoot,
+			SessionName: session,
+			DisplayName: display,
+		},
+		ProjectRoot: spec.ProjectRoot,
+	}); err != nil {
+		return ShellWorkspace{}, err
+	}
+
+	out := ShellWorkspace{DisplayName: display, Session: session}
+	if spec.Run != "" {
+		out.SeedErr = StartAgentInShell(ctx, session, spec.Run)
+	} else if spec.Type != "" {
+		out.SeedErr = TypeInShell(ctx, session, spec.Type)
+	}
+	return out, nil
+}
+
+// WorktreeWorkspaceSpec is one new worktree in a project.
+type WorktreeWorkspaceSpec struct {
+	// WorkDir is where the request came from; the new worktree branches from
+	// the worktree containing it.
+	WorkDir, ProjectRoot string
+	Name, Base           string
+	DirPrefix            bool
+	Setup                config.WorktreeSetupConfig
+	// AgentStart is the configured per-agent start commands.
+	AgentStart map[string]string
+	Agent      string
+	SkipPerms  bool
+	// Run executes a command in the worktree session, after the agent when
+	// both are given.
+	Run string
+	// NoLaunch records the worktree without starting a session.
+	NoLaunch bool
+	// OperationID names this creation in the recovery journal.
+	OperationID string
+}
+
+// WorktreeWorkspace is a worktree CreateWorktreeWorkspace made, and what
+// happened after: setup that failed and a launch that did not start are
+// reported here rather than undoing the worktree, which exists either way.
+type WorktreeWorkspace struct {
+	Record   *WorktreeRecord
+	Session  string
+	Outcomes []SetupOutcome
+	// LaunchErr is a session that did not start; it is only tried when no
+	// required setup failed.
+	LaunchErr error
+}
+
+// RequiredFailed is the required setup that failed, which is also what kept
+// the session from launching.
+func (w WorktreeWorkspace) RequiredFailed() []SetupOutcome {
+	return FailedSetup(w.Outcomes, true)
+}
+
+// PlanError is a worktree that could not be planned: a bad name or base, the
+// caller's to fix. Nothing has been written when it is returned.
+type PlanError struct{ Err error }
+
+func (e *PlanError) Error() string { return e.Err.Error() }
+func (e *PlanError) Unwrap() error { return e.Err }
+
+// CreateWorktreeWorkspace plans and creates a worktree, runs its configured
+// setup and launches its session. A non-nil error with a nil Record means
+// nothing was created; with a Record, the worktree exists but creation did not
+// finish, and the recovery journal holds what remains.
+func CreateWorktreeWorkspace(ctx context.Context, spec WorktreeWorkspaceSpec) (WorktreeWorkspace, error) {
+	workDir := spec.WorkDir
+	if workDir == "" {
+		workDir = spec.ProjectRoot
+	}
+	plan, err := ResolveWorktreePlan(ctx, workDir, spec.ProjectRoot, spec.Name, spec.Base, spec.DirPrefix, spec.Setup)
+	if err != nil {
+		return WorktreeWorkspace{}, &PlanError{Err: err}
+	}
+	if repoKey, keyErr := RepoKeyForPath(ctx, spec.ProjectRoot); keyErr == nil {
+		plan.RepoKey = repoKey
+	} else {
+		plan.RepoKey = StablePathKey(spec.ProjectRoot)
+	}
+	plan.OperationID = spec.OperationID
+	if plan.OperationID == "" {
+		plan.OperationID = fmt.Sprintf("op-%d", time.Now().UnixNano())
+	}
+	plan.AgentType = spec.Agent
+	plan.SkipPerms = spec.SkipPerms
+
+	record, err := ExecuteWorktree(ctx, plan.RepoKey, plan)
+	if record == nil {
+		return WorktreeWorkspace{}, err
+	}
+	out := WorktreeWorkspace{Record: record}
+	if journalErr := PersistPendingCreation(ctx, plan, record); journalErr != nil {
+		out.Outcomes = append(out.Outcomes, SetupOutcome{Kind: "journal", Action: "persist recovery", Required: true, Err: journalErr})
+	}
+	if err != nil {
+		return out, err
+	}
+	out.Outcomes = append(out.Outcomes, PersistWorktreeIdentity(ctx, plan)...)
+	out.Outcomes = append(out.Outcomes, RunConfiguredSetup(ctx, plan)...)
+
+	if len(out.RequiredFailed()) == 0 {
+		if journalErr := RemovePendingCreation(plan); journalErr != nil {
+			out.Outcomes = append(out.Outcomes, SetupOutcome{Kind: "journal", Action: "finalize pending creation", Required: true, Err: journalErr})
+		}
+	}
+
+	out.Session = WorktreeSessionName(record.Path, record.Name)
+	if spec.NoLaunch || len(out.RequiredFailed()) > 0 {
+		return out, nil
+	}
+	startAgent := spec.Agent != "" || spec.Run != ""
+	command := ""
+	if spec.Agent != "" {
+		command = ResolveAgentCommand(record.Path, spec.Agent, spec.AgentStart, spec.SkipPerms)
+	} else if spec.Run != "" {
+		command = spec.Run
+	}
+	_, out.LaunchErr = LaunchWorktreeSession(ctx, AgentLaunchSpec{
+		SessionName:  out.Session,
+		WorkDir:      record.Path,
+		AgentCommand: command,
+		Env:          BuildEnvOverrides(plan.MainWorktree),
+		StartAgent:   startAgent,
+	})
+	if out.LaunchErr == nil && spec.Agent != "" && spec.Run != "" {
+		out.LaunchErr = StartAgentInShell(ctx, out.Session, spec.Run)
+	}
+	return out, nil
+}
+
+// FailedSetup is the outcomes that failed, or with requiredOnly only the
+// required ones.
+func FailedSetup(outcomes []SetupOutcome, requiredOnly bool) []SetupOutcome {
+	var failed []SetupOutcome
+	for _, outcome := range outcomes {
+		if outcome.Err != nil && (!requiredOnly || outcome.Required) {
+			failed = append(failed, outcome)
+		}
+	}
+	return failed
+}
+
+// SummarizeSetup is failed outcomes as one line: "action: error; …".
+func SummarizeSetup(outcomes []SetupOutcome) string {
+	parts := make([]string, 0, len(outcomes))
+	for _, outcome := range outcomes {
+		if outcome.Err != nil {
+			parts = append(parts, outcome.Action+": "+outcome.Err.Error())
+		}
+	}
+	return strings.Join(parts, "; ")
+}
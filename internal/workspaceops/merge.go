@@ -0,0 +1,418 @@
+package workspaceops
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/marcus/sidecar/internal/undojournal"
+)
+
+// This file is the presentation-neutral direct merge: merge a worktree's
+// branch into the branch checked out elsewhere, then push the result. The
+// project workspace's merge workflow and `sidecar workspace merge` both run
+// it, so what a merge is allowed to touch is decided once.
+//
+// Every step re-proves the identity the caller reviewed before it writes:
+// the branch checked out, the HEAD it pointed at, a clean tree and no Git
+// operation in progress. Anything that moved underneath is a refusal, never a
+// thing to merge past.
+
+// DirectMergeRecovery describes the safe actions available after a failed merge.
+type DirectMergeRecovery string
+
+const (
+	DirectMergeRecoveryNone        DirectMergeRecovery = ""
+	DirectMergeRecoveryConflict    DirectMergeRecovery = "conflict"
+	DirectMergeRecoveryPushFailure DirectMergeRecovery = "push-failure"
+)
+
+// DirectMergeOperation is the immutable context and accumulated result of a merge.
+type DirectMergeOperation struct {
+	SourcePath   string
+	TargetPath   string
+	SourceBranch string
+	TargetBranch string
+	Remote       string
+	SourceOID    string
+	TargetOID    string // target HEAD captured during visible preflight
+	PreMergeOID  string // target HEAD immediately before merge, after ff-only update
+	MergeOID     string
+	Completed    []string
+	Recovery     DirectMergeRecovery
+	GitState     string
+	Err          error
+	Aborted      bool
+}
+
+// Clone returns a copy that shares nothing mutable with op, so a command can
+// run against it while op stays on screen.
+func (op *DirectMergeOperation) Clone() *DirectMergeOperation {
+	if op == nil {
+		return nil
+	}
+	clone := *op
+	clone.Completed = append([]string(nil), op.Completed...)
+	return &clone
+}
+
+// PreflightDirectMerge resolves and checks both checkouts without writing
+// anything: the source worktree at sourcePath on sourceBranch, and the one
+// worktree that has targetBranch checked out. The returned operation pins
+// both HEADs; RunDirectMerge refuses if either has moved since.
+func PreflightDirectMerge(ctx context.Context, repoPath, sourcePath, sourceBranch, targetBranch string) (*DirectMergeOperation, error) {
+	worktrees, err := ListWorktreeStates(ctx, repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("inventory worktrees: %w", err)
+	}
+	sourcePath = CanonicalWorktreePath(sourcePath)
+	var source, target *WorktreeState
+	for i := range worktrees {
+		wt := &worktrees[i]
+		if wt.Path == sourcePath {
+			source = wt
+		}
+		if wt.Branch == targetBranch {
+			if target != nil {
+				return nil, fmt.Errorf("target branch %q is checked out more than once", targetBranch)
+			}
+			target = wt
+		}
+	}
+	if source == nil {
+		return nil, fmt.Errorf("source worktree %q is not registered", sourcePath)
+	}
+	if source.Branch != sourceBranch || source.Detached || source.Bare || source.Locked || source.Prunable {
+		return nil, fmt.Errorf("source worktree is not a safe checkout of %q", sourceBranch)
+	}
+	if target == nil {
+		return nil, fmt.Errorf("target branch %q must be checked out in a worktree", targetBranch)
+	}
+	if target.Path == source.Path {
+		return nil, fmt.Errorf("source and target resolve to the same worktree")
+	}
+	if target.Detached || target.Bare || target.Locked || target.Prunable {
+		return nil, fmt.Errorf("target worktree is not safe to update")
+	}
+	for _, checkout := range []*WorktreeState{source, target} {
+		if err := requireClean(ctx, checkout.Path); err != nil {
+			return nil, err
+		}
+		if state := WorktreeOperationState(ctx, checkout.Path); state != "clean" {
+			return nil, fmt.Errorf("worktree %q has a Git operation in progress: %s", checkout.Path, state)
+		}
+	}
+	sourceOID, err := gitOutput(ctx, source.Path, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("resolve source HEAD: %w", err)
+	}
+	sourceRefOID, err := gitOutput(ctx, repoPath, "rev-parse", "refs/heads/"+sourceBranch)
+	if err != nil || sourceRefOID != sourceOID {
+		return nil, fmt.Errorf("source branch moved or does not match its checkout")
+	}
+	targetOID, err := gitOutput(ctx, target.Path, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("resolve target HEAD: %w", err)
+	}
+	targetRefOID, err := gitOutput(ctx, repoPath, "rev-parse", "refs/heads/"+targetBranch)
+	if err != nil || targetRefOID != targetOID {
+		return nil, fmt.Errorf("target branch moved or does not match its checkout")
+	}
+	remote, err := ResolveBranchRemote(ctx, repoPath, targetBranch)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := gitOutput(ctx, repoPath, "ls-remote", "--exit-code", "--heads", remote, "refs/heads/"+targetBranch); err != nil {
+		return nil, fmt.Errorf("remote %q does not expose target branch %q: %w", remote, targetBranch, err)
+	}
+	return &DirectMergeOperation{
+		SourcePath: source.Path, TargetPath: target.Path,
+		SourceBranch: sourceBranch, TargetBranch: targetBranch, Remote: remote,
+		SourceOID: sourceOID, TargetOID: targetOID,
+		Completed: []string{"preflight"},
+	}, nil
+}
+
+// RunDirectMerge fast-forwards the target from its remote, merges the source
+// with --no-ff and pushes. A conflict leaves the merge in progress with
+// Recovery set to conflict; a rejected push keeps the merge commit with
+// Recovery set to push-failure.
+func RunDirectMerge(ctx context.Context, op *DirectMergeOperation) *DirectMergeOperation {
+	return RunDirectMergeWithHooks(ctx, op, nil, nil)
+}
+
+// RunDirectMergeWithHooks is RunDirectMerge with callbacks just before the
+// fast-forward and just before the merge, the two windows in which the tests
+// move a checkout underneath it.
+func RunDirectMergeWithHooks(ctx context.Context, op *DirectMergeOperation, beforePull, beforeMerge func()) *DirectMergeOperation {
+	if op == nil {
+		return &DirectMergeOperation{Err: fmt.Errorf("missing direct merge operation")}
+	}
+	if err := revalidateDirectMerge(ctx, op); err != nil {
+		return failDirectMerge(ctx, op, err, DirectMergeRecoveryNone)
+	}
+	if _, err := gitOutput(ctx, op.TargetPath, "fetch", op.Remote, op.TargetBranch); err != nil {
+		return failDirectMerge(ctx, op, fmt.Errorf("fetch %s: %w", op.Remote, err), DirectMergeRecoveryNone)
+	}
+	op.Completed = append(op.Completed, "fetch")
+	if beforePull != nil {
+		beforePull()
+	}
+	if err := requireClean(ctx, op.TargetPath); err != nil {
+		return failDirectMerge(ctx, op, fmt.Errorf("target changed before fast-forward: %w", err), DirectMergeRecoveryNone)
+	}
+	if state := WorktreeOperationState(ctx, op.TargetPath); state != "clean" {
+		return failDirectMerge(ctx, op, fmt.Errorf("target started a Git operation before fast-forward: %s", state), DirectMergeRecoveryNone)
+	}
+	if err := requireReviewedCheckout(ctx, op.TargetPath, op.TargetBranch, op.TargetOID); err != nil {
+		return failDirectMerge(ctx, op, fmt.Errorf("target checkout changed before fast-forward: %w", err), DirectMergeRecoveryNone)
+	}
+	if _, err := gitOutput(ctx, op.TargetPath, "pull", "--ff-only", op.Remote, op.TargetBranch); err != nil {
+		return failDirectMerge(ctx, op, fmt.Errorf("fast-forward target: %w", err), DirectMergeRecoveryNone)
+	}
+	op.Completed = append(op.Completed, "fast-forward target")
+	postPullOID, err := gitOutput(ctx, op.TargetPath, "rev-parse", "HEAD")
+	if err != nil {
+		return failDirectMerge(ctx, op, fmt.Errorf("pin post-pull target HEAD: %w", err), DirectMergeRecoveryNone)
+	}
+	if postPullOID == "" {
+		return failDirectMerge(ctx, op, fmt.Errorf("pin post-pull target HEAD: empty OID"), DirectMergeRecoveryNone)
+	}
+	op.PreMergeOID = postPullOID
+	if err := requireClean(ctx, op.TargetPath); err != nil {
+		return failDirectMerge(ctx, op, err, DirectMergeRecoveryNone)
+	}
+	if beforeMerge != nil {
+		beforeMerge()
+	}
+	if err := requireClean(ctx, op.SourcePath); err != nil {
+		return failDirectMerge(ctx, op, fmt.Errorf("source changed after review: %w", err), DirectMergeRecoveryNone)
+	}
+	if state := WorktreeOperationState(ctx, op.SourcePath); state != "clean" {
+		return failDirectMerge(ctx, op, fmt.Errorf("source started a Git operation after review: %s", state), DirectMergeRecoveryNone)
+	}
+	if err := requireReviewedCheckout(ctx, op.SourcePath, op.SourceBranch, op.SourceOID); err != nil {
+		return failDirectMerge(ctx, op, fmt.Errorf("source checkout changed before merge: %w", err), DirectMergeRecoveryNone)
+	}
+	if err := requireReviewedCheckout(ctx, op.TargetPath, op.TargetBranch, op.PreMergeOID); err != nil {
+		return failDirectMerge(ctx, op, fmt.Errorf("target checkout changed before merge: %w", err), DirectMergeRecoveryNone)
+	}
+	// Recorded so the merge can be undone from the Git plugin's undo history.
+	record := undojournal.Begin(ctx, op.TargetPath, "merge "+op.SourceBranch, undojournal.Options{Head: true})
+	message := fmt.Sprintf("Merge branch '%s'", op.SourceBranch)
+	_, err = gitOutput(ctx, op.TargetPath, "merge", "--no-ff", op.SourceOID, "-m", message)
+	record(err)
+	if err != nil {
+		if WorktreeOperationState(ctx, op.TargetPath) == "merge" {
+			return failDirectMerge(ctx, op, fmt.Errorf("merge conflict: %w", err), DirectMergeRecoveryConflict)
+		}
+		return failDirectMerge(ctx, op, fmt.Errorf("merge: %w", err), DirectMergeRecoveryNone)
+	}
+	op.Completed = append(op.Completed, "merge")
+	op.MergeOID, _ = gitOutput(ctx, op.TargetPath, "rev-parse", "HEAD")
+	return pushDirectMerge(ctx, op)
+}
+
+// ContinueDirectMerge commits a conflicted merge once every conflict is
+// resolved, then pushes it.
+func ContinueDirectMerge(ctx context.Context, op *DirectMergeOperation) *DirectMergeOperation {
+	if op == nil || op.Recovery != DirectMergeRecoveryConflict {
+		return failDirectMerge(ctx, op, fmt.Errorf("no conflicted merge is available to continue"), DirectMergeRecoveryNone)
+	}
+	if err := revalidateConflictRecovery(ctx, op); err != nil {
+		return failDirectMerge(ctx, op, err, DirectMergeRecoveryConflict)
+	}
+	if unmerged, _ := gitOutput(ctx, op.TargetPath, "diff", "--name-only", "--diff-filter=U"); unmerged != "" {
+		return failDirectMerge(ctx, op, fmt.Errorf("resolve all conflicts before continuing: %s", strings.ReplaceAll(unmerged, "\n", ", ")), DirectMergeRecoveryConflict)
+	}
+	cmd := exec.CommandContext(ctx, "git", "-c", "core.editor=true", "merge", "--continue")
+	cmd.Dir = op.TargetPath
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return failDirectMerge(ctx, op, fmt.Errorf("continue merge: %s: %w", strings.TrimSpace(string(out)), err), DirectMergeRecoveryConflict)
+	}
+	op.Completed = appendUnique(op.Completed, "merge")
+	op.MergeOID, _ = gitOutput(ctx, op.TargetPath, "rev-parse", "HEAD")
+	op.Recovery, op.Err = DirectMergeRecoveryNone, nil
+	return pushDirectMerge(ctx, op)
+}
+
+// AbortDirectMerge abandons a conflicted merge and proves the target is back
+// at the HEAD it had just before the merge began.
+func AbortDirectMerge(ctx context.Context, op *DirectMergeOperation) *DirectMergeOperation {
+	if op == nil || op.Recovery != DirectMergeRecoveryConflict {
+		return failDirectMerge(ctx, op, fmt.Errorf("no conflicted merge is available to abort"), DirectMergeRecoveryNone)
+	}
+	if err := revalidateConflictRecovery(ctx, op); err != nil {
+		return failDirectMerge(ctx, op, err, DirectMergeRecoveryConflict)
+	}
+	if _, err := gitOutput(ctx, op.TargetPath, "merge", "--abort"); err != nil {
+		return failDirectMerge(ctx, op, fmt.Errorf("abort merge: %w", err), DirectMergeRecoveryConflict)
+	}
+	head, _ := gitOutput(ctx, op.TargetPath, "rev-parse", "HEAD")
+	if head != op.PreMergeOID {
+		return failDirectMerge(ctx, op, fmt.Errorf("merge aborted but target HEAD is %s, expected %s", head, op.PreMergeOID), DirectMergeRecoveryNone)
+	}
+	op.Recovery, op.Err = DirectMergeRecoveryNone, nil
+	op.Aborted = true
+	op.GitState = currentGitState(ctx, op.TargetPath)
+	return op
+}
+
+// RetryDirectMergePush pushes a merge whose first push was rejected, provided
+// the target still holds exactly that merge.
+func RetryDirectMergePush(ctx context.Context, op *DirectMergeOperation) *DirectMergeOperation {
+	if op == nil || op.Recovery != DirectMergeRecoveryPushFailure {
+		return failDirectMerge(ctx, op, fmt.Errorf("no failed push is available to retry"), DirectMergeRecoveryNone)
+	}
+	if state := WorktreeOperationState(ctx, op.TargetPath); state != "clean" {
+		return failDirectMerge(ctx, op, fmt.Errorf("cannot retry push during Git operation: %s", state), DirectMergeRecoveryPushFailure)
+	}
+	if err := requireClean(ctx, op.TargetPath); err != nil {
+		return failDirectMerge(ctx, op, err, DirectMergeRecoveryPushFailure)
+	}
+	if err := requireReviewedCheckout(ctx, op.TargetPath, op.TargetBranch, op.MergeOID); err != nil {
+		return failDirectMerge(ctx, op, fmt.Errorf("target checkout changed since the failed push: %w", err), DirectMergeRecoveryPushFailure)
+	}
+	return pushDirectMerge(ctx, op)
+}
+
+func pushDirectMerge(ctx context.Context, op *DirectMergeOperation) *DirectMergeOperation {
+	if op.MergeOID == "" {
+		return failDirectMerge(ctx, op, fmt.Errorf("merge result OID is not pinned"), DirectMergeRecoveryPushFailure)
+	}
+	if state := WorktreeOperationState(ctx, op.TargetPath); state != "clean" {
+		return failDirectMerge(ctx, op, fmt.Errorf("cannot push during Git operation: %s", state), DirectMergeRecoveryPushFailure)
+	}
+	if err := requireClean(ctx, op.TargetPath); err != nil {
+		return failDirectMerge(ctx, op, err, DirectMergeRecoveryPushFailure)
+	}
+	if err := requireReviewedCheckout(ctx, op.TargetPath, op.TargetBranch, op.MergeOID); err != nil {
+		return failDirectMerge(ctx, op, fmt.Errorf("target checkout changed before push: %w", err), DirectMergeRecoveryPushFailure)
+	}
+	refspec := "HEAD:refs/heads/" + op.TargetBranch
+	if _, err := gitOutput(ctx, op.TargetPath, "push", op.Remote, refspec); err != nil {
+		return failDirectMerge(ctx, op, fmt.Errorf("push %s %s: %w", op.Remote, op.TargetBranch, err), DirectMergeRecoveryPushFailure)
+	}
+	op.Completed = appendUnique(op.Completed, "push")
+	op.Recovery, op.Err = DirectMergeRecoveryNone, nil
+	op.GitState = currentGitState(ctx, op.TargetPath)
+	return op
+}
+
+func revalidateConflictRecovery(ctx context.Context, op *DirectMergeOperation) error {
+	if op.SourceOID == "" || op.PreMergeOID == "" {
+		return fmt.Errorf("conflict recovery identity is incomplete")
+	}
+	if err := requireReviewedCheckout(ctx, op.TargetPath, op.TargetBranch, op.PreMergeOID); err != nil {
+		return fmt.Errorf("refusing recovery because target checkout changed: %w", err)
+	}
+	if state := WorktreeOperationState(ctx, op.TargetPath); state != "merge" {
+		return fmt.Errorf("the target no longer has the expected merge in progress")
+	}
+	mergeHead, err := gitOutput(ctx, op.TargetPath, "rev-parse", "MERGE_HEAD")
+	if err != nil || mergeHead != op.SourceOID {
+		return fmt.Errorf("refusing recovery because MERGE_HEAD changed: got %q, expected %q", mergeHead, op.SourceOID)
+	}
+	return nil
+}
+
+func revalidateDirectMerge(ctx context.Context, op *DirectMergeOperation) error {
+	checks := []struct{ path, branch, want string }{
+		{op.SourcePath, op.SourceBranch, op.SourceOID},
+		{op.TargetPath, op.TargetBranch, op.TargetOID},
+	}
+	for _, check := range checks {
+		if state := WorktreeOperationState(ctx, check.path); state != "clean" {
+			return fmt.Errorf("worktree %q has a Git operation in progress: %s", check.path, state)
+		}
+		if err := requireClean(ctx, check.path); err != nil {
+			return err
+		}
+		if err := requireReviewedCheckout(ctx, check.path, check.branch, check.want); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// requireReviewedCheckout is requireCheckoutIdentity in the merge's terms: a
+// HEAD that moved did so after the user reviewed the diff.
+func requireReviewedCheckout(ctx context.Context, path, branch, oid string) error {
+	if err := requireCheckoutIdentity(ctx, path, branch, ""); err != nil {
+		return err
+	}
+	if oid == "" {
+		return nil
+	}
+	head, err := gitOutput(ctx, path, "rev-parse", "HEAD")
+	if err != nil || head != oid {
+		return fmt.Errorf("HEAD changed after review in %q", path)
+	}
+	return nil
+}
+
+func requireClean(ctx context.Context, path string) error {
+	dirty, err := WorktreeIsDirty(ctx, path)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("worktree %q is dirty", path)
+	}
+	return nil
+}
+
+func failDirectMerge(ctx context.Context, op *DirectMergeOperation, err error, recovery DirectMergeRecovery) *DirectMergeOperation {
+	if op == nil {
+		op = &DirectMergeOperation{}
+	}
+	op.Err, op.Recovery = err, recovery
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if op.TargetPath != "" {
+		op.GitState = currentGitState(ctx, op.TargetPath)
+	}
+	return op
+}
+
+// ResolveBranchRemote names the remote branch is pushed to: its configured
+// upstream remote, or the repository's only remote.
+func ResolveBranchRemote(ctx context.Context, repoPath, branch string) (string, error) {
+	if remote, err := gitOutput(ctx, repoPath, "config", "--get", "branch."+branch+".remote"); err == nil && remote != "" && remote != "." {
+		return remote, nil
+	}
+	remotes, err := gitOutput(ctx, repoPath, "remote")
+	if err != nil {
+		return "", fmt.Errorf("list remotes: %w", err)
+	}
+	items := strings.Fields(remotes)
+	if len(items) == 1 {
+		return items[0], nil
+	}
+	if len(items) == 0 {
+		return "", fmt.Errorf("no remote is configured for target branch %q", branch)
+	}
+	return "", fmt.Errorf("target branch %q has no remote and repository has multiple remotes", branch)
+}
+
+func currentGitState(ctx context.Context, path string) string {
+	head, _ := gitOutput(ctx, path, "rev-parse", "--short", "HEAD")
+	branch, _ := gitOutput(ctx, path, "branch", "--show-current")
+	status, _ := gitOutput(ctx, path, "status", "--short")
+	state := fmt.Sprintf("target %s at %s; operation: %s", branch, head, WorktreeOperationState(ctx, path))
+	if status == "" {
+		return state + "; working tree clean"
+	}
+	return state + "; status:\n" + status
+}
+
+func appendUnique(items []string, item string) []string {
+	for _, existing := range items {
+		if existing == item {
+			return items
+		}
+	}
+	return append(items, item)
+}
@@ -28,6 +28,9 @@ func RunConfiguredSetup(ctx context.Context, plan *WorktreePlan) []SetupOutcome
 	if mainPath == "" {
 		mainPath = plan.MainWorktree
 	}
+	if plan.Sparse != nil {
+		outcomes = append(outcomes, SetupOutcome{Kind: "sparse-checkout", Action: "sparse-checkout " + plan.Sparse.Describe(), Err: ApplySparseCheckout(ctx, plan.Path, plan.Sparse)})
+	}
 	outcomes = append(outcomes, SetupOutcome{Kind: "td-root", Action: ".td-root", Err: tdroot.CreateTDRoot(plan.MainWorktree, plan.Path, mainPath)})
 	if plan.CopyEnv {
 		for _, rel := range plan.EnvFiles {
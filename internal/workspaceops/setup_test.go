@@ -42,3 +42,42 @@ func TestRunConfiguredSetupPreservesWorktreeEnvAndTDRoot(t *testing.T) {
 		t.Fatalf("shared setup did not report td-root: %+v", outcomes)
 	}
 }
+
+func TestApplySparseCheckoutCarriesTheSourceCone(t *testing.T) {
+	root := throwawayRepo(t)
+	for _, dir := range []string{"app", "docs"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, dir, "file"), []byte(dir+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git(t, root, "add", ".")
+	git(t, root, "commit", "-q", "-m", "dirs")
+	git(t, root, "sparse-checkout", "set", "--cone", "app")
+
+	sparse := ResolveSparseCheckout(context.Background(), root)
+	if sparse == nil || !sparse.Cone || len(sparse.Patterns) != 1 || sparse.Patterns[0] != "app" {
+		t.Fatalf("sparse = %+v, want cone [app]", sparse)
+	}
+
+	worktree := filepath.Join(filepath.Dir(root), "wt")
+	git(t, root, "worktree", "add", "-q", worktree, "HEAD")
+	git(t, worktree, "sparse-checkout", "disable")
+	if _, err := os.Stat(filepath.Join(worktree, "docs", "file")); err != nil {
+		t.Fatalf("full checkout missing docs/file: %v", err)
+	}
+	if err := ApplySparseCheckout(context.Background(), worktree, sparse); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(worktree, "docs", "file")); !os.IsNotExist(err) {
+		t.Fatalf("docs/file still checked out: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(worktree, "app", "file")); err != nil {
+		t.Fatalf("app/file missing: %v", err)
+	}
+	if err := ApplySparseCheckout(context.Background(), worktree, sparse); err != nil {
+		t.Fatalf("reapplying a matching definition: %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package workspaceops
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/marcus/sidecar/internal/gitstate"
+)
+
+// SparseCheckout is a sparse-checkout definition carried from the source
+// worktree to a new one.
+type SparseCheckout struct {
+	Cone     bool     `json:"cone"`
+	Patterns []string `json:"patterns"`
+}
+
+// ResolveSparseCheckout reads the sparse checkout of the worktree at dir, or
+// returns nil when it checks out everything.
+func ResolveSparseCheckout(ctx context.Context, dir string) *SparseCheckout {
+	s := gitstate.LoadSparse(ctx, dir)
+	if s == nil {
+		return nil
+	}
+	return &SparseCheckout{Cone: s.Cone, Patterns: append([]string(nil), s.Patterns...)}
+}
+
+// Describe summarizes the definition for confirmation screens.
+func (s *SparseCheckout) Describe() string {
+	if len(s.Patterns) == 0 {
+		return "sparse (no patterns)"
+	}
+	if s.Cone {
+		return "cone " + strings.Join(s.Patterns, ", ")
+	}
+	return fmt.Sprintf("%d non-cone patterns", len(s.Patterns))
+}
+
+// ApplySparseCheckout makes the worktree at dir check out what s describes.
+// A worktree that already matches is left alone.
+func ApplySparseCheckout(ctx context.Context, dir string, s *SparseCheckout) error {
+	if current := ResolveSparseCheckout(ctx, dir); current != nil && current.Cone == s.Cone && slices.Equal(current.Patterns, s.Patterns) {
+		return nil
+	}
+	mode := "--no-cone"
+	if s.Cone {
+		mode = "--cone"
+	}
+	cmd := exec.CommandContext(ctx, "git", "sparse-checkout", "set", mode, "--stdin")
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(strings.Join(s.Patterns, "\n") + "\n")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git sparse-checkout set: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	return nil
+}
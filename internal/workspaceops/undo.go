@@ -0,0 +1,46 @@
+package workspaceops
+
+import (
+	"context"
+	"strings"
+
+	"github.com/marcus/sidecar/internal/undojournal"
+)
+
+// journalWorktreeRemoval captures a worktree that is about to be removed for
+// the repository's undo journal: its branch and HEAD, its index, and the
+// contents of every changed or untracked file, so undoing the delete brings
+// back the work a forced removal throws away. The returned function records
+// the entry from RepoPath, which outlives the worktree, once the removal has
+// succeeded. A failed record never stops the removal.
+func journalWorktreeRemoval(ctx context.Context, req WorktreeRemoval) func(error) {
+	var files []string
+	for _, args := range [][]string{
+		{"diff", "-z", "--name-only", "HEAD"},
+		{"ls-files", "-z", "--others", "--exclude-standard"},
+	} {
+		out, err := gitOutput(ctx, req.Path, args...)
+		if err != nil {
+			continue
+		}
+		for _, path := range strings.Split(out, "\x00") {
+			if path != "" {
+				files = append(files, path)
+			}
+		}
+	}
+	e, err := undojournal.Capture(ctx, req.Path, "delete worktree", undojournal.Options{Worktree: true, Index: true, Files: files})
+	return func(removeErr error) {
+		if err != nil || removeErr != nil {
+			return
+		}
+		e.RepoDir = req.RepoPath
+		_ = undojournal.Record(ctx, req.RepoPath, e)
+	}
+}
+
+// journalBranchDeletion captures a local branch's tip before it is deleted
+// and returns the function that records it once the deletion has succeeded.
+func journalBranchDeletion(ctx context.Context, req BranchDeletion) func(error) {
+	return undojournal.Begin(ctx, req.RepoPath, "delete branch "+req.Branch, undojournal.Options{Refs: []string{"refs/heads/" + req.Branch}})
+}
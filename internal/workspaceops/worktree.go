@@ -38,6 +38,9 @@ type WorktreePlan struct {
 	RunHook        bool     `json:"runHook"`
 	HookPath       string   `json:"hookPath"`
 	HookRequired   bool     `json:"hookRequired"`
+	// Sparse is the source worktree's sparse checkout, reapplied to the new
+	// worktree; nil when the source checks out everything.
+	Sparse *SparseCheckout `json:"sparse,omitempty"`
 }
 
 // WorktreeRecord is the lifecycle identity returned by creation. It
@@ -156,7 +159,8 @@ func ResolveWorktreePlan(ctx context.Context, workDir, projectRoot, name, base s
 	}
 	return &WorktreePlan{SourceWorktree: sourceWorktree, MainWorktree: mainWorktree, SourceRef: sourceRef, SourceOID: sourceOID,
 		Branch: slug, Path: destination, DisplayName: displayName, RemotePolicy: "local branch only; no remote push",
-		CopyEnv: len(envFiles) > 0, EnvFiles: envFiles, RunHook: runHook, HookPath: hookPath, HookRequired: setup.HookRequired}, nil
+		CopyEnv: len(envFiles) > 0, EnvFiles: envFiles, RunHook: runHook, HookPath: hookPath, HookRequired: setup.HookRequired,
+		Sparse: ResolveSparseCheckout(ctx, sourceWorktree)}, nil
 }
 
 // ExecuteWorktree revalidates the confirmed plan, creates into a pinned
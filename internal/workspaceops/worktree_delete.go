@@ -192,10 +192,12 @@ func DeleteWorktree(ctx context.Context, req WorktreeRemoval) error {
 		return shellErr
 	}
 
+	record := journalWorktreeRemoval(ctx, req)
 	cmd := exec.CommandContext(ctx, "git", "worktree", "remove", req.Path)
 	cmd.Dir = req.RepoPath
 	output, err := cmd.CombinedOutput()
 	if err == nil {
+		record(nil)
 		return shellErr
 	}
 	if !req.Force {
@@ -207,7 +209,9 @@ func DeleteWorktree(ctx context.Context, req WorktreeRemoval) error {
 
 	cmd = exec.CommandContext(ctx, "git", "worktree", "remove", "--force", req.Path)
 	cmd.Dir = req.RepoPath
-	if output, err := cmd.CombinedOutput(); err != nil {
+	output, err = cmd.CombinedOutput()
+	record(err)
+	if err != nil {
 		return fmt.Errorf("git worktree remove: %s: %w", strings.TrimSpace(string(output)), err)
 	}
 	return shellErr
@@ -422,9 +426,12 @@ func DeleteLocalBranch(ctx context.Context, req BranchDeletion) error {
 	if req.Force {
 		flag = "-D"
 	}
+	record := journalBranchDeletion(ctx, req)
 	cmd := exec.CommandContext(ctx, "git", "branch", flag, req.Branch)
 	cmd.Dir = req.RepoPath
-	if output, err := cmd.CombinedOutput(); err != nil {
+	output, err := cmd.CombinedOutput()
+	record(err)
+	if err != nil {
 		return fmt.Errorf("delete branch: %s: %w", strings.TrimSpace(string(output)), err)
 	}
 	return nil
@@ -0,0 +1,93 @@
+// Package worktreestatus records which of a project's worktrees have
+// uncommitted changes, so a process that is not Sidecar can answer "is
+// anything dirty" without running git in every checkout.
+//
+// The workspace plugin already reads every worktree's status on each refresh;
+// it writes the counts here as it goes. A reader sees the state as of the last
+// refresh of a running Sidecar, which is what a status line wants: cheap and
+// nearly current.
+package worktreestatus
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileName is the store's basename inside a project's state directory.
+const FileName = "worktree-status.json"
+
+// Worktree is one checkout's uncommitted changes, counted by kind.
+type Worktree struct {
+	Path      string `json:"path"`
+	Branch    string `json:"branch,omitempty"`
+	Staged    int    `json:"staged"`
+	Unstaged  int    `json:"unstaged"`
+	Untracked int    `json:"untracked"`
+}
+
+// Dirty reports whether the worktree has anything uncommitted.
+func (w Worktree) Dirty() bool {
+	return w.Staged+w.Unstaged+w.Untracked > 0
+}
+
+// Status is the stored file.
+type Status struct {
+	Version   int        `json:"version"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	Worktrees []Worktree `json:"worktrees"`
+}
+
+// Path is the store inside a project's state directory.
+func Path(projectDir string) string {
+	return filepath.Join(projectDir, FileName)
+}
+
+// Read loads a project's store. A missing file is an empty status, not an
+// error: the project has simply not been refreshed by a running Sidecar yet.
+func Read(path string) (Status, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Status{}, nil
+	}
+	if err != nil {
+		return Status{}, err
+	}
+	var status Status
+	if err := json.Unmarshal(data, &status); err != nil {
+		return Status{}, err
+	}
+	return status, nil
+}
+
+// Save replaces the store atomically so a reader polling every second never
+// parses a half-written file.
+func Save(path string, worktrees []Worktree, now time.Time) error {
+	if worktrees == nil {
+		worktrees = []Worktree{}
+	}
+	data, err := json.Marshal(Status{Version: 1, UpdatedAt: now, Worktrees: worktrees})
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".worktree-status-*")
+	if err != nil {
+		return err
+	}
+	name := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(name)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(name)
+		return err
+	}
+	return os.Rename(name, path)
+}
@@ -0,0 +1,36 @@
+package worktreestatus
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveAndReadRoundTrip(t *testing.T) {
+	path := Path(t.TempDir())
+	now := time.Now().Truncate(time.Second)
+	in := []Worktree{
+		{Path: "/src/app", Branch: "main"},
+		{Path: "/src/app-auth", Branch: "auth", Unstaged: 2, Untracked: 1},
+	}
+	if err := Save(path, in, now); err != nil {
+		t.Fatal(err)
+	}
+	status, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.UpdatedAt.Equal(now) || len(status.Worktrees) != 2 {
+		t.Fatalf("status = %+v", status)
+	}
+	if status.Worktrees[0].Dirty() || !status.Worktrees[1].Dirty() {
+		t.Fatalf("dirty flags wrong: %+v", status.Worktrees)
+	}
+}
+
+func TestReadMissingFileIsEmpty(t *testing.T) {
+	status, err := Read(filepath.Join(t.TempDir(), FileName))
+	if err != nil || len(status.Worktrees) != 0 {
+		t.Fatalf("status = %+v, err = %v", status, err)
+	}
+}